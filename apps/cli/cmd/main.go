@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/jameswlane/devex/apps/cli/internal/audit"
 	"github.com/jameswlane/devex/apps/cli/internal/commands"
 	"github.com/jameswlane/devex/apps/cli/internal/config"
 	"github.com/jameswlane/devex/apps/cli/internal/datastore"
@@ -15,6 +16,7 @@ import (
 	"github.com/jameswlane/devex/apps/cli/internal/platform"
 	"github.com/jameswlane/devex/apps/cli/internal/types"
 	"github.com/jameswlane/devex/apps/cli/internal/utils"
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
 )
 
 var (
@@ -25,6 +27,12 @@ var (
 // Version updated for plugin SDK integration release
 
 func main() {
+	// The CLI doubles as the sandbox launcher for plugins: confine the process and exec the plugin
+	if len(os.Args) > 1 && os.Args[1] == sdk.SandboxLauncherArg {
+		sdk.SetSudoAuditor(auditSudoCommand)
+		sdk.RunSandboxLauncher(os.Args[2:])
+	}
+
 	// Determine debug mode from command line arguments or environment
 	debugMode := isDebugMode()
 
@@ -61,6 +69,9 @@ func main() {
 		handleError("determining home directory", err)
 	}
 
+	// Record every command DevEx runs in the tamper-evident audit log
+	audit.SetDefault(audit.New(audit.DefaultPath(homeDir)))
+
 	log.Info("DevEx CLI started", "version", version, "platform", fmt.Sprintf("%s/%s", plat.OS, plat.Architecture))
 
 	// Initialize a database with proper directory creation
@@ -78,7 +89,12 @@ func main() {
 	rootCmd := commands.NewRootCmd(version, repo, crossPlatformSettings)
 
 	// Execute the command (fixed: removed duplicate execution)
-	if err := rootCmd.Execute(); err != nil {
+	err = rootCmd.Execute()
+
+	// Export the traces and metrics of the run before the process may exit
+	commands.ShutdownTelemetry(err)
+
+	if err != nil {
 		handleError("executing root command", err)
 	}
 
@@ -116,6 +132,19 @@ func handleError(context string, err error) {
 	}
 }
 
+// auditSudoCommand records a command a plugin runs as root through its sudo helper in the
+// audit log
+func auditSudoCommand(plugin, command string, args []string) func(error) {
+	if audit.Default() == nil {
+		if homeDir, err := utils.GetHomeDir(); err == nil {
+			audit.SetDefault(audit.New(audit.DefaultPath(homeDir)))
+		}
+	}
+	ctx := audit.WithTrigger(context.Background(), plugin, "plugin")
+	execution := audit.BeginCommand(ctx, "sudo", append([]string{command}, args...))
+	return func(err error) { _ = execution.End(err) }
+}
+
 func initializeDatabase(homeDir string) types.Repository {
 	// Ensure .devex directory exists
 	devexDir := filepath.Join(homeDir, ".devex")
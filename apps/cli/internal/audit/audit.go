@@ -0,0 +1,303 @@
+// Package audit keeps a tamper-evident record of the commands DevEx runs. Records are appended
+// to a JSONL file and each one carries the SHA-256 hash of the record before it, so editing,
+// reordering or removing a record breaks the chain from that point on.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileName is the name of the audit log
+const FileName = "audit.jsonl"
+
+// maxRecordSize bounds a single record when reading the log
+const maxRecordSize = 1024 * 1024
+
+// Record is a single executed command
+type Record struct {
+	Seq       int64     `json:"seq"`
+	Time      time.Time `json:"time"`
+	Command   string    `json:"command"`
+	Args      []string  `json:"args,omitempty"`
+	UID       int       `json:"uid"`
+	Sudo      bool      `json:"sudo"`
+	App       string    `json:"app,omitempty"`
+	Operation string    `json:"operation,omitempty"`
+	ExitCode  int       `json:"exit_code"`
+	Duration  int64     `json:"duration_ms"`
+	Error     string    `json:"error,omitempty"`
+	PrevHash  string    `json:"prev_hash"`
+	Hash      string    `json:"hash"`
+}
+
+// computeHash returns the hash of a record, covering every field but the hash itself
+func (r Record) computeHash() (string, error) {
+	r.Hash = ""
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Log is an append-only audit log file
+type Log struct {
+	path string
+	mu   sync.Mutex
+}
+
+// New returns the audit log stored at path. The file is created on the first append.
+func New(path string) *Log {
+	return &Log{path: path}
+}
+
+// DefaultPath returns the audit log location in the DevEx data directory
+func DefaultPath(homeDir string) string {
+	return filepath.Join(homeDir, ".local", "share", "devex", FileName)
+}
+
+// Path returns the location of the log file
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append chains a record to the end of the log and returns it with its sequence number and
+// hashes filled in
+func (l *Log) Append(record Record) (Record, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return record, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	file, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return record, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	// Other DevEx processes append to the same chain
+	if err := lockFile(file); err != nil {
+		return record, fmt.Errorf("failed to lock audit log: %w", err)
+	}
+	defer func() { _ = unlockFile(file) }()
+
+	last, err := lastRecord(file)
+	if err != nil {
+		return record, err
+	}
+	record.Seq, record.PrevHash = 1, ""
+	if last != nil {
+		record.Seq, record.PrevHash = last.Seq+1, last.Hash
+	}
+	if record.Hash, err = record.computeHash(); err != nil {
+		return record, err
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return record, fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return record, fmt.Errorf("failed to append to audit log: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return record, fmt.Errorf("failed to sync audit log: %w", err)
+	}
+	return record, nil
+}
+
+// lastRecord reads the final record of the log by scanning back from the end of the file
+func lastRecord(file *os.File) (*Record, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat audit log: %w", err)
+	}
+	size := info.Size()
+	if size == 0 {
+		return nil, nil
+	}
+
+	chunk := int64(4096)
+	for {
+		if chunk > size {
+			chunk = size
+		}
+		buf := make([]byte, chunk)
+		if _, err := file.ReadAt(buf, size-chunk); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+		buf = bytes.TrimRight(buf, "\n")
+		start := bytes.LastIndexByte(buf, '\n')
+		if start >= 0 || chunk == size {
+			var record Record
+			if err := json.Unmarshal(buf[start+1:], &record); err != nil {
+				return nil, fmt.Errorf("the last audit record is corrupt, run 'devex audit verify': %w", err)
+			}
+			return &record, nil
+		}
+		if chunk >= maxRecordSize {
+			return nil, fmt.Errorf("the last audit record exceeds %d bytes", maxRecordSize)
+		}
+		chunk *= 4
+	}
+}
+
+// Records reads every record of the log in order. A missing log has no records.
+func (l *Log) Records() ([]Record, error) {
+	var records []Record
+	err := l.scan(func(line int, record Record, err error) error {
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+		return nil
+	})
+	return records, err
+}
+
+// scan calls fn for each line of the log with the record it holds or the error decoding it
+func (l *Log) scan(fn func(line int, record Record, err error) error) error {
+	file, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var record Record
+		var decodeErr error
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			decodeErr = &ChainError{Line: line, Reason: fmt.Sprintf("invalid record: %v", err)}
+		}
+		if err := fn(line, record, decodeErr); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return nil
+}
+
+// ChainError reports where the audit log was tampered with or damaged
+type ChainError struct {
+	Line   int
+	Seq    int64
+	Reason string
+}
+
+func (e *ChainError) Error() string {
+	if e.Seq == 0 {
+		return fmt.Sprintf("audit log line %d: %s", e.Line, e.Reason)
+	}
+	return fmt.Sprintf("audit log line %d (record %d): %s", e.Line, e.Seq, e.Reason)
+}
+
+// Verification summarizes an intact audit log
+type Verification struct {
+	Records int
+	// Head is the hash of the last record. Comparing it with a previously noted head reveals
+	// records removed from the end of the log, which the chain alone cannot.
+	Head string
+}
+
+// Verify checks that every record matches its hash, follows the previous record and that no
+// record is missing. It returns a ChainError for the first record that does not.
+func (l *Log) Verify() (*Verification, error) {
+	result := &Verification{}
+	var prev *Record
+	err := l.scan(func(line int, record Record, err error) error {
+		if err != nil {
+			return err
+		}
+
+		hash, err := record.computeHash()
+		if err != nil {
+			return err
+		}
+		switch {
+		case hash != record.Hash:
+			return &ChainError{Line: line, Seq: record.Seq, Reason: "the record was modified"}
+		case prev == nil && (record.Seq != 1 || record.PrevHash != ""):
+			return &ChainError{Line: line, Seq: record.Seq, Reason: "the first records were removed"}
+		case prev != nil && record.PrevHash != prev.Hash:
+			return &ChainError{Line: line, Seq: record.Seq, Reason: fmt.Sprintf("does not follow record %d", prev.Seq)}
+		case prev != nil && record.Seq != prev.Seq+1:
+			return &ChainError{Line: line, Seq: record.Seq, Reason: fmt.Sprintf("records after %d are missing", prev.Seq)}
+		}
+
+		prev = &record
+		result.Records++
+		result.Head = record.Hash
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Filter selects records to show
+type Filter struct {
+	App       string
+	Operation string
+	Command   string
+	Since     time.Time
+	Failed    bool
+	// Limit keeps the most recent records, 0 keeps all
+	Limit int
+}
+
+// Match reports whether a record passes the filter, ignoring Limit
+func (f Filter) Match(record Record) bool {
+	switch {
+	case f.App != "" && record.App != f.App:
+		return false
+	case f.Operation != "" && record.Operation != f.Operation:
+		return false
+	case f.Command != "" && record.Command != f.Command && filepath.Base(record.Command) != f.Command:
+		return false
+	case !f.Since.IsZero() && record.Time.Before(f.Since):
+		return false
+	case f.Failed && record.ExitCode == 0:
+		return false
+	}
+	return true
+}
+
+// Apply returns the records passing the filter, oldest first
+func (f Filter) Apply(records []Record) []Record {
+	var matched []Record
+	for _, record := range records {
+		if f.Match(record) {
+			matched = append(matched, record)
+		}
+	}
+	if f.Limit > 0 && len(matched) > f.Limit {
+		matched = matched[len(matched)-f.Limit:]
+	}
+	return matched
+}
@@ -0,0 +1,200 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jameswlane/devex/apps/cli/internal/log"
+)
+
+// redacted replaces secrets in recorded arguments
+const redacted = "[REDACTED]"
+
+var (
+	defaultLog   *Log
+	defaultLogMu sync.RWMutex
+)
+
+// SetDefault sets the log that executed commands are recorded in. Commands are not recorded
+// until a log is set, which keeps tests and library use from writing to the user's log.
+func SetDefault(l *Log) {
+	defaultLogMu.Lock()
+	defer defaultLogMu.Unlock()
+	defaultLog = l
+}
+
+// Default returns the log that executed commands are recorded in, or nil
+func Default() *Log {
+	defaultLogMu.RLock()
+	defer defaultLogMu.RUnlock()
+	return defaultLog
+}
+
+type triggerKey struct{}
+
+// trigger is the app and operation a command runs for
+type trigger struct {
+	app       string
+	operation string
+}
+
+// WithTrigger returns a context recording the app and operation that commands run with it
+// belong to. An empty app keeps the app of the parent context.
+func WithTrigger(ctx context.Context, app, operation string) context.Context {
+	if app == "" {
+		app = triggerFrom(ctx).app
+	}
+	return context.WithValue(ctx, triggerKey{}, trigger{app: app, operation: operation})
+}
+
+// triggerFrom returns the trigger recorded in a context
+func triggerFrom(ctx context.Context) trigger {
+	if ctx == nil {
+		return trigger{}
+	}
+	t, _ := ctx.Value(triggerKey{}).(trigger)
+	return t
+}
+
+// Execution is a command being recorded
+type Execution struct {
+	log    *Log
+	record Record
+	start  time.Time
+}
+
+// Begin starts recording a command that is about to run. Call End with the command's result.
+func Begin(ctx context.Context, cmd *exec.Cmd) *Execution {
+	return BeginCommand(ctx, cmd.Path, cmd.Args[min(1, len(cmd.Args)):])
+}
+
+// BeginCommand starts recording a program run on DevEx's behalf, such as a plugin, whose
+// exec.Cmd is not available
+func BeginCommand(ctx context.Context, command string, args []string) *Execution {
+	l := Default()
+	if l == nil {
+		return &Execution{}
+	}
+
+	t := triggerFrom(ctx)
+	args = RedactArgs(args)
+	return &Execution{
+		log: l,
+		record: Record{
+			Command:   command,
+			Args:      args,
+			UID:       os.Getuid(),
+			Sudo:      usesSudo(command, args),
+			App:       t.app,
+			Operation: t.operation,
+		},
+		start: time.Now(),
+	}
+}
+
+// End appends the command to the audit log and returns err unchanged. Failing to record the
+// command is logged rather than failing the operation that ran it.
+func (e *Execution) End(err error) error {
+	if e.log == nil {
+		return err
+	}
+
+	e.record.Time = e.start.UTC()
+	e.record.Duration = time.Since(e.start).Milliseconds()
+	e.record.ExitCode = exitCode(err)
+	if err != nil {
+		e.record.Error = RedactString(err.Error())
+	}
+	if _, appendErr := e.log.Append(e.record); appendErr != nil {
+		log.Warn("Failed to record command in audit log", "command", e.record.Command, "error", appendErr)
+	}
+	return err
+}
+
+// Run runs a command and records it
+func Run(ctx context.Context, cmd *exec.Cmd) error {
+	execution := Begin(ctx, cmd)
+	return execution.End(cmd.Run())
+}
+
+// CombinedOutput runs a command, records it and returns its combined output
+func CombinedOutput(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	execution := Begin(ctx, cmd)
+	output, err := cmd.CombinedOutput()
+	return output, execution.End(err)
+}
+
+// exitCode returns the exit code of a finished command, -1 when it could not run
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// usesSudo reports whether a command runs as root through sudo, directly or in a shell script
+func usesSudo(command string, args []string) bool {
+	if filepath.Base(command) == "sudo" {
+		return true
+	}
+	if name := filepath.Base(command); (name == "bash" || name == "sh") && len(args) == 2 && args[0] == "-c" {
+		return sudoPattern.MatchString(args[1])
+	}
+	return false
+}
+
+var (
+	sudoPattern = regexp.MustCompile(`(^|[;&|(\s])sudo\s`)
+
+	// secretFlags take a secret as their value
+	secretFlags = regexp.MustCompile(`(?i)^--?[a-z0-9-]*(password|passwd|token|secret|api-?key|credentials?)$`)
+
+	// secretPatterns match secrets inside a single argument or shell script
+	secretPatterns = []*regexp.Regexp{
+		// --password=value, TOKEN=value, api_key: value
+		regexp.MustCompile(`(?i)([a-z0-9_-]*(?:password|passwd|token|secret|api[_-]?key|credentials?)[a-z0-9_]*\s*[=:]\s*)("[^"]*"|'[^']*'|[^\s;&|"']+)`),
+		// --password value, as a whole word inside a script
+		regexp.MustCompile(`(?i)(--?[a-z0-9-]*(?:password|passwd|token|secret|api-?key)\s+)("[^"]*"|'[^']*'|[^\s;&|"'-][^\s;&|"']*)`),
+		// Authorization: Bearer value
+		regexp.MustCompile(`(?i)(authorization:\s*(?:bearer|basic|token)\s+)([^\s"']+)`),
+	}
+
+	// urlCredentials matches the user information of a URL
+	urlCredentials = regexp.MustCompile(`(://)[^/\s:@]+:[^/\s@]+@`)
+)
+
+// RedactArgs replaces secrets in command arguments: values of secret flags, secret variable
+// assignments, authorization headers and credentials in URLs
+func RedactArgs(args []string) []string {
+	result := make([]string, len(args))
+	for i, arg := range args {
+		if i > 0 && secretFlags.MatchString(args[i-1]) {
+			result[i] = redacted
+			continue
+		}
+		result[i] = RedactString(arg)
+	}
+	return result
+}
+
+// RedactString replaces secrets in a single argument or shell script
+func RedactString(s string) string {
+	for _, pattern := range secretPatterns {
+		s = pattern.ReplaceAllString(s, "${1}"+redacted)
+	}
+	if strings.Contains(s, "://") {
+		s = urlCredentials.ReplaceAllString(s, "${1}"+redacted+"@")
+	}
+	return s
+}
@@ -3,10 +3,14 @@ package backup
 import (
 	"archive/tar"
 	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -27,9 +31,15 @@ const (
 	MaxFiles     = 10000              // Maximum number of files
 )
 
+// BackupManager creates backups as snapshots in a chunk store and keeps a local index of
+// them. Backups of the legacy archive format in the backups directory remain readable.
 type BackupManager struct {
-	baseDir    string
-	backupsDir string
+	baseDir      string
+	backupsDir   string
+	storeOptions StoreOptions
+	storeErr     error
+	store        *ChunkStore
+	confirmPaths func(paths []string) bool
 }
 
 type BackupMetadata struct {
@@ -42,6 +52,11 @@ type BackupMetadata struct {
 	Version     string            `json:"version" yaml:"version"`
 	Tags        []string          `json:"tags,omitempty" yaml:"tags,omitempty"`
 	Changes     map[string]string `json:"changes,omitempty" yaml:"changes,omitempty"`
+	Format      string            `json:"format,omitempty" yaml:"format,omitempty"`
+	Target      string            `json:"target,omitempty" yaml:"target,omitempty"`
+	Encryption  string            `json:"encryption,omitempty" yaml:"encryption,omitempty"`
+	StoredSize  int64             `json:"stored_size,omitempty" yaml:"stored_size,omitempty"` // Bytes uploaded after deduplication
+	Paths       []string          `json:"paths,omitempty" yaml:"paths,omitempty"`             // Absolute extra paths passed with BackupOptions.Paths
 }
 
 type BackupOptions struct {
@@ -49,15 +64,27 @@ type BackupOptions struct {
 	Type        string
 	Tags        []string
 	MaxBackups  int
-	Compress    bool
+	Compress    bool // Only used by the legacy format, chunks are always compressed
 	Include     []string
 	Exclude     []string
+	Paths       []string // Files and directories backed up besides the configuration, such as dotfiles
 }
 
+// NewBackupManager creates a backup manager for baseDir whose chunk store is configured
+// from the environment, see StoreOptionsFromEnv
 func NewBackupManager(baseDir string) *BackupManager {
+	options, err := StoreOptionsFromEnv(baseDir)
+	bm := NewBackupManagerWithStore(baseDir, options)
+	bm.storeErr = err
+	return bm
+}
+
+// NewBackupManagerWithStore creates a backup manager for baseDir using the given chunk store
+func NewBackupManagerWithStore(baseDir string, options StoreOptions) *BackupManager {
 	return &BackupManager{
-		baseDir:    baseDir,
-		backupsDir: filepath.Join(baseDir, BackupsDir),
+		baseDir:      baseDir,
+		backupsDir:   filepath.Join(baseDir, BackupsDir),
+		storeOptions: options,
 	}
 }
 
@@ -66,69 +93,34 @@ func (bm *BackupManager) CreateBackup(options BackupOptions) (*BackupMetadata, e
 		return nil, fmt.Errorf("failed to create backups directory: %w", err)
 	}
 
-	backupID := bm.generateBackupID()
-	backupDir := filepath.Join(bm.backupsDir, backupID)
-
-	if err := os.MkdirAll(backupDir, 0750); err != nil {
-		return nil, fmt.Errorf("failed to create backup directory: %w", err)
-	}
-
-	configDir := filepath.Join(bm.baseDir, "config")
-	files, err := bm.collectFiles(configDir, options.Include, options.Exclude)
+	store, err := bm.openStore(true)
 	if err != nil {
-		return nil, fmt.Errorf("failed to collect files from %s (include: %v, exclude: %v): %w", configDir, options.Include, options.Exclude, err)
+		return nil, fmt.Errorf("failed to open backup store: %w", err)
 	}
 
-	var totalSize int64
-	var backedUpFiles []string
-
-	if options.Compress {
-		archivePath := filepath.Join(backupDir, "config.tar.gz")
-		size, err := bm.createCompressedBackup(configDir, archivePath, files)
-		if err != nil {
-			if removeErr := os.RemoveAll(backupDir); removeErr != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to clean up backup directory: %v\n", removeErr)
-			}
-			return nil, fmt.Errorf("failed to create compressed backup: %w", err)
-		}
-		totalSize = size
-		backedUpFiles = []string{"config.tar.gz"}
-	} else {
-		for _, file := range files {
-			src := filepath.Join(configDir, file)
-			dst := filepath.Join(backupDir, "config", file)
-
-			if err := bm.copyFile(src, dst); err != nil {
-				if removeErr := os.RemoveAll(backupDir); removeErr != nil {
-					fmt.Fprintf(os.Stderr, "Warning: failed to clean up backup directory: %v\n", removeErr)
-				}
-				return nil, fmt.Errorf("failed to copy file %s: %w", file, err)
-			}
-
-			info, _ := os.Stat(src)
-			if info != nil {
-				totalSize += info.Size()
-			}
-			backedUpFiles = append(backedUpFiles, filepath.Join("config", file))
-		}
+	roots, err := bm.snapshotRoots(options)
+	if err != nil {
+		return nil, err
 	}
 
 	metadata := &BackupMetadata{
-		ID:          backupID,
+		ID:          bm.generateBackupID(),
 		Timestamp:   time.Now(),
 		Description: options.Description,
 		Type:        options.Type,
-		Files:       backedUpFiles,
-		Size:        totalSize,
+		Files:       []string{},
 		Version:     "1.0.0",
 		Tags:        options.Tags,
+		Format:      FormatChunked,
+		Target:      store.Target().String(),
+		Encryption:  store.Encryption(),
+	}
+	for _, root := range roots[1:] {
+		metadata.Paths = append(metadata.Paths, root.Path)
 	}
 
-	if err := bm.saveMetadata(backupDir, metadata); err != nil {
-		if removeErr := os.RemoveAll(backupDir); removeErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to clean up backup directory %s: %v\n", backupDir, removeErr)
-		}
-		return nil, fmt.Errorf("failed to save metadata to %s for backup %s: %w", backupDir, backupID, err)
+	if _, err := bm.createSnapshot(store, metadata, roots, options); err != nil {
+		return nil, fmt.Errorf("failed to create backup %s: %w", metadata.ID, err)
 	}
 
 	if err := bm.updateGlobalMetadata(metadata); err != nil {
@@ -142,10 +134,16 @@ func (bm *BackupManager) CreateBackup(options BackupOptions) (*BackupMetadata, e
 	return metadata, nil
 }
 
+// RestoreBackup restores a backup after backing up the files it replaces. The configuration
+// is restored to targetDir, or to the configuration directory when targetDir is empty.
 func (bm *BackupManager) RestoreBackup(backupID string, targetDir string) error {
+	if targetDir == "" {
+		targetDir = filepath.Join(bm.baseDir, "config")
+	}
+
 	backupDir := filepath.Join(bm.backupsDir, backupID)
 	if _, err := os.Stat(backupDir); os.IsNotExist(err) {
-		return fmt.Errorf("backup %s not found in directory %s", backupID, bm.backupsDir)
+		return bm.restoreChunkedBackup(backupID, targetDir)
 	}
 
 	_, err := bm.loadMetadata(backupDir)
@@ -153,10 +151,6 @@ func (bm *BackupManager) RestoreBackup(backupID string, targetDir string) error
 		return fmt.Errorf("failed to load backup metadata for %s from %s: %w", backupID, backupDir, err)
 	}
 
-	if targetDir == "" {
-		targetDir = filepath.Join(bm.baseDir, "config")
-	}
-
 	currentBackup, err := bm.CreateBackup(BackupOptions{
 		Description: fmt.Sprintf("Pre-restore backup (restoring from %s)", backupID),
 		Type:        "pre-restore",
@@ -193,22 +187,70 @@ func (bm *BackupManager) RestoreBackup(backupID string, targetDir string) error
 	return nil
 }
 
-func (bm *BackupManager) ListBackups(filter string, limit int) ([]*BackupMetadata, error) {
-	metadataPath := filepath.Join(bm.backupsDir, MetadataFile)
-	if _, err := os.Stat(metadataPath); os.IsNotExist(err) {
-		return []*BackupMetadata{}, nil
+// SetPathConfirmation sets how a restore confirms absolute paths of a snapshot that the
+// local index does not record as backed up from this machine. Without it such paths are
+// refused.
+func (bm *BackupManager) SetPathConfirmation(confirm func(paths []string) bool) {
+	bm.confirmPaths = confirm
+}
+
+// restoreChunkedBackup restores a snapshot from the chunk store, restoring the pre-restore
+// backup of the same paths when it fails
+func (bm *BackupManager) restoreChunkedBackup(backupID string, targetDir string) error {
+	manifest, err := bm.getSnapshot(backupID)
+	if err != nil {
+		return err
 	}
 
-	data, err := os.ReadFile(metadataPath)
+	dests, err := bm.restoreRoots(backupID, manifest, targetDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read metadata: %w", err)
+		return err
+	}
+	var paths []string
+	for i, root := range manifest.Roots {
+		if root.Name != configRoot {
+			paths = append(paths, dests[i])
+		}
+	}
+	currentBackup, err := bm.CreateBackup(BackupOptions{
+		Description: fmt.Sprintf("Pre-restore backup (restoring from %s)", backupID),
+		Type:        "pre-restore",
+		Tags:        []string{"auto", "pre-restore"},
+		Paths:       paths,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create pre-restore backup before restoring %s to %s: %w", backupID, targetDir, err)
 	}
 
-	var allBackups []*BackupMetadata
-	if err := json.Unmarshal(data, &allBackups); err != nil {
-		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	if err := bm.restoreSnapshot(manifest, dests); err != nil {
+		if restoreErr := bm.RestoreBackup(currentBackup.ID, targetDir); restoreErr != nil {
+			// Log the restore error but don't fail the original error
+			fmt.Fprintf(os.Stderr, "Warning: failed to restore backup %s: %v\n", currentBackup.ID, restoreErr)
+		}
+		return fmt.Errorf("failed to restore files: %w", err)
 	}
 
+	return nil
+}
+
+// ListBackups lists the backups of the local index together with snapshots in the chunk
+// store created elsewhere, newest first
+func (bm *BackupManager) ListBackups(filter string, limit int) ([]*BackupMetadata, error) {
+	allBackups, err := bm.readGlobalMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	indexed := make(map[string]bool, len(allBackups))
+	for _, backup := range allBackups {
+		indexed[backup.ID] = true
+	}
+	remote, err := bm.listSnapshots(indexed)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to list backups in the backup store: %v\n", err)
+	}
+	allBackups = append(allBackups, remote...)
+
 	var filtered []*BackupMetadata
 	for _, backup := range allBackups {
 		if filter == "" || bm.matchesFilter(backup, filter) {
@@ -227,22 +269,62 @@ func (bm *BackupManager) ListBackups(filter string, limit int) ([]*BackupMetadat
 	return filtered, nil
 }
 
+// readGlobalMetadata reads the local index of backups
+func (bm *BackupManager) readGlobalMetadata() ([]*BackupMetadata, error) {
+	metadataPath := filepath.Join(bm.backupsDir, MetadataFile)
+	data, err := os.ReadFile(metadataPath)
+	if os.IsNotExist(err) {
+		return []*BackupMetadata{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+
+	var allBackups []*BackupMetadata
+	if err := json.Unmarshal(data, &allBackups); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	return allBackups, nil
+}
+
 func (bm *BackupManager) GetBackup(backupID string) (*BackupMetadata, error) {
 	backupDir := filepath.Join(bm.backupsDir, backupID)
+	if _, err := os.Stat(backupDir); os.IsNotExist(err) {
+		manifest, err := bm.getSnapshot(backupID)
+		if err != nil {
+			return nil, err
+		}
+		return &manifest.Metadata, nil
+	}
 	return bm.loadMetadata(backupDir)
 }
 
+// DeleteBackup deletes a backup and the chunks no other backup references
 func (bm *BackupManager) DeleteBackup(backupID string) error {
-	backupDir := filepath.Join(bm.backupsDir, backupID)
-	if _, err := os.Stat(backupDir); os.IsNotExist(err) {
-		return fmt.Errorf("backup %s not found in directory %s", backupID, bm.backupsDir)
+	chunked, err := bm.deleteBackup(backupID)
+	if err != nil {
+		return err
 	}
+	if chunked {
+		return bm.pruneChunks()
+	}
+	return nil
+}
 
-	if err := os.RemoveAll(backupDir); err != nil {
-		return fmt.Errorf("failed to delete backup %s from %s: %w", backupID, backupDir, err)
+// deleteBackup deletes a backup without pruning chunks and reports whether it was a snapshot
+func (bm *BackupManager) deleteBackup(backupID string) (bool, error) {
+	chunked := false
+	backupDir := filepath.Join(bm.backupsDir, backupID)
+	if _, err := os.Stat(backupDir); os.IsNotExist(err) {
+		if err := bm.deleteSnapshot(backupID); err != nil {
+			return false, err
+		}
+		chunked = true
+	} else if err := os.RemoveAll(backupDir); err != nil {
+		return false, fmt.Errorf("failed to delete backup %s from %s: %w", backupID, backupDir, err)
 	}
 
-	return bm.removeFromGlobalMetadata(backupID)
+	return chunked, bm.removeFromGlobalMetadata(backupID)
 }
 
 func (bm *BackupManager) CompareBackups(id1, id2 string) (*BackupComparison, error) {
@@ -264,30 +346,34 @@ func (bm *BackupManager) CompareBackups(id1, id2 string) (*BackupComparison, err
 		ModifiedFiles: []string{},
 	}
 
-	files1 := make(map[string]bool)
-	for _, f := range backup1.Files {
-		files1[f] = true
+	files1, err := bm.fileHashes(backup1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read files of backup %s: %w", id1, err)
 	}
 
-	files2 := make(map[string]bool)
-	for _, f := range backup2.Files {
-		files2[f] = true
+	files2, err := bm.fileHashes(backup2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read files of backup %s: %w", id2, err)
 	}
 
 	for f := range files1 {
-		if !files2[f] {
+		if _, ok := files2[f]; !ok {
 			comparison.RemovedFiles = append(comparison.RemovedFiles, f)
 		}
 	}
 
-	for f := range files2 {
-		if !files1[f] {
+	for f, hash2 := range files2 {
+		if hash1, ok := files1[f]; !ok {
 			comparison.AddedFiles = append(comparison.AddedFiles, f)
-		} else if bm.filesDiffer(id1, id2, f) {
+		} else if hash1 != hash2 {
 			comparison.ModifiedFiles = append(comparison.ModifiedFiles, f)
 		}
 	}
 
+	sort.Strings(comparison.AddedFiles)
+	sort.Strings(comparison.RemovedFiles)
+	sort.Strings(comparison.ModifiedFiles)
+
 	return comparison, nil
 }
 
@@ -305,8 +391,32 @@ func (bm *BackupManager) ensureBackupsDir() error {
 	return os.MkdirAll(bm.backupsDir, 0750)
 }
 
+// generateBackupID returns an ID from the current time, with a counter appended when a backup
+// was already created in the same second, here or by another machine sharing the chunk store
 func (bm *BackupManager) generateBackupID() string {
-	return fmt.Sprintf("backup-%s", time.Now().Format(BackupTimeFormat))
+	base := fmt.Sprintf("backup-%s", time.Now().Format(BackupTimeFormat))
+
+	existing := make(map[string]bool)
+	if backups, err := bm.readGlobalMetadata(); err == nil {
+		for _, backup := range backups {
+			existing[backup.ID] = true
+		}
+	}
+	if bm.store != nil {
+		if ids, err := bm.store.ListSnapshots(context.Background()); err == nil {
+			for _, id := range ids {
+				existing[id] = true
+			}
+		}
+	}
+
+	id := base
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(bm.backupsDir, id)); !existing[id] && os.IsNotExist(err) {
+			return id
+		}
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
 }
 
 func (bm *BackupManager) collectFiles(dir string, include, exclude []string) ([]string, error) {
@@ -359,63 +469,6 @@ func (bm *BackupManager) shouldInclude(path string, include, exclude []string) b
 	return true
 }
 
-func (bm *BackupManager) createCompressedBackup(sourceDir, archivePath string, files []string) (int64, error) {
-	file, err := os.Create(archivePath)
-	if err != nil {
-		return 0, err
-	}
-	defer file.Close()
-
-	gzWriter := gzip.NewWriter(file)
-	defer gzWriter.Close()
-
-	tarWriter := tar.NewWriter(gzWriter)
-	defer tarWriter.Close()
-
-	var totalSize int64
-
-	for _, filePath := range files {
-		fullPath := filepath.Join(sourceDir, filePath)
-		if err := bm.addToTar(tarWriter, fullPath, filePath); err != nil {
-			return 0, err
-		}
-
-		info, _ := os.Stat(fullPath)
-		if info != nil {
-			totalSize += info.Size()
-		}
-	}
-
-	return totalSize, nil
-}
-
-func (bm *BackupManager) addToTar(tw *tar.Writer, sourcePath, archivePath string) error {
-	file, err := os.Open(sourcePath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	info, err := file.Stat()
-	if err != nil {
-		return err
-	}
-
-	header, err := tar.FileInfoHeader(info, "")
-	if err != nil {
-		return err
-	}
-
-	header.Name = archivePath
-
-	if err := tw.WriteHeader(header); err != nil {
-		return err
-	}
-
-	_, err = io.Copy(tw, file)
-	return err
-}
-
 func (bm *BackupManager) extractCompressedBackup(archivePath, targetDir string) error {
 	file, err := os.Open(archivePath)
 	if err != nil {
@@ -695,10 +748,16 @@ func (bm *BackupManager) pruneOldBackups(maxBackups int) error {
 		})
 
 		toDelete := len(autoBackups) - maxBackups
+		pruneChunks := false
 		for i := 0; i < toDelete; i++ {
-			if err := bm.DeleteBackup(autoBackups[i].ID); err != nil {
+			chunked, err := bm.deleteBackup(autoBackups[i].ID)
+			if err != nil {
 				return err
 			}
+			pruneChunks = pruneChunks || chunked
+		}
+		if pruneChunks {
+			return bm.pruneChunks()
 		}
 	}
 
@@ -729,18 +788,71 @@ func (bm *BackupManager) matchesFilter(backup *BackupMetadata, filter string) bo
 	return false
 }
 
-func (bm *BackupManager) filesDiffer(backupID1, backupID2, file string) bool {
-	path1 := filepath.Join(bm.backupsDir, backupID1, file)
-	path2 := filepath.Join(bm.backupsDir, backupID2, file)
+// fileHashes returns the SHA-256 of every file of a backup, keyed by the path listed in
+// BackupMetadata.Files of chunked backups
+func (bm *BackupManager) fileHashes(backup *BackupMetadata) (map[string]string, error) {
+	if backup.Format == FormatChunked {
+		return bm.snapshotHashes(backup.ID)
+	}
 
-	data1, err1 := os.ReadFile(path1)
-	data2, err2 := os.ReadFile(path2)
+	hashes := make(map[string]string)
+	backupDir := filepath.Join(bm.backupsDir, backup.ID)
+	compressedPath := filepath.Join(backupDir, "config.tar.gz")
+	if _, err := os.Stat(compressedPath); err == nil {
+		err := bm.readCompressedBackup(compressedPath, func(name string, r io.Reader) error {
+			hash := sha256.New()
+			if _, err := io.Copy(hash, io.LimitReader(r, MaxFileSize)); err != nil {
+				return err
+			}
+			hashes[path.Join(configRoot, filepath.ToSlash(filepath.Clean(name)))] = hex.EncodeToString(hash.Sum(nil))
+			return nil
+		})
+		return hashes, err
+	}
 
-	if err1 != nil || err2 != nil {
-		return true
+	for _, file := range backup.Files {
+		data, err := os.ReadFile(filepath.Join(backupDir, file))
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(data)
+		hashes[filepath.ToSlash(file)] = hex.EncodeToString(sum[:])
 	}
+	return hashes, nil
+}
 
-	return string(data1) != string(data2)
+// readCompressedBackup calls fn with each regular file of a legacy archive
+func (bm *BackupManager) readCompressedBackup(archivePath string, fn func(name string, r io.Reader) error) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gzReader, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gzReader.Close()
+
+	tarReader := tar.NewReader(gzReader)
+	for fileCount := 1; ; fileCount++ {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if fileCount > MaxFiles {
+			return fmt.Errorf("too many files in archive (limit: %d)", MaxFiles)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if err := fn(header.Name, tarReader); err != nil {
+				return err
+			}
+		}
+	}
 }
 
 type BackupComparison struct {
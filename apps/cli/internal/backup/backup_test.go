@@ -0,0 +1,276 @@
+package backup_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/backup"
+)
+
+// writeFile creates a file and its parent directories
+func writeFile(path, content string) {
+	Expect(os.MkdirAll(filepath.Dir(path), 0750)).To(Succeed())
+	Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+}
+
+// readFile returns the content of a file
+func readFile(path string) string {
+	data, err := os.ReadFile(path)
+	Expect(err).NotTo(HaveOccurred())
+	return string(data)
+}
+
+// writeLegacyBackup creates a backup in the archive format used before the chunk store
+func writeLegacyBackup(baseDir, id string, files map[string]string) {
+	backupDir := filepath.Join(baseDir, backup.BackupsDir, id)
+	Expect(os.MkdirAll(backupDir, 0750)).To(Succeed())
+
+	archive, err := os.Create(filepath.Join(backupDir, "config.tar.gz"))
+	Expect(err).NotTo(HaveOccurred())
+	gz := gzip.NewWriter(archive)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		Expect(tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg})).To(Succeed())
+		_, err := tw.Write([]byte(content))
+		Expect(err).NotTo(HaveOccurred())
+	}
+	Expect(tw.Close()).To(Succeed())
+	Expect(gz.Close()).To(Succeed())
+	Expect(archive.Close()).To(Succeed())
+
+	metadata := backup.BackupMetadata{
+		ID:        id,
+		Timestamp: time.Now().Add(-time.Hour),
+		Type:      "manual",
+		Files:     []string{"config.tar.gz"},
+		Version:   "1.0.0",
+	}
+	data, err := json.Marshal(metadata)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(os.WriteFile(filepath.Join(backupDir, "metadata.json"), data, 0600)).To(Succeed())
+	index, err := json.Marshal([]backup.BackupMetadata{metadata})
+	Expect(err).NotTo(HaveOccurred())
+	Expect(os.WriteFile(filepath.Join(baseDir, backup.BackupsDir, backup.MetadataFile), index, 0600)).To(Succeed())
+}
+
+var _ = Describe("BackupManager", func() {
+	var (
+		home      string
+		baseDir   string
+		configDir string
+		storeDir  string
+		manager   *backup.BackupManager
+	)
+
+	BeforeEach(func() {
+		home = GinkgoT().TempDir()
+		oldHome := os.Getenv("HOME")
+		Expect(os.Setenv("HOME", home)).To(Succeed())
+		DeferCleanup(func() { _ = os.Setenv("HOME", oldHome) })
+
+		baseDir = filepath.Join(home, ".devex")
+		configDir = filepath.Join(baseDir, "config")
+		storeDir = filepath.Join(GinkgoT().TempDir(), "store")
+		writeFile(filepath.Join(configDir, "applications.yaml"), "applications: [git]\n")
+		writeFile(filepath.Join(configDir, "system.yaml"), "shell: zsh\n")
+
+		manager = backup.NewBackupManagerWithStore(baseDir, backup.StoreOptions{Target: backup.NewLocalTarget(storeDir)})
+	})
+
+	It("backs up the configuration and extra paths incrementally", func() {
+		writeFile(filepath.Join(home, ".zshrc"), "export EDITOR=vim\n")
+
+		first, err := manager.CreateBackup(backup.BackupOptions{Type: "manual", Paths: []string{"~/.zshrc", filepath.Join(home, ".missing")}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(first.Format).To(Equal(backup.FormatChunked))
+		Expect(first.Files).To(ConsistOf("config/applications.yaml", "config/system.yaml", "~/.zshrc"))
+		Expect(first.StoredSize).To(BeNumerically(">", 0))
+
+		second, err := manager.CreateBackup(backup.BackupOptions{Type: "manual", Paths: []string{"~/.zshrc"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second.ID).NotTo(Equal(first.ID))
+		Expect(second.StoredSize).To(BeZero())
+
+		backups, err := manager.ListBackups("", 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(backups).To(HaveLen(2))
+
+		found, err := manager.GetBackup(first.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found.Files).To(Equal(first.Files))
+	})
+
+	It("restores files and backs up what it replaces", func() {
+		writeFile(filepath.Join(home, ".gitconfig"), "[user]\n\tname = Dev\n")
+		created, err := manager.CreateBackup(backup.BackupOptions{Type: "manual", Paths: []string{"~/.gitconfig"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		writeFile(filepath.Join(configDir, "system.yaml"), "shell: fish\n")
+		writeFile(filepath.Join(home, ".gitconfig"), "broken")
+
+		Expect(manager.RestoreBackup(created.ID, "")).To(Succeed())
+		Expect(readFile(filepath.Join(configDir, "system.yaml"))).To(Equal("shell: zsh\n"))
+		Expect(readFile(filepath.Join(home, ".gitconfig"))).To(Equal("[user]\n\tname = Dev\n"))
+
+		preRestore, err := manager.ListBackups("pre-restore", 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(preRestore).To(HaveLen(1))
+		comparison, err := manager.CompareBackups(created.ID, preRestore[0].ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(comparison.ModifiedFiles).To(ConsistOf("config/system.yaml", "~/.gitconfig"))
+	})
+
+	It("restores the configuration into another directory", func() {
+		created, err := manager.CreateBackup(backup.BackupOptions{Type: "manual"})
+		Expect(err).NotTo(HaveOccurred())
+
+		target := GinkgoT().TempDir()
+		Expect(manager.RestoreBackup(created.ID, target)).To(Succeed())
+		Expect(readFile(filepath.Join(target, "applications.yaml"))).To(Equal("applications: [git]\n"))
+	})
+
+	It("refuses home directory roots that resolve outside of it", func() {
+		store, err := backup.OpenChunkStore(context.Background(), backup.StoreOptions{Target: backup.NewLocalTarget(storeDir)}, true)
+		Expect(err).NotTo(HaveOccurred())
+		outside := filepath.Join(GinkgoT().TempDir(), "etc")
+		Expect(store.PutSnapshot(context.Background(), &backup.Manifest{
+			Metadata: backup.BackupMetadata{ID: "backup-20240101-120000", Format: backup.FormatChunked},
+			Roots:    []backup.SnapshotRoot{{Name: "config", Path: configDir}, {Name: "~/../../etc", Path: outside}},
+			Entries:  []backup.SnapshotEntry{{Root: 1, Rel: "passwd"}},
+		})).To(Succeed())
+
+		err = manager.RestoreBackup("backup-20240101-120000", "")
+		Expect(err).To(MatchError(ContainSubstring("security violation")))
+		Expect(filepath.Join(outside, "passwd")).NotTo(BeAnExistingFile())
+	})
+
+	It("restores absolute paths only when they were backed up here or are confirmed", func() {
+		outside := GinkgoT().TempDir()
+		writeFile(filepath.Join(outside, "hosts"), "127.0.0.1 devex\n")
+		otherDir := filepath.Join(GinkgoT().TempDir(), ".devex")
+		writeFile(filepath.Join(otherDir, "config", "system.yaml"), "shell: bash\n")
+		other := backup.NewBackupManagerWithStore(otherDir, backup.StoreOptions{Target: backup.NewLocalTarget(storeDir)})
+		created, err := other.CreateBackup(backup.BackupOptions{Type: "manual", Paths: []string{outside}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created.Paths).To(Equal([]string{outside}))
+
+		writeFile(filepath.Join(outside, "hosts"), "broken")
+		Expect(manager.RestoreBackup(created.ID, "")).To(MatchError(ContainSubstring("not backed up from this machine: " + outside)))
+		Expect(readFile(filepath.Join(outside, "hosts"))).To(Equal("broken"))
+
+		var confirmed []string
+		manager.SetPathConfirmation(func(paths []string) bool {
+			confirmed = paths
+			return true
+		})
+		Expect(manager.RestoreBackup(created.ID, "")).To(Succeed())
+		Expect(confirmed).To(Equal([]string{outside}))
+		Expect(readFile(filepath.Join(outside, "hosts"))).To(Equal("127.0.0.1 devex\n"))
+
+		writeFile(filepath.Join(outside, "hosts"), "broken")
+		Expect(other.RestoreBackup(created.ID, "")).To(Succeed())
+		Expect(readFile(filepath.Join(outside, "hosts"))).To(Equal("127.0.0.1 devex\n"))
+	})
+
+	It("compares backups by content", func() {
+		first, err := manager.CreateBackup(backup.BackupOptions{Type: "manual"})
+		Expect(err).NotTo(HaveOccurred())
+
+		writeFile(filepath.Join(configDir, "system.yaml"), "shell: bash\n")
+		Expect(os.Remove(filepath.Join(configDir, "applications.yaml"))).To(Succeed())
+		writeFile(filepath.Join(configDir, "desktop.yaml"), "theme: dark\n")
+		second, err := manager.CreateBackup(backup.BackupOptions{Type: "manual"})
+		Expect(err).NotTo(HaveOccurred())
+
+		comparison, err := manager.CompareBackups(first.ID, second.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(comparison.AddedFiles).To(Equal([]string{"config/desktop.yaml"}))
+		Expect(comparison.RemovedFiles).To(Equal([]string{"config/applications.yaml"}))
+		Expect(comparison.ModifiedFiles).To(Equal([]string{"config/system.yaml"}))
+	})
+
+	It("removes chunks of deleted backups", func() {
+		created, err := manager.CreateBackup(backup.BackupOptions{Type: "manual"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(manager.DeleteBackup(created.ID)).To(Succeed())
+		chunks, err := backup.NewLocalTarget(storeDir).List(context.Background(), "chunks")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(chunks).To(BeEmpty())
+
+		_, err = manager.GetBackup(created.ID)
+		Expect(err).To(MatchError(ContainSubstring("not found")))
+	})
+
+	It("prunes automatic backups beyond the retention limit", func() {
+		for i := 0; i < 3; i++ {
+			writeFile(filepath.Join(configDir, "system.yaml"), "shell: zsh\n"+string(rune('a'+i)))
+			_, err := manager.CreateBackup(backup.BackupOptions{Type: "auto", MaxBackups: 2})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		backups, err := manager.ListBackups("", 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(backups).To(HaveLen(2))
+
+		chunks, err := backup.NewLocalTarget(storeDir).List(context.Background(), "chunks")
+		Expect(err).NotTo(HaveOccurred())
+		// applications.yaml is shared, system.yaml differs in each remaining backup
+		Expect(chunks).To(HaveLen(3))
+	})
+
+	It("lists snapshots created by another machine", func() {
+		other := backup.NewBackupManagerWithStore(filepath.Join(GinkgoT().TempDir(), ".devex"), backup.StoreOptions{Target: backup.NewLocalTarget(storeDir)})
+		created, err := manager.CreateBackup(backup.BackupOptions{Type: "manual"})
+		Expect(err).NotTo(HaveOccurred())
+
+		backups, err := other.ListBackups("", 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(backups).To(HaveLen(1))
+		Expect(backups[0].ID).To(Equal(created.ID))
+	})
+
+	Context("with a backup in the legacy archive format", func() {
+		BeforeEach(func() {
+			writeLegacyBackup(baseDir, "backup-20240101-120000", map[string]string{
+				"applications.yaml": "applications: [git]\n",
+				"system.yaml":       "shell: bash\n",
+			})
+		})
+
+		It("restores it", func() {
+			Expect(manager.RestoreBackup("backup-20240101-120000", "")).To(Succeed())
+			Expect(readFile(filepath.Join(configDir, "system.yaml"))).To(Equal("shell: bash\n"))
+		})
+
+		It("compares it with a chunked backup", func() {
+			created, err := manager.CreateBackup(backup.BackupOptions{Type: "manual"})
+			Expect(err).NotTo(HaveOccurred())
+
+			comparison, err := manager.CompareBackups("backup-20240101-120000", created.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(comparison.AddedFiles).To(BeEmpty())
+			Expect(comparison.RemovedFiles).To(BeEmpty())
+			Expect(comparison.ModifiedFiles).To(Equal([]string{"config/system.yaml"}))
+		})
+
+		It("lists and deletes it", func() {
+			backups, err := manager.ListBackups("", 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backups).To(HaveLen(1))
+
+			Expect(manager.DeleteBackup("backup-20240101-120000")).To(Succeed())
+			backups, err = manager.ListBackups("", 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backups).To(BeEmpty())
+		})
+	})
+})
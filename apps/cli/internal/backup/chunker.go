@@ -0,0 +1,102 @@
+package backup
+
+import (
+	"errors"
+	"io"
+)
+
+// Content defined chunking parameters. Boundaries depend only on the bytes around them, so
+// an edit in one part of a file leaves the chunks of the rest unchanged.
+const (
+	MinChunkSize = 16 * 1024
+	AvgChunkSize = 64 * 1024
+	MaxChunkSize = 256 * 1024
+
+	chunkMask = AvgChunkSize - 1
+)
+
+// gearTable maps bytes to the random values of the gear rolling hash. It is generated from a
+// fixed seed because changing it would change every chunk boundary.
+var gearTable = func() [256]uint64 {
+	var table [256]uint64
+	state := uint64(0x6465766578636463) // "devexcdc"
+	for i := range table {
+		// splitmix64
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+	return table
+}()
+
+// Chunker splits a stream into content defined chunks
+type Chunker struct {
+	reader io.Reader
+	buf    []byte
+	start  int
+	end    int
+	eof    bool
+}
+
+// NewChunker creates a chunker reading from r
+func NewChunker(r io.Reader) *Chunker {
+	return &Chunker{reader: r, buf: make([]byte, 2*MaxChunkSize)}
+}
+
+// Next returns the next chunk, or io.EOF after the last one. The chunk is only valid until
+// the next call.
+func (c *Chunker) Next() ([]byte, error) {
+	if err := c.fill(); err != nil {
+		return nil, err
+	}
+	if c.start == c.end {
+		return nil, io.EOF
+	}
+
+	data := c.buf[c.start:c.end]
+	n := chunkBoundary(data)
+	chunk := data[:n]
+	c.start += n
+	return chunk, nil
+}
+
+// fill reads until the buffer holds a full maximum sized chunk or the stream ends
+func (c *Chunker) fill() error {
+	if c.end-c.start >= MaxChunkSize || c.eof {
+		return nil
+	}
+	if c.start > 0 {
+		copy(c.buf, c.buf[c.start:c.end])
+		c.end -= c.start
+		c.start = 0
+	}
+	for c.end < MaxChunkSize && !c.eof {
+		n, err := c.reader.Read(c.buf[c.end:])
+		c.end += n
+		if errors.Is(err, io.EOF) {
+			c.eof = true
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunkBoundary returns the length of the first chunk of data
+func chunkBoundary(data []byte) int {
+	if len(data) <= MinChunkSize {
+		return len(data)
+	}
+	limit := min(len(data), MaxChunkSize)
+
+	var hash uint64
+	for i := MinChunkSize; i < limit; i++ {
+		hash = (hash << 1) + gearTable[data[i]]
+		if hash&chunkMask == 0 {
+			return i + 1
+		}
+	}
+	return limit
+}
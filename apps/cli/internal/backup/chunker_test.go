@@ -0,0 +1,72 @@
+package backup_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/backup"
+)
+
+// chunks splits data and returns copies of the chunks
+func chunks(data []byte) [][]byte {
+	chunker := backup.NewChunker(bytes.NewReader(data))
+	var result [][]byte
+	for {
+		chunk, err := chunker.Next()
+		if errors.Is(err, io.EOF) {
+			return result
+		}
+		Expect(err).NotTo(HaveOccurred())
+		result = append(result, append([]byte(nil), chunk...))
+	}
+}
+
+var _ = Describe("Chunker", func() {
+	var data []byte
+
+	BeforeEach(func() {
+		data = make([]byte, 2*1024*1024)
+		rand.New(rand.NewSource(1)).Read(data)
+	})
+
+	It("splits content into chunks that reassemble it", func() {
+		result := chunks(data)
+		Expect(len(result)).To(BeNumerically(">", 1))
+		for _, chunk := range result[:len(result)-1] {
+			Expect(len(chunk)).To(BeNumerically(">=", backup.MinChunkSize))
+			Expect(len(chunk)).To(BeNumerically("<=", backup.MaxChunkSize))
+		}
+		Expect(bytes.Join(result, nil)).To(Equal(data))
+	})
+
+	It("returns small files as a single chunk", func() {
+		Expect(chunks([]byte("export EDITOR=vim\n"))).To(HaveLen(1))
+		Expect(chunks(nil)).To(BeEmpty())
+	})
+
+	It("keeps the chunks away from an edit unchanged", func() {
+		before := chunks(data)
+
+		edited := append([]byte(nil), data[:1024*1024]...)
+		edited = append(edited, []byte("inserted line\n")...)
+		edited = append(edited, data[1024*1024:]...)
+		after := chunks(edited)
+
+		unchanged := 0
+		seen := make(map[string]bool)
+		for _, chunk := range before {
+			seen[string(chunk)] = true
+		}
+		for _, chunk := range after {
+			if seen[string(chunk)] {
+				unchanged++
+			}
+		}
+		Expect(unchanged).To(BeNumerically(">=", len(before)-2))
+	})
+})
@@ -0,0 +1,467 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Environment variables configuring where backups are stored and how they are encrypted
+const (
+	EnvBackupTarget     = "DEVEX_BACKUP_TARGET"     // see ParseTarget, defaults to ~/.devex/backups/store
+	EnvBackupPassphrase = "DEVEX_BACKUP_PASSPHRASE" // encrypts a new store with a passphrase
+	EnvBackupIdentity   = "DEVEX_BACKUP_IDENTITY"   // encrypts a new store to an age identity file
+)
+
+const (
+	// FormatChunked marks backups kept as snapshots in the chunk store. Backups without a
+	// format are directories of the legacy archive format.
+	FormatChunked = "chunked"
+	StoreDir      = "store"
+	StagingDir    = "staging"
+	// configRoot names the DevEx configuration directory in snapshots
+	configRoot = "config"
+)
+
+// Manifest describes the files of a snapshot and the chunks holding their content
+type Manifest struct {
+	Metadata BackupMetadata  `json:"metadata"`
+	Roots    []SnapshotRoot  `json:"roots"`
+	Entries  []SnapshotEntry `json:"entries"`
+}
+
+// SnapshotRoot is a file or directory captured by a snapshot. Name is "config" for the
+// DevEx configuration directory, and the path with the home directory shortened to "~"
+// otherwise, so snapshots restore into the home directory of another machine.
+type SnapshotRoot struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// SnapshotEntry is a file of a snapshot
+type SnapshotEntry struct {
+	Root    int         `json:"root"`
+	Rel     string      `json:"rel,omitempty"` // Slash-separated path below the root, empty when the root is a file
+	Mode    os.FileMode `json:"mode"`
+	Size    int64       `json:"size"`
+	ModTime time.Time   `json:"mod_time"`
+	Hash    string      `json:"hash"` // SHA-256 of the content
+	Chunks  []string    `json:"chunks"`
+}
+
+// Name returns the path of the entry as listed in BackupMetadata.Files
+func (m *Manifest) Name(entry SnapshotEntry) string {
+	name := m.Roots[entry.Root].Name
+	if entry.Rel == "" {
+		return name
+	}
+	return name + "/" + entry.Rel
+}
+
+// StoreOptionsFromEnv configures the chunk store of baseDir from the environment
+func StoreOptionsFromEnv(baseDir string) (StoreOptions, error) {
+	backupsDir := filepath.Join(baseDir, BackupsDir)
+	spec := os.Getenv(EnvBackupTarget)
+	if spec == "" {
+		spec = filepath.Join(backupsDir, StoreDir)
+	}
+
+	target, err := ParseTarget(spec, filepath.Join(backupsDir, StagingDir))
+	if err != nil {
+		return StoreOptions{}, err
+	}
+	return StoreOptions{
+		Target:       target,
+		Passphrase:   os.Getenv(EnvBackupPassphrase),
+		IdentityFile: os.Getenv(EnvBackupIdentity),
+	}, nil
+}
+
+// openStore opens the chunk store once and keeps it for later operations
+func (bm *BackupManager) openStore(create bool) (*ChunkStore, error) {
+	if bm.store != nil {
+		return bm.store, nil
+	}
+	if bm.storeErr != nil {
+		return nil, bm.storeErr
+	}
+	store, err := OpenChunkStore(context.Background(), bm.storeOptions, create)
+	if err != nil {
+		return nil, err
+	}
+	bm.store = store
+	return store, nil
+}
+
+// rootName returns the snapshot name of an extra backup path
+func rootName(path string) string {
+	if home, err := os.UserHomeDir(); err == nil {
+		if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+			if rel == "." {
+				return "~"
+			}
+			return "~/" + filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// rootPath returns where a snapshot root below the home directory is restored on this
+// machine, and false for roots outside of it
+func rootPath(root SnapshotRoot) (string, bool, error) {
+	rest, found := strings.CutPrefix(root.Name, "~")
+	if !found || (rest != "" && rest[0] != '/') {
+		return "", false, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", true, fmt.Errorf("failed to resolve %s: %w", root.Name, err)
+	}
+	path := filepath.Join(home, filepath.FromSlash(rest))
+	if rel, err := filepath.Rel(home, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", true, fmt.Errorf("security violation: %s resolves outside of %s", root.Name, home)
+	}
+	return path, true, nil
+}
+
+// restoreRoots returns where each root of a snapshot is restored. The configuration goes
+// to configDir and roots below the home directory into the home directory of this machine.
+// Other roots must be absolute paths the local index records as backed up by backupID,
+// since the manifest may come from a shared store; remaining paths are confirmed through
+// SetPathConfirmation or refused.
+func (bm *BackupManager) restoreRoots(backupID string, manifest *Manifest, configDir string) ([]string, error) {
+	recorded := map[string]bool{}
+	if index, err := bm.readGlobalMetadata(); err == nil {
+		for _, metadata := range index {
+			if metadata.ID == backupID {
+				for _, path := range metadata.Paths {
+					recorded[filepath.Clean(path)] = true
+				}
+			}
+		}
+	}
+
+	dests := make([]string, len(manifest.Roots))
+	var unconfirmed []string
+	for i, root := range manifest.Roots {
+		if root.Name == configRoot {
+			dests[i] = configDir
+			continue
+		}
+		path, home, err := rootPath(root)
+		if err != nil {
+			return nil, err
+		}
+		if home {
+			dests[i] = path
+			continue
+		}
+		if !filepath.IsAbs(root.Path) || filepath.ToSlash(filepath.Clean(root.Path)) != root.Name {
+			return nil, fmt.Errorf("security violation: invalid root %s in backup %s", root.Name, backupID)
+		}
+		dests[i] = filepath.Clean(root.Path)
+		if !recorded[dests[i]] {
+			unconfirmed = append(unconfirmed, dests[i])
+		}
+	}
+
+	if len(unconfirmed) > 0 && (bm.confirmPaths == nil || !bm.confirmPaths(unconfirmed)) {
+		return nil, fmt.Errorf("backup %s restores paths that were not backed up from this machine: %s", backupID, strings.Join(unconfirmed, ", "))
+	}
+	return dests, nil
+}
+
+// snapshotRoots returns the roots captured by a backup with options
+func (bm *BackupManager) snapshotRoots(options BackupOptions) ([]SnapshotRoot, error) {
+	roots := []SnapshotRoot{{Name: configRoot, Path: filepath.Join(bm.baseDir, "config")}}
+	seen := map[string]bool{configRoot: true}
+	for _, p := range options.Paths {
+		abs, err := filepath.Abs(expandHome(p))
+		if err != nil {
+			return nil, fmt.Errorf("invalid backup path %s: %w", p, err)
+		}
+		name := rootName(abs)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		roots = append(roots, SnapshotRoot{Name: name, Path: abs})
+	}
+	return roots, nil
+}
+
+// expandHome expands a leading ~ to the home directory
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// createSnapshot stores the files of roots in the chunk store
+func (bm *BackupManager) createSnapshot(store *ChunkStore, metadata *BackupMetadata, roots []SnapshotRoot, options BackupOptions) (*Manifest, error) {
+	ctx := context.Background()
+	manifest := &Manifest{Roots: roots}
+
+	for i, root := range roots {
+		info, err := os.Stat(root.Path)
+		if err != nil {
+			if i > 0 && errors.Is(err, os.ErrNotExist) {
+				// Extra paths are optional, a dotfile may not exist on every machine
+				continue
+			}
+			return nil, err
+		}
+
+		var files []string
+		if info.IsDir() {
+			files, err = bm.collectFiles(root.Path, options.Include, options.Exclude)
+			if err != nil {
+				return nil, fmt.Errorf("failed to collect files from %s (include: %v, exclude: %v): %w", root.Path, options.Include, options.Exclude, err)
+			}
+		} else {
+			files = []string{""}
+		}
+
+		for _, rel := range files {
+			entry, stored, err := bm.storeFile(ctx, store, filepath.Join(root.Path, rel))
+			if err != nil {
+				return nil, fmt.Errorf("failed to back up %s: %w", filepath.Join(root.Path, rel), err)
+			}
+			entry.Root = i
+			entry.Rel = filepath.ToSlash(rel)
+			manifest.Entries = append(manifest.Entries, entry)
+			metadata.Files = append(metadata.Files, manifest.Name(entry))
+			metadata.Size += entry.Size
+			metadata.StoredSize += stored
+		}
+	}
+
+	manifest.Metadata = *metadata
+	if err := store.PutSnapshot(ctx, manifest); err != nil {
+		return nil, fmt.Errorf("failed to store snapshot in %s: %w", store.Target(), err)
+	}
+	return manifest, nil
+}
+
+// storeFile stores the content of a file and returns its entry and the bytes uploaded
+func (bm *BackupManager) storeFile(ctx context.Context, store *ChunkStore, path string) (SnapshotEntry, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return SnapshotEntry{}, 0, err
+	}
+	defer func() { _ = file.Close() }()
+
+	info, err := file.Stat()
+	if err != nil {
+		return SnapshotEntry{}, 0, err
+	}
+	chunks, hash, size, stored, err := store.PutFile(ctx, file)
+	if err != nil {
+		return SnapshotEntry{}, 0, err
+	}
+	return SnapshotEntry{
+		Mode:    info.Mode().Perm(),
+		Size:    size,
+		ModTime: info.ModTime().UTC(),
+		Hash:    hash,
+		Chunks:  chunks,
+	}, stored, nil
+}
+
+// getSnapshot reads the manifest of a chunked backup
+func (bm *BackupManager) getSnapshot(backupID string) (*Manifest, error) {
+	store, err := bm.openStore(false)
+	if err != nil {
+		if errors.Is(err, ErrStoreNotInitialized) {
+			return nil, fmt.Errorf("backup %s not found in %s", backupID, bm.backupsDir)
+		}
+		return nil, err
+	}
+	manifest, err := store.GetSnapshot(context.Background(), backupID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("backup %s not found in %s or %s", backupID, bm.backupsDir, store.Target())
+	}
+	return manifest, err
+}
+
+// restoreSnapshot writes the files of a snapshot back, each root to its destination in
+// dests as returned by restoreRoots
+func (bm *BackupManager) restoreSnapshot(manifest *Manifest, dests []string) error {
+	store, err := bm.openStore(false)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	if len(manifest.Entries) > MaxFiles {
+		return fmt.Errorf("too many files in backup (limit: %d)", MaxFiles)
+	}
+	var totalSize int64
+	var chunks []string
+	for _, entry := range manifest.Entries {
+		if entry.Root < 0 || entry.Root >= len(manifest.Roots) {
+			return fmt.Errorf("invalid root %d in backup %s", entry.Root, manifest.Metadata.ID)
+		}
+		if entry.Size > MaxFileSize {
+			return fmt.Errorf("file %s exceeds maximum size limit (%d bytes)", manifest.Name(entry), MaxFileSize)
+		}
+		totalSize += entry.Size
+		if totalSize > MaxTotalSize {
+			return fmt.Errorf("total restore size exceeds limit (%d bytes)", MaxTotalSize)
+		}
+		chunks = append(chunks, entry.Chunks...)
+	}
+	if err := store.Prefetch(ctx, chunks); err != nil {
+		return fmt.Errorf("failed to fetch backup %s: %w", manifest.Metadata.ID, err)
+	}
+
+	for _, entry := range manifest.Entries {
+		dest := dests[entry.Root]
+		target := dest
+		if entry.Rel != "" {
+			rel := filepath.FromSlash(entry.Rel)
+			// Security check: prevent file traversal attacks
+			if err := bm.validatePath(rel, dest); err != nil {
+				return fmt.Errorf("security violation: %w", err)
+			}
+			target = filepath.Join(dest, rel)
+		}
+
+		if err := bm.restoreFile(ctx, store, entry, target); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", manifest.Name(entry), err)
+		}
+	}
+	return nil
+}
+
+// restoreFile assembles a file from its chunks next to target, checks its hash and renames
+// it into place
+func (bm *BackupManager) restoreFile(ctx context.Context, store *ChunkStore, entry SnapshotEntry, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".devex-restore-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	hash := sha256.New()
+	var written int64
+	for _, id := range entry.Chunks {
+		data, err := store.GetChunk(ctx, id)
+		if err != nil {
+			_ = tmp.Close()
+			return err
+		}
+		written += int64(len(data))
+		if written > entry.Size {
+			_ = tmp.Close()
+			return fmt.Errorf("content is larger than the recorded %d bytes", entry.Size)
+		}
+		hash.Write(data)
+		if _, err := tmp.Write(data); err != nil {
+			_ = tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != entry.Hash {
+		return fmt.Errorf("content hash %s does not match the recorded %s", sum, entry.Hash)
+	}
+
+	if err := os.Chmod(tmp.Name(), entry.Mode.Perm()); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return err
+	}
+	return os.Chtimes(target, entry.ModTime, entry.ModTime)
+}
+
+// snapshotHashes returns the content hash of every file of a chunked backup
+func (bm *BackupManager) snapshotHashes(backupID string) (map[string]string, error) {
+	manifest, err := bm.getSnapshot(backupID)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make(map[string]string, len(manifest.Entries))
+	for _, entry := range manifest.Entries {
+		hashes[manifest.Name(entry)] = entry.Hash
+	}
+	return hashes, nil
+}
+
+// deleteSnapshot removes a chunked backup without pruning its chunks
+func (bm *BackupManager) deleteSnapshot(backupID string) error {
+	store, err := bm.openStore(false)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	key, err := snapshotKey(backupID)
+	if err != nil {
+		return err
+	}
+	if _, err := store.Target().Get(ctx, key); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("backup %s not found in %s or %s", backupID, bm.backupsDir, store.Target())
+		}
+		return err
+	}
+	return store.DeleteSnapshot(ctx, backupID)
+}
+
+// pruneChunks removes chunks no longer referenced by any snapshot
+func (bm *BackupManager) pruneChunks() error {
+	store, err := bm.openStore(false)
+	if err != nil {
+		return err
+	}
+	if _, err := store.Prune(context.Background()); err != nil {
+		return fmt.Errorf("failed to prune unreferenced chunks from %s: %w", store.Target(), err)
+	}
+	return nil
+}
+
+// listSnapshots returns the metadata of snapshots on the target that are missing from the
+// local index, such as those created on another machine
+func (bm *BackupManager) listSnapshots(indexed map[string]bool) ([]*BackupMetadata, error) {
+	store, err := bm.openStore(false)
+	if errors.Is(err, ErrStoreNotInitialized) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	ids, err := store.ListSnapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var backups []*BackupMetadata
+	for _, id := range ids {
+		if indexed[id] {
+			continue
+		}
+		manifest, err := store.GetSnapshot(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		metadata := manifest.Metadata
+		backups = append(backups, &metadata)
+	}
+	return backups, nil
+}
@@ -0,0 +1,557 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"filippo.io/age"
+)
+
+// Layout of a chunk store on its target
+const (
+	storeConfigKey  = "config"
+	storeKeyKey     = "keys/master.age"
+	chunksPrefix    = "chunks"
+	snapshotsPrefix = "snapshots"
+	storeVersion    = 1
+)
+
+// Encryption modes of a chunk store
+const (
+	EncryptionNone       = "none"
+	EncryptionPassphrase = "passphrase"
+	EncryptionIdentity   = "identity"
+)
+
+// ErrStoreNotInitialized is returned when opening a target that holds no chunk store
+var ErrStoreNotInitialized = errors.New("backup store not initialized")
+
+// StoreOptions configures where a chunk store lives and how it is encrypted. Encryption is
+// chosen when the store is created; later opens must supply the matching secret.
+type StoreOptions struct {
+	Target       Target
+	Passphrase   string // Encrypts the store with a passphrase
+	IdentityFile string // Encrypts the store to the X25519 identity in an age identity file
+}
+
+// storeConfig is the unencrypted description of a chunk store
+type storeConfig struct {
+	Version    int       `json:"version"`
+	Encryption string    `json:"encryption"`
+	Created    time.Time `json:"created"`
+}
+
+// ChunkStore is a content addressed store of deduplicated, compressed and optionally
+// encrypted chunks, together with the snapshot manifests referencing them.
+//
+// When encrypted, every chunk and manifest is encrypted with age to a store key generated
+// when the store was created. The store key itself is kept on the target encrypted with the
+// passphrase or identity. Chunk names are keyed hashes so they reveal nothing about file
+// contents.
+//
+// Pruning assumes a single writer: a backup running concurrently on another machine may
+// reuse a chunk that pruning is removing.
+type ChunkStore struct {
+	target     Target
+	encryption string
+	identity   *age.X25519Identity
+	idKey      []byte
+
+	mu    sync.Mutex
+	known map[string]bool
+}
+
+// OpenChunkStore opens the chunk store on options.Target. When the target holds no store it
+// is created if create is set, otherwise ErrStoreNotInitialized is returned.
+func OpenChunkStore(ctx context.Context, options StoreOptions, create bool) (*ChunkStore, error) {
+	if options.Target == nil {
+		return nil, fmt.Errorf("no backup target configured")
+	}
+	if options.Passphrase != "" && options.IdentityFile != "" {
+		return nil, fmt.Errorf("use either a passphrase or an identity file to encrypt backups, not both")
+	}
+
+	data, err := options.Target.Get(ctx, storeConfigKey)
+	if errors.Is(err, ErrNotFound) {
+		if !create {
+			return nil, fmt.Errorf("%s: %w", options.Target, ErrStoreNotInitialized)
+		}
+		return initChunkStore(ctx, options)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup store configuration from %s: %w", options.Target, err)
+	}
+
+	var config storeConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid backup store configuration in %s: %w", options.Target, err)
+	}
+	if config.Version > storeVersion {
+		return nil, fmt.Errorf("backup store %s has version %d, this DevEx supports up to %d", options.Target, config.Version, storeVersion)
+	}
+
+	store := &ChunkStore{target: options.Target, encryption: config.Encryption}
+	if config.Encryption == EncryptionNone {
+		if options.Passphrase != "" || options.IdentityFile != "" {
+			return nil, fmt.Errorf("backup store %s is not encrypted; point the backup target at a new location to create an encrypted store", options.Target)
+		}
+		return store, nil
+	}
+
+	identities, err := keyIdentities(config.Encryption, options)
+	if err != nil {
+		return nil, fmt.Errorf("backup store %s: %w", options.Target, err)
+	}
+	sealed, err := options.Target.Get(ctx, storeKeyKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the key of backup store %s: %w", options.Target, err)
+	}
+	reader, err := age.Decrypt(bytes.NewReader(sealed), identities...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unlock backup store %s, check the %s: %w", options.Target, config.Encryption, err)
+	}
+	key, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unlock backup store %s: %w", options.Target, err)
+	}
+	if err := store.setKey(strings.TrimSpace(string(key))); err != nil {
+		return nil, fmt.Errorf("invalid key in backup store %s: %w", options.Target, err)
+	}
+	return store, nil
+}
+
+// initChunkStore creates a new chunk store on options.Target
+func initChunkStore(ctx context.Context, options StoreOptions) (*ChunkStore, error) {
+	config := storeConfig{Version: storeVersion, Encryption: EncryptionNone, Created: time.Now().UTC()}
+	store := &ChunkStore{target: options.Target, known: map[string]bool{}}
+
+	var recipient age.Recipient
+	switch {
+	case options.Passphrase != "":
+		config.Encryption = EncryptionPassphrase
+		scrypt, err := age.NewScryptRecipient(options.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		recipient = scrypt
+	case options.IdentityFile != "":
+		config.Encryption = EncryptionIdentity
+		identities, err := readIdentityFile(options.IdentityFile)
+		if err != nil {
+			return nil, err
+		}
+		x25519, ok := identities[0].(*age.X25519Identity)
+		if !ok {
+			return nil, fmt.Errorf("identity file %s must start with an X25519 identity (AGE-SECRET-KEY-1...)", options.IdentityFile)
+		}
+		recipient = x25519.Recipient()
+	}
+	store.encryption = config.Encryption
+
+	if recipient != nil {
+		identity, err := age.GenerateX25519Identity()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate backup store key: %w", err)
+		}
+		var sealed bytes.Buffer
+		writer, err := age.Encrypt(&sealed, recipient)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(writer, identity.String()); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		if err := options.Target.Put(ctx, storeKeyKey, sealed.Bytes()); err != nil {
+			return nil, fmt.Errorf("failed to store the key of backup store %s: %w", options.Target, err)
+		}
+		if err := store.setKey(identity.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := options.Target.Put(ctx, storeConfigKey, data); err != nil {
+		return nil, fmt.Errorf("failed to create backup store in %s: %w", options.Target, err)
+	}
+	if err := options.Target.Flush(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create backup store in %s: %w", options.Target, err)
+	}
+	return store, nil
+}
+
+// keyIdentities returns the identities unlocking the store key for the given encryption mode
+func keyIdentities(encryption string, options StoreOptions) ([]age.Identity, error) {
+	switch encryption {
+	case EncryptionPassphrase:
+		if options.Passphrase == "" {
+			return nil, fmt.Errorf("store is encrypted with a passphrase; set %s", EnvBackupPassphrase)
+		}
+		identity, err := age.NewScryptIdentity(options.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return []age.Identity{identity}, nil
+	case EncryptionIdentity:
+		if options.IdentityFile == "" {
+			return nil, fmt.Errorf("store is encrypted with an age identity; set %s", EnvBackupIdentity)
+		}
+		return readIdentityFile(options.IdentityFile)
+	default:
+		return nil, fmt.Errorf("unknown encryption %q", encryption)
+	}
+}
+
+// readIdentityFile parses an age identity file
+func readIdentityFile(path string) ([]age.Identity, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open identity file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	identities, err := age.ParseIdentities(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse identity file %s: %w", path, err)
+	}
+	return identities, nil
+}
+
+// setKey installs the store key used to encrypt chunks and to name them
+func (s *ChunkStore) setKey(key string) error {
+	identity, err := age.ParseX25519Identity(key)
+	if err != nil {
+		return err
+	}
+	mac := sha256.Sum256([]byte("devex-backup-chunk-id\x00" + key))
+	s.identity = identity
+	s.idKey = mac[:]
+	return nil
+}
+
+// Encryption returns how the store is encrypted
+func (s *ChunkStore) Encryption() string {
+	return s.encryption
+}
+
+// Target returns where the store lives
+func (s *ChunkStore) Target() Target {
+	return s.target
+}
+
+// chunkID names a chunk by its content, with a keyed hash when the store is encrypted
+func (s *ChunkStore) chunkID(data []byte) string {
+	if s.idKey == nil {
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:])
+	}
+	mac := hmac.New(sha256.New, s.idKey)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// chunkKey returns the storage key of a chunk
+func chunkKey(id string) string {
+	return path.Join(chunksPrefix, id[:2], id)
+}
+
+// snapshotKey returns the storage key of a snapshot manifest
+func snapshotKey(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("invalid backup ID %q", id)
+	}
+	return path.Join(snapshotsPrefix, id+".json"), nil
+}
+
+// seal compresses and, when the store is encrypted, encrypts data for storage
+func (s *ChunkStore) seal(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var out io.WriteCloser = nopWriteCloser{&buf}
+	if s.identity != nil {
+		writer, err := age.Encrypt(&buf, s.identity.Recipient())
+		if err != nil {
+			return nil, err
+		}
+		out = writer
+	}
+
+	gz := gzip.NewWriter(out)
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	if err := out.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// open reverses seal
+func (s *ChunkStore) open(data []byte) ([]byte, error) {
+	var in io.Reader = bytes.NewReader(data)
+	if s.identity != nil {
+		reader, err := age.Decrypt(in, s.identity)
+		if err != nil {
+			return nil, err
+		}
+		in = reader
+	}
+
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = gz.Close() }()
+	return io.ReadAll(io.LimitReader(gz, MaxFileSize+1))
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// loadKnown lists the chunks already on the target so they are not uploaded again
+func (s *ChunkStore) loadKnown(ctx context.Context) (map[string]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.known != nil {
+		return s.known, nil
+	}
+
+	keys, err := s.target.List(ctx, chunksPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunks in %s: %w", s.target, err)
+	}
+	s.known = make(map[string]bool, len(keys))
+	for _, key := range keys {
+		s.known[path.Base(key)] = true
+	}
+	return s.known, nil
+}
+
+// PutChunk stores a chunk unless the store already holds it and returns its ID together
+// with the number of bytes uploaded
+func (s *ChunkStore) PutChunk(ctx context.Context, data []byte) (string, int64, error) {
+	known, err := s.loadKnown(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+
+	id := s.chunkID(data)
+	s.mu.Lock()
+	exists := known[id]
+	s.mu.Unlock()
+	if exists {
+		return id, 0, nil
+	}
+
+	sealed, err := s.seal(data)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to encode chunk: %w", err)
+	}
+	if err := s.target.Put(ctx, chunkKey(id), sealed); err != nil {
+		return "", 0, err
+	}
+
+	s.mu.Lock()
+	known[id] = true
+	s.mu.Unlock()
+	return id, int64(len(sealed)), nil
+}
+
+// GetChunk returns the content of a chunk after checking it matches its ID
+func (s *ChunkStore) GetChunk(ctx context.Context, id string) ([]byte, error) {
+	if len(id) != sha256.Size*2 {
+		return nil, fmt.Errorf("invalid chunk ID %q", id)
+	}
+	sealed, err := s.target.Get(ctx, chunkKey(id))
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.open(sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode chunk %s: %w", id, err)
+	}
+	if s.chunkID(data) != id {
+		return nil, fmt.Errorf("chunk %s is corrupted", id)
+	}
+	return data, nil
+}
+
+// Prefetch fetches the given chunks ahead of reading them when the target supports it
+func (s *ChunkStore) Prefetch(ctx context.Context, ids []string) error {
+	prefetcher, ok := s.target.(Prefetcher)
+	if !ok {
+		return nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		if len(id) != sha256.Size*2 {
+			return fmt.Errorf("invalid chunk ID %q", id)
+		}
+		keys[i] = chunkKey(id)
+	}
+	return prefetcher.Prefetch(ctx, keys)
+}
+
+// PutFile splits r into chunks, stores them and returns the chunk IDs, the SHA-256 of the
+// content, its size and the number of bytes uploaded
+func (s *ChunkStore) PutFile(ctx context.Context, r io.Reader) ([]string, string, int64, int64, error) {
+	hash := sha256.New()
+	chunker := NewChunker(io.TeeReader(r, hash))
+
+	var ids []string
+	var size, stored int64
+	for {
+		chunk, err := chunker.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, "", 0, 0, err
+		}
+		id, n, err := s.PutChunk(ctx, chunk)
+		if err != nil {
+			return nil, "", 0, 0, err
+		}
+		ids = append(ids, id)
+		size += int64(len(chunk))
+		stored += n
+	}
+	return ids, hex.EncodeToString(hash.Sum(nil)), size, stored, nil
+}
+
+// PutSnapshot makes the uploaded chunks durable and then stores the manifest, so a manifest
+// never references chunks missing from the target
+func (s *ChunkStore) PutSnapshot(ctx context.Context, manifest *Manifest) error {
+	key, err := snapshotKey(manifest.Metadata.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.target.Flush(ctx); err != nil {
+		return fmt.Errorf("failed to upload chunks to %s: %w", s.target, err)
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	sealed, err := s.seal(data)
+	if err != nil {
+		return err
+	}
+	if err := s.target.Put(ctx, key, sealed); err != nil {
+		return err
+	}
+	return s.target.Flush(ctx)
+}
+
+// GetSnapshot reads a snapshot manifest
+func (s *ChunkStore) GetSnapshot(ctx context.Context, id string) (*Manifest, error) {
+	key, err := snapshotKey(id)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.target.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.open(sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot %s: %w", id, err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %w", id, err)
+	}
+	return &manifest, nil
+}
+
+// ListSnapshots returns the IDs of the snapshots in the store
+func (s *ChunkStore) ListSnapshots(ctx context.Context) ([]string, error) {
+	keys, err := s.target.List(ctx, snapshotsPrefix)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if id, ok := strings.CutSuffix(path.Base(key), ".json"); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// DeleteSnapshot removes a snapshot manifest. Its chunks stay until Prune.
+func (s *ChunkStore) DeleteSnapshot(ctx context.Context, id string) error {
+	key, err := snapshotKey(id)
+	if err != nil {
+		return err
+	}
+	if err := s.target.Delete(ctx, key); err != nil {
+		return err
+	}
+	return s.target.Flush(ctx)
+}
+
+// Prune removes the chunks no snapshot references and returns how many were removed
+func (s *ChunkStore) Prune(ctx context.Context) (int, error) {
+	ids, err := s.ListSnapshots(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	referenced := make(map[string]bool)
+	for _, id := range ids {
+		manifest, err := s.GetSnapshot(ctx, id)
+		if err != nil {
+			// Keep every chunk rather than deleting chunks an unreadable snapshot may use
+			return 0, fmt.Errorf("failed to read snapshot %s, not pruning: %w", id, err)
+		}
+		for _, entry := range manifest.Entries {
+			for _, chunk := range entry.Chunks {
+				referenced[chunk] = true
+			}
+		}
+	}
+
+	keys, err := s.target.List(ctx, chunksPrefix)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, key := range keys {
+		id := path.Base(key)
+		if referenced[id] {
+			continue
+		}
+		if err := s.target.Delete(ctx, key); err != nil {
+			return removed, err
+		}
+		s.mu.Lock()
+		delete(s.known, id)
+		s.mu.Unlock()
+		removed++
+	}
+	return removed, s.target.Flush(ctx)
+}
@@ -0,0 +1,235 @@
+package backup_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/backup"
+)
+
+var _ = Describe("Chunk store", func() {
+	var (
+		ctx    context.Context
+		target *backup.LocalTarget
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		target = backup.NewLocalTarget(GinkgoT().TempDir())
+	})
+
+	It("requires initialization unless creating", func() {
+		_, err := backup.OpenChunkStore(ctx, backup.StoreOptions{Target: target}, false)
+		Expect(err).To(MatchError(backup.ErrStoreNotInitialized))
+	})
+
+	It("uploads each chunk once", func() {
+		store, err := backup.OpenChunkStore(ctx, backup.StoreOptions{Target: target}, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.Encryption()).To(Equal(backup.EncryptionNone))
+
+		content := []byte("alias ll='ls -la'\n")
+		ids, hash, size, stored, err := store.PutFile(ctx, bytes.NewReader(content))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids).To(HaveLen(1))
+		Expect(size).To(Equal(int64(len(content))))
+		Expect(stored).To(BeNumerically(">", 0))
+		sum := sha256.Sum256(content)
+		Expect(hash).To(Equal(hex.EncodeToString(sum[:])))
+
+		reopened, err := backup.OpenChunkStore(ctx, backup.StoreOptions{Target: target}, false)
+		Expect(err).NotTo(HaveOccurred())
+		again, _, _, stored, err := reopened.PutFile(ctx, bytes.NewReader(content))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(Equal(ids))
+		Expect(stored).To(BeZero())
+
+		data, err := reopened.GetChunk(ctx, ids[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(content))
+	})
+
+	It("detects corrupted chunks", func() {
+		store, err := backup.OpenChunkStore(ctx, backup.StoreOptions{Target: target}, true)
+		Expect(err).NotTo(HaveOccurred())
+		id, _, err := store.PutChunk(ctx, []byte("original"))
+		Expect(err).NotTo(HaveOccurred())
+
+		other, _, err := store.PutChunk(ctx, []byte("tampered"))
+		Expect(err).NotTo(HaveOccurred())
+		sealed, err := target.Get(ctx, "chunks/"+other[:2]+"/"+other)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(target.Put(ctx, "chunks/"+id[:2]+"/"+id, sealed)).To(Succeed())
+
+		_, err = store.GetChunk(ctx, id)
+		Expect(err).To(MatchError(ContainSubstring("corrupted")))
+	})
+
+	Context("encrypted with a passphrase", func() {
+		var store *backup.ChunkStore
+
+		BeforeEach(func() {
+			var err error
+			store, err = backup.OpenChunkStore(ctx, backup.StoreOptions{Target: target, Passphrase: "correct horse"}, true)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("hides chunk contents and names", func() {
+			content := []byte("GITHUB_TOKEN=ghp_secretvalue\n")
+			id, _, err := store.PutChunk(ctx, content)
+			Expect(err).NotTo(HaveOccurred())
+
+			plain := sha256.Sum256(content)
+			Expect(id).NotTo(Equal(hex.EncodeToString(plain[:])))
+			sealed, err := target.Get(ctx, "chunks/"+id[:2]+"/"+id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(sealed)).NotTo(ContainSubstring("ghp_secretvalue"))
+
+			reopened, err := backup.OpenChunkStore(ctx, backup.StoreOptions{Target: target, Passphrase: "correct horse"}, false)
+			Expect(err).NotTo(HaveOccurred())
+			data, err := reopened.GetChunk(ctx, id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(Equal(content))
+		})
+
+		It("requires the passphrase", func() {
+			_, err := backup.OpenChunkStore(ctx, backup.StoreOptions{Target: target}, false)
+			Expect(err).To(MatchError(ContainSubstring(backup.EnvBackupPassphrase)))
+
+			_, err = backup.OpenChunkStore(ctx, backup.StoreOptions{Target: target, Passphrase: "wrong"}, false)
+			Expect(err).To(MatchError(ContainSubstring("failed to unlock")))
+		})
+	})
+
+	It("encrypts to an age identity", func() {
+		identity, err := age.GenerateX25519Identity()
+		Expect(err).NotTo(HaveOccurred())
+		identityFile := filepath.Join(GinkgoT().TempDir(), "key.txt")
+		Expect(os.WriteFile(identityFile, []byte(identity.String()+"\n"), 0600)).To(Succeed())
+
+		store, err := backup.OpenChunkStore(ctx, backup.StoreOptions{Target: target, IdentityFile: identityFile}, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.Encryption()).To(Equal(backup.EncryptionIdentity))
+		id, _, err := store.PutChunk(ctx, []byte("[user]\n\tname = Dev\n"))
+		Expect(err).NotTo(HaveOccurred())
+
+		reopened, err := backup.OpenChunkStore(ctx, backup.StoreOptions{Target: target, IdentityFile: identityFile}, false)
+		Expect(err).NotTo(HaveOccurred())
+		data, err := reopened.GetChunk(ctx, id)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(HavePrefix("[user]"))
+	})
+
+	It("refuses a passphrase for an unencrypted store", func() {
+		_, err := backup.OpenChunkStore(ctx, backup.StoreOptions{Target: target}, true)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = backup.OpenChunkStore(ctx, backup.StoreOptions{Target: target, Passphrase: "secret"}, false)
+		Expect(err).To(MatchError(ContainSubstring("not encrypted")))
+	})
+
+	It("prunes chunks no snapshot references", func() {
+		store, err := backup.OpenChunkStore(ctx, backup.StoreOptions{Target: target}, true)
+		Expect(err).NotTo(HaveOccurred())
+
+		kept, _, err := store.PutChunk(ctx, []byte("kept"))
+		Expect(err).NotTo(HaveOccurred())
+		_, _, err = store.PutChunk(ctx, []byte("orphaned"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.PutSnapshot(ctx, &backup.Manifest{
+			Metadata: backup.BackupMetadata{ID: "backup-1"},
+			Roots:    []backup.SnapshotRoot{{Name: "config", Path: "/config"}},
+			Entries:  []backup.SnapshotEntry{{Rel: "a.yaml", Chunks: []string{kept}}},
+		})).To(Succeed())
+
+		removed, err := store.Prune(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(removed).To(Equal(1))
+
+		keys, err := target.List(ctx, "chunks")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys).To(HaveLen(1))
+		Expect(strings.HasSuffix(keys[0], kept)).To(BeTrue())
+	})
+})
+
+var _ = Describe("ParseTarget", func() {
+	It("recognizes the kinds of targets", func() {
+		staging := GinkgoT().TempDir()
+
+		target, err := backup.ParseTarget("/srv/backups", staging)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(target).To(BeAssignableToTypeOf(&backup.LocalTarget{}))
+
+		target, err = backup.ParseTarget("dev@nas.local:backups/devex", staging)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(target).To(BeAssignableToTypeOf(&backup.RsyncTarget{}))
+		Expect(target.String()).To(Equal("dev@nas.local:backups/devex"))
+
+		target, err = backup.ParseTarget("ssh://dev@nas.local/srv/devex", staging)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(target.String()).To(Equal("dev@nas.local:/srv/devex"))
+
+		target, err = backup.ParseTarget("s3://devex-backups/laptop", staging)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(target.String()).To(Equal("s3://devex-backups/laptop"))
+
+		_, err = backup.ParseTarget("s3://", staging)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects keys escaping a local target", func() {
+		target := backup.NewLocalTarget(GinkgoT().TempDir())
+		Expect(target.Put(context.Background(), "../outside", []byte("x"))).NotTo(Succeed())
+	})
+})
+
+// The S3 target is tested against a local MinIO when DEVEX_TEST_S3_BUCKET names a bucket on
+// the server configured by DEVEX_BACKUP_S3_ENDPOINT, e.g.
+//
+//	docker run -p 9000:9000 minio/minio server /data
+//	DEVEX_BACKUP_S3_ENDPOINT=http://localhost:9000 AWS_ACCESS_KEY_ID=minioadmin \
+//	AWS_SECRET_ACCESS_KEY=minioadmin DEVEX_TEST_S3_BUCKET=devex go test ./internal/backup/
+var _ = Describe("S3 target", func() {
+	It("stores a chunk store in a bucket", func() {
+		bucket := os.Getenv("DEVEX_TEST_S3_BUCKET")
+		if bucket == "" {
+			Skip("DEVEX_TEST_S3_BUCKET is not set")
+		}
+		ctx := context.Background()
+		target, err := backup.NewS3TargetFromEnv(bucket, "devex-test-"+filepath.Base(GinkgoT().TempDir()))
+		Expect(err).NotTo(HaveOccurred())
+
+		store, err := backup.OpenChunkStore(ctx, backup.StoreOptions{Target: target, Passphrase: "minio"}, true)
+		Expect(err).NotTo(HaveOccurred())
+		id, _, err := store.PutChunk(ctx, []byte("export EDITOR=nvim\n"))
+		Expect(err).NotTo(HaveOccurred())
+
+		reopened, err := backup.OpenChunkStore(ctx, backup.StoreOptions{Target: target, Passphrase: "minio"}, false)
+		Expect(err).NotTo(HaveOccurred())
+		data, err := reopened.GetChunk(ctx, id)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("export EDITOR=nvim\n"))
+
+		_, err = target.Get(ctx, "chunks/missing")
+		Expect(err).To(MatchError(backup.ErrNotFound))
+
+		for _, prefix := range []string{"chunks", "keys"} {
+			keys, err := target.List(ctx, prefix)
+			Expect(err).NotTo(HaveOccurred())
+			for _, key := range keys {
+				Expect(target.Delete(ctx, key)).To(Succeed())
+			}
+		}
+		Expect(target.Delete(ctx, "config")).To(Succeed())
+	})
+})
@@ -0,0 +1,190 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ErrNotFound is returned by a Target for keys it does not hold
+var ErrNotFound = errors.New("not found")
+
+// Target is a storage location for a chunk store. Keys are slash-separated paths such as
+// "chunks/ab/abcdef" relative to the root of the target.
+type Target interface {
+	// Put stores data under key, replacing any previous value
+	Put(ctx context.Context, key string, data []byte) error
+	// Get returns the data stored under key, or an error wrapping ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete removes key; removing a missing key is not an error
+	Delete(ctx context.Context, key string) error
+	// List returns the keys below prefix
+	List(ctx context.Context, prefix string) ([]string, error)
+	// Flush makes everything Put so far durable on the target
+	Flush(ctx context.Context) error
+	// String describes the target for messages
+	String() string
+}
+
+// scpLikeTarget matches rsync style remote paths such as user@host:backups
+var scpLikeTarget = regexp.MustCompile(`^(?:[\w.-]+@)?[\w.-]+:`)
+
+// ParseTarget creates the target described by spec:
+//
+//	/path/to/dir or file:///path/to/dir    a local directory
+//	user@host:path or ssh://user@host/path  a directory reachable with rsync over SSH
+//	s3://bucket/prefix                      an S3 compatible bucket, see NewS3TargetFromEnv
+//
+// stagingDir is where remote targets stage uploads before they are flushed.
+func ParseTarget(spec, stagingDir string) (Target, error) {
+	switch {
+	case spec == "":
+		return nil, fmt.Errorf("empty backup target")
+	case strings.HasPrefix(spec, "s3://"):
+		bucket, prefix, _ := strings.Cut(strings.TrimPrefix(spec, "s3://"), "/")
+		if bucket == "" {
+			return nil, fmt.Errorf("backup target %q has no bucket", spec)
+		}
+		return NewS3TargetFromEnv(bucket, prefix)
+	case strings.HasPrefix(spec, "ssh://"):
+		hostAndPath := strings.TrimPrefix(spec, "ssh://")
+		host, dir, found := strings.Cut(hostAndPath, "/")
+		if !found || host == "" || dir == "" {
+			return nil, fmt.Errorf("backup target %q must have the form ssh://[user@]host/path", spec)
+		}
+		return NewRsyncTarget(host, "/"+dir, stagingDir), nil
+	case strings.HasPrefix(spec, "file://"):
+		return NewLocalTarget(strings.TrimPrefix(spec, "file://")), nil
+	case scpLikeTarget.MatchString(spec) && !filepath.IsAbs(spec):
+		host, dir, _ := strings.Cut(spec, ":")
+		if dir == "" {
+			return nil, fmt.Errorf("backup target %q has no remote path", spec)
+		}
+		return NewRsyncTarget(host, dir, stagingDir), nil
+	default:
+		return NewLocalTarget(spec), nil
+	}
+}
+
+// validateKey rejects keys that would escape the root of a target
+func validateKey(key string) error {
+	if key == "" || path.IsAbs(key) || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return fmt.Errorf("invalid storage key %q", key)
+	}
+	return nil
+}
+
+// LocalTarget stores keys as files below a local directory
+type LocalTarget struct {
+	root string
+}
+
+// NewLocalTarget creates a target storing files below root
+func NewLocalTarget(root string) *LocalTarget {
+	return &LocalTarget{root: root}
+}
+
+func (t *LocalTarget) path(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(t.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes data to a temporary file and renames it into place
+func (t *LocalTarget) Put(_ context.Context, key string, data []byte) error {
+	target, err := t.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Get reads the file of key
+func (t *LocalTarget) Get(_ context.Context, key string) ([]byte, error) {
+	target, err := t.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(target)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s in %s: %w", key, t, ErrNotFound)
+	}
+	return data, err
+}
+
+// Delete removes the file of key
+func (t *LocalTarget) Delete(_ context.Context, key string) error {
+	target, err := t.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List walks the directory of prefix
+func (t *LocalTarget) List(_ context.Context, prefix string) ([]string, error) {
+	dir, err := t.path(strings.TrimSuffix(prefix, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	err = filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(t.root, p)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, filepath.ToSlash(rel))
+		return nil
+	})
+	return keys, err
+}
+
+// Flush is a no-op, Put writes files directly
+func (t *LocalTarget) Flush(context.Context) error {
+	return nil
+}
+
+func (t *LocalTarget) String() string {
+	return t.root
+}
@@ -0,0 +1,247 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/jameswlane/devex/apps/cli/internal/audit"
+)
+
+// notFoundExitCode is the exit code of the remote shell when a key does not exist
+const notFoundExitCode = 44
+
+// rsyncDeleteBatch is the number of keys removed by one remote rm command
+const rsyncDeleteBatch = 500
+
+// Prefetcher is implemented by targets that can fetch many keys at once more cheaply than
+// with one Get each
+type Prefetcher interface {
+	Prefetch(ctx context.Context, keys []string) error
+}
+
+// RsyncTarget stores keys in a directory on a remote host reached over SSH. Uploads are staged
+// in a local directory and transferred with a single rsync run on Flush, and deletions are
+// queued until then as well.
+type RsyncTarget struct {
+	host    string
+	dir     string
+	staging *LocalTarget
+	cache   *LocalTarget
+
+	mu      sync.Mutex
+	deletes []string
+}
+
+// NewRsyncTarget creates a target for dir on host, which may include a user such as
+// user@host. Uploads are staged and downloads cached below stagingDir.
+func NewRsyncTarget(host, dir, stagingDir string) *RsyncTarget {
+	base := filepath.Join(stagingDir, sanitizeName(host+":"+dir))
+	return &RsyncTarget{
+		host:    host,
+		dir:     strings.TrimSuffix(dir, "/"),
+		staging: NewLocalTarget(filepath.Join(base, "upload")),
+		cache:   NewLocalTarget(filepath.Join(base, "cache")),
+	}
+}
+
+// sanitizeName turns a remote location into a directory name
+func sanitizeName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+// remote runs a shell command on the remote host and returns its standard output
+func (t *RsyncTarget) remote(ctx context.Context, script string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ssh", "-o", "BatchMode=yes", t.host, script)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := audit.Run(ctx, cmd); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == notFoundExitCode {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("ssh %s failed: %w: %s", t.host, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// remotePath returns the quoted remote path of key
+func (t *RsyncTarget) remotePath(key string) string {
+	return shellQuote(t.dir + "/" + key)
+}
+
+// Put stages data for the next Flush
+func (t *RsyncTarget) Put(ctx context.Context, key string, data []byte) error {
+	return t.staging.Put(ctx, key, data)
+}
+
+// Get returns a staged or prefetched copy of key, or reads it from the remote host
+func (t *RsyncTarget) Get(ctx context.Context, key string) ([]byte, error) {
+	if data, err := t.staging.Get(ctx, key); err == nil || !errors.Is(err, ErrNotFound) {
+		return data, err
+	}
+	if data, err := t.cache.Get(ctx, key); err == nil || !errors.Is(err, ErrNotFound) {
+		return data, err
+	}
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	path := t.remotePath(key)
+	data, err := t.remote(ctx, fmt.Sprintf("[ -f %s ] || exit %d; cat -- %s", path, notFoundExitCode, path))
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("%s in %s: %w", key, t, ErrNotFound)
+	}
+	return data, err
+}
+
+// Prefetch downloads keys into the local cache with a single rsync run
+func (t *RsyncTarget) Prefetch(ctx context.Context, keys []string) error {
+	var list strings.Builder
+	for _, key := range keys {
+		if err := validateKey(key); err != nil {
+			return err
+		}
+		list.WriteString(key)
+		list.WriteByte('\n')
+	}
+	if list.Len() == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(t.cache.root, 0700); err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, "rsync", "-a", "--files-from=-", t.host+":"+t.dir+"/", t.cache.root+"/")
+	cmd.Stdin = strings.NewReader(list.String())
+	if output, err := audit.CombinedOutput(ctx, cmd); err != nil {
+		return fmt.Errorf("rsync from %s failed: %w: %s", t, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Delete queues key for removal on the next Flush
+func (t *RsyncTarget) Delete(ctx context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	if err := t.staging.Delete(ctx, key); err != nil {
+		return err
+	}
+	if err := t.cache.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.deletes = append(t.deletes, key)
+	return nil
+}
+
+// List lists the keys below prefix on the remote host together with staged keys
+func (t *RsyncTarget) List(ctx context.Context, prefix string) ([]string, error) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if err := validateKey(prefix); err != nil {
+		return nil, err
+	}
+
+	output, err := t.remote(ctx, fmt.Sprintf("cd %s 2>/dev/null || exit 0; [ -d %s ] || exit 0; find %s -type f",
+		shellQuote(t.dir), shellQuote(prefix), shellQuote(prefix)))
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	deleted := make(map[string]bool, len(t.deletes))
+	for _, key := range t.deletes {
+		deleted[key] = true
+	}
+	t.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var keys []string
+	for _, line := range strings.Split(string(output), "\n") {
+		key := strings.TrimPrefix(strings.TrimSpace(line), "./")
+		if key == "" || deleted[key] || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+
+	staged, err := t.staging.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range staged {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// Flush transfers staged uploads with rsync and then runs queued deletions
+func (t *RsyncTarget) Flush(ctx context.Context) error {
+	staged, err := t.staging.List(ctx, ".")
+	if err != nil {
+		return err
+	}
+	if len(staged) > 0 {
+		if _, err := t.remote(ctx, "mkdir -p -- "+shellQuote(t.dir)); err != nil {
+			return err
+		}
+		cmd := exec.CommandContext(ctx, "rsync", "-a", "--remove-source-files",
+			t.staging.root+"/", t.host+":"+t.dir+"/")
+		if output, err := audit.CombinedOutput(ctx, cmd); err != nil {
+			return fmt.Errorf("rsync to %s failed: %w: %s", t, err, strings.TrimSpace(string(output)))
+		}
+	}
+
+	t.mu.Lock()
+	deletes := t.deletes
+	t.deletes = nil
+	t.mu.Unlock()
+	if len(deletes) == 0 {
+		return nil
+	}
+
+	for start := 0; start < len(deletes); start += rsyncDeleteBatch {
+		batch := deletes[start:min(start+rsyncDeleteBatch, len(deletes))]
+		quoted := make([]string, len(batch))
+		for i, key := range batch {
+			quoted[i] = t.remotePath(key)
+		}
+		if _, err := t.remote(ctx, "rm -f -- "+strings.Join(quoted, " ")); err != nil {
+			t.mu.Lock()
+			t.deletes = append(deletes[start:], t.deletes...)
+			t.mu.Unlock()
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *RsyncTarget) String() string {
+	return t.host + ":" + t.dir
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,151 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// Environment variables configuring S3 targets. Credentials are read from the usual
+// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY variables or ~/.aws/credentials.
+const (
+	EnvS3Endpoint = "DEVEX_BACKUP_S3_ENDPOINT" // host[:port], prefixed with http:// for servers without TLS
+	EnvS3Region   = "DEVEX_BACKUP_S3_REGION"
+)
+
+// DefaultS3Endpoint is used when EnvS3Endpoint is not set
+const DefaultS3Endpoint = "s3.amazonaws.com"
+
+// S3Target stores keys as objects in an S3 compatible bucket such as AWS S3 or MinIO
+type S3Target struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// NewS3Target creates a target storing objects below prefix in bucket
+func NewS3Target(client *minio.Client, bucket, prefix string) *S3Target {
+	prefix = strings.Trim(prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return &S3Target{client: client, bucket: bucket, prefix: prefix}
+}
+
+// NewS3TargetFromEnv creates an S3 target configured from the environment
+func NewS3TargetFromEnv(bucket, prefix string) (*S3Target, error) {
+	endpoint := os.Getenv(EnvS3Endpoint)
+	if endpoint == "" {
+		endpoint = DefaultS3Endpoint
+	}
+	secure := true
+	if rest, found := strings.CutPrefix(endpoint, "http://"); found {
+		endpoint, secure = rest, false
+	}
+	endpoint = strings.TrimSuffix(strings.TrimPrefix(endpoint, "https://"), "/")
+
+	region := os.Getenv(EnvS3Region)
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds: credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.EnvMinio{},
+			&credentials.FileAWSCredentials{},
+		}),
+		Secure: secure,
+		Region: region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client for %s: %w", endpoint, err)
+	}
+	return NewS3Target(client, bucket, prefix), nil
+}
+
+// Put uploads data as the object of key
+func (t *S3Target) Put(ctx context.Context, key string, data []byte) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	_, err := t.client.PutObject(ctx, t.bucket, t.prefix+key, bytes.NewReader(data), int64(len(data)),
+		minio.PutObjectOptions{ContentType: "application/octet-stream"})
+	if err != nil {
+		return fmt.Errorf("failed to upload %s to %s: %w", key, t, err)
+	}
+	return nil
+}
+
+// Get downloads the object of key
+func (t *S3Target) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	object, err := t.client.GetObject(ctx, t.bucket, t.prefix+key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, t.wrapError(key, err)
+	}
+	defer func() { _ = object.Close() }()
+
+	data, err := io.ReadAll(object)
+	if err != nil {
+		return nil, t.wrapError(key, err)
+	}
+	return data, nil
+}
+
+// wrapError translates missing objects into ErrNotFound
+func (t *S3Target) wrapError(key string, err error) error {
+	if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+		return fmt.Errorf("%s in %s: %w", key, t, ErrNotFound)
+	}
+	return fmt.Errorf("failed to download %s from %s: %w", key, t, err)
+}
+
+// Delete removes the object of key
+func (t *S3Target) Delete(ctx context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	if err := t.client.RemoveObject(ctx, t.bucket, t.prefix+key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete %s from %s: %w", key, t, err)
+	}
+	return nil
+}
+
+// List lists the objects below prefix
+func (t *S3Target) List(ctx context.Context, prefix string) ([]string, error) {
+	listPrefix := t.prefix
+	if prefix = strings.Trim(prefix, "/"); prefix != "" {
+		listPrefix += prefix + "/"
+	}
+
+	var keys []string
+	objects := t.client.ListObjects(ctx, t.bucket, minio.ListObjectsOptions{
+		Prefix:    listPrefix,
+		Recursive: true,
+	})
+	for object := range objects {
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list %s in %s: %w", prefix, t, object.Err)
+		}
+		keys = append(keys, strings.TrimPrefix(object.Key, t.prefix))
+	}
+	return keys, nil
+}
+
+// Flush is a no-op, Put uploads objects directly
+func (t *S3Target) Flush(context.Context) error {
+	return nil
+}
+
+func (t *S3Target) String() string {
+	return "s3://" + t.bucket + "/" + strings.TrimSuffix(t.prefix, "/")
+}
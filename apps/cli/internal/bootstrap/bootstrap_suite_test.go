@@ -1,13 +1,23 @@
 package bootstrap_test
 
 import (
+	"os"
 	"testing"
 
 	"github.com/jameswlane/devex/apps/cli/internal/testhelper"
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
 
+// TestMain lets the test binary act as the plugin sandbox launcher, as the CLI does
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && os.Args[1] == sdk.SandboxLauncherArg {
+		sdk.RunSandboxLauncher(os.Args[2:])
+	}
+	os.Exit(m.Run())
+}
+
 func TestBootstrap(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Bootstrap Suite")
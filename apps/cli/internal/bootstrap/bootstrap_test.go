@@ -2,14 +2,17 @@ package bootstrap_test
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/spf13/cobra"
 
 	"github.com/jameswlane/devex/apps/cli/internal/bootstrap"
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
 )
 
 var _ = Describe("PluginBootstrap", func() {
@@ -210,4 +213,149 @@ var _ = Describe("PluginBootstrap", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
+
+	Describe("SetRegistryURLInConfig", func() {
+		var configPath string
+
+		BeforeEach(func() {
+			configPath = filepath.Join(tempHomeDir, ".devex", "config.yaml")
+			originalURL, hadURL := os.LookupEnv("DEVEX_PLUGIN_REGISTRY_URL")
+			os.Unsetenv("DEVEX_PLUGIN_REGISTRY_URL")
+			DeferCleanup(func() {
+				if hadURL {
+					os.Setenv("DEVEX_PLUGIN_REGISTRY_URL", originalURL)
+				}
+			})
+		})
+
+		It("should point GetRegistryURL at the configured registry", func() {
+			Expect(bootstrap.SetRegistryURLInConfig("http://registry.internal:8080")).To(Succeed())
+			Expect(bootstrap.GetRegistryURL()).To(Equal("http://registry.internal:8080"))
+		})
+
+		It("should keep other settings in the config file", func() {
+			Expect(os.MkdirAll(filepath.Dir(configPath), 0750)).To(Succeed())
+			Expect(os.WriteFile(configPath, []byte("# team settings\nverbose: true\nplugin_registry_url: http://old\n"), 0600)).To(Succeed())
+
+			Expect(bootstrap.SetRegistryURLInConfig("http://new")).To(Succeed())
+			data, err := os.ReadFile(configPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring("# team settings"))
+			Expect(string(data)).To(ContainSubstring("verbose: true"))
+			Expect(string(data)).To(ContainSubstring("plugin_registry_url: http://new"))
+			Expect(string(data)).NotTo(ContainSubstring("http://old"))
+		})
+
+		It("should fall back to the default registry when the setting is removed", func() {
+			Expect(bootstrap.SetRegistryURLInConfig("http://registry.internal")).To(Succeed())
+			Expect(bootstrap.SetRegistryURLInConfig("")).To(Succeed())
+			Expect(bootstrap.GetRegistryURL()).To(Equal(bootstrap.DefaultRegistryURL))
+		})
+	})
+
+	Describe("Plugin trust", func() {
+		var configPath string
+
+		BeforeEach(func() {
+			configPath = filepath.Join(tempHomeDir, ".devex", "config.yaml")
+			Expect(os.MkdirAll(filepath.Dir(configPath), 0750)).To(Succeed())
+		})
+
+		It("should trust the release keys and configured registry keys", func() {
+			keyring, err := bootstrap.TrustRoots()
+			Expect(err).NotTo(HaveOccurred())
+			embedded := len(keyring.Keys())
+
+			config := "plugin_trusted_keys:\n  - RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3\n"
+			Expect(os.WriteFile(configPath, []byte(config), 0600)).To(Succeed())
+			keyring, err = bootstrap.TrustRoots()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(keyring.Keys()).To(HaveLen(embedded + 1))
+
+			Expect(os.WriteFile(configPath, []byte("plugin_trusted_keys:\n  - not a key\n"), 0600)).To(Succeed())
+			_, err = bootstrap.TrustRoots()
+			Expect(err).To(MatchError(ContainSubstring("plugin_trusted_keys")))
+		})
+
+		It("should record plugins allowed without a trusted signature once", func() {
+			Expect(os.WriteFile(configPath, []byte("verbose: true\n"), 0600)).To(Succeed())
+
+			Expect(bootstrap.AllowUnverifiedPluginInConfig("package-manager-apt")).To(Succeed())
+			Expect(bootstrap.AllowUnverifiedPluginInConfig("package-manager-apt")).To(Succeed())
+			Expect(bootstrap.AllowUnverifiedPluginInConfig("tool-shell")).To(Succeed())
+			Expect(bootstrap.AllowedUnverifiedPlugins()).To(Equal([]string{"package-manager-apt", "tool-shell"}))
+
+			data, err := os.ReadFile(configPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring("verbose: true"))
+
+			Expect(bootstrap.AllowUnverifiedPluginInConfig("../evil")).To(HaveOccurred())
+		})
+
+		It("should offer --allow-unverified on install and update", func() {
+			pluginBootstrap, err := bootstrap.NewPluginBootstrap(false)
+			Expect(err).NotTo(HaveOccurred())
+			rootCmd := &cobra.Command{Use: "devex"}
+			pluginBootstrap.RegisterCommands(rootCmd)
+
+			for _, name := range []string{"install", "update"} {
+				cmd, _, err := rootCmd.Find([]string{"plugin", name})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cmd.Flags().Lookup("allow-unverified")).NotTo(BeNil(), "plugin %s", name)
+			}
+		})
+	})
+
+	Describe("Plugin permissions", func() {
+		// permissions runs the plugin permissions command on a fresh command tree, as flags keep
+		// their values between executions
+		permissions := func(args ...string) error {
+			rootCmd := &cobra.Command{Use: "devex", SilenceUsage: true, SilenceErrors: true}
+			pluginBootstrap.RegisterCommands(rootCmd)
+			rootCmd.SetArgs(append([]string{"plugin", "permissions"}, args...))
+			return rootCmd.Execute()
+		}
+
+		BeforeEach(func() {
+			pluginDir := filepath.Join(tempHomeDir, ".devex", "plugins")
+			Expect(os.MkdirAll(pluginDir, 0755)).To(Succeed())
+			info := `{"name":"hello","version":"1.0.0","description":"Says hello","permissions":{}}`
+			script := "#!/bin/sh\nif [ \"$1\" = --plugin-info ]; then echo '" + info + "'; exit 0; fi\necho hello\n"
+			Expect(os.WriteFile(filepath.Join(pluginDir, "devex-plugin-hello"), []byte(script), 0755)).To(Succeed())
+			GinkgoT().Setenv("DEVEX_NONINTERACTIVE", "1")
+
+			var err error
+			pluginBootstrap, err = bootstrap.NewPluginBootstrap(true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pluginBootstrap.Initialize(ctx)).To(Succeed())
+		})
+
+		It("should only run plugins once their permissions are granted", func() {
+			err := pluginBootstrap.ExecutePlugin("hello", nil)
+			var notGranted *sdk.PermissionsNotGrantedError
+			Expect(errors.As(err, &notGranted)).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring("devex plugin permissions hello --grant")))
+
+			Expect(permissions("hello", "--grant")).To(Succeed())
+			Expect(pluginBootstrap.ExecutePlugin("hello", nil)).To(Succeed())
+
+			Expect(permissions("--revoke", "--all")).To(Succeed())
+			Expect(pluginBootstrap.EnsurePluginPermissions("hello")).To(HaveOccurred())
+		})
+
+		It("should collect the permissions of several plugins at once", func() {
+			err := pluginBootstrap.EnsurePluginsPermissions([]string{"hello", "missing", "hello"})
+			Expect(err).To(HaveOccurred())
+			Expect(strings.Count(err.Error(), "devex plugin permissions hello --grant")).To(Equal(1))
+
+			Expect(permissions("hello", "--grant")).To(Succeed())
+			Expect(pluginBootstrap.EnsurePluginsPermissions([]string{"hello", "missing"})).To(Succeed())
+		})
+
+		It("should reject conflicting flags", func() {
+			Expect(permissions("hello", "--grant", "--revoke")).To(MatchError(ContainSubstring("cannot be combined")))
+			Expect(permissions("--grant")).To(MatchError(ContainSubstring("--all")))
+			Expect(permissions("missing", "--grant")).To(MatchError(ContainSubstring("not installed")))
+		})
+	})
 })
@@ -0,0 +1,234 @@
+package bootstrap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+// permissionPromptsSuspended counts the interactive programs that own the terminal
+var permissionPromptsSuspended atomic.Int32
+
+// SuspendPermissionPrompts stops plugins from asking for consent until the returned function is
+// called. A TUI suspends prompts while it owns the terminal, so plugins whose permissions were not
+// granted beforehand fail with a hint instead of reading from stdin.
+func SuspendPermissionPrompts() (resume func()) {
+	permissionPromptsSuspended.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() { permissionPromptsSuspended.Add(-1) })
+	}
+}
+
+// EnsurePluginsPermissions asks for consent to the permissions of each of the given plugins once.
+// Callers collect consent this way before starting a TUI that runs the plugins.
+func (b *PluginBootstrap) EnsurePluginsPermissions(pluginNames []string) error {
+	names := slices.Clone(pluginNames)
+	slices.Sort(names)
+
+	var errs []error
+	for _, name := range slices.Compact(names) {
+		errs = append(errs, b.EnsurePluginPermissions(name))
+	}
+	return errors.Join(errs...)
+}
+
+// EnsurePluginPermissions makes sure the user granted the permissions an installed plugin
+// requests, asking for consent when a terminal is attached and no TUI owns it. Plugins that are
+// not installed are left to the caller to report.
+func (b *PluginBootstrap) EnsurePluginPermissions(pluginName string) error {
+	pluginInfo, installed := b.manager.ListPlugins()[pluginName]
+	if !installed {
+		return nil
+	}
+
+	grant, err := sdk.LoadPermissionGrant(b.manager.GetPluginDir(), pluginName)
+	if err != nil {
+		return err
+	}
+	covered, missing := grant.Covers(pluginInfo.Permissions)
+	if covered {
+		return nil
+	}
+
+	notGranted := &sdk.PermissionsNotGrantedError{Plugin: pluginName, Requested: pluginInfo.Permissions, Missing: missing}
+	if !canPrompt() {
+		return permissionsHint(notGranted)
+	}
+
+	if grant == nil {
+		fmt.Printf("\n🔐 Plugin %s requests permission to:\n", pluginName)
+		missing = pluginInfo.Permissions.Describe()
+	} else {
+		fmt.Printf("\n🔐 Plugin %s now requests additional permissions:\n", pluginName)
+	}
+	for _, line := range missing {
+		fmt.Printf("   • %s\n", line)
+	}
+	if pluginInfo.Permissions != nil && pluginInfo.Permissions.Sudo {
+		fmt.Println("   Commands it runs with sudo are checked against this list and recorded in the audit log ('devex audit show').")
+	}
+	fmt.Print("Allow? [y/N]: ")
+
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+		return permissionsHint(notGranted)
+	}
+	return b.grantPluginPermissions(pluginName, pluginInfo.Permissions)
+}
+
+// grantPluginPermissions records consent to the permissions a plugin requests
+func (b *PluginBootstrap) grantPluginPermissions(pluginName string, permissions *sdk.PluginPermissions) error {
+	grant := &sdk.PermissionGrant{Permissions: permissions, GrantedAt: time.Now()}
+	if err := sdk.SavePermissionGrant(b.manager.GetPluginDir(), pluginName, grant); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Granted permissions to plugin %s\n", pluginName)
+	return nil
+}
+
+// canPrompt reports whether the user can be asked for consent
+func canPrompt() bool {
+	if permissionPromptsSuspended.Load() > 0 {
+		return false
+	}
+	if os.Getenv("DEVEX_NONINTERACTIVE") == "1" || os.Getenv("CI") != "" {
+		return false
+	}
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// permissionsHint explains how to grant the permissions of a plugin
+func permissionsHint(err *sdk.PermissionsNotGrantedError) error {
+	return fmt.Errorf("%w\nReview and grant them with 'devex plugin permissions %s --grant'", err, err.Plugin)
+}
+
+// describePermissionGrant describes whether the permissions a plugin requests are granted
+func (b *PluginBootstrap) describePermissionGrant(pluginName string, requested *sdk.PluginPermissions) string {
+	grant, err := sdk.LoadPermissionGrant(b.manager.GetPluginDir(), pluginName)
+	if err != nil {
+		return fmt.Sprintf("❌ %v", err)
+	}
+	if grant == nil {
+		return "⚠️  not granted"
+	}
+	if covered, missing := grant.Covers(requested); !covered {
+		return fmt.Sprintf("⚠️  requests more than granted: %s", strings.Join(missing, ", "))
+	}
+	return fmt.Sprintf("✅ granted on %s", grant.GrantedAt.Format("2006-01-02"))
+}
+
+// newPluginPermissionsCmd creates the plugin permissions command
+func (b *PluginBootstrap) newPluginPermissionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "permissions [plugin-name]",
+		Short: "Review and grant plugin permissions",
+		Long: `Show the permissions plugins request in their manifest and whether they were granted.
+
+Plugins declare whether they need sudo, network access, the paths they write and the
+commands they run. DevEx asks for consent when a plugin is installed and runs it
+confined to its manifest: the environment is scrubbed and, on Linux, no-new-privs and
+Landlock enforce the rest where the kernel supports it. Plugins allowed to use sudo are
+only confined to their environment, as no-new-privs would stop sudo. A plugin that
+needs more than its manifest declares fails.
+
+Use --grant to consent without a prompt, e.g. in automated setups, and --revoke to
+withdraw consent; the plugin will not run until it is granted again.`,
+		Example: `  # Review the permissions of all installed plugins
+  devex plugin permissions
+
+  # Grant the permissions of a plugin
+  devex plugin permissions package-manager-apt --grant
+
+  # Grant every installed plugin in CI
+  devex plugin permissions --grant --all`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: b.handlePluginPermissions,
+	}
+
+	cmd.Flags().Bool("grant", false, "Grant the permissions the plugin requests")
+	cmd.Flags().Bool("revoke", false, "Revoke the permissions granted to the plugin")
+	cmd.Flags().Bool("all", false, "Apply --grant or --revoke to every installed plugin")
+
+	return cmd
+}
+
+// handlePluginPermissions shows, grants or revokes plugin permissions
+func (b *PluginBootstrap) handlePluginPermissions(cmd *cobra.Command, args []string) error {
+	grant, _ := cmd.Flags().GetBool("grant")
+	revoke, _ := cmd.Flags().GetBool("revoke")
+	all, _ := cmd.Flags().GetBool("all")
+
+	switch {
+	case grant && revoke:
+		return fmt.Errorf("--grant and --revoke cannot be combined")
+	case all && len(args) > 0:
+		return fmt.Errorf("--all does not take a plugin name")
+	case (grant || revoke) && !all && len(args) == 0:
+		return fmt.Errorf("a plugin name or --all is required")
+	}
+
+	plugins := b.manager.ListPlugins()
+	names := make([]string, 0, len(plugins))
+	if len(args) > 0 {
+		if _, installed := plugins[args[0]]; !installed {
+			return fmt.Errorf("plugin %s is not installed", args[0])
+		}
+		names = append(names, args[0])
+	} else {
+		for name := range plugins {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	var errs []error
+	for _, name := range names {
+		switch {
+		case grant:
+			errs = append(errs, b.grantPluginPermissions(name, plugins[name].Permissions))
+		case revoke:
+			if err := sdk.SavePermissionGrant(b.manager.GetPluginDir(), name, nil); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			fmt.Printf("Revoked permissions of plugin %s\n", name)
+		case len(args) > 0:
+			b.printPluginPermissions(name, plugins[name].Permissions)
+		default:
+			fmt.Printf("%-32s %s\n", name, b.describePermissionGrant(name, plugins[name].Permissions))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// printPluginPermissions shows the manifest of a plugin, its grant and how it is enforced
+func (b *PluginBootstrap) printPluginPermissions(pluginName string, permissions *sdk.PluginPermissions) {
+	fmt.Printf("Plugin: %s\n", pluginName)
+	fmt.Println("Requests permission to:")
+	for _, line := range permissions.Describe() {
+		fmt.Printf("  • %s\n", line)
+	}
+	fmt.Printf("Status: %s\n", b.describePermissionGrant(pluginName, permissions))
+
+	switch {
+	case permissions == nil:
+		fmt.Println("Enforcement: none, the plugin runs with full access")
+	case permissions.Sudo:
+		fmt.Println("Enforcement: environment only, plugins allowed to use sudo cannot be confined further")
+	default:
+		fmt.Printf("Enforcement: %s\n", sdk.SandboxEnforcement())
+	}
+}
@@ -2,6 +2,7 @@ package bootstrap
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,13 +12,20 @@ import (
 	"sync"
 	"time"
 
+	"github.com/jameswlane/devex/apps/cli/internal/audit"
 	"github.com/jameswlane/devex/apps/cli/internal/log"
 	"github.com/jameswlane/devex/apps/cli/internal/platform"
 	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/yaml.v3"
 )
 
+var tracer = otel.Tracer("devex/bootstrap/plugins")
+
 const (
 	// Default plugin registry URL - you would host this
 	DefaultRegistryURL = "https://registry.devex.sh"
@@ -83,6 +91,7 @@ type PluginBootstrap struct {
 	detector     *platform.Detector
 	downloader   *sdk.Downloader
 	manager      *sdk.ExecutableManager
+	keyring      *sdk.Keyring
 	platform     *platform.Platform
 	skipDownload bool
 	cache        *BootstrapCache
@@ -106,33 +115,113 @@ func GetRegistryURL() string {
 
 // getRegistryURLFromConfig attempts to read registry URL from config file
 func getRegistryURLFromConfig() string {
-	homeDir, err := os.UserHomeDir()
+	return readPluginConfig().PluginRegistryURL
+}
+
+// pluginConfig holds the plugin settings of ~/.devex/config.yaml
+type pluginConfig struct {
+	PluginRegistryURL     string   `yaml:"plugin_registry_url"`
+	PluginTrustedKeys     []string `yaml:"plugin_trusted_keys"`
+	PluginAllowUnverified []string `yaml:"plugin_allow_unverified"`
+}
+
+// readPluginConfig reads the plugin settings from the config file. A missing or invalid file
+// yields empty settings so the defaults are used.
+func readPluginConfig() pluginConfig {
+	var config pluginConfig
+
+	configPath, err := registryConfigPath()
 	if err != nil {
-		return ""
+		return config
 	}
 
 	// Check for config file
-	configPath := filepath.Join(homeDir, ".devex", "config.yaml")
 	data, err := os.ReadFile(configPath)
 	if err != nil {
-		return ""
+		return config
 	}
 
-	// Parse YAML properly to extract registry URL
-	var config struct {
-		PluginRegistryURL string `yaml:"plugin_registry_url"`
+	// Parse YAML properly to extract the settings
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		// If YAML parsing fails, return empty settings (fallback to defaults)
+		return pluginConfig{}
 	}
+	return config
+}
 
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		// If YAML parsing fails, return empty string (fallback to default)
-		return ""
+// registryConfigPath returns the config file holding plugin_registry_url
+func registryConfigPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".devex", "config.yaml"), nil
+}
+
+// SetRegistryURLInConfig stores the plugin registry URL in ~/.devex/config.yaml, keeping the
+// other settings in the file. An empty URL removes the setting so the default is used again.
+func SetRegistryURLInConfig(registryURL string) error {
+	const key = "plugin_registry_url"
+	return updateConfig(func(root *yaml.Node) {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value != key {
+				continue
+			}
+			if registryURL == "" {
+				root.Content = append(root.Content[:i], root.Content[i+2:]...)
+			} else {
+				root.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: registryURL}
+				registryURL = ""
+			}
+			break
+		}
+		if registryURL != "" {
+			root.Content = append(root.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: registryURL})
+		}
+	})
+}
+
+// updateConfig applies a change to the top-level mapping of ~/.devex/config.yaml, keeping the
+// other settings and comments in the file
+func updateConfig(change func(root *yaml.Node)) error {
+	configPath, err := registryConfigPath()
+	if err != nil {
+		return err
 	}
 
-	if config.PluginRegistryURL != "" {
-		return config.PluginRegistryURL
+	var doc yaml.Node
+	data, err := os.ReadFile(configPath)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", configPath, err)
+		}
+	case !os.IsNotExist(err):
+		return fmt.Errorf("failed to read %s: %w", configPath, err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s is not a YAML mapping", configPath)
 	}
 
-	return ""
+	change(root)
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", configPath, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o750); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(configPath), err)
+	}
+	if err := os.WriteFile(configPath, out, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", configPath, err)
+	}
+	return nil
 }
 
 // NewPluginBootstrap creates a new plugin bootstrap instance
@@ -164,7 +253,14 @@ func NewPluginBootstrap(skipDownload bool) (*PluginBootstrap, error) {
 		log.Info("Using custom plugin registry", "url", registryURL)
 	}
 
+	// Plugins must be signed by a trusted key unless the user allowed them to be unverified
+	keyring, err := TrustRoots()
+	if err != nil {
+		return nil, err
+	}
 	downloader := sdk.NewDownloader(registryURL, pluginDir)
+	downloader.SetKeyring(keyring)
+	downloader.AllowUnverified(AllowedUnverifiedPlugins()...)
 
 	// Configure downloader to use CLI logger adapter
 	// In test mode, silence stdout but still log to file
@@ -172,10 +268,20 @@ func NewPluginBootstrap(skipDownload bool) (*PluginBootstrap, error) {
 	loggerAdapter := NewSDKLoggerAdapter(true) // Always silent to avoid TUI conflicts
 	downloader.SetLogger(loggerAdapter)
 
+	// Plugins run confined to the permissions granted to them, with the CLI itself as the
+	// sandbox launcher
+	launcher, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate the devex executable: %w", err)
+	}
+	manager := sdk.NewExecutableManager(pluginDir)
+	manager.SetSandbox(&sdk.Sandbox{Launcher: launcher, Logger: loggerAdapter})
+
 	return &PluginBootstrap{
 		detector:     platform.NewDetector(),
 		downloader:   downloader,
-		manager:      sdk.NewExecutableManager(pluginDir),
+		manager:      manager,
+		keyring:      keyring,
 		skipDownload: skipDownload,
 		cache:        &BootstrapCache{},
 	}, nil
@@ -266,11 +372,103 @@ func (b *PluginBootstrap) IsPluginAvailable(ctx context.Context, pluginName stri
 
 // ExecutePlugin executes a plugin with given arguments
 func (b *PluginBootstrap) ExecutePlugin(pluginName string, args []string) error {
+	return b.ExecutePluginWithContext(context.Background(), pluginName, args)
+}
+
+// ExecutePluginWithContext executes a plugin with given arguments, tracing the run as part
+// of the operation in ctx
+func (b *PluginBootstrap) ExecutePluginWithContext(ctx context.Context, pluginName string, args []string) error {
 	if err := validatePluginName(pluginName); err != nil {
 		return fmt.Errorf("invalid plugin name: %w", err)
 	}
+	if err := b.EnsurePluginPermissions(pluginName); err != nil {
+		return err
+	}
+
+	command := ""
+	if len(args) > 0 {
+		command = args[0]
+	}
+	ctx, span := startPluginSpan(ctx, pluginName, command)
+	defer span.End()
+
+	execution := b.beginPluginExecution(ctx, pluginName, args)
+	return endPluginSpan(span, execution.End(b.manager.ExecutePlugin(pluginName, args)))
+}
+
+// startPluginSpan starts the span of a plugin run
+func startPluginSpan(ctx context.Context, pluginName, command string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "plugin_execution", trace.WithAttributes(
+		attribute.String("plugin", pluginName),
+		attribute.String("command", command),
+	))
+}
 
-	return b.manager.ExecutePlugin(pluginName, args)
+// endPluginSpan records the outcome of a plugin run on its span
+func endPluginSpan(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Plugin execution failed")
+	}
+	return err
+}
+
+// beginPluginExecution records a plugin run in the audit log
+func (b *PluginBootstrap) beginPluginExecution(ctx context.Context, pluginName string, args []string) *audit.Execution {
+	command := pluginName
+	if pluginInfo, installed := b.manager.ListPlugins()[pluginName]; installed {
+		command = pluginInfo.Path
+	}
+	return audit.BeginCommand(audit.WithTrigger(ctx, "", "plugin"), command, args)
+}
+
+// EnsurePlugin makes sure a plugin is installed, downloading it on first use
+func (b *PluginBootstrap) EnsurePlugin(ctx context.Context, pluginName string) error {
+	if err := validatePluginName(pluginName); err != nil {
+		return fmt.Errorf("invalid plugin name: %w", err)
+	}
+
+	if _, installed := b.manager.ListPlugins()[pluginName]; installed {
+		return nil
+	}
+	if b.skipDownload {
+		return fmt.Errorf("plugin %s is not installed, run 'devex plugin install %s'", pluginName, pluginName)
+	}
+
+	log.Info("Downloading plugin on first use", "plugin", pluginName)
+	if err := b.downloader.DownloadPluginWithContext(ctx, pluginName); err != nil {
+		return fmt.Errorf("failed to install plugin %s: %w", pluginName, err)
+	}
+	if err := b.manager.DiscoverPluginsWithContext(ctx); err != nil {
+		return fmt.Errorf("failed to load plugin %s: %w", pluginName, err)
+	}
+	if _, installed := b.manager.ListPlugins()[pluginName]; !installed {
+		return fmt.Errorf("plugin %s is not available for this platform", pluginName)
+	}
+	return nil
+}
+
+// CallPlugin sends a structured protocol request to a plugin.
+// Returns sdk.ErrProtocolUnsupported for legacy plugins so callers can fall back to ExecutePlugin.
+func (b *PluginBootstrap) CallPlugin(ctx context.Context, pluginName string, req *sdk.RPCRequest, onEvent func(sdk.RPCEvent)) (*sdk.RPCResult, error) {
+	if err := validatePluginName(pluginName); err != nil {
+		return nil, fmt.Errorf("invalid plugin name: %w", err)
+	}
+	if err := b.EnsurePluginPermissions(pluginName); err != nil {
+		return nil, err
+	}
+
+	ctx, span := startPluginSpan(ctx, pluginName, req.Method)
+	defer span.End()
+
+	execution := b.beginPluginExecution(ctx, pluginName, []string{sdk.RPCFlag, req.Method})
+	result, err := b.manager.CallPlugin(ctx, pluginName, req, onEvent)
+	err = execution.End(err)
+	if errors.Is(err, sdk.ErrProtocolUnsupported) {
+		// Legacy plugins are run again with ExecutePlugin, which traces that run
+		return result, err
+	}
+	return result, endPluginSpan(span, err)
 }
 
 // GetPlatform returns the detected platform
@@ -312,9 +510,16 @@ func (b *PluginBootstrap) createPluginManagementCmd() *cobra.Command {
 	installCmd := &cobra.Command{
 		Use:   "install [plugin-name]",
 		Short: "Install a plugin",
-		Args:  cobra.ExactArgs(1),
-		RunE:  b.handleInstallPlugin,
+		Long: `Install a plugin from the registry. Plugins must be signed by a key trusted by DevEx;
+unsigned plugins and plugins signed by other keys are refused unless installed with
+--allow-unverified, which is remembered for later updates of the plugin.
+
+The permissions the plugin requests are shown for consent after installation. A plugin
+only runs once they are granted, see 'devex plugin permissions'.`,
+		Args: cobra.ExactArgs(1),
+		RunE: b.handleInstallPlugin,
 	}
+	installCmd.Flags().Bool("allow-unverified", false, "Allow this plugin to be installed without a trusted signature")
 
 	// plugin remove command
 	removeCmd := &cobra.Command{
@@ -331,6 +536,7 @@ func (b *PluginBootstrap) createPluginManagementCmd() *cobra.Command {
 		Args:  cobra.MaximumNArgs(1),
 		RunE:  b.handleUpdatePlugins,
 	}
+	updateCmd.Flags().Bool("allow-unverified", false, "Allow the named plugin to be updated without a trusted signature")
 
 	// plugin info command
 	infoCmd := &cobra.Command{
@@ -349,11 +555,14 @@ func (b *PluginBootstrap) createPluginManagementCmd() *cobra.Command {
 The registry URL can be configured via:
 1. Environment variable: DEVEX_PLUGIN_REGISTRY_URL
 2. Config file: ~/.devex/config.yaml (plugin_registry_url key)
-3. Default: https://registry.devex.sh/v1`,
+3. Default: https://registry.devex.sh
+
+Run 'devex registry serve' to host a registry from a local directory and
+'devex registry use <url>' to point DevEx at it.`,
 		RunE: b.handleRegistryInfo,
 	}
 
-	cmd.AddCommand(listCmd, searchCmd, installCmd, removeCmd, updateCmd, infoCmd, registryCmd)
+	cmd.AddCommand(listCmd, searchCmd, installCmd, removeCmd, updateCmd, infoCmd, registryCmd, b.newPluginPermissionsCmd())
 	return cmd
 }
 
@@ -442,8 +651,14 @@ func (b *PluginBootstrap) handleInstallPlugin(cmd *cobra.Command, args []string)
 	ctx := cmd.Context()
 	pluginName := args[0]
 
+	if allow, _ := cmd.Flags().GetBool("allow-unverified"); allow {
+		if err := b.allowUnverified(pluginName); err != nil {
+			return err
+		}
+	}
+
 	if err := b.downloader.DownloadPluginWithContext(ctx, pluginName); err != nil {
-		return fmt.Errorf("failed to install plugin: %w", err)
+		return fmt.Errorf("failed to install plugin: %w", unverifiedPluginHint(pluginName, err))
 	}
 
 	// Reload plugins
@@ -452,6 +667,9 @@ func (b *PluginBootstrap) handleInstallPlugin(cmd *cobra.Command, args []string)
 	}
 
 	fmt.Printf("Plugin %s installed successfully!\n", pluginName)
+	if err := b.EnsurePluginPermissions(pluginName); err != nil {
+		return fmt.Errorf("plugin %s will not run until its permissions are granted: %w", pluginName, err)
+	}
 	fmt.Println("Restart devex or run 'devex plugin list' to see new commands")
 	return nil
 }
@@ -469,6 +687,12 @@ func (b *PluginBootstrap) handleRemovePlugin(cmd *cobra.Command, args []string)
 	if err := os.Remove(pluginInfo.Path); err != nil {
 		return fmt.Errorf("failed to remove plugin: %w", err)
 	}
+	if err := sdk.SavePluginSignature(b.manager.GetPluginDir(), pluginName, nil); err != nil {
+		log.Warning("Failed to remove signature of plugin %s: %v", pluginName, err)
+	}
+	if err := sdk.SavePermissionGrant(b.manager.GetPluginDir(), pluginName, nil); err != nil {
+		log.Warning("Failed to remove permissions of plugin %s: %v", pluginName, err)
+	}
 
 	fmt.Printf("Plugin %s removed successfully\n", pluginName)
 	return nil
@@ -477,14 +701,29 @@ func (b *PluginBootstrap) handleRemovePlugin(cmd *cobra.Command, args []string)
 // handleUpdatePlugins updates plugins
 func (b *PluginBootstrap) handleUpdatePlugins(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
+	allow, _ := cmd.Flags().GetBool("allow-unverified")
 	if len(args) == 0 {
+		if allow {
+			return fmt.Errorf("--allow-unverified requires a plugin name")
+		}
 		// Update all plugins
 		return b.updateAllPlugins(ctx)
 	}
 
 	// Update specific plugin
 	pluginName := args[0]
-	return b.downloader.DownloadPluginWithContext(ctx, pluginName)
+	if allow {
+		if err := b.allowUnverified(pluginName); err != nil {
+			return err
+		}
+	}
+	if err := b.downloader.DownloadPluginWithContext(ctx, pluginName); err != nil {
+		return unverifiedPluginHint(pluginName, err)
+	}
+	if err := b.manager.DiscoverPluginsWithContext(ctx); err != nil {
+		return fmt.Errorf("failed to reload plugins: %w", err)
+	}
+	return b.EnsurePluginPermissions(pluginName)
 }
 
 // handlePluginInfo shows information about a specific plugin
@@ -501,6 +740,9 @@ func (b *PluginBootstrap) handlePluginInfo(cmd *cobra.Command, args []string) er
 	fmt.Printf("Version: %s\n", pluginInfo.Version)
 	fmt.Printf("Description: %s\n", pluginInfo.Description)
 	fmt.Printf("Path: %s\n", pluginInfo.Path)
+	fmt.Printf("Signature: %s\n", b.describeVerification(pluginName, b.PluginVerification(pluginName, pluginInfo.Path)))
+	fmt.Printf("Permissions: %s\n", strings.Join(pluginInfo.Permissions.Describe(), ", "))
+	fmt.Printf("Granted: %s\n", b.describePermissionGrant(pluginName, pluginInfo.Permissions))
 	fmt.Printf("Commands:\n")
 
 	for _, pluginCmd := range pluginInfo.Commands {
@@ -527,6 +769,16 @@ func (b *PluginBootstrap) updateAllPlugins(ctx context.Context) error {
 		}
 	}
 
+	// Updated plugins may request more permissions than were granted
+	if err := b.manager.DiscoverPluginsWithContext(ctx); err != nil {
+		return fmt.Errorf("failed to reload plugins: %w", err)
+	}
+	for name := range plugins {
+		if err := b.EnsurePluginPermissions(name); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+
 	fmt.Println("Plugin update complete!")
 	return nil
 }
@@ -576,9 +828,9 @@ func (b *PluginBootstrap) handleRegistryInfo(cmd *cobra.Command, args []string)
 	// Show example configuration
 	fmt.Println("\nTo use a custom registry:")
 	fmt.Println("1. Set environment variable:")
-	fmt.Println("   export DEVEX_PLUGIN_REGISTRY_URL=https://your-registry.com/v1")
-	fmt.Println("\n2. Or add to config file (~/.devex/config.yaml):")
-	fmt.Println("   plugin_registry_url: https://your-registry.com/v1")
+	fmt.Println("   export DEVEX_PLUGIN_REGISTRY_URL=https://your-registry.com")
+	fmt.Println("\n2. Or store it in the config file (~/.devex/config.yaml):")
+	fmt.Println("   devex registry use https://your-registry.com")
 
 	return nil
 }
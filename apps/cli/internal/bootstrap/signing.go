@@ -0,0 +1,135 @@
+package bootstrap
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+// embeddedTrustRoots holds the retired and revoked keys DevEx releases signed plugins with.
+// Keys are rotated by moving the outgoing release key here with "expires" set once releases
+// are signed with the new key, and compromised key IDs are listed under "revoked".
+//
+//go:embed trust_roots.json
+var embeddedTrustRoots []byte
+
+// releaseSigningKey is the minisign public key of the key plugin releases are signed with. It
+// is set from the PLUGIN_SIGNING_PUBLIC_KEY release variable at build time, so the CLI only
+// trusts the key the release workflow holds. Development builds trust no release key and so
+// refuse every plugin that is not allowed by plugin_allow_unverified.
+var releaseSigningKey string
+
+// TrustRoots returns the keys trusted to sign plugins: the release keys built into the CLI and
+// the keys of self-hosted registries listed under plugin_trusted_keys in ~/.devex/config.yaml.
+// Keys revoked by the embedded trust roots stay revoked even when configured.
+func TrustRoots() (*sdk.Keyring, error) {
+	keyring, err := sdk.ParseKeyring(embeddedTrustRoots)
+	if err != nil {
+		return nil, fmt.Errorf("invalid embedded trust roots: %w", err)
+	}
+
+	if releaseSigningKey != "" {
+		key, err := sdk.ParsePublicKey(releaseSigningKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid release signing key: %w", err)
+		}
+		keyring.Add(sdk.TrustedKey{PublicKey: key, Comment: "DevEx release signing key"})
+	}
+
+	for _, text := range readPluginConfig().PluginTrustedKeys {
+		key, err := sdk.ParsePublicKey(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key in plugin_trusted_keys: %w", err)
+		}
+		keyring.Add(sdk.TrustedKey{PublicKey: key, Comment: "plugin_trusted_keys in ~/.devex/config.yaml"})
+	}
+	return keyring, nil
+}
+
+// AllowedUnverifiedPlugins returns the plugins the user has allowed to be installed without a
+// signature from a trusted key
+func AllowedUnverifiedPlugins() []string {
+	return readPluginConfig().PluginAllowUnverified
+}
+
+// AllowUnverifiedPluginInConfig records in ~/.devex/config.yaml that a plugin may be installed
+// and updated without a signature from a trusted key
+func AllowUnverifiedPluginInConfig(pluginName string) error {
+	if err := validatePluginName(pluginName); err != nil {
+		return fmt.Errorf("invalid plugin name: %w", err)
+	}
+	if slices.Contains(AllowedUnverifiedPlugins(), pluginName) {
+		return nil
+	}
+
+	const key = "plugin_allow_unverified"
+	return updateConfig(func(root *yaml.Node) {
+		entry := &yaml.Node{Kind: yaml.ScalarNode, Value: pluginName}
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value == key && root.Content[i+1].Kind == yaml.SequenceNode {
+				root.Content[i+1].Content = append(root.Content[i+1].Content, entry)
+				return
+			}
+			if root.Content[i].Value == key {
+				root.Content = append(root.Content[:i], root.Content[i+2:]...)
+				break
+			}
+		}
+		root.Content = append(root.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+			&yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{entry}})
+	})
+}
+
+// PluginVerification verifies an installed plugin against the signature stored when it was
+// downloaded
+func (b *PluginBootstrap) PluginVerification(pluginName, pluginPath string) sdk.SignatureVerification {
+	signature, err := sdk.LoadPluginSignature(b.manager.GetPluginDir(), pluginName)
+	if err != nil {
+		return sdk.SignatureVerification{Status: sdk.SignatureInvalid, Err: err}
+	}
+	return sdk.VerifyPluginSignature(b.keyring, pluginPath, signature)
+}
+
+// unverifiedPluginHint explains how to install a plugin that failed signature verification
+func unverifiedPluginHint(pluginName string, err error) error {
+	var sigErr *sdk.SignatureError
+	if errors.As(err, &sigErr) && !sigErr.Tampered() {
+		return fmt.Errorf("%w\nOnly install plugins without a trusted signature from sources you trust: 'devex plugin install %s --allow-unverified'", err, pluginName)
+	}
+	return err
+}
+
+// allowUnverified lets a plugin be installed without a trusted signature from now on
+func (b *PluginBootstrap) allowUnverified(pluginName string) error {
+	if err := AllowUnverifiedPluginInConfig(pluginName); err != nil {
+		return fmt.Errorf("failed to allow unverified plugin: %w", err)
+	}
+	b.downloader.AllowUnverified(pluginName)
+	fmt.Printf("⚠️  Plugin %s may be installed without a trusted signature (plugin_allow_unverified in ~/.devex/config.yaml)\n", pluginName)
+	return nil
+}
+
+// describeVerification describes the verification status of an installed plugin
+func (b *PluginBootstrap) describeVerification(pluginName string, verification sdk.SignatureVerification) string {
+	if verification.Status == sdk.SignatureVerified {
+		description := fmt.Sprintf("✅ verified, signed by key %s", verification.Key.ID)
+		if verification.Key.Comment != "" {
+			description += fmt.Sprintf(" (%s)", verification.Key.Comment)
+		}
+		return description
+	}
+
+	var sigErr *sdk.SignatureError
+	if errors.As(verification.Err, &sigErr) && !sigErr.Tampered() {
+		if slices.Contains(AllowedUnverifiedPlugins(), pluginName) {
+			return fmt.Sprintf("⚠️  %v, allowed by plugin_allow_unverified", verification.Err)
+		}
+	}
+	return fmt.Sprintf("❌ %v", verification.Err)
+}
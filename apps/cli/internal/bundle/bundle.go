@@ -0,0 +1,426 @@
+// Package bundle packs everything needed to install a set of apps on a machine without network
+// access into a single archive: the package manager plugins, the package payloads (.deb and .rpm
+// files with their dependencies, AppImages, installer scripts and mise tool tarballs) and the
+// repository signing keys. Every file is recorded in the manifest with its SHA-256 checksum and
+// verified when the bundle is extracted.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jameswlane/devex/apps/cli/internal/platform"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+const (
+	// CurrentVersion is the bundle format version written by this release
+	CurrentVersion = 1
+
+	// ManifestName is the archive entry holding the bundle manifest
+	ManifestName = "bundle.yaml"
+
+	// filesPrefix is the archive directory holding the bundled files
+	filesPrefix = "files/"
+
+	// Security limits for archive extraction
+	MaxFileSize  int64 = 4 * 1024 * 1024 * 1024  // 4GB per file
+	MaxTotalSize int64 = 32 * 1024 * 1024 * 1024 // 32GB total
+	MaxFiles           = 20000
+)
+
+// Manifest describes the contents of a bundle
+type Manifest struct {
+	Version   int       `yaml:"version"`
+	CreatedAt time.Time `yaml:"created_at"`
+	Platform  Platform  `yaml:"platform"`
+	Plugins   []File    `yaml:"plugins,omitempty"`
+	Apps      []App     `yaml:"apps"`
+}
+
+// App is a bundled application with the configuration it was bundled for
+type App struct {
+	Name   string         `yaml:"name"`
+	Config types.OSConfig `yaml:"config"`
+	Files  []File         `yaml:"files,omitempty"`
+	Keys   []File         `yaml:"keys,omitempty"` // signing keys of Config.AptSources, matched by Source
+}
+
+// File is a bundled file. Path is relative to the files directory of the archive.
+type File struct {
+	Path   string `yaml:"path"`
+	SHA256 string `yaml:"sha256"`
+	Size   int64  `yaml:"size"`
+	Source string `yaml:"source,omitempty"` // URL the file was fetched from, if any
+
+	// Signature is the minisign signature of a plugin binary from its registry entry
+	Signature string `yaml:"signature,omitempty"`
+}
+
+// Name returns the base name of the file
+func (f File) Name() string {
+	return path.Base(f.Path)
+}
+
+// Method returns the install method of the app
+func (a App) Method() string {
+	return a.Config.InstallMethod
+}
+
+// Key returns the bundled signing key fetched from source
+func (a App) Key(source string) (File, bool) {
+	for _, key := range a.Keys {
+		if key.Source == source {
+			return key, true
+		}
+	}
+	return File{}, false
+}
+
+// App returns the bundled app with the given name, ignoring case
+func (m *Manifest) App(name string) (App, bool) {
+	for _, app := range m.Apps {
+		if strings.EqualFold(app.Name, name) {
+			return app, true
+		}
+	}
+	return App{}, false
+}
+
+// files returns every file recorded in the manifest
+func (m *Manifest) files() []File {
+	files := append([]File(nil), m.Plugins...)
+	for _, app := range m.Apps {
+		files = append(files, app.Files...)
+		files = append(files, app.Keys...)
+	}
+	return files
+}
+
+// Platform is the platform a bundle installs on, written as distribution-version-architecture
+// (ubuntu-24.04-amd64) or os-architecture (darwin-arm64)
+type Platform struct {
+	OS           string `yaml:"os"`
+	Distribution string `yaml:"distribution,omitempty"`
+	Version      string `yaml:"version,omitempty"`
+	Architecture string `yaml:"architecture"`
+}
+
+// architectureAliases maps architecture names reported by uname to Go architecture names
+var architectureAliases = map[string]string{
+	"x86_64":  "amd64",
+	"aarch64": "arm64",
+	"armv7l":  "arm",
+	"i386":    "386",
+	"i686":    "386",
+}
+
+// ParsePlatform parses a platform such as ubuntu-24.04-amd64, fedora-40-arm64 or darwin-arm64
+func ParsePlatform(value string) (Platform, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(value)), "-")
+	for _, part := range parts {
+		if part == "" {
+			return Platform{}, fmt.Errorf("invalid platform %q, expected e.g. ubuntu-24.04-amd64", value)
+		}
+	}
+	if len(parts) < 2 {
+		return Platform{}, fmt.Errorf("invalid platform %q, expected e.g. ubuntu-24.04-amd64", value)
+	}
+
+	arch := parts[len(parts)-1]
+	if alias, ok := architectureAliases[arch]; ok {
+		arch = alias
+	}
+	parts = parts[:len(parts)-1]
+
+	switch {
+	case len(parts) == 1 && (parts[0] == "linux" || parts[0] == "darwin" || parts[0] == "windows"):
+		return Platform{OS: parts[0], Architecture: arch}, nil
+	case len(parts) == 1:
+		return Platform{OS: "linux", Distribution: parts[0], Architecture: arch}, nil
+	default:
+		return Platform{
+			OS:           "linux",
+			Distribution: strings.Join(parts[:len(parts)-1], "-"),
+			Version:      parts[len(parts)-1],
+			Architecture: arch,
+		}, nil
+	}
+}
+
+// PlatformOf returns the bundle platform of a detected platform
+func PlatformOf(detected platform.DetectionResult) Platform {
+	return Platform{
+		OS:           detected.OS,
+		Distribution: detected.Distribution,
+		Version:      detected.Version,
+		Architecture: detected.Architecture,
+	}
+}
+
+// String formats the platform the way ParsePlatform reads it
+func (p Platform) String() string {
+	parts := []string{p.OS}
+	if p.Distribution != "" {
+		parts = []string{p.Distribution}
+		if p.Version != "" {
+			parts = append(parts, p.Version)
+		}
+	}
+	return strings.Join(append(parts, p.Architecture), "-")
+}
+
+// Detection returns the platform as a detection result for resolving app configurations
+func (p Platform) Detection() platform.DetectionResult {
+	return platform.DetectionResult{
+		OS:           p.OS,
+		Distribution: p.Distribution,
+		Version:      p.Version,
+		Architecture: p.Architecture,
+	}
+}
+
+// PluginPlatform returns the registry platform key of plugin binaries for the platform, e.g. linux-amd64
+func (p Platform) PluginPlatform() string {
+	return p.OS + "-" + p.Architecture
+}
+
+// Check reports whether a bundle for this platform can be installed on current. The OS,
+// distribution and architecture must match; a different distribution version is allowed and
+// reported by the caller.
+func (p Platform) Check(current Platform) error {
+	if p.OS != current.OS || p.Architecture != current.Architecture ||
+		(p.Distribution != "" && p.Distribution != current.Distribution) {
+		return fmt.Errorf("bundle was created for %s and cannot be installed on %s", p, current)
+	}
+	return nil
+}
+
+// Write stores the manifest and the files it records, read from stagingDir, as a gzip-compressed
+// tar archive
+func (m *Manifest) Write(stagingDir, archivePath string) error {
+	m.Version = CurrentVersion
+	manifest, err := yaml.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to encode bundle manifest: %w", err)
+	}
+
+	if dir := filepath.Dir(archivePath); dir != "" {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return fmt.Errorf("failed to create bundle directory: %w", err)
+		}
+	}
+	out, err := os.OpenFile(archivePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle archive: %w", err)
+	}
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	writeErr := writeEntry(tw, ManifestName, int64(len(manifest)), 0600, m.CreatedAt, strings.NewReader(string(manifest)))
+	for _, file := range m.files() {
+		if writeErr != nil {
+			break
+		}
+		writeErr = writeFile(tw, stagingDir, file, m.CreatedAt)
+	}
+
+	for _, closer := range []io.Closer{tw, gz, out} {
+		if err := closer.Close(); err != nil && writeErr == nil {
+			writeErr = err
+		}
+	}
+	if writeErr != nil {
+		_ = os.Remove(archivePath)
+		return fmt.Errorf("failed to write bundle archive: %w", writeErr)
+	}
+	return nil
+}
+
+// writeFile streams a staged file into the archive
+func writeFile(tw *tar.Writer, stagingDir string, file File, modTime time.Time) error {
+	source, err := os.Open(filepath.Join(stagingDir, filepath.FromSlash(file.Path)))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = source.Close() }()
+
+	info, err := source.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() != file.Size {
+		return fmt.Errorf("%s changed while the bundle was written", file.Path)
+	}
+	return writeEntry(tw, filesPrefix+file.Path, file.Size, int64(info.Mode().Perm()), modTime, source)
+}
+
+func writeEntry(tw *tar.Writer, name string, size, mode int64, modTime time.Time, data io.Reader) error {
+	header := &tar.Header{
+		Name:     name,
+		Mode:     mode,
+		Size:     size,
+		ModTime:  modTime,
+		Typeflag: tar.TypeReg,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := io.CopyN(tw, data, size)
+	return err
+}
+
+// Bundle is an extracted bundle
+type Bundle struct {
+	Manifest
+	Dir string
+}
+
+// Path returns the location of a bundled file in the extraction directory
+func (b *Bundle) Path(file File) string {
+	return filepath.Join(b.Dir, filepath.FromSlash(file.Path))
+}
+
+// Extract unpacks a bundle archive into dir and verifies every file against the checksum
+// recorded in the manifest. Entries that are not regular files, escape the archive, exceed the
+// size limits or are not recorded in the manifest are rejected.
+func Extract(archivePath, dir string) (*Bundle, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("bundle is not a gzip archive: %w", err)
+	}
+	defer gz.Close()
+
+	var manifestData []byte
+	checksums := make(map[string]string)
+	var totalSize int64
+	tr := tar.NewReader(gz)
+	for count := 0; ; count++ {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+
+		if count >= MaxFiles {
+			return nil, fmt.Errorf("too many files in bundle (limit: %d)", MaxFiles)
+		}
+		if header.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("unsupported entry %s in bundle", header.Name)
+		}
+		if header.Size > MaxFileSize {
+			return nil, fmt.Errorf("file %s exceeds maximum size limit (%d bytes)", header.Name, MaxFileSize)
+		}
+		totalSize += header.Size
+		if totalSize > MaxTotalSize {
+			return nil, fmt.Errorf("bundle exceeds maximum size limit (%d bytes)", MaxTotalSize)
+		}
+
+		switch {
+		case header.Name == ManifestName:
+			if manifestData, err = io.ReadAll(io.LimitReader(tr, header.Size)); err != nil {
+				return nil, fmt.Errorf("failed to read %s from bundle: %w", header.Name, err)
+			}
+		case strings.HasPrefix(header.Name, filesPrefix):
+			rel := strings.TrimPrefix(header.Name, filesPrefix)
+			if err := ValidatePath(rel); err != nil {
+				return nil, err
+			}
+			sum, err := extractFile(tr, filepath.Join(dir, filepath.FromSlash(rel)), header)
+			if err != nil {
+				return nil, fmt.Errorf("failed to extract %s from bundle: %w", rel, err)
+			}
+			checksums[rel] = sum
+		default:
+			return nil, fmt.Errorf("unexpected entry %s in bundle", header.Name)
+		}
+	}
+
+	if manifestData == nil {
+		return nil, fmt.Errorf("bundle has no %s", ManifestName)
+	}
+	bundle := &Bundle{Dir: dir}
+	if err := yaml.Unmarshal(manifestData, &bundle.Manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle manifest: %w", err)
+	}
+	if bundle.Version > CurrentVersion {
+		return nil, fmt.Errorf("bundle has version %d, this release supports up to %d", bundle.Version, CurrentVersion)
+	}
+
+	recorded := make(map[string]bool, len(checksums))
+	for _, file := range bundle.files() {
+		if err := ValidatePath(file.Path); err != nil {
+			return nil, err
+		}
+		sum, ok := checksums[file.Path]
+		if !ok {
+			return nil, fmt.Errorf("bundle is missing %s", file.Path)
+		}
+		if !strings.EqualFold(sum, file.SHA256) {
+			return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", file.Path, file.SHA256, sum)
+		}
+		recorded[file.Path] = true
+	}
+	for rel := range checksums {
+		if !recorded[rel] {
+			return nil, fmt.Errorf("bundle contains %s, which is not recorded in the manifest", rel)
+		}
+	}
+	return bundle, nil
+}
+
+// extractFile writes an archive entry to target and returns its SHA-256 checksum
+func extractFile(tr *tar.Reader, target string, header *tar.Header) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+		return "", err
+	}
+	mode := os.FileMode(0600)
+	if header.FileInfo().Mode()&0100 != 0 {
+		mode = 0700
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_EXCL, mode)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	_, copyErr := io.CopyN(io.MultiWriter(out, hash), tr, header.Size)
+	if err := out.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	if copyErr != nil {
+		return "", copyErr
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// ValidatePath checks that a bundled file path stays inside the files directory of the archive
+func ValidatePath(rel string) error {
+	if rel == "" || path.IsAbs(rel) || filepath.IsAbs(rel) || strings.Contains(rel, `\`) {
+		return fmt.Errorf("security violation: invalid bundle path %q", rel)
+	}
+	if clean := path.Clean(rel); clean != rel || clean == ".." || strings.HasPrefix(clean, "../") {
+		return fmt.Errorf("security violation: invalid bundle path %q", rel)
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+package bundle_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jameswlane/devex/apps/cli/internal/bundle"
+	"github.com/jameswlane/devex/apps/cli/internal/pluginregistry"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+// fakeSources serves bundle contents from memory and records the plugin downloads it ran
+type fakeSources struct {
+	downloads []string
+	fetched   []string
+	urls      map[string]string
+}
+
+func (f *fakeSources) Plugin(_ context.Context, name, platform, dest string) ([]byte, error) {
+	return []byte("signature of " + name), os.WriteFile(dest, []byte(name+" for "+platform), 0755)
+}
+
+func (f *fakeSources) Download(_ context.Context, plugin, dir string, args []string) error {
+	f.downloads = append(f.downloads, plugin+" "+strings.Join(args, " "))
+	name := args[len(args)-1]
+	return os.WriteFile(filepath.Join(dir, name+".pkg"), []byte(plugin+" payload of "+name), 0644)
+}
+
+func (f *fakeSources) Fetch(_ context.Context, url, dest string) error {
+	f.fetched = append(f.fetched, url)
+	contents, ok := f.urls[url]
+	if !ok {
+		return fmt.Errorf("HTTP 404")
+	}
+	return os.WriteFile(dest, []byte(contents), 0600)
+}
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+var _ = Describe("Bundle", func() {
+	var (
+		ctx     context.Context
+		tempDir string
+		target  bundle.Platform
+		sources *fakeSources
+		apps    []types.CrossPlatformApp
+	)
+
+	const script = "#!/bin/sh\necho installing\n"
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		tempDir = GinkgoT().TempDir()
+		var err error
+		target, err = bundle.ParsePlatform("ubuntu-24.04-amd64")
+		Expect(err).NotTo(HaveOccurred())
+
+		sources = &fakeSources{urls: map[string]string{
+			"https://example.com/install.sh":    script,
+			"https://example.com/repo-key.gpg":  "key material",
+			"https://example.com/editor.sig":    "signature",
+			"https://example.com/editor-key.pk": "public key",
+		}}
+		apps = []types.CrossPlatformApp{
+			{
+				Name: "editor",
+				Linux: types.OSConfig{
+					InstallMethod:  "apt",
+					InstallCommand: "editor",
+					AptSources: []types.AptSource{{
+						KeySource:  "https://example.com/repo-key.gpg",
+						KeyName:    "/etc/apt/keyrings/editor.gpg",
+						SourceRepo: "deb [signed-by=/etc/apt/keyrings/editor.gpg] https://example.com/apt stable main",
+						SourceName: "/etc/apt/sources.list.d/editor.list",
+					}},
+					Alternatives: []types.OSConfig{{
+						InstallMethod:        "dnf",
+						InstallCommand:       "editor",
+						PlatformRequirements: []types.PlatformRequirement{{OS: "fedora"}},
+					}},
+				},
+			},
+			{
+				Name: "viewer",
+				Linux: types.OSConfig{
+					InstallMethod:  "appimage",
+					InstallCommand: "viewer",
+					DownloadURL:    "https://example.com/viewer.AppImage",
+					SHA256:         sha256Hex("appimage"),
+				},
+			},
+			{
+				Name: "tool",
+				Linux: types.OSConfig{
+					InstallMethod: "curlpipe",
+					DownloadURL:   "https://example.com/install.sh",
+					SHA256:        sha256Hex(script),
+				},
+			},
+		}
+	})
+
+	create := func() (*bundle.Manifest, string, error) {
+		output := filepath.Join(tempDir, "out", "bundle.tar.gz")
+		manifest, err := bundle.Create(ctx, bundle.CreateOptions{
+			Apps: apps, Platform: target, Host: target, Output: output, Sources: sources,
+		})
+		return manifest, output, err
+	}
+
+	Describe("ParsePlatform", func() {
+		It("should parse distribution and OS platforms", func() {
+			Expect(target).To(Equal(bundle.Platform{OS: "linux", Distribution: "ubuntu", Version: "24.04", Architecture: "amd64"}))
+			Expect(target.String()).To(Equal("ubuntu-24.04-amd64"))
+			Expect(target.PluginPlatform()).To(Equal("linux-amd64"))
+
+			platform, err := bundle.ParsePlatform("darwin-arm64")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(platform).To(Equal(bundle.Platform{OS: "darwin", Architecture: "arm64"}))
+
+			platform, err = bundle.ParsePlatform("opensuse-leap-15.6-x86_64")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(platform.Distribution).To(Equal("opensuse-leap"))
+			Expect(platform.Architecture).To(Equal("amd64"))
+		})
+
+		It("should reject malformed platforms", func() {
+			for _, value := range []string{"", "ubuntu", "ubuntu--amd64"} {
+				_, err := bundle.ParsePlatform(value)
+				Expect(err).To(HaveOccurred(), value)
+			}
+		})
+
+		It("should refuse bundles for another distribution or architecture", func() {
+			Expect(target.Check(bundle.Platform{OS: "linux", Distribution: "ubuntu", Version: "22.04", Architecture: "amd64"})).To(Succeed())
+			Expect(target.Check(bundle.Platform{OS: "linux", Distribution: "fedora", Version: "40", Architecture: "amd64"})).NotTo(Succeed())
+			Expect(target.Check(bundle.Platform{OS: "linux", Distribution: "ubuntu", Version: "24.04", Architecture: "arm64"})).NotTo(Succeed())
+		})
+	})
+
+	Describe("Create and Extract", func() {
+		It("should bundle payloads, keys and plugins and verify them on extraction", func() {
+			manifest, output, err := create()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(sources.downloads).To(ConsistOf(
+				"package-manager-apt editor",
+				"package-manager-appimage --sha256="+sha256Hex("appimage")+" --url=https://example.com/viewer.AppImage viewer",
+			))
+			Expect(manifest.Apps).To(HaveLen(3))
+			Expect(manifest.Plugins).To(HaveLen(3))
+
+			extracted, err := bundle.Extract(output, filepath.Join(tempDir, "extract"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(extracted.Platform).To(Equal(target))
+
+			editor, ok := extracted.App("editor")
+			Expect(ok).To(BeTrue())
+			Expect(editor.Method()).To(Equal("apt"))
+			Expect(editor.Config.Alternatives).To(BeEmpty())
+			Expect(os.ReadFile(extracted.Path(editor.Files[0]))).To(Equal([]byte("package-manager-apt payload of editor")))
+			key, ok := editor.Key("https://example.com/repo-key.gpg")
+			Expect(ok).To(BeTrue())
+			Expect(os.ReadFile(extracted.Path(key))).To(Equal([]byte("key material")))
+
+			tool, ok := extracted.App("tool")
+			Expect(ok).To(BeTrue())
+			Expect(tool.Files).To(HaveLen(1))
+			Expect(tool.Files[0].Name()).To(Equal(bundle.ScriptName))
+			Expect(tool.Files[0].SHA256).To(Equal(sha256Hex(script)))
+
+			plugin := extracted.Plugins[0]
+			Expect(plugin.Path).To(Equal("plugins/package-manager-appimage"))
+			Expect(plugin.Signature).To(Equal("signature of package-manager-appimage"))
+			info, err := os.Stat(extracted.Path(plugin))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm() & 0100).NotTo(BeZero())
+		})
+
+		It("should resolve apps for the target platform", func() {
+			var err error
+			target, err = bundle.ParsePlatform("fedora-40-amd64")
+			Expect(err).NotTo(HaveOccurred())
+			apps = apps[:1]
+
+			manifest, _, err := create()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(manifest.Apps[0].Method()).To(Equal("dnf"))
+			Expect(manifest.Apps[0].Keys).To(BeEmpty())
+			Expect(sources.downloads).To(Equal([]string{"package-manager-dnf editor"}))
+		})
+
+		It("should refuse package payloads resolved on another distribution", func() {
+			host, err := bundle.ParsePlatform("ubuntu-22.04-amd64")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = bundle.Create(ctx, bundle.CreateOptions{
+				Apps: apps, Platform: target, Host: host, Output: filepath.Join(tempDir, "bundle.tar.gz"), Sources: sources,
+			})
+			Expect(err).To(MatchError(ContainSubstring("create the bundle on ubuntu-24.04-amd64")))
+		})
+
+		It("should refuse apps that cannot be installed offline", func() {
+			apps = append(apps, types.CrossPlatformApp{
+				Name:  "database",
+				Linux: types.OSConfig{InstallMethod: "docker", InstallCommand: "postgres:16"},
+			})
+			_, output, err := create()
+			Expect(err).To(MatchError(ContainSubstring("database (docker)")))
+			Expect(output).NotTo(BeAnExistingFile())
+		})
+
+		It("should refuse installer scripts that do not match their pin", func() {
+			sources.urls["https://example.com/install.sh"] = "#!/bin/sh\necho tampered\n"
+			_, _, err := create()
+			Expect(err).To(MatchError(ContainSubstring("expected " + sha256Hex(script))))
+		})
+
+		It("should reject bundles whose files do not match the manifest", func() {
+			manifest := bundle.Manifest{
+				Version: bundle.CurrentVersion,
+				Apps: []bundle.App{{
+					Name:   "tool",
+					Config: types.OSConfig{InstallMethod: "curlpipe"},
+					Files:  []bundle.File{{Path: "apps/tool/install.sh", SHA256: sha256Hex(script), Size: int64(len(script))}},
+				}},
+			}
+			data, err := yaml.Marshal(manifest)
+			Expect(err).NotTo(HaveOccurred())
+
+			archive := filepath.Join(tempDir, "tampered.tar.gz")
+			out, err := os.Create(archive)
+			Expect(err).NotTo(HaveOccurred())
+			gz := gzip.NewWriter(out)
+			tw := tar.NewWriter(gz)
+			for name, contents := range map[string]string{
+				bundle.ManifestName:          string(data),
+				"files/apps/tool/install.sh": "#!/bin/sh\nrm -rf /\n",
+			} {
+				Expect(tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(contents)), Typeflag: tar.TypeReg})).To(Succeed())
+				_, err := tw.Write([]byte(contents))
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(tw.Close()).To(Succeed())
+			Expect(gz.Close()).To(Succeed())
+			Expect(out.Close()).To(Succeed())
+
+			_, err = bundle.Extract(archive, filepath.Join(tempDir, "extract"))
+			Expect(err).To(MatchError(ContainSubstring("checksum mismatch for apps/tool/install.sh")))
+		})
+	})
+
+	Describe("NetworkSources", func() {
+		It("should fetch plugin binaries from the registry and verify their checksums and signatures", func() {
+			binary := filepath.Join(tempDir, "package-manager-apt")
+			Expect(os.WriteFile(binary, []byte("plugin binary"), 0755)).To(Succeed())
+			registryDir := filepath.Join(tempDir, "registry")
+			_, err := pluginregistry.Publish(ctx, registryDir, pluginregistry.PublishOptions{
+				Binary: binary, Platform: "linux-amd64", Info: &sdk.PluginInfo{Name: "package-manager-apt", Version: "1.0.0"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			server, err := pluginregistry.NewServer(registryDir, "")
+			Expect(err).NotTo(HaveOccurred())
+			httpServer := httptest.NewServer(server.Handler())
+			defer httpServer.Close()
+
+			network, err := bundle.NewNetworkSources(httpServer.URL, nil)
+			Expect(err).NotTo(HaveOccurred())
+			dest := filepath.Join(tempDir, "plugin")
+			_, err = network.Plugin(ctx, "package-manager-apt", "linux-amd64", dest)
+			Expect(err).To(MatchError(ContainSubstring("plugin package-manager-apt: unsigned")))
+			Expect(dest).NotTo(BeAnExistingFile())
+
+			network.AllowUnverified = []string{"package-manager-apt"}
+			signature, err := network.Plugin(ctx, "package-manager-apt", "linux-amd64", dest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(signature).To(BeNil())
+			Expect(os.ReadFile(dest)).To(Equal([]byte("plugin binary")))
+
+			_, err = network.Plugin(ctx, "package-manager-apt", "darwin-arm64", dest)
+			Expect(err).To(MatchError(ContainSubstring("no binary for darwin-arm64")))
+
+			// Replace the published binary behind the registry's back
+			metadata, err := network.Registry.GetPlugin(ctx, "package-manager-apt")
+			Expect(err).NotTo(HaveOccurred())
+			published := filepath.Join(registryDir, strings.TrimPrefix(metadata.Binaries["linux-amd64"].URL, httpServer.URL+"/"))
+			Expect(os.WriteFile(published, []byte("tampered binary"), 0755)).To(Succeed())
+			_, err = network.Plugin(ctx, "package-manager-apt", "linux-amd64", dest)
+			Expect(err).To(MatchError(ContainSubstring("checksum mismatch")))
+			Expect(dest).NotTo(BeAnExistingFile())
+		})
+	})
+})
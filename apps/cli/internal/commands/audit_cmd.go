@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/jameswlane/devex/apps/cli/internal/audit"
+	"github.com/jameswlane/devex/apps/cli/internal/config"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+func init() {
+	Register(NewAuditCmd)
+}
+
+// NewAuditCmd creates the audit command for inspecting the log of executed commands
+func NewAuditCmd(repo types.Repository, settings config.CrossPlatformSettings) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Inspect the log of commands DevEx executed",
+		Long: `DevEx records every command it runs on your behalf in an append-only audit log at
+~/.local/share/devex/audit.jsonl: the command with secrets redacted, the user and whether
+sudo was used, the application and operation that triggered it, its exit code and duration.
+
+Each record carries the hash of the record before it, so editing, reordering or removing
+records is detected by 'devex audit verify'. Note the head hash it prints: records removed
+from the end of the log can only be detected by comparing it with a previously noted head.`,
+		Example: `  # Check that the audit log was not tampered with
+  devex audit verify
+
+  # Show what ran while installing docker
+  devex audit show --app docker
+
+  # Show failed commands of the last day
+  devex audit show --failed --since 24h`,
+	}
+
+	cmd.AddCommand(newAuditVerifyCmd(settings))
+	cmd.AddCommand(newAuditShowCmd(settings))
+
+	return cmd
+}
+
+// newAuditVerifyCmd creates the audit verify command
+func newAuditVerifyCmd(settings config.CrossPlatformSettings) *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the hash chain of the audit log",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			auditLog := audit.New(audit.DefaultPath(settings.HomeDir))
+			result, err := auditLog.Verify()
+			if err != nil {
+				return fmt.Errorf("audit log verification failed: %w", err)
+			}
+
+			if result.Records == 0 {
+				fmt.Printf("No commands recorded in %s\n", auditLog.Path())
+				return nil
+			}
+			fmt.Printf("✅ %d record(s), chain intact\n", result.Records)
+			fmt.Printf("   Head: %s\n", result.Head)
+			return nil
+		},
+	}
+}
+
+// newAuditShowCmd creates the audit show command
+func newAuditShowCmd(settings config.CrossPlatformSettings) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show recorded commands",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter := audit.Filter{}
+			filter.App, _ = cmd.Flags().GetString("app")
+			filter.Operation, _ = cmd.Flags().GetString("operation")
+			filter.Command, _ = cmd.Flags().GetString("command")
+			filter.Failed, _ = cmd.Flags().GetBool("failed")
+			filter.Limit, _ = cmd.Flags().GetInt("limit")
+			asJSON, _ := cmd.Flags().GetBool("json")
+
+			if since, _ := cmd.Flags().GetString("since"); since != "" {
+				t, err := parseAuditSince(since, time.Now())
+				if err != nil {
+					return err
+				}
+				filter.Since = t
+			}
+
+			records, err := audit.New(audit.DefaultPath(settings.HomeDir)).Records()
+			if err != nil {
+				return err
+			}
+			records = filter.Apply(records)
+
+			if asJSON {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				if records == nil {
+					records = []audit.Record{}
+				}
+				return encoder.Encode(records)
+			}
+			if len(records) == 0 {
+				fmt.Println("No matching commands recorded")
+				return nil
+			}
+			for _, record := range records {
+				printAuditRecord(record)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("app", "", "Only show commands run for this application")
+	cmd.Flags().String("operation", "", "Only show commands of this operation, e.g. install or plugin")
+	cmd.Flags().String("command", "", "Only show runs of this program, by name or path")
+	cmd.Flags().String("since", "", "Only show commands since a duration ago (24h) or a date (2006-01-02)")
+	cmd.Flags().Bool("failed", false, "Only show commands that failed")
+	cmd.Flags().Int("limit", 50, "Show at most this many of the most recent commands, 0 for all")
+	cmd.Flags().Bool("json", false, "Output the records as JSON")
+
+	return cmd
+}
+
+// parseAuditSince parses a duration before now, a date or an RFC 3339 timestamp
+func parseAuditSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since value %q: use a duration such as 24h or a date such as 2006-01-02", value)
+}
+
+// printAuditRecord shows a record on one line, e.g.
+// 2024-05-01 10:00:00  docker/install  exit 0  1.2s  sudo  apt-get install -y docker.io
+func printAuditRecord(record audit.Record) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	status := green(fmt.Sprintf("exit %d", record.ExitCode))
+	if record.ExitCode != 0 {
+		status = red(fmt.Sprintf("exit %d", record.ExitCode))
+	}
+
+	trigger := record.App
+	if record.Operation != "" {
+		trigger = strings.TrimPrefix(trigger+"/"+record.Operation, "/")
+	}
+	if trigger == "" {
+		trigger = "-"
+	}
+
+	sudo := ""
+	if record.Sudo {
+		sudo = "sudo  "
+	}
+
+	duration := (time.Duration(record.Duration) * time.Millisecond).String()
+	commandLine := strings.TrimSpace(record.Command + " " + strings.Join(record.Args, " "))
+	fmt.Printf("%s  %-24s  %s  %6s  %s%s\n",
+		record.Time.Local().Format("2006-01-02 15:04:05"), trigger, status, duration, sudo, commandLine)
+}
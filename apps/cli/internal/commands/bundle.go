@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jameswlane/devex/apps/cli/internal/bootstrap"
+	"github.com/jameswlane/devex/apps/cli/internal/bundle"
+	"github.com/jameswlane/devex/apps/cli/internal/config"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+func init() {
+	Register(NewBundleCmd)
+}
+
+// NewBundleCmd creates the bundle command for installing on machines without network access
+func NewBundleCmd(repo types.Repository, settings config.CrossPlatformSettings) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Create offline install bundles for air-gapped machines",
+		Long: `Collect everything needed to install a set of applications into a single archive
+that 'devex install --from-bundle' installs from without network access.
+
+A bundle holds the package manager plugins from the plugin registry, the package
+payloads (.deb and .rpm files with their dependencies, AppImages, installer scripts
+and mise tool versions) and the signing keys of third-party APT repositories. Every
+file is recorded with its SHA-256 checksum and verified before installation.`,
+		Example: `  # Bundle apps for an air-gapped Ubuntu 24.04 machine
+  devex bundle create --apps git,curl,neovim --platform ubuntu-24.04-amd64
+
+  # Install from the bundle on the target machine
+  devex install --from-bundle devex-bundle-ubuntu-24.04-amd64.tar.gz`,
+	}
+
+	cmd.AddCommand(newBundleCreateCmd(settings))
+
+	return cmd
+}
+
+// newBundleCreateCmd creates the bundle create command
+func newBundleCreateCmd(settings config.CrossPlatformSettings) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Write an offline install bundle for a set of applications",
+		Long: `Resolve each application and its dependencies for the target platform and collect
+their payloads into a bundle.
+
+APT, DNF and mise payloads are resolved against this machine's repositories and tool
+builds, so bundles containing them must be created on the target platform, with any
+third-party repositories of the applications already configured. AppImages and
+installer scripts can be bundled from any machine. Docker images and other install
+methods cannot be bundled.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names, _ := cmd.Flags().GetStringSlice("apps")
+			platformFlag, _ := cmd.Flags().GetString("platform")
+			output, _ := cmd.Flags().GetString("output")
+			if len(names) == 0 {
+				return fmt.Errorf("no applications specified, use --apps")
+			}
+
+			host := bundle.PlatformOf(types.CurrentPlatform())
+			target := host
+			if platformFlag != "" {
+				var err error
+				if target, err = bundle.ParsePlatform(platformFlag); err != nil {
+					return err
+				}
+			}
+			if output == "" {
+				output = fmt.Sprintf("devex-bundle-%s.tar.gz", target)
+			}
+
+			apps, err := NewInstallResolver(settings).ResolveNames(names)
+			if err != nil {
+				return err
+			}
+
+			sources, err := bundle.NewNetworkSources(bootstrap.GetRegistryURL(), runBundlePlugin)
+			if err != nil {
+				return err
+			}
+			if sources.Keyring, err = bootstrap.TrustRoots(); err != nil {
+				return err
+			}
+			sources.AllowUnverified = bootstrap.AllowedUnverifiedPlugins()
+
+			fmt.Printf("📦 Bundling %d application(s) for %s...\n", len(apps), target)
+			manifest, err := bundle.Create(cmd.Context(), bundle.CreateOptions{
+				Apps:     apps,
+				Platform: target,
+				Host:     host,
+				Output:   output,
+				Sources:  sources,
+			})
+			if err != nil {
+				return err
+			}
+
+			files, size := 0, int64(0)
+			for _, app := range manifest.Apps {
+				for _, group := range [][]bundle.File{app.Files, app.Keys} {
+					for _, file := range group {
+						files++
+						size += file.Size
+					}
+				}
+			}
+			fmt.Printf("✅ Bundle written to %s\n", output)
+			fmt.Printf("   %d application(s), %d plugin(s), %d file(s), %s\n",
+				len(manifest.Apps), len(manifest.Plugins), files, formatBytes(size))
+			if info, err := os.Stat(output); err == nil {
+				fmt.Printf("   Archive size: %s\n", formatBytes(info.Size()))
+			}
+			fmt.Printf("\nInstall it on the target machine with:\n  devex install --from-bundle %s\n", output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSlice("apps", nil, "Applications to bundle, with their dependencies")
+	cmd.Flags().String("platform", "", "Platform the bundle installs on, e.g. ubuntu-24.04-amd64 (default: this machine)")
+	cmd.Flags().StringP("output", "o", "", "Bundle file to write (default: devex-bundle-<platform>.tar.gz)")
+
+	return cmd
+}
+
+// runBundlePlugin runs a package manager plugin command, downloading the plugin on first use
+func runBundlePlugin(ctx context.Context, plugin string, args []string) error {
+	if pluginBootstrap == nil {
+		return fmt.Errorf("plugin system is not available")
+	}
+	if err := pluginBootstrap.EnsurePlugin(ctx, plugin); err != nil {
+		return err
+	}
+	return pluginBootstrap.ExecutePlugin(plugin, args)
+}
@@ -18,6 +18,8 @@ import (
 	"github.com/jameswlane/devex/apps/cli/internal/backup"
 	"github.com/jameswlane/devex/apps/cli/internal/config"
 	"github.com/jameswlane/devex/apps/cli/internal/help"
+	"github.com/jameswlane/devex/apps/cli/internal/log"
+	"github.com/jameswlane/devex/apps/cli/internal/teamsync"
 	"github.com/jameswlane/devex/apps/cli/internal/tui"
 	"github.com/jameswlane/devex/apps/cli/internal/types"
 	"github.com/jameswlane/devex/apps/cli/internal/undo"
@@ -71,6 +73,9 @@ Examples:
   
   # Validate all configuration files
   devex config validate
+
+  # Check configuration files against their schemas
+  devex config lint
   
   # Compare current config with backup
   devex config diff backup-20240817.yaml`,
@@ -83,6 +88,8 @@ Examples:
 	cmd.AddCommand(newConfigShowCmd(settings))
 	cmd.AddCommand(newConfigEditCmd(settings))
 	cmd.AddCommand(newConfigValidateCmd(settings))
+	cmd.AddCommand(newConfigLintCmd(settings))
+	cmd.AddCommand(newConfigSchemaCmd())
 	cmd.AddCommand(newConfigDiffCmd(settings))
 	cmd.AddCommand(newConfigInheritanceCmd(settings))
 	cmd.AddCommand(newConfigTeamCmd(settings))
@@ -873,8 +880,13 @@ You can initialize from your current user configs or start with minimal defaults
 // newConfigTeamSyncCmd creates the team sync subcommand
 func newConfigTeamSyncCmd(settings config.CrossPlatformSettings) *cobra.Command {
 	var (
-		branch string
-		force  bool
+		ref         string
+		branch      string
+		trustedKeys []string
+		interval    string
+		force       bool
+		yes         bool
+		background  bool
 	)
 
 	cmd := &cobra.Command{
@@ -882,19 +894,77 @@ func newConfigTeamSyncCmd(settings config.CrossPlatformSettings) *cobra.Command
 		Short: "Sync team configuration from repository",
 		Long: `Synchronize team configuration from a Git repository.
 
-This allows teams to maintain shared configurations in version control
-and sync them across team members' development environments.`,
+The team configuration directory is pinned to a branch, tag or commit. Branches
+follow the remote on every sync; tags and commits stay where they are until the
+pin is changed with --ref. The pin is remembered, so later syncs only need
+'devex config team sync'.
+
+When trusted keys are configured, the pinned revision must be signed by one of
+them: annotated tags are verified through the tag signature, everything else
+through the commit signature. GPG keys must be in your keyring and SSH keys in
+git's gpg.ssh.allowedSignersFile.
+
+Before anything is applied, sync shows how the effective configuration changes.
+Local edits in the team directory are refused unless --force is given.`,
+		Example: `  # Clone the team repository and follow its default branch
+  devex config team sync https://github.com/company/devex-team-config.git
+
+  # Pin to a signed release tag
+  devex config team sync --ref v1.4.0 --trusted-key 3AA5C34371567BD2
+
+  # Sync in the background every 12 hours
+  devex config team sync --interval 12h
+
+  # Show what would change without applying it
+  devex config team sync --dry-run`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			repoURL := ""
+			opts := teamSyncOptions{
+				ref:        ref,
+				force:      force,
+				yes:        yes,
+				background: background,
+			}
 			if len(args) > 0 {
-				repoURL = args[0]
+				opts.url = args[0]
+			}
+			if opts.ref == "" {
+				opts.ref = branch
+			}
+			if cmd.Flags().Changed("trusted-key") {
+				opts.trustedKeys = []string{}
+				for _, key := range trustedKeys {
+					if key == "" {
+						continue
+					}
+					if err := teamsync.ValidateTrustedKey(key); err != nil {
+						return err
+					}
+					opts.trustedKeys = append(opts.trustedKeys, teamsync.NormalizeFingerprint(key))
+				}
+			}
+			if cmd.Flags().Changed("interval") {
+				parsed, err := teamsync.ParseInterval(interval)
+				if err != nil {
+					return err
+				}
+				opts.interval = &parsed
 			}
-			return syncTeamConfig(settings, repoURL, branch, force)
+			opts.dryRun, _ = cmd.Flags().GetBool("dry-run")
+
+			return syncTeamConfig(cmd.Context(), settings, opts)
 		},
 	}
 
-	cmd.Flags().StringVar(&branch, "branch", "main", "Git branch to sync from")
-	cmd.Flags().BoolVar(&force, "force", false, "Force sync, overwriting local changes")
+	cmd.Flags().StringVar(&ref, "ref", "", "Branch, tag or commit to pin the team configuration to")
+	cmd.Flags().StringVar(&branch, "branch", "", "Git branch to sync from")
+	_ = cmd.Flags().MarkDeprecated("branch", "use --ref instead")
+	cmd.Flags().StringSliceVar(&trustedKeys, "trusted-key", nil, "GPG or SSH key fingerprint allowed to sign the team configuration (repeatable, replaces the stored list; pass \"\" to clear)")
+	cmd.Flags().StringVar(&interval, "interval", "", "Sync in the background at this interval, e.g. 6h (0 disables)")
+	cmd.Flags().BoolVar(&force, "force", false, "Discard local edits and replace a team directory that is not a git repository")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Apply the changes without asking for confirmation")
+	cmd.Flags().BoolVar(&background, "background", false, "Run as a background sync")
+	_ = cmd.Flags().MarkHidden("background")
 
 	return cmd
 }
@@ -954,11 +1024,44 @@ func showTeamConfigStatus(settings config.CrossPlatformSettings) error {
 		fmt.Printf("\n%s No team configuration files found\n", yellow("⚠️"))
 	}
 
-	// Check for Git repository
-	gitDir := filepath.Join(teamDir, ".git")
-	if _, err := os.Stat(gitDir); err == nil {
-		fmt.Printf("\n%s Git repository detected\n", green("🔗"))
-		fmt.Printf("Team configs are version controlled\n")
+	// Check for a synced Git repository
+	ctx := context.Background()
+	repo, err := teamsync.Open(teamDir)
+	if err != nil {
+		return nil
+	}
+	fmt.Printf("\n%s Git repository detected\n", green("🔗"))
+	if url, err := repo.RemoteURL(ctx); err == nil {
+		fmt.Printf("Remote: %s\n", url)
+	}
+	syncConfig, err := repo.LoadConfig(ctx)
+	if err != nil {
+		fmt.Printf("%s %v\n", yellow("⚠️"), err)
+		return nil
+	}
+	if syncConfig.Ref == "" {
+		fmt.Printf("Pinned to: %s\n", yellow("not synced yet, run 'devex config team sync'"))
+		return nil
+	}
+	fmt.Printf("Pinned to: %s\n", syncConfig.Ref)
+	if syncConfig.Applied != "" {
+		fmt.Printf("Applied: %s %s\n", teamsync.ShortCommit(syncConfig.Applied), repo.Subject(ctx, syncConfig.Applied))
+	}
+	if len(syncConfig.TrustedKeys) > 0 {
+		fmt.Printf("Trusted keys: %s\n", strings.Join(syncConfig.TrustedKeys, ", "))
+	} else {
+		fmt.Printf("Trusted keys: %s\n", yellow("none, signatures are not verified"))
+	}
+	if syncConfig.Interval > 0 {
+		fmt.Printf("Background sync: every %s\n", syncConfig.Interval)
+	} else {
+		fmt.Printf("Background sync: disabled\n")
+	}
+	if !syncConfig.LastSync.IsZero() {
+		fmt.Printf("Last sync: %s\n", syncConfig.LastSync.Local().Format("Jan 02 15:04"))
+	}
+	if changes, err := repo.LocalChanges(ctx); err == nil && len(changes) > 0 {
+		fmt.Printf("%s Local edits: %s\n", yellow("⚠️"), strings.Join(changes, ", "))
 	}
 
 	return nil
@@ -1062,75 +1165,229 @@ git:
 	return nil
 }
 
+// teamSyncOptions holds the parsed flags of 'config team sync'
+type teamSyncOptions struct {
+	url         string
+	ref         string
+	trustedKeys []string       // nil keeps the stored list
+	interval    *time.Duration // nil keeps the stored interval
+	force       bool
+	yes         bool
+	dryRun      bool
+	background  bool // non-interactive: apply only verified changes without local edits
+}
+
+// teamConfigFiles lists the team configuration files in the order the loader applies them,
+// relative to the team directory
+func teamConfigFiles(settings config.CrossPlatformSettings) []string {
+	envDir := filepath.ToSlash(filepath.Join("environments", settings.GetEnvironment()))
+	files := make([]string, 0, 2*len(config.CrossPlatformFiles))
+	files = append(files, config.CrossPlatformFiles...)
+	for _, file := range config.CrossPlatformFiles {
+		files = append(files, envDir+"/"+file)
+	}
+	return files
+}
+
 // syncTeamConfig syncs team configuration from a repository
-func syncTeamConfig(settings config.CrossPlatformSettings, repoURL, branch string, force bool) error {
+func syncTeamConfig(ctx context.Context, settings config.CrossPlatformSettings, opts teamSyncOptions) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := tracer.Start(ctx, "config_team_sync")
+	defer span.End()
+
 	green := color.New(color.FgGreen).SprintFunc()
 	yellow := color.New(color.FgYellow).SprintFunc()
 	cyan := color.New(color.FgCyan).SprintFunc()
 
 	teamDir := settings.GetTeamConfigDir()
 
-	if repoURL == "" {
-		// Check if already a git repository
-		gitDir := filepath.Join(teamDir, ".git")
-		if _, err := os.Stat(gitDir); os.IsNotExist(err) {
-			return fmt.Errorf("no repository URL provided and team config is not a git repository")
-		}
+	if opts.background {
+		return backgroundTeamSync(ctx, settings, teamDir)
+	}
 
-		fmt.Printf("%s Syncing team configuration from existing repository\n\n", cyan("🔄"))
+	fmt.Printf("%s Syncing team configuration\n\n", cyan("🔄"))
+	if opts.url != "" {
+		fmt.Printf("Repository: %s\n", opts.url)
+	}
+	fmt.Printf("Target: %s\n", teamDir)
 
-		// Pull from existing repository
-		ctx := context.Background()
-		cmd := exec.CommandContext(ctx, "git", "-C", teamDir, "pull", "origin", branch)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+	plan, err := teamsync.Prepare(ctx, teamsync.Options{
+		Dir:         teamDir,
+		URL:         opts.url,
+		Ref:         opts.ref,
+		TrustedKeys: opts.trustedKeys,
+		Interval:    opts.interval,
+		Files:       teamConfigFiles(settings),
+		UserDir:     settings.GetUserConfigDir(),
+		Force:       opts.force,
+	})
+	if err != nil {
+		return err
+	}
+	defer plan.Discard()
 
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("failed to pull from repository: %w", err)
-		}
+	printTeamSyncPlan(plan)
 
-		fmt.Printf("\n%s Team configuration synced successfully!\n", green("✅"))
+	if plan.UpToDate() {
+		if opts.dryRun {
+			return nil
+		}
+		// Still save a changed pin, key list or interval
+		if err := plan.Apply(ctx); err != nil {
+			return err
+		}
+		fmt.Printf("\n%s Team configuration is up to date\n", green("✅"))
 		return nil
 	}
 
-	fmt.Printf("%s Syncing team configuration from repository\n\n", cyan("🔄"))
-	fmt.Printf("Repository: %s\n", repoURL)
-	fmt.Printf("Branch: %s\n", branch)
-	fmt.Printf("Target: %s\n\n", teamDir)
+	if opts.dryRun {
+		fmt.Printf("\n%s Dry run, no changes applied\n", yellow("ℹ️"))
+		return nil
+	}
 
-	// Check if directory exists and has content
-	if _, err := os.Stat(teamDir); !os.IsNotExist(err) && !force {
-		if entries, err := os.ReadDir(teamDir); err == nil && len(entries) > 0 {
-			fmt.Printf("%s Team directory exists and is not empty\n", yellow("⚠️"))
-			fmt.Printf("Use --force to overwrite existing configuration\n")
+	if !opts.yes {
+		fmt.Printf("\nApply these changes? [y/N]: ")
+		var response string
+		_, _ = fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+			fmt.Println("Sync cancelled")
 			return nil
 		}
 	}
 
-	// Remove existing directory if forcing
-	if force {
-		if err := os.RemoveAll(teamDir); err != nil {
-			return fmt.Errorf("failed to remove existing team config: %w", err)
+	if err := plan.Apply(ctx); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%s Team configuration synced to %s\n", green("🎉"), teamsync.ShortCommit(plan.Commit))
+	fmt.Printf("Run 'devex config inheritance' to see the full hierarchy\n")
+	return nil
+}
+
+// printTeamSyncPlan shows the pinned revision and how the effective configuration changes
+func printTeamSyncPlan(plan *teamsync.Plan) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+
+	fmt.Printf("Pinned to: %s %s\n", plan.Kind, plan.Config.Ref)
+	fmt.Printf("Revision: %s %s\n", teamsync.ShortCommit(plan.Commit), plan.Subject)
+	if plan.Signer != "" {
+		fmt.Printf("Signature: %s signed by trusted key %s\n", green("✓"), plan.Signer)
+	} else {
+		fmt.Printf("Signature: %s not verified, no trusted keys configured\n", yellow("-"))
+	}
+	if plan.Previous != "" && plan.Previous != plan.Commit {
+		fmt.Printf("Currently applied: %s\n", teamsync.ShortCommit(plan.Previous))
+	}
+
+	if len(plan.LocalChanges) > 0 {
+		fmt.Printf("\n%s Local edits that will be discarded:\n", yellow("⚠️"))
+		for _, file := range plan.LocalChanges {
+			fmt.Printf("  %s\n", file)
 		}
 	}
 
-	// Clone repository
-	ctx := context.Background()
-	cmd := exec.CommandContext(ctx, "git", "clone", "-b", branch, repoURL, teamDir)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	if plan.Commit == plan.Previous {
+		return
+	}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to clone repository: %w", err)
+	if len(plan.Files) > 0 {
+		fmt.Printf("\n%s Changed files:\n", cyan("📁"))
+		for _, file := range plan.Files {
+			fmt.Printf("  %s\n", file)
+		}
 	}
 
-	fmt.Printf("\n%s Team configuration synced successfully!\n", green("🎉"))
-	fmt.Printf("\nTeam configuration is now available at: %s\n", teamDir)
-	fmt.Printf("Run 'devex config inheritance' to see the full hierarchy\n")
+	if len(plan.Changes) == 0 {
+		fmt.Printf("\n%s No changes to the effective configuration\n", green("✓"))
+		return
+	}
+
+	fmt.Printf("\n%s Effective configuration changes:\n", cyan("📋"))
+	for _, change := range plan.Changes {
+		note := ""
+		if change.Shadowed {
+			note = yellow(" (overridden by your user configuration)")
+		}
+		switch change.Kind {
+		case teamsync.ChangeAdded:
+			fmt.Printf("\n%s %s in %s%s\n", green("+"), change.Key, change.File, note)
+		case teamsync.ChangeRemoved:
+			fmt.Printf("\n%s %s from %s%s\n", red("-"), change.Key, change.File, note)
+		default:
+			fmt.Printf("\n%s %s in %s%s\n", yellow("~"), change.Key, change.File, note)
+		}
+		for _, line := range strings.Split(strings.TrimRight(change.Diff(), "\n"), "\n") {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				fmt.Printf("    %s\n", green(line))
+			case strings.HasPrefix(line, "-"):
+				fmt.Printf("    %s\n", red(line))
+			default:
+				fmt.Printf("    %s\n", line)
+			}
+		}
+	}
+}
 
+// backgroundTeamSync applies the pinned revision without prompting. Runs with local edits or
+// failed verification are logged and leave the team directory untouched.
+func backgroundTeamSync(ctx context.Context, settings config.CrossPlatformSettings, teamDir string) error {
+	plan, err := teamsync.Prepare(ctx, teamsync.Options{
+		Dir:     teamDir,
+		Files:   teamConfigFiles(settings),
+		UserDir: settings.GetUserConfigDir(),
+	})
+	if err != nil {
+		log.Warn("Background team config sync skipped", "dir", teamDir, "error", err)
+		return err
+	}
+	defer plan.Discard()
+
+	if plan.UpToDate() {
+		return nil
+	}
+	if err := plan.Apply(ctx); err != nil {
+		log.Warn("Background team config sync failed", "dir", teamDir, "error", err)
+		return err
+	}
+	log.Info("Team configuration synced in the background", "commit", teamsync.ShortCommit(plan.Commit), "changes", len(plan.Changes))
 	return nil
 }
 
+// startBackgroundTeamSync launches a detached 'config team sync --background' when the team
+// repository has a sync interval and the last sync is older than it
+func startBackgroundTeamSync(settings config.CrossPlatformSettings) {
+	ctx := context.Background()
+	repo, err := teamsync.Open(settings.GetTeamConfigDir())
+	if err != nil {
+		return
+	}
+	cfg, err := repo.LoadConfig(ctx)
+	if err != nil || !cfg.Due(time.Now()) {
+		return
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return
+	}
+	// Record the attempt first so concurrent commands don't start more syncs
+	if err := repo.MarkSynced(ctx, time.Now()); err != nil {
+		return
+	}
+	cmd := exec.Command(executable, "config", "team", "sync", "--background")
+	if err := cmd.Start(); err != nil {
+		log.Warn("Failed to start background team config sync", "error", err)
+		return
+	}
+	_ = cmd.Process.Release()
+}
+
 // newConfigEnvironmentCmd creates the environment subcommand
 func newConfigEnvironmentCmd(settings config.CrossPlatformSettings) *cobra.Command {
 	cmd := &cobra.Command{
@@ -2176,6 +2433,7 @@ func newConfigBackupCreateCmd(settings config.CrossPlatformSettings) *cobra.Comm
 		description string
 		tags        []string
 		compress    bool
+		paths       []string
 	)
 
 	cmd := &cobra.Command{
@@ -2191,12 +2449,20 @@ Examples:
   devex config backup create --description "Before major update"
   
   # Create compressed backup with tags
-  devex config backup create --compress --tags "stable,pre-update"`,
+  devex config backup create --compress --tags "stable,pre-update"
+
+  # Also back up dotfiles and desktop settings
+  devex config backup create --path ~/.zshrc --path ~/.config/dconf
+
+Backups are stored deduplicated in ~/.devex/backups/store. Set
+DEVEX_BACKUP_TARGET to a directory, an rsync path such as user@host:backups,
+or s3://bucket/prefix to store them elsewhere, and DEVEX_BACKUP_PASSPHRASE or
+DEVEX_BACKUP_IDENTITY to encrypt a new store.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Check for --no-tui flag
 			noTUI, _ := cmd.Flags().GetBool("no-tui")
 
-			if !noTUI {
+			if !noTUI && len(paths) == 0 {
 				return runConfigBackupWithProgress(settings, description, tags, compress)
 			}
 
@@ -2210,6 +2476,7 @@ Examples:
 				Tags:        tags,
 				Compress:    compress,
 				MaxBackups:  backup.MaxBackups,
+				Paths:       paths,
 			})
 
 			if err != nil {
@@ -2219,6 +2486,10 @@ Examples:
 			green := color.New(color.FgGreen).SprintFunc()
 			fmt.Printf("%s Created backup: %s\n", green("✓"), backupMetadata.ID)
 			fmt.Printf("  Size: %s\n", formatBytes(backupMetadata.Size))
+			if backupMetadata.Format == backup.FormatChunked {
+				fmt.Printf("  Uploaded: %s\n", formatBytes(backupMetadata.StoredSize))
+				fmt.Printf("  Target: %s\n", backupMetadata.Target)
+			}
 			fmt.Printf("  Files: %d\n", len(backupMetadata.Files))
 			if description != "" {
 				fmt.Printf("  Description: %s\n", description)
@@ -2234,6 +2505,7 @@ Examples:
 	cmd.Flags().StringVarP(&description, "description", "d", "", "Backup description")
 	cmd.Flags().StringSliceVarP(&tags, "tags", "t", []string{}, "Tags for the backup")
 	cmd.Flags().BoolVarP(&compress, "compress", "c", true, "Compress the backup")
+	cmd.Flags().StringArrayVarP(&paths, "path", "p", []string{}, "Additional file or directory to back up, such as a dotfile")
 	cmd.Flags().Bool("no-tui", false, "Disable TUI progress display")
 
 	return cmd
@@ -2298,6 +2570,9 @@ Examples:
 					fmt.Printf("  Created: %s\n", b.Timestamp.Format("2006-01-02 15:04:05"))
 					fmt.Printf("  Type: %s\n", b.Type)
 					fmt.Printf("  Size: %s\n", formatBytes(b.Size))
+					if b.Encryption != "" && b.Encryption != backup.EncryptionNone {
+						fmt.Printf("  Encryption: %s\n", b.Encryption)
+					}
 					if b.Description != "" {
 						fmt.Printf("  Description: %s\n", b.Description)
 					}
@@ -2333,6 +2608,11 @@ func newConfigBackupRestoreCmd(settings config.CrossPlatformSettings) *cobra.Com
 This operation will create a pre-restore backup automatically
 before applying the selected backup.
 
+Files backed up from the home directory are restored into your
+home directory. Other paths are restored only when this machine
+backed them up with --path, or after a confirmation, which
+--force does not give.
+
 Examples:
   # Restore a specific backup
   devex config backup restore backup-20240817-143022
@@ -2375,6 +2655,22 @@ Examples:
 					fmt.Println("Restore cancelled")
 					return nil
 				}
+
+				// Paths outside the home directory that this machine never backed up,
+				// such as those of a snapshot from a shared store, need another confirmation
+				manager.SetPathConfirmation(func(paths []string) bool {
+					fmt.Printf("\n%s This backup also restores files outside your home directory:\n", yellow("⚠"))
+					for _, path := range paths {
+						fmt.Printf("  %s\n", path)
+					}
+					fmt.Printf("Restore them? [y/N]: ")
+
+					var response string
+					if _, err := fmt.Scanln(&response); err != nil {
+						return false
+					}
+					return strings.ToLower(response) == "y"
+				})
 			}
 
 			// Perform restore
@@ -0,0 +1,203 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/jameswlane/devex/apps/cli/internal/config"
+	"github.com/jameswlane/devex/apps/cli/internal/configschema"
+)
+
+// pluginListTimeout bounds the registry lookup of the plugins providing install methods
+const pluginListTimeout = 10 * time.Second
+
+// newConfigLintCmd creates the lint subcommand
+func newConfigLintCmd(settings config.CrossPlatformSettings) *cobra.Command {
+	var (
+		format string
+		strict bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "lint [paths...]",
+		Short: "Check configuration files against their schemas",
+		Long: `Check application, setup and security files against their JSON Schemas and
+against each other, reporting each problem with its line and column.
+
+Besides the schema, lint checks that:
+  • dependencies and conflicts name apps that exist
+  • install methods are provided by a plugin (skipped with --offline)
+  • alternatives can be selected and version constraints parse
+  • setup navigation points at existing steps
+  • security override patterns are valid regular expressions
+
+Without paths, the default, team and user configuration directories are
+linted together, so user apps may satisfy dependencies of default apps.
+
+Examples:
+  # Lint the active configuration
+  devex config lint
+
+  # Lint an application catalog in a repository
+  devex config lint config/
+
+  # Fail on warnings too, with machine-readable output
+  devex config lint --strict --format json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigLint(cmd.Context(), settings, args, format, strict)
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "Output format (text, json)")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Fail on warnings as well as errors")
+
+	return cmd
+}
+
+// newConfigSchemaCmd creates the schema subcommand
+func newConfigSchemaCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema <app|setup|security>",
+		Short: "Print the JSON Schema of a configuration file",
+		Long: `Print the JSON Schema of application, setup or security files.
+
+Editors can use the schema for completion and validation, e.g. with the YAML
+language server:
+  # yaml-language-server: $schema=./app.schema.json
+
+Examples:
+  devex config schema app > app.schema.json`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{string(configschema.KindApp), string(configschema.KindSetup), string(configschema.KindSecurity)},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kind, err := configschema.ParseKind(args[0])
+			if err != nil {
+				return err
+			}
+			schema, err := configschema.For(kind)
+			if err != nil {
+				return err
+			}
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(schema)
+		},
+	}
+}
+
+func runConfigLint(ctx context.Context, settings config.CrossPlatformSettings, paths []string, format string, strict bool) error {
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unsupported format %q, expected text or json", format)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if len(paths) == 0 {
+		for _, dir := range []string{settings.GetConfigDir(), settings.GetTeamConfigDir(), settings.GetUserConfigDir()} {
+			if _, err := os.Stat(dir); err == nil {
+				paths = append(paths, dir)
+			}
+		}
+		if len(paths) == 0 {
+			return fmt.Errorf("no configuration directory found, run 'devex init' or pass the files to lint")
+		}
+	}
+
+	plugins := availablePluginNames(ctx)
+	result, err := configschema.Lint(paths, configschema.Options{Plugins: plugins})
+	if err != nil {
+		return err
+	}
+
+	errors := result.Count(configschema.SeverityError)
+	warnings := result.Count(configschema.SeverityWarning)
+
+	if format == "json" {
+		diagnostics := result.Diagnostics
+		if diagnostics == nil {
+			diagnostics = []configschema.Diagnostic{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(diagnostics); err != nil {
+			return err
+		}
+	} else {
+		printLintResult(result, plugins == nil)
+	}
+
+	if errors > 0 || (strict && warnings > 0) {
+		return fmt.Errorf("lint failed with %d error(s) and %d warning(s)", errors, warnings)
+	}
+	return nil
+}
+
+func printLintResult(result *configschema.Result, pluginsSkipped bool) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+
+	for _, d := range result.Diagnostics {
+		severity := red(string(d.Severity))
+		if d.Severity == configschema.SeverityWarning {
+			severity = yellow(string(d.Severity))
+		}
+		location := fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+		if d.Field != "" {
+			fmt.Printf("%s: %s: %s: %s\n", location, severity, cyan(d.Field), d.Message)
+		} else {
+			fmt.Printf("%s: %s: %s\n", location, severity, d.Message)
+		}
+	}
+
+	if pluginsSkipped {
+		fmt.Printf("%s Plugins unavailable, install methods were not checked\n", yellow("ℹ️"))
+	}
+
+	errors := result.Count(configschema.SeverityError)
+	warnings := result.Count(configschema.SeverityWarning)
+	if errors == 0 && warnings == 0 {
+		fmt.Printf("%s %d file(s) checked, no problems found\n", green("✅"), len(result.Files))
+		return
+	}
+	fmt.Printf("\n%d file(s) checked: %s, %s\n", len(result.Files),
+		red(fmt.Sprintf("%d error(s)", errors)), yellow(fmt.Sprintf("%d warning(s)", warnings)))
+}
+
+// availablePluginNames lists the plugins in the registry and the installed plugins. It
+// returns nil when neither is known, e.g. offline without installed plugins.
+func availablePluginNames(ctx context.Context) map[string]bool {
+	pb := GetPluginBootstrap()
+	if pb == nil {
+		return nil
+	}
+
+	names := map[string]bool{}
+	if !offlineMode {
+		ctx, cancel := context.WithTimeout(ctx, pluginListTimeout)
+		defer cancel()
+		if available, err := pb.GetAvailablePlugins(ctx); err == nil {
+			for name := range available {
+				names[name] = true
+			}
+		}
+	}
+	if manager := pb.GetManager(); manager != nil {
+		for name := range manager.ListPlugins() {
+			names[name] = true
+		}
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+	return names
+}
@@ -1,12 +1,22 @@
 package commands
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"math"
+	"os"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"gopkg.in/yaml.v3"
 
 	"github.com/jameswlane/devex/apps/cli/internal/config"
+	"github.com/jameswlane/devex/apps/cli/internal/log"
+	"github.com/jameswlane/devex/apps/cli/internal/tui"
 	"github.com/jameswlane/devex/apps/cli/internal/types"
 )
 
@@ -17,17 +27,16 @@ func NewDetectCmd(repo types.Repository, settings config.CrossPlatformSettings)
 		Short: "Detect technologies and suggest configurations",
 		Long: `Analyze the current project to detect technologies and provide smart configuration recommendations.
 
-The detect command scans your project directory to identify:
+The detect command runs the stack-detector plugin, which is downloaded on first use,
+and scans your project directory to identify:
 • Programming languages (Python, Go, Node.js, etc.)
 • Frameworks (React, Django, Rails, etc.)
 • Tools and infrastructure (Docker, Kubernetes, Terraform, etc.)
 • Databases (PostgreSQL, MongoDB, etc.)
 • CI/CD systems (GitHub Actions, GitLab CI, etc.)
 
-Based on detected technologies, it provides intelligent suggestions for:
-• Applications to install
-• Environment configurations to set up
-• System tools to configure
+Detected technologies are mapped to applications in your configuration,
+e.g. go.mod suggests Go (installed with mise) and docker-compose.yml suggests docker.
 
 Examples:
   # Detect technologies in current directory
@@ -39,23 +48,23 @@ Examples:
   # Detect and save results to file
   devex detect --output detection-results.json
 
-  # Apply suggested configurations automatically
+  # Install the suggested applications
   devex detect --apply`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runDetect(cmd, args, settings)
+			return runDetect(cmd, repo, settings)
 		},
 	}
 
 	// Add subcommands
 	cmd.AddCommand(newDetectAnalyzeCmd(settings))
 	cmd.AddCommand(newDetectSuggestCmd(settings))
-	cmd.AddCommand(newDetectApplyCmd(settings))
+	cmd.AddCommand(newDetectApplyCmd(repo, settings))
 
 	// Flags for the main detect command
 	cmd.Flags().Bool("detailed", false, "Show detailed detection analysis")
 	cmd.Flags().String("output", "", "Save detection results to file (JSON format)")
 	cmd.Flags().String("dir", "", "Directory to analyze (default: current directory)")
-	cmd.Flags().Bool("apply", false, "Apply suggested configurations automatically")
+	cmd.Flags().Bool("apply", false, "Install the suggested applications after confirmation")
 	cmd.Flags().Float64("confidence", 0.5, "Minimum confidence threshold (0.0-1.0)")
 
 	return cmd
@@ -67,12 +76,9 @@ func newDetectAnalyzeCmd(settings config.CrossPlatformSettings) *cobra.Command {
 		Use:   "analyze [directory]",
 		Short: "Analyze project structure and detect technologies",
 		Long:  `Perform detailed analysis of project structure to detect technologies and frameworks.`,
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			dir := ""
-			if len(args) > 0 {
-				dir = args[0]
-			}
-			return runDetectAnalyze(dir, settings)
+			return runDetectAnalyze(cmd, detectDir(args))
 		},
 	}
 
@@ -85,93 +91,281 @@ func newDetectAnalyzeCmd(settings config.CrossPlatformSettings) *cobra.Command {
 // newDetectSuggestCmd creates the suggest subcommand
 func newDetectSuggestCmd(settings config.CrossPlatformSettings) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "suggest",
-		Short: "Show configuration suggestions based on detected technologies",
-		Long:  `Display intelligent configuration suggestions based on detected project technologies.`,
+		Use:   "suggest [directory]",
+		Short: "Show application suggestions based on detected technologies",
+		Long:  `Display the applications from your configuration that match the detected project technologies.`,
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runDetectSuggest(settings)
+			return runDetectSuggest(cmd, detectDir(args), settings)
 		},
 	}
 
-	cmd.Flags().String("category", "", "Filter suggestions by category (application, environment, system)")
+	cmd.Flags().String("category", "", "Filter suggestions by category (application, environment)")
 	cmd.Flags().String("priority", "", "Filter suggestions by priority (critical, recommended, optional)")
 
 	return cmd
 }
 
 // newDetectApplyCmd creates the apply subcommand
-func newDetectApplyCmd(settings config.CrossPlatformSettings) *cobra.Command {
+func newDetectApplyCmd(repo types.Repository, settings config.CrossPlatformSettings) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "apply",
-		Short: "Apply suggested configurations automatically",
-		Long: `Automatically apply configuration suggestions based on detected technologies.
-
-This command will:
-• Create configuration files for detected technologies
-• Add recommended applications to your DevEx configuration
-• Set up environment-specific configurations if needed
-
-Use with caution in production environments.`,
+		Use:   "apply [directory]",
+		Short: "Install the applications suggested for a project",
+		Long: `Install the applications suggested for the detected project technologies.
+
+The suggested applications and their dependencies are listed and installed after
+confirmation. Technologies without a matching application in your configuration
+are reported and skipped.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runDetectApply(settings)
+			return runDetectApply(cmd, detectDir(args), repo, settings)
 		},
 	}
 
-	cmd.Flags().Bool("dry-run", false, "Show what would be applied without making changes")
+	cmd.Flags().Bool("dry-run", false, "Show what would be installed without making changes")
 	cmd.Flags().String("priority", "critical,recommended", "Apply suggestions with these priorities")
-	cmd.Flags().Bool("force", false, "Overwrite existing configurations")
+	cmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
 
 	return cmd
 }
 
+// detectDir returns the directory argument, defaulting to the current directory
+func detectDir(args []string) string {
+	if len(args) > 0 && args[0] != "" {
+		return args[0]
+	}
+	return "."
+}
+
 // runDetect runs the main detect command
-func runDetect(cmd *cobra.Command, args []string, settings config.CrossPlatformSettings) error {
-	yellow := color.New(color.FgYellow).SprintFunc()
-	cyan := color.New(color.FgCyan).SprintFunc()
+func runDetect(cmd *cobra.Command, repo types.Repository, settings config.CrossPlatformSettings) error {
+	detailed, _ := cmd.Flags().GetBool("detailed")
+	output, _ := cmd.Flags().GetString("output")
+	dir, _ := cmd.Flags().GetString("dir")
+	apply, _ := cmd.Flags().GetBool("apply")
+	confidence, _ := cmd.Flags().GetFloat64("confidence")
+
+	if confidence < 0 || confidence > 1 {
+		return fmt.Errorf("confidence must be between 0.0 and 1.0, got %.2f", confidence)
+	}
+	if dir == "" {
+		dir = "."
+	}
+
+	report, err := RunStackDetector(cmd.Context(), dir)
+	if err != nil {
+		return err
+	}
+	printStackReport(report, detailed)
+
+	if output != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode detection results: %w", err)
+		}
+		if err := os.WriteFile(output, data, 0600); err != nil {
+			return fmt.Errorf("failed to save detection results: %w", err)
+		}
+		fmt.Printf("📄 Detection results saved to %s\n\n", output)
+	}
 
-	fmt.Printf("%s Technology Detection\n\n", cyan("🔍"))
-	fmt.Printf("%s This feature requires the stack-detector plugin.\n", yellow("⚠️"))
-	fmt.Printf("To install and use the stack detector:\n\n")
-	fmt.Printf("1. The plugin will be automatically downloaded on first use\n")
-	fmt.Printf("2. Run: devex plugin run tool-stackdetector detect\n")
-	fmt.Printf("3. Or configure auto-detection in your devex config\n\n")
-	fmt.Printf("For more information, see: https://docs.devex.sh/plugins/stack-detector\n")
+	suggestions := SuggestApps(report, NewInstallResolver(settings), int(math.Ceil(confidence*10)))
+	printSuggestions(suggestions)
 
+	if apply {
+		return applySuggestions(cmd.Context(), FilterSuggestions(suggestions, "critical,recommended", ""), false, false, repo, settings)
+	}
 	return nil
 }
 
 // runDetectAnalyze runs the analyze subcommand
-func runDetectAnalyze(dir string, settings config.CrossPlatformSettings) error {
-	yellow := color.New(color.FgYellow).SprintFunc()
-	cyan := color.New(color.FgCyan).SprintFunc()
+func runDetectAnalyze(cmd *cobra.Command, dir string) error {
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	format, _ := cmd.Flags().GetString("format")
 
-	fmt.Printf("%s Detailed Technology Analysis\n\n", cyan("🔬"))
-	fmt.Printf("%s This feature requires the stack-detector plugin.\n", yellow("⚠️"))
-	fmt.Printf("Run: devex plugin run tool-stackdetector analyze %s\n", dir)
+	report, err := RunStackDetector(cmd.Context(), dir)
+	if err != nil {
+		return err
+	}
 
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode analysis: %w", err)
+		}
+		fmt.Println(string(data))
+	case "yaml":
+		data, err := yaml.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("failed to encode analysis: %w", err)
+		}
+		fmt.Print(string(data))
+	case "table":
+		printStackReport(report, verbose)
+		printStackAnalysis(report)
+	default:
+		return fmt.Errorf("invalid format '%s'. Valid formats: table, json, yaml", format)
+	}
 	return nil
 }
 
 // runDetectSuggest runs the suggest subcommand
-func runDetectSuggest(settings config.CrossPlatformSettings) error {
-	yellow := color.New(color.FgYellow).SprintFunc()
-	cyan := color.New(color.FgCyan).SprintFunc()
+func runDetectSuggest(cmd *cobra.Command, dir string, settings config.CrossPlatformSettings) error {
+	category, _ := cmd.Flags().GetString("category")
+	priority, _ := cmd.Flags().GetString("priority")
 
-	fmt.Printf("%s Configuration Suggestions\n\n", cyan("💡"))
-	fmt.Printf("%s This feature requires the stack-detector plugin.\n", yellow("⚠️"))
-	fmt.Printf("Run: devex plugin run tool-stackdetector suggest\n")
+	report, err := RunStackDetector(cmd.Context(), dir)
+	if err != nil {
+		return err
+	}
 
+	suggestions := SuggestApps(report, NewInstallResolver(settings), 0)
+	printSuggestions(FilterSuggestions(suggestions, priority, category))
 	return nil
 }
 
 // runDetectApply runs the apply subcommand
-func runDetectApply(settings config.CrossPlatformSettings) error {
+func runDetectApply(cmd *cobra.Command, dir string, repo types.Repository, settings config.CrossPlatformSettings) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	priority, _ := cmd.Flags().GetString("priority")
+	force, _ := cmd.Flags().GetBool("force")
+
+	report, err := RunStackDetector(cmd.Context(), dir)
+	if err != nil {
+		return err
+	}
+
+	suggestions := FilterSuggestions(SuggestApps(report, NewInstallResolver(settings), 0), priority, "")
+	printSuggestions(suggestions)
+	return applySuggestions(cmd.Context(), suggestions, dryRun, force, repo, settings)
+}
+
+// applySuggestions installs the configured apps among the suggestions after confirmation
+func applySuggestions(ctx context.Context, suggestions []StackSuggestion, dryRun, force bool, repo types.Repository, settings config.CrossPlatformSettings) error {
+	ctx, span := tracer.Start(ctx, "detect_apply")
+	defer span.End()
+
+	var names []string
+	for _, suggestion := range suggestions {
+		if suggestion.Configured {
+			names = append(names, suggestion.App)
+		}
+	}
+	if len(names) == 0 {
+		fmt.Println("Nothing to install.")
+		return nil
+	}
+	span.SetAttributes(attribute.StringSlice("apps", names))
+
+	apps, err := NewInstallResolver(settings).ResolveNames(names)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if dryRun {
+		return previewInstallation(apps)
+	}
+
+	if !force {
+		fmt.Printf("Install %s? [y/N]: ", strings.Join(names, ", "))
+		var response string
+		if _, err := fmt.Scanln(&response); err != nil || strings.ToLower(response) != "y" {
+			fmt.Println("Installation cancelled")
+			return nil
+		}
+	}
+
+	log.Info("Installing applications suggested by stack detection", "apps", names)
+	if err := tui.StartInstallation(ctx, apps, repo, settings); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Installation failed")
+		return fmt.Errorf("installation failed: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Suggested applications installed")
+	return nil
+}
+
+// printStackReport lists the detected technologies
+func printStackReport(report *StackReport, detailed bool) {
+	cyan := color.New(color.FgCyan).SprintFunc()
+
+	fmt.Printf("%s Technology Detection: %s\n\n", cyan("🔍"), report.ProjectPath)
+	if len(report.Technologies) == 0 {
+		fmt.Println("No recognizable technology stack detected.")
+		fmt.Println()
+		return
+	}
+
+	for _, tech := range report.Technologies {
+		fmt.Printf("  %s %-24s %s\n", confidenceSymbol(tech.Confidence), tech.Name, tech.Category)
+		if detailed {
+			if tech.Description != "" {
+				fmt.Printf("     %s\n", tech.Description)
+			}
+			if len(tech.Files) > 0 {
+				fmt.Printf("     files: %s\n", strings.Join(tech.Files, ", "))
+			}
+		}
+	}
+	fmt.Println()
+}
+
+// printStackAnalysis shows the summary, recommendations and issues of a report
+func printStackAnalysis(report *StackReport) {
 	yellow := color.New(color.FgYellow).SprintFunc()
+
+	summary := report.Summary
+	fmt.Printf("Primary language: %s\n", summary.PrimaryLanguage)
+	fmt.Printf("Project type:     %s\n", summary.ProjectType)
+	fmt.Printf("Complexity:       %s\n", summary.ComplexityLevel)
+	fmt.Printf("Maturity:         %s\n\n", summary.MaturityLevel)
+
+	for _, recommendation := range report.Recommendations {
+		fmt.Printf("  💡 %s\n", recommendation)
+	}
+	for _, issue := range report.Issues {
+		fmt.Printf("  %s %s\n", yellow("⚠️"), issue)
+	}
+	if summary.RecommendedAction != "" {
+		fmt.Printf("\nNext step: %s\n", summary.RecommendedAction)
+	}
+}
+
+// printSuggestions lists the suggested apps and whether they can be installed
+func printSuggestions(suggestions []StackSuggestion) {
 	cyan := color.New(color.FgCyan).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
 
-	fmt.Printf("%s Applying Detection Suggestions\n\n", cyan("🔧"))
-	fmt.Printf("%s This feature requires the stack-detector plugin.\n", yellow("⚠️"))
-	fmt.Printf("Run: devex plugin run tool-stackdetector apply\n")
+	fmt.Printf("%s Suggested Applications\n\n", cyan("💡"))
+	if len(suggestions) == 0 {
+		fmt.Println("No applications to suggest for the detected technologies.")
+		fmt.Println()
+		return
+	}
 
-	return nil
+	for _, suggestion := range suggestions {
+		reason := strings.Join(suggestion.Technologies, ", ")
+		if suggestion.Configured {
+			fmt.Printf("  %-16s %-12s (%s)\n", suggestion.App, suggestion.Priority, reason)
+		} else {
+			fmt.Printf("  %-16s %s (%s)\n", suggestion.App, yellow("not in your configuration"), reason)
+		}
+	}
+	fmt.Println()
+}
+
+// confidenceSymbol returns a symbol for a 1-10 confidence
+func confidenceSymbol(confidence int) string {
+	switch {
+	case confidence >= 9:
+		return "🟢"
+	case confidence >= 7:
+		return "🟡"
+	case confidence >= 5:
+		return "🟠"
+	default:
+		return "🔴"
+	}
 }
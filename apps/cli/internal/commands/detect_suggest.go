@@ -0,0 +1,219 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+// stackDetectorPlugin is the plugin that analyzes project directories
+const stackDetectorPlugin = "tool-stackdetector"
+
+// Suggestion priorities, derived from the detector's confidence
+const (
+	SuggestionCritical    = "critical"
+	SuggestionRecommended = "recommended"
+	SuggestionOptional    = "optional"
+)
+
+// Suggestion categories used by the --category filter
+const (
+	SuggestionApplication = "application"
+	SuggestionEnvironment = "environment"
+)
+
+// StackReport is the JSON report produced by the stack-detector plugin
+type StackReport struct {
+	GeneratedAt     time.Time            `json:"generated_at" yaml:"generated_at"`
+	ProjectPath     string               `json:"project_path" yaml:"project_path"`
+	Technologies    []DetectedTechnology `json:"technologies" yaml:"technologies"`
+	Recommendations []string             `json:"recommendations" yaml:"recommendations"`
+	Issues          []string             `json:"issues" yaml:"issues"`
+	Summary         StackSummary         `json:"summary" yaml:"summary"`
+}
+
+// DetectedTechnology is a single technology found in the project
+type DetectedTechnology struct {
+	Name        string   `json:"name" yaml:"name"`
+	Category    string   `json:"category" yaml:"category"`
+	Confidence  int      `json:"confidence" yaml:"confidence"` // 1-10 scale
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Files       []string `json:"files,omitempty" yaml:"files,omitempty"`
+}
+
+// StackSummary provides high-level project insights
+type StackSummary struct {
+	PrimaryLanguage   string `json:"primary_language" yaml:"primary_language"`
+	ProjectType       string `json:"project_type" yaml:"project_type"`
+	ComplexityLevel   string `json:"complexity_level" yaml:"complexity_level"`
+	MaturityLevel     string `json:"maturity_level" yaml:"maturity_level"`
+	RecommendedAction string `json:"recommended_action" yaml:"recommended_action"`
+}
+
+// StackSuggestion is a catalog app suggested for the detected technologies
+type StackSuggestion struct {
+	App          string   // catalog name when configured, otherwise the mapped name
+	Technologies []string // technologies that led to the suggestion
+	Confidence   int      // highest confidence among Technologies
+	Priority     string
+	Category     string
+	Configured   bool // the app is defined in this configuration and can be installed
+}
+
+// technologyApps maps technologies reported by the stack detector to catalog apps
+var technologyApps = map[string][]string{
+	"Go":                   {"go"},
+	"Node.js":              {"node.js"},
+	"Node.js (npm)":        {"node.js"},
+	"Node.js (Yarn)":       {"node.js"},
+	"TypeScript":           {"node.js"},
+	"Python":               {"python"},
+	"Python (Pipenv)":      {"python"},
+	"Python (Poetry)":      {"python"},
+	"Rust":                 {"rust"},
+	"Ruby":                 {"ruby"},
+	"Elixir":               {"elixir"},
+	"Java (Maven)":         {"java"},
+	"Java/Kotlin (Gradle)": {"java"},
+	"Docker":               {"docker"},
+	"Docker Compose":       {"docker"},
+	"Make":                 {"build-essential"},
+	"CMake":                {"build-essential"},
+	"Git":                  {"git"},
+}
+
+// ParseStackReport decodes the JSON report written by the stack-detector plugin.
+// Any text printed before the report by older plugin versions is ignored.
+func ParseStackReport(output string) (*StackReport, error) {
+	start := strings.Index(output, "{")
+	if start < 0 {
+		return nil, fmt.Errorf("stack detector returned no report")
+	}
+
+	var report StackReport
+	if err := json.Unmarshal([]byte(output[start:]), &report); err != nil {
+		return nil, fmt.Errorf("failed to parse stack report: %w", err)
+	}
+	return &report, nil
+}
+
+// RunStackDetector analyzes dir with the stack-detector plugin, downloading it on first use
+func RunStackDetector(ctx context.Context, dir string) (*StackReport, error) {
+	pb := GetPluginBootstrap()
+	if pb == nil {
+		return nil, fmt.Errorf("plugin system is not initialized")
+	}
+	if err := pb.EnsurePlugin(ctx, stackDetectorPlugin); err != nil {
+		return nil, err
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid directory path: %w", err)
+	}
+
+	req := sdk.NewRPCRequest("report", sdk.RPCParams{
+		Query:   absDir,
+		Options: map[string]string{"format": "json"},
+	})
+	result, err := pb.CallPlugin(ctx, stackDetectorPlugin, req, nil)
+	if errors.Is(err, sdk.ErrProtocolUnsupported) {
+		return nil, fmt.Errorf("the installed %s plugin is too old, run 'devex plugin update %s'", stackDetectorPlugin, stackDetectorPlugin)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("stack detection failed: %w", err)
+	}
+
+	return ParseStackReport(result.Output)
+}
+
+// SuggestApps maps the detected technologies to catalog apps. Technologies below
+// minConfidence (1-10) and technologies without a catalog mapping are ignored.
+func SuggestApps(report *StackReport, resolver *InstallResolver, minConfidence int) []StackSuggestion {
+	byApp := make(map[string]*StackSuggestion)
+	var order []string
+
+	for _, tech := range report.Technologies {
+		if tech.Confidence < minConfidence {
+			continue
+		}
+		for _, name := range technologyApps[tech.Name] {
+			suggestion, exists := byApp[name]
+			if !exists {
+				suggestion = &StackSuggestion{App: name, Category: SuggestionApplication}
+				if app, ok := resolver.Lookup(name); ok {
+					suggestion.App = app.Name
+					suggestion.Configured = true
+					if app.Category == "Programming Languages" {
+						suggestion.Category = SuggestionEnvironment
+					}
+				}
+				byApp[name] = suggestion
+				order = append(order, name)
+			}
+			suggestion.Technologies = append(suggestion.Technologies, tech.Name)
+			if tech.Confidence > suggestion.Confidence {
+				suggestion.Confidence = tech.Confidence
+			}
+		}
+	}
+
+	suggestions := make([]StackSuggestion, 0, len(order))
+	for _, name := range order {
+		suggestion := byApp[name]
+		suggestion.Priority = suggestionPriority(suggestion.Confidence)
+		suggestions = append(suggestions, *suggestion)
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Confidence > suggestions[j].Confidence
+	})
+	return suggestions
+}
+
+// suggestionPriority derives a priority from a 1-10 confidence
+func suggestionPriority(confidence int) string {
+	switch {
+	case confidence >= 9:
+		return SuggestionCritical
+	case confidence >= 7:
+		return SuggestionRecommended
+	default:
+		return SuggestionOptional
+	}
+}
+
+// FilterSuggestions keeps the suggestions matching any of the comma-separated priorities
+// and categories. An empty filter matches everything.
+func FilterSuggestions(suggestions []StackSuggestion, priorities, categories string) []StackSuggestion {
+	wantPriority := splitFilter(priorities)
+	wantCategory := splitFilter(categories)
+
+	var filtered []StackSuggestion
+	for _, suggestion := range suggestions {
+		if len(wantPriority) > 0 && !wantPriority[suggestion.Priority] {
+			continue
+		}
+		if len(wantCategory) > 0 && !wantCategory[suggestion.Category] {
+			continue
+		}
+		filtered = append(filtered, suggestion)
+	}
+	return filtered
+}
+
+func splitFilter(value string) map[string]bool {
+	set := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.ToLower(strings.TrimSpace(part)); part != "" {
+			set[part] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,89 @@
+package commands_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/commands"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+var _ = Describe("Stack detection suggestions", func() {
+	var resolver *commands.InstallResolver
+
+	BeforeEach(func() {
+		resolver = commands.NewInstallResolverFromApps([]types.CrossPlatformApp{
+			resolverTestApp("Go", "Programming Languages", "mise"),
+			resolverTestApp("Node.js", "Programming Languages", "mise"),
+			resolverTestApp("mise", "Development"),
+			resolverTestApp("build-essential", "Development"),
+		})
+	})
+
+	It("parses the plugin report and ignores leading output", func() {
+		report, err := commands.ParseStackReport(`📄 Generating stack report for: /src/app
+{"project_path": "/src/app", "technologies": [{"name": "Go", "category": "Language", "confidence": 10, "files": ["go.mod"]}],
+ "summary": {"primary_language": "Go"}}`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.ProjectPath).To(Equal("/src/app"))
+		Expect(report.Technologies).To(HaveLen(1))
+		Expect(report.Technologies[0].Files).To(ConsistOf("go.mod"))
+		Expect(report.Summary.PrimaryLanguage).To(Equal("Go"))
+
+		_, err = commands.ParseStackReport("❌ No recognizable technology stack detected")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("maps technologies to catalog apps", func() {
+		report := &commands.StackReport{Technologies: []commands.DetectedTechnology{
+			{Name: "Go", Confidence: 10},
+			{Name: "TypeScript", Confidence: 9},
+			{Name: "Node.js (npm)", Confidence: 7},
+			{Name: "Docker Compose", Confidence: 8},
+			{Name: "Make", Confidence: 6},
+			{Name: "ESLint", Confidence: 8},
+		}}
+
+		suggestions := commands.SuggestApps(report, resolver, 0)
+		Expect(suggestions).To(HaveLen(4))
+
+		Expect(suggestions[0].App).To(Equal("Go"))
+		Expect(suggestions[0].Configured).To(BeTrue())
+		Expect(suggestions[0].Priority).To(Equal(commands.SuggestionCritical))
+		Expect(suggestions[0].Category).To(Equal(commands.SuggestionEnvironment))
+
+		Expect(suggestions[1].App).To(Equal("Node.js"))
+		Expect(suggestions[1].Technologies).To(Equal([]string{"TypeScript", "Node.js (npm)"}))
+
+		Expect(suggestions[2].App).To(Equal("docker"))
+		Expect(suggestions[2].Configured).To(BeFalse())
+		Expect(suggestions[2].Priority).To(Equal(commands.SuggestionRecommended))
+
+		Expect(suggestions[3].App).To(Equal("build-essential"))
+		Expect(suggestions[3].Priority).To(Equal(commands.SuggestionOptional))
+		Expect(suggestions[3].Category).To(Equal(commands.SuggestionApplication))
+	})
+
+	It("drops technologies below the confidence threshold", func() {
+		report := &commands.StackReport{Technologies: []commands.DetectedTechnology{
+			{Name: "Go", Confidence: 10},
+			{Name: "Make", Confidence: 6},
+		}}
+		suggestions := commands.SuggestApps(report, resolver, 7)
+		Expect(suggestions).To(HaveLen(1))
+		Expect(suggestions[0].App).To(Equal("Go"))
+	})
+
+	It("filters by priority and category", func() {
+		suggestions := []commands.StackSuggestion{
+			{App: "Go", Priority: commands.SuggestionCritical, Category: commands.SuggestionEnvironment},
+			{App: "docker", Priority: commands.SuggestionRecommended, Category: commands.SuggestionApplication},
+			{App: "build-essential", Priority: commands.SuggestionOptional, Category: commands.SuggestionApplication},
+		}
+
+		Expect(commands.FilterSuggestions(suggestions, "critical, recommended", "")).To(HaveLen(2))
+		Expect(commands.FilterSuggestions(suggestions, "", "application")).To(HaveLen(2))
+		Expect(commands.FilterSuggestions(suggestions, "optional", "environment")).To(BeEmpty())
+		Expect(commands.FilterSuggestions(suggestions, "", "")).To(HaveLen(3))
+	})
+})
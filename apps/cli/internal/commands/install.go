@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -56,13 +57,25 @@ Configuration files are located in ~/.local/share/devex/config/:
   devex install docker git vscode
 
   # Install with categories
-  devex install --categories development,databases
+  devex install --categories "Development Tools,Databases"
 
   # Dry run to preview changes
   devex install --dry-run
 
   # Install with verbose output
-  devex install --verbose`,
+  devex install --verbose
+
+  # Continue an installation that was interrupted or crashed
+  devex install --resume
+
+  # Reproduce the versions recorded in ~/.devex/devex.lock
+  devex install --locked
+
+  # Reproduce a lockfile shared by a teammate
+  devex install --locked --lockfile ./devex.lock
+
+  # Install on a machine without network access from 'devex bundle create'
+  devex install --from-bundle devex-bundle-ubuntu-24.04-amd64.tar.gz`,
 		Args: cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
@@ -72,6 +85,32 @@ Configuration files are located in ~/.local/share/devex/config/:
 			dryRun := viper.GetBool("dry-run")
 			categories := viper.GetStringSlice("categories")
 
+			resume, _ := cmd.Flags().GetBool("resume")
+			locked, _ := cmd.Flags().GetBool("locked")
+			lockPath, _ := cmd.Flags().GetString("lockfile")
+			fromBundle, _ := cmd.Flags().GetString("from-bundle")
+			if fromBundle != "" {
+				if resume || locked || len(categories) > 0 {
+					return fmt.Errorf("invalid inputs: --from-bundle cannot be combined with --resume, --locked or categories")
+				}
+				return executeBundleInstall(ctx, fromBundle, args, verbose, dryRun, repo, settings)
+			}
+			if resume && locked {
+				return fmt.Errorf("invalid inputs: --resume cannot be combined with --locked")
+			}
+			if resume {
+				return executeResume(ctx, args, categories, verbose, repo, settings)
+			}
+			if locked {
+				if len(categories) > 0 {
+					return fmt.Errorf("invalid inputs: --locked cannot be combined with categories")
+				}
+				return executeLockedInstall(ctx, args, lockPath, verbose, dryRun, repo, settings)
+			}
+			if lockPath != "" {
+				return fmt.Errorf("invalid inputs: --lockfile requires --locked")
+			}
+
 			return executeInstall(ctx, args, categories, verbose, dryRun, repo, settings)
 		},
 		SilenceUsage: true, // Prevent usage spam on runtime errors
@@ -79,6 +118,10 @@ Configuration files are located in ~/.local/share/devex/config/:
 
 	// Define command-specific flags
 	cmd.Flags().StringSlice("categories", nil, "Install apps from specific categories")
+	cmd.Flags().Bool("resume", false, "Resume the most recent interrupted installation session")
+	cmd.Flags().Bool("locked", false, "Install the exact versions recorded in the lockfile")
+	cmd.Flags().String("lockfile", "", "Lockfile to install from with --locked (default ~/.devex/devex.lock)")
+	cmd.Flags().String("from-bundle", "", "Install from an offline bundle created with 'devex bundle create', without network access")
 
 	// Bind flags to Viper for hierarchical config
 	_ = viper.BindPFlag("categories", cmd.Flags().Lookup("categories"))
@@ -91,7 +134,7 @@ Configuration files are located in ~/.local/share/devex/config/:
 
 // executeInstall implements the core installation logic with proper context handling
 func executeInstall(ctx context.Context, apps []string, categories []string, verbose, dryRun bool, repo types.Repository, settings config.CrossPlatformSettings) error {
-	_, span := tracer.Start(ctx, "install_command",
+	ctx, span := tracer.Start(ctx, "install_command",
 		trace.WithAttributes(
 			attribute.StringSlice("apps", apps),
 			attribute.StringSlice("categories", categories),
@@ -102,8 +145,6 @@ func executeInstall(ctx context.Context, apps []string, categories []string, ver
 	)
 	defer span.End()
 
-	// Note: Context will be properly propagated when TUI and other functions support it
-
 	// Update settings with runtime configuration
 	settings.Verbose = verbose
 
@@ -123,24 +164,34 @@ func executeInstall(ctx context.Context, apps []string, categories []string, ver
 
 	// Determine what to install
 	var appsToInstall []types.CrossPlatformApp
+	resolver := NewInstallResolver(settings)
 	switch {
 	case len(apps) > 0:
-		// Install specific apps requested - for now just use default apps as placeholder
-		// TODO: Implement app lookup by name
-		log.Info("Specific app installation requested", "apps", apps)
-		log.Info("Currently installing default apps - specific app lookup will be implemented")
-		appsToInstall = settings.GetDefaultApps()
+		log.Info("Resolving requested applications", "apps", apps)
+		resolved, err := resolver.ResolveNames(apps)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "App resolution failed")
+			return err
+		}
+		appsToInstall = resolved
 	case len(categories) > 0:
-		// Install apps from specific categories - for now just use default apps as placeholder
-		// TODO: Implement category filtering
-		log.Info("Category-based installation requested", "categories", categories)
-		log.Info("Currently installing default apps - category filtering will be implemented")
-		appsToInstall = settings.GetDefaultApps()
+		log.Info("Resolving applications by category", "categories", categories)
+		resolved, err := resolver.ResolveCategories(categories)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Category resolution failed")
+			return err
+		}
+		appsToInstall = resolved
 	default:
 		// Install default apps
 		appsToInstall = settings.GetDefaultApps()
 	}
 
+	span.SetAttributes(attribute.Int("resolved_app_count", len(appsToInstall)))
+	log.Debug("Resolved applications to install", "count", len(appsToInstall))
+
 	// Handle dry run
 	if dryRun {
 		return previewInstallation(appsToInstall)
@@ -151,6 +202,10 @@ func executeInstall(ctx context.Context, apps []string, categories []string, ver
 		attribute.Int("app_count", len(appsToInstall)),
 	))
 
+	// Record the installation so 'devex undo' can revert the system changes it makes
+	ctx, finishUndo := beginUndoableInstall(ctx, appsToInstall)
+	defer finishUndo()
+
 	// Pass context to tui.StartInstallation for proper cancellation support
 	if err := tui.StartInstallation(ctx, appsToInstall, repo, settings); err != nil {
 		span.RecordError(err)
@@ -172,6 +227,73 @@ func executeInstall(ctx context.Context, apps []string, categories []string, ver
 	return nil
 }
 
+// executeResume continues the most recent installation session that did not complete
+func executeResume(ctx context.Context, apps []string, categories []string, verbose bool, repo types.Repository, settings config.CrossPlatformSettings) error {
+	ctx, span := tracer.Start(ctx, "install_resume")
+	defer span.End()
+
+	if len(apps) > 0 || len(categories) > 0 {
+		return fmt.Errorf("invalid inputs: --resume cannot be combined with apps or categories")
+	}
+
+	sessionRepo, ok := repo.(types.SessionRepository)
+	if !ok {
+		return fmt.Errorf("installation sessions are not supported by this repository")
+	}
+
+	session, err := sessionRepo.LatestIncompleteSession()
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to load installation session: %w", err)
+	}
+	if session == nil {
+		return fmt.Errorf("no interrupted installation session to resume")
+	}
+	span.SetAttributes(attribute.String("session", session.ID))
+
+	appsToResume := resolveSessionApps(session, NewInstallResolver(settings))
+	log.Info("Resuming installation session", "session", session.ID, "status", session.Status, "apps", len(appsToResume))
+
+	settings.Verbose = verbose
+	ctx, finishUndo := beginUndoableInstall(ctx, appsToResume)
+	defer finishUndo()
+	if err := tui.ResumeInstallation(ctx, session, appsToResume, repo, settings); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Resumed installation failed")
+		return fmt.Errorf("installation failed: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Resumed installation completed successfully")
+	return nil
+}
+
+// beginUndoableInstall records the installation of apps as an undoable operation
+func beginUndoableInstall(ctx context.Context, apps []types.CrossPlatformApp) (context.Context, func()) {
+	names := make([]string, len(apps))
+	for i, app := range apps {
+		names[i] = app.Name
+	}
+	return beginUndoableOperation(ctx, "install",
+		fmt.Sprintf("Installed applications: %s", strings.Join(names, ", ")),
+		strings.Join(names, ","),
+		map[string]interface{}{"apps": names})
+}
+
+// resolveSessionApps maps the apps planned by a session back to their configurations, in session order.
+// Apps that are no longer configured cannot be installed and are left out with a warning.
+func resolveSessionApps(session *types.InstallSession, resolver *InstallResolver) []types.CrossPlatformApp {
+	apps := make([]types.CrossPlatformApp, 0, len(session.Apps))
+	for _, sessionApp := range session.Apps {
+		app, ok := resolver.Lookup(sessionApp.AppName)
+		if !ok {
+			log.Warn("App from installation session is no longer configured, skipping", "app", sessionApp.AppName)
+			continue
+		}
+		apps = append(apps, app)
+	}
+	return apps
+}
+
 // validateInstallInputs validates the installation inputs
 func validateInstallInputs(apps []string, categories []string) error {
 	// Apps and categories are mutually exclusive for clarity
@@ -0,0 +1,164 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/jameswlane/devex/apps/cli/internal/bootstrap"
+	"github.com/jameswlane/devex/apps/cli/internal/bundle"
+	"github.com/jameswlane/devex/apps/cli/internal/config"
+	"github.com/jameswlane/devex/apps/cli/internal/log"
+	"github.com/jameswlane/devex/apps/cli/internal/tui"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+// BundleApps returns the apps to install from a bundle, configured with the OS configuration
+// they were bundled with. When names is empty every bundled app is installed. Apps missing from
+// the local configuration are installed from the bundled configuration alone.
+func BundleApps(contents *bundle.Bundle, names []string, resolver *InstallResolver) ([]types.CrossPlatformApp, error) {
+	bundled := contents.Apps
+	if len(names) > 0 {
+		bundled = make([]bundle.App, 0, len(names))
+		var missing []string
+		for _, name := range names {
+			app, ok := contents.App(name)
+			if !ok {
+				if local, found := resolver.Lookup(name); found {
+					app, ok = contents.App(local.Name)
+				}
+			}
+			if !ok {
+				missing = append(missing, name)
+				continue
+			}
+			bundled = append(bundled, app)
+		}
+		if len(missing) > 0 {
+			return nil, fmt.Errorf("not included in the bundle: %s", strings.Join(missing, ", "))
+		}
+	}
+
+	apps := make([]types.CrossPlatformApp, 0, len(bundled))
+	for _, entry := range bundled {
+		app, ok := resolver.Lookup(entry.Name)
+		if !ok {
+			app = types.CrossPlatformApp{Name: entry.Name}
+		}
+		apps = append(apps, app.WithOSConfig(entry.Config))
+	}
+	return apps, nil
+}
+
+// executeBundleInstall verifies a bundle and installs its apps without network access
+func executeBundleInstall(ctx context.Context, archive string, names []string, verbose, dryRun bool, repo types.Repository, settings config.CrossPlatformSettings) error {
+	ctx, span := tracer.Start(ctx, "install_bundle")
+	defer span.End()
+	span.SetAttributes(attribute.String("bundle", archive))
+
+	dir, err := os.MkdirTemp("", "devex-bundle-*")
+	if err != nil {
+		return fmt.Errorf("failed to create extraction directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	fmt.Printf("📦 Verifying bundle %s...\n", archive)
+	contents, err := bundle.Extract(archive, dir)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	current := bundle.PlatformOf(types.CurrentPlatform())
+	if err := contents.Platform.Check(current); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if contents.Platform.Version != "" && contents.Platform.Version != current.Version {
+		fmt.Printf("⚠️  Bundle was created for %s, this machine runs %s\n", contents.Platform, current)
+	}
+
+	apps, err := BundleApps(contents, names, NewInstallResolver(settings))
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	fmt.Printf("✅ Verified %d application(s) and %d plugin(s) created %s\n",
+		len(contents.Apps), len(contents.Plugins), contents.CreatedAt.Local().Format("2006-01-02 15:04"))
+
+	if dryRun {
+		return previewInstallation(apps)
+	}
+
+	if err := installBundledPlugins(ctx, contents); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	log.Info("Installing from bundle", "bundle", archive, "apps", len(apps))
+	settings.Verbose = verbose
+	ctx, finishUndo := beginUndoableInstall(ctx, apps)
+	defer finishUndo()
+	if err := tui.StartBundleInstallation(ctx, apps, contents, repo, settings); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Bundle installation failed")
+		return fmt.Errorf("installation failed: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Bundle installation completed successfully")
+	return nil
+}
+
+// installBundledPlugins installs the verified plugin binaries of a bundle, replacing installed
+// versions so the plugins match the payloads they downloaded. Plugins must be signed by a
+// trusted key, like plugins installed from the registry.
+func installBundledPlugins(ctx context.Context, contents *bundle.Bundle) error {
+	if len(contents.Plugins) == 0 {
+		return nil
+	}
+	if pluginBootstrap == nil {
+		return fmt.Errorf("plugin system is not available")
+	}
+
+	keyring, err := bootstrap.TrustRoots()
+	if err != nil {
+		return err
+	}
+	allowed := bootstrap.AllowedUnverifiedPlugins()
+	for _, plugin := range contents.Plugins {
+		name := path.Base(plugin.Path)
+		if _, err := sdk.RequireTrustedSignature(keyring, name, contents.Path(plugin), []byte(plugin.Signature), slices.Contains(allowed, name)); err != nil {
+			return fmt.Errorf("refusing bundled plugin: %w", err)
+		}
+	}
+
+	manager := pluginBootstrap.GetManager()
+	for _, plugin := range contents.Plugins {
+		name := path.Base(plugin.Path)
+		if err := manager.InstallPlugin(contents.Path(plugin), name); err != nil {
+			return fmt.Errorf("failed to install bundled plugin %s: %w", name, err)
+		}
+		if err := sdk.SavePluginSignature(manager.GetPluginDir(), name, []byte(plugin.Signature)); err != nil {
+			log.Warn("Failed to save plugin signature", "plugin", name, "error", err)
+		}
+		log.Debug("Installed bundled plugin", "plugin", name)
+	}
+	if err := manager.DiscoverPluginsWithContext(ctx); err != nil {
+		return err
+	}
+
+	// Bundled plugins only run once the permissions they request are granted
+	for _, plugin := range contents.Plugins {
+		if err := pluginBootstrap.EnsurePluginPermissions(path.Base(plugin.Path)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
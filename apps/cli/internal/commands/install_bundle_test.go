@@ -0,0 +1,53 @@
+package commands_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/bundle"
+	"github.com/jameswlane/devex/apps/cli/internal/commands"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+var _ = Describe("BundleApps", func() {
+	var (
+		contents *bundle.Bundle
+		resolver *commands.InstallResolver
+	)
+
+	BeforeEach(func() {
+		contents = &bundle.Bundle{Manifest: bundle.Manifest{Apps: []bundle.App{
+			{Name: "git", Config: types.OSConfig{InstallMethod: "apt", InstallCommand: "git"}},
+			{Name: "mise-node", Config: types.OSConfig{InstallMethod: "mise", InstallCommand: "node@20"}},
+		}}}
+		resolver = commands.NewInstallResolverFromApps([]types.CrossPlatformApp{{
+			Name:        "Git",
+			Description: "Version control",
+			Linux: types.OSConfig{
+				InstallMethod:  "dnf",
+				InstallCommand: "git-core",
+			},
+		}})
+	})
+
+	It("should install every bundled app with its bundled configuration", func() {
+		apps, err := commands.BundleApps(contents, nil, resolver)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(apps).To(HaveLen(2))
+
+		Expect(apps[0].Description).To(Equal("Version control"))
+		Expect(apps[0].GetOSConfig().InstallMethod).To(Equal("apt"))
+		Expect(apps[1].Name).To(Equal("mise-node"))
+		Expect(apps[1].GetOSConfig().InstallCommand).To(Equal("node@20"))
+	})
+
+	It("should install only the named apps and reject apps missing from the bundle", func() {
+		apps, err := commands.BundleApps(contents, []string{"GIT"}, resolver)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(apps).To(HaveLen(1))
+		Expect(apps[0].Name).To(Equal("Git"))
+
+		_, err = commands.BundleApps(contents, []string{"docker"}, resolver)
+		Expect(err).To(MatchError("not included in the bundle: docker"))
+	})
+})
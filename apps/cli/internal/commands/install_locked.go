@@ -0,0 +1,197 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/jameswlane/devex/apps/cli/internal/config"
+	"github.com/jameswlane/devex/apps/cli/internal/installers"
+	"github.com/jameswlane/devex/apps/cli/internal/lockfile"
+	"github.com/jameswlane/devex/apps/cli/internal/log"
+	"github.com/jameswlane/devex/apps/cli/internal/tui"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+// LockIssueKind classifies why a locked version cannot be reproduced
+type LockIssueKind string
+
+const (
+	// LockIssueNotConfigured means the locked app is not in the configuration on this machine
+	LockIssueNotConfigured LockIssueKind = "not configured"
+	// LockIssueNotLocked means the app to install has no lockfile entry
+	LockIssueNotLocked LockIssueKind = "not locked"
+	// LockIssueMethodMismatch means the app installs with a different method here than when it was locked
+	LockIssueMethodMismatch LockIssueKind = "different install method"
+	// LockIssueUnsupported means the install method cannot pin versions
+	LockIssueUnsupported LockIssueKind = "version pinning unsupported"
+	// LockIssueUnavailable means the locked version cannot be found in the configured repositories
+	LockIssueUnavailable LockIssueKind = "version unavailable"
+)
+
+// LockIssue is a single app that cannot be installed at its locked version
+type LockIssue struct {
+	App    string
+	Kind   LockIssueKind
+	Detail string
+}
+
+// LockReport is the result of checking whether a lockfile can be reproduced on this machine
+type LockReport struct {
+	Apps   []types.CrossPlatformApp
+	Issues []LockIssue
+}
+
+// Blocking returns the issues that prevent a locked install. Every other issue only means the
+// app is installed at its configured constraint instead of the locked version.
+func (r *LockReport) Blocking() []LockIssue {
+	var blocking []LockIssue
+	for _, issue := range r.Issues {
+		if issue.Kind == LockIssueUnavailable {
+			blocking = append(blocking, issue)
+		}
+	}
+	return blocking
+}
+
+// VersionLookup returns the version-pinning installer for an install method
+type VersionLookup func(method string) (types.VersionedInstaller, error)
+
+// CheckLockfile determines which apps can be installed at their locked versions. When names is
+// empty every app in the lockfile is installed; otherwise only the named apps are.
+func CheckLockfile(lock *lockfile.Lockfile, names []string, resolver *InstallResolver, lookup VersionLookup) (*LockReport, error) {
+	report := &LockReport{}
+
+	if len(names) == 0 {
+		for _, entry := range lock.Apps {
+			app, ok := resolver.Lookup(entry.Name)
+			if !ok {
+				report.Issues = append(report.Issues, LockIssue{App: entry.Name, Kind: LockIssueNotConfigured,
+					Detail: "the app is locked but not defined in this configuration"})
+				continue
+			}
+			report.Apps = append(report.Apps, app)
+		}
+	} else {
+		apps, err := resolver.ResolveNames(names)
+		if err != nil {
+			return nil, err
+		}
+		report.Apps = apps
+	}
+
+	for _, app := range report.Apps {
+		method := app.GetOSConfig().InstallMethod
+		entry, ok := lock.Find(app.Name)
+		if !ok {
+			report.Issues = append(report.Issues, LockIssue{App: app.Name, Kind: LockIssueNotLocked,
+				Detail: "installing the configured version"})
+			continue
+		}
+		if entry.InstallMethod != method {
+			report.Issues = append(report.Issues, LockIssue{App: app.Name, Kind: LockIssueMethodMismatch,
+				Detail: fmt.Sprintf("locked with %s, installs with %s here", entry.InstallMethod, method)})
+			continue
+		}
+
+		installer, err := lookup(method)
+		if err != nil {
+			report.Issues = append(report.Issues, LockIssue{App: app.Name, Kind: LockIssueUnsupported, Detail: err.Error()})
+			continue
+		}
+		for _, pkg := range entry.Packages {
+			if _, err := installer.ResolveVersions(pkg.Name, "="+pkg.Version); err != nil {
+				report.Issues = append(report.Issues, LockIssue{App: app.Name, Kind: LockIssueUnavailable,
+					Detail: fmt.Sprintf("%s %s: %v", pkg.Name, pkg.Version, err)})
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// printLockReport lists the apps that will not be installed at their locked versions
+func printLockReport(report *LockReport, path string) {
+	yellow := color.New(color.FgYellow).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	if len(report.Issues) == 0 {
+		fmt.Printf("🔒 Installing %d application(s) at the versions locked in %s\n", len(report.Apps), path)
+		return
+	}
+
+	fmt.Printf("🔒 %s cannot be fully reproduced on this machine:\n\n", path)
+	for _, issue := range report.Issues {
+		symbol := yellow("!")
+		if issue.Kind == LockIssueUnavailable {
+			symbol = red("✗")
+		}
+		fmt.Printf("  %s %s: %s (%s)\n", symbol, issue.App, issue.Kind, issue.Detail)
+	}
+	fmt.Println()
+}
+
+// executeLockedInstall installs the versions recorded in a lockfile, reporting what cannot be reproduced
+func executeLockedInstall(ctx context.Context, apps []string, path string, verbose, dryRun bool, repo types.Repository, settings config.CrossPlatformSettings) error {
+	ctx, span := tracer.Start(ctx, "install_locked")
+	defer span.End()
+
+	if path == "" {
+		path = lockfile.DefaultPath(settings.HomeDir)
+	}
+	span.SetAttributes(attribute.String("lockfile", path))
+
+	lock, err := lockfile.Load(path)
+	if err != nil {
+		span.RecordError(err)
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no lockfile at %s, run 'devex install' first to create one", path)
+		}
+		return err
+	}
+
+	lookup := func(method string) (types.VersionedInstaller, error) {
+		return installers.GetVersionedInstaller(ctx, method)
+	}
+	report, err := CheckLockfile(lock, apps, NewInstallResolver(settings), lookup)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	printLockReport(report, path)
+
+	if blocking := report.Blocking(); len(blocking) > 0 {
+		names := make([]string, 0, len(blocking))
+		for _, issue := range blocking {
+			names = append(names, issue.App)
+		}
+		span.SetStatus(codes.Error, "Locked versions unavailable")
+		return fmt.Errorf("locked versions are unavailable for: %s", strings.Join(names, ", "))
+	}
+	if len(report.Apps) == 0 {
+		return fmt.Errorf("no locked applications can be installed from %s", path)
+	}
+
+	if dryRun {
+		return previewInstallation(report.Apps)
+	}
+
+	log.Info("Installing locked versions", "lockfile", path, "apps", len(report.Apps))
+	settings.Verbose = verbose
+	ctx, finishUndo := beginUndoableInstall(ctx, report.Apps)
+	defer finishUndo()
+	if err := tui.StartLockedInstallation(ctx, report.Apps, lock, repo, settings); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Locked installation failed")
+		return fmt.Errorf("installation failed: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Locked installation completed successfully")
+	return nil
+}
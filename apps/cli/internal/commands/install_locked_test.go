@@ -0,0 +1,99 @@
+package commands_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/commands"
+	"github.com/jameswlane/devex/apps/cli/internal/installers"
+	"github.com/jameswlane/devex/apps/cli/internal/lockfile"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+// lockedVersions is a VersionedInstaller that only knows a fixed set of exact versions
+type lockedVersions map[string]string
+
+func (v lockedVersions) ResolveVersions(command, constraint string) ([]types.PackageVersion, error) {
+	if "="+v[command] != constraint {
+		return nil, fmt.Errorf("no version of %s matches %s", command, constraint)
+	}
+	return []types.PackageVersion{{Name: command, Version: v[command]}}, nil
+}
+
+func (v lockedVersions) InstalledVersions(string) ([]types.PackageVersion, error) {
+	return nil, nil
+}
+
+var _ = Describe("CheckLockfile", func() {
+	var (
+		resolver *commands.InstallResolver
+		lock     *lockfile.Lockfile
+		lookup   commands.VersionLookup
+	)
+
+	BeforeEach(func() {
+		tool := resolverTestApp("tool", "Utilities")
+		tool.Linux.InstallMethod = "curlpipe"
+		tool.MacOS.InstallMethod = "curlpipe"
+		tool.Windows.InstallMethod = "curlpipe"
+		resolver = commands.NewInstallResolverFromApps([]types.CrossPlatformApp{
+			resolverTestApp("git", "Development Tools"),
+			resolverTestApp("curl", "Utilities"),
+			resolverTestApp("jq", "Utilities"),
+			tool,
+		})
+
+		lock = lockfile.New()
+		lock.Upsert(lockfile.App{Name: "git", InstallMethod: "apt", Packages: []lockfile.Package{{Name: "git", Version: "2.43.0"}}})
+		lock.Upsert(lockfile.App{Name: "curl", InstallMethod: "apt", Packages: []lockfile.Package{{Name: "curl", Version: "8.5.0"}}})
+
+		lookup = func(method string) (types.VersionedInstaller, error) {
+			if method != "apt" {
+				return nil, installers.ErrVersionPinningUnsupported
+			}
+			return lockedVersions{"git": "2.43.0", "curl": "8.5.0"}, nil
+		}
+	})
+
+	It("installs every locked app when no names are given", func() {
+		lock.Upsert(lockfile.App{Name: "removed", InstallMethod: "apt"})
+
+		report, err := commands.CheckLockfile(lock, nil, resolver, lookup)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resolvedNames(report.Apps)).To(ConsistOf("git", "curl"))
+		Expect(report.Issues).To(ConsistOf(commands.LockIssue{
+			App: "removed", Kind: commands.LockIssueNotConfigured, Detail: "the app is locked but not defined in this configuration",
+		}))
+		Expect(report.Blocking()).To(BeEmpty())
+	})
+
+	It("reports apps that cannot be installed at a locked version", func() {
+		lock.Upsert(lockfile.App{Name: "tool", InstallMethod: "curlpipe"})
+		lock.Upsert(lockfile.App{Name: "git", InstallMethod: "dnf"})
+
+		report, err := commands.CheckLockfile(lock, []string{"git", "jq", "tool"}, resolver, lookup)
+		Expect(err).ToNot(HaveOccurred())
+
+		kinds := map[string]commands.LockIssueKind{}
+		for _, issue := range report.Issues {
+			kinds[issue.App] = issue.Kind
+		}
+		Expect(kinds).To(Equal(map[string]commands.LockIssueKind{
+			"git":  commands.LockIssueMethodMismatch,
+			"jq":   commands.LockIssueNotLocked,
+			"tool": commands.LockIssueUnsupported,
+		}))
+		Expect(report.Blocking()).To(BeEmpty())
+	})
+
+	It("blocks when a locked version is no longer available", func() {
+		lock.Upsert(lockfile.App{Name: "curl", InstallMethod: "apt", Packages: []lockfile.Package{{Name: "curl", Version: "7.88.1"}}})
+
+		report, err := commands.CheckLockfile(lock, []string{"curl"}, resolver, lookup)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Blocking()).To(HaveLen(1))
+		Expect(report.Blocking()[0].Detail).To(ContainSubstring("curl 7.88.1"))
+	})
+})
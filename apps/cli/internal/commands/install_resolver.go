@@ -0,0 +1,279 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jameswlane/devex/apps/cli/internal/config"
+	"github.com/jameswlane/devex/apps/cli/internal/log"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+// maxAppSuggestions limits how many catalog names are suggested for an unknown app
+const maxAppSuggestions = 3
+
+// UnknownAppsError reports requested application names that are not in the catalog
+type UnknownAppsError struct {
+	Names       []string
+	Suggestions map[string][]string
+}
+
+func (e *UnknownAppsError) Error() string {
+	parts := make([]string, 0, len(e.Names))
+	for _, name := range e.Names {
+		if suggestions := e.Suggestions[name]; len(suggestions) > 0 {
+			parts = append(parts, fmt.Sprintf("'%s' (did you mean: %s?)", name, strings.Join(suggestions, ", ")))
+		} else {
+			parts = append(parts, fmt.Sprintf("'%s'", name))
+		}
+	}
+	return fmt.Sprintf("unknown application(s): %s", strings.Join(parts, "; "))
+}
+
+// InstallResolver resolves app names and categories against the application catalog
+// and expands them with their transitive dependencies in installation order
+type InstallResolver struct {
+	apps   []types.CrossPlatformApp
+	byName map[string]int
+}
+
+// NewInstallResolver creates a resolver over all applications known to the settings
+func NewInstallResolver(settings config.CrossPlatformSettings) *InstallResolver {
+	return NewInstallResolverFromApps(settings.GetAllApps())
+}
+
+// NewInstallResolverFromApps creates a resolver over the given application catalog.
+// When an app name appears more than once the first definition wins.
+func NewInstallResolverFromApps(apps []types.CrossPlatformApp) *InstallResolver {
+	r := &InstallResolver{
+		apps:   make([]types.CrossPlatformApp, 0, len(apps)),
+		byName: make(map[string]int, len(apps)),
+	}
+
+	for _, app := range apps {
+		key := normalizeAppName(app.Name)
+		if key == "" {
+			continue
+		}
+		if _, exists := r.byName[key]; exists {
+			log.Debug("Duplicate application in catalog, keeping first definition", "app", app.Name)
+			continue
+		}
+		r.byName[key] = len(r.apps)
+		r.apps = append(r.apps, app)
+	}
+
+	return r
+}
+
+// Lookup finds an application by name, ignoring case and surrounding whitespace
+func (r *InstallResolver) Lookup(name string) (types.CrossPlatformApp, bool) {
+	idx, ok := r.byName[normalizeAppName(name)]
+	if !ok {
+		return types.CrossPlatformApp{}, false
+	}
+	return r.apps[idx], true
+}
+
+// ResolveNames resolves the requested app names and returns them together with their
+// dependencies, ordered so that every dependency precedes the apps that need it.
+// All unknown names are reported at once in an *UnknownAppsError.
+func (r *InstallResolver) ResolveNames(names []string) ([]types.CrossPlatformApp, error) {
+	roots := make([]types.CrossPlatformApp, 0, len(names))
+	var unknown []string
+	suggestions := make(map[string][]string)
+
+	for _, name := range names {
+		app, ok := r.Lookup(name)
+		if !ok {
+			unknown = append(unknown, name)
+			suggestions[name] = r.Suggest(name)
+			continue
+		}
+		roots = append(roots, app)
+	}
+
+	if len(unknown) > 0 {
+		return nil, &UnknownAppsError{Names: unknown, Suggestions: suggestions}
+	}
+
+	return r.expandDependencies(roots)
+}
+
+// ResolveCategories returns every app in the requested categories together with their
+// dependencies. Category names are matched case-insensitively.
+func (r *InstallResolver) ResolveCategories(categories []string) ([]types.CrossPlatformApp, error) {
+	wanted := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		wanted[normalizeAppName(category)] = true
+	}
+
+	matched := make(map[string]bool, len(wanted))
+	var roots []types.CrossPlatformApp
+	for _, app := range r.apps {
+		key := normalizeAppName(app.Category)
+		if wanted[key] {
+			matched[key] = true
+			roots = append(roots, app)
+		}
+	}
+
+	var missing []string
+	for _, category := range categories {
+		if !matched[normalizeAppName(category)] {
+			missing = append(missing, category)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("unknown categor(y/ies): %s (available: %s)",
+			strings.Join(missing, ", "), strings.Join(r.Categories(), ", "))
+	}
+
+	return r.expandDependencies(roots)
+}
+
+// Categories returns the sorted, de-duplicated list of categories in the catalog
+func (r *InstallResolver) Categories() []string {
+	seen := make(map[string]bool)
+	var categories []string
+	for _, app := range r.apps {
+		key := normalizeAppName(app.Category)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		categories = append(categories, app.Category)
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// Suggest returns catalog app names that closely resemble the given name
+func (r *InstallResolver) Suggest(name string) []string {
+	query := normalizeAppName(name)
+	if query == "" {
+		return nil
+	}
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+
+	maxDistance := len(query) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	var candidates []candidate
+	for _, app := range r.apps {
+		key := normalizeAppName(app.Name)
+		distance := levenshteinDistance(query, key)
+		switch {
+		case distance <= maxDistance:
+			candidates = append(candidates, candidate{name: app.Name, distance: distance})
+		case len(query) >= 3 && (strings.Contains(key, query) || strings.Contains(query, key)):
+			candidates = append(candidates, candidate{name: app.Name, distance: maxDistance + 1})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	if len(candidates) > maxAppSuggestions {
+		candidates = candidates[:maxAppSuggestions]
+	}
+
+	result := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		result = append(result, c.name)
+	}
+	return result
+}
+
+// expandDependencies walks the platform-specific Dependencies of each root depth-first and
+// returns the apps in dependency order. Dependencies that are not catalog apps (for example
+// package managers such as apt) are treated as system prerequisites and left to the installers.
+func (r *InstallResolver) expandDependencies(roots []types.CrossPlatformApp) ([]types.CrossPlatformApp, error) {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	state := make(map[string]int, len(roots))
+	ordered := make([]types.CrossPlatformApp, 0, len(roots))
+
+	var visit func(app types.CrossPlatformApp, path []string) error
+	visit = func(app types.CrossPlatformApp, path []string) error {
+		key := normalizeAppName(app.Name)
+		switch state[key] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle detected: %s", strings.Join(append(path, app.Name), " -> "))
+		}
+
+		state[key] = visiting
+		path = append(path, app.Name)
+
+		osConfig := app.GetOSConfig()
+		for _, depName := range osConfig.Dependencies {
+			dep, ok := r.Lookup(depName)
+			if !ok {
+				log.Debug("Dependency is not a catalog app, treating as system prerequisite",
+					"app", app.Name, "dependency", depName)
+				continue
+			}
+			if err := visit(dep, path); err != nil {
+				return err
+			}
+		}
+
+		state[key] = visited
+		ordered = append(ordered, app)
+		return nil
+	}
+
+	for _, root := range roots {
+		if err := visit(root, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// normalizeAppName lower-cases and trims a name for catalog lookups
+func normalizeAppName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// levenshteinDistance computes the edit distance between two strings
+func levenshteinDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(br)]
+}
@@ -0,0 +1,112 @@
+package commands_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/commands"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+func resolverTestApp(name, category string, deps ...string) types.CrossPlatformApp {
+	osConfig := types.OSConfig{
+		InstallMethod:  "apt",
+		InstallCommand: name,
+		Dependencies:   deps,
+	}
+	return types.CrossPlatformApp{
+		Name:     name,
+		Category: category,
+		Linux:    osConfig,
+		MacOS:    osConfig,
+		Windows:  osConfig,
+	}
+}
+
+func resolvedNames(apps []types.CrossPlatformApp) []string {
+	names := make([]string, 0, len(apps))
+	for _, app := range apps {
+		names = append(names, app.Name)
+	}
+	return names
+}
+
+var _ = Describe("InstallResolver", func() {
+	var resolver *commands.InstallResolver
+
+	BeforeEach(func() {
+		resolver = commands.NewInstallResolverFromApps([]types.CrossPlatformApp{
+			resolverTestApp("curl", "Utilities", "apt"),
+			resolverTestApp("git", "Development Tools", "apt"),
+			resolverTestApp("fzf", "Utilities", "git"),
+			resolverTestApp("Neovim", "Text Editors", "curl", "fzf"),
+			resolverTestApp("LazyGit", "Development Tools", "git"),
+			resolverTestApp("PostgreSQL", "Databases"),
+			resolverTestApp("Redis", "Databases"),
+		})
+	})
+
+	Describe("ResolveNames", func() {
+		It("matches names case-insensitively", func() {
+			apps, err := resolver.ResolveNames([]string{"neovim"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resolvedNames(apps)).To(ContainElement("Neovim"))
+		})
+
+		It("orders transitive dependencies before the apps that need them", func() {
+			apps, err := resolver.ResolveNames([]string{"neovim", "lazygit"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resolvedNames(apps)).To(Equal([]string{"curl", "git", "fzf", "Neovim", "LazyGit"}))
+		})
+
+		It("does not install an app twice when requested and depended upon", func() {
+			apps, err := resolver.ResolveNames([]string{"git", "fzf", "git"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resolvedNames(apps)).To(Equal([]string{"git", "fzf"}))
+		})
+
+		It("reports every unknown name with suggestions", func() {
+			_, err := resolver.ResolveNames([]string{"neovm", "lazygit", "nothing-like-it"})
+			Expect(err).To(HaveOccurred())
+
+			var unknownErr *commands.UnknownAppsError
+			Expect(errors.As(err, &unknownErr)).To(BeTrue())
+			Expect(unknownErr.Names).To(Equal([]string{"neovm", "nothing-like-it"}))
+			Expect(unknownErr.Suggestions["neovm"]).To(ContainElement("Neovim"))
+			Expect(err.Error()).To(ContainSubstring("did you mean: Neovim"))
+		})
+
+		It("detects dependency cycles", func() {
+			cyclic := commands.NewInstallResolverFromApps([]types.CrossPlatformApp{
+				resolverTestApp("a", "Utilities", "b"),
+				resolverTestApp("b", "Utilities", "a"),
+			})
+			_, err := cyclic.ResolveNames([]string{"a"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("dependency cycle"))
+		})
+	})
+
+	Describe("ResolveCategories", func() {
+		It("selects apps in the requested categories", func() {
+			apps, err := resolver.ResolveCategories([]string{"databases"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resolvedNames(apps)).To(Equal([]string{"PostgreSQL", "Redis"}))
+		})
+
+		It("includes dependencies from outside the category", func() {
+			apps, err := resolver.ResolveCategories([]string{"Text Editors"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resolvedNames(apps)).To(Equal([]string{"curl", "git", "fzf", "Neovim"}))
+		})
+
+		It("rejects unknown categories and lists the available ones", func() {
+			_, err := resolver.ResolveCategories([]string{"Games"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Games"))
+			Expect(err.Error()).To(ContainSubstring("Databases"))
+		})
+	})
+})
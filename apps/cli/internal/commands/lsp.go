@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jameswlane/devex/apps/cli/internal/config"
+	"github.com/jameswlane/devex/apps/cli/internal/lsp"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+func init() {
+	Register(NewLSPCmd)
+}
+
+// NewLSPCmd creates the language server command for editing configuration files
+func NewLSPCmd(repo types.Repository, settings config.CrossPlatformSettings) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lsp",
+		Short: "Run a language server for DevEx configuration files",
+		Long: `Run a Language Server Protocol server over stdin and stdout for DevEx YAML files:
+applications, setup.yaml and security.yaml.
+
+The server completes install methods from the available plugins and app names in
+dependencies and conflicts, shows the documentation of fields on hover, reports the
+problems of 'devex config lint' as you type and jumps from a dependency to the file
+of the app it names. Apps are indexed from the workspace folders and the DevEx
+configuration directories.`,
+		Example: `  # Neovim (nvim-lspconfig)
+  vim.lsp.config('devex', { cmd = { 'devex', 'lsp' }, filetypes = { 'yaml' } })
+
+  # Helix (languages.toml)
+  [language-server.devex]
+  command = "devex"
+  args = ["lsp"]`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var dirs []string
+			for _, dir := range []string{settings.GetConfigDir(), settings.GetTeamConfigDir(), settings.GetUserConfigDir()} {
+				if _, err := os.Stat(dir); err == nil {
+					dirs = append(dirs, dir)
+				}
+			}
+
+			server := lsp.NewServer(lsp.Options{
+				Version: cmd.Root().Version,
+				Dirs:    dirs,
+				Plugins: func(ctx context.Context) map[string]bool {
+					// The plugin system is started here rather than before the command so
+					// the editor does not wait for it, and never downloads plugins
+					if GetPluginBootstrap() == nil {
+						skipPluginDownload = true
+						_ = initializePluginSystem(ctx)
+					}
+					return availablePluginNames(ctx)
+				},
+			})
+			return server.Serve(cmd.Context(), os.Stdin, os.Stdout)
+		},
+	}
+
+	// Editors commonly pass --stdio to language servers, which is the only transport
+	cmd.Flags().Bool("stdio", true, "Communicate over stdin and stdout")
+	_ = cmd.Flags().MarkHidden("stdio")
+
+	return cmd
+}
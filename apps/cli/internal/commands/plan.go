@@ -0,0 +1,432 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/jameswlane/devex/apps/cli/internal/config"
+	"github.com/jameswlane/devex/apps/cli/internal/installers"
+	"github.com/jameswlane/devex/apps/cli/internal/log"
+	"github.com/jameswlane/devex/apps/cli/internal/tui"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+// PlanAction describes what apply will do for a single application
+type PlanAction string
+
+const (
+	// PlanActionInstall installs a desired app that is not present
+	PlanActionInstall PlanAction = "install"
+	// PlanActionReinstall reinstalls a desired app that is recorded but missing from the system
+	PlanActionReinstall PlanAction = "reinstall"
+	// PlanActionAdopt records a desired app that is already installed but not tracked by DevEx
+	PlanActionAdopt PlanAction = "adopt"
+	// PlanActionRemove uninstalls a tracked app that is no longer desired
+	PlanActionRemove PlanAction = "remove"
+)
+
+// PlanChange is a single entry in an installation plan
+type PlanChange struct {
+	Action PlanAction
+	App    types.CrossPlatformApp
+	Reason string
+}
+
+// InstallPlan is the difference between the desired app set and the machine's current state
+type InstallPlan struct {
+	Changes   []PlanChange
+	Unchanged []string
+}
+
+// HasChanges reports whether applying the plan would do anything
+func (p *InstallPlan) HasChanges() bool {
+	return len(p.Changes) > 0
+}
+
+// Count returns the number of changes with the given action
+func (p *InstallPlan) Count(action PlanAction) int {
+	count := 0
+	for _, change := range p.Changes {
+		if change.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// AppsFor returns the apps of all changes with the given actions, preserving plan order
+func (p *InstallPlan) AppsFor(actions ...PlanAction) []types.CrossPlatformApp {
+	var apps []types.CrossPlatformApp
+	for _, change := range p.Changes {
+		for _, action := range actions {
+			if change.Action == action {
+				apps = append(apps, change.App)
+				break
+			}
+		}
+	}
+	return apps
+}
+
+// InstallStateChecker probes the package manager for an app's real installation state.
+// known is false when the state cannot be determined (e.g. the installer plugin is unavailable).
+type InstallStateChecker func(ctx context.Context, app types.CrossPlatformApp) (installed bool, known bool, err error)
+
+// pluginInstallStateChecker checks installation state through the package-manager plugins
+func pluginInstallStateChecker(ctx context.Context, app types.CrossPlatformApp) (bool, bool, error) {
+	osConfig := app.GetOSConfig()
+	if osConfig.InstallMethod == "" {
+		return false, false, nil
+	}
+
+	installer := installers.GetInstaller(ctx, osConfig.InstallMethod)
+	if installer == nil {
+		return false, false, nil
+	}
+
+	installed, err := installer.IsInstalled(osConfig.InstallCommand)
+	if err != nil {
+		return false, false, err
+	}
+	return installed, true, nil
+}
+
+// ComputeInstallPlan diffs the desired apps against the apps recorded in the datastore and
+// the real package-manager state. Desired apps must already be in dependency order.
+func ComputeInstallPlan(ctx context.Context, desired []types.CrossPlatformApp, recorded []string, resolver *InstallResolver, check InstallStateChecker) (*InstallPlan, error) {
+	plan := &InstallPlan{}
+
+	recordedSet := make(map[string]string, len(recorded))
+	for _, name := range recorded {
+		recordedSet[normalizeAppName(name)] = name
+	}
+
+	desiredSet := make(map[string]bool, len(desired))
+	for _, app := range desired {
+		key := normalizeAppName(app.Name)
+		desiredSet[key] = true
+
+		installed, known, err := check(ctx, app)
+		if err != nil {
+			log.Warn("Failed to check installation state, relying on datastore", "app", app.Name, "error", err)
+			known = false
+		}
+
+		_, isRecorded := recordedSet[key]
+		switch {
+		case isRecorded && (!known || installed):
+			plan.Unchanged = append(plan.Unchanged, app.Name)
+		case isRecorded:
+			plan.Changes = append(plan.Changes, PlanChange{
+				Action: PlanActionReinstall,
+				App:    app,
+				Reason: "recorded as installed but missing from the system",
+			})
+		case known && installed:
+			plan.Changes = append(plan.Changes, PlanChange{
+				Action: PlanActionAdopt,
+				App:    app,
+				Reason: "already installed, not yet tracked by DevEx",
+			})
+		default:
+			plan.Changes = append(plan.Changes, PlanChange{
+				Action: PlanActionInstall,
+				App:    app,
+				Reason: "in desired configuration",
+			})
+		}
+	}
+
+	// Removals are listed in reverse dependency order so dependents go before their dependencies
+	var catalogRemovals []types.CrossPlatformApp
+	var recordOnly []string
+	removalSet := make(map[string]bool)
+	for key, name := range recordedSet {
+		if desiredSet[key] {
+			continue
+		}
+		if app, inCatalog := resolver.Lookup(name); inCatalog {
+			catalogRemovals = append(catalogRemovals, app)
+			removalSet[key] = true
+		} else {
+			recordOnly = append(recordOnly, name)
+		}
+	}
+	sort.Slice(catalogRemovals, func(i, j int) bool { return catalogRemovals[i].Name < catalogRemovals[j].Name })
+	sort.Strings(recordOnly)
+
+	ordered, err := resolver.expandDependencies(catalogRemovals)
+	if err != nil {
+		return nil, fmt.Errorf("failed to order removals: %w", err)
+	}
+	for i := len(ordered) - 1; i >= 0; i-- {
+		if !removalSet[normalizeAppName(ordered[i].Name)] {
+			continue
+		}
+		plan.Changes = append(plan.Changes, PlanChange{
+			Action: PlanActionRemove,
+			App:    ordered[i],
+			Reason: "no longer in desired configuration",
+		})
+	}
+
+	for _, name := range recordOnly {
+		plan.Changes = append(plan.Changes, PlanChange{
+			Action: PlanActionRemove,
+			App:    types.CrossPlatformApp{Name: name},
+			Reason: "not in the application catalog; only the installation record will be removed",
+		})
+	}
+
+	return plan, nil
+}
+
+// WithoutRemovals drops the removals from the plan, keeping apps that are no longer desired
+func (p *InstallPlan) WithoutRemovals() {
+	kept := p.Changes[:0]
+	for _, change := range p.Changes {
+		if change.Action != PlanActionRemove {
+			kept = append(kept, change)
+		}
+	}
+	p.Changes = kept
+}
+
+// buildInstallPlan computes the plan for the current settings and datastore. Apps that are
+// recorded but no longer desired are only planned for removal when prune is set, as the
+// datastore also records apps the user installed outside of the configuration.
+func buildInstallPlan(ctx context.Context, repo types.Repository, settings config.CrossPlatformSettings, prune bool, check InstallStateChecker) (*InstallPlan, error) {
+	resolver := NewInstallResolver(settings)
+
+	desired, err := resolver.expandDependencies(settings.GetDefaultApps())
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve desired applications: %w", err)
+	}
+
+	installedApps, err := repo.ListApps()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed apps: %w", err)
+	}
+	recorded := make([]string, 0, len(installedApps))
+	for _, app := range installedApps {
+		recorded = append(recorded, app.Name)
+	}
+
+	plan, err := ComputeInstallPlan(ctx, desired, recorded, resolver, check)
+	if err != nil {
+		return nil, err
+	}
+	if !prune {
+		plan.WithoutRemovals()
+	}
+	return plan, nil
+}
+
+// printInstallPlan renders a plan in a Terraform-like format
+func printInstallPlan(plan *InstallPlan) {
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+
+	if !plan.HasChanges() {
+		fmt.Printf("%s No changes. %d application(s) match the desired configuration.\n", green("✅"), len(plan.Unchanged))
+		return
+	}
+
+	fmt.Printf("%s DevEx will perform the following actions:\n\n", cyan("📋"))
+	for _, change := range plan.Changes {
+		var symbol string
+		switch change.Action {
+		case PlanActionInstall:
+			symbol = green("+")
+		case PlanActionReinstall, PlanActionAdopt:
+			symbol = yellow("~")
+		case PlanActionRemove:
+			symbol = red("-")
+		}
+
+		method := change.App.GetOSConfig().InstallMethod
+		if method != "" {
+			fmt.Printf("  %s %s (%s) %s\n", symbol, change.App.Name, method, change.Action)
+		} else {
+			fmt.Printf("  %s %s %s\n", symbol, change.App.Name, change.Action)
+		}
+		fmt.Printf("      %s\n", change.Reason)
+	}
+
+	fmt.Printf("\nPlan: %s to install, %s to change, %s to remove, %d unchanged.\n",
+		green(plan.Count(PlanActionInstall)),
+		yellow(plan.Count(PlanActionReinstall)+plan.Count(PlanActionAdopt)),
+		red(plan.Count(PlanActionRemove)),
+		len(plan.Unchanged))
+}
+
+// NewPlanCmd creates the plan command
+func NewPlanCmd(repo types.Repository, settings config.CrossPlatformSettings) *cobra.Command {
+	var prune bool
+
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Show the changes needed to reach the desired configuration",
+		Long: `Compute the difference between the desired application set and this machine.
+
+The desired set is every default application from the merged configuration
+layers (default, team and user), including their dependencies. It is compared
+against the DevEx installation database and the real package-manager state:
+
+  +  install    desired but not installed
+  ~  reinstall  recorded by DevEx but missing from the system
+  ~  adopt      installed on the system but not recorded by DevEx
+  -  remove     recorded by DevEx but no longer desired (with --prune)
+
+Apps installed with 'devex install' are recorded too, so removals are only
+planned with --prune.
+
+Run 'devex apply' to execute the plan.`,
+		Example: `  # Preview the changes
+  devex plan
+
+  # Also preview the apps apply --prune would uninstall
+  devex plan --prune`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			plan, err := buildInstallPlan(cmd.Context(), repo, settings, prune, pluginInstallStateChecker)
+			if err != nil {
+				return err
+			}
+			printInstallPlan(plan)
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	cmd.Flags().BoolVar(&prune, "prune", false, "Plan the removal of tracked applications that are no longer desired")
+
+	return cmd
+}
+
+// NewApplyCmd creates the apply command
+func NewApplyCmd(repo types.Repository, settings config.CrossPlatformSettings) *cobra.Command {
+	var (
+		autoApprove bool
+		prune       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Reconcile this machine with the desired configuration",
+		Long: `Compute the installation plan (see 'devex plan') and execute only the changes.
+
+Applications are installed in dependency order. With --prune, applications that
+are tracked by DevEx but no longer part of the desired configuration are
+uninstalled, dependents before their dependencies. This includes apps installed
+with 'devex install' that are not in the configuration, so review the plan first.`,
+		Example: `  # Review and apply the plan
+  devex apply
+
+  # Apply without confirmation
+  devex apply --auto-approve
+
+  # Also uninstall tracked apps that are no longer desired
+  devex apply --prune`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runApply(cmd.Context(), autoApprove, prune, repo, settings)
+		},
+		SilenceUsage: true,
+	}
+
+	cmd.Flags().BoolVar(&autoApprove, "auto-approve", false, "Skip interactive approval of the plan")
+	cmd.Flags().BoolVar(&prune, "prune", false, "Uninstall tracked applications that are no longer desired")
+
+	return cmd
+}
+
+// runApply executes the delta between the desired and current state
+func runApply(ctx context.Context, autoApprove, prune bool, repo types.Repository, settings config.CrossPlatformSettings) error {
+	settings.Verbose = viper.GetBool("verbose")
+	dryRun := viper.GetBool("dry-run")
+
+	plan, err := buildInstallPlan(ctx, repo, settings, prune, pluginInstallStateChecker)
+	if err != nil {
+		return err
+	}
+
+	printInstallPlan(plan)
+	if !plan.HasChanges() || dryRun {
+		return nil
+	}
+
+	if !autoApprove {
+		fmt.Print("\nDo you want to perform these actions? (y/N): ")
+		var response string
+		_, _ = fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" {
+			fmt.Println("Apply cancelled.")
+			return nil
+		}
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	// Record apps that are already present before installing anything else
+	for _, app := range plan.AppsFor(PlanActionAdopt) {
+		if err := repo.AddApp(app.Name); err != nil {
+			log.Warn("Failed to record adopted app", "app", app.Name, "error", err)
+			continue
+		}
+		fmt.Printf("%s Recorded %s as installed\n", green("✓"), app.Name)
+	}
+
+	if toInstall := plan.AppsFor(PlanActionInstall, PlanActionReinstall); len(toInstall) > 0 {
+		if err := tui.StartInstallation(ctx, toInstall, repo, settings); err != nil {
+			return fmt.Errorf("installation failed: %w", err)
+		}
+	}
+
+	var removeErrors []string
+	for _, app := range plan.AppsFor(PlanActionRemove) {
+		if err := removePlannedApp(ctx, app, repo); err != nil {
+			fmt.Printf("%s Failed to remove %s: %v\n", yellow("⚠️"), app.Name, err)
+			removeErrors = append(removeErrors, app.Name)
+			continue
+		}
+		fmt.Printf("%s Removed %s\n", green("✓"), app.Name)
+	}
+
+	if len(removeErrors) > 0 {
+		return fmt.Errorf("failed to remove: %s", strings.Join(removeErrors, ", "))
+	}
+
+	fmt.Printf("%s Apply complete!\n", green("✅"))
+	return nil
+}
+
+// removePlannedApp uninstalls an app that is no longer desired and drops its installation record
+func removePlannedApp(ctx context.Context, app types.CrossPlatformApp, repo types.Repository) error {
+	osConfig := app.GetOSConfig()
+	if osConfig.InstallMethod != "" {
+		installer := installers.GetInstaller(ctx, osConfig.InstallMethod)
+		if installer == nil {
+			return fmt.Errorf("install method '%s' is not available", osConfig.InstallMethod)
+		}
+
+		uninstallCommand := osConfig.UninstallCommand
+		if uninstallCommand == "" {
+			uninstallCommand = osConfig.InstallCommand
+		}
+		if err := installer.Uninstall(uninstallCommand, repo); err != nil {
+			return err
+		}
+	}
+
+	return repo.DeleteApp(app.Name)
+}
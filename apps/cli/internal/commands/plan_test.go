@@ -0,0 +1,122 @@
+package commands_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/commands"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+var _ = Describe("ComputeInstallPlan", func() {
+	var (
+		ctx      context.Context
+		resolver *commands.InstallResolver
+		catalog  []types.CrossPlatformApp
+		onSystem map[string]bool
+		checker  commands.InstallStateChecker
+	)
+
+	changeFor := func(plan *commands.InstallPlan, name string) *commands.PlanChange {
+		for i := range plan.Changes {
+			if plan.Changes[i].App.Name == name {
+				return &plan.Changes[i]
+			}
+		}
+		return nil
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		catalog = []types.CrossPlatformApp{
+			resolverTestApp("git", "Development Tools"),
+			resolverTestApp("curl", "Utilities"),
+			resolverTestApp("fzf", "Utilities"),
+			resolverTestApp("Neovim", "Text Editors"),
+			resolverTestApp("Redis", "Databases"),
+		}
+		resolver = commands.NewInstallResolverFromApps(catalog)
+		onSystem = map[string]bool{}
+		checker = func(_ context.Context, app types.CrossPlatformApp) (bool, bool, error) {
+			return onSystem[app.Name], true, nil
+		}
+	})
+
+	It("plans installs for desired apps that are missing", func() {
+		plan, err := commands.ComputeInstallPlan(ctx, catalog[:2], nil, resolver, checker)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(plan.Count(commands.PlanActionInstall)).To(Equal(2))
+		Expect(plan.Unchanged).To(BeEmpty())
+	})
+
+	It("leaves recorded and installed apps unchanged", func() {
+		onSystem["git"] = true
+		plan, err := commands.ComputeInstallPlan(ctx, catalog[:1], []string{"git"}, resolver, checker)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(plan.HasChanges()).To(BeFalse())
+		Expect(plan.Unchanged).To(ConsistOf("git"))
+	})
+
+	It("reinstalls recorded apps that drifted off the system", func() {
+		plan, err := commands.ComputeInstallPlan(ctx, catalog[:1], []string{"git"}, resolver, checker)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changeFor(plan, "git").Action).To(Equal(commands.PlanActionReinstall))
+	})
+
+	It("adopts apps installed outside of DevEx", func() {
+		onSystem["curl"] = true
+		plan, err := commands.ComputeInstallPlan(ctx, catalog[1:2], nil, resolver, checker)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changeFor(plan, "curl").Action).To(Equal(commands.PlanActionAdopt))
+	})
+
+	It("removes recorded apps that are no longer desired", func() {
+		onSystem["git"] = true
+		plan, err := commands.ComputeInstallPlan(ctx, catalog[:1], []string{"git", "Redis", "legacy-tool"}, resolver, checker)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(plan.Count(commands.PlanActionRemove)).To(Equal(2))
+		Expect(changeFor(plan, "Redis").App.GetOSConfig().InstallMethod).To(Equal("apt"))
+		Expect(changeFor(plan, "legacy-tool").Reason).To(ContainSubstring("not in the application catalog"))
+	})
+
+	It("removes dependents before their dependencies", func() {
+		catalog = append(catalog,
+			resolverTestApp("lazygit", "Development Tools", "git", "fzf"),
+			resolverTestApp("delta", "Development Tools", "git"),
+		)
+		resolver = commands.NewInstallResolverFromApps(catalog)
+
+		plan, err := commands.ComputeInstallPlan(ctx, nil, []string{"git", "delta", "fzf", "lazygit", "legacy-tool"}, resolver, checker)
+		Expect(err).ToNot(HaveOccurred())
+		names := resolvedNames(plan.AppsFor(commands.PlanActionRemove))
+		Expect(names).To(HaveExactElements("lazygit", "fzf", "delta", "git", "legacy-tool"))
+	})
+
+	It("keeps tracked apps when removals are dropped", func() {
+		plan, err := commands.ComputeInstallPlan(ctx, catalog[:1], []string{"Redis"}, resolver, checker)
+		Expect(err).ToNot(HaveOccurred())
+		plan.WithoutRemovals()
+		Expect(plan.Count(commands.PlanActionRemove)).To(BeZero())
+		Expect(plan.Count(commands.PlanActionInstall)).To(Equal(1))
+	})
+
+	It("trusts the datastore when the real state cannot be determined", func() {
+		unknown := func(_ context.Context, _ types.CrossPlatformApp) (bool, bool, error) {
+			return false, false, errors.New("plugin unavailable")
+		}
+		plan, err := commands.ComputeInstallPlan(ctx, catalog[:2], []string{"git"}, resolver, unknown)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(plan.Unchanged).To(ConsistOf("git"))
+		Expect(changeFor(plan, "curl").Action).To(Equal(commands.PlanActionInstall))
+	})
+
+	It("keeps the desired dependency order for installs", func() {
+		plan, err := commands.ComputeInstallPlan(ctx, catalog[:3], nil, resolver, checker)
+		Expect(err).ToNot(HaveOccurred())
+		names := resolvedNames(plan.AppsFor(commands.PlanActionInstall, commands.PlanActionReinstall))
+		Expect(names).To(Equal([]string{"git", "curl", "fzf"}))
+	})
+})
@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/signal"
+	"runtime"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jameswlane/devex/apps/cli/internal/bootstrap"
+	"github.com/jameswlane/devex/apps/cli/internal/config"
+	"github.com/jameswlane/devex/apps/cli/internal/pluginregistry"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+func init() {
+	Register(NewRegistryCmd)
+}
+
+// NewRegistryCmd creates the registry command for hosting a self-hosted plugin registry
+func NewRegistryCmd(_ types.Repository, _ config.CrossPlatformSettings) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "registry",
+		Short: "Host a plugin registry from a local directory",
+		Long: `Serve DevEx plugins from a local directory for networks that cannot reach the
+hosted registry. 'publish' copies plugin binaries, checksums and signatures into the
+directory, 'serve' exposes it with the registry API and 'use' points DevEx at it.`,
+		Example: `  # Publish a plugin built for this machine
+  devex registry publish ./registry dist/package-manager-apt
+
+  # Publish a cross-compiled binary with its metadata
+  devex registry publish ./registry dist/package-manager-brew-darwin-arm64 \
+    --platform darwin-arm64 --info package-manager-brew.json
+
+  # Serve the registry
+  devex registry serve ./registry --addr :8080
+
+  # Use it on a build machine
+  devex registry use http://registry.internal:8080`,
+	}
+
+	cmd.AddCommand(newRegistryServeCmd())
+	cmd.AddCommand(newRegistryPublishCmd())
+	cmd.AddCommand(newRegistryUseCmd())
+
+	return cmd
+}
+
+// newRegistryServeCmd creates the registry serve command
+func newRegistryServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve [dir]",
+		Short: "Serve a registry directory over HTTP",
+		Long: `Serve a registry directory with the API used by 'devex plugin' commands and plugin
+bootstrapping. Plugins published while the server runs are picked up automatically.
+
+Binary URLs are derived from the request host unless --base-url is set, which is
+needed when the registry is reached through a proxy or a different host name.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			addr, _ := cmd.Flags().GetString("addr")
+			baseURL, _ := cmd.Flags().GetString("base-url")
+
+			server, err := pluginregistry.NewServer(dir, baseURL)
+			if err != nil {
+				return err
+			}
+
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", addr, err)
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			httpServer := &http.Server{
+				Handler:           server.Handler(),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				_ = httpServer.Shutdown(shutdownCtx)
+			}()
+
+			fmt.Printf("📦 Serving plugin registry from %s on http://%s\n", dir, listener.Addr())
+			fmt.Println("   Point DevEx at it with: devex registry use <url>")
+			if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("registry server failed: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("addr", ":8080", "Address to listen on")
+	cmd.Flags().String("base-url", "", "Public URL of the registry, used in binary download URLs")
+
+	return cmd
+}
+
+// newRegistryPublishCmd creates the registry publish command
+func newRegistryPublishCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "publish <dir> <binary>",
+		Short: "Add a plugin binary to a registry directory",
+		Long: `Copy a plugin binary into a registry directory, record its SHA-256 checksum and size
+and update the registry index. A minisign signature is published with it when
+--signature is given or <binary>.minisig exists. Clients refuse plugins that are not
+signed by a key they trust, so sign every binary, e.g. 'minisign -S -m <binary>', and
+add the public key to plugin_trusted_keys in ~/.devex/config.yaml on each client.
+
+Plugin metadata is read from the binary with --plugin-info. Binaries built for another
+platform cannot be run, so pass the metadata with --info instead, e.g. the output of
+'<host binary> --plugin-info'.
+
+Publishing a new version replaces all binaries of the previous version; publish the
+binary of every platform for the new version.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			platformKey, _ := cmd.Flags().GetString("platform")
+			infoFile, _ := cmd.Flags().GetString("info")
+			signature, _ := cmd.Flags().GetString("signature")
+			pluginType, _ := cmd.Flags().GetString("type")
+			priority, _ := cmd.Flags().GetInt("priority")
+
+			opts := pluginregistry.PublishOptions{
+				Binary:    args[1],
+				Platform:  platformKey,
+				Signature: signature,
+				Type:      pluginType,
+				Priority:  priority,
+			}
+			if infoFile != "" {
+				info, err := pluginregistry.LoadPluginInfo(infoFile)
+				if err != nil {
+					return err
+				}
+				opts.Info = info
+			}
+
+			metadata, err := pluginregistry.Publish(cmd.Context(), args[0], opts)
+			if err != nil {
+				return fmt.Errorf("failed to publish %s: %w", args[1], err)
+			}
+
+			fmt.Printf("✅ Published %s v%s\n", metadata.Name, metadata.Version)
+			for _, platformKey := range sortedBinaryPlatforms(metadata.Binaries) {
+				binary := metadata.Binaries[platformKey]
+				fmt.Printf("   %-14s %s  %d bytes\n", platformKey, binary.Checksum, binary.Size)
+			}
+			published := opts.Platform
+			if published == "" {
+				published = runtime.GOOS + "-" + runtime.GOARCH
+			}
+			if metadata.Binaries[published].Signature == "" {
+				fmt.Println("⚠️  The binary is unsigned; clients only install it with 'devex plugin install --allow-unverified'")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("platform", "", "Platform the binary was built for, e.g. linux-amd64 (default: this machine)")
+	cmd.Flags().String("info", "", "JSON file with the plugin metadata in --plugin-info format")
+	cmd.Flags().String("signature", "", "Minisign signature of the binary (default: <binary>.minisig if present)")
+	cmd.Flags().String("type", "", "Plugin type, e.g. package-manager or desktop")
+	cmd.Flags().Int("priority", 0, "Installation priority")
+
+	return cmd
+}
+
+// newRegistryUseCmd creates the registry use command
+func newRegistryUseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "use [url]",
+		Short: "Download plugins from a self-hosted registry",
+		Long: `Store the plugin registry URL in ~/.devex/config.yaml. The DEVEX_PLUGIN_REGISTRY_URL
+environment variable still takes precedence. Use --reset to go back to the hosted registry.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reset, _ := cmd.Flags().GetBool("reset")
+			switch {
+			case reset && len(args) > 0:
+				return fmt.Errorf("--reset does not take a URL")
+			case !reset && len(args) == 0:
+				return fmt.Errorf("a registry URL is required")
+			}
+
+			registryURL := ""
+			if !reset {
+				registryURL = args[0]
+				client, err := sdk.NewRegistryClient(sdk.RegistryConfig{BaseURL: registryURL, Timeout: 10 * time.Second})
+				if err != nil {
+					return err
+				}
+				if _, err := client.GetRegistry(cmd.Context()); err != nil {
+					return fmt.Errorf("registry at %s is not reachable: %w", registryURL, err)
+				}
+			}
+
+			if err := bootstrap.SetRegistryURLInConfig(registryURL); err != nil {
+				return err
+			}
+			fmt.Printf("Plugin registry: %s\n", bootstrap.GetRegistryURL())
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("reset", false, "Use the default hosted registry again")
+
+	return cmd
+}
+
+// sortedBinaryPlatforms returns the platforms of a plugin's binaries in order
+func sortedBinaryPlatforms(binaries map[string]sdk.PlatformBinary) []string {
+	platforms := make([]string, 0, len(binaries))
+	for platformKey := range binaries {
+		platforms = append(platforms, platformKey)
+	}
+	sort.Strings(platforms)
+	return platforms
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/jameswlane/devex/apps/cli/internal/bootstrap"
 	"github.com/jameswlane/devex/apps/cli/internal/config"
+	"github.com/jameswlane/devex/apps/cli/internal/installers"
 	"github.com/jameswlane/devex/apps/cli/internal/log"
 	"github.com/jameswlane/devex/apps/cli/internal/types"
 )
@@ -48,6 +49,24 @@ All installations are configurable via YAML files in ~/.local/share/devex/config
 				return err
 			}
 
+			// The language server speaks on stdout and starts the plugin system itself
+			if cmd.Name() == "lsp" {
+				return nil
+			}
+
+			// Export traces and metrics of the run when configured
+			startTelemetry(cmd)
+
+			// Installing from a bundle must not touch the network
+			if bundleFlag := cmd.Flags().Lookup("from-bundle"); bundleFlag != nil && bundleFlag.Value.String() != "" {
+				offlineMode = true
+			}
+
+			// Keep a team configuration with a sync interval up to date
+			if !isRunningInTest() && !offlineMode && cmd.CommandPath() != "devex config team sync" {
+				startBackgroundTeamSync(settings)
+			}
+
 			// Skip plugin system initialization for setup command to prevent premature downloads
 			// Plugins will be downloaded during the setup flow itself when user confirms
 			cmdName := cmd.Name()
@@ -56,6 +75,11 @@ All installations are configurable via YAML files in ~/.local/share/devex/config
 				return nil
 			}
 
+			// Registry commands host plugins for other machines and must not download them
+			if cmd.Parent() != nil && cmd.Parent().Name() == "registry" {
+				return nil
+			}
+
 			return initializePluginSystem(cmd.Context())
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -78,6 +102,8 @@ All installations are configurable via YAML files in ~/.local/share/devex/config
 	// Register other commands
 	cmd.AddCommand(NewSetupCmd(repo, settings))
 	cmd.AddCommand(NewInstallCmd(repo, settings))
+	cmd.AddCommand(NewPlanCmd(repo, settings))
+	cmd.AddCommand(NewApplyCmd(repo, settings))
 	cmd.AddCommand(NewUninstallCmd(repo, settings))
 	cmd.AddCommand(NewRollbackCmd(repo, settings))
 	cmd.AddCommand(NewStatusCmd(repo, settings))
@@ -89,10 +115,15 @@ All installations are configurable via YAML files in ~/.local/share/devex/config
 	cmd.AddCommand(NewRecoveryCmd(repo, settings))
 	cmd.AddCommand(NewTemplateCmd(repo, settings))
 	cmd.AddCommand(NewCacheCmd(repo, settings))
+	cmd.AddCommand(NewSnapshotCmd(repo, settings))
+	cmd.AddCommand(NewAuditCmd(repo, settings))
+	cmd.AddCommand(NewRegistryCmd(repo, settings))
+	cmd.AddCommand(NewBundleCmd(repo, settings))
 	cmd.AddCommand(NewDetectCmd(repo, settings))
 	cmd.AddCommand(NewListCmd(repo, settings))
 	cmd.AddCommand(NewShellCmd(repo, settings))
 	cmd.AddCommand(NewSystemCmd(settings))
+	cmd.AddCommand(NewLSPCmd(repo, settings))
 	cmd.AddCommand(NewCompletionCmd())
 	cmd.AddCommand(NewHelpCmd(repo, settings))
 
@@ -195,17 +226,7 @@ func validateViperConfiguration() error {
 			logLevel, strings.Join(validLogLevels, ", "))
 	}
 
-	// Validate categories if provided
-	categories := viper.GetStringSlice("categories")
-	if len(categories) > 0 {
-		validCategories := []string{"development", "databases", "desktop", "terminal", "optional"}
-		for _, category := range categories {
-			if !contains(validCategories, category) {
-				return fmt.Errorf("invalid category '%s' - must be one of: %s",
-					category, strings.Join(validCategories, ", "))
-			}
-		}
-	}
+	// Categories are validated against the application catalog when they are resolved
 
 	log.Debug("Configuration validation passed")
 	return nil
@@ -267,6 +288,9 @@ func initializePluginSystem(ctx context.Context) error {
 		return nil // Don't fail the entire CLI
 	}
 
+	// Install methods run through the plugins of this bootstrap
+	installers.InitializeWithPluginBootstrap(pluginBootstrap)
+
 	// TODO: Register plugin commands with root command
 	// This would need to be done differently since we're inside NewRootCmd
 	// For now, plugins will be accessed via the plugin subcommand
@@ -44,6 +44,13 @@ Run 'devex setup' to begin the guided installation process.`,
   # Run automated setup (non-interactive mode)
   DEVEX_NONINTERACTIVE=1 devex setup
 
+  # Continue an interrupted setup from its first incomplete step
+  devex setup --resume
+
+  # Record your answers, then replay them unattended on another machine
+  devex setup --record answers.yaml
+  devex setup --answers answers.yaml
+
   # Setup with verbose logging
   devex setup --verbose
 
@@ -61,6 +68,16 @@ Run 'devex setup' to begin the guided installation process.`,
 
 			verbose := viper.GetBool("verbose")
 			configPath := viper.GetString("config")
+			answersPath, _ := cmd.Flags().GetString("answers")
+			recordPath, _ := cmd.Flags().GetString("record")
+			resume, _ := cmd.Flags().GetBool("resume")
+
+			if answersPath != "" && recordPath != "" {
+				return fmt.Errorf("--answers and --record cannot be used together")
+			}
+			if answersPath != "" && resume {
+				return fmt.Errorf("--answers and --resume cannot be used together")
+			}
 
 			if verbose {
 				log.Info("Starting DevEx setup in verbose mode")
@@ -94,8 +111,40 @@ Run 'devex setup' to begin the guided installation process.`,
 				}
 			}
 
+			// Answers files, recordings and resuming need a setup workflow; fall back to the default one
+			if setupConfig == nil && (answersPath != "" || recordPath != "" || resume) {
+				setupConfig, err = config.LoadSetupConfig("")
+				if err != nil {
+					return fmt.Errorf("failed to load setup config: %w", err)
+				}
+			}
+
+			// Load the answers before anything is installed so a bad file fails fast
+			var answers *setup.AnswersFile
+			if answersPath != "" {
+				answers, err = setup.LoadAnswers(answersPath)
+				if err != nil {
+					return err
+				}
+			}
+
+			// Look up the interrupted setup before anything is installed
+			var progress *setup.SetupProgress
+			if resume {
+				progress, err = setup.LoadProgress(repo)
+				if err != nil {
+					return err
+				}
+				if progress == nil {
+					return fmt.Errorf("no interrupted setup to resume")
+				}
+			}
+
 			// Check if running in non-interactive mode
-			if !setup.IsInteractiveMode() {
+			if answers == nil && !setup.IsInteractiveMode() {
+				if recordPath != "" || resume {
+					return fmt.Errorf("--record and --resume need an interactive session")
+				}
 				log.Info("Running in non-interactive automated mode")
 				return setup.RunAutomatedSetup(ctx, repo, settings)
 			}
@@ -112,12 +161,40 @@ Run 'devex setup' to begin the guided installation process.`,
 				// Continue anyway - plugins might be installed during setup
 			}
 
+			if answers != nil {
+				log.Info("Running unattended setup from answers file", "path", answersPath)
+				return setup.RunUnattendedSetup(ctx, setupConfig, answers, repo, settings, detectedPlatform, pluginBootstrap)
+			}
+
+			// Plugins cannot ask for consent once the TUI owns the terminal, so the plugins the
+			// setup runs are installed and granted first
+			requiredPlugins := setup.RequiredPlugins(setupConfig)
+			for _, pluginName := range requiredPlugins {
+				if err := pluginBootstrap.EnsurePlugin(ctx, pluginName); err != nil {
+					log.Warn("Failed to install plugin required by setup", "plugin", pluginName, "error", err)
+				}
+			}
+			if pluginPlatform := pluginBootstrap.GetPlatform(); pluginPlatform != nil {
+				requiredPlugins = append(requiredPlugins, pluginPlatform.GetRequiredPlugins()...)
+			}
+			if err := pluginBootstrap.EnsurePluginsPermissions(requiredPlugins); err != nil {
+				fmt.Printf("⚠️  %v\n", err)
+			}
+			defer bootstrap.SuspendPermissionPrompts()()
+
 			// Run interactive setup using dynamic model
 			log.Info("Starting interactive setup")
 			var model tea.Model
 			if setupConfig != nil {
 				// Use dynamic model with custom config
-				model = setup.NewDynamicSetupModel(setupConfig, repo, settings, detectedPlatform, pluginBootstrap)
+				dynamicModel := setup.NewDynamicSetupModel(setupConfig, repo, settings, detectedPlatform, pluginBootstrap)
+				if progress != nil {
+					if err := dynamicModel.Resume(progress); err != nil {
+						return err
+					}
+					log.Info("Resuming setup", "setup", progress.Setup, "step", progress.Step)
+				}
+				model = dynamicModel
 			} else {
 				// Use default setup model (fallback to old behavior for now)
 				model = setup.NewSetupModel(repo, settings, detectedPlatform)
@@ -137,6 +214,24 @@ Run 'devex setup' to begin the guided installation process.`,
 				}
 			}
 
+			// The alternate screen hides anything printed while the TUI runs
+			if m, ok := finalModel.(*setup.DynamicSetupModel); ok && !m.Completed() {
+				if saved, err := setup.LoadProgress(repo); err == nil && saved != nil {
+					fmt.Printf("Setup did not finish. Run 'devex setup --resume' to continue from step %s.\n", saved.Step)
+				}
+			}
+
+			if m, ok := finalModel.(*setup.DynamicSetupModel); ok && recordPath != "" {
+				if !m.Completed() {
+					fmt.Printf("Setup did not finish, answers were not recorded\n")
+				} else if err := setup.WriteAnswers(recordPath, setupConfig, m.Answers()); err != nil {
+					return err
+				} else {
+					fmt.Printf("📝 Answers recorded to %s\n", recordPath)
+					fmt.Printf("   Replay them with: devex setup --answers %s\n", recordPath)
+				}
+			}
+
 			log.Info("Setup completed successfully")
 			return nil
 		},
@@ -145,6 +240,9 @@ Run 'devex setup' to begin the guided installation process.`,
 	cmd.Flags().BoolP("verbose", "v", false, "Enable verbose output")
 	cmd.Flags().Bool("non-interactive", false, "Run in non-interactive mode (automated)")
 	cmd.Flags().StringP("config", "c", "", "Path to custom setup configuration file (YAML)")
+	cmd.Flags().String("answers", "", "Run unattended with answers from this YAML file")
+	cmd.Flags().String("record", "", "Write the answers given interactively to this YAML file")
+	cmd.Flags().Bool("resume", false, "Continue the last interrupted setup from its first incomplete step")
 
 	_ = viper.BindPFlag("verbose", cmd.Flags().Lookup("verbose"))
 	_ = viper.BindPFlag("non-interactive", cmd.Flags().Lookup("non-interactive"))
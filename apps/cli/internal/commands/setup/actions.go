@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/jameswlane/devex/apps/cli/internal/audit"
 	"github.com/jameswlane/devex/apps/cli/internal/bootstrap"
 	"github.com/jameswlane/devex/apps/cli/internal/config"
 	"github.com/jameswlane/devex/apps/cli/internal/log"
@@ -55,6 +56,50 @@ func (ae *ActionExecutor) Execute(ctx context.Context, action *types.StepAction,
 	}
 }
 
+// RequiredPlugins returns the plugins the steps of a setup configuration run, so their permissions
+// can be granted before the setup TUI takes over the terminal
+func RequiredPlugins(config *types.SetupConfig) []string {
+	if config == nil {
+		return nil
+	}
+
+	var plugins []string
+	for _, step := range config.Steps {
+		if step.Question != nil && step.Question.OptionsSource != nil && step.Question.OptionsSource.Type == types.SourceTypePlugin {
+			plugins = append(plugins, step.Question.OptionsSource.Plugin)
+		}
+		if step.Action != nil {
+			plugins = append(plugins, actionPlugins(step.Action)...)
+		}
+	}
+	return plugins
+}
+
+// actionPlugins returns the plugins an action runs, mirroring the dispatch of Execute
+func actionPlugins(action *types.StepAction) []string {
+	params := action.Params
+	var plugins []string
+	switch action.Type {
+	case types.ActionTypeInstall:
+		if _, ok := params["install_languages"]; ok {
+			plugins = append(plugins, "mise")
+		} else if _, ok := params["install_databases"]; ok {
+			plugins = append(plugins, "docker")
+		}
+	case types.ActionTypeConfigure:
+		if _, ok := params["configure_shell"]; ok {
+			plugins = append(plugins, "tool-shell")
+		} else if _, ok := params["configure_git"]; ok {
+			plugins = append(plugins, "tool-git")
+		}
+	case types.ActionTypePlugin:
+		if pluginName, ok := params["plugin_name"].(string); ok {
+			plugins = append(plugins, pluginName)
+		}
+	}
+	return plugins
+}
+
 // executeInstall executes an installation action
 func (ae *ActionExecutor) executeInstall(ctx context.Context, action *types.StepAction, state *types.SetupState) error {
 	// Extract parameters from action
@@ -148,7 +193,7 @@ func (ae *ActionExecutor) executeCommand(ctx context.Context, action *types.Step
 	cmd := exec.CommandContext(ctx, "sh", "-c", cmdStr)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	return audit.Run(audit.WithTrigger(ctx, "", "setup"), cmd)
 }
 
 // installLanguages installs programming languages via mise plugin
@@ -279,7 +324,7 @@ func (ae *ActionExecutor) executePluginWithSetup(
 
 	// Check if plugin is installed
 	plugins := ae.pluginBootstrap.GetManager().ListPluginsWithContext(ctx)
-	pluginInfo, exists := plugins[pluginName]
+	_, exists := plugins[pluginName]
 	if !exists {
 		// Try to download the plugin
 		log.Info("Downloading plugin", "plugin", pluginName)
@@ -309,8 +354,14 @@ func (ae *ActionExecutor) executePluginWithSetup(
 		return fmt.Errorf("failed to marshal plugin input: %w", err)
 	}
 
-	// Execute plugin
-	cmd := exec.CommandContext(ctx, pluginInfo.Path, command)
+	// Execute plugin, confined to the permissions granted to it
+	if err := ae.pluginBootstrap.EnsurePluginPermissions(pluginName); err != nil {
+		return err
+	}
+	cmd, err := ae.pluginBootstrap.GetManager().PluginCommand(ctx, pluginName, command)
+	if err != nil {
+		return err
+	}
 	cmd.Stdin = bytes.NewReader(inputJSON)
 
 	// Capture output
@@ -320,7 +371,8 @@ func (ae *ActionExecutor) executePluginWithSetup(
 
 	log.Info("Executing plugin", "plugin", pluginName, "command", command)
 
-	if err := cmd.Run(); err != nil {
+	if err := audit.Run(audit.WithTrigger(ctx, "", "setup"), cmd); err != nil {
+		err = sdk.PluginExitError(pluginName, err)
 		return fmt.Errorf("plugin execution failed: %w\nStderr: %s", err, stderr.String())
 	}
 
@@ -0,0 +1,51 @@
+package setup_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/commands/setup"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+var _ = Describe("RequiredPlugins", func() {
+	It("lists the plugins run by option sources and actions", func() {
+		setupConfig := &types.SetupConfig{
+			Steps: []types.SetupStep{
+				{ID: "welcome", Type: types.StepTypeInfo, Info: &types.InfoContent{Message: "Hello"}},
+				{ID: "node", Type: types.StepTypeQuestion, Question: &types.Question{
+					Type:          types.QuestionTypeSelect,
+					Variable:      "node",
+					OptionsSource: &types.OptionsSource{Type: types.SourceTypePlugin, Plugin: "tool-mise", Key: "versions"},
+				}},
+				{ID: "languages", Type: types.StepTypeQuestion, Question: &types.Question{
+					Type:          types.QuestionTypeMultiSelect,
+					Variable:      "languages",
+					OptionsSource: &types.OptionsSource{Type: types.SourceTypeConfig, Path: "languages.yaml"},
+				}},
+				{ID: "install", Type: types.StepTypeAction, Action: &types.StepAction{
+					Type:   types.ActionTypeInstall,
+					Params: map[string]interface{}{"install_databases": "{{.databases}}"},
+				}},
+				{ID: "git", Type: types.StepTypeAction, Action: &types.StepAction{
+					Type:   types.ActionTypeConfigure,
+					Params: map[string]interface{}{"configure_git": map[string]interface{}{"name": "{{.name}}"}},
+				}},
+				{ID: "theme", Type: types.StepTypeAction, Action: &types.StepAction{
+					Type:   types.ActionTypePlugin,
+					Params: map[string]interface{}{"plugin_name": "desktop-gnome"},
+				}},
+				{ID: "script", Type: types.StepTypeAction, Action: &types.StepAction{
+					Type:   types.ActionTypeExecute,
+					Params: map[string]interface{}{"command": "true"},
+				}},
+			},
+		}
+
+		Expect(setup.RequiredPlugins(setupConfig)).To(Equal([]string{"tool-mise", "docker", "tool-git", "desktop-gnome"}))
+	})
+
+	It("requires no plugins without a configuration", func() {
+		Expect(setup.RequiredPlugins(nil)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,323 @@
+package setup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+// AnswersFile holds the answers for an unattended setup run, keyed by Question.Variable
+type AnswersFile struct {
+	// Setup names the setup configuration the answers were written for (informational)
+	Setup      string                 `yaml:"setup,omitempty"`
+	RecordedAt time.Time              `yaml:"recorded_at,omitempty"`
+	Answers    map[string]interface{} `yaml:"answers"`
+}
+
+// AnswerErrors collects every problem found in an answers file
+type AnswerErrors []string
+
+func (e AnswerErrors) Error() string {
+	if len(e) == 1 {
+		return "invalid setup answers: " + e[0]
+	}
+	return "invalid setup answers:\n  - " + strings.Join(e, "\n  - ")
+}
+
+// LoadAnswers reads an answers file
+func LoadAnswers(path string) (*AnswersFile, error) {
+	data, err := os.ReadFile(expandHome(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read answers file: %w", err)
+	}
+
+	var answers AnswersFile
+	if err := yaml.Unmarshal(data, &answers); err != nil {
+		return nil, fmt.Errorf("failed to parse answers file: %w", err)
+	}
+	if answers.Answers == nil {
+		answers.Answers = make(map[string]interface{})
+	}
+	return &answers, nil
+}
+
+// WriteAnswers records the answers given to setupConfig so the same setup can be replayed
+// with 'devex setup --answers'
+func WriteAnswers(path string, setupConfig *types.SetupConfig, answers map[string]interface{}) error {
+	file := AnswersFile{
+		Setup:      setupConfig.Metadata.Name,
+		RecordedAt: time.Now().UTC().Truncate(time.Second),
+		Answers:    answers,
+	}
+	data, err := yaml.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to encode answers: %w", err)
+	}
+
+	path = expandHome(path)
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return fmt.Errorf("failed to create answers directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write answers file: %w", err)
+	}
+	return nil
+}
+
+// expandHome expands a leading ~ to the user's home directory
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
+
+// ApplyAnswers walks the workflow without running any action and answers every question that
+// is shown, following ShowIf conditions as the answers are set. Answers are checked against the
+// question's options and validation rules; unknown variables and missing required answers are
+// errors. All problems are returned together as AnswerErrors.
+func (e *SetupExecutor) ApplyAnswers(answers map[string]interface{}) error {
+	var problems AnswerErrors
+
+	known := make(map[string]bool)
+	for _, step := range e.config.Steps {
+		if step.Question != nil {
+			known[step.Question.Variable] = true
+		}
+	}
+	var unknown []string
+	for variable := range answers {
+		if !known[variable] {
+			unknown = append(unknown, variable)
+		}
+	}
+	sort.Strings(unknown)
+	for _, variable := range unknown {
+		problems = append(problems, fmt.Sprintf("%s: no question uses this variable", variable))
+	}
+
+	err := e.walk(func(step *types.SetupStep) error {
+		if step.Type != types.StepTypeQuestion || step.Question == nil {
+			return nil
+		}
+		raw, present := answers[step.Question.Variable]
+		value, set, err := e.resolveAnswer(step.Question, raw, present)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", step.Question.Variable, err))
+			return nil
+		}
+		if set {
+			e.SetAnswer(step.Question.Variable, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+// walk visits every step the workflow shows from the first step to the end, following
+// navigation overrides. Each step may be visited only once, which rules out loops that an
+// interactive user would break by going back.
+func (e *SetupExecutor) walk(visit func(step *types.SetupStep) error) error {
+	e.state.CurrentStep = 0
+	visited := make(map[int]bool)
+
+	for !e.IsComplete() {
+		step := e.GetCurrentStep()
+		if step.ShowIf != nil {
+			shouldShow, err := e.conditionEval.Evaluate(step.ShowIf)
+			if err != nil {
+				return fmt.Errorf("failed to evaluate condition for step %s: %w", step.ID, err)
+			}
+			if !shouldShow {
+				e.state.CurrentStep++
+				continue
+			}
+		}
+
+		if visited[e.state.CurrentStep] {
+			return fmt.Errorf("setup workflow returns to step %s and cannot run unattended", step.ID)
+		}
+		visited[e.state.CurrentStep] = true
+
+		if err := visit(step); err != nil {
+			return err
+		}
+		if err := e.NextStep(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveAnswer converts a raw answer to the value the interactive model would store for the
+// question. set is false when the question is left unanswered.
+func (e *SetupExecutor) resolveAnswer(question *types.Question, raw interface{}, present bool) (value interface{}, set bool, err error) {
+	var options []types.QuestionOption
+	if question.Type == types.QuestionTypeSelect || question.Type == types.QuestionTypeMultiSelect {
+		if options, err = e.LoadOptions(question); err != nil {
+			return nil, false, fmt.Errorf("failed to load options: %w", err)
+		}
+	}
+
+	if !present || raw == nil {
+		raw, present = defaultAnswer(question, options)
+		if !present {
+			if question.Validation != nil && question.Validation.Required {
+				return nil, false, fmt.Errorf("missing required answer for %q", question.Prompt)
+			}
+			return nil, false, nil
+		}
+	}
+
+	switch question.Type {
+	case types.QuestionTypeSelect:
+		choice, ok := scalarString(raw)
+		if !ok {
+			return nil, false, fmt.Errorf("expected a single value, got %T", raw)
+		}
+		if err := checkOption(choice, options); err != nil {
+			return nil, false, err
+		}
+		value = choice
+
+	case types.QuestionTypeMultiSelect:
+		var choices []string
+		switch v := raw.(type) {
+		case []interface{}:
+			for _, item := range v {
+				choice, ok := scalarString(item)
+				if !ok {
+					return nil, false, fmt.Errorf("expected a list of values, got %T in the list", item)
+				}
+				choices = append(choices, choice)
+			}
+		case []string:
+			choices = v
+		default:
+			choice, ok := scalarString(raw)
+			if !ok {
+				return nil, false, fmt.Errorf("expected a list of values, got %T", raw)
+			}
+			choices = []string{choice}
+		}
+		for _, choice := range choices {
+			if err := checkOption(choice, options); err != nil {
+				return nil, false, err
+			}
+		}
+		if choices == nil {
+			choices = []string{}
+		}
+		value = choices
+
+	case types.QuestionTypeBool:
+		switch v := raw.(type) {
+		case bool:
+			value = v
+		case string:
+			parsed, err := parseBoolAnswer(v)
+			if err != nil {
+				return nil, false, err
+			}
+			value = parsed
+		default:
+			return nil, false, fmt.Errorf("expected true or false, got %T", raw)
+		}
+
+	default:
+		text, ok := scalarString(raw)
+		if !ok {
+			return nil, false, fmt.Errorf("expected a text value, got %T", raw)
+		}
+		value = text
+	}
+
+	if err := e.ValidateAnswer(question, value); err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// defaultAnswer returns the question default, falling back to the options marked as default
+func defaultAnswer(question *types.Question, options []types.QuestionOption) (interface{}, bool) {
+	if question.Default != nil {
+		return question.Default, true
+	}
+
+	var defaults []interface{}
+	for _, option := range options {
+		if option.Default {
+			defaults = append(defaults, option.Value)
+		}
+	}
+	switch {
+	case len(defaults) == 0:
+		return nil, false
+	case question.Type == types.QuestionTypeMultiSelect:
+		return defaults, true
+	default:
+		return defaults[0], true
+	}
+}
+
+// checkOption verifies that choice is the value of one of the options shown to the user.
+// Questions whose options cannot be listed accept any value.
+func checkOption(choice string, options []types.QuestionOption) error {
+	if len(options) == 0 {
+		return nil
+	}
+	values := make([]string, 0, len(options))
+	for _, option := range options {
+		if option.Value == choice {
+			return nil
+		}
+		values = append(values, option.Value)
+	}
+	return fmt.Errorf("%q is not one of the available options (%s)", choice, strings.Join(values, ", "))
+}
+
+// scalarString formats a YAML scalar as a string
+func scalarString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case bool, int, int64, float64:
+		return fmt.Sprintf("%v", v), true
+	default:
+		return "", false
+	}
+}
+
+// parseBoolAnswer accepts the spellings of yes and no used in answers files
+func parseBoolAnswer(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "y", "on":
+		return true, nil
+	case "no", "n", "off":
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("expected yes or no, got %q", value)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,235 @@
+package setup_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/commands/setup"
+	"github.com/jameswlane/devex/apps/cli/internal/config"
+	"github.com/jameswlane/devex/apps/cli/internal/platform"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+var _ = Describe("Setup answers", func() {
+	var (
+		setupConfig      *types.SetupConfig
+		detectedPlatform platform.DetectionResult
+		executor         *setup.SetupExecutor
+	)
+
+	BeforeEach(func() {
+		hasDesktop := true
+		setupConfig = &types.SetupConfig{
+			Metadata: types.SetupMetadata{Name: "Answers Test"},
+			Steps: []types.SetupStep{
+				{
+					ID:   "welcome",
+					Type: types.StepTypeInfo,
+					Info: &types.InfoContent{Message: "Welcome"},
+				},
+				{
+					ID:    "desktop_apps",
+					Title: "Desktop Applications",
+					Type:  types.StepTypeQuestion,
+					ShowIf: &types.Condition{
+						System: &types.SystemCondition{HasDesktop: &hasDesktop},
+					},
+					Question: &types.Question{
+						Type:     types.QuestionTypeMultiSelect,
+						Variable: "desktop_apps",
+						Options: []types.QuestionOption{
+							{Label: "Firefox", Value: "firefox"},
+							{Label: "VS Code", Value: "vscode", Default: true},
+						},
+					},
+				},
+				{
+					ID:    "shell",
+					Title: "Shell",
+					Type:  types.StepTypeQuestion,
+					Question: &types.Question{
+						Type:     types.QuestionTypeSelect,
+						Variable: "shell",
+						Options: []types.QuestionOption{
+							{Label: "zsh", Value: "zsh"},
+							{Label: "bash", Value: "bash"},
+						},
+						Validation: &types.Validation{Required: true},
+					},
+				},
+				{
+					ID:    "configure_git",
+					Title: "Configure Git",
+					Type:  types.StepTypeQuestion,
+					Question: &types.Question{
+						Type:     types.QuestionTypeBool,
+						Variable: "configure_git",
+						Default:  false,
+					},
+				},
+				{
+					ID:    "git_email",
+					Title: "Git Email",
+					Type:  types.StepTypeQuestion,
+					ShowIf: &types.Condition{
+						Variable: "configure_git",
+						Operator: types.OperatorEquals,
+						Value:    true,
+					},
+					Question: &types.Question{
+						Type:     types.QuestionTypeText,
+						Variable: "git_email",
+						Prompt:   "Git email",
+						Validation: &types.Validation{
+							Required: true,
+							Pattern:  `^[^@]+@[^@]+$`,
+							Message:  "enter a valid email address",
+						},
+					},
+				},
+			},
+		}
+		detectedPlatform = platform.DetectionResult{OS: "linux", Distribution: "ubuntu", DesktopEnv: "gnome"}
+		executor = setup.NewSetupExecutor(setupConfig, config.CrossPlatformSettings{}, nil, detectedPlatform)
+	})
+
+	Describe("ApplyAnswers", func() {
+		It("stores answers the way the interactive setup does", func() {
+			err := executor.ApplyAnswers(map[string]interface{}{
+				"desktop_apps":  []interface{}{"firefox"},
+				"shell":         "bash",
+				"configure_git": "yes",
+				"git_email":     "dev@example.com",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			answers := executor.GetState().Answers
+			Expect(answers["desktop_apps"]).To(Equal([]string{"firefox"}))
+			Expect(answers["shell"]).To(Equal("bash"))
+			Expect(answers["configure_git"]).To(BeTrue())
+			Expect(answers["git_email"]).To(Equal("dev@example.com"))
+		})
+
+		It("falls back to question and option defaults", func() {
+			Expect(executor.ApplyAnswers(map[string]interface{}{"shell": "zsh"})).To(Succeed())
+
+			answers := executor.GetState().Answers
+			Expect(answers["desktop_apps"]).To(Equal([]string{"vscode"}))
+			Expect(answers["configure_git"]).To(BeFalse())
+			Expect(answers).NotTo(HaveKey("git_email"))
+		})
+
+		It("fails on missing required answers", func() {
+			err := executor.ApplyAnswers(map[string]interface{}{})
+			Expect(err).To(MatchError(ContainSubstring("shell: missing required answer")))
+		})
+
+		It("requires answers for questions enabled by earlier answers", func() {
+			err := executor.ApplyAnswers(map[string]interface{}{"shell": "zsh", "configure_git": true})
+			Expect(err).To(MatchError(ContainSubstring("git_email: missing required answer")))
+		})
+
+		It("skips questions hidden by ShowIf", func() {
+			detectedPlatform.DesktopEnv = "none"
+			executor = setup.NewSetupExecutor(setupConfig, config.CrossPlatformSettings{}, nil, detectedPlatform)
+
+			err := executor.ApplyAnswers(map[string]interface{}{"shell": "zsh", "desktop_apps": []interface{}{"firefox"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(executor.GetState().Answers).NotTo(HaveKey("desktop_apps"))
+		})
+
+		It("reports every invalid answer at once", func() {
+			err := executor.ApplyAnswers(map[string]interface{}{
+				"desktop_apps":  []interface{}{"emacs"},
+				"shell":         "tcsh",
+				"configure_git": "maybe",
+				"editor":        "vim",
+			})
+
+			var problems setup.AnswerErrors
+			Expect(err).To(BeAssignableToTypeOf(problems))
+			problems = err.(setup.AnswerErrors)
+			Expect(problems).To(ConsistOf(
+				ContainSubstring("editor: no question uses this variable"),
+				ContainSubstring(`desktop_apps: "emacs" is not one of the available options`),
+				ContainSubstring(`shell: "tcsh" is not one of the available options (zsh, bash)`),
+				ContainSubstring(`configure_git: expected yes or no`),
+			))
+		})
+
+		It("applies validation rules", func() {
+			err := executor.ApplyAnswers(map[string]interface{}{
+				"shell":         "zsh",
+				"configure_git": true,
+				"git_email":     "not-an-email",
+			})
+			Expect(err).To(MatchError(ContainSubstring("git_email: enter a valid email address")))
+		})
+	})
+
+	Describe("answers files", func() {
+		It("round-trips recorded answers", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "nested", "answers.yaml")
+			recorded := map[string]interface{}{
+				"desktop_apps":  []string{"firefox", "vscode"},
+				"shell":         "zsh",
+				"configure_git": false,
+			}
+			Expect(setup.WriteAnswers(path, setupConfig, recorded)).To(Succeed())
+
+			answers, err := setup.LoadAnswers(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(answers.Setup).To(Equal("Answers Test"))
+			Expect(executor.ApplyAnswers(answers.Answers)).To(Succeed())
+			Expect(executor.GetState().Answers["desktop_apps"]).To(Equal([]string{"firefox", "vscode"}))
+		})
+
+		It("rejects malformed files", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "answers.yaml")
+			Expect(os.WriteFile(path, []byte("answers: [unclosed"), 0600)).To(Succeed())
+
+			_, err := setup.LoadAnswers(path)
+			Expect(err).To(MatchError(ContainSubstring("failed to parse answers file")))
+		})
+	})
+
+	Describe("RunUnattendedSetup", func() {
+		It("fails before running any action when answers are invalid", func() {
+			marker := filepath.Join(GinkgoT().TempDir(), "ran")
+			setupConfig.Steps = append([]types.SetupStep{{
+				ID:   "touch",
+				Type: types.StepTypeAction,
+				Action: &types.StepAction{
+					Type:   types.ActionTypeExecute,
+					Params: map[string]interface{}{"command": "touch " + marker},
+				},
+			}}, setupConfig.Steps...)
+
+			err := setup.RunUnattendedSetup(context.Background(), setupConfig, &setup.AnswersFile{Answers: map[string]interface{}{}},
+				nil, config.CrossPlatformSettings{}, detectedPlatform, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(marker).NotTo(BeAnExistingFile())
+		})
+
+		It("runs actions once the answers are valid", func() {
+			marker := filepath.Join(GinkgoT().TempDir(), "ran")
+			setupConfig.Steps = append(setupConfig.Steps, types.SetupStep{
+				ID:   "touch",
+				Type: types.StepTypeAction,
+				Action: &types.StepAction{
+					Type:   types.ActionTypeExecute,
+					Params: map[string]interface{}{"command": "touch " + marker},
+				},
+			})
+
+			err := setup.RunUnattendedSetup(context.Background(), setupConfig, &setup.AnswersFile{Answers: map[string]interface{}{"shell": "zsh"}},
+				nil, config.CrossPlatformSettings{}, detectedPlatform, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(marker).To(BeAnExistingFile())
+		})
+	})
+})
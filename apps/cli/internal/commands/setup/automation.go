@@ -6,9 +6,11 @@ import (
 	"os"
 	"strings"
 
+	"github.com/jameswlane/devex/apps/cli/internal/bootstrap"
 	"github.com/jameswlane/devex/apps/cli/internal/config"
 	"github.com/jameswlane/devex/apps/cli/internal/installers"
 	"github.com/jameswlane/devex/apps/cli/internal/log"
+	"github.com/jameswlane/devex/apps/cli/internal/platform"
 	"github.com/jameswlane/devex/apps/cli/internal/types"
 	"github.com/spf13/viper"
 )
@@ -185,3 +187,108 @@ func RunAutomatedSetup(ctx context.Context, repo types.Repository, settings conf
 
 	return nil
 }
+
+// RunUnattendedSetup runs the setup workflow with answers taken from an answers file. Every
+// answer is validated before the first action runs, so a run with missing or invalid answers
+// fails without changing the system.
+func RunUnattendedSetup(
+	ctx context.Context,
+	setupConfig *types.SetupConfig,
+	answers *AnswersFile,
+	repo types.Repository,
+	settings config.CrossPlatformSettings,
+	detectedPlatform platform.DetectionResult,
+	pluginBootstrap *bootstrap.PluginBootstrap,
+) error {
+	log.Info("Running unattended setup", "setup", setupConfig.Metadata.Name, "answers", len(answers.Answers))
+	if answers.Setup != "" && answers.Setup != setupConfig.Metadata.Name {
+		log.Warn("Answers were recorded for a different setup configuration", "recorded", answers.Setup, "current", setupConfig.Metadata.Name)
+	}
+
+	executor := NewSetupExecutor(setupConfig, settings, repo, detectedPlatform)
+	if pluginBootstrap != nil {
+		executor.SetPluginCaller(pluginBootstrap)
+	}
+	if err := executor.ApplyAnswers(answers.Answers); err != nil {
+		return err
+	}
+
+	fmt.Printf("🚀 Starting unattended DevEx setup: %s\n", setupConfig.Metadata.Name)
+
+	actionExecutor := NewActionExecutor(pluginBootstrap, settings, detectedPlatform)
+	var actionErrors []string
+	err := executor.walk(func(step *types.SetupStep) error {
+		switch step.Type {
+		case types.StepTypeQuestion:
+			if step.Question == nil {
+				return nil
+			}
+			if value, ok := executor.GetAnswer(step.Question.Variable); ok {
+				fmt.Printf("  • %s: %s\n", step.Title, formatAnswer(value))
+			}
+
+		case types.StepTypeInfo:
+			if step.Info != nil {
+				message, _ := executor.InterpolateString(step.Info.Message)
+				log.Info("Setup step", "step", step.ID, "message", strings.TrimSpace(message))
+			}
+
+		case types.StepTypeAction:
+			if step.Action == nil {
+				return nil
+			}
+			progress := step.Action.ProgressMessage
+			if progress == "" {
+				progress = step.Title
+			}
+			fmt.Printf("⏳ %s\n", progress)
+
+			if err := actionExecutor.Execute(ctx, step.Action, executor.GetState()); err != nil {
+				if step.Action.OnError == types.ErrorBehaviorContinue || step.Action.OnError == types.ErrorBehaviorSkip {
+					log.Warn("Setup action failed, continuing", "step", step.ID, "error", err)
+					fmt.Printf("⚠️  %s: %v\n", step.Title, err)
+					actionErrors = append(actionErrors, fmt.Sprintf("%s: %v", step.ID, err))
+					return nil
+				}
+				return fmt.Errorf("setup step %s failed: %w", step.ID, err)
+			}
+			if step.Action.SuccessMessage != "" {
+				fmt.Printf("✅ %s\n", step.Action.SuccessMessage)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("Unattended setup failed", err)
+		return err
+	}
+
+	if len(actionErrors) > 0 {
+		fmt.Printf("\n⚠️  Unattended setup completed with %d error(s)\n", len(actionErrors))
+		return fmt.Errorf("unattended setup completed with errors: %s", strings.Join(actionErrors, "; "))
+	}
+
+	fmt.Printf("\n🎉 Unattended setup complete!\n")
+	if logFile := log.GetLogFile(); logFile != "" {
+		fmt.Printf("\n📋 Installation logs: %s\n", logFile)
+	}
+	return nil
+}
+
+// formatAnswer renders an answer for the unattended setup summary
+func formatAnswer(value interface{}) string {
+	switch v := value.(type) {
+	case []string:
+		if len(v) == 0 {
+			return "(none)"
+		}
+		return strings.Join(v, ", ")
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -198,7 +198,16 @@ func (ce *ConditionEvaluator) compareValues(a, b interface{}, expectEqual bool)
 
 // containsValue checks if a value contains another value
 func (ce *ConditionEvaluator) containsValue(haystack, needle interface{}) bool {
-	// Handle slice/array types
+	// Handle slice/array types; multi-select answers are stored as []string
+	if selected, ok := haystack.([]string); ok {
+		needleStr := fmt.Sprintf("%v", needle)
+		for _, item := range selected {
+			if item == needleStr {
+				return true
+			}
+		}
+		return false
+	}
 	if slice, ok := haystack.([]interface{}); ok {
 		needleStr := fmt.Sprintf("%v", needle)
 		for _, item := range slice {
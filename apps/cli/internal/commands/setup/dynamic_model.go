@@ -28,6 +28,16 @@ type DynamicSetupModel struct {
 	executing       bool
 	err             error
 	quitting        bool
+
+	// Options of the current question, loaded in the background when the step is entered
+	options        []types.QuestionOption
+	optionsErr     error
+	loadingOptions bool
+	optionsLoad    int // identifies the latest load so results for a step left meanwhile are dropped
+
+	// Steps before this index do not replace the saved progress of an interrupted earlier run,
+	// so starting over without --resume keeps it until this run gets as far
+	saveFrom int
 }
 
 // ActionCompleteMsg is sent when an action completes
@@ -35,6 +45,13 @@ type ActionCompleteMsg struct {
 	err error
 }
 
+// OptionsLoadedMsg is sent when the options of a question step have been loaded
+type OptionsLoadedMsg struct {
+	load    int
+	options []types.QuestionOption
+	err     error
+}
+
 // NewDynamicSetupModel creates a new dynamic setup model
 func NewDynamicSetupModel(
 	setupConfig *types.SetupConfig,
@@ -44,6 +61,9 @@ func NewDynamicSetupModel(
 	pluginBootstrap *bootstrap.PluginBootstrap,
 ) *DynamicSetupModel {
 	executor := NewSetupExecutor(setupConfig, settings, repo, detectedPlatform)
+	if pluginBootstrap != nil {
+		executor.SetPluginCaller(pluginBootstrap)
+	}
 	actionExecutor := NewActionExecutor(pluginBootstrap, settings, detectedPlatform)
 
 	ti := textinput.New()
@@ -54,18 +74,33 @@ func NewDynamicSetupModel(
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("69"))
 
+	saveFrom := 1
+	if progress, err := LoadProgress(repo); err == nil && progress != nil && progress.Setup == setupConfig.Metadata.Name {
+		saveFrom = max(saveFrom, executor.stepIndex(progress.Step))
+	}
+
 	return &DynamicSetupModel{
 		executor:       executor,
 		actionExecutor: actionExecutor,
 		textInput:      ti,
 		selected:       make(map[int]bool),
 		spinner:        s,
+		saveFrom:       saveFrom,
 	}
 }
 
+// Resume continues an interrupted setup from its saved progress, see LoadProgress
+func (m *DynamicSetupModel) Resume(progress *SetupProgress) error {
+	if err := m.executor.Restore(progress); err != nil {
+		return err
+	}
+	m.saveFrom = 0
+	return nil
+}
+
 // Init initializes the model
 func (m *DynamicSetupModel) Init() tea.Cmd {
-	return textinput.Blink
+	return tea.Batch(textinput.Blink, m.enterStep())
 }
 
 // Update handles messages
@@ -87,13 +122,26 @@ func (m *DynamicSetupModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		// Check if we've reached the end
 		if m.executor.IsComplete() {
-			m.quitting = true
-			return m, tea.Quit
+			return m, m.complete()
+		}
+		return m, m.enterStep()
+
+	case OptionsLoadedMsg:
+		if msg.load != m.optionsLoad {
+			return m, nil
+		}
+		m.loadingOptions = false
+		if msg.err != nil {
+			m.optionsErr = msg.err
+			return m, nil
+		}
+		if step := m.executor.GetCurrentStep(); step != nil && step.Question != nil {
+			m.options = m.executor.MergeOptions(step.Question, msg.options)
 		}
 		return m, nil
 
 	case spinner.TickMsg:
-		if m.executing {
+		if m.executing || m.loadingOptions {
 			var cmd tea.Cmd
 			m.spinner, cmd = m.spinner.Update(msg)
 			return m, cmd
@@ -137,6 +185,7 @@ func (m *DynamicSetupModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 				m.validationError = err.Error()
 			} else {
 				m.resetCursor()
+				return m, m.enterStep()
 			}
 		}
 
@@ -154,11 +203,14 @@ func (m *DynamicSetupModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 	case "down", "j":
 		if step.Type == types.StepTypeQuestion && !m.executing {
 			if step.Question != nil {
-				maxOptions := len(step.Question.Options)
+				maxOptions := m.optionCount(step.Question)
 				m.cursor++
 				if m.cursor >= maxOptions {
 					m.cursor = maxOptions - 1
 				}
+				if m.cursor < 0 {
+					m.cursor = 0
+				}
 			}
 		}
 
@@ -189,12 +241,14 @@ func (m *DynamicSetupModel) handleEnter(step *types.SetupStep) (tea.Model, tea.C
 		}
 		// Check if we've reached the end
 		if m.executor.IsComplete() {
-			m.quitting = true
-			return m, tea.Quit
+			return m, m.complete()
 		}
-		return m, nil
+		return m, m.enterStep()
 
 	case types.StepTypeQuestion:
+		if m.loadingOptions {
+			return m, nil
+		}
 		return m.handleQuestionSubmit(step)
 
 	case types.StepTypeAction:
@@ -225,14 +279,14 @@ func (m *DynamicSetupModel) handleQuestionSubmit(step *types.SetupStep) (tea.Mod
 
 	case types.QuestionTypeSelect:
 		// Get selected option
-		if m.cursor >= 0 && m.cursor < len(step.Question.Options) {
-			answer = step.Question.Options[m.cursor].Value
+		if m.cursor >= 0 && m.cursor < len(m.options) {
+			answer = m.options[m.cursor].Value
 		}
 
 	case types.QuestionTypeMultiSelect:
 		// Get all selected options
 		var selected []string
-		for i, opt := range step.Question.Options {
+		for i, opt := range m.options {
 			if m.selected[i] {
 				selected = append(selected, opt.Value)
 			}
@@ -267,11 +321,10 @@ func (m *DynamicSetupModel) handleQuestionSubmit(step *types.SetupStep) (tea.Mod
 
 	// Check if we've reached the end
 	if m.executor.IsComplete() {
-		m.quitting = true
-		return m, tea.Quit
+		return m, m.complete()
 	}
 
-	return m, nil
+	return m, m.enterStep()
 }
 
 // executeAction executes an action asynchronously
@@ -283,6 +336,56 @@ func (m *DynamicSetupModel) executeAction(step *types.SetupStep) tea.Cmd {
 	}
 }
 
+// enterStep prepares the current step after navigating to it. The options of a question are
+// loaded by a command rather than in View, since a plugin options source may have to download
+// the plugin first; the step shows a loading state until OptionsLoadedMsg arrives.
+func (m *DynamicSetupModel) enterStep() tea.Cmd {
+	if state := m.executor.GetState(); state.CurrentStep >= m.saveFrom {
+		saveProgress(m.executor.repo, m.executor.config, state)
+		m.saveFrom = 0
+	}
+
+	m.optionsLoad++
+	m.options, m.optionsErr, m.loadingOptions = nil, nil, false
+
+	step := m.executor.GetCurrentStep()
+	if step == nil || step.Type != types.StepTypeQuestion || step.Question == nil {
+		return nil
+	}
+
+	load, err := m.executor.PrepareOptionsLoad(step.Question)
+	if err != nil {
+		m.optionsErr = err
+		return nil
+	}
+	if load == nil {
+		m.options = m.executor.MergeOptions(step.Question, nil)
+		return nil
+	}
+
+	m.loadingOptions = true
+	id := m.optionsLoad
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		options, err := load()
+		return OptionsLoadedMsg{load: id, options: options, err: err}
+	})
+}
+
+// complete ends a setup that went through every step
+func (m *DynamicSetupModel) complete() tea.Cmd {
+	clearProgress(m.executor.repo)
+	m.quitting = true
+	return tea.Quit
+}
+
+// optionCount returns the number of choices the cursor can move through
+func (m *DynamicSetupModel) optionCount(question *types.Question) int {
+	if question.Type == types.QuestionTypeBool {
+		return 2
+	}
+	return len(m.options)
+}
+
 // resetCursor resets cursor and selection state
 func (m *DynamicSetupModel) resetCursor() {
 	m.cursor = 0
@@ -382,20 +485,21 @@ func (m *DynamicSetupModel) renderQuestionStep(step *types.SetupStep) string {
 
 	var s strings.Builder
 
-	// Load options if needed
-	options := step.Question.Options
-	if step.Question.OptionsSource != nil {
-		loadedOptions, err := m.executor.LoadOptions(step.Question)
-		if err != nil {
-			return fmt.Sprintf("Error loading options: %v", err)
-		}
-		options = loadedOptions
+	// Options, including those from the options source, are loaded when the step is entered
+	if m.optionsErr != nil {
+		return fmt.Sprintf("Error loading options: %v", m.optionsErr)
 	}
+	options := m.options
 
 	// Render prompt
 	promptStyle := lipgloss.NewStyle().Bold(true)
 	s.WriteString(promptStyle.Render(step.Question.Prompt) + "\n\n")
 
+	if m.loadingOptions {
+		s.WriteString(m.spinner.View() + " Loading options...")
+		return s.String()
+	}
+
 	// Render based on question type
 	switch step.Question.Type {
 	case types.QuestionTypeText:
@@ -508,3 +612,13 @@ func (m *DynamicSetupModel) renderNavigationHints(step *types.SetupStep) string
 func (m *DynamicSetupModel) HasErrors() bool {
 	return m.err != nil
 }
+
+// Completed reports whether the user went through every step of the workflow
+func (m *DynamicSetupModel) Completed() bool {
+	return m.executor.IsComplete()
+}
+
+// Answers returns the answers given so far, keyed by question variable
+func (m *DynamicSetupModel) Answers() map[string]interface{} {
+	return m.executor.GetState().Answers
+}
@@ -0,0 +1,168 @@
+package setup_test
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/commands/setup"
+	"github.com/jameswlane/devex/apps/cli/internal/config"
+	"github.com/jameswlane/devex/apps/cli/internal/mocks"
+	"github.com/jameswlane/devex/apps/cli/internal/platform"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+// collectMsgs runs a command and the commands it batches, returning the messages they produce
+func collectMsgs(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		var msgs []tea.Msg
+		for _, c := range batch {
+			msgs = append(msgs, collectMsgs(c)...)
+		}
+		return msgs
+	}
+	return []tea.Msg{msg}
+}
+
+var _ = Describe("DynamicSetupModel", func() {
+	var setupConfig *types.SetupConfig
+
+	BeforeEach(func() {
+		setupConfig = &types.SetupConfig{
+			Steps: []types.SetupStep{
+				{
+					ID:    "theme",
+					Title: "Theme",
+					Type:  types.StepTypeQuestion,
+					Question: &types.Question{
+						Type:     types.QuestionTypeSelect,
+						Variable: "theme",
+						Prompt:   "Choose a theme:",
+						Options:  []types.QuestionOption{{Label: "Default", Value: "default"}},
+						OptionsSource: &types.OptionsSource{
+							Type:   types.SourceTypePlugin,
+							Plugin: "desktop-gnome",
+							Key:    "themes",
+						},
+					},
+				},
+			},
+		}
+	})
+
+	It("loads plugin options in a command and shows a loading state meanwhile", func() {
+		model := setup.NewDynamicSetupModel(setupConfig, nil, config.CrossPlatformSettings{}, platform.DetectionResult{OS: "linux"}, nil)
+
+		cmd := model.Init()
+		Expect(model.View()).To(ContainSubstring("Loading options..."))
+		Expect(model.View()).NotTo(ContainSubstring("Default"))
+
+		var loaded []tea.Msg
+		for _, msg := range collectMsgs(cmd) {
+			if _, ok := msg.(setup.OptionsLoadedMsg); ok {
+				loaded = append(loaded, msg)
+			}
+		}
+		Expect(loaded).To(HaveLen(1))
+
+		model.Update(loaded[0])
+		view := model.View()
+		Expect(view).NotTo(ContainSubstring("Loading options..."))
+		Expect(view).To(ContainSubstring("plugin system is not initialized"))
+	})
+
+	It("shows static options without loading", func() {
+		setupConfig.Steps[0].Question.OptionsSource = nil
+		model := setup.NewDynamicSetupModel(setupConfig, nil, config.CrossPlatformSettings{}, platform.DetectionResult{OS: "linux"}, nil)
+
+		model.Init()
+		Expect(model.View()).To(ContainSubstring("> Default"))
+	})
+})
+
+var _ = Describe("DynamicSetupModel progress", func() {
+	var (
+		setupConfig *types.SetupConfig
+		repo        *mocks.MockRepository
+	)
+
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	newModel := func() *setup.DynamicSetupModel {
+		model := setup.NewDynamicSetupModel(setupConfig, repo, config.CrossPlatformSettings{}, platform.DetectionResult{OS: "linux"}, nil)
+		model.Init()
+		return model
+	}
+
+	BeforeEach(func() {
+		repo = mocks.NewMockRepository()
+		setupConfig = &types.SetupConfig{
+			Metadata: types.SetupMetadata{Name: "test"},
+			Steps: []types.SetupStep{
+				{ID: "welcome", Type: types.StepTypeInfo, Info: &types.InfoContent{Message: "Hello"}},
+				{ID: "languages", Type: types.StepTypeQuestion, Question: &types.Question{
+					Type:     types.QuestionTypeMultiSelect,
+					Variable: "languages",
+					Prompt:   "Languages?",
+					Options:  []types.QuestionOption{{Label: "Go", Value: "go"}},
+				}},
+				{ID: "shell", Type: types.StepTypeQuestion, Question: &types.Question{
+					Type:     types.QuestionTypeBool,
+					Variable: "zsh",
+					Prompt:   "Use zsh?",
+				}},
+			},
+		}
+	})
+
+	It("resumes an interrupted setup from its first incomplete step", func() {
+		model := newModel()
+		model.Update(enter)
+		model.Update(tea.KeyMsg{Type: tea.KeySpace})
+		model.Update(enter)
+		Expect(model.View()).To(ContainSubstring("Use zsh?"))
+
+		progress, err := setup.LoadProgress(repo)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress.Step).To(Equal("shell"))
+
+		resumed := setup.NewDynamicSetupModel(setupConfig, repo, config.CrossPlatformSettings{}, platform.DetectionResult{OS: "linux"}, nil)
+		Expect(resumed.Resume(progress)).To(Succeed())
+		resumed.Init()
+		Expect(resumed.View()).To(ContainSubstring("Use zsh?"))
+		Expect(resumed.Answers()).To(HaveKeyWithValue("languages", []string{"go"}))
+
+		_, cmd := resumed.Update(enter)
+		Expect(cmd).NotTo(BeNil())
+		Expect(resumed.Completed()).To(BeTrue())
+		Expect(setup.LoadProgress(repo)).To(BeNil())
+	})
+
+	It("keeps the progress of an interrupted setup until a new run gets as far", func() {
+		model := newModel()
+		model.Update(enter)
+		model.Update(enter)
+
+		restarted := newModel()
+		restarted.Update(enter)
+		progress, err := setup.LoadProgress(repo)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress.Step).To(Equal("shell"))
+
+		restarted.Update(tea.KeyMsg{Type: tea.KeySpace})
+		restarted.Update(enter)
+		progress, err = setup.LoadProgress(repo)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress.Step).To(Equal("shell"))
+		Expect(progress.Answers).To(HaveKeyWithValue("languages", ConsistOf("go")))
+	})
+
+	It("refuses progress saved for another setup", func() {
+		model := setup.NewDynamicSetupModel(setupConfig, repo, config.CrossPlatformSettings{}, platform.DetectionResult{OS: "linux"}, nil)
+		Expect(model.Resume(&setup.SetupProgress{Setup: "other", Step: "shell"})).To(MatchError(ContainSubstring(`for setup "other"`)))
+	})
+})
@@ -180,19 +180,89 @@ func (e *SetupExecutor) GetAnswer(variable string) (interface{}, bool) {
 	return value, ok
 }
 
-// LoadOptions loads options for a question
+// SetPluginCaller sets the plugin system used to load options from plugins
+func (e *SetupExecutor) SetPluginCaller(caller PluginCaller) {
+	e.optionsLoader.SetPluginCaller(caller)
+}
+
+// LoadOptions loads options for a question. Static options come first; options loaded from
+// the options source are appended unless a static option has the same value. Every option is
+// filtered through its ShowIf condition.
 func (e *SetupExecutor) LoadOptions(question *types.Question) ([]types.QuestionOption, error) {
-	// If static options are provided, use them
-	if len(question.Options) > 0 {
-		return e.filterOptions(question.Options), nil
+	load, err := e.PrepareOptionsLoad(question)
+	if err != nil {
+		return nil, err
+	}
+
+	var loaded []types.QuestionOption
+	if load != nil {
+		if loaded, err = load(); err != nil {
+			return nil, err
+		}
+	}
+	return e.MergeOptions(question, loaded), nil
+}
+
+// PrepareOptionsLoad resolves the options source of a question against the current answers and
+// returns a function querying it, or nil when the question has no options source. The function
+// does not touch the setup state, so it can run in the background while the UI keeps rendering.
+func (e *SetupExecutor) PrepareOptionsLoad(question *types.Question) (func() ([]types.QuestionOption, error), error) {
+	if question.OptionsSource == nil {
+		return nil, nil
+	}
+	source, err := e.interpolateSource(question.OptionsSource)
+	if err != nil {
+		return nil, err
+	}
+	return func() ([]types.QuestionOption, error) {
+		return e.optionsLoader.Load(source)
+	}, nil
+}
+
+// MergeOptions combines the static options of a question with the options loaded from its
+// options source and filters them through their ShowIf conditions
+func (e *SetupExecutor) MergeOptions(question *types.Question, loaded []types.QuestionOption) []types.QuestionOption {
+	options := question.Options
+	if len(options) == 0 {
+		options = loaded
+	} else if len(loaded) > 0 {
+		seen := make(map[string]bool, len(options))
+		for _, opt := range options {
+			seen[opt.Value] = true
+		}
+		merged := append([]types.QuestionOption{}, options...)
+		for _, opt := range loaded {
+			if !seen[opt.Value] {
+				merged = append(merged, opt)
+			}
+		}
+		options = merged
 	}
+	return e.filterOptions(options)
+}
 
-	// If options source is provided, load dynamically
-	if question.OptionsSource != nil {
-		return e.optionsLoader.Load(question.OptionsSource)
+// interpolateSource fills in the plugin, option set and arguments of a plugin options source
+// from earlier answers and system information, e.g. plugin: "desktop-{{ .desktop }}"
+func (e *SetupExecutor) interpolateSource(source *types.OptionsSource) (*types.OptionsSource, error) {
+	if source.Type != types.SourceTypePlugin {
+		return source, nil
 	}
 
-	return []types.QuestionOption{}, nil
+	interpolated := *source
+	var err error
+	if interpolated.Plugin, err = e.InterpolateString(source.Plugin); err != nil {
+		return nil, fmt.Errorf("invalid options_source.plugin: %w", err)
+	}
+	if interpolated.Key, err = e.InterpolateString(source.Key); err != nil {
+		return nil, fmt.Errorf("invalid options_source.key: %w", err)
+	}
+	interpolated.Args = make([]string, len(source.Args))
+	for i, arg := range source.Args {
+		if interpolated.Args[i], err = e.InterpolateString(arg); err != nil {
+			return nil, fmt.Errorf("invalid options_source.args: %w", err)
+		}
+	}
+	return &interpolated, nil
 }
 
 // filterOptions filters options based on ShowIf conditions
@@ -246,8 +316,21 @@ func (e *SetupExecutor) ValidateAnswer(question *types.Question, answer interfac
 
 	val := question.Validation
 
+	// Multi-select answers are stored as []string
+	if selected, ok := answer.([]string); ok {
+		items := make([]interface{}, len(selected))
+		for i, item := range selected {
+			items[i] = item
+		}
+		answer = items
+	}
+
 	// Check required
-	if val.Required && (answer == nil || answer == "") {
+	empty := answer == nil || answer == ""
+	if slice, ok := answer.([]interface{}); ok && len(slice) == 0 {
+		empty = true
+	}
+	if val.Required && empty {
 		if val.Message != "" {
 			return fmt.Errorf("%s", val.Message)
 		}
@@ -8,6 +8,7 @@ import (
 	"github.com/jameswlane/devex/apps/cli/internal/config"
 	"github.com/jameswlane/devex/apps/cli/internal/platform"
 	"github.com/jameswlane/devex/apps/cli/internal/types"
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
 )
 
 var _ = Describe("SetupExecutor", func() {
@@ -347,6 +348,68 @@ var _ = Describe("SetupExecutor", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(options).To(HaveLen(2))
 		})
+
+		It("should merge plugin options with static options and filter them", func() {
+			GinkgoT().Setenv("HOME", GinkgoT().TempDir())
+			caller := &fakePluginCaller{result: &sdk.RPCResult{Options: []sdk.OptionInfo{
+				{Value: "Adwaita"}, {Value: "Yaru", Label: "Yaru (plugin)"}, {Value: "HighContrast"},
+			}}}
+			executor.SetPluginCaller(caller)
+			hidden := false
+
+			question := &types.Question{
+				Options: []types.QuestionOption{
+					{Label: "Yaru", Value: "Yaru"},
+					{
+						Label:  "High Contrast",
+						Value:  "HighContrast",
+						ShowIf: &types.Condition{System: &types.SystemCondition{HasDesktop: &hidden}},
+					},
+				},
+				OptionsSource: &types.OptionsSource{
+					Type:   types.SourceTypePlugin,
+					Plugin: "desktop-{{ .desktop }}",
+					Key:    "themes",
+				},
+			}
+
+			options, err := executor.LoadOptions(question)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(options).To(Equal([]types.QuestionOption{
+				{Label: "Yaru", Value: "Yaru"},
+				{Label: "Adwaita", Value: "Adwaita"},
+			}))
+			Expect(caller.ensured).To(Equal([]string{"desktop-gnome"}))
+		})
+
+		It("should resolve the options source when the load is prepared", func() {
+			GinkgoT().Setenv("HOME", GinkgoT().TempDir())
+			caller := &fakePluginCaller{result: &sdk.RPCResult{Options: []sdk.OptionInfo{{Value: "22.11.0"}}}}
+			executor.SetPluginCaller(caller)
+			executor.SetAnswer("language", "node")
+
+			question := &types.Question{OptionsSource: &types.OptionsSource{
+				Type:   types.SourceTypePlugin,
+				Plugin: "package-manager-mise",
+				Key:    "versions",
+				Args:   []string{"{{ .language }}"},
+			}}
+			load, err := executor.PrepareOptionsLoad(question)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(caller.requests).To(BeEmpty())
+
+			executor.SetAnswer("language", "python")
+			loaded, err := load()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(executor.MergeOptions(question, loaded)).To(Equal([]types.QuestionOption{{Label: "22.11.0", Value: "22.11.0"}}))
+			Expect(caller.requests[0].Params.Packages).To(Equal([]string{"node"}))
+		})
+
+		It("should not prepare a load without an options source", func() {
+			load, err := executor.PrepareOptionsLoad(&types.Question{Options: []types.QuestionOption{{Value: "a"}}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(load).To(BeNil())
+		})
 	})
 
 	Describe("GetProgress", func() {
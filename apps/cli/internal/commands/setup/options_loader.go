@@ -1,32 +1,69 @@
 package setup
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/jameswlane/devex/apps/cli/internal/config"
+	"github.com/jameswlane/devex/apps/cli/internal/log"
 	"github.com/jameswlane/devex/apps/cli/internal/platform"
 	"github.com/jameswlane/devex/apps/cli/internal/types"
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
 )
 
+const (
+	// pluginOptionsTimeout bounds a plugin options query, including a first-use download
+	pluginOptionsTimeout = 30 * time.Second
+
+	// defaultPluginOptionsTTL is how long plugin options are cached on disk when the source
+	// does not set cache_ttl
+	defaultPluginOptionsTTL = time.Hour
+)
+
+// PluginCaller sends protocol requests to plugins; *bootstrap.PluginBootstrap implements it
+type PluginCaller interface {
+	EnsurePlugin(ctx context.Context, pluginName string) error
+	CallPlugin(ctx context.Context, pluginName string, req *sdk.RPCRequest, onEvent func(sdk.RPCEvent)) (*sdk.RPCResult, error)
+}
+
 // OptionsLoader loads options dynamically from various sources
 type OptionsLoader struct {
 	settings config.CrossPlatformSettings
 	platform platform.DetectionResult
+	plugins  PluginCaller
+
+	// Successful plugin results by cache key, so a question revisited in the session is not
+	// queried again. The lock only guards the map, never a plugin download or call.
+	mu            sync.Mutex
+	pluginResults map[string][]types.QuestionOption
 }
 
 // NewOptionsLoader creates a new options loader
 func NewOptionsLoader(settings config.CrossPlatformSettings, platform platform.DetectionResult) *OptionsLoader {
 	return &OptionsLoader{
-		settings: settings,
-		platform: platform,
+		settings:      settings,
+		platform:      platform,
+		pluginResults: make(map[string][]types.QuestionOption),
 	}
 }
 
+// SetPluginCaller sets the plugin system used to answer plugin options sources
+func (ol *OptionsLoader) SetPluginCaller(caller PluginCaller) {
+	ol.plugins = caller
+}
+
 // Load loads options from the specified source
 func (ol *OptionsLoader) Load(source *types.OptionsSource) ([]types.QuestionOption, error) {
 	switch source.Type {
@@ -85,11 +122,163 @@ func (ol *OptionsLoader) loadFromSystem(source *types.OptionsSource) ([]types.Qu
 	}
 }
 
-// loadFromPlugin loads options from plugins
+// loadFromPlugin asks a plugin for the options of an option set. Successful results are kept
+// for the session and cached on disk for cache_ttl; failures are not kept, so revisiting the
+// question tries again. The query may take up to pluginOptionsTimeout, so callers rendering a
+// UI should run it in the background.
 func (ol *OptionsLoader) loadFromPlugin(source *types.OptionsSource) ([]types.QuestionOption, error) {
-	// This would query plugins for available options
-	// For now, return empty
-	return []types.QuestionOption{}, nil
+	if source.Plugin == "" {
+		return nil, fmt.Errorf("plugin options source requires a plugin")
+	}
+	if source.Key == "" {
+		return nil, fmt.Errorf("plugin options source requires a key naming the option set")
+	}
+
+	ttl := defaultPluginOptionsTTL
+	if source.CacheTTL != "" {
+		parsed, err := time.ParseDuration(source.CacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache_ttl %q: %w", source.CacheTTL, err)
+		}
+		ttl = parsed
+	}
+
+	key := pluginOptionsCacheKey(source)
+	ol.mu.Lock()
+	options, ok := ol.pluginResults[key]
+	ol.mu.Unlock()
+	if ok {
+		return options, nil
+	}
+
+	if ttl > 0 {
+		if options, ok := loadCachedPluginOptions(key, ttl); ok {
+			ol.rememberPluginOptions(key, options)
+			return options, nil
+		}
+	}
+
+	options, err := ol.queryPlugin(source)
+	if err != nil {
+		return nil, err
+	}
+	ol.rememberPluginOptions(key, options)
+	if ttl > 0 {
+		if cacheErr := saveCachedPluginOptions(key, options); cacheErr != nil {
+			log.Debug("Failed to cache plugin options", "plugin", source.Plugin, "error", cacheErr)
+		}
+	}
+	return options, nil
+}
+
+// rememberPluginOptions keeps the options of a plugin query for the rest of the session
+func (ol *OptionsLoader) rememberPluginOptions(key string, options []types.QuestionOption) {
+	ol.mu.Lock()
+	defer ol.mu.Unlock()
+	ol.pluginResults[key] = options
+}
+
+// queryPlugin sends an options request to the plugin, downloading it on first use
+func (ol *OptionsLoader) queryPlugin(source *types.OptionsSource) ([]types.QuestionOption, error) {
+	if ol.plugins == nil {
+		return nil, fmt.Errorf("cannot load options from %s: plugin system is not initialized", source.Plugin)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pluginOptionsTimeout)
+	defer cancel()
+
+	if err := ol.plugins.EnsurePlugin(ctx, source.Plugin); err != nil {
+		return nil, err
+	}
+
+	req := sdk.NewRPCRequest(sdk.MethodOptions, sdk.RPCParams{
+		Query:    source.Key,
+		Packages: source.Args,
+		Options:  source.Flags,
+	})
+	result, err := ol.plugins.CallPlugin(ctx, source.Plugin, req, nil)
+	if errors.Is(err, sdk.ErrProtocolUnsupported) {
+		return nil, fmt.Errorf("the installed %s plugin is too old to provide options, run 'devex plugin update %s'", source.Plugin, source.Plugin)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s options from %s: %w", source.Key, source.Plugin, err)
+	}
+
+	options := make([]types.QuestionOption, 0, len(result.Options))
+	for _, option := range result.Options {
+		label := option.Label
+		if label == "" {
+			label = option.Value
+		}
+		options = append(options, types.QuestionOption{
+			Label:       label,
+			Value:       option.Value,
+			Description: option.Description,
+			Default:     option.Default,
+		})
+	}
+	return options, nil
+}
+
+// pluginOptionsCacheKey identifies a plugin query by everything that is sent to the plugin
+func pluginOptionsCacheKey(source *types.OptionsSource) string {
+	flags := make([]string, 0, len(source.Flags))
+	for name, value := range source.Flags {
+		flags = append(flags, name+"="+value)
+	}
+	sort.Strings(flags)
+
+	parts := append([]string{source.Plugin, source.Key}, source.Args...)
+	parts = append(parts, flags...)
+	hash := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(hash[:])
+}
+
+// pluginOptionsCachePath returns the disk cache file for a plugin query
+func pluginOptionsCachePath(key string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".devex", "setup-cache", "options", key+".json"), nil
+}
+
+// loadCachedPluginOptions returns the cached options of a plugin query younger than ttl
+func loadCachedPluginOptions(key string, ttl time.Duration) ([]types.QuestionOption, bool) {
+	path, err := pluginOptionsCachePath(key)
+	if err != nil {
+		return nil, false
+	}
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > ttl {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var options []types.QuestionOption
+	if err := json.Unmarshal(data, &options); err != nil {
+		return nil, false
+	}
+	return options, true
+}
+
+// saveCachedPluginOptions stores the options of a plugin query on disk
+func saveCachedPluginOptions(key string, options []types.QuestionOption) error {
+	path, err := pluginOptionsCachePath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	data, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
 }
 
 // getLanguageOptions returns programming language options
@@ -1,6 +1,8 @@
 package setup_test
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 
@@ -11,8 +13,48 @@ import (
 	"github.com/jameswlane/devex/apps/cli/internal/config"
 	"github.com/jameswlane/devex/apps/cli/internal/platform"
 	"github.com/jameswlane/devex/apps/cli/internal/types"
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
 )
 
+// fakePluginCaller records plugin requests and answers them with a fixed result
+type fakePluginCaller struct {
+	result   *sdk.RPCResult
+	err      error
+	ensured  []string
+	requests []*sdk.RPCRequest
+}
+
+func (c *fakePluginCaller) EnsurePlugin(_ context.Context, pluginName string) error {
+	c.ensured = append(c.ensured, pluginName)
+	return nil
+}
+
+func (c *fakePluginCaller) CallPlugin(_ context.Context, _ string, req *sdk.RPCRequest, _ func(sdk.RPCEvent)) (*sdk.RPCResult, error) {
+	c.requests = append(c.requests, req)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.result, nil
+}
+
+// blockingPluginCaller holds queries to the "slow" plugin until release is closed
+type blockingPluginCaller struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingPluginCaller) EnsurePlugin(_ context.Context, pluginName string) error {
+	if pluginName == "slow" {
+		close(c.started)
+		<-c.release
+	}
+	return nil
+}
+
+func (c *blockingPluginCaller) CallPlugin(_ context.Context, pluginName string, _ *sdk.RPCRequest, _ func(sdk.RPCEvent)) (*sdk.RPCResult, error) {
+	return &sdk.RPCResult{Options: []sdk.OptionInfo{{Value: pluginName}}}, nil
+}
+
 var _ = Describe("OptionsLoader", func() {
 	var (
 		loader           *setup.OptionsLoader
@@ -232,14 +274,132 @@ description: "Third option"`,
 		})
 
 		Context("with plugin source", func() {
-			It("should return empty options (not yet implemented)", func() {
-				source := &types.OptionsSource{
-					Type: types.SourceTypePlugin,
+			var (
+				caller *fakePluginCaller
+				source *types.OptionsSource
+			)
+
+			BeforeEach(func() {
+				GinkgoT().Setenv("HOME", tempDir)
+				caller = &fakePluginCaller{result: &sdk.RPCResult{Options: []sdk.OptionInfo{
+					{Value: "22.11.0", Label: "node 22.11.0", Default: true},
+					{Value: "20.18.0"},
+				}}}
+				source = &types.OptionsSource{
+					Type:   types.SourceTypePlugin,
+					Plugin: "package-manager-mise",
+					Key:    "versions",
+					Args:   []string{"node"},
+					Flags:  map[string]string{"limit": "2"},
 				}
+				loader.SetPluginCaller(caller)
+			})
 
+			It("should query the plugin for the option set", func() {
 				options, err := loader.Load(source)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(options).To(BeEmpty())
+				Expect(options).To(Equal([]types.QuestionOption{
+					{Label: "node 22.11.0", Value: "22.11.0", Default: true},
+					{Label: "20.18.0", Value: "20.18.0"},
+				}))
+
+				Expect(caller.ensured).To(Equal([]string{"package-manager-mise"}))
+				Expect(caller.requests).To(HaveLen(1))
+				Expect(caller.requests[0].Method).To(Equal(sdk.MethodOptions))
+				Expect(caller.requests[0].Params.Query).To(Equal("versions"))
+				Expect(caller.requests[0].Params.Packages).To(Equal([]string{"node"}))
+				Expect(caller.requests[0].Params.Options).To(HaveKeyWithValue("limit", "2"))
+			})
+
+			It("should cache results across loaders", func() {
+				_, err := loader.Load(source)
+				Expect(err).NotTo(HaveOccurred())
+				_, err = loader.Load(source)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(caller.requests).To(HaveLen(1))
+
+				other := setup.NewOptionsLoader(settings, detectedPlatform)
+				other.SetPluginCaller(caller)
+				options, err := other.Load(source)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(options).To(HaveLen(2))
+				Expect(caller.requests).To(HaveLen(1))
+				Expect(filepath.Join(tempDir, ".devex", "setup-cache", "options")).To(BeADirectory())
+			})
+
+			It("should skip the disk cache when cache_ttl is 0", func() {
+				source.CacheTTL = "0"
+				_, err := loader.Load(source)
+				Expect(err).NotTo(HaveOccurred())
+
+				other := setup.NewOptionsLoader(settings, detectedPlatform)
+				other.SetPluginCaller(caller)
+				_, err = other.Load(source)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(caller.requests).To(HaveLen(2))
+			})
+
+			It("should ask to update plugins that do not speak the protocol", func() {
+				caller.err = sdk.ErrProtocolUnsupported
+				_, err := loader.Load(source)
+				Expect(err).To(MatchError(ContainSubstring("run 'devex plugin update package-manager-mise'")))
+			})
+
+			It("should not cache failures on disk", func() {
+				caller.err = errors.New("mise is not installed")
+				_, err := loader.Load(source)
+				Expect(err).To(HaveOccurred())
+
+				caller.err = nil
+				other := setup.NewOptionsLoader(settings, detectedPlatform)
+				other.SetPluginCaller(caller)
+				options, err := other.Load(source)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(options).To(HaveLen(2))
+			})
+
+			It("should retry failed queries in the same session", func() {
+				caller.err = errors.New("mise is not installed")
+				_, err := loader.Load(source)
+				Expect(err).To(HaveOccurred())
+
+				caller.err = nil
+				options, err := loader.Load(source)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(options).To(HaveLen(2))
+				Expect(caller.requests).To(HaveLen(2))
+			})
+
+			It("should not block other queries while a plugin is downloaded", func() {
+				blocking := &blockingPluginCaller{started: make(chan struct{}), release: make(chan struct{})}
+				loader.SetPluginCaller(blocking)
+
+				slow := make(chan error, 1)
+				go func() {
+					_, err := loader.Load(&types.OptionsSource{Type: types.SourceTypePlugin, Plugin: "slow", Key: "themes"})
+					slow <- err
+				}()
+				Eventually(blocking.started).Should(BeClosed())
+
+				options, err := loader.Load(&types.OptionsSource{Type: types.SourceTypePlugin, Plugin: "fast", Key: "themes"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(options).To(Equal([]types.QuestionOption{{Label: "fast", Value: "fast"}}))
+
+				close(blocking.release)
+				Eventually(slow).Should(Receive(BeNil()))
+			})
+
+			It("should fail without a plugin system", func() {
+				_, err := setup.NewOptionsLoader(settings, detectedPlatform).Load(source)
+				Expect(err).To(MatchError(ContainSubstring("plugin system is not initialized")))
+			})
+
+			It("should require a plugin and an option set", func() {
+				_, err := loader.Load(&types.OptionsSource{Type: types.SourceTypePlugin, Key: "themes"})
+				Expect(err).To(MatchError(ContainSubstring("requires a plugin")))
+
+				_, err = loader.Load(&types.OptionsSource{Type: types.SourceTypePlugin, Plugin: "desktop-gnome"})
+				Expect(err).To(MatchError(ContainSubstring("requires a key")))
 			})
 		})
 
@@ -0,0 +1,114 @@
+package setup
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jameswlane/devex/apps/cli/internal/log"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+// setupProgressKey stores the progress of the last interactive setup in the datastore
+const setupProgressKey = "setup_progress"
+
+// SetupProgress is the position and answers of an interactive setup. It is saved as the setup
+// advances so 'devex setup --resume' can continue an interrupted run from its first incomplete
+// step, and cleared once the setup completes.
+type SetupProgress struct {
+	Setup     string                 `json:"setup"` // Metadata.Name of the setup configuration
+	Step      string                 `json:"step"`  // ID of the first incomplete step
+	Answers   map[string]interface{} `json:"answers"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+// LoadProgress returns the saved progress of an incomplete setup, or nil when there is none
+func LoadProgress(repo types.Repository) (*SetupProgress, error) {
+	if repo == nil {
+		return nil, nil
+	}
+	value, err := repo.Get(setupProgressKey)
+	if err != nil || value == "" {
+		// The key is missing until a setup has been interrupted once
+		return nil, nil
+	}
+
+	var progress SetupProgress
+	if err := json.Unmarshal([]byte(value), &progress); err != nil {
+		return nil, fmt.Errorf("failed to parse saved setup progress: %w", err)
+	}
+	return &progress, nil
+}
+
+// saveProgress records the current step and answers of the setup
+func saveProgress(repo types.Repository, setupConfig *types.SetupConfig, state *types.SetupState) {
+	if repo == nil || state.CurrentStep >= len(setupConfig.Steps) {
+		return
+	}
+	data, err := json.Marshal(SetupProgress{
+		Setup:     setupConfig.Metadata.Name,
+		Step:      setupConfig.Steps[state.CurrentStep].ID,
+		Answers:   state.Answers,
+		UpdatedAt: time.Now().UTC().Truncate(time.Second),
+	})
+	if err != nil {
+		log.Warn("Failed to encode setup progress", "error", err)
+		return
+	}
+	if err := repo.Set(setupProgressKey, string(data)); err != nil {
+		log.Warn("Failed to save setup progress, the setup will not be resumable", "error", err)
+	}
+}
+
+// clearProgress forgets the saved progress once a setup completes
+func clearProgress(repo types.Repository) {
+	if repo == nil {
+		return
+	}
+	if err := repo.Set(setupProgressKey, ""); err != nil {
+		log.Warn("Failed to clear setup progress", "error", err)
+	}
+}
+
+// Restore continues the workflow from saved progress: the answers are set again and the
+// executor moves to the step the progress stopped at
+func (e *SetupExecutor) Restore(progress *SetupProgress) error {
+	if progress.Setup != e.config.Metadata.Name {
+		return fmt.Errorf("saved setup progress is for setup %q, not %q", progress.Setup, e.config.Metadata.Name)
+	}
+	index := e.stepIndex(progress.Step)
+	if index < 0 {
+		return fmt.Errorf("saved setup progress stopped at step %s, which the setup no longer has", progress.Step)
+	}
+
+	multiSelect := make(map[string]bool)
+	for _, step := range e.config.Steps {
+		if step.Question != nil && step.Question.Type == types.QuestionTypeMultiSelect {
+			multiSelect[step.Question.Variable] = true
+		}
+	}
+	for variable, value := range progress.Answers {
+		// JSON decodes the []string of multi-select answers as []interface{}
+		if items, ok := value.([]interface{}); ok && multiSelect[variable] {
+			selected := make([]string, 0, len(items))
+			for _, item := range items {
+				selected = append(selected, fmt.Sprint(item))
+			}
+			value = selected
+		}
+		e.SetAnswer(variable, value)
+	}
+
+	e.state.CurrentStep = index
+	return nil
+}
+
+// stepIndex returns the index of the step with the given ID, or -1
+func (e *SetupExecutor) stepIndex(stepID string) int {
+	for i, step := range e.config.Steps {
+		if step.ID == stepID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,244 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/jameswlane/devex/apps/cli/internal/config"
+	"github.com/jameswlane/devex/apps/cli/internal/datastore/repository"
+	"github.com/jameswlane/devex/apps/cli/internal/log"
+	"github.com/jameswlane/devex/apps/cli/internal/platform"
+	"github.com/jameswlane/devex/apps/cli/internal/snapshot"
+	"github.com/jameswlane/devex/apps/cli/internal/tui"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+	"github.com/jameswlane/devex/apps/cli/internal/utils"
+)
+
+func init() {
+	Register(NewSnapshotCmd)
+}
+
+// NewSnapshotCmd creates the snapshot command for moving a machine setup between systems
+func NewSnapshotCmd(repo types.Repository, settings config.CrossPlatformSettings) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Export and import a portable snapshot of this machine",
+		Long: `Capture the installed applications, theme selections, login shell, shell and git
+configuration and the latest desktop plugin backups in a single archive, and restore it
+on another machine.
+
+Import re-resolves every application for the target platform, so a snapshot taken on
+Ubuntu can be restored on Fedora using the applications' alternative install methods.`,
+		Example: `  # Export a snapshot of this machine
+  devex snapshot export ~/laptop.tar.gz
+
+  # Preview how a snapshot would be restored here
+  devex snapshot import ~/laptop.tar.gz --dry-run
+
+  # Restore only configuration files and themes
+  devex snapshot import ~/laptop.tar.gz --skip-apps`,
+	}
+
+	cmd.AddCommand(newSnapshotExportCmd(repo, settings))
+	cmd.AddCommand(newSnapshotImportCmd(repo, settings))
+
+	return cmd
+}
+
+// newSnapshotExportCmd creates the snapshot export command
+func newSnapshotExportCmd(repo types.Repository, settings config.CrossPlatformSettings) *cobra.Command {
+	return &cobra.Command{
+		Use:   "export [file]",
+		Short: "Write a snapshot of this machine to an archive",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := fmt.Sprintf("devex-snapshot-%s.tar.gz", time.Now().Format("20060102-150405"))
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			opts := snapshot.CollectOptions{
+				HomeDir:  settings.HomeDir,
+				Repo:     repo,
+				Lookup:   NewInstallResolver(settings).Lookup,
+				Platform: types.CurrentPlatform(),
+				Shell:    os.Getenv("SHELL"),
+			}
+			if systemRepo, ok := repo.(types.SystemRepository); ok {
+				opts.Themes = repository.NewThemeRepository(systemRepo)
+			}
+
+			snap, err := snapshot.Collect(opts)
+			if err != nil {
+				return fmt.Errorf("failed to capture snapshot: %w", err)
+			}
+			if err := snap.Write(path); err != nil {
+				return err
+			}
+
+			fmt.Printf("📸 Snapshot written to %s\n", path)
+			fmt.Printf("   %d application(s), %d file(s)", len(snap.Apps), len(snap.Files))
+			if snap.Shell != "" {
+				fmt.Printf(", shell %s", snap.Shell)
+			}
+			fmt.Println()
+			return nil
+		},
+	}
+}
+
+// newSnapshotImportCmd creates the snapshot import command
+func newSnapshotImportCmd(repo types.Repository, settings config.CrossPlatformSettings) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Restore a snapshot on this machine",
+		Long: `Restore a snapshot on this machine. Configuration files that would be replaced are
+kept next to the restored file with a .pre-snapshot suffix. A desktop backup is restored
+through its desktop plugin when this machine runs the same desktop environment.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			skipApps, _ := cmd.Flags().GetBool("skip-apps")
+			skipFiles, _ := cmd.Flags().GetBool("skip-files")
+
+			snap, err := snapshot.Open(args[0])
+			if err != nil {
+				return err
+			}
+
+			target := types.CurrentPlatform()
+			resolutions := snapshot.ResolveApps(snap, NewInstallResolver(settings).Lookup, target)
+			printSnapshotPlan(snap, resolutions, target)
+			if dryRun {
+				return nil
+			}
+
+			if !skipFiles {
+				if err := restoreSnapshotFiles(cmd.Context(), snap, settings, target); err != nil {
+					return err
+				}
+			}
+
+			if systemRepo, ok := repo.(types.SystemRepository); ok {
+				if err := snapshot.RestoreThemes(snap, repository.NewThemeRepository(systemRepo)); err != nil {
+					return fmt.Errorf("failed to restore theme preferences: %w", err)
+				}
+			}
+
+			if !skipApps {
+				var apps []types.CrossPlatformApp
+				for _, resolution := range resolutions {
+					if resolution.Installable() {
+						apps = append(apps, resolution.App)
+					}
+				}
+				if len(apps) > 0 {
+					if err := tui.StartInstallation(cmd.Context(), apps, repo, settings); err != nil {
+						return fmt.Errorf("installation failed: %w", err)
+					}
+				}
+			}
+
+			restoreSnapshotShell(snap)
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("dry-run", false, "Show how the snapshot would be restored without changing anything")
+	cmd.Flags().Bool("skip-apps", false, "Do not install applications")
+	cmd.Flags().Bool("skip-files", false, "Do not restore shell, git and desktop configuration")
+
+	return cmd
+}
+
+// printSnapshotPlan shows how each snapshot app resolves on this platform
+func printSnapshotPlan(snap *snapshot.Snapshot, resolutions []snapshot.AppResolution, target platform.DetectionResult) {
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	fmt.Printf("📸 Snapshot from %s taken %s\n", describeSource(snap.Source), snap.CreatedAt.Local().Format("2006-01-02 15:04"))
+	fmt.Printf("   Restoring on %s\n\n", describeSource(snapshot.Source{OS: target.OS, Distribution: target.Distribution, Version: target.Version}))
+
+	installable := 0
+	for _, resolution := range resolutions {
+		switch {
+		case !resolution.Installable():
+			fmt.Printf("  %s %s: %s\n", yellow("!"), resolution.Name, resolution.Reason)
+		case resolution.SourceMethod != "" && resolution.SourceMethod != resolution.Method:
+			installable++
+			fmt.Printf("  %s %s (%s → %s)\n", green("+"), resolution.Name, resolution.SourceMethod, resolution.Method)
+		default:
+			installable++
+			fmt.Printf("  %s %s (%s)\n", green("+"), resolution.Name, resolution.Method)
+		}
+	}
+	fmt.Printf("\n%d of %d application(s) can be installed, %d file(s) captured.\n\n", installable, len(resolutions), len(snap.Files))
+}
+
+// describeSource formats a platform as e.g. "ubuntu 24.04 (linux)"
+func describeSource(source snapshot.Source) string {
+	if source.Distribution == "" || source.Distribution == "unknown" {
+		return source.OS
+	}
+	if source.Version == "" || source.Version == "unknown" {
+		return fmt.Sprintf("%s (%s)", source.Distribution, source.OS)
+	}
+	return fmt.Sprintf("%s %s (%s)", source.Distribution, source.Version, source.OS)
+}
+
+// restoreSnapshotFiles writes captured files and restores the desktop backup for this desktop environment
+func restoreSnapshotFiles(ctx context.Context, snap *snapshot.Snapshot, settings config.CrossPlatformSettings, target platform.DetectionResult) error {
+	restored, err := snapshot.RestoreFiles(snap, settings.HomeDir)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range restored {
+		switch {
+		case file.Skipped:
+			log.Debug("Snapshot file unchanged", "path", file.Target)
+		case file.Previous != "":
+			fmt.Printf("  restored %s (previous kept as %s)\n", file.Target, filepath.Base(file.Previous))
+		default:
+			fmt.Printf("  restored %s\n", file.Target)
+		}
+
+		if file.Kind != snapshot.FileKindDesktop {
+			continue
+		}
+		pluginName := "desktop-" + file.Desktop
+		pb := GetPluginBootstrap()
+		if file.Desktop != target.DesktopEnv || pb == nil || !pb.IsPluginAvailable(ctx, pluginName) {
+			fmt.Printf("  %s backup saved to %s, restore it with the %s plugin on a %s desktop\n",
+				file.Desktop, file.Target, pluginName, file.Desktop)
+			continue
+		}
+		if err := pb.ExecutePlugin(pluginName, []string{"restore", file.Target}); err != nil {
+			log.Warn("Failed to restore desktop backup", "desktop", file.Desktop, "error", err)
+		}
+	}
+	return nil
+}
+
+// restoreSnapshotShell switches the login shell to the snapshot's shell when it is installed
+func restoreSnapshotShell(snap *snapshot.Snapshot) {
+	if snap.Shell == "" || filepath.Base(os.Getenv("SHELL")) == snap.Shell {
+		return
+	}
+
+	shellPath, err := utils.GetShellPath(snap.Shell)
+	if err != nil {
+		log.Warn("Shell from snapshot is not installed", "shell", snap.Shell, "error", err)
+		return
+	}
+	if err := utils.ChangeUserShell(shellPath); err != nil {
+		log.Warn("Failed to switch login shell", "shell", snap.Shell, "error", err)
+		log.Info(fmt.Sprintf("You can switch manually with: chsh -s %s", shellPath))
+	}
+}
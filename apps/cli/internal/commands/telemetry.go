@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jameswlane/devex/apps/cli/internal/log"
+	"github.com/jameswlane/devex/apps/cli/internal/metrics"
+	"github.com/jameswlane/devex/apps/cli/internal/telemetry"
+)
+
+// telemetryShutdownTimeout bounds exporting the telemetry of a run when the command ends
+const telemetryShutdownTimeout = 5 * time.Second
+
+var (
+	runTelemetry *telemetry.Telemetry
+	commandSpan  trace.Span
+)
+
+// startTelemetry starts the exporters configured with metrics.textfile and
+// telemetry.otlp_endpoint, and starts the span of the command that the spans of the run
+// belong to. Telemetry that cannot start is not worth failing the command for.
+func startTelemetry(cmd *cobra.Command) {
+	t, err := telemetry.Start(cmd.Context(), telemetry.Config{
+		Version:      cmd.Root().Version,
+		Textfile:     viper.GetString("metrics.textfile"),
+		OTLPEndpoint: viper.GetString("telemetry.otlp_endpoint"),
+	})
+	if err != nil {
+		log.Warn("Telemetry is disabled", "error", err)
+		return
+	}
+	runTelemetry = t
+
+	ctx, span := otel.Tracer("devex/commands").Start(cmd.Context(), cmd.CommandPath())
+	cmd.SetContext(ctx)
+	commandSpan = span
+}
+
+// ShutdownTelemetry ends the span of the command with the error it returned and exports the
+// traces and metrics of the run. It must be called before the process exits.
+func ShutdownTelemetry(err error) {
+	if runTelemetry == nil {
+		return
+	}
+
+	if err != nil {
+		commandSpan.RecordError(err)
+		commandSpan.SetStatus(codes.Error, "Command failed")
+	}
+	commandSpan.End()
+
+	ctx, cancel := context.WithTimeout(context.Background(), telemetryShutdownTimeout)
+	defer cancel()
+	if err := runTelemetry.Shutdown(ctx, metrics.Snapshot()); err != nil {
+		log.Warn("Failed to export telemetry", "error", err)
+	}
+	runTelemetry, commandSpan = nil, nil
+}
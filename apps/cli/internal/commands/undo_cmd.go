@@ -1,9 +1,11 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -11,7 +13,9 @@ import (
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 
+	"github.com/jameswlane/devex/apps/cli/internal/audit"
 	"github.com/jameswlane/devex/apps/cli/internal/config"
+	"github.com/jameswlane/devex/apps/cli/internal/installers"
 	"github.com/jameswlane/devex/apps/cli/internal/types"
 	"github.com/jameswlane/devex/apps/cli/internal/undo"
 )
@@ -19,26 +23,38 @@ import (
 // NewUndoCmd creates a new undo command
 func NewUndoCmd(repo types.Repository, settings config.CrossPlatformSettings) *cobra.Command {
 	var (
-		force  bool
-		format string
-		limit  int
+		force   bool
+		format  string
+		limit   int
+		preview bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "undo [operation-id]",
-		Short: "Undo recent configuration changes",
+		Short: "Undo recent configuration changes and installations",
 		Long: `Undo recent configuration changes using backup and version history.
 
 The undo system tracks all configuration operations and allows you to safely
 rollback changes. You can undo the last operation or specify a particular
 operation by its ID.
 
+Installs and uninstalls also record the changes they made to the system:
+installs record the packages, package repositories, signing keys and theme
+files they add, uninstalls the packages and configuration files they remove.
+Undoing them reverts these changes in reverse order; changes that fail to
+revert are kept, and undoing the operation again retries them. Other edits,
+such as lines post-install commands add to your shell startup file, are not
+reverted. Use --preview to see the steps without running them.
+
 Examples:
   # Undo the most recent operation
   devex undo
   
   # Undo a specific operation
   devex undo add-20240817-143022
+
+  # Show what undoing an installation would revert
+  devex undo install-20240817-150112 --preview
   
   # List available operations to undo
   devex undo --list
@@ -51,6 +67,7 @@ Examples:
 		RunE: func(cmd *cobra.Command, args []string) error {
 			baseDir := filepath.Join(os.Getenv("HOME"), ".devex")
 			undoManager := undo.NewUndoManager(baseDir)
+			undoManager.SetSystemReverter(systemReverter{repo: repo})
 
 			// Handle list flag
 			if cmd.Flags().Changed("list") {
@@ -62,6 +79,11 @@ Examples:
 				return showUndoStatus(undoManager, format)
 			}
 
+			// Handle preview flag
+			if preview {
+				return previewUndoOperation(undoManager, args)
+			}
+
 			// Handle specific operation ID
 			if len(args) > 0 {
 				return undoSpecificOperation(undoManager, args[0], force)
@@ -78,6 +100,7 @@ Examples:
 	cmd.Flags().IntVar(&limit, "limit", 10, "Number of operations to show in list")
 	cmd.Flags().Bool("list", false, "List available operations to undo")
 	cmd.Flags().Bool("status", false, "Show undo system status")
+	cmd.Flags().BoolVar(&preview, "preview", false, "Show the steps of the undo without running them")
 
 	return cmd
 }
@@ -213,6 +236,7 @@ func undoSpecificOperation(undoManager *undo.UndoManager, operationID string, fo
 		fmt.Printf("Undo operation: %s\n", operation.Description)
 		fmt.Printf("Target: %s\n", operation.Target)
 		fmt.Printf("Time: %s\n", operation.Timestamp.Format("2006-01-02 15:04:05"))
+		printUndoSteps(undoManager, operation.ID)
 
 		if len(operation.UndoRisks) > 0 {
 			yellow := color.New(color.FgYellow).SprintFunc()
@@ -273,6 +297,7 @@ func undoLastOperation(undoManager *undo.UndoManager, force bool) error {
 		fmt.Printf("Undo operation: %s\n", lastOp.Description)
 		fmt.Printf("Target: %s\n", lastOp.Target)
 		fmt.Printf("Time: %s\n", lastOp.Timestamp.Format("2006-01-02 15:04:05"))
+		printUndoSteps(undoManager, lastOp.ID)
 
 		if len(lastOp.UndoRisks) > 0 {
 			yellow := color.New(color.FgYellow).SprintFunc()
@@ -308,6 +333,9 @@ func displayUndoResult(result *undo.UndoResult) error {
 
 	fmt.Printf("%s %s\n", green("✓"), result.Message)
 	fmt.Printf("Restored from: %s\n", result.RestoredFrom)
+	if result.Reverted > 0 {
+		fmt.Printf("Reverted system changes: %d\n", result.Reverted)
+	}
 
 	if result.NewBackupID != "" {
 		fmt.Printf("Pre-undo backup: %s\n", result.NewBackupID)
@@ -323,3 +351,115 @@ func displayUndoResult(result *undo.UndoResult) error {
 	fmt.Printf("\nYou can now run 'devex config show' to verify the changes\n")
 	return nil
 }
+
+// previewUndoOperation shows the steps undoing an operation takes, the last operation by default
+func previewUndoOperation(undoManager *undo.UndoManager, args []string) error {
+	var operationID string
+	if len(args) > 0 {
+		operationID = args[0]
+	} else {
+		operations, err := undoManager.GetUndoableOperations(1)
+		if err != nil {
+			return fmt.Errorf("failed to get operations: %w", err)
+		}
+		if len(operations) == 0 {
+			fmt.Println("No operations available to undo")
+			return nil
+		}
+		operationID = operations[0].ID
+	}
+
+	operation, err := undoManager.GetOperationDetails(operationID)
+	if err != nil {
+		return fmt.Errorf("failed to get operation details: %w", err)
+	}
+	fmt.Printf("Undo operation: %s\n", operation.Description)
+	printUndoSteps(undoManager, operationID)
+	if !operation.CanUndo {
+		fmt.Printf("\nThis operation was already undone or cannot be undone\n")
+	}
+	return nil
+}
+
+// printUndoSteps lists the steps undoing an operation takes
+func printUndoSteps(undoManager *undo.UndoManager, operationID string) {
+	steps, err := undoManager.PreviewUndo(operationID)
+	if err != nil {
+		return
+	}
+	fmt.Println("\nSteps:")
+	for i, step := range steps {
+		fmt.Printf("  %d. %s\n", i+1, step)
+	}
+}
+
+// beginUndoableOperation records an operation that changes the system and returns a context
+// collecting its changes. Call the returned function when the operation finishes to attach
+// the changes so 'devex undo' reverts them.
+func beginUndoableOperation(ctx context.Context, operation, description, target string, metadata map[string]interface{}) (context.Context, func()) {
+	baseDir := filepath.Join(os.Getenv("HOME"), ".devex")
+	undoManager := undo.NewUndoManager(baseDir)
+
+	undoOp, err := undoManager.RecordOperation(operation, description, target, metadata)
+	if err != nil {
+		// Log warning but don't block the operation
+		fmt.Fprintf(os.Stderr, "Warning: Failed to record undo operation: %v\n", err)
+		return ctx, func() {}
+	}
+
+	changes := undo.NewChangeSet(undoManager.ChangeDir(undoOp.ID))
+	return undo.WithChangeSet(ctx, changes), func() {
+		if recorded := changes.Changes(); len(recorded) > 0 {
+			if err := undoManager.AttachChanges(undoOp.ID, recorded); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Failed to record changes for undo: %v\n", err)
+			}
+		}
+	}
+}
+
+// systemReverter reverts package and repository changes with the installers and sudo
+type systemReverter struct {
+	repo types.Repository
+}
+
+// UninstallPackage removes a package an operation installed
+func (r systemReverter) UninstallPackage(ctx context.Context, change undo.Change) error {
+	installer := installers.GetInstaller(ctx, change.Method)
+	if installer == nil {
+		return fmt.Errorf("install method %s is not available", change.Method)
+	}
+	if err := installer.Uninstall(change.Target, r.repo); err != nil {
+		return err
+	}
+	if change.App != "" {
+		_ = r.repo.DeleteApp(change.App)
+	}
+	return nil
+}
+
+// InstallPackage reinstalls a package an operation removed
+func (r systemReverter) InstallPackage(ctx context.Context, change undo.Change) error {
+	installer := installers.GetInstaller(ctx, change.Method)
+	if installer == nil {
+		return fmt.Errorf("install method %s is not available", change.Method)
+	}
+	if err := installer.Install(change.Target, r.repo); err != nil {
+		return err
+	}
+	if change.App != "" {
+		_ = r.repo.AddApp(change.App)
+	}
+	return nil
+}
+
+// RemoveSystemFile removes a root-owned file such as an APT source list or keyring
+func (r systemReverter) RemoveSystemFile(ctx context.Context, path string) error {
+	if !filepath.IsAbs(path) || filepath.Clean(path) == "/" {
+		return fmt.Errorf("refusing to remove %q", path)
+	}
+	cmd := exec.CommandContext(ctx, "sudo", "rm", "-f", "--", filepath.Clean(path))
+	if output, err := audit.CombinedOutput(audit.WithTrigger(ctx, "", "undo"), cmd); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
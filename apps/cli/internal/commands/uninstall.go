@@ -16,6 +16,7 @@ import (
 	"github.com/jameswlane/devex/apps/cli/internal/installers"
 	"github.com/jameswlane/devex/apps/cli/internal/log"
 	"github.com/jameswlane/devex/apps/cli/internal/types"
+	"github.com/jameswlane/devex/apps/cli/internal/undo"
 )
 
 func NewUninstallCmd(repo types.Repository, settings config.CrossPlatformSettings) *cobra.Command {
@@ -217,6 +218,17 @@ func runUninstall(ctx context.Context, apps []string, category string, all bool,
 		fmt.Println()
 	}
 
+	// Record the uninstall so 'devex undo' can reinstall the packages and restore their files
+	names := make([]string, len(appsToUninstall))
+	for i, app := range appsToUninstall {
+		names[i] = app.Name
+	}
+	ctx, finishUndo := beginUndoableOperation(ctx, "uninstall",
+		fmt.Sprintf("Uninstalled applications: %s", strings.Join(names, ", ")),
+		strings.Join(names, ","),
+		map[string]interface{}{"apps": names})
+	defer finishUndo()
+
 	// Show what will be uninstalled
 	fmt.Printf("%s Uninstalling %d application(s):\n\n", cyan("📦"), len(appsToUninstall))
 
@@ -279,10 +291,16 @@ func runUninstall(ctx context.Context, apps []string, category string, all bool,
 		}
 
 		fmt.Printf("  %s Successfully uninstalled\n", green("✅"))
+		undo.ChangeSetFrom(ctx).Add(undo.Change{
+			Kind:   undo.ChangePackageRemoved,
+			App:    app.Name,
+			Method: app.InstallMethod,
+			Target: app.InstallCommand,
+		})
 
 		// Clean up configuration files if requested
 		if !keepConfig {
-			if err := cleanupConfigFiles(&app); err != nil {
+			if err := cleanupConfigFiles(ctx, &app); err != nil {
 				fmt.Printf("  %s Warning: Failed to clean up config files: %v\n", yellow("⚠️"), err)
 			} else if len(app.ConfigFiles) > 0 {
 				fmt.Printf("  %s Removed configuration files\n", green("🧹"))
@@ -337,10 +355,20 @@ func runUninstall(ctx context.Context, apps []string, category string, all bool,
 	return nil
 }
 
-func cleanupConfigFiles(app *types.AppConfig) error {
-	// Clean up configuration files listed in the app config
+func cleanupConfigFiles(ctx context.Context, app *types.AppConfig) error {
+	// Clean up configuration files listed in the app config, keeping a copy for undo
 	for _, configFile := range app.ConfigFiles {
-		if err := removeFileIfExists(configFile.Destination); err != nil {
+		path, err := expandUserPath(configFile.Destination)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Lstat(path); err != nil {
+			continue
+		}
+		if err := undo.ChangeSetFrom(ctx).SaveFile(app.Name, path); err != nil {
+			return err
+		}
+		if err := removeFileIfExists(path); err != nil {
 			return fmt.Errorf("failed to remove config file %s: %w", configFile.Destination, err)
 		}
 	}
@@ -357,14 +385,23 @@ func cleanupDataFiles(app *types.AppConfig) error {
 	return nil
 }
 
+// expandUserPath expands a leading ~/ to the home directory
+func expandUserPath(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, path[2:]), nil
+}
+
 func removeFileIfExists(path string) error {
 	// Expand home directory if needed
-	if strings.HasPrefix(path, "~/") {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return err
-		}
-		path = filepath.Join(homeDir, path[2:])
+	path, err := expandUserPath(path)
+	if err != nil {
+		return err
 	}
 
 	// Check if file exists before trying to remove
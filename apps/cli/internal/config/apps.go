@@ -94,7 +94,11 @@ func convertToAppConfig(app types.CrossPlatformApp) *types.AppConfig {
 		AptSources:       osConfig.AptSources,
 		CleanupFiles:     osConfig.CleanupFiles,
 		Conflicts:        osConfig.Conflicts,
+		DockerOptions:    osConfig.DockerOptions,
 		DownloadURL:      osConfig.DownloadURL,
+		SHA256:           osConfig.SHA256,
+		Signature:        osConfig.Signature,
+		SignatureKey:     osConfig.SignatureKey,
 		InstallDir:       osConfig.Destination,
 	}
 }
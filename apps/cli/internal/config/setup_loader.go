@@ -265,6 +265,9 @@ func (l *SetupConfigLoader) validateQuestion(q *types.Question) error {
 		if len(q.Options) == 0 && q.OptionsSource == nil {
 			return fmt.Errorf("select/multiselect questions require options or options_source")
 		}
+		if err := validateOptionsSource(q.OptionsSource); err != nil {
+			return err
+		}
 	case types.QuestionTypeBool:
 		// Bool questions don't need options
 	default:
@@ -274,6 +277,25 @@ func (l *SetupConfigLoader) validateQuestion(q *types.Question) error {
 	return nil
 }
 
+// validateOptionsSource validates the settings a dynamic options source needs
+func validateOptionsSource(source *types.OptionsSource) error {
+	if source == nil || source.Type != types.SourceTypePlugin {
+		return nil
+	}
+	if source.Plugin == "" {
+		return fmt.Errorf("options_source.plugin is required for plugin sources")
+	}
+	if source.Key == "" {
+		return fmt.Errorf("options_source.key is required for plugin sources")
+	}
+	if source.CacheTTL != "" {
+		if _, err := time.ParseDuration(source.CacheTTL); err != nil {
+			return fmt.Errorf("invalid options_source.cache_ttl %q: %w", source.CacheTTL, err)
+		}
+	}
+	return nil
+}
+
 // validateInfo validates info configuration
 func (l *SetupConfigLoader) validateInfo(info *types.InfoContent) error {
 	if info.Message == "" {
@@ -4,13 +4,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+
 	"github.com/jameswlane/devex/apps/cli/internal/log"
 	"github.com/jameswlane/devex/apps/cli/internal/types"
 	"github.com/spf13/viper"
 )
 
+var sha256Pattern = regexp.MustCompile(`^[a-fA-F0-9]{64}$`)
+
 // ValidationError represents a configuration validation error
 type ValidationError struct {
 	File    string
@@ -224,6 +229,8 @@ func (v *ConfigValidator) validateCrossPlatformApp(app types.CrossPlatformApp, f
 		v.addError(filename, "platforms", "No platform configurations found", nil)
 	}
 
+	v.validateVersionConstraint(app.Version, "version", filename)
+
 	// Validate category if present
 	if app.Category != "" {
 		validCategories := []string{
@@ -247,6 +254,9 @@ func (v *ConfigValidator) validateOSConfig(osConfig types.OSConfig, platform, fi
 		v.addError(filename, platform+".install_command", "Install command is required", nil)
 	}
 
+	v.validateVersionConstraint(osConfig.Version, platform+".version", filename)
+	v.validateDownloadVerification(osConfig, platform, filename)
+
 	// Validate platform-specific install methods
 	switch platform {
 	case "linux":
@@ -270,6 +280,29 @@ func (v *ConfigValidator) validateOSConfig(osConfig types.OSConfig, platform, fi
 	}
 }
 
+// validateDownloadVerification checks the checksum and signature settings of a download
+func (v *ConfigValidator) validateDownloadVerification(osConfig types.OSConfig, platform, filename string) {
+	if osConfig.SHA256 != "" && !sha256Pattern.MatchString(osConfig.SHA256) {
+		v.addError(filename, platform+".sha256", "sha256 must be 64 hexadecimal characters", nil)
+	}
+	if osConfig.Signature != "" && osConfig.SignatureKey == "" {
+		v.addError(filename, platform+".signature_key", "A signature requires the signature_key that verifies it", nil)
+	}
+	if osConfig.SignatureKey != "" && osConfig.Signature == "" {
+		v.addWarning(filename, platform+".signature", "signature_key is set without a signature to verify", nil)
+	}
+}
+
+// validateVersionConstraint checks that a version constraint can be parsed
+func (v *ConfigValidator) validateVersionConstraint(constraint, field, filename string) {
+	if constraint == "" {
+		return
+	}
+	if _, err := sdk.ParseVersionConstraint(constraint); err != nil {
+		v.addError(filename, field, fmt.Sprintf("Invalid version constraint: %s", constraint), err)
+	}
+}
+
 // addError adds a validation error
 func (v *ConfigValidator) addError(file, field, message string, err error) {
 	v.errors = append(v.errors, ValidationError{
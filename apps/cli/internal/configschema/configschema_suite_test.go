@@ -0,0 +1,13 @@
+package configschema_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestConfigSchema(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ConfigSchema Suite")
+}
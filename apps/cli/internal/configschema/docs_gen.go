@@ -0,0 +1,164 @@
+// Code generated by "go run ./tools config-docs"; DO NOT EDIT.
+
+package configschema
+
+// typeDocs holds the doc comments of the configuration types
+var typeDocs = map[string]string{
+	"security.SecurityConfig":   "SecurityConfig holds security configuration and overrides",
+	"security.SecurityLevel":    "SecurityLevel defines how strict command validation should be",
+	"security.SecurityOverride": "SecurityOverride represents a security rule override",
+	"security.SecurityRuleType": "SecurityRuleType defines the type of security rule being overridden",
+	"types.ActionType":          "ActionType defines the type of action",
+	"types.AptSource":           "AptSource defines a source repository for APT.",
+	"types.Condition":           "Condition represents a conditional expression",
+	"types.ConditionOperator":   "ConditionOperator defines comparison operators",
+	"types.ConfigFile":          "ConfigFile defines a source and destination for configuration files.",
+	"types.CopyCommand":         "CopyCommand defines the source and destination for file copying.",
+	"types.CrossPlatformApp":    "CrossPlatformApp defines an application with OS-specific installation methods",
+	"types.DockerHealthcheck":   "DockerHealthcheck defines the health check of a Docker container.",
+	"types.DockerOptions":       "DockerOptions defines options for Docker containers.",
+	"types.ErrorBehavior":       "ErrorBehavior defines how to handle errors",
+	"types.InfoContent":         "InfoContent represents informational content to display",
+	"types.InfoStyle":           "InfoStyle defines the style of informational message",
+	"types.InstallCommand":      "InstallCommand defines a command to execute during installation.",
+	"types.OSConfig":            "OSConfig defines OS-specific installation configuration",
+	"types.OptionsSource":       "OptionsSource defines how to load options dynamically",
+	"types.PlatformRequirement": "PlatformRequirement defines OS and version requirements for an installation method",
+	"types.Question":            "Question represents a user input question",
+	"types.QuestionOption":      "QuestionOption represents a selectable option",
+	"types.QuestionType":        "QuestionType defines the type of question",
+	"types.SetupAction":         "SetupAction represents a reusable action definition",
+	"types.SetupConfig":         "SetupConfig represents the complete dynamic setup workflow configuration",
+	"types.SetupMetadata":       "SetupMetadata provides information about the setup configuration",
+	"types.SetupStep":           "SetupStep represents a single screen/step in the setup workflow",
+	"types.SetupTimeouts":       "SetupTimeouts configures timeouts for various operations",
+	"types.SourceType":          "SourceType defines where options come from",
+	"types.StepAction":          "StepAction represents an action to execute",
+	"types.StepNavigation":      "StepNavigation controls navigation behavior",
+	"types.StepType":            "StepType defines the type of setup step",
+	"types.SystemCondition":     "SystemCondition represents a system-level condition",
+	"types.SystemRequirements":  "SystemRequirements defines system-level requirements for an application",
+	"types.Theme":               "Theme defines a theme configuration.",
+	"types.Validation":          "Validation defines validation rules for answers",
+}
+
+// fieldDocs holds the comments of the fields of the configuration types
+var fieldDocs = map[string]string{
+	"security.SecurityConfig.AppSpecificOverrides":   "overrides keyed by app name",
+	"security.SecurityConfig.GlobalOverrides":        "overrides applying to every app",
+	"security.SecurityConfig.Level":                  "0=Strict, 1=Moderate, 2=Permissive, 3=Enterprise",
+	"security.SecurityOverride.Pattern":              "regular expression matching the allowed commands",
+	"security.SecurityOverride.Reason":               "why the override is safe",
+	"security.SecurityOverride.WarnUser":             "show a warning when the override allows a command",
+	"types.AptSource.KeyFingerprint":                 "SECURITY: GPG key fingerprint for validation",
+	"types.Condition.And":                            "Logical operators for complex conditions",
+	"types.Condition.Operator":                       "Operator (equals, not_equals, contains, exists, etc.)",
+	"types.Condition.System":                         "System condition (platform, desktop, etc.)",
+	"types.Condition.Value":                          "Value to compare against",
+	"types.Condition.Variable":                       "Variable to check",
+	"types.CrossPlatformApp.AllPlatforms":            "installation on every platform, overrides the OS specific ones",
+	"types.CrossPlatformApp.Category":                "category the app is listed under, e.g. Development Tools",
+	"types.CrossPlatformApp.Default":                 "whether the app is installed by default",
+	"types.CrossPlatformApp.Description":             "short description shown when selecting apps",
+	"types.CrossPlatformApp.DesktopEnvironments":     "desktops the app suits, or \"all\"",
+	"types.CrossPlatformApp.Linux":                   "installation on Linux",
+	"types.CrossPlatformApp.MacOS":                   "installation on macOS",
+	"types.CrossPlatformApp.Name":                    "unique name, referenced by dependencies and conflicts",
+	"types.CrossPlatformApp.Version":                 "version constraint, e.g. \">=2.40\" or \"~1.2\"",
+	"types.CrossPlatformApp.Windows":                 "installation on Windows",
+	"types.DockerOptions.Stack":                      "run as a service of a devex-managed compose project",
+	"types.InfoContent.Message":                      "Message to display",
+	"types.InfoContent.Style":                        "Style/type of info (info, warning, error, success)",
+	"types.InfoContent.Variables":                    "Variables to interpolate into message",
+	"types.OSConfig.Alternatives":                    "configurations for other platforms, picked by platform_requirements",
+	"types.OSConfig.Conflicts":                       "names of apps that cannot be installed together with this one",
+	"types.OSConfig.Dependencies":                    "names of apps installed before this one",
+	"types.OSConfig.InstallCommand":                  "packages or arguments passed to the install method",
+	"types.OSConfig.InstallMethod":                   "package manager plugin that installs the app, e.g. apt or brew",
+	"types.OSConfig.OfficialSupport":                 "whether the maintainers test this configuration",
+	"types.OSConfig.PlatformRequirements":            "platforms this configuration is selected on; any platform when empty",
+	"types.OSConfig.SHA256":                          "expected checksum of the download",
+	"types.OSConfig.Signature":                       "URL of a detached OpenPGP signature of the download",
+	"types.OSConfig.SignatureKey":                    "URL or path of the public key that made Signature",
+	"types.OSConfig.UninstallCommand":                "packages or arguments passed to remove the app",
+	"types.OSConfig.Version":                         "version constraint on this platform, overrides the app version",
+	"types.OptionsSource.Args":                       "Arguments of the option set, e.g. the tool whose versions are listed (for plugin source)",
+	"types.OptionsSource.CacheTTL":                   "How long plugin results are cached, e.g. \"30m\" or \"0\" to always query (default 1h)",
+	"types.OptionsSource.Flags":                      "Flags passed to the plugin, e.g. limit: \"10\" (for plugin source)",
+	"types.OptionsSource.Key":                        "Key within the config (for config source), or the option set to query (for plugin source)",
+	"types.OptionsSource.Path":                       "Path to config file or key (for config source)",
+	"types.OptionsSource.Plugin":                     "Plugin that answers the options query (for plugin source)",
+	"types.OptionsSource.SystemType":                 "System detection type (for system source)",
+	"types.OptionsSource.Transform":                  "Transform to apply to loaded options",
+	"types.OptionsSource.Type":                       "Type of source (config, system, plugin)",
+	"types.PlatformRequirement.Arch":                 "CPU architecture, e.g. amd64 or arm64",
+	"types.PlatformRequirement.OS":                   "operating system or distribution ID, e.g. linux, ubuntu or fedora",
+	"types.PlatformRequirement.PlatformDependencies": "packages installed first on this platform",
+	"types.PlatformRequirement.Version":              "distribution version, e.g. \"22.04\", or \"8+\" for 8 and newer",
+	"types.Question.Default":                         "Default value",
+	"types.Question.Multiple":                        "Whether multiple selections are allowed (for select type)",
+	"types.Question.Options":                         "Options for select/multiselect questions",
+	"types.Question.OptionsSource":                   "Options source (static, config, system)",
+	"types.Question.Placeholder":                     "Placeholder text for text inputs",
+	"types.Question.Prompt":                          "Prompt text shown to user",
+	"types.Question.Type":                            "Type of question (text, select, multiselect)",
+	"types.Question.Validation":                      "Validation rules",
+	"types.Question.Variable":                        "Variable name to store the answer",
+	"types.QuestionOption.Default":                   "Whether this option is selected by default",
+	"types.QuestionOption.Description":               "Description/help text for this option",
+	"types.QuestionOption.Label":                     "Display name shown to user",
+	"types.QuestionOption.ShowIf":                    "Condition for showing this option",
+	"types.QuestionOption.Value":                     "Value stored when selected",
+	"types.SetupAction.Description":                  "Action description",
+	"types.SetupAction.Name":                         "Action name",
+	"types.SetupAction.Params":                       "Action parameters",
+	"types.SetupAction.Type":                         "Action type",
+	"types.SetupConfig.Actions":                      "Actions map action IDs to their implementations",
+	"types.SetupConfig.Metadata":                     "Metadata about the setup configuration",
+	"types.SetupConfig.Steps":                        "Steps define the workflow screens/questions",
+	"types.SetupConfig.Timeouts":                     "Timeouts for various operations",
+	"types.SetupStep.Action":                         "Action to execute (if type is \"action\")",
+	"types.SetupStep.Description":                    "Optional description/help text",
+	"types.SetupStep.ID":                             "Unique identifier for this step",
+	"types.SetupStep.Info":                           "Information to display (if type is \"info\")",
+	"types.SetupStep.Navigation":                     "Navigation configuration",
+	"types.SetupStep.Question":                       "Question configuration (if type is \"question\")",
+	"types.SetupStep.ShowIf":                         "Conditions for showing this step",
+	"types.SetupStep.Skippable":                      "Whether this step can be skipped",
+	"types.SetupStep.Title":                          "Display name shown to users",
+	"types.SetupStep.Type":                           "Type of step (question, info, action)",
+	"types.StepAction.OnError":                       "Error handling behavior",
+	"types.StepAction.Params":                        "Action-specific parameters",
+	"types.StepAction.ProgressMessage":               "Progress message shown during execution",
+	"types.StepAction.SuccessMessage":                "Success message after completion",
+	"types.StepAction.Type":                          "Action type (install, configure, execute)",
+	"types.StepNavigation.AllowBack":                 "Whether user can go back from this step",
+	"types.StepNavigation.AutoAdvance":               "Auto-advance after completion",
+	"types.StepNavigation.NextStep":                  "Next step ID (overrides default sequential flow)",
+	"types.StepNavigation.NextStepIf":                "Conditional next steps",
+	"types.StepNavigation.PrevStep":                  "Previous step ID (overrides default sequential flow)",
+	"types.SystemCondition.Architecture":             "Architecture condition (amd64, arm64, etc.)",
+	"types.SystemCondition.Desktop":                  "Desktop environment condition (gnome, kde, etc.)",
+	"types.SystemCondition.Distribution":             "Distribution condition (ubuntu, fedora, etc.)",
+	"types.SystemCondition.HasDesktop":               "Has desktop environment",
+	"types.SystemCondition.OS":                       "OS condition (linux, darwin, windows)",
+	"types.Validation.Function":                      "Custom validation function name",
+	"types.Validation.Max":                           "Maximum value/length",
+	"types.Validation.Message":                       "Custom validation message",
+	"types.Validation.Min":                           "Minimum value/length",
+	"types.Validation.Pattern":                       "Regex pattern to match",
+	"types.Validation.Required":                      "Required field",
+}
+
+// typeEnums holds the constants declared for the named types of the configuration
+var typeEnums = map[string][]any{
+	"security.SecurityLevel":    {0, 1, 2, 3},
+	"security.SecurityRuleType": {"dangerous-command", "unknown-executable", "command-injection", "privilege-escalation", "network-access", "filesystem-access"},
+	"types.ActionType":          {"install", "configure", "execute", "plugin"},
+	"types.ConditionOperator":   {"equals", "not_equals", "contains", "not_contains", "exists", "not_exists", "greater_than", "less_than", "matches", "not_matches"},
+	"types.ErrorBehavior":       {"stop", "continue", "retry", "skip"},
+	"types.InfoStyle":           {"info", "warning", "error", "success"},
+	"types.QuestionType":        {"text", "select", "multiselect", "bool"},
+	"types.SourceType":          {"static", "config", "system", "plugin"},
+	"types.StepType":            {"question", "info", "action"},
+}
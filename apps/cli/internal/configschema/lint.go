@@ -0,0 +1,534 @@
+package configschema
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+	"gopkg.in/yaml.v3"
+)
+
+// InstallMethodPluginPrefix prefixes install methods to name the plugin providing them
+const InstallMethodPluginPrefix = "package-manager-"
+
+// osBlocks are the keys of an app holding an OS configuration
+var osBlocks = []string{"linux", "macos", "windows", "all_platforms"}
+
+// distributionPackageManagers only work on the distributions they belong to
+var distributionPackageManagers = map[string]bool{
+	"apt": true, "deb": true, "dnf": true, "yum": true, "rpm": true, "pacman": true, "yay": true,
+	"makepkg": true, "zypper": true, "emerge": true, "apk": true, "xbps": true, "eopkg": true,
+}
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// Options configures the checks of Lint
+type Options struct {
+	// Plugins lists the names of the available plugins. Install methods are only checked
+	// against plugins when it is not nil.
+	Plugins map[string]bool
+}
+
+// File is a parsed configuration file
+type File struct {
+	Path     string
+	Kind     Kind
+	Document *yaml.Node // nil when the file does not parse
+	// Diagnostics are the syntax, schema and single-file problems of the file
+	Diagnostics []Diagnostic
+}
+
+// KindOf tells the kind of a configuration file from its path
+func KindOf(path string) (Kind, bool) {
+	ext := filepath.Ext(path)
+	if ext != ".yaml" && ext != ".yml" {
+		return "", false
+	}
+	switch strings.TrimSuffix(filepath.Base(path), ext) {
+	case "setup":
+		return KindSetup, true
+	case "security":
+		return KindSecurity, true
+	}
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(path)), "/") {
+		if dir == "applications" {
+			return KindApp, true
+		}
+	}
+	return "", false
+}
+
+// ParseFile parses a configuration file and checks it against its schema
+func ParseFile(path string, data []byte, kind Kind) *File {
+	file := &File{Path: path, Kind: kind}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		line := 1
+		if match := yamlLinePattern.FindStringSubmatch(err.Error()); match != nil {
+			line, _ = strconv.Atoi(match[1])
+		}
+		file.Diagnostics = append(file.Diagnostics, Diagnostic{
+			Line: line, Column: 1, Severity: SeverityError,
+			Message: strings.TrimPrefix(err.Error(), "yaml: "),
+		})
+		file.finish()
+		return file
+	}
+	file.Document = &document
+
+	schema, err := For(kind)
+	if err != nil {
+		file.Diagnostics = append(file.Diagnostics, Diagnostic{Line: 1, Column: 1, Severity: SeverityError, Message: err.Error()})
+		file.finish()
+		return file
+	}
+	root := DocumentRoot(&document)
+	if root == nil {
+		file.Diagnostics = append(file.Diagnostics, Diagnostic{Line: 1, Column: 1, Severity: SeverityError, Message: "file is empty"})
+		file.finish()
+		return file
+	}
+
+	file.Diagnostics = append(file.Diagnostics, Validate(schema, &document)...)
+	switch kind {
+	case KindApp:
+		file.Diagnostics = append(file.Diagnostics, checkApp(root)...)
+	case KindSetup:
+		file.Diagnostics = append(file.Diagnostics, checkSetup(root)...)
+	case KindSecurity:
+		file.Diagnostics = append(file.Diagnostics, checkSecurity(root)...)
+	}
+	file.finish()
+	return file
+}
+
+// finish sets the file of the diagnostics
+func (f *File) finish() {
+	for i := range f.Diagnostics {
+		f.Diagnostics[i].File = f.Path
+	}
+}
+
+// AppName returns the name of the app defined by an application file and its node
+func (f *File) AppName() (string, *yaml.Node) {
+	if f.Kind != KindApp {
+		return "", nil
+	}
+	_, name := MappingValue(DocumentRoot(f.Document), "name")
+	if name == nil || name.Kind != yaml.ScalarNode || name.Value == "" {
+		return "", nil
+	}
+	return name.Value, name
+}
+
+// AppRef locates the definition of an app
+type AppRef struct {
+	Name   string
+	File   string
+	Line   int
+	Column int
+}
+
+// Catalog indexes the apps defined by a set of application files for cross-file checks.
+// Names are matched case-insensitively like the install resolver does.
+type Catalog struct {
+	apps map[string]AppRef
+}
+
+// NewCatalog creates an empty catalog
+func NewCatalog() *Catalog {
+	return &Catalog{apps: map[string]AppRef{}}
+}
+
+// Add indexes the app of an application file. A later file defining the same app replaces
+// the earlier one, as user configuration overrides the defaults.
+func (c *Catalog) Add(file *File) {
+	name, node := file.AppName()
+	if name == "" {
+		return
+	}
+	c.apps[strings.ToLower(name)] = AppRef{Name: name, File: file.Path, Line: node.Line, Column: node.Column}
+}
+
+// Lookup finds an app by name
+func (c *Catalog) Lookup(name string) (AppRef, bool) {
+	ref, ok := c.apps[strings.ToLower(strings.TrimSpace(name))]
+	return ref, ok
+}
+
+// Names returns the sorted names of the apps
+func (c *Catalog) Names() []string {
+	names := make([]string, 0, len(c.apps))
+	for _, ref := range c.apps {
+		names = append(names, ref.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check runs the checks of a file that depend on other files and the available plugins
+func (c *Catalog) Check(file *File, options Options) []Diagnostic {
+	root := DocumentRoot(file.Document)
+	if root == nil {
+		return nil
+	}
+
+	var diagnostics []Diagnostic
+	switch file.Kind {
+	case KindApp:
+		forEachOSConfig(root, func(config *yaml.Node, path string) {
+			diagnostics = append(diagnostics, c.checkAppReferences(config, path, "dependencies", "unknown dependency %q")...)
+			diagnostics = append(diagnostics, c.checkAppReferences(config, path, "conflicts", "conflict with unknown app %q")...)
+			if options.Plugins != nil {
+				diagnostics = append(diagnostics, checkInstallMethod(config, path, options.Plugins)...)
+			}
+		})
+	case KindSecurity:
+		_, overrides := MappingValue(root, "app_overrides")
+		if overrides != nil && overrides.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(overrides.Content); i += 2 {
+				key := overrides.Content[i]
+				if _, ok := c.Lookup(key.Value); !ok {
+					diagnostics = append(diagnostics, diagnosticAt(key, SeverityWarning, "app_overrides."+key.Value,
+						"overrides for unknown app %q%s", key.Value, suggestion(key.Value, c.Names())))
+				}
+			}
+		}
+	}
+
+	for i := range diagnostics {
+		diagnostics[i].File = file.Path
+	}
+	return diagnostics
+}
+
+func (c *Catalog) checkAppReferences(config *yaml.Node, path, key, format string) []Diagnostic {
+	_, list := MappingValue(config, key)
+	if list == nil || list.Kind != yaml.SequenceNode {
+		return nil
+	}
+	var diagnostics []Diagnostic
+	for i, item := range list.Content {
+		if item.Kind != yaml.ScalarNode || item.Value == "" {
+			continue
+		}
+		if _, ok := c.Lookup(item.Value); !ok {
+			diagnostics = append(diagnostics, diagnosticAt(item, SeverityError, fmt.Sprintf("%s.%s[%d]", path, key, i),
+				format+"%s", item.Value, suggestion(item.Value, c.Names())))
+		}
+	}
+	return diagnostics
+}
+
+// checkInstallMethod reports install methods no plugin provides. A method close to one a
+// plugin provides is most likely a typo and reported as an error.
+func checkInstallMethod(config *yaml.Node, path string, plugins map[string]bool) []Diagnostic {
+	_, method := MappingValue(config, "install_method")
+	if method == nil || method.Kind != yaml.ScalarNode || method.Value == "" {
+		return nil
+	}
+	if plugins[InstallMethodPluginPrefix+method.Value] {
+		return nil
+	}
+
+	field := path + ".install_method"
+	if closest := Closest(method.Value, InstallMethods(plugins)); closest != "" {
+		return []Diagnostic{diagnosticAt(method, SeverityError, field, "unknown install method %q, did you mean %q?", method.Value, closest)}
+	}
+	return []Diagnostic{diagnosticAt(method, SeverityWarning, field, "no plugin %s%s provides install method %q",
+		InstallMethodPluginPrefix, method.Value, method.Value)}
+}
+
+// InstallMethods returns the sorted install methods provided by plugins
+func InstallMethods(plugins map[string]bool) []string {
+	var methods []string
+	for name := range plugins {
+		if method, ok := strings.CutPrefix(name, InstallMethodPluginPrefix); ok {
+			methods = append(methods, method)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// forEachOSConfig calls fn with every OS configuration of an app, including alternatives
+func forEachOSConfig(root *yaml.Node, fn func(config *yaml.Node, path string)) {
+	for _, block := range osBlocks {
+		_, config := MappingValue(root, block)
+		if config == nil || config.Kind != yaml.MappingNode {
+			continue
+		}
+		fn(config, block)
+		_, alternatives := MappingValue(config, "alternatives")
+		if alternatives == nil || alternatives.Kind != yaml.SequenceNode {
+			continue
+		}
+		for i, alternative := range alternatives.Content {
+			if alternative.Kind == yaml.MappingNode {
+				fn(alternative, fmt.Sprintf("%s.alternatives[%d]", block, i))
+			}
+		}
+	}
+}
+
+// checkApp runs the checks of an application file beyond its schema
+func checkApp(root *yaml.Node) []Diagnostic {
+	var diagnostics []Diagnostic
+
+	hasPlatform := false
+	for _, block := range osBlocks {
+		_, config := MappingValue(root, block)
+		if _, method := MappingValue(config, "install_method"); method != nil && method.Value != "" {
+			hasPlatform = true
+		}
+		diagnostics = append(diagnostics, checkAlternatives(config, block)...)
+	}
+	if !hasPlatform {
+		diagnostics = append(diagnostics, diagnosticAt(root, SeverityError, "",
+			"no install_method for any of %s", strings.Join(osBlocks, ", ")))
+	}
+
+	_, version := MappingValue(root, "version")
+	diagnostics = append(diagnostics, checkVersionConstraint(version, "version")...)
+	forEachOSConfig(root, func(config *yaml.Node, path string) {
+		_, version := MappingValue(config, "version")
+		diagnostics = append(diagnostics, checkVersionConstraint(version, path+".version")...)
+
+		_, signature := MappingValue(config, "signature")
+		signatureKey, _ := MappingValue(config, "signature_key")
+		if signature != nil && signature.Value != "" && signatureKey == nil {
+			diagnostics = append(diagnostics, diagnosticAt(signature, SeverityError, path+".signature",
+				"a signature requires the signature_key that verifies it"))
+		}
+	})
+	return diagnostics
+}
+
+// checkAlternatives reports configurations that can never be selected. The best OS
+// configuration is the first whose platform_requirements match, or else the first without
+// platform_requirements, so any later configuration without requirements is unreachable.
+func checkAlternatives(config *yaml.Node, block string) []Diagnostic {
+	if config == nil || config.Kind != yaml.MappingNode {
+		return nil
+	}
+	_, alternatives := MappingValue(config, "alternatives")
+	if alternatives == nil || alternatives.Kind != yaml.SequenceNode || len(alternatives.Content) == 0 {
+		return nil
+	}
+
+	type candidate struct {
+		node *yaml.Node
+		path string
+	}
+	candidates := []candidate{{config, block}}
+	for i, alternative := range alternatives.Content {
+		candidates = append(candidates, candidate{alternative, fmt.Sprintf("%s.alternatives[%d]", block, i)})
+	}
+
+	var diagnostics []Diagnostic
+	fallback := ""
+	for _, c := range candidates {
+		requirementsKey, requirements := MappingValue(c.node, "platform_requirements")
+		if requirementsKey != nil && requirements.Kind == yaml.SequenceNode && len(requirements.Content) > 0 {
+			continue
+		}
+		methodKey, method := MappingValue(c.node, "install_method")
+		anchor := c.node
+		if methodKey != nil {
+			anchor = method
+		}
+
+		if fallback != "" {
+			diagnostics = append(diagnostics, diagnosticAt(anchor, SeverityWarning, c.path+".platform_requirements",
+				"missing platform_requirements, this configuration is never used because %s already applies to any platform", fallback))
+			continue
+		}
+		fallback = c.path
+		if method != nil && distributionPackageManagers[method.Value] && block != "macos" && block != "windows" {
+			diagnostics = append(diagnostics, diagnosticAt(anchor, SeverityWarning, c.path+".platform_requirements",
+				"missing platform_requirements, %s is also used on distributions without %s", method.Value, method.Value))
+		}
+	}
+	return diagnostics
+}
+
+func checkVersionConstraint(node *yaml.Node, path string) []Diagnostic {
+	if node == nil || node.Kind != yaml.ScalarNode || node.Value == "" {
+		return nil
+	}
+	if _, err := sdk.ParseVersionConstraint(node.Value); err != nil {
+		return []Diagnostic{diagnosticAt(node, SeverityError, path, "invalid version constraint %q: %v", node.Value, err)}
+	}
+	return nil
+}
+
+// checkSetup reports duplicate step IDs and navigation to steps that do not exist
+func checkSetup(root *yaml.Node) []Diagnostic {
+	_, steps := MappingValue(root, "steps")
+	if steps == nil || steps.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	var diagnostics []Diagnostic
+	ids := map[string]bool{}
+	var idList []string
+	for i, step := range steps.Content {
+		_, id := MappingValue(step, "id")
+		if id == nil || id.Value == "" {
+			continue
+		}
+		if ids[id.Value] {
+			diagnostics = append(diagnostics, diagnosticAt(id, SeverityError, fmt.Sprintf("steps[%d].id", i), "duplicate step id %q", id.Value))
+		}
+		ids[id.Value] = true
+		idList = append(idList, id.Value)
+	}
+
+	for i, step := range steps.Content {
+		_, navigation := MappingValue(step, "navigation")
+		path := fmt.Sprintf("steps[%d].navigation", i)
+		var targets []*yaml.Node
+		var fields []string
+		for _, key := range []string{"next_step", "prev_step"} {
+			if _, target := MappingValue(navigation, key); target != nil && target.Kind == yaml.ScalarNode && target.Value != "" {
+				targets, fields = append(targets, target), append(fields, path+"."+key)
+			}
+		}
+		if _, conditional := MappingValue(navigation, "next_step_if"); conditional != nil && conditional.Kind == yaml.MappingNode {
+			for j := 0; j+1 < len(conditional.Content); j += 2 {
+				targets = append(targets, conditional.Content[j+1])
+				fields = append(fields, path+".next_step_if."+conditional.Content[j].Value)
+			}
+		}
+		for j, target := range targets {
+			if !ids[target.Value] {
+				diagnostics = append(diagnostics, diagnosticAt(target, SeverityError, fields[j],
+					"unknown step %q%s", target.Value, suggestion(target.Value, idList)))
+			}
+		}
+	}
+	return diagnostics
+}
+
+// checkSecurity reports override patterns that are not valid regular expressions
+func checkSecurity(root *yaml.Node) []Diagnostic {
+	var diagnostics []Diagnostic
+	checkOverrides := func(list *yaml.Node, path string) {
+		if list == nil || list.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, override := range list.Content {
+			_, pattern := MappingValue(override, "pattern")
+			if pattern == nil || pattern.Kind != yaml.ScalarNode {
+				continue
+			}
+			if _, err := regexp.Compile(pattern.Value); err != nil {
+				diagnostics = append(diagnostics, diagnosticAt(pattern, SeverityError, fmt.Sprintf("%s[%d].pattern", path, i),
+					"invalid regular expression: %v", err))
+			}
+		}
+	}
+
+	_, global := MappingValue(root, "global_overrides")
+	checkOverrides(global, "global_overrides")
+	_, apps := MappingValue(root, "app_overrides")
+	if apps != nil && apps.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(apps.Content); i += 2 {
+			checkOverrides(apps.Content[i+1], "app_overrides."+apps.Content[i].Value)
+		}
+	}
+	return diagnostics
+}
+
+// Collect lists the configuration files below paths. Files named explicitly must be of a
+// known kind; directories are searched recursively for files of any known kind.
+func Collect(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			if _, ok := KindOf(path); !ok {
+				return nil, fmt.Errorf("%s is not an application, setup or security file", path)
+			}
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(p string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() && p != path && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if _, ok := KindOf(p); ok && !entry.IsDir() {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// Result is the outcome of linting a set of files
+type Result struct {
+	Files       []*File
+	Diagnostics []Diagnostic
+}
+
+// Count returns the number of diagnostics of a severity
+func (r *Result) Count(severity Severity) int {
+	count := 0
+	for _, d := range r.Diagnostics {
+		if d.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// Lint checks the configuration files below paths, each against its schema and all of them
+// against each other
+func Lint(paths []string, options Options) (*Result, error) {
+	names, err := Collect(paths)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, errors.New("no configuration files found")
+	}
+
+	result := &Result{}
+	catalog := NewCatalog()
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		kind, _ := KindOf(name)
+		file := ParseFile(name, data, kind)
+		catalog.Add(file)
+		result.Files = append(result.Files, file)
+	}
+
+	for _, file := range result.Files {
+		result.Diagnostics = append(result.Diagnostics, file.Diagnostics...)
+		result.Diagnostics = append(result.Diagnostics, catalog.Check(file, options)...)
+	}
+	SortDiagnostics(result.Diagnostics)
+	return result, nil
+}
@@ -0,0 +1,211 @@
+package configschema_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/configschema"
+)
+
+var _ = Describe("Lint", func() {
+	var dir string
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0750)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+		return path
+	}
+
+	lint := func(options configschema.Options) []configschema.Diagnostic {
+		result, err := configschema.Lint([]string{dir}, options)
+		Expect(err).NotTo(HaveOccurred())
+		return result.Diagnostics
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	It("accepts a valid catalog", func() {
+		write("applications/git.yaml", "name: git\ndescription: Version control\nlinux:\n  install_method: apt\n  install_command: git\n  platform_requirements:\n    - os: debian\n")
+		write("applications/gh.yaml", "name: gh\ndescription: GitHub CLI\nall_platforms:\n  install_method: mise\n  install_command: gh\n  dependencies:\n    - Git\n")
+
+		Expect(lint(configschema.Options{Plugins: map[string]bool{
+			"package-manager-apt":  true,
+			"package-manager-mise": true,
+		}})).To(BeEmpty())
+	})
+
+	It("reports schema problems with their positions", func() {
+		path := write("applications/bad.yaml", `name: bad
+description: Broken
+linux:
+  install_method: apt
+  install_comand: bad
+  official_support: maybe
+default: [true]
+`)
+
+		diagnostics := lint(configschema.Options{})
+		Expect(diagnostics).To(HaveLen(3))
+
+		Expect(diagnostics[0].File).To(Equal(path))
+		Expect(diagnostics[0].Line).To(Equal(5))
+		Expect(diagnostics[0].Column).To(Equal(3))
+		Expect(diagnostics[0].Field).To(Equal("linux.install_comand"))
+		Expect(diagnostics[0].Message).To(Equal(`unknown field "install_comand", did you mean "install_command"?`))
+
+		Expect(diagnostics[1].Line).To(Equal(6))
+		Expect(diagnostics[1].Column).To(Equal(21))
+		Expect(diagnostics[1].EndColumn).To(Equal(26))
+		Expect(diagnostics[1].Message).To(Equal(`expected true or false, got "maybe"`))
+
+		Expect(diagnostics[2].String()).To(Equal(path + `:7:10: error: default: expected true or false, got a list`))
+	})
+
+	It("reports missing required fields and syntax errors", func() {
+		write("applications/empty.yaml", "name: empty\nlinux:\n  install_command: empty\n")
+		write("applications/syntax.yaml", "name: syntax\n  description: indented\n")
+
+		diagnostics := lint(configschema.Options{})
+		messages := make([]string, len(diagnostics))
+		for i, d := range diagnostics {
+			messages[i] = d.Message
+		}
+		Expect(messages).To(ContainElements(
+			`missing required field "description"`,
+			`missing required field "install_method"`,
+			"no install_method for any of linux, macos, windows, all_platforms",
+		))
+		Expect(diagnostics[len(diagnostics)-1].File).To(HaveSuffix("syntax.yaml"))
+		Expect(diagnostics[len(diagnostics)-1].Line).To(Equal(2))
+		Expect(diagnostics[len(diagnostics)-1].Message).To(Equal("line 2: mapping values are not allowed in this context"))
+	})
+
+	It("checks references between apps", func() {
+		write("applications/editor.yaml", `name: editor
+description: Editor
+linux:
+  install_method: apt
+  install_command: editor
+  platform_requirements:
+    - os: debian
+  dependencies:
+    - gti
+  conflicts:
+    - other-editor
+`)
+		write("applications/git.yaml", "name: git\ndescription: Git\nmacos:\n  install_method: brew\n  install_command: git\n")
+
+		diagnostics := lint(configschema.Options{})
+		Expect(diagnostics).To(HaveLen(2))
+		Expect(diagnostics[0].Line).To(Equal(9))
+		Expect(diagnostics[0].Message).To(Equal(`unknown dependency "gti", did you mean "git"?`))
+		Expect(diagnostics[1].Field).To(Equal("linux.conflicts[0]"))
+		Expect(diagnostics[1].Message).To(Equal(`conflict with unknown app "other-editor"`))
+	})
+
+	It("checks install methods against the available plugins", func() {
+		write("applications/tool.yaml", `name: tool
+description: Tool
+macos:
+  install_method: brw
+  install_command: tool
+windows:
+  install_method: nix
+  install_command: tool
+`)
+
+		diagnostics := lint(configschema.Options{Plugins: map[string]bool{"package-manager-brew": true}})
+		Expect(diagnostics).To(HaveLen(2))
+		Expect(diagnostics[0].Severity).To(Equal(configschema.SeverityError))
+		Expect(diagnostics[0].Message).To(Equal(`unknown install method "brw", did you mean "brew"?`))
+		Expect(diagnostics[1].Severity).To(Equal(configschema.SeverityWarning))
+		Expect(diagnostics[1].Message).To(Equal(`no plugin package-manager-nix provides install method "nix"`))
+	})
+
+	It("reports alternatives that are never selected", func() {
+		write("applications/tool.yaml", `name: tool
+description: Tool
+macos:
+  install_method: brew
+  install_command: tool
+  alternatives:
+    - install_method: macports
+      install_command: tool
+    - install_method: mise
+      install_command: tool
+      platform_requirements:
+        - os: darwin
+          arch: arm64
+`)
+
+		diagnostics := lint(configschema.Options{})
+		Expect(diagnostics).To(HaveLen(1))
+		Expect(diagnostics[0].Severity).To(Equal(configschema.SeverityWarning))
+		Expect(diagnostics[0].Field).To(Equal("macos.alternatives[0].platform_requirements"))
+		Expect(diagnostics[0].Line).To(Equal(7))
+	})
+
+	It("checks setup navigation", func() {
+		write("setup.yaml", `steps:
+  - id: welcome
+    title: Welcome
+    type: info
+    info:
+      message: Hello
+    navigation:
+      next_step: langauges
+  - id: welcome
+    title: Again
+    type: question
+`)
+
+		diagnostics := lint(configschema.Options{})
+		Expect(diagnostics).To(HaveLen(2))
+		Expect(diagnostics[0].Line).To(Equal(8))
+		Expect(diagnostics[0].Message).To(Equal(`unknown step "langauges"`))
+		Expect(diagnostics[1].Message).To(Equal(`duplicate step id "welcome"`))
+	})
+
+	It("checks security overrides", func() {
+		write("applications/git.yaml", "name: git\ndescription: Git\nmacos:\n  install_method: brew\n")
+		write("security.yaml", `level: 4
+app_overrides:
+  git:
+    - rule_type: dangerous-command
+      pattern: "rm -rf ("
+      reason: Cleanup
+  gti:
+    - rule_type: dangerous-command
+      pattern: "^git"
+      reason: Typo
+`)
+
+		diagnostics := lint(configschema.Options{})
+		Expect(diagnostics).To(HaveLen(3))
+		Expect(diagnostics[0].Message).To(HavePrefix(`invalid value "4", expected one of 0, 1, 2, 3`))
+		Expect(diagnostics[1].Field).To(Equal("app_overrides.git[0].pattern"))
+		Expect(diagnostics[1].Message).To(HavePrefix("invalid regular expression"))
+		Expect(diagnostics[2].Severity).To(Equal(configschema.SeverityWarning))
+		Expect(diagnostics[2].Message).To(Equal(`overrides for unknown app "gti", did you mean "git"?`))
+	})
+
+	It("tells the kind of files from their paths", func() {
+		for path, expected := range map[string]configschema.Kind{
+			"config/applications/dev/git.yaml": configschema.KindApp,
+			"team/setup.yml":                   configschema.KindSetup,
+			"config/security.yaml":             configschema.KindSecurity,
+		} {
+			kind, ok := configschema.KindOf(path)
+			Expect(ok).To(BeTrue(), path)
+			Expect(kind).To(Equal(expected), path)
+		}
+		_, ok := configschema.KindOf("config/system/git.yaml")
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -0,0 +1,289 @@
+// Package configschema describes the DevEx configuration files with JSON Schemas generated
+// from their Go types and lints them with positions taken from the YAML source.
+package configschema
+
+//go:generate go run ../../tools config-docs
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jameswlane/devex/apps/cli/internal/security"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+// JSONSchemaDialect is the JSON Schema version of the generated schemas
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Kind identifies a kind of configuration file
+type Kind string
+
+const (
+	KindApp      Kind = "app"
+	KindSetup    Kind = "setup"
+	KindSecurity Kind = "security"
+)
+
+// Kinds lists the kinds of configuration files that have a schema
+var Kinds = []Kind{KindApp, KindSetup, KindSecurity}
+
+var rootTypes = map[Kind]reflect.Type{
+	KindApp:      reflect.TypeOf(types.CrossPlatformApp{}),
+	KindSetup:    reflect.TypeOf(types.SetupConfig{}),
+	KindSecurity: reflect.TypeOf(security.SecurityConfig{}),
+}
+
+var titles = map[Kind]string{
+	KindApp:      "DevEx application",
+	KindSetup:    "DevEx setup workflow",
+	KindSecurity: "DevEx security configuration",
+}
+
+// RootType returns the Go type a kind of configuration file is decoded into
+func RootType(kind Kind) (reflect.Type, bool) {
+	t, ok := rootTypes[kind]
+	return t, ok
+}
+
+// ParseKind parses the name of a configuration kind
+func ParseKind(name string) (Kind, error) {
+	for _, kind := range Kinds {
+		if string(kind) == name {
+			return kind, nil
+		}
+	}
+	names := make([]string, len(Kinds))
+	for i, kind := range Kinds {
+		names[i] = string(kind)
+	}
+	return "", fmt.Errorf("unknown configuration kind %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// TypeList is the "type" keyword of a schema, a single type or a list of alternatives
+type TypeList []string
+
+// MarshalJSON writes a single type as a string
+func (t TypeList) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// Schema is the subset of JSON Schema used to describe configuration files
+type Schema struct {
+	Schema      string   `json:"$schema,omitempty"`
+	Ref         string   `json:"$ref,omitempty"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Type        TypeList `json:"type,omitempty"`
+	Enum        []any    `json:"enum,omitempty"`
+	Pattern     string   `json:"pattern,omitempty"`
+
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	// AdditionalProperties is false for structs and the schema of the values for maps
+	AdditionalProperties any     `json:"additionalProperties,omitempty"`
+	Items                *Schema `json:"items,omitempty"`
+
+	Defs map[string]*Schema `json:"$defs,omitempty"`
+
+	// PropertyOrder lists Properties in the order of the struct fields
+	PropertyOrder []string `json:"-"`
+}
+
+// HasType reports whether the schema allows values of type name
+func (s *Schema) HasType(name string) bool {
+	for _, t := range s.Type {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// durationPattern matches the durations accepted by time.ParseDuration
+const durationPattern = `^-?([0-9]+(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+$|^0$`
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+var (
+	schemasMu sync.Mutex
+	schemas   = map[Kind]*Schema{}
+)
+
+// For returns the schema of a kind of configuration file
+func For(kind Kind) (*Schema, error) {
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+
+	if schema, ok := schemas[kind]; ok {
+		return schema, nil
+	}
+	t, ok := rootTypes[kind]
+	if !ok {
+		return nil, fmt.Errorf("no schema for configuration kind %q", kind)
+	}
+
+	g := &generator{defs: map[string]*Schema{}}
+	schema := g.structSchema(t)
+	schema.Schema = JSONSchemaDialect
+	schema.Title = titles[kind]
+	if len(g.defs) > 0 {
+		schema.Defs = g.defs
+	}
+	schemas[kind] = schema
+	return schema, nil
+}
+
+// generator builds schemas from Go types, collecting named structs in $defs
+type generator struct {
+	defs map[string]*Schema
+}
+
+// typeKey names a type as "package.Type" like the keys of the generated docs
+func typeKey(t reflect.Type) string {
+	if t.Name() == "" || t.PkgPath() == "" {
+		return ""
+	}
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	return pkg + "." + t.Name()
+}
+
+func (g *generator) schemaFor(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == durationType {
+		return &Schema{Type: TypeList{"string", "integer"}, Pattern: durationPattern}
+	}
+
+	var schema *Schema
+	switch t.Kind() {
+	case reflect.Struct:
+		if _, ok := g.defs[t.Name()]; !ok {
+			// Register before filling so recursive types such as Condition terminate
+			g.defs[t.Name()] = &Schema{}
+			*g.defs[t.Name()] = *g.structSchema(t)
+		}
+		return &Schema{Ref: "#/$defs/" + t.Name()}
+	case reflect.String:
+		schema = &Schema{Type: TypeList{"string"}}
+	case reflect.Bool:
+		schema = &Schema{Type: TypeList{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema = &Schema{Type: TypeList{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		schema = &Schema{Type: TypeList{"number"}}
+	case reflect.Slice, reflect.Array:
+		schema = &Schema{Type: TypeList{"array"}, Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		schema = &Schema{Type: TypeList{"object"}, AdditionalProperties: g.schemaFor(t.Elem())}
+	default:
+		// Interfaces accept any value
+		schema = &Schema{}
+	}
+
+	if key := typeKey(t); key != "" {
+		schema.Enum = typeEnums[key]
+	}
+	return schema
+}
+
+// structSchema describes a struct by the YAML names of its fields. Unknown fields are not
+// allowed since yaml.v3 silently drops them.
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{
+		Type:                 TypeList{"object"},
+		Description:          typeDocs[typeKey(t)],
+		Properties:           map[string]*Schema{},
+		AdditionalProperties: false,
+	}
+	g.addFields(schema, t)
+	return schema
+}
+
+func (g *generator) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, inline := yamlName(field)
+		if name == "-" {
+			continue
+		}
+		if inline {
+			g.addFields(schema, field.Type)
+			continue
+		}
+
+		property := g.schemaFor(field.Type)
+		property.Description = fieldDocs[typeKey(t)+"."+field.Name]
+		for _, option := range strings.Split(field.Tag.Get("jsonschema"), ",") {
+			switch {
+			case option == "required":
+				schema.Required = append(schema.Required, name)
+			case strings.HasPrefix(option, "pattern="):
+				property.Pattern = strings.TrimPrefix(option, "pattern=")
+			}
+		}
+		schema.Properties[name] = property
+		schema.PropertyOrder = append(schema.PropertyOrder, name)
+	}
+}
+
+// yamlName returns the key yaml.v3 uses for a field and whether the field is inlined
+func yamlName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("yaml")
+	name, options, _ := strings.Cut(tag, ",")
+	if strings.Contains(","+options+",", ",inline,") {
+		return "", true
+	}
+	if name == "" {
+		return strings.ToLower(field.Name), false
+	}
+	return name, false
+}
+
+// Resolve follows the $ref of a schema within root
+func (s *Schema) Resolve(root *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		ref := s.Ref
+		s = root.Defs[strings.TrimPrefix(ref, "#/$defs/")]
+		if s != nil && s.Ref == ref {
+			return nil
+		}
+	}
+	return s
+}
+
+// Property returns the schema of a property of an object schema and its description,
+// which is kept on the referencing property rather than on the shared definition
+func (s *Schema) Property(root *Schema, name string) (*Schema, string) {
+	resolved := s.Resolve(root)
+	if resolved == nil {
+		return nil, ""
+	}
+	if property, ok := resolved.Properties[name]; ok {
+		description := property.Description
+		target := property.Resolve(root)
+		if description == "" && target != nil {
+			description = target.Description
+		}
+		return target, description
+	}
+	if additional, ok := resolved.AdditionalProperties.(*Schema); ok {
+		return additional.Resolve(root), ""
+	}
+	return nil, ""
+}
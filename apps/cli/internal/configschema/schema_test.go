@@ -0,0 +1,66 @@
+package configschema_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/configschema"
+)
+
+var _ = Describe("Schema", func() {
+	It("describes applications with their YAML field names and comments", func() {
+		schema, err := configschema.For(configschema.KindApp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(schema.Schema).To(Equal(configschema.JSONSchemaDialect))
+		Expect(schema.Required).To(ConsistOf("name", "description"))
+		Expect(schema.AdditionalProperties).To(Equal(false))
+
+		linux, description := schema.Property(schema, "linux")
+		Expect(linux).NotTo(BeNil())
+		Expect(description).NotTo(BeEmpty())
+		Expect(linux.Required).To(ContainElement("install_method"))
+
+		method, description := linux.Property(schema, "install_method")
+		Expect(method.HasType("string")).To(BeTrue())
+		Expect(description).To(ContainSubstring("plugin"))
+	})
+
+	It("lists the constants of enum types", func() {
+		schema, err := configschema.For(configschema.KindSetup)
+		Expect(err).NotTo(HaveOccurred())
+
+		steps, _ := schema.Property(schema, "steps")
+		stepType, _ := steps.Items.Property(schema, "type")
+		Expect(stepType.Enum).To(ConsistOf("question", "info", "action"))
+
+		security, err := configschema.For(configschema.KindSecurity)
+		Expect(err).NotTo(HaveOccurred())
+		level, _ := security.Property(security, "level")
+		Expect(level.Enum).To(ConsistOf(0, 1, 2, 3))
+	})
+
+	It("marshals to JSON with shared definitions", func() {
+		schema, err := configschema.For(configschema.KindSetup)
+		Expect(err).NotTo(HaveOccurred())
+
+		data, err := json.Marshal(schema)
+		Expect(err).NotTo(HaveOccurred())
+
+		var decoded map[string]any
+		Expect(json.Unmarshal(data, &decoded)).To(Succeed())
+		Expect(decoded["type"]).To(Equal("object"))
+		Expect(decoded["$defs"]).To(HaveKey("Condition"))
+		Expect(string(data)).To(ContainSubstring(`"$ref":"#/$defs/Condition"`))
+	})
+
+	It("parses kind names", func() {
+		kind, err := configschema.ParseKind("security")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kind).To(Equal(configschema.KindSecurity))
+
+		_, err = configschema.ParseKind("system")
+		Expect(err).To(MatchError(ContainSubstring("app, setup, security")))
+	})
+})
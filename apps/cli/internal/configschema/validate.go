@@ -0,0 +1,338 @@
+package configschema
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity of a diagnostic
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a problem found in a configuration file. Lines and columns start at 1;
+// the end is exclusive and on the same line as the start.
+type Diagnostic struct {
+	File      string   `json:"file"`
+	Line      int      `json:"line"`
+	Column    int      `json:"column"`
+	EndColumn int      `json:"end_column,omitempty"`
+	Severity  Severity `json:"severity"`
+	Field     string   `json:"field,omitempty"` // Path of the field, e.g. linux.alternatives[1].install_method
+	Message   string   `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	location := fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+	if d.Field != "" {
+		return fmt.Sprintf("%s: %s: %s: %s", location, d.Severity, d.Field, d.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, d.Severity, d.Message)
+}
+
+// diagnosticAt creates a diagnostic spanning node, or its first line for collections
+func diagnosticAt(node *yaml.Node, severity Severity, field, format string, args ...any) Diagnostic {
+	d := Diagnostic{Line: node.Line, Column: node.Column, Severity: severity, Field: field, Message: fmt.Sprintf(format, args...)}
+	if node.Kind == yaml.ScalarNode && !strings.Contains(node.Value, "\n") {
+		d.EndColumn = node.Column + len([]rune(node.Value))
+		if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+			d.EndColumn += 2
+		}
+	}
+	return d
+}
+
+// SortDiagnostics orders diagnostics by file and position
+func SortDiagnostics(diagnostics []Diagnostic) {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i], diagnostics[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
+
+// Validate checks a parsed YAML document against a schema
+func Validate(schema *Schema, document *yaml.Node) []Diagnostic {
+	v := &validator{root: schema, patterns: map[string]*regexp.Regexp{}}
+	if node := DocumentRoot(document); node != nil {
+		v.validate(schema, node, "")
+	}
+	return v.diagnostics
+}
+
+type validator struct {
+	root        *Schema
+	patterns    map[string]*regexp.Regexp
+	diagnostics []Diagnostic
+}
+
+func (v *validator) report(node *yaml.Node, path, format string, args ...any) {
+	v.diagnostics = append(v.diagnostics, diagnosticAt(node, SeverityError, path, format, args...))
+}
+
+func (v *validator) validate(schema *Schema, node *yaml.Node, path string) {
+	schema = schema.Resolve(v.root)
+	if schema == nil {
+		return
+	}
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	// An empty value decodes to the zero value of any field
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+
+	if len(schema.Type) > 0 && !v.matchesType(schema, node) {
+		v.report(node, path, "expected %s, got %s", describeTypes(schema.Type), describeNode(node))
+		return
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		v.validateMapping(schema, node, path)
+	case yaml.SequenceNode:
+		if schema.Items != nil {
+			for i, item := range node.Content {
+				v.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	case yaml.ScalarNode:
+		v.validateScalar(schema, node, path)
+	}
+}
+
+func (v *validator) matchesType(schema *Schema, node *yaml.Node) bool {
+	for _, t := range schema.Type {
+		switch t {
+		case "object":
+			if node.Kind == yaml.MappingNode {
+				return true
+			}
+		case "array":
+			if node.Kind == yaml.SequenceNode {
+				return true
+			}
+		case "string":
+			// yaml.v3 decodes any scalar into a string field
+			if node.Kind == yaml.ScalarNode {
+				return true
+			}
+		case "integer":
+			if node.Kind == yaml.ScalarNode && node.Tag == "!!int" {
+				return true
+			}
+		case "number":
+			if node.Kind == yaml.ScalarNode && (node.Tag == "!!int" || node.Tag == "!!float") {
+				return true
+			}
+		case "boolean":
+			if node.Kind == yaml.ScalarNode && node.Tag == "!!bool" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (v *validator) validateMapping(schema *Schema, node *yaml.Node, path string) {
+	seen := map[string]bool{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Value == "<<" {
+			continue
+		}
+		fieldPath := joinPath(path, key.Value)
+		if seen[key.Value] {
+			v.report(key, fieldPath, "duplicate field %q", key.Value)
+			continue
+		}
+		seen[key.Value] = true
+
+		if property, ok := schema.Properties[key.Value]; ok {
+			v.validate(property, value, fieldPath)
+			continue
+		}
+		switch additional := schema.AdditionalProperties.(type) {
+		case *Schema:
+			v.validate(additional, value, fieldPath)
+		case bool:
+			if !additional {
+				v.report(key, fieldPath, "unknown field %q%s", key.Value, suggestion(key.Value, schema.PropertyOrder))
+			}
+		}
+	}
+
+	for _, name := range schema.Required {
+		if !seen[name] {
+			v.report(node, joinPath(path, name), "missing required field %q", name)
+		}
+	}
+}
+
+func (v *validator) validateScalar(schema *Schema, node *yaml.Node, path string) {
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, node) {
+		values := make([]string, len(schema.Enum))
+		for i, value := range schema.Enum {
+			values[i] = fmt.Sprint(value)
+		}
+		hint := ""
+		if node.Tag == "!!str" {
+			hint = suggestion(node.Value, values)
+		}
+		v.report(node, path, "invalid value %q, expected one of %s%s", node.Value, strings.Join(values, ", "), hint)
+	}
+
+	if schema.Pattern != "" && node.Tag == "!!str" {
+		pattern, ok := v.patterns[schema.Pattern]
+		if !ok {
+			pattern = regexp.MustCompile(schema.Pattern)
+			v.patterns[schema.Pattern] = pattern
+		}
+		if !pattern.MatchString(node.Value) {
+			v.report(node, path, "invalid value %q, expected a value matching %s", node.Value, schema.Pattern)
+		}
+	}
+}
+
+func enumContains(enum []any, node *yaml.Node) bool {
+	for _, value := range enum {
+		switch value := value.(type) {
+		case string:
+			if node.Value == value {
+				return true
+			}
+		case int:
+			if n, err := strconv.Atoi(node.Value); err == nil && n == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func describeTypes(types TypeList) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		switch t {
+		case "object":
+			names[i] = "a mapping"
+		case "array":
+			names[i] = "a list"
+		case "integer":
+			names[i] = "an integer"
+		case "boolean":
+			names[i] = "true or false"
+		default:
+			names[i] = "a " + t
+		}
+	}
+	return strings.Join(names, " or ")
+}
+
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	switch node.Tag {
+	case "!!int", "!!float":
+		return "the number " + node.Value
+	case "!!bool":
+		return node.Value
+	}
+	return fmt.Sprintf("%q", node.Value)
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// suggestion returns a "did you mean" hint for the candidate closest to value
+func suggestion(value string, candidates []string) string {
+	if closest := Closest(value, candidates); closest != "" {
+		return fmt.Sprintf(", did you mean %q?", closest)
+	}
+	return ""
+}
+
+// Closest returns the candidate within a small edit distance of value, or "" if none is
+func Closest(value string, candidates []string) string {
+	best, bestDistance := "", len(value)/3+2
+	for _, candidate := range candidates {
+		if candidate == value {
+			continue
+		}
+		if d := editDistance(strings.ToLower(value), strings.ToLower(candidate)); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance computes the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(br)]
+}
+
+// DocumentRoot returns the top-level value of a parsed document
+func DocumentRoot(node *yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return node.Content[0]
+	}
+	return node
+}
+
+// MappingValue returns the key and value nodes of key in a mapping node
+func MappingValue(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
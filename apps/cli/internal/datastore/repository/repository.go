@@ -11,9 +11,10 @@ import (
 )
 
 type repository struct {
-	appRepo    *AppRepository
-	systemRepo types.SystemRepository
-	db         types.Database
+	appRepo     *AppRepository
+	systemRepo  types.SystemRepository
+	sessionRepo types.SessionRepository
+	db          types.Database
 }
 
 // NewRepository initializes and returns a Repository instance
@@ -27,9 +28,10 @@ func NewRepository(db types.Database) types.Repository {
 	}
 
 	return &repository{
-		appRepo:    NewAppRepository(db),
-		systemRepo: NewSystemRepository(db),
-		db:         db,
+		appRepo:     NewAppRepository(db),
+		systemRepo:  NewSystemRepository(db),
+		sessionRepo: NewSessionRepository(db),
+		db:          db,
 	}
 }
 
@@ -142,3 +144,26 @@ func (r *repository) DeleteApp(name string) error {
 	log.Info("Deleting app from repository", "name", name)
 	return r.appRepo.RemoveApp(name)
 }
+
+// SessionRepository Methods
+func (r *repository) CreateSession(id string, apps []string) error {
+	log.Info("Creating installation session", "session", id, "apps", len(apps))
+	return r.sessionRepo.CreateSession(id, apps)
+}
+
+func (r *repository) UpdateSessionApp(sessionID, appName, status string, step types.InstallStep, errMsg string) error {
+	return r.sessionRepo.UpdateSessionApp(sessionID, appName, status, step, errMsg)
+}
+
+func (r *repository) SetSessionStatus(sessionID, status string) error {
+	log.Info("Updating installation session", "session", sessionID, "status", status)
+	return r.sessionRepo.SetSessionStatus(sessionID, status)
+}
+
+func (r *repository) GetSession(sessionID string) (*types.InstallSession, error) {
+	return r.sessionRepo.GetSession(sessionID)
+}
+
+func (r *repository) LatestIncompleteSession() (*types.InstallSession, error) {
+	return r.sessionRepo.LatestIncompleteSession()
+}
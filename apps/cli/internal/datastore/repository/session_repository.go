@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jameswlane/devex/apps/cli/internal/log"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+type sessionRepository struct {
+	db types.Database
+}
+
+// NewSessionRepository creates a repository for installation session journals
+func NewSessionRepository(db types.Database) types.SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+// CreateSession records a new running session with every app pending, in installation order
+func (r *sessionRepository) CreateSession(id string, apps []string) error {
+	log.Debug("Creating installation session", "session", id, "apps", len(apps))
+
+	ctx := context.Background()
+	tx, err := r.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin session transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO install_sessions (id, status, created_at, updated_at) VALUES (?, ?, ?, ?)`,
+		id, types.SessionStatusRunning, now, now); err != nil {
+		return fmt.Errorf("failed to create session '%s': %w", id, err)
+	}
+
+	for i, app := range apps {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO install_session_apps (session_id, position, app_name, status, updated_at) VALUES (?, ?, ?, ?, ?)`,
+			id, i, app, types.SessionAppPending, now); err != nil {
+			return fmt.Errorf("failed to record app '%s' in session '%s': %w", app, id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit session '%s': %w", id, err)
+	}
+	return nil
+}
+
+// UpdateSessionApp records the status, last finished step and error of an app in a session
+func (r *sessionRepository) UpdateSessionApp(sessionID, appName, status string, step types.InstallStep, errMsg string) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	result, err := r.db.Conn().ExecContext(ctx,
+		`UPDATE install_session_apps SET status = ?, step = ?, error = ?, updated_at = ? WHERE session_id = ? AND app_name = ?`,
+		status, string(step), errMsg, now, sessionID, appName)
+	if err != nil {
+		return fmt.Errorf("failed to update app '%s' in session '%s': %w", appName, sessionID, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("app '%s' is not part of session '%s'", appName, sessionID)
+	}
+
+	_, err = r.db.Conn().ExecContext(ctx, `UPDATE install_sessions SET updated_at = ? WHERE id = ?`, now, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to touch session '%s': %w", sessionID, err)
+	}
+	return nil
+}
+
+// SetSessionStatus updates the overall status of a session
+func (r *sessionRepository) SetSessionStatus(sessionID, status string) error {
+	log.Debug("Updating installation session status", "session", sessionID, "status", status)
+
+	ctx := context.Background()
+	result, err := r.db.Conn().ExecContext(ctx,
+		`UPDATE install_sessions SET status = ?, updated_at = ? WHERE id = ?`, status, time.Now(), sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to update session '%s': %w", sessionID, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("session '%s' not found", sessionID)
+	}
+	return nil
+}
+
+// GetSession loads a session and its apps. Returns nil if the session does not exist.
+func (r *sessionRepository) GetSession(sessionID string) (*types.InstallSession, error) {
+	ctx := context.Background()
+	session := &types.InstallSession{}
+
+	err := r.db.Conn().QueryRowContext(ctx,
+		`SELECT id, status, created_at, updated_at FROM install_sessions WHERE id = ?`, sessionID).
+		Scan(&session.ID, &session.Status, &session.CreatedAt, &session.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session '%s': %w", sessionID, err)
+	}
+
+	rows, err := r.db.Conn().QueryContext(ctx,
+		`SELECT app_name, position, status, step, error FROM install_session_apps WHERE session_id = ? ORDER BY position`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query apps of session '%s': %w", sessionID, err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			log.Error("Failed to close rows", err)
+		}
+	}(rows)
+
+	for rows.Next() {
+		var app types.InstallSessionApp
+		var step string
+		if err := rows.Scan(&app.AppName, &app.Position, &app.Status, &step, &app.Error); err != nil {
+			return nil, fmt.Errorf("failed to scan session app: %w", err)
+		}
+		app.Step = types.InstallStep(step)
+		session.Apps = append(session.Apps, app)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read apps of session '%s': %w", sessionID, err)
+	}
+
+	return session, nil
+}
+
+// LatestIncompleteSession returns the most recent session that did not complete, or nil if there is none.
+// Sessions left "running" are included: they belong to a run that crashed or was killed.
+func (r *sessionRepository) LatestIncompleteSession() (*types.InstallSession, error) {
+	ctx := context.Background()
+
+	var id string
+	err := r.db.Conn().QueryRowContext(ctx,
+		`SELECT id FROM install_sessions WHERE status IN (?, ?, ?) ORDER BY created_at DESC, rowid DESC LIMIT 1`,
+		types.SessionStatusRunning, types.SessionStatusInterrupted, types.SessionStatusFailed).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query incomplete sessions: %w", err)
+	}
+
+	return r.GetSession(id)
+}
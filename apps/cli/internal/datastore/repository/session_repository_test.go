@@ -0,0 +1,82 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jameswlane/devex/apps/cli/internal/datastore"
+	"github.com/jameswlane/devex/apps/cli/internal/datastore/repository"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+func TestSessionRepository_CreateAndGetSession(t *testing.T) {
+	db := datastore.NewInMemorySQLite()
+	defer db.Close()
+	repo := repository.NewSessionRepository(db)
+
+	require.NoError(t, repo.CreateSession("s1", []string{"git", "curl", "docker"}))
+
+	session, err := repo.GetSession("s1")
+	require.NoError(t, err)
+	require.NotNil(t, session)
+	assert.Equal(t, types.SessionStatusRunning, session.Status)
+	require.Len(t, session.Apps, 3)
+	assert.Equal(t, "git", session.Apps[0].AppName)
+	assert.Equal(t, "docker", session.Apps[2].AppName)
+	assert.Equal(t, types.SessionAppPending, session.Apps[1].Status)
+	assert.Equal(t, types.InstallStepNone, session.Apps[1].Step)
+
+	missing, err := repo.GetSession("missing")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestSessionRepository_UpdateSessionApp(t *testing.T) {
+	db := datastore.NewInMemorySQLite()
+	defer db.Close()
+	repo := repository.NewSessionRepository(db)
+	require.NoError(t, repo.CreateSession("s1", []string{"git", "docker"}))
+
+	require.NoError(t, repo.UpdateSessionApp("s1", "git", types.SessionAppCompleted, types.InstallStepRegister, ""))
+	require.NoError(t, repo.UpdateSessionApp("s1", "docker", types.SessionAppFailed, types.InstallStepDependencies, "post-install failed"))
+
+	session, err := repo.GetSession("s1")
+	require.NoError(t, err)
+	assert.Equal(t, types.SessionAppCompleted, session.Apps[0].Status)
+	assert.Equal(t, types.InstallStepRegister, session.Apps[0].Step)
+	assert.Equal(t, types.SessionAppFailed, session.Apps[1].Status)
+	assert.Equal(t, "post-install failed", session.Apps[1].Error)
+
+	err = repo.UpdateSessionApp("s1", "vim", types.SessionAppRunning, types.InstallStepNone, "")
+	assert.Error(t, err)
+}
+
+func TestSessionRepository_LatestIncompleteSession(t *testing.T) {
+	db := datastore.NewInMemorySQLite()
+	defer db.Close()
+	repo := repository.NewSessionRepository(db)
+
+	latest, err := repo.LatestIncompleteSession()
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+
+	require.NoError(t, repo.CreateSession("s1", []string{"git"}))
+	require.NoError(t, repo.SetSessionStatus("s1", types.SessionStatusInterrupted))
+	require.NoError(t, repo.CreateSession("s2", []string{"curl"}))
+	require.NoError(t, repo.SetSessionStatus("s2", types.SessionStatusCompleted))
+
+	latest, err = repo.LatestIncompleteSession()
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, "s1", latest.ID)
+	assert.True(t, latest.Incomplete())
+
+	require.NoError(t, repo.SetSessionStatus("s1", types.SessionStatusAbandoned))
+	latest, err = repo.LatestIncompleteSession()
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+
+	assert.Error(t, repo.SetSessionStatus("missing", types.SessionStatusCompleted))
+}
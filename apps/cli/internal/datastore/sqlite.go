@@ -47,6 +47,22 @@ func InitializeSchema(conn *sql.DB) error {
     CREATE TABLE IF NOT EXISTS schema_migrations (
         version INTEGER PRIMARY KEY,
         applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+    );
+    CREATE TABLE IF NOT EXISTS install_sessions (
+        id TEXT PRIMARY KEY,
+        status TEXT NOT NULL,
+        created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+        updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+    );
+    CREATE TABLE IF NOT EXISTS install_session_apps (
+        session_id TEXT NOT NULL,
+        position INTEGER NOT NULL,
+        app_name TEXT NOT NULL,
+        status TEXT NOT NULL,
+        step TEXT NOT NULL DEFAULT '',
+        error TEXT NOT NULL DEFAULT '',
+        updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (session_id, app_name)
     );`
 	ctx := context.Background()
 	_, err := conn.ExecContext(ctx, schema)
@@ -43,4 +43,41 @@ var _ = Describe("Installers Package", func() {
 		})
 	})
 
+	Describe("DockerContainerSpec", func() {
+		It("describes the container from the image and docker options", func() {
+			app := types.AppConfig{
+				BaseConfig:     types.BaseConfig{Name: "Redis"},
+				InstallMethod:  "docker",
+				InstallCommand: "redis:7",
+				DockerOptions: types.DockerOptions{
+					ContainerName: "redis",
+					Ports:         []string{"127.0.0.1:6379:6379"},
+					Volumes:       []string{"redis-data:/data"},
+					RestartPolicy: "unless-stopped",
+					Healthcheck:   &types.DockerHealthcheck{Test: "redis-cli ping", Retries: 5},
+					Stack:         "databases",
+				},
+			}
+
+			spec := installers.DockerContainerSpec(app)
+			Expect(spec.App).To(Equal("Redis"))
+			Expect(spec.Image).To(Equal("redis:7"))
+			Expect(spec.ContainerName()).To(Equal("redis"))
+			Expect(spec.Ports).To(Equal([]string{"127.0.0.1:6379:6379"}))
+			Expect(spec.Volumes).To(Equal([]string{"redis-data:/data"}))
+			Expect(spec.Healthcheck.Test).To(Equal("redis-cli ping"))
+			Expect(spec.Healthcheck.Retries).To(Equal(5))
+			Expect(spec.Stack).To(Equal("databases"))
+		})
+
+		It("derives the container name from the app when none is configured", func() {
+			app := types.AppConfig{
+				BaseConfig:     types.BaseConfig{Name: "My App"},
+				InstallMethod:  "docker",
+				InstallCommand: "nginx:latest",
+			}
+			Expect(installers.DockerContainerSpec(app).ContainerName()).To(Equal("devex-my-app"))
+		})
+	})
+
 })
@@ -2,9 +2,14 @@ package installers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os/exec"
+	"sort"
 	"strings"
 
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+
 	"github.com/jameswlane/devex/apps/cli/internal/bootstrap"
 	"github.com/jameswlane/devex/apps/cli/internal/config"
 	"github.com/jameswlane/devex/apps/cli/internal/log"
@@ -15,6 +20,9 @@ import (
 var pluginBootstrap *bootstrap.PluginBootstrap
 var testMode bool
 
+// ErrVersionPinningUnsupported is returned when an install method cannot resolve or report exact versions
+var ErrVersionPinningUnsupported = errors.New("version pinning is not supported")
+
 // InitializeWithPluginBootstrap initializes the installer system with plugin bootstrap
 func InitializeWithPluginBootstrap(pb *bootstrap.PluginBootstrap) {
 	pluginBootstrap = pb
@@ -33,6 +41,22 @@ func DisableTestMode() {
 	log.Debug("Test mode disabled for installer system")
 }
 
+// EnsurePermissions asks for consent to the permissions of the plugins that install apps. Call it
+// before a TUI takes over the terminal: plugins run from the TUI cannot ask.
+func EnsurePermissions(apps []types.CrossPlatformApp) error {
+	if testMode || pluginBootstrap == nil {
+		return nil
+	}
+
+	pluginNames := make([]string, 0, len(apps))
+	for _, app := range apps {
+		if method := app.GetOSConfig().InstallMethod; method != "" {
+			pluginNames = append(pluginNames, "package-manager-"+method)
+		}
+	}
+	return pluginBootstrap.EnsurePluginsPermissions(pluginNames)
+}
+
 // GetAvailableInstallers returns a list of available installer methods for the current platform
 func GetAvailableInstallers(ctx context.Context) []string {
 	if pluginBootstrap == nil {
@@ -96,13 +120,46 @@ func GetInstaller(ctx context.Context, method string) types.BaseInstaller {
 	}
 
 	return &PluginBasedInstaller{
+		ctx:             ctx,
 		method:          method,
 		pluginBootstrap: pluginBootstrap,
 	}
 }
 
+// GetVersionedInstaller returns the installer for method if it can pin package versions.
+// Returns ErrVersionPinningUnsupported for methods such as curlpipe that install unversioned artifacts.
+func GetVersionedInstaller(ctx context.Context, method string) (types.VersionedInstaller, error) {
+	installer, ok := GetInstaller(ctx, method).(types.VersionedInstaller)
+	if !ok {
+		return nil, fmt.Errorf("%w by install method '%s'", ErrVersionPinningUnsupported, method)
+	}
+	return installer, nil
+}
+
+// OutputFunc receives the messages plugins report while they run, with the levels of the
+// installer log ("DEBUG", "INFO", "WARN", "ERROR" and "STDOUT" for plain output)
+type OutputFunc func(level, message string)
+
+type outputKey struct{}
+
+// WithOutput returns a context whose installers report plugin messages to fn
+func WithOutput(ctx context.Context, fn OutputFunc) context.Context {
+	return context.WithValue(ctx, outputKey{}, fn)
+}
+
+// outputFrom returns the output function of a context, or nil
+func outputFrom(ctx context.Context) OutputFunc {
+	if ctx == nil {
+		return nil
+	}
+	fn, _ := ctx.Value(outputKey{}).(OutputFunc)
+	return fn
+}
+
 // PluginBasedInstaller wraps plugin execution in the BaseInstaller interface
 type PluginBasedInstaller struct {
+	// ctx is the operation the installer was created for, which plugin runs are traced under
+	ctx             context.Context
 	method          string
 	pluginBootstrap *bootstrap.PluginBootstrap
 }
@@ -113,21 +170,42 @@ func (p *PluginBasedInstaller) Install(command string, repo types.Repository) er
 		return fmt.Errorf("plugin bootstrap not initialized")
 	}
 
-	pluginName := "package-manager-" + p.method
-	args := []string{"install"}
+	packages := parsePackageArgs(command, "install")
+	if _, err := p.call(sdk.MethodInstall, packages); !errors.Is(err, sdk.ErrProtocolUnsupported) {
+		return err
+	}
 
-	// Parse command to extract package names
-	if command != "" {
-		// Simple command parsing - split on spaces and filter out common flags
-		parts := strings.Fields(command)
-		for _, part := range parts {
-			if !strings.HasPrefix(part, "-") && part != "install" && part != "sudo" {
-				args = append(args, part)
-			}
-		}
+	return p.pluginBootstrap.ExecutePluginWithContext(p.context(), p.pluginName(), append([]string{"install"}, packages...))
+}
+
+// InstallWithOptions executes the plugin install command with installer-specific options.
+// Legacy plugins receive the options as --name=value flags.
+func (p *PluginBasedInstaller) InstallWithOptions(command string, options map[string]string, repo types.Repository) error {
+	if p.pluginBootstrap == nil {
+		return fmt.Errorf("plugin bootstrap not initialized")
+	}
+
+	packages := parsePackageArgs(command, "install")
+	params := sdk.RPCParams{Packages: packages, Options: options}
+	if _, err := p.callWithParams(sdk.MethodInstall, params); !errors.Is(err, sdk.ErrProtocolUnsupported) {
+		return err
+	}
+
+	args := []string{"install"}
+	for _, name := range sortedOptionNames(options) {
+		args = append(args, fmt.Sprintf("--%s=%s", name, options[name]))
 	}
+	return p.pluginBootstrap.ExecutePluginWithContext(p.context(), p.pluginName(), append(args, packages...))
+}
 
-	return p.pluginBootstrap.ExecutePlugin(pluginName, args)
+// sortedOptionNames returns the option names in a stable order
+func sortedOptionNames(options map[string]string) []string {
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 // Uninstall executes the plugin remove command
@@ -136,20 +214,12 @@ func (p *PluginBasedInstaller) Uninstall(command string, repo types.Repository)
 		return fmt.Errorf("plugin bootstrap not initialized")
 	}
 
-	pluginName := "package-manager-" + p.method
-	args := []string{"remove"}
-
-	// Parse command to extract package names
-	if command != "" {
-		parts := strings.Fields(command)
-		for _, part := range parts {
-			if !strings.HasPrefix(part, "-") && part != "remove" && part != "sudo" {
-				args = append(args, part)
-			}
-		}
+	packages := parsePackageArgs(command, "remove")
+	if _, err := p.call(sdk.MethodRemove, packages); !errors.Is(err, sdk.ErrProtocolUnsupported) {
+		return err
 	}
 
-	return p.pluginBootstrap.ExecutePlugin(pluginName, args)
+	return p.pluginBootstrap.ExecutePluginWithContext(p.context(), p.pluginName(), append([]string{"remove"}, packages...))
 }
 
 // IsInstalled checks if a package is installed using the plugin
@@ -158,25 +228,283 @@ func (p *PluginBasedInstaller) IsInstalled(command string) (bool, error) {
 		return false, fmt.Errorf("plugin bootstrap not initialized")
 	}
 
-	pluginName := "package-manager-" + p.method
-	args := []string{"list"}
+	packages := parsePackageArgs(command, "install")
+	result, err := p.call(sdk.MethodIsInstalled, packages)
+	if err == nil {
+		if len(result.Installed) == 0 {
+			return false, nil
+		}
+		for _, installed := range result.Installed {
+			if !installed {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	if !errors.Is(err, sdk.ErrProtocolUnsupported) {
+		return false, err
+	}
+
+	// Legacy plugins report the result through the is-installed exit code contract
+	if !p.supportsCommand(sdk.MethodIsInstalled) {
+		return false, fmt.Errorf("plugin %s does not support is-installed", p.pluginName())
+	}
+	err = p.pluginBootstrap.ExecutePluginWithContext(p.context(), p.pluginName(), append([]string{sdk.MethodIsInstalled}, packages...))
+	return isInstalledFromExitCode(err)
+}
+
+// isInstalledFromExitCode interprets the result of a plugin's is-installed command:
+// exit code 0 means installed, 1 means not installed and anything else means the check failed.
+func isInstalledFromExitCode(err error) (bool, error) {
+	if err == nil {
+		return true, nil
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		switch exitErr.ExitCode() {
+		case sdk.ExitCodeNotInstalled:
+			return false, nil
+		case sdk.ExitCodeCheckFailed:
+			return false, fmt.Errorf("installation check failed: %w", err)
+		}
+	}
+	return false, fmt.Errorf("failed to run installation check: %w", err)
+}
+
+// supportsCommand reports whether the installed plugin declares the given command
+func (p *PluginBasedInstaller) supportsCommand(command string) bool {
+	metadata, exists := p.pluginBootstrap.GetManager().ListPlugins()[p.pluginName()]
+	if !exists {
+		return false
+	}
+	for _, cmd := range metadata.Commands {
+		if cmd.Name == command {
+			return true
+		}
+	}
+	return false
+}
+
+// pluginName returns the package manager plugin backing this installer
+func (p *PluginBasedInstaller) pluginName() string {
+	return "package-manager-" + p.method
+}
+
+// ResolveVersions asks the plugin for the newest version of each package matching constraint
+func (p *PluginBasedInstaller) ResolveVersions(command, constraint string) ([]types.PackageVersion, error) {
+	return p.versions(sdk.MethodResolve, command, constraint)
+}
 
-	// For now, we'll return true and let the plugin handle the check
-	// This is a simplified implementation
-	err := p.pluginBootstrap.ExecutePlugin(pluginName, args)
-	return err == nil, nil
+// InstalledVersions asks the plugin for the installed version of each package
+func (p *PluginBasedInstaller) InstalledVersions(command string) ([]types.PackageVersion, error) {
+	return p.versions(sdk.MethodVersion, command, "")
 }
 
-func executeInstallCommand(ctx context.Context, app types.AppConfig, repo types.Repository) error {
+// versions performs a resolve or version request. Plugins that do not declare the
+// command, or that only speak the legacy argument interface, cannot pin versions.
+func (p *PluginBasedInstaller) versions(method, command, constraint string) ([]types.PackageVersion, error) {
+	if p.pluginBootstrap == nil {
+		return nil, fmt.Errorf("plugin bootstrap not initialized")
+	}
+	if !p.supportsCommand(method) {
+		return nil, fmt.Errorf("%w: plugin %s does not support %s", ErrVersionPinningUnsupported, p.pluginName(), method)
+	}
+
+	packages := parsePackageArgs(command, "install")
+	result, err := p.callWithParams(method, sdk.RPCParams{Packages: packages, Constraint: constraint})
+	if errors.Is(err, sdk.ErrProtocolUnsupported) {
+		return nil, fmt.Errorf("%w: plugin %s does not support the plugin protocol", ErrVersionPinningUnsupported, p.pluginName())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]types.PackageVersion, 0, len(result.Packages))
+	for _, pkg := range result.Packages {
+		versions = append(versions, types.PackageVersion{
+			Name:    pkg.Name,
+			Version: pkg.Version,
+			Source:  pkg.Source,
+			Spec:    pkg.Spec,
+		})
+	}
+	return versions, nil
+}
+
+// context returns the context plugins run in
+func (p *PluginBasedInstaller) context() context.Context {
+	if p.ctx == nil {
+		return context.Background()
+	}
+	return p.ctx
+}
+
+// call sends a structured request to the plugin, reporting the events it streams back to the
+// output of the context. Returns sdk.ErrProtocolUnsupported for legacy plugins.
+func (p *PluginBasedInstaller) call(method string, packages []string) (*sdk.RPCResult, error) {
+	return p.callWithParams(method, sdk.RPCParams{Packages: packages})
+}
+
+// callWithParams is call with full request parameters
+func (p *PluginBasedInstaller) callWithParams(method string, params sdk.RPCParams) (*sdk.RPCResult, error) {
+	output := outputFrom(p.context())
+	req := sdk.NewRPCRequest(method, params)
+	result, err := p.pluginBootstrap.CallPlugin(p.context(), p.pluginName(), req, func(event sdk.RPCEvent) {
+		switch event.Method {
+		case sdk.EventProgress:
+			log.Debug("Plugin progress", "plugin", p.pluginName(), "progress", event.Params.Progress, "message", event.Params.Message)
+			if output != nil && event.Params.Message != "" {
+				output("INFO", fmt.Sprintf("%s (%d%%)", event.Params.Message, event.Params.Progress))
+			}
+		case sdk.EventLog:
+			log.Debug("Plugin log", "plugin", p.pluginName(), "level", event.Params.Level, "message", event.Params.Message)
+			if output != nil {
+				output(eventLogLevel(event.Params.Level), event.Params.Message)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Output != "" {
+		log.Debug("Plugin output", "plugin", p.pluginName(), "method", method, "output", result.Output)
+		if output != nil {
+			for _, line := range strings.Split(result.Output, "\n") {
+				output("STDOUT", line)
+			}
+		}
+	}
+	return result, nil
+}
+
+// eventLogLevel maps the level of a plugin log event to the installer log levels
+func eventLogLevel(level string) string {
+	switch strings.ToLower(level) {
+	case "debug":
+		return "DEBUG"
+	case "warn", "warning":
+		return "WARN"
+	case "error":
+		return "ERROR"
+	default:
+		return "INFO"
+	}
+}
+
+// parsePackageArgs extracts package names from an install command, dropping flags, sudo and the verb itself
+func parsePackageArgs(command, verb string) []string {
+	var packages []string
+	for _, part := range strings.Fields(command) {
+		if !strings.HasPrefix(part, "-") && part != verb && part != "sudo" {
+			packages = append(packages, part)
+		}
+	}
+	return packages
+}
+
+// RunInstallCommand runs the install command of an app with the installer of its install method
+func RunInstallCommand(ctx context.Context, app types.AppConfig, repo types.Repository) error {
+	return RunInstallCommandWithOptions(ctx, app, nil, repo)
+}
+
+// RunInstallCommandWithOptions runs the install command of an app with extra installer options
+// that take precedence over the options derived from the app, e.g. a local copy of an AppImage
+func RunInstallCommandWithOptions(ctx context.Context, app types.AppConfig, extra map[string]string, repo types.Repository) error {
 	installer := GetInstaller(ctx, app.InstallMethod)
 	if installer == nil {
 		log.Error("Unsupported install method", fmt.Errorf("method: %s", app.InstallMethod))
 		return fmt.Errorf("install method '%s' is not supported on this platform", app.InstallMethod)
 	}
 	log.Info("Executing installer", "method", app.InstallMethod)
+
+	options, err := installOptions(app)
+	if err != nil {
+		return err
+	}
+	for name, value := range extra {
+		if options == nil {
+			options = make(map[string]string, len(extra))
+		}
+		options[name] = value
+	}
+	if len(options) > 0 {
+		optionsInstaller, ok := installer.(types.OptionsInstaller)
+		if !ok {
+			return fmt.Errorf("install method '%s' does not support install options", app.InstallMethod)
+		}
+		return optionsInstaller.InstallWithOptions(app.InstallCommand, options, repo)
+	}
 	return installer.Install(app.InstallCommand, repo)
 }
 
+// installOptions returns the installer-specific options of an app
+func installOptions(app types.AppConfig) (map[string]string, error) {
+	switch app.InstallMethod {
+	case "docker":
+		spec, err := DockerContainerSpec(app).Encode()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{sdk.ContainerSpecOption: spec}, nil
+	case "appimage":
+		return appImageOptions(app), nil
+	case "curlpipe":
+		// The plugin refuses to run a script that does not match the pinned checksum
+		if app.SHA256 == "" {
+			return nil, nil
+		}
+		return map[string]string{"sha256": app.SHA256}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// appImageOptions passes the download URL and the checksum and signature that must match it
+func appImageOptions(app types.AppConfig) map[string]string {
+	options := make(map[string]string)
+	for name, value := range map[string]string{
+		"url":           app.DownloadURL,
+		"sha256":        app.SHA256,
+		"signature":     app.Signature,
+		"signature-key": app.SignatureKey,
+	} {
+		if value != "" {
+			options[name] = value
+		}
+	}
+	return options
+}
+
+// DockerContainerSpec describes the container of a docker app from its install command (the
+// image) and docker_options
+func DockerContainerSpec(app types.AppConfig) *sdk.ContainerSpec {
+	opts := app.DockerOptions
+	spec := &sdk.ContainerSpec{
+		App:           app.Name,
+		Image:         strings.TrimSpace(app.InstallCommand),
+		Name:          opts.ContainerName,
+		Ports:         opts.Ports,
+		Environment:   opts.Environment,
+		Volumes:       opts.Volumes,
+		RestartPolicy: opts.RestartPolicy,
+		Network:       opts.Network,
+		Command:       opts.Command,
+		Stack:         opts.Stack,
+	}
+	if hc := opts.Healthcheck; hc != nil {
+		spec.Healthcheck = &sdk.ContainerHealthcheck{
+			Test:        hc.Test,
+			Interval:    hc.Interval,
+			Timeout:     hc.Timeout,
+			StartPeriod: hc.StartPeriod,
+			Retries:     hc.Retries,
+		}
+	}
+	return spec
+}
+
 // InstallCrossPlatformApp installs a cross-platform application using the appropriate OS-specific configuration
 func InstallCrossPlatformApp(ctx context.Context, app types.CrossPlatformApp, settings config.CrossPlatformSettings, repo types.Repository) error {
 	log.Info("Installing cross-platform app", "app", app.Name)
@@ -212,7 +540,11 @@ func InstallCrossPlatformApp(ctx context.Context, app types.CrossPlatformApp, se
 		AptSources:       osConfig.AptSources,
 		CleanupFiles:     osConfig.CleanupFiles,
 		Conflicts:        osConfig.Conflicts,
+		DockerOptions:    osConfig.DockerOptions,
 		DownloadURL:      osConfig.DownloadURL,
+		SHA256:           osConfig.SHA256,
+		Signature:        osConfig.Signature,
+		SignatureKey:     osConfig.SignatureKey,
 		InstallDir:       osConfig.Destination,
 	}
 
@@ -230,7 +562,7 @@ func InstallApp(ctx context.Context, app types.AppConfig, settings config.CrossP
 	}
 
 	// Execute the actual install command
-	if err := executeInstallCommand(ctx, app, repo); err != nil {
+	if err := RunInstallCommand(ctx, app, repo); err != nil {
 		return fmt.Errorf("failed to execute install command: %w", err)
 	}
 
@@ -284,6 +616,13 @@ func (m *MockInstaller) Install(command string, repo types.Repository) error {
 	return nil
 }
 
+// InstallWithOptions simulates package installation with installer-specific options
+func (m *MockInstaller) InstallWithOptions(command string, options map[string]string, repo types.Repository) error {
+	log.Info("Mock install", "method", m.method, "command", command, "options", sortedOptionNames(options))
+	// Simulate successful installation in test mode
+	return nil
+}
+
 // Uninstall simulates package uninstallation
 func (m *MockInstaller) Uninstall(command string, repo types.Repository) error {
 	log.Info("Mock uninstall", "method", m.method, "command", command)
@@ -0,0 +1,57 @@
+package installers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+
+	"github.com/jameswlane/devex/apps/cli/internal/bootstrap"
+	"github.com/jameswlane/devex/apps/cli/internal/mocks"
+)
+
+var _ = Describe("PluginBasedInstaller", func() {
+	It("reports the events and output of protocol plugins to the context output", func() {
+		home := GinkgoT().TempDir()
+		GinkgoT().Setenv("HOME", home)
+		GinkgoT().Setenv("DEVEX_NONINTERACTIVE", "1")
+
+		pluginDir := filepath.Join(home, ".devex", "plugins")
+		Expect(os.MkdirAll(pluginDir, 0755)).To(Succeed())
+		info := `{"name":"package-manager-fake","version":"1.0.0","protocol_version":2,"permissions":{}}`
+		script := `#!/bin/sh
+if [ "$1" = --plugin-info ]; then echo '` + info + `'; exit 0; fi
+echo '{"jsonrpc":"2.0","method":"log","params":{"level":"warn","message":"mirror is slow"}}'
+echo '{"jsonrpc":"2.0","method":"progress","params":{"progress":50,"message":"Unpacking git"}}'
+printf '%s\n' '{"jsonrpc":"2.0","id":1,"result":{"output":"Setting up git\nDone"}}'
+`
+		Expect(os.WriteFile(filepath.Join(pluginDir, "devex-plugin-package-manager-fake"), []byte(script), 0755)).To(Succeed())
+		Expect(sdk.SavePermissionGrant(pluginDir, "package-manager-fake", &sdk.PermissionGrant{
+			Permissions: &sdk.PluginPermissions{},
+			GrantedAt:   time.Now(),
+		})).To(Succeed())
+
+		pb, err := bootstrap.NewPluginBootstrap(true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pb.Initialize(context.Background())).To(Succeed())
+
+		var lines []string
+		ctx := WithOutput(context.Background(), func(level, message string) {
+			lines = append(lines, level+" "+message)
+		})
+		installer := &PluginBasedInstaller{ctx: ctx, method: "fake", pluginBootstrap: pb}
+		Expect(installer.Install("git", mocks.NewMockRepository())).To(Succeed())
+
+		Expect(lines).To(Equal([]string{
+			"WARN mirror is slow",
+			"INFO Unpacking git (50%)",
+			"STDOUT Setting up git",
+			"STDOUT Done",
+		}))
+	})
+})
@@ -6,10 +6,17 @@ import (
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/jameswlane/devex/apps/cli/internal/log"
 	"github.com/jameswlane/devex/apps/cli/internal/utils"
 )
 
+var tracer = otel.Tracer("devex/installers/validation")
+
 // ValidationResult contains the result of a validation check
 type ValidationResult struct {
 	Check    string
@@ -125,6 +132,12 @@ type suiteResult struct {
 
 // runSuite executes all checks in a validation suite
 func (bv *BackgroundValidator) runSuite(ctx context.Context, suite ValidationSuite) []ValidationResult {
+	ctx, span := tracer.Start(ctx, "validation_suite", trace.WithAttributes(
+		attribute.String("suite", suite.Name),
+		attribute.Int("check_count", len(suite.Checks)),
+	))
+	defer span.End()
+
 	results := make([]ValidationResult, len(suite.Checks))
 
 	var wg sync.WaitGroup
@@ -137,11 +150,24 @@ func (bv *BackgroundValidator) runSuite(ctx context.Context, suite ValidationSui
 	}
 
 	wg.Wait()
+
+	for _, result := range results {
+		if !result.Success {
+			span.SetStatus(codes.Error, "Validation checks failed")
+			break
+		}
+	}
 	return results
 }
 
 // runCheck executes a single validation check
 func (bv *BackgroundValidator) runCheck(ctx context.Context, check ValidationCheck) ValidationResult {
+	ctx, span := tracer.Start(ctx, "validation_check", trace.WithAttributes(
+		attribute.String("check", check.Name),
+		attribute.Bool("critical", check.Critical),
+	))
+	defer span.End()
+
 	start := time.Now()
 	result := ValidationResult{
 		Check: check.Name,
@@ -182,6 +208,10 @@ func (bv *BackgroundValidator) runCheck(ctx context.Context, check ValidationChe
 	}
 
 	result.Duration = time.Since(start)
+	if !result.Success {
+		span.RecordError(result.Error)
+		span.SetStatus(codes.Error, result.Message)
+	}
 	return result
 }
 
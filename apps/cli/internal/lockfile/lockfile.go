@@ -0,0 +1,145 @@
+// Package lockfile reads and writes devex.lock, the record of the exact package versions
+// DevEx installed. Installing with --locked reproduces those versions on another machine.
+package lockfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// FileName is the name of the lockfile
+	FileName = "devex.lock"
+
+	// CurrentVersion is the lockfile format version written by this release
+	CurrentVersion = 1
+)
+
+// Lockfile records the exact versions installed for each app
+type Lockfile struct {
+	Version     int       `yaml:"version"`
+	GeneratedAt time.Time `yaml:"generated_at"`
+	Platform    string    `yaml:"platform"`
+	Apps        []App     `yaml:"apps"`
+}
+
+// App is the locked state of a single application
+type App struct {
+	Name          string    `yaml:"name"`
+	InstallMethod string    `yaml:"install_method"`
+	Constraint    string    `yaml:"constraint,omitempty"`
+	Packages      []Package `yaml:"packages"`
+}
+
+// Package is an exact package version and the repository it was installed from
+type Package struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+	Source  string `yaml:"source,omitempty"`
+}
+
+// New returns an empty lockfile for the current platform
+func New() *Lockfile {
+	return &Lockfile{
+		Version:  CurrentVersion,
+		Platform: runtime.GOOS + "/" + runtime.GOARCH,
+	}
+}
+
+// DefaultPath returns the lockfile location next to the user's DevEx configuration
+func DefaultPath(homeDir string) string {
+	return filepath.Join(homeDir, ".devex", FileName)
+}
+
+// Load reads a lockfile. Errors wrap os.ErrNotExist when the file does not exist.
+func Load(path string) (*Lockfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+
+	var lock Lockfile
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lockfile %s: %w", path, err)
+	}
+	if lock.Version > CurrentVersion {
+		return nil, fmt.Errorf("lockfile %s has version %d, this release supports up to %d", path, lock.Version, CurrentVersion)
+	}
+	return &lock, nil
+}
+
+// LoadOrNew reads a lockfile, returning an empty one if the file does not exist
+func LoadOrNew(path string) (*Lockfile, error) {
+	lock, err := Load(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return New(), nil
+	}
+	return lock, err
+}
+
+// Find returns the locked entry for an app
+func (l *Lockfile) Find(name string) (App, bool) {
+	for _, app := range l.Apps {
+		if app.Name == name {
+			return app, true
+		}
+	}
+	return App{}, false
+}
+
+// Upsert adds or replaces the entry for an app
+func (l *Lockfile) Upsert(app App) {
+	for i := range l.Apps {
+		if l.Apps[i].Name == app.Name {
+			l.Apps[i] = app
+			return
+		}
+	}
+	l.Apps = append(l.Apps, app)
+}
+
+// Save writes the lockfile atomically, ordering apps by name so diffs stay small
+func (l *Lockfile) Save(path string) error {
+	l.Version = CurrentVersion
+	l.GeneratedAt = time.Now().UTC().Truncate(time.Second)
+	if l.Platform == "" {
+		l.Platform = runtime.GOOS + "/" + runtime.GOARCH
+	}
+	sort.Slice(l.Apps, func(i, j int) bool { return l.Apps[i].Name < l.Apps[j].Name })
+
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("failed to encode lockfile: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create lockfile directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+FileName+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	return nil
+}
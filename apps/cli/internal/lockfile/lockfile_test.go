@@ -0,0 +1,66 @@
+package lockfile_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/lockfile"
+)
+
+var _ = Describe("Lockfile", func() {
+	var path string
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), ".devex", lockfile.FileName)
+	})
+
+	It("round-trips apps sorted by name", func() {
+		lock := lockfile.New()
+		lock.Upsert(lockfile.App{Name: "node", InstallMethod: "mise", Constraint: "20",
+			Packages: []lockfile.Package{{Name: "node@20", Version: "20.11.1", Source: "core:node"}}})
+		lock.Upsert(lockfile.App{Name: "git", InstallMethod: "apt",
+			Packages: []lockfile.Package{{Name: "git", Version: "1:2.43.0-1ubuntu7.1", Source: "http://archive.ubuntu.com/ubuntu noble-updates/main"}}})
+		Expect(lock.Save(path)).To(Succeed())
+
+		loaded, err := lockfile.Load(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loaded.Version).To(Equal(lockfile.CurrentVersion))
+		Expect(loaded.GeneratedAt.IsZero()).To(BeFalse())
+		Expect(loaded.Apps).To(HaveLen(2))
+		Expect(loaded.Apps[0].Name).To(Equal("git"))
+
+		node, ok := loaded.Find("node")
+		Expect(ok).To(BeTrue())
+		Expect(node.Constraint).To(Equal("20"))
+		Expect(node.Packages[0].Version).To(Equal("20.11.1"))
+	})
+
+	It("replaces an existing entry on upsert", func() {
+		lock := lockfile.New()
+		lock.Upsert(lockfile.App{Name: "git", InstallMethod: "apt", Packages: []lockfile.Package{{Name: "git", Version: "1"}}})
+		lock.Upsert(lockfile.App{Name: "git", InstallMethod: "apt", Packages: []lockfile.Package{{Name: "git", Version: "2"}}})
+
+		Expect(lock.Apps).To(HaveLen(1))
+		Expect(lock.Apps[0].Packages[0].Version).To(Equal("2"))
+	})
+
+	It("starts empty when no lockfile exists", func() {
+		_, err := lockfile.Load(path)
+		Expect(err).To(MatchError(os.ErrNotExist))
+
+		lock, err := lockfile.LoadOrNew(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lock.Apps).To(BeEmpty())
+	})
+
+	It("rejects lockfiles written by a newer release", func() {
+		Expect(os.MkdirAll(filepath.Dir(path), 0750)).To(Succeed())
+		Expect(os.WriteFile(path, []byte("version: 99\napps: []\n"), 0600)).To(Succeed())
+
+		_, err := lockfile.LoadOrNew(path)
+		Expect(err).To(MatchError(ContainSubstring("version 99")))
+	})
+})
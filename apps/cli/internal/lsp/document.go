@@ -0,0 +1,272 @@
+package lsp
+
+import (
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/jameswlane/devex/apps/cli/internal/configschema"
+)
+
+// itemSegment marks a sequence item in a path of keys
+const itemSegment = "[]"
+
+// document is a file opened in the editor
+type document struct {
+	uri     string
+	path    string
+	kind    configschema.Kind
+	known   bool // whether the file is a configuration file devex knows
+	version int
+	text    string
+	lines   []string
+}
+
+func newDocument(uri string, version int, text string) *document {
+	d := &document{uri: uri, path: uriToPath(uri), version: version}
+	d.kind, d.known = configschema.KindOf(d.path)
+	d.setText(text)
+	return d
+}
+
+func (d *document) setText(text string) {
+	d.text = text
+	d.lines = strings.Split(text, "\n")
+	for i, line := range d.lines {
+		d.lines[i] = strings.TrimSuffix(line, "\r")
+	}
+}
+
+func (d *document) line(n int) string {
+	if n < 0 || n >= len(d.lines) {
+		return ""
+	}
+	return d.lines[n]
+}
+
+// lspCharacter converts a zero-based rune column of a line to a UTF-16 offset
+func (d *document) lspCharacter(line, column int) int {
+	offset := 0
+	for i, r := range []rune(d.line(line)) {
+		if i >= column {
+			break
+		}
+		offset += utf16.RuneLen(r)
+	}
+	return offset
+}
+
+// runeColumn converts a UTF-16 offset of a line to a zero-based rune column
+func (d *document) runeColumn(line, character int) int {
+	column, offset := 0, 0
+	for _, r := range d.line(line) {
+		if offset >= character {
+			break
+		}
+		offset += utf16.RuneLen(r)
+		column++
+	}
+	return column
+}
+
+// lspRange converts a zero-based line and rune columns to a range
+func (d *document) lspRange(line, start, end int) Range {
+	return Range{
+		Start: Position{Line: line, Character: d.lspCharacter(line, start)},
+		End:   Position{Line: line, Character: d.lspCharacter(line, end)},
+	}
+}
+
+// yamlLine is the shape of a line of YAML as far as it locates the cursor. Columns count
+// runes from zero.
+type yamlLine struct {
+	blank    bool
+	dash     int // column of the "- " of a sequence item, or -1
+	indent   int // column of the key or value, after any "- "
+	key      string
+	hasColon bool
+	value    string // the text after "key:", or the whole content without a key
+	valueCol int
+}
+
+func parseYAMLLine(text string) yamlLine {
+	runes := []rune(text)
+	l := yamlLine{dash: -1}
+	i := 0
+	for i < len(runes) && runes[i] == ' ' {
+		i++
+	}
+	if i == len(runes) || runes[i] == '#' {
+		l.blank = true
+		l.indent = i
+		return l
+	}
+	for i < len(runes) && runes[i] == '-' && (i+1 == len(runes) || runes[i+1] == ' ') {
+		l.dash = i
+		i++
+		for i < len(runes) && runes[i] == ' ' {
+			i++
+		}
+	}
+	l.indent = i
+
+	content := string(runes[i:])
+	if comment := strings.Index(content, " #"); comment >= 0 {
+		content = content[:comment]
+	}
+	if key, value, ok := cutKey(content); ok {
+		l.key, l.hasColon = key, true
+		trimmed := strings.TrimLeft(value, " ")
+		l.value = strings.TrimRight(trimmed, " ")
+		l.valueCol = i + utf8.RuneCountInString(content) - utf8.RuneCountInString(trimmed)
+		return l
+	}
+	l.value = strings.TrimRight(content, " ")
+	l.valueCol = i
+	return l
+}
+
+// cutKey splits "key: value" at the colon of a plain or quoted key
+func cutKey(content string) (string, string, bool) {
+	if strings.HasPrefix(content, `"`) || strings.HasPrefix(content, "'") {
+		end := strings.IndexByte(content[1:], content[0])
+		if end < 0 || !strings.HasPrefix(content[end+2:], ":") {
+			return "", "", false
+		}
+		rest := content[end+3:]
+		if rest != "" && rest[0] != ' ' {
+			return "", "", false
+		}
+		return content[1 : end+1], rest, true
+	}
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case ':':
+			if i+1 == len(content) || content[i+1] == ' ' {
+				return content[:i], content[i+1:], i > 0
+			}
+		case ' ', '{', '[', '"', '\'':
+			if i == 0 || content[i] != ' ' {
+				return "", "", false
+			}
+		}
+	}
+	return "", "", false
+}
+
+// cursor describes what is under the cursor
+type cursor struct {
+	// path of keys and items leading to the mapping or sequence of the cursor line
+	path       []string
+	line       yamlLine
+	lineNumber int
+	// onKey tells whether the cursor is on the key of the line, or where a key is typed
+	onKey bool
+	// token is the word under the cursor and its rune columns
+	token      string
+	tokenStart int
+	tokenEnd   int
+}
+
+// cursorAt analyzes the cursor position from the indentation of the preceding lines, so it
+// also works while the document does not parse
+func (d *document) cursorAt(position Position) *cursor {
+	lines := make([]yamlLine, 0, position.Line+1)
+	for i := 0; i <= position.Line && i < len(d.lines); i++ {
+		lines = append(lines, parseYAMLLine(d.lines[i]))
+	}
+	if len(lines) <= position.Line {
+		return nil
+	}
+	column := d.runeColumn(position.Line, position.Character)
+
+	current := lines[position.Line]
+	if current.blank {
+		current.indent = column
+	}
+	c := &cursor{line: current, lineNumber: position.Line, path: parentPath(lines[:position.Line], current)}
+
+	switch {
+	case current.blank:
+		c.onKey, c.tokenStart, c.tokenEnd = true, column, column
+	case current.hasColon && column <= current.indent+utf8.RuneCountInString(current.key):
+		c.onKey, c.token = true, current.key
+		c.tokenStart, c.tokenEnd = current.indent, current.indent+utf8.RuneCountInString(current.key)
+	case current.hasColon:
+		c.token, c.tokenStart = current.value, current.valueCol
+		c.tokenEnd = current.valueCol + utf8.RuneCountInString(current.value)
+	default:
+		// A word without a colon is a key being typed, or the value of a sequence item
+		c.onKey = current.dash < 0
+		c.token, c.tokenStart = current.value, current.valueCol
+		c.tokenEnd = current.valueCol + utf8.RuneCountInString(current.value)
+	}
+	return c
+}
+
+// parentPath walks up the lines before current to the keys and items containing it.
+// Sequences may be indented like their key, as in "key:\n- item".
+func parentPath(lines []yamlLine, current yamlLine) []string {
+	var reversed []string
+	limit, inItem := current.indent, false
+	if current.dash >= 0 {
+		reversed = append(reversed, itemSegment)
+		limit, inItem = current.dash, true
+	}
+
+	for i := len(lines) - 1; i >= 0 && (limit > 0 || inItem); i-- {
+		l := lines[i]
+		if l.blank {
+			continue
+		}
+		switch {
+		case l.dash >= 0 && l.indent == limit && !inItem:
+			// The first entry of the sequence item holding the mapping
+			reversed = append(reversed, itemSegment)
+			limit, inItem = l.dash, true
+		case l.dash >= 0 && l.dash == limit && inItem:
+			// An earlier item of the same sequence
+		case l.hasColon && l.value == "" && (l.indent < limit || (inItem && l.dash < 0 && l.indent == limit)):
+			reversed = append(reversed, l.key)
+			limit, inItem = l.indent, false
+			if l.dash >= 0 {
+				reversed = append(reversed, itemSegment)
+				limit, inItem = l.dash, true
+			}
+		}
+	}
+
+	path := make([]string, len(reversed))
+	for i, segment := range reversed {
+		path[len(reversed)-1-i] = segment
+	}
+	return path
+}
+
+// uriToPath converts a file URI to a path
+func uriToPath(uri string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme != "file" {
+		return uri
+	}
+	path := parsed.Path
+	if runtime.GOOS == "windows" {
+		path = strings.TrimPrefix(path, "/")
+	}
+	return filepath.FromSlash(path)
+}
+
+// pathToURI converts a path to a file URI
+func pathToURI(path string) string {
+	if absolute, err := filepath.Abs(path); err == nil {
+		path = absolute
+	}
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
@@ -0,0 +1,71 @@
+package lsp
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cursor", func() {
+	const app = `name: editor
+linux:
+  install_method: apt
+  dependencies:
+  - git
+  alternatives:
+    - install_method: snap
+      platform_requirements:
+        - os: ubuntu
+          dependencies:
+            - snapd
+      conflicts:
+        - vim
+  instal
+`
+
+	cursorAt := func(line, character int) *cursor {
+		return newDocument("file:///catalog/applications/editor.yaml", 1, app).cursorAt(Position{Line: line, Character: character})
+	}
+
+	It("finds the keys and items leading to a line", func() {
+		Expect(cursorAt(2, 5).path).To(Equal([]string{"linux"}))
+		Expect(cursorAt(4, 4).path).To(Equal([]string{"linux", "dependencies", "[]"}))
+		Expect(cursorAt(6, 10).path).To(Equal([]string{"linux", "alternatives", "[]"}))
+		Expect(cursorAt(7, 8).path).To(Equal([]string{"linux", "alternatives", "[]"}))
+		Expect(cursorAt(10, 16).path).To(Equal([]string{"linux", "alternatives", "[]", "platform_requirements", "[]", "dependencies", "[]"}))
+		Expect(cursorAt(12, 10).path).To(Equal([]string{"linux", "alternatives", "[]", "conflicts", "[]"}))
+	})
+
+	It("tells keys from values", func() {
+		c := cursorAt(2, 4)
+		Expect(c.onKey).To(BeTrue())
+		Expect(c.token).To(Equal("install_method"))
+
+		c = cursorAt(2, 19)
+		Expect(c.onKey).To(BeFalse())
+		Expect(c.token).To(Equal("apt"))
+		Expect(c.tokenStart).To(Equal(18))
+		Expect(c.tokenEnd).To(Equal(21))
+
+		c = cursorAt(4, 4)
+		Expect(c.onKey).To(BeFalse())
+		Expect(c.token).To(Equal("git"))
+
+		// A key being typed does not parse as YAML yet
+		c = cursorAt(13, 8)
+		Expect(c.onKey).To(BeTrue())
+		Expect(c.token).To(Equal("instal"))
+		Expect(c.path).To(Equal([]string{"linux"}))
+	})
+
+	It("converts columns to UTF-16 offsets", func() {
+		doc := newDocument("file:///catalog/applications/emoji.yaml", 1, "description: 🚀 fast\n")
+		Expect(doc.lspCharacter(0, 15)).To(Equal(16))
+		Expect(doc.runeColumn(0, 16)).To(Equal(15))
+	})
+
+	It("converts between paths and URIs", func() {
+		uri := pathToURI("/catalog/applications/my editor.yaml")
+		Expect(uri).To(Equal("file:///catalog/applications/my%20editor.yaml"))
+		Expect(uriToPath(uri)).To(Equal("/catalog/applications/my editor.yaml"))
+	})
+})
@@ -0,0 +1,544 @@
+// Package lsp implements a language server for DevEx configuration files. It speaks the
+// Language Server Protocol over stdio and builds on the schemas and lint checks of the
+// configschema package.
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jameswlane/devex/apps/cli/internal/configschema"
+)
+
+// Options configures the language server
+type Options struct {
+	// Version of the server reported to the editor
+	Version string
+	// Dirs are configuration directories indexed along with the workspace folders, so apps
+	// defined there resolve as dependencies
+	Dirs []string
+	// Plugins lists the available plugins. It is called once in the background since it may
+	// query the plugin registry. Install methods are not checked while it returns nil.
+	Plugins func(ctx context.Context) map[string]bool
+}
+
+// Server is a language server for DevEx configuration files
+type Server struct {
+	options Options
+	conn    *conn
+
+	mu        sync.Mutex
+	workspace map[string]*configschema.File // files on disk by path
+	documents map[string]*document          // open documents by URI
+	plugins   map[string]bool
+	shutdown  bool
+}
+
+// NewServer creates a language server
+func NewServer(options Options) *Server {
+	return &Server{
+		options:   options,
+		workspace: map[string]*configschema.File{},
+		documents: map[string]*document{},
+	}
+}
+
+// errExit stops serving after the exit notification
+var errExit = errors.New("exit")
+
+// Serve answers the messages read from in until the client exits or in is closed
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.conn = newConn(in, out)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for {
+		req, err := s.conn.read()
+		var responseErr *ResponseError
+		switch {
+		case errors.As(err, &responseErr):
+			if err := s.conn.reply(json.RawMessage("null"), nil, responseErr); err != nil {
+				return err
+			}
+			continue
+		case errors.Is(err, io.EOF):
+			return nil
+		case err != nil:
+			return err
+		}
+
+		result, err := s.handle(ctx, req)
+		if errors.Is(err, errExit) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if !s.shutdown {
+				return errors.New("exit without shutdown")
+			}
+			return nil
+		}
+		if len(req.ID) == 0 {
+			continue
+		}
+		if err := s.conn.reply(req.ID, result, err); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(ctx context.Context, req *request) (any, error) {
+	switch req.Method {
+	case "initialize":
+		var params InitializeParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.initialize(&params), nil
+	case "initialized":
+		s.loadPlugins(ctx)
+		return nil, nil
+	case "shutdown":
+		s.mu.Lock()
+		s.shutdown = true
+		s.mu.Unlock()
+		return nil, nil
+	case "exit":
+		return nil, errExit
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		item := params.TextDocument
+		s.mu.Lock()
+		s.documents[item.URI] = newDocument(item.URI, item.Version, item.Text)
+		s.mu.Unlock()
+		return nil, s.publishAll()
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		doc, ok := s.documents[params.TextDocument.URI]
+		if ok && len(params.ContentChanges) > 0 {
+			// Changes are full documents as announced in the capabilities
+			doc.setText(params.ContentChanges[len(params.ContentChanges)-1].Text)
+			doc.version = params.TextDocument.Version
+		}
+		s.mu.Unlock()
+		return nil, s.publishAll()
+	case "textDocument/didSave":
+		var params DidCloseTextDocumentParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		s.reloadFile(uriToPath(params.TextDocument.URI))
+		return nil, nil
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		delete(s.documents, params.TextDocument.URI)
+		s.mu.Unlock()
+		s.reloadFile(uriToPath(params.TextDocument.URI))
+		if err := s.conn.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+			URI: params.TextDocument.URI, Diagnostics: []Diagnostic{},
+		}); err != nil {
+			return nil, err
+		}
+		return nil, s.publishAll()
+	case "textDocument/completion":
+		return s.withPosition(req.Params, s.completion)
+	case "textDocument/hover":
+		return s.withPosition(req.Params, s.hover)
+	case "textDocument/definition":
+		return s.withPosition(req.Params, s.definition)
+	}
+
+	if len(req.ID) == 0 {
+		// Notifications the server does not handle, such as $/cancelRequest, are ignored
+		return nil, nil
+	}
+	return nil, &ResponseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q is not supported", req.Method)}
+}
+
+func decodeParams(params json.RawMessage, v any) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &ResponseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// withPosition runs a request on the cursor position of an open document
+func (s *Server) withPosition(raw json.RawMessage, fn func(doc *document, c *cursor, schema *configschema.Schema) any) (any, error) {
+	var params TextDocumentPositionParams
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc, ok := s.documents[params.TextDocument.URI]
+	if !ok || !doc.known {
+		return nil, nil
+	}
+	schema, err := configschema.For(doc.kind)
+	if err != nil {
+		return nil, err
+	}
+	c := doc.cursorAt(params.Position)
+	if c == nil {
+		return nil, nil
+	}
+	return fn(doc, c, schema), nil
+}
+
+func (s *Server) initialize(params *InitializeParams) *InitializeResult {
+	roots := append([]string{}, s.options.Dirs...)
+	for _, folder := range params.WorkspaceFolders {
+		roots = append(roots, uriToPath(folder.URI))
+	}
+	if len(params.WorkspaceFolders) == 0 && params.RootURI != "" {
+		roots = append(roots, uriToPath(params.RootURI))
+	}
+
+	s.mu.Lock()
+	for _, root := range roots {
+		// Roots without configuration files, or that disappeared, are skipped
+		files, err := configschema.Collect([]string{root})
+		if err != nil {
+			continue
+		}
+		for _, path := range files {
+			s.loadFileLocked(path)
+		}
+	}
+	s.mu.Unlock()
+
+	return &InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync:   TextDocumentSyncOptions{OpenClose: true, Change: syncFull, Save: true},
+			CompletionProvider: CompletionOptions{TriggerCharacters: []string{":", " ", "-"}},
+			HoverProvider:      true,
+			DefinitionProvider: true,
+		},
+		ServerInfo: ServerInfo{Name: "devex", Version: s.options.Version},
+	}
+}
+
+// loadPlugins lists the plugins in the background and diagnoses the open documents again
+// once they are known
+func (s *Server) loadPlugins(ctx context.Context) {
+	if s.options.Plugins == nil {
+		return
+	}
+	go func() {
+		plugins := s.options.Plugins(ctx)
+		if plugins == nil || ctx.Err() != nil {
+			return
+		}
+		s.mu.Lock()
+		s.plugins = plugins
+		s.mu.Unlock()
+		_ = s.publishAll()
+	}()
+}
+
+// reloadFile reads a workspace file again after it was saved or closed
+func (s *Server) reloadFile(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loadFileLocked(path)
+}
+
+func (s *Server) loadFileLocked(path string) {
+	kind, ok := configschema.KindOf(path)
+	if !ok {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		delete(s.workspace, path)
+		return
+	}
+	s.workspace[path] = configschema.ParseFile(path, data, kind)
+}
+
+// catalogLocked indexes the apps of the workspace, with open documents replacing their
+// version on disk
+func (s *Server) catalogLocked() (*configschema.Catalog, map[string]*configschema.File) {
+	files := make(map[string]*configschema.File, len(s.workspace)+len(s.documents))
+	for path, file := range s.workspace {
+		files[path] = file
+	}
+	for _, doc := range s.documents {
+		if doc.known {
+			files[doc.path] = configschema.ParseFile(doc.path, []byte(doc.text), doc.kind)
+		}
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	catalog := configschema.NewCatalog()
+	for _, path := range paths {
+		catalog.Add(files[path])
+	}
+	return catalog, files
+}
+
+// publishAll diagnoses every open document, since a change to one app may resolve or break
+// references in the others
+func (s *Server) publishAll() error {
+	s.mu.Lock()
+	catalog, files := s.catalogLocked()
+	options := configschema.Options{Plugins: s.plugins}
+	var notifications []*PublishDiagnosticsParams
+	for _, doc := range s.documents {
+		if !doc.known {
+			continue
+		}
+		file := files[doc.path]
+		diagnostics := append(append([]configschema.Diagnostic{}, file.Diagnostics...), catalog.Check(file, options)...)
+		configschema.SortDiagnostics(diagnostics)
+
+		version := doc.version
+		params := &PublishDiagnosticsParams{URI: doc.uri, Version: &version, Diagnostics: []Diagnostic{}}
+		for _, d := range diagnostics {
+			params.Diagnostics = append(params.Diagnostics, doc.lspDiagnostic(d))
+		}
+		notifications = append(notifications, params)
+	}
+	s.mu.Unlock()
+
+	for _, params := range notifications {
+		if err := s.conn.notify("textDocument/publishDiagnostics", params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lspDiagnostic converts a lint diagnostic, which has one-based positions in runes
+func (d *document) lspDiagnostic(diagnostic configschema.Diagnostic) Diagnostic {
+	line := max(diagnostic.Line-1, 0)
+	start := max(diagnostic.Column-1, 0)
+	end := len([]rune(d.line(line)))
+	if diagnostic.EndColumn > 0 {
+		end = diagnostic.EndColumn - 1
+	}
+	if end <= start {
+		end = start + 1
+	}
+
+	severity := severityError
+	if diagnostic.Severity == configschema.SeverityWarning {
+		severity = severityWarning
+	}
+	return Diagnostic{Range: d.lspRange(line, start, end), Severity: severity, Source: "devex", Message: diagnostic.Message}
+}
+
+// schemaAt follows a path of keys and items from the root schema
+func schemaAt(root *configschema.Schema, path []string) *configschema.Schema {
+	schema := root
+	for _, segment := range path {
+		if segment == itemSegment {
+			resolved := schema.Resolve(root)
+			if resolved == nil {
+				return nil
+			}
+			schema = resolved.Items
+			continue
+		}
+		schema, _ = schema.Property(root, segment)
+	}
+	return schema.Resolve(root)
+}
+
+// referencesApps tells whether the items at path name apps: the dependencies and conflicts
+// of an OS configuration. Platform requirements also have dependencies, which are packages.
+func referencesApps(doc *document, root *configschema.Schema, path []string) bool {
+	n := len(path)
+	if doc.kind != configschema.KindApp || n < 2 || path[n-1] != itemSegment {
+		return false
+	}
+	if path[n-2] != "dependencies" && path[n-2] != "conflicts" {
+		return false
+	}
+	return schemaAt(root, path[:n-2]) == root.Defs["OSConfig"]
+}
+
+// isInstallMethod tells whether a key of the mapping at path is an install method
+func isInstallMethod(doc *document, root *configschema.Schema, path []string, key string) bool {
+	return doc.kind == configschema.KindApp && key == "install_method" && schemaAt(root, path) == root.Defs["OSConfig"]
+}
+
+func (s *Server) completion(doc *document, c *cursor, root *configschema.Schema) any {
+	list := &CompletionList{Items: []CompletionItem{}}
+	line := []rune(doc.line(c.lineNumber))
+	replace := func(text string) *TextEdit {
+		// A value typed right after the colon needs a space to be YAML
+		if c.tokenStart > 0 && c.tokenStart <= len(line) && line[c.tokenStart-1] == ':' {
+			text = " " + text
+		}
+		return &TextEdit{Range: doc.lspRange(c.lineNumber, c.tokenStart, c.tokenEnd), NewText: text}
+	}
+
+	mapping := schemaAt(root, c.path)
+	isMapping := mapping != nil && mapping.HasType("object") && mapping.Properties != nil
+	switch {
+	case isMapping && (c.onKey || !c.line.hasColon):
+		for _, name := range mapping.PropertyOrder {
+			property, description := mapping.Property(root, name)
+			text := name + ": "
+			if property != nil && (property.HasType("object") || property.HasType("array")) {
+				text = name + ":"
+			}
+			item := CompletionItem{Label: name, Kind: completionKindProperty, Detail: typeName(mapping.Properties[name]), TextEdit: replace(text)}
+			if description != "" {
+				item.Documentation = markdown(description)
+			}
+			list.Items = append(list.Items, item)
+		}
+	case c.onKey:
+	case c.line.hasColon && isInstallMethod(doc, root, c.path, c.line.key):
+		for _, method := range configschema.InstallMethods(s.plugins) {
+			list.Items = append(list.Items, CompletionItem{
+				Label: method, Kind: completionKindValue, Detail: configschema.InstallMethodPluginPrefix + method, TextEdit: replace(method),
+			})
+		}
+	case c.line.hasColon:
+		property, _ := mapping.Property(root, c.line.key)
+		list.Items = append(list.Items, valueItems(property, replace)...)
+	case referencesApps(doc, root, c.path):
+		catalog, files := s.catalogLocked()
+		own, _ := files[doc.path].AppName()
+		for _, name := range catalog.Names() {
+			if strings.EqualFold(name, own) {
+				continue
+			}
+			ref, _ := catalog.Lookup(name)
+			list.Items = append(list.Items, CompletionItem{Label: name, Kind: completionKindReference, Detail: ref.File, TextEdit: replace(name)})
+		}
+	default:
+		list.Items = append(list.Items, valueItems(mapping, replace)...)
+	}
+	return list
+}
+
+// valueItems suggests the enum values of a schema, or true and false for booleans
+func valueItems(schema *configschema.Schema, replace func(string) *TextEdit) []CompletionItem {
+	if schema == nil {
+		return nil
+	}
+	var values []string
+	for _, value := range schema.Enum {
+		values = append(values, fmt.Sprint(value))
+	}
+	if len(values) == 0 && schema.HasType("boolean") {
+		values = []string{"true", "false"}
+	}
+	items := make([]CompletionItem, len(values))
+	for i, value := range values {
+		items[i] = CompletionItem{Label: value, Kind: completionKindValue, TextEdit: replace(value)}
+	}
+	return items
+}
+
+// typeName describes the type of a property for hovers and completion details
+func typeName(schema *configschema.Schema) string {
+	switch {
+	case schema == nil:
+		return ""
+	case schema.Ref != "":
+		return strings.TrimPrefix(schema.Ref, "#/$defs/")
+	case schema.HasType("array"):
+		return "list of " + typeName(schema.Items)
+	case schema.HasType("object"):
+		if values, ok := schema.AdditionalProperties.(*configschema.Schema); ok {
+			return "map of " + typeName(values)
+		}
+		return "mapping"
+	case len(schema.Type) == 0:
+		return "any"
+	}
+	return strings.Join(schema.Type, " or ")
+}
+
+func (s *Server) hover(doc *document, c *cursor, root *configschema.Schema) any {
+	hoverRange := doc.lspRange(c.lineNumber, c.tokenStart, c.tokenEnd)
+
+	// The value of a dependency or conflict describes the app it names
+	if !c.onKey && !c.line.hasColon && referencesApps(doc, root, c.path) {
+		catalog, files := s.catalogLocked()
+		ref, ok := catalog.Lookup(strings.Trim(c.token, `"'`))
+		if !ok {
+			return nil
+		}
+		text := fmt.Sprintf("**%s**", ref.Name)
+		if file := files[ref.File]; file != nil {
+			_, description := configschema.MappingValue(configschema.DocumentRoot(file.Document), "description")
+			if description != nil && description.Value != "" {
+				text += "\n\n" + description.Value
+			}
+		}
+		text += fmt.Sprintf("\n\nDefined in `%s`", ref.File)
+		return &Hover{Contents: *markdown(text), Range: &hoverRange}
+	}
+
+	if !c.line.hasColon {
+		return nil
+	}
+	mapping := schemaAt(root, c.path)
+	if mapping == nil || mapping.Properties == nil {
+		return nil
+	}
+	raw, ok := mapping.Properties[c.line.key]
+	if !ok {
+		return nil
+	}
+	property, description := mapping.Property(root, c.line.key)
+
+	text := fmt.Sprintf("**%s**: `%s`", c.line.key, typeName(raw))
+	if description != "" {
+		text += "\n\n" + description
+	}
+	if property != nil && len(property.Enum) > 0 {
+		values := make([]string, len(property.Enum))
+		for i, value := range property.Enum {
+			values[i] = fmt.Sprintf("`%v`", value)
+		}
+		text += "\n\nOne of " + strings.Join(values, ", ")
+	}
+	if c.onKey {
+		return &Hover{Contents: *markdown(text), Range: &hoverRange}
+	}
+	return &Hover{Contents: *markdown(text)}
+}
+
+func (s *Server) definition(doc *document, c *cursor, root *configschema.Schema) any {
+	if c.onKey || c.line.hasColon || !referencesApps(doc, root, c.path) {
+		return nil
+	}
+	catalog, _ := s.catalogLocked()
+	ref, ok := catalog.Lookup(strings.Trim(c.token, `"'`))
+	if !ok {
+		return nil
+	}
+	position := Position{Line: ref.Line - 1, Character: ref.Column - 1}
+	if target := s.documents[pathToURI(ref.File)]; target != nil {
+		position.Character = target.lspCharacter(position.Line, position.Character)
+	}
+	return []Location{{URI: pathToURI(ref.File), Range: Range{Start: position, End: position}}}
+}
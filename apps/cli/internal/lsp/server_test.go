@@ -0,0 +1,253 @@
+package lsp_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/lsp"
+)
+
+// client talks to a server over pipes like an editor
+type client struct {
+	writer    *io.PipeWriter
+	responses chan json.RawMessage
+	nextID    int
+
+	mu          sync.Mutex
+	diagnostics map[string][]lsp.Diagnostic
+}
+
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *lsp.ResponseError
+}
+
+func (c *client) send(message any) {
+	body, err := json.Marshal(message)
+	Expect(err).NotTo(HaveOccurred())
+	_, err = fmt.Fprintf(c.writer, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	Expect(err).NotTo(HaveOccurred())
+}
+
+func (c *client) notify(method string, params any) {
+	c.send(map[string]any{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+func (c *client) call(method string, params any, result any) {
+	c.nextID++
+	c.send(map[string]any{"jsonrpc": "2.0", "id": c.nextID, "method": method, "params": params})
+	var raw json.RawMessage
+	Eventually(c.responses).Should(Receive(&raw))
+	var response message
+	Expect(json.Unmarshal(raw, &response)).To(Succeed())
+	Expect(response.Error).To(BeNil())
+	Expect(string(response.ID)).To(Equal(strconv.Itoa(c.nextID)))
+	if result != nil {
+		Expect(json.Unmarshal(response.Result, result)).To(Succeed())
+	}
+}
+
+func (c *client) read(reader *bufio.Reader) {
+	for {
+		header, err := textproto.NewReader(reader).ReadMIMEHeader()
+		if err != nil {
+			return
+		}
+		length, _ := strconv.Atoi(header.Get("Content-Length"))
+		body := make([]byte, length)
+		if _, err := io.ReadFull(reader, body); err != nil {
+			return
+		}
+
+		var msg message
+		Expect(json.Unmarshal(body, &msg)).To(Succeed())
+		if msg.Method == "textDocument/publishDiagnostics" {
+			var params lsp.PublishDiagnosticsParams
+			Expect(json.Unmarshal(msg.Params, &params)).To(Succeed())
+			c.mu.Lock()
+			c.diagnostics[params.URI] = params.Diagnostics
+			c.mu.Unlock()
+			continue
+		}
+		c.responses <- body
+	}
+}
+
+func (c *client) diagnosticsOf(uri string) func() []lsp.Diagnostic {
+	return func() []lsp.Diagnostic {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.diagnostics[uri]
+	}
+}
+
+func position(uri string, line, character int) map[string]any {
+	return map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"position":     map[string]any{"line": line, "character": character},
+	}
+}
+
+func labels(list *lsp.CompletionList) []string {
+	names := make([]string, len(list.Items))
+	for i, item := range list.Items {
+		names[i] = item.Label
+	}
+	return names
+}
+
+const editorYAML = `name: editor
+description: Text editor
+linux:
+  install_method: apt
+  install_comand: editor
+  dependencies:
+    - git
+`
+
+var _ = Describe("Server", func() {
+	var (
+		dir       string
+		editorURI string
+		c         *client
+		served    chan error
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		Expect(os.MkdirAll(filepath.Join(dir, "applications"), 0750)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "applications", "git.yaml"),
+			[]byte("name: git\ndescription: Distributed version control\nmacos:\n  install_method: brew\n"), 0600)).To(Succeed())
+		editorPath := filepath.Join(dir, "applications", "editor.yaml")
+		Expect(os.WriteFile(editorPath, []byte(editorYAML), 0600)).To(Succeed())
+		editorURI = "file://" + editorPath
+
+		server := lsp.NewServer(lsp.Options{
+			Version: "test",
+			Plugins: func(ctx context.Context) map[string]bool {
+				return map[string]bool{"package-manager-apt": true, "package-manager-brew": true, "tool-git": true}
+			},
+		})
+		serverIn, clientOut := io.Pipe()
+		clientIn, serverOut := io.Pipe()
+		c = &client{writer: clientOut, responses: make(chan json.RawMessage, 10), diagnostics: map[string][]lsp.Diagnostic{}}
+		go c.read(bufio.NewReader(clientIn))
+
+		done := make(chan error, 1)
+		served = done
+		go func() {
+			done <- server.Serve(context.Background(), serverIn, serverOut)
+			_ = serverOut.Close()
+		}()
+		DeferCleanup(func() {
+			_ = clientOut.Close()
+		})
+
+		var result lsp.InitializeResult
+		c.call("initialize", map[string]any{"rootUri": "file://" + dir}, &result)
+		Expect(result.ServerInfo.Name).To(Equal("devex"))
+		Expect(result.Capabilities.HoverProvider).To(BeTrue())
+		Expect(result.Capabilities.DefinitionProvider).To(BeTrue())
+		c.notify("initialized", map[string]any{})
+
+		c.notify("textDocument/didOpen", map[string]any{"textDocument": map[string]any{
+			"uri": editorURI, "languageId": "yaml", "version": 1, "text": editorYAML,
+		}})
+	})
+
+	It("diagnoses documents as they change", func() {
+		Eventually(c.diagnosticsOf(editorURI)).Should(HaveLen(1))
+		diagnostic := c.diagnosticsOf(editorURI)()[0]
+		Expect(diagnostic.Message).To(Equal(`unknown field "install_comand", did you mean "install_command"?`))
+		Expect(diagnostic.Severity).To(Equal(1))
+		Expect(diagnostic.Range).To(Equal(lsp.Range{
+			Start: lsp.Position{Line: 4, Character: 2},
+			End:   lsp.Position{Line: 4, Character: 16},
+		}))
+
+		changed := "name: editor\ndescription: Text editor\nlinux:\n  install_method: aptt\n  dependencies:\n    - gti\n"
+		c.notify("textDocument/didChange", map[string]any{
+			"textDocument":   map[string]any{"uri": editorURI, "version": 2},
+			"contentChanges": []map[string]any{{"text": changed}},
+		})
+		Eventually(func() []string {
+			var messages []string
+			for _, d := range c.diagnosticsOf(editorURI)() {
+				messages = append(messages, d.Message)
+			}
+			return messages
+		}).Should(ConsistOf(
+			`unknown install method "aptt", did you mean "apt"?`,
+			`unknown dependency "gti", did you mean "git"?`,
+		))
+	})
+
+	It("completes install methods from the plugins", func() {
+		Eventually(func() []string {
+			var list lsp.CompletionList
+			c.call("textDocument/completion", position(editorURI, 3, 19), &list)
+			return labels(&list)
+		}).Should(Equal([]string{"apt", "brew"}))
+	})
+
+	It("completes app names in dependencies", func() {
+		var list lsp.CompletionList
+		c.call("textDocument/completion", position(editorURI, 6, 7), &list)
+		Expect(labels(&list)).To(Equal([]string{"git"}))
+		Expect(list.Items[0].TextEdit.Range.Start).To(Equal(lsp.Position{Line: 6, Character: 6}))
+	})
+
+	It("completes keys with their documentation", func() {
+		var list lsp.CompletionList
+		c.call("textDocument/completion", position(editorURI, 4, 4), &list)
+		Expect(labels(&list)).To(ContainElements("install_method", "install_command", "alternatives"))
+		for _, item := range list.Items {
+			if item.Label == "alternatives" {
+				Expect(item.Detail).To(Equal("list of OSConfig"))
+				Expect(item.Documentation.Value).To(ContainSubstring("platform_requirements"))
+			}
+		}
+	})
+
+	It("shows the documentation of fields on hover", func() {
+		var hover lsp.Hover
+		c.call("textDocument/hover", position(editorURI, 3, 5), &hover)
+		Expect(hover.Contents.Kind).To(Equal("markdown"))
+		Expect(hover.Contents.Value).To(HavePrefix("**install_method**: `string`"))
+		Expect(hover.Contents.Value).To(ContainSubstring("plugin"))
+	})
+
+	It("describes the app of a dependency on hover", func() {
+		var hover lsp.Hover
+		c.call("textDocument/hover", position(editorURI, 6, 7), &hover)
+		Expect(hover.Contents.Value).To(ContainSubstring("Distributed version control"))
+	})
+
+	It("jumps from a dependency to the file of the app", func() {
+		var locations []lsp.Location
+		c.call("textDocument/definition", position(editorURI, 6, 7), &locations)
+		Expect(locations).To(HaveLen(1))
+		Expect(locations[0].URI).To(Equal("file://" + filepath.Join(dir, "applications", "git.yaml")))
+		Expect(locations[0].Range.Start).To(Equal(lsp.Position{Line: 0, Character: 6}))
+	})
+
+	It("exits after shutdown", func() {
+		c.call("shutdown", nil, nil)
+		c.notify("exit", nil)
+		Eventually(served).Should(Receive(BeNil()))
+	})
+})
@@ -3,6 +3,7 @@ package metrics
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -65,6 +66,11 @@ type Metric struct {
 	Error     error
 }
 
+// IsDuration tells whether the metric measures how long an operation took
+func (m Metric) IsDuration() bool {
+	return strings.HasSuffix(string(m.Type), ".duration")
+}
+
 // Collector is the interface for metrics collection
 type Collector interface {
 	Record(metric Metric)
@@ -169,6 +175,16 @@ func (c *InMemoryCollector) GetStats() Stats {
 	return stats
 }
 
+// Metrics returns a copy of the recorded metrics
+func (c *InMemoryCollector) Metrics() []Metric {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	metrics := make([]Metric, len(c.metrics))
+	copy(metrics, c.metrics)
+	return metrics
+}
+
 // Reset clears all metrics and statistics
 func (c *InMemoryCollector) Reset() {
 	c.mu.Lock()
@@ -263,6 +279,14 @@ func GetStats() Stats {
 	return Stats{}
 }
 
+// Snapshot returns the metrics recorded by the global collector, or nil if it does not keep them
+func Snapshot() []Metric {
+	if lister, ok := globalCollector.(interface{ Metrics() []Metric }); ok {
+		return lister.Metrics()
+	}
+	return nil
+}
+
 // InstallationTimer helps track installation duration
 type InstallationTimer struct {
 	startTime   time.Time
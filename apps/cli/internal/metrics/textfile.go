@@ -0,0 +1,195 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// textfilePrefix prefixes the names of the metrics written for Prometheus
+const textfilePrefix = "devex_"
+
+// WriteTextfile writes metrics in the Prometheus text format to path, which the textfile
+// collector of the node exporter reads from files named *.prom. Counts become counters and
+// durations become summaries in seconds. They are added to the values already in the file,
+// so they keep increasing across runs like those of a long-running process.
+func WriteTextfile(path string, metrics []Metric) error {
+	series, err := readTextfile(path)
+	if err != nil {
+		return err
+	}
+
+	for _, metric := range metrics {
+		name := textfilePrefix + sanitizeName(string(metric.Type))
+		labels := formatLabels(metric.Tags)
+		if metric.IsDuration() {
+			series[name+"_seconds_sum"+labels] += metric.Duration.Seconds()
+			series[name+"_seconds_count"+labels]++
+			continue
+		}
+		series[name+"_total"+labels] += metric.Value
+	}
+
+	data := renderTextfile(series, time.Now())
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+	// The node exporter may read the file at any time, so it is replaced at once
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return nil
+}
+
+// readTextfile reads the counters and summaries of a previous run, keyed by series
+func readTextfile(path string) (map[string]float64, error) {
+	series := map[string]float64{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return series, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metrics: %w", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Label values may contain spaces, the value is after the last one
+		separator := strings.LastIndexByte(line, ' ')
+		if separator < 0 {
+			continue
+		}
+		key := line[:separator]
+		if _, kind := family(key); kind == "gauge" {
+			continue
+		}
+		value, err := strconv.ParseFloat(line[separator+1:], 64)
+		if err != nil {
+			continue
+		}
+		series[key] = value
+	}
+	return series, scanner.Err()
+}
+
+// renderTextfile formats series grouped by metric family, followed by the time of the run
+func renderTextfile(series map[string]float64, now time.Time) []byte {
+	keys := make([]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	families := map[string][]string{}
+	kinds := map[string]string{}
+	var names []string
+	for _, key := range keys {
+		name, kind := family(key)
+		if _, seen := families[name]; !seen {
+			names = append(names, name)
+			kinds[name] = kind
+		}
+		families[name] = append(families[name], key)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "# TYPE %s %s\n", name, kinds[name])
+		for _, key := range families[name] {
+			fmt.Fprintf(&b, "%s %s\n", key, strconv.FormatFloat(series[key], 'g', -1, 64))
+		}
+	}
+
+	lastRun := textfilePrefix + "last_run_timestamp_seconds"
+	fmt.Fprintf(&b, "# HELP %s Time the last DevEx command finished.\n", lastRun)
+	fmt.Fprintf(&b, "# TYPE %s gauge\n", lastRun)
+	fmt.Fprintf(&b, "%s %d\n", lastRun, now.Unix())
+	return []byte(b.String())
+}
+
+// family returns the metric family of a series and its type
+func family(key string) (string, string) {
+	name := key
+	if brace := strings.IndexByte(key, '{'); brace >= 0 {
+		name = key[:brace]
+	}
+	switch {
+	case strings.HasSuffix(name, "_total"):
+		return name, "counter"
+	case strings.HasSuffix(name, "_sum"):
+		return strings.TrimSuffix(name, "_sum"), "summary"
+	case strings.HasSuffix(name, "_count"):
+		return strings.TrimSuffix(name, "_count"), "summary"
+	default:
+		return name, "gauge"
+	}
+}
+
+// formatLabels formats tags as Prometheus labels, sorted by name
+func formatLabels(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	labels := make([]string, 0, len(names))
+	for _, name := range names {
+		label := sanitizeName(name)
+		// Names starting with __ are reserved for Prometheus
+		if strings.HasPrefix(label, "__") {
+			continue
+		}
+		labels = append(labels, label+`="`+labelValueEscaper.Replace(tags[name])+`"`)
+	}
+	if len(labels) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(labels, ",") + "}"
+}
+
+// labelValueEscaper escapes label values as the Prometheus text format requires
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// sanitizeName replaces the characters a Prometheus metric or label name cannot contain
+func sanitizeName(name string) string {
+	sanitized := []byte(name)
+	for i, c := range sanitized {
+		valid := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9')
+		if !valid {
+			sanitized[i] = '_'
+		}
+	}
+	return string(sanitized)
+}
@@ -0,0 +1,106 @@
+package metrics_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/metrics"
+)
+
+var _ = Describe("Prometheus textfile", func() {
+	var (
+		collector *metrics.InMemoryCollector
+		path      string
+	)
+
+	BeforeEach(func() {
+		collector = metrics.NewInMemoryCollector()
+		path = filepath.Join(GinkgoT().TempDir(), "textfile", "devex.prom")
+	})
+
+	recordInstall := func(pkg string, duration time.Duration) {
+		tags := map[string]string{"installer": "apt", "package": pkg}
+		collector.RecordCount(metrics.MetricInstallStarted, tags)
+		collector.RecordCount(metrics.MetricInstallSucceeded, tags)
+		collector.RecordDuration(metrics.MetricInstallDuration, duration, tags)
+	}
+
+	readLines := func() []string {
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		return strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	}
+
+	It("writes counts as counters and durations as summaries in seconds", func() {
+		recordInstall("git", 1500*time.Millisecond)
+		recordInstall("git", 500*time.Millisecond)
+
+		Expect(metrics.WriteTextfile(path, collector.Metrics())).To(Succeed())
+
+		lines := readLines()
+		Expect(lines).To(ContainElements(
+			"# TYPE devex_install_started_total counter",
+			`devex_install_started_total{installer="apt",package="git"} 2`,
+			"# TYPE devex_install_duration_seconds summary",
+			`devex_install_duration_seconds_sum{installer="apt",package="git"} 2`,
+			`devex_install_duration_seconds_count{installer="apt",package="git"} 2`,
+			"# TYPE devex_last_run_timestamp_seconds gauge",
+		))
+		Expect(lines[len(lines)-1]).To(HavePrefix("devex_last_run_timestamp_seconds "))
+	})
+
+	It("adds to the counters of previous runs", func() {
+		recordInstall("git", time.Second)
+		Expect(metrics.WriteTextfile(path, collector.Metrics())).To(Succeed())
+
+		collector.Reset()
+		recordInstall("git", time.Second)
+		recordInstall("curl", time.Second)
+		Expect(metrics.WriteTextfile(path, collector.Metrics())).To(Succeed())
+
+		lines := readLines()
+		Expect(lines).To(ContainElements(
+			`devex_install_succeeded_total{installer="apt",package="git"} 2`,
+			`devex_install_succeeded_total{installer="apt",package="curl"} 1`,
+			`devex_install_duration_seconds_count{installer="apt",package="git"} 2`,
+		))
+		Expect(lines).To(ContainElement(HavePrefix("# TYPE devex_install_succeeded_total")))
+		lastRuns := 0
+		for _, line := range lines {
+			if strings.HasPrefix(line, "devex_last_run_timestamp_seconds ") {
+				lastRuns++
+			}
+		}
+		Expect(lastRuns).To(Equal(1))
+	})
+
+	It("sanitizes label names and escapes label values", func() {
+		collector.RecordCount(metrics.MetricType("custom-op.started"), map[string]string{
+			"reason":   `quoted "value" with \ and` + "\nnewline",
+			"bad-name": "x",
+			"__name__": "reserved",
+		})
+
+		Expect(metrics.WriteTextfile(path, collector.Metrics())).To(Succeed())
+
+		Expect(readLines()).To(ContainElement(
+			`devex_custom_op_started_total{bad_name="x",reason="quoted \"value\" with \\ and\nnewline"} 1`,
+		))
+	})
+
+	It("replaces the file without leaving temporary files", func() {
+		recordInstall("git", time.Second)
+		Expect(metrics.WriteTextfile(path, collector.Metrics())).To(Succeed())
+		Expect(metrics.WriteTextfile(path, nil)).To(Succeed())
+
+		entries, err := os.ReadDir(filepath.Dir(path))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Name()).To(Equal("devex.prom"))
+	})
+})
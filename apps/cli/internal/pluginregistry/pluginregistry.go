@@ -0,0 +1,139 @@
+// Package pluginregistry hosts a DevEx plugin registry from a local directory. The directory
+// holds an index compatible with the hosted registry API and the published plugin binaries with
+// their checksums and optional minisign signatures:
+//
+//	registry.json
+//	plugins/<name>/<version>/<name>-<os>-<arch>
+//	plugins/<name>/<version>/<name>-<os>-<arch>.sha256
+//	plugins/<name>/<version>/<name>-<os>-<arch>.minisig
+//
+// Binary URLs are stored relative to the directory and made absolute when served, so the same
+// directory can be copied to another host or served behind a proxy.
+package pluginregistry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+const (
+	// IndexName is the registry index file in a registry directory
+	IndexName = "registry.json"
+
+	// FormatVersion is the registry format version written by publish
+	FormatVersion = "1.0.0"
+
+	// pluginsDir is the directory holding published binaries, relative to the registry directory
+	pluginsDir = "plugins"
+)
+
+var (
+	// ErrNotFound is returned when a plugin or platform is not published
+	ErrNotFound = errors.New("not found")
+
+	pluginNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,63}$`)
+	versionPattern    = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._+-]{0,63}$`)
+	platformPattern   = regexp.MustCompile(`^([a-z0-9]+)-([a-z0-9]+)$`)
+)
+
+// IndexPath returns the index file of a registry directory
+func IndexPath(dir string) string {
+	return filepath.Join(dir, IndexName)
+}
+
+// LoadIndex reads the index of a registry directory. A directory without an index is an
+// empty registry.
+func LoadIndex(dir string) (*sdk.PluginRegistry, error) {
+	data, err := os.ReadFile(IndexPath(dir))
+	if errors.Is(err, os.ErrNotExist) {
+		return &sdk.PluginRegistry{
+			Version: FormatVersion,
+			Plugins: make(map[string]sdk.PluginMetadata),
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry index: %w", err)
+	}
+
+	var index sdk.PluginRegistry
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("invalid registry index %s: %w", IndexPath(dir), err)
+	}
+	if index.Plugins == nil {
+		index.Plugins = make(map[string]sdk.PluginMetadata)
+	}
+	return &index, nil
+}
+
+// SaveIndex atomically writes the index of a registry directory
+func SaveIndex(dir string, index *sdk.PluginRegistry) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode registry index: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+IndexName+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to write registry index: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write registry index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write registry index: %w", err)
+	}
+	// Readable by the web server or proxy that serves the directory
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil { //nolint:gosec // the index is public
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write registry index: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), IndexPath(dir)); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write registry index: %w", err)
+	}
+	return nil
+}
+
+// ParsePlatform splits a platform key such as "linux-amd64" into its OS and architecture
+func ParsePlatform(platform string) (goos, goarch string, err error) {
+	match := platformPattern.FindStringSubmatch(platform)
+	if match == nil {
+		return "", "", fmt.Errorf("invalid platform %q: expected <os>-<arch>, e.g. linux-amd64", platform)
+	}
+	return match[1], match[2], nil
+}
+
+// binaryPath returns the registry-relative path of a published binary
+func binaryPath(name, version, platform string) string {
+	file := name + "-" + platform
+	if strings.HasPrefix(platform, "windows-") {
+		file += ".exe"
+	}
+	return path.Join(pluginsDir, name, version, file)
+}
+
+// resolveFile maps a registry-relative path from the index to a file inside dir
+func resolveFile(dir, rel string) (string, error) {
+	clean := path.Clean("/" + rel)
+	if clean == "/" || !strings.HasPrefix(clean, "/"+pluginsDir+"/") {
+		return "", fmt.Errorf("path %q is outside the registry", rel)
+	}
+	return filepath.Join(dir, filepath.FromSlash(clean)), nil
+}
+
+// lastUpdated returns the timestamp recorded in the index, truncated for RFC 3339 clients
+func lastUpdated() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
@@ -0,0 +1,241 @@
+package pluginregistry_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/pluginregistry"
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+const pluginScript = `#!/bin/sh
+if [ "$1" = "--plugin-info" ]; then
+  echo '{"name":"package-manager-test","version":"1.2.0","description":"Test plugin","tags":["test"]}'
+fi
+`
+
+var _ = Describe("Plugin registry", func() {
+	var (
+		ctx         context.Context
+		registryDir string
+		binary      string
+		hostKey     string
+		signingKey  sdk.PublicKey
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		tempDir := GinkgoT().TempDir()
+		registryDir = filepath.Join(tempDir, "registry")
+		binary = filepath.Join(tempDir, "package-manager-test")
+		Expect(os.WriteFile(binary, []byte(pluginScript), 0o755)).To(Succeed())
+		hostKey = runtime.GOOS + "-" + runtime.GOARCH
+
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		signingKey = sdk.PublicKey{ID: sdk.KeyID{1, 2, 3, 4, 5, 6, 7, 8}, Key: public}
+		signature, err := sdk.SignFile(private, signingKey.ID, binary, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(binary+sdk.SignatureExtension, signature, 0o600)).To(Succeed())
+	})
+
+	checksumOf := func(path string) string {
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:])
+	}
+
+	Describe("Publish", func() {
+		It("should read metadata from a host binary and record its checksum", func() {
+			if runtime.GOOS == "windows" {
+				Skip("plugin script requires a POSIX shell")
+			}
+
+			metadata, err := pluginregistry.Publish(ctx, registryDir, pluginregistry.PublishOptions{Binary: binary})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(metadata.Name).To(Equal("package-manager-test"))
+			Expect(metadata.Version).To(Equal("1.2.0"))
+
+			published := metadata.Binaries[hostKey]
+			Expect(published.Checksum).To(Equal(checksumOf(binary)))
+			Expect(published.Size).To(Equal(int64(len(pluginScript))))
+			Expect(published.OS).To(Equal(runtime.GOOS))
+			Expect(published.URL).To(Equal("plugins/package-manager-test/1.2.0/package-manager-test-" + hostKey))
+
+			Expect(filepath.Join(registryDir, published.URL)).To(BeARegularFile())
+			Expect(os.ReadFile(filepath.Join(registryDir, published.URL+".sha256"))).To(ContainSubstring(published.Checksum))
+
+			index, err := pluginregistry.LoadIndex(registryDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(index.Plugins).To(HaveKey("package-manager-test"))
+		})
+
+		It("should publish other platforms with explicit metadata and a signature", func() {
+			info := &sdk.PluginInfo{Name: "package-manager-test", Version: "1.2.0"}
+
+			_, err := pluginregistry.Publish(ctx, registryDir, pluginregistry.PublishOptions{
+				Binary: binary, Platform: "darwin-arm64", Info: info,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			metadata, err := pluginregistry.Publish(ctx, registryDir, pluginregistry.PublishOptions{
+				Binary: binary, Platform: "windows-amd64", Info: info,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(metadata.Binaries).To(HaveKey("darwin-arm64"))
+			Expect(metadata.Binaries).To(HaveKey("windows-amd64"))
+			Expect(metadata.Binaries["windows-amd64"].URL).To(HaveSuffix(".exe"))
+			Expect(metadata.Platforms).To(Equal([]string{"darwin", "windows"}))
+			Expect(filepath.Join(registryDir, metadata.Binaries["darwin-arm64"].URL+sdk.SignatureExtension)).To(BeARegularFile())
+			Expect(metadata.Binaries["darwin-arm64"].Signature).To(HavePrefix("untrusted comment: "))
+		})
+
+		It("should reject signatures that are not minisign signatures", func() {
+			Expect(os.WriteFile(binary+sdk.SignatureExtension, []byte("-----BEGIN PGP SIGNATURE-----"), 0o600)).To(Succeed())
+			_, err := pluginregistry.Publish(ctx, registryDir, pluginregistry.PublishOptions{
+				Binary: binary, Platform: "darwin-arm64", Info: &sdk.PluginInfo{Name: "package-manager-test", Version: "1.2.0"},
+			})
+			Expect(err).To(MatchError(ContainSubstring("not a minisign signature")))
+		})
+
+		It("should replace the binaries of the previous version", func() {
+			_, err := pluginregistry.Publish(ctx, registryDir, pluginregistry.PublishOptions{
+				Binary: binary, Platform: "darwin-arm64", Info: &sdk.PluginInfo{Name: "package-manager-test", Version: "1.2.0"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			metadata, err := pluginregistry.Publish(ctx, registryDir, pluginregistry.PublishOptions{
+				Binary: binary, Platform: "linux-amd64", Info: &sdk.PluginInfo{Name: "package-manager-test", Version: "1.3.0"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(metadata.Binaries).To(HaveLen(1))
+			Expect(metadata.Binaries).To(HaveKey("linux-amd64"))
+		})
+
+		It("should reject cross-platform binaries without metadata", func() {
+			platform := "plan9-mips"
+			_, err := pluginregistry.Publish(ctx, registryDir, pluginregistry.PublishOptions{Binary: binary, Platform: platform})
+			Expect(err).To(MatchError(ContainSubstring("pass the metadata explicitly")))
+		})
+
+		It("should reject invalid platforms and names", func() {
+			_, err := pluginregistry.Publish(ctx, registryDir, pluginregistry.PublishOptions{
+				Binary: binary, Platform: "linux", Info: &sdk.PluginInfo{Name: "test", Version: "1.0.0"},
+			})
+			Expect(err).To(MatchError(ContainSubstring("invalid platform")))
+
+			_, err = pluginregistry.Publish(ctx, registryDir, pluginregistry.PublishOptions{
+				Binary: binary, Platform: "linux-amd64", Info: &sdk.PluginInfo{Name: "../escape", Version: "1.0.0"},
+			})
+			Expect(err).To(MatchError(ContainSubstring("invalid plugin name")))
+		})
+	})
+
+	Describe("Server", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			_, err := pluginregistry.Publish(ctx, registryDir, pluginregistry.PublishOptions{
+				Binary:   binary,
+				Platform: hostKey,
+				Info:     &sdk.PluginInfo{Name: "package-manager-test", Version: "1.2.0", Description: "Test plugin", Tags: []string{"test"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			registryServer, err := pluginregistry.NewServer(registryDir, "")
+			Expect(err).NotTo(HaveOccurred())
+			server = httptest.NewServer(registryServer.Handler())
+			DeferCleanup(server.Close)
+		})
+
+		It("should serve the registry to the SDK registry client", func() {
+			client, err := sdk.NewRegistryClient(sdk.RegistryConfig{BaseURL: server.URL})
+			Expect(err).NotTo(HaveOccurred())
+
+			registry, err := client.GetRegistry(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(registry.BaseURL).To(Equal(server.URL))
+			Expect(registry.LastUpdated.IsZero()).To(BeFalse())
+			Expect(registry.Plugins).To(HaveKey("package-manager-test"))
+			Expect(registry.Plugins["package-manager-test"].Binaries[hostKey].URL).To(HavePrefix(server.URL + "/plugins/"))
+
+			plugin, err := client.GetPlugin(ctx, "package-manager-test")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(plugin.Description).To(Equal("Test plugin"))
+
+			results, err := client.SearchPlugins(ctx, "test", nil, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(HaveLen(1))
+		})
+
+		It("should let the SDK downloader install the plugin with checksum and signature verification", func() {
+			pluginDir := GinkgoT().TempDir()
+			downloader := sdk.NewDownloader(server.URL, pluginDir)
+			downloader.SetSilent(true)
+
+			err := downloader.DownloadPluginWithContext(ctx, "package-manager-test")
+			Expect(err).To(MatchError(ContainSubstring(string(sdk.SignatureUntrusted))))
+
+			downloader.SetKeyring(sdk.NewKeyring(sdk.TrustedKey{PublicKey: signingKey}))
+			Expect(downloader.DownloadPluginWithContext(ctx, "package-manager-test")).To(Succeed())
+
+			installed := filepath.Join(pluginDir, "devex-plugin-package-manager-test")
+			if runtime.GOOS == "windows" {
+				installed += ".exe"
+			}
+			Expect(checksumOf(installed)).To(Equal(checksumOf(binary)))
+		})
+
+		It("should pick up plugins published while it runs", func() {
+			_, err := pluginregistry.Publish(ctx, registryDir, pluginregistry.PublishOptions{
+				Binary: binary, Platform: hostKey, Info: &sdk.PluginInfo{Name: "desktop-test", Version: "0.1.0"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			resp, err := http.Get(server.URL + "/api/v1/plugins/desktop-test")
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		It("should return 404 for unknown plugins and platforms", func() {
+			for _, path := range []string{
+				"/api/v1/plugins/missing",
+				"/api/v1/plugins/package-manager-test/download/plan9-mips",
+				"/plugins/package-manager-test/1.2.0/",
+				"/registry.json",
+			} {
+				resp, err := http.Get(server.URL + path)
+				Expect(err).NotTo(HaveOccurred())
+				_, _ = io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				Expect(resp.StatusCode).To(Equal(http.StatusNotFound), path)
+			}
+		})
+
+		It("should use the configured base URL for binary URLs", func() {
+			registryServer, err := pluginregistry.NewServer(registryDir, "https://registry.internal/")
+			Expect(err).NotTo(HaveOccurred())
+			proxied := httptest.NewServer(registryServer.Handler())
+			defer proxied.Close()
+
+			client, err := sdk.NewRegistryClient(sdk.RegistryConfig{BaseURL: proxied.URL})
+			Expect(err).NotTo(HaveOccurred())
+			plugin, err := client.GetPlugin(ctx, "package-manager-test")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(plugin.Binaries[hostKey].URL).To(HavePrefix("https://registry.internal/plugins/"))
+		})
+	})
+})
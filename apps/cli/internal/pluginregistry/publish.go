@@ -0,0 +1,233 @@
+package pluginregistry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+// pluginInfoTimeout bounds how long a plugin binary may take to report its metadata
+const pluginInfoTimeout = 10 * time.Second
+
+// PublishOptions describes a plugin binary to add to a registry directory
+type PublishOptions struct {
+	Binary    string // path of the plugin binary
+	Platform  string // <os>-<arch> the binary was built for, defaults to the host platform
+	Signature string // minisign signature of the binary, defaults to <binary>.minisig if it exists
+
+	// Info is the plugin metadata. When nil it is read from the binary with --plugin-info,
+	// which requires the binary to be built for the host platform.
+	Info *sdk.PluginInfo
+
+	Type     string // plugin type, e.g. package-manager
+	Priority int
+}
+
+// Publish copies a plugin binary, its checksum and signature into a registry directory and
+// records it in the index. The signature is also carried in the index entry of the binary,
+// where clients verify it against their trusted keys. Publishing a new version replaces the binaries of the previous
+// version; publishing the same version for another platform adds to them.
+func Publish(ctx context.Context, dir string, opts PublishOptions) (*sdk.PluginMetadata, error) {
+	platform := opts.Platform
+	if platform == "" {
+		platform = runtime.GOOS + "-" + runtime.GOARCH
+	}
+	goos, goarch, err := ParsePlatform(platform)
+	if err != nil {
+		return nil, err
+	}
+
+	info := opts.Info
+	if info == nil {
+		if platform != runtime.GOOS+"-"+runtime.GOARCH {
+			return nil, fmt.Errorf("cannot read plugin info from a %s binary on %s-%s, pass the metadata explicitly", platform, runtime.GOOS, runtime.GOARCH)
+		}
+		if info, err = ReadPluginInfo(ctx, opts.Binary); err != nil {
+			return nil, err
+		}
+	}
+	if !pluginNamePattern.MatchString(info.Name) {
+		return nil, fmt.Errorf("invalid plugin name %q", info.Name)
+	}
+	if !versionPattern.MatchString(info.Version) {
+		return nil, fmt.Errorf("invalid version %q for plugin %s", info.Version, info.Name)
+	}
+
+	signature := opts.Signature
+	if signature == "" && fileExists(opts.Binary+sdk.SignatureExtension) {
+		signature = opts.Binary + sdk.SignatureExtension
+	}
+	var signatureData []byte
+	if signature != "" {
+		if signatureData, err = readSignature(signature); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil { //nolint:gosec // registry directories are served publicly
+		return nil, fmt.Errorf("failed to create registry directory: %w", err)
+	}
+	index, err := LoadIndex(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	rel := binaryPath(info.Name, info.Version, platform)
+	target, err := resolveFile(dir, rel)
+	if err != nil {
+		return nil, err
+	}
+	checksum, size, err := copyFile(opts.Binary, target, 0o755)
+	if err != nil {
+		return nil, err
+	}
+	checksumLine := fmt.Sprintf("%s  %s\n", checksum, path.Base(rel))
+	if err := os.WriteFile(target+".sha256", []byte(checksumLine), 0o644); err != nil { //nolint:gosec // checksums are public
+		return nil, fmt.Errorf("failed to write checksum: %w", err)
+	}
+	if signature != "" {
+		if _, _, err := copyFile(signature, target+sdk.SignatureExtension, 0o644); err != nil {
+			return nil, fmt.Errorf("failed to copy signature: %w", err)
+		}
+	} else if err := os.Remove(target + sdk.SignatureExtension); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to remove stale signature: %w", err)
+	}
+
+	metadata, exists := index.Plugins[info.Name]
+	if !exists || metadata.Version != info.Version {
+		metadata = sdk.PluginMetadata{Binaries: make(map[string]sdk.PlatformBinary)}
+	}
+	metadata.PluginInfo = *info
+	metadata.Path = path.Join(pluginsDir, info.Name)
+	if opts.Type != "" {
+		metadata.Type = opts.Type
+	}
+	if opts.Priority != 0 {
+		metadata.Priority = opts.Priority
+	}
+	metadata.Binaries[platform] = sdk.PlatformBinary{
+		URL:       rel,
+		Checksum:  checksum,
+		Size:      size,
+		OS:        goos,
+		Arch:      goarch,
+		Signature: string(signatureData),
+	}
+	metadata.Platforms = platformOSes(metadata.Binaries)
+
+	index.Plugins[info.Name] = metadata
+	index.Version = FormatVersion
+	index.LastUpdated = lastUpdated()
+	if err := SaveIndex(dir, index); err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+// ReadPluginInfo asks a plugin binary for its metadata
+func ReadPluginInfo(ctx context.Context, binary string) (*sdk.PluginInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, pluginInfoTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, binary, "--plugin-info").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin info from %s: %w", binary, err)
+	}
+	var info sdk.PluginInfo
+	if err := json.Unmarshal(output, &info); err != nil {
+		return nil, fmt.Errorf("invalid plugin info from %s: %w", binary, err)
+	}
+	return &info, nil
+}
+
+// readSignature reads a detached signature and checks that it is a minisign signature
+func readSignature(file string) ([]byte, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature: %w", err)
+	}
+	if _, err := sdk.ParseSignature(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return data, nil
+}
+
+// LoadPluginInfo reads plugin metadata from a JSON file in the --plugin-info format
+func LoadPluginInfo(file string) (*sdk.PluginInfo, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin info: %w", err)
+	}
+	var info sdk.PluginInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("invalid plugin info in %s: %w", file, err)
+	}
+	return &info, nil
+}
+
+// copyFile atomically copies src to dst with the given mode and returns the SHA-256 and size
+// of the copy
+func copyFile(src, dst string, mode os.FileMode) (string, int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer func() { _ = in.Close() }()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil { //nolint:gosec // registry directories are served publicly
+		return "", 0, fmt.Errorf("failed to create %s: %w", filepath.Dir(dst), err)
+	}
+	out, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+"-*")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(out, hasher), in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(out.Name(), mode)
+	}
+	if err == nil {
+		err = os.Rename(out.Name(), dst)
+	}
+	if err != nil {
+		_ = os.Remove(out.Name())
+		return "", 0, fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), size, nil
+}
+
+// platformOSes returns the sorted operating systems a plugin has binaries for
+func platformOSes(binaries map[string]sdk.PlatformBinary) []string {
+	seen := make(map[string]bool)
+	var oses []string
+	for _, binary := range binaries {
+		if !seen[binary.OS] {
+			seen[binary.OS] = true
+			oses = append(oses, binary.OS)
+		}
+	}
+	sort.Strings(oses)
+	return oses
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
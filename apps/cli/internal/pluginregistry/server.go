@@ -0,0 +1,216 @@
+package pluginregistry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+// Server serves a registry directory with the API used by the SDK's RegistryClient and
+// Downloader:
+//
+//	GET /api/v1/registry                               the full registry
+//	GET /api/v1/plugins/{name}                         metadata of one plugin
+//	GET /api/v1/plugins/{name}/download/{platform}     the binary for a platform
+//	GET /plugins/...                                   binaries, checksums and signatures
+//
+// The index is re-read when it changes, so plugins can be published while the server runs.
+type Server struct {
+	dir     string
+	baseURL string // public URL of the registry, derived from each request when empty
+
+	mu      sync.Mutex
+	index   *sdk.PluginRegistry
+	modTime time.Time
+}
+
+// NewServer creates a server for a registry directory. baseURL is the URL clients reach the
+// registry at; leave it empty to derive it from the request host.
+func NewServer(dir, baseURL string) (*Server, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("registry directory %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("registry directory %s is not a directory", dir)
+	}
+
+	s := &Server{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/")}
+	if _, err := s.currentIndex(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Handler returns the HTTP handler of the registry API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/registry", s.handleRegistry)
+	mux.HandleFunc("GET /api/v1/plugins/{name}", s.handlePlugin)
+	mux.HandleFunc("GET /api/v1/plugins/{name}/download/{platform}", s.handleDownload)
+	mux.HandleFunc("GET /"+pluginsDir+"/", s.handleFile)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return mux
+}
+
+// handleRegistry serves the full registry in the RegistryResponse format
+func (s *Server) handleRegistry(w http.ResponseWriter, r *http.Request) {
+	index, err := s.currentIndex()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	base := s.requestBaseURL(r)
+	plugins := make(map[string]sdk.PluginMetadata, len(index.Plugins))
+	for name, metadata := range index.Plugins {
+		plugins[name] = withAbsoluteURLs(metadata, base)
+	}
+	writeJSON(w, sdk.RegistryResponse{
+		BaseURL:     base,
+		Version:     index.Version,
+		LastUpdated: index.LastUpdated.UTC().Format(time.RFC3339),
+		Plugins:     plugins,
+	})
+}
+
+// handlePlugin serves the metadata of one plugin
+func (s *Server) handlePlugin(w http.ResponseWriter, r *http.Request) {
+	metadata, err := s.lookupPlugin(r.PathValue("name"))
+	if err != nil {
+		writeLookupError(w, err)
+		return
+	}
+	writeJSON(w, withAbsoluteURLs(*metadata, s.requestBaseURL(r)))
+}
+
+// handleDownload serves the binary of a plugin for a platform
+func (s *Server) handleDownload(w http.ResponseWriter, r *http.Request) {
+	metadata, err := s.lookupPlugin(r.PathValue("name"))
+	if err != nil {
+		writeLookupError(w, err)
+		return
+	}
+	binary, ok := metadata.Binaries[r.PathValue("platform")]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("plugin %s is not published for %s", metadata.Name, r.PathValue("platform")))
+		return
+	}
+	s.serveFile(w, r, binary.URL)
+}
+
+// handleFile serves published binaries, checksums and signatures
+func (s *Server) handleFile(w http.ResponseWriter, r *http.Request) {
+	s.serveFile(w, r, r.URL.Path)
+}
+
+// serveFile serves a registry-relative file, refusing directories and paths outside plugins/
+func (s *Server) serveFile(w http.ResponseWriter, r *http.Request, rel string) {
+	file, err := resolveFile(s.dir, rel)
+	if err != nil || strings.HasPrefix(path.Base(rel), ".") {
+		writeError(w, http.StatusNotFound, ErrNotFound)
+		return
+	}
+	info, err := os.Stat(file)
+	if err != nil || info.IsDir() {
+		writeError(w, http.StatusNotFound, ErrNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeFile(w, r, file)
+}
+
+// lookupPlugin returns the metadata of a published plugin
+func (s *Server) lookupPlugin(name string) (*sdk.PluginMetadata, error) {
+	if !pluginNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("plugin %q: %w", name, ErrNotFound)
+	}
+	index, err := s.currentIndex()
+	if err != nil {
+		return nil, err
+	}
+	metadata, ok := index.Plugins[name]
+	if !ok {
+		return nil, fmt.Errorf("plugin %s: %w", name, ErrNotFound)
+	}
+	return &metadata, nil
+}
+
+// currentIndex returns the index, reloading it when the file changed
+func (s *Server) currentIndex() (*sdk.PluginRegistry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var modTime time.Time
+	if info, err := os.Stat(IndexPath(s.dir)); err == nil {
+		modTime = info.ModTime()
+	}
+	if s.index != nil && modTime.Equal(s.modTime) {
+		return s.index, nil
+	}
+
+	index, err := LoadIndex(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	s.index = index
+	s.modTime = modTime
+	return index, nil
+}
+
+// requestBaseURL returns the public URL of the registry for a request
+func (s *Server) requestBaseURL(r *http.Request) string {
+	if s.baseURL != "" {
+		return s.baseURL
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// withAbsoluteURLs returns plugin metadata with binary URLs resolved against base
+func withAbsoluteURLs(metadata sdk.PluginMetadata, base string) sdk.PluginMetadata {
+	binaries := make(map[string]sdk.PlatformBinary, len(metadata.Binaries))
+	for platform, binary := range metadata.Binaries {
+		if !strings.Contains(binary.URL, "://") {
+			binary.URL = base + "/" + strings.TrimPrefix(binary.URL, "/")
+		}
+		binaries[platform] = binary
+	}
+	metadata.Binaries = binaries
+	return metadata
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeLookupError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -39,18 +39,18 @@ const (
 
 // SecurityOverride represents a security rule override
 type SecurityOverride struct {
-	RuleType SecurityRuleType `yaml:"rule_type" json:"rule_type"`
-	Pattern  string           `yaml:"pattern" json:"pattern"`
-	Reason   string           `yaml:"reason" json:"reason"`
+	RuleType SecurityRuleType `yaml:"rule_type" json:"rule_type" jsonschema:"required"`
+	Pattern  string           `yaml:"pattern" json:"pattern" jsonschema:"required"` // regular expression matching the allowed commands
+	Reason   string           `yaml:"reason" json:"reason" jsonschema:"required"`   // why the override is safe
 	AppName  string           `yaml:"app_name,omitempty" json:"app_name,omitempty"`
-	WarnUser bool             `yaml:"warn_user" json:"warn_user"`
+	WarnUser bool             `yaml:"warn_user" json:"warn_user"` // show a warning when the override allows a command
 }
 
 // SecurityConfig holds security configuration and overrides
 type SecurityConfig struct {
-	Level                SecurityLevel                 `yaml:"level" json:"level"`
-	GlobalOverrides      []SecurityOverride            `yaml:"global_overrides" json:"global_overrides"`
-	AppSpecificOverrides map[string][]SecurityOverride `yaml:"app_overrides" json:"app_overrides"`
+	Level                SecurityLevel                 `yaml:"level" json:"level"`                       // 0=Strict, 1=Moderate, 2=Permissive, 3=Enterprise
+	GlobalOverrides      []SecurityOverride            `yaml:"global_overrides" json:"global_overrides"` // overrides applying to every app
+	AppSpecificOverrides map[string][]SecurityOverride `yaml:"app_overrides" json:"app_overrides"`       // overrides keyed by app name
 	EnterpriseMode       bool                          `yaml:"enterprise_mode" json:"enterprise_mode"`
 	WarnOnOverrides      bool                          `yaml:"warn_on_overrides" json:"warn_on_overrides"`
 }
@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/jameswlane/devex/apps/cli/internal/audit"
 	"github.com/jameswlane/devex/apps/cli/internal/log"
 	"github.com/jameswlane/devex/apps/cli/internal/types"
 )
@@ -98,7 +99,7 @@ func (sce *SecureCommandExecutor) ExecuteCommand(ctx context.Context, command st
 	// Set platform-specific process attributes
 	setPlatformSpecificAttrs(cmd)
 
-	output, err := cmd.CombinedOutput()
+	output, err := audit.CombinedOutput(ctx, cmd)
 	return string(output), err
 }
 
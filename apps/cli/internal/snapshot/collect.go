@@ -0,0 +1,177 @@
+package snapshot
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/jameswlane/devex/apps/cli/internal/platform"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+// shellFiles are the shell startup files captured relative to the home directory
+var shellFiles = []string{
+	".bashrc",
+	".bash_profile",
+	".profile",
+	".zshrc",
+	".zprofile",
+	".config/fish/config.fish",
+}
+
+// gitFiles are the git configuration files captured relative to the home directory
+var gitFiles = []string{
+	".gitconfig",
+	".config/git/config",
+	".config/git/ignore",
+}
+
+// desktopBackupDirs are the directories desktop plugins write their backups to, relative to the home directory
+var desktopBackupDirs = map[string]string{
+	"budgie":   ".devex/backups/budgie",
+	"cinnamon": ".devex/backups/cinnamon",
+	"cosmic":   ".devex/backups/cosmic",
+	"gnome":    ".devex/backups/gnome",
+	"kde":      ".devex/backups/kde",
+	"lxqt":     ".devex/backups/lxqt",
+	"mate":     ".devex/backups/mate",
+	"pantheon": ".devex/backups/pantheon",
+	"xfce":     ".devex/backups/xfce",
+}
+
+// AppLookup returns the configuration of an app by name
+type AppLookup func(name string) (types.CrossPlatformApp, bool)
+
+// CollectOptions configures what Collect captures
+type CollectOptions struct {
+	HomeDir  string
+	Repo     types.Repository
+	Themes   types.ThemeRepository // optional
+	Lookup   AppLookup             // optional, used to record each app's install method
+	Platform platform.DetectionResult
+	Shell    string // login shell path, e.g. $SHELL
+}
+
+// Collect captures the current machine state
+func Collect(opts CollectOptions) (*Snapshot, error) {
+	snap := &Snapshot{
+		Version:   CurrentVersion,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		Source: Source{
+			OS:           opts.Platform.OS,
+			Distribution: opts.Platform.Distribution,
+			Version:      opts.Platform.Version,
+			DesktopEnv:   opts.Platform.DesktopEnv,
+			Architecture: opts.Platform.Architecture,
+		},
+	}
+	if opts.Shell != "" {
+		snap.Shell = filepath.Base(opts.Shell)
+	}
+
+	installed, err := opts.Repo.ListApps()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed apps: %w", err)
+	}
+	for _, installedApp := range installed {
+		app := App{Name: installedApp.Name}
+		if opts.Lookup != nil {
+			if config, ok := opts.Lookup(installedApp.Name); ok {
+				app.Category = config.Category
+				app.InstallMethod = config.GetBestOSConfig().InstallMethod
+			}
+		}
+		snap.Apps = append(snap.Apps, app)
+	}
+	sort.Slice(snap.Apps, func(i, j int) bool { return snap.Apps[i].Name < snap.Apps[j].Name })
+
+	if opts.Themes != nil {
+		preferences, err := opts.Themes.GetAllThemePreferences()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read theme preferences: %w", err)
+		}
+		snap.Themes = Themes{Global: preferences.GlobalTheme}
+		if len(preferences.AppThemes) > 0 {
+			snap.Themes.Apps = preferences.AppThemes
+		}
+	}
+
+	for _, rel := range shellFiles {
+		if err := snap.captureFile(opts.HomeDir, rel, FileKindShell, ""); err != nil {
+			return nil, err
+		}
+	}
+	for _, rel := range gitFiles {
+		if err := snap.captureFile(opts.HomeDir, rel, FileKindGit, ""); err != nil {
+			return nil, err
+		}
+	}
+	if err := snap.captureDesktopBackups(opts.HomeDir); err != nil {
+		return nil, err
+	}
+
+	return snap, nil
+}
+
+// captureFile adds a regular file to the snapshot if it exists
+func (s *Snapshot) captureFile(homeDir, rel string, kind FileKind, desktop string) error {
+	info, err := os.Lstat(filepath.Join(homeDir, filepath.FromSlash(rel)))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s: %w", rel, err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+	if info.Size() > MaxFileSize {
+		return fmt.Errorf("%s exceeds maximum size limit (%d bytes)", rel, MaxFileSize)
+	}
+
+	data, err := os.ReadFile(filepath.Join(homeDir, filepath.FromSlash(rel)))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", rel, err)
+	}
+	s.Files = append(s.Files, File{Kind: kind, Path: rel, Desktop: desktop, data: data})
+	return nil
+}
+
+// captureDesktopBackups adds the newest backup of every desktop environment plugin
+func (s *Snapshot) captureDesktopBackups(homeDir string) error {
+	desktops := make([]string, 0, len(desktopBackupDirs))
+	for desktop := range desktopBackupDirs {
+		desktops = append(desktops, desktop)
+	}
+	sort.Strings(desktops)
+
+	for _, desktop := range desktops {
+		dir := desktopBackupDirs[desktop]
+		entries, err := os.ReadDir(filepath.Join(homeDir, filepath.FromSlash(dir)))
+		if err != nil {
+			continue
+		}
+
+		var newest os.DirEntry
+		var newestTime time.Time
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			if newest == nil || info.ModTime().After(newestTime) {
+				newest, newestTime = entry, info.ModTime()
+			}
+		}
+		if newest == nil {
+			continue
+		}
+		if err := s.captureFile(homeDir, path.Join(dir, newest.Name()), FileKindDesktop, desktop); err != nil {
+			return err
+		}
+	}
+	return nil
+}
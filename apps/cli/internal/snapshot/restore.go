@@ -0,0 +1,156 @@
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/jameswlane/devex/apps/cli/internal/platform"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+// PreviousSuffix is appended to files that an import replaced
+const PreviousSuffix = ".pre-snapshot"
+
+// AppResolution is how a snapshot app installs on the target platform
+type AppResolution struct {
+	Name         string
+	SourceMethod string
+	Method       string
+	App          types.CrossPlatformApp // configured to install with Method
+	Reason       string                 // why the app cannot be installed, empty when it can
+}
+
+// Installable reports whether the app can be installed on the target platform
+func (r AppResolution) Installable() bool {
+	return r.Reason == ""
+}
+
+// ResolveApps re-resolves every snapshot app against the local configuration for the target
+// platform. Each app installs with the OS configuration that best matches the target, which
+// may be one of its alternatives, e.g. dnf on Fedora for an app captured on Ubuntu with apt.
+func ResolveApps(snap *Snapshot, lookup AppLookup, target platform.DetectionResult) []AppResolution {
+	resolutions := make([]AppResolution, 0, len(snap.Apps))
+	for _, captured := range snap.Apps {
+		resolution := AppResolution{Name: captured.Name, SourceMethod: captured.InstallMethod}
+
+		app, ok := lookup(captured.Name)
+		if !ok {
+			resolution.Reason = "not defined in this configuration"
+			resolutions = append(resolutions, resolution)
+			continue
+		}
+
+		best := app.GetBestOSConfigFor(target)
+		if best.InstallMethod == "" {
+			resolution.Reason = fmt.Sprintf("not supported on %s", target.OS)
+			resolutions = append(resolutions, resolution)
+			continue
+		}
+
+		resolution.Name = app.Name
+		resolution.Method = best.InstallMethod
+		resolution.App = app.WithOSConfig(best)
+		resolutions = append(resolutions, resolution)
+	}
+	return resolutions
+}
+
+// RestoredFile is the outcome of restoring a single captured file
+type RestoredFile struct {
+	File
+	Target   string
+	Previous string // where the replaced file was kept, empty if nothing was replaced
+	Skipped  bool   // the file already had the captured contents
+}
+
+// RestoreFiles writes the captured files of the given kinds into homeDir. Existing files
+// with different contents are kept next to the restored file with PreviousSuffix.
+func RestoreFiles(snap *Snapshot, homeDir string, kinds ...FileKind) ([]RestoredFile, error) {
+	wanted := make(map[FileKind]bool, len(kinds))
+	for _, kind := range kinds {
+		wanted[kind] = true
+	}
+
+	var restored []RestoredFile
+	for _, file := range snap.Files {
+		if len(kinds) > 0 && !wanted[file.Kind] {
+			continue
+		}
+		if err := ValidatePath(file.Path); err != nil {
+			return restored, err
+		}
+		if !restorable(file) {
+			return restored, fmt.Errorf("security violation: snapshot file %q is not a known %s file", file.Path, file.Kind)
+		}
+
+		result := RestoredFile{File: file, Target: filepath.Join(homeDir, filepath.FromSlash(file.Path))}
+		mode := os.FileMode(0644)
+		if existing, err := os.ReadFile(result.Target); err == nil {
+			if bytes.Equal(existing, file.data) {
+				result.Skipped = true
+				restored = append(restored, result)
+				continue
+			}
+			if info, err := os.Stat(result.Target); err == nil {
+				mode = info.Mode().Perm()
+			}
+			result.Previous = result.Target + PreviousSuffix
+			if err := os.WriteFile(result.Previous, existing, mode); err != nil {
+				return restored, fmt.Errorf("failed to keep existing %s: %w", file.Path, err)
+			}
+		} else if !os.IsNotExist(err) {
+			return restored, fmt.Errorf("failed to read existing %s: %w", file.Path, err)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(result.Target), 0750); err != nil {
+			return restored, fmt.Errorf("failed to create directory for %s: %w", file.Path, err)
+		}
+		if err := os.WriteFile(result.Target, file.data, mode); err != nil {
+			return restored, fmt.Errorf("failed to restore %s: %w", file.Path, err)
+		}
+		restored = append(restored, result)
+	}
+	return restored, nil
+}
+
+// restorable reports whether a file is one that Collect captures for its kind, so an edited
+// snapshot cannot write arbitrary files into the home directory
+func restorable(file File) bool {
+	switch file.Kind {
+	case FileKindShell:
+		return slices.Contains(shellFiles, file.Path)
+	case FileKindGit:
+		return slices.Contains(gitFiles, file.Path)
+	case FileKindDesktop:
+		dir, ok := desktopBackupDirs[file.Desktop]
+		return ok && path.Dir(file.Path) == dir
+	default:
+		return false
+	}
+}
+
+// RestoreThemes saves the snapshot's theme selections
+func RestoreThemes(snap *Snapshot, themes types.ThemeRepository) error {
+	if snap.Themes.Global != "" {
+		if err := themes.SetGlobalTheme(snap.Themes.Global); err != nil {
+			return err
+		}
+	}
+
+	apps := make([]string, 0, len(snap.Themes.Apps))
+	for app := range snap.Themes.Apps {
+		apps = append(apps, app)
+	}
+	sort.Strings(apps)
+	for _, app := range apps {
+		if err := themes.SetAppTheme(app, snap.Themes.Apps[app]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+// Package snapshot captures a machine's DevEx state in a portable archive: the installed
+// apps, theme selections, login shell, shell and git configuration and the latest desktop
+// plugin backups. Importing a snapshot re-resolves every app for the target platform, so a
+// snapshot taken on one distribution can be restored on another.
+package snapshot
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// CurrentVersion is the snapshot format version written by this release
+	CurrentVersion = 1
+
+	// ManifestName is the archive entry holding the snapshot manifest
+	ManifestName = "snapshot.yaml"
+
+	// filesPrefix is the archive directory holding captured files
+	filesPrefix = "files/"
+
+	// Security limits for archive extraction
+	MaxFileSize  = 50 * 1024 * 1024  // 50MB per file
+	MaxTotalSize = 512 * 1024 * 1024 // 512MB total
+	MaxFiles     = 1000
+)
+
+// FileKind classifies a captured file
+type FileKind string
+
+const (
+	// FileKindShell is a shell startup file such as .zshrc
+	FileKindShell FileKind = "shell"
+	// FileKindGit is git configuration such as .gitconfig
+	FileKindGit FileKind = "git"
+	// FileKindDesktop is a backup written by a desktop environment plugin
+	FileKindDesktop FileKind = "desktop"
+)
+
+// Snapshot is the manifest of a snapshot archive
+type Snapshot struct {
+	Version   int       `yaml:"version"`
+	CreatedAt time.Time `yaml:"created_at"`
+	Source    Source    `yaml:"source"`
+	Apps      []App     `yaml:"apps"`
+	Themes    Themes    `yaml:"themes,omitempty"`
+	Shell     string    `yaml:"shell,omitempty"`
+	Files     []File    `yaml:"files,omitempty"`
+}
+
+// Source describes the machine the snapshot was taken on
+type Source struct {
+	OS           string `yaml:"os"`
+	Distribution string `yaml:"distribution,omitempty"`
+	Version      string `yaml:"version,omitempty"`
+	DesktopEnv   string `yaml:"desktop_environment,omitempty"`
+	Architecture string `yaml:"architecture,omitempty"`
+}
+
+// App is an installed application. The install method is informational; import picks the
+// method for the target platform from the app configuration.
+type App struct {
+	Name          string `yaml:"name"`
+	Category      string `yaml:"category,omitempty"`
+	InstallMethod string `yaml:"install_method,omitempty"`
+}
+
+// Themes holds the selected global and per-app themes
+type Themes struct {
+	Global string            `yaml:"global,omitempty"`
+	Apps   map[string]string `yaml:"apps,omitempty"`
+}
+
+// File is a captured file, stored relative to the home directory
+type File struct {
+	Kind    FileKind `yaml:"kind"`
+	Path    string   `yaml:"path"`
+	Desktop string   `yaml:"desktop,omitempty"`
+
+	data []byte
+}
+
+// Data returns the captured contents of a file
+func (f File) Data() []byte {
+	return f.data
+}
+
+// Write stores the snapshot and its files as a gzip-compressed tar archive
+func (s *Snapshot) Write(archivePath string) error {
+	s.Version = CurrentVersion
+	manifest, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot manifest: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(archivePath), 0750); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	out, err := os.OpenFile(archivePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot archive: %w", err)
+	}
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	writeErr := writeEntry(tw, ManifestName, manifest, s.CreatedAt)
+	for _, file := range s.Files {
+		if writeErr != nil {
+			break
+		}
+		writeErr = writeEntry(tw, filesPrefix+file.Path, file.data, s.CreatedAt)
+	}
+
+	for _, closer := range []io.Closer{tw, gz, out} {
+		if err := closer.Close(); err != nil && writeErr == nil {
+			writeErr = err
+		}
+	}
+	if writeErr != nil {
+		_ = os.Remove(archivePath)
+		return fmt.Errorf("failed to write snapshot archive: %w", writeErr)
+	}
+	return nil
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	header := &tar.Header{
+		Name:     name,
+		Mode:     0600,
+		Size:     int64(len(data)),
+		ModTime:  modTime,
+		Typeflag: tar.TypeReg,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// Open reads a snapshot archive. Entries that are not regular files, escape the archive
+// or exceed the size limits are rejected.
+func Open(archivePath string) (*Snapshot, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot is not a gzip archive: %w", err)
+	}
+	defer gz.Close()
+
+	var manifest []byte
+	contents := make(map[string][]byte)
+	var totalSize int64
+	tr := tar.NewReader(gz)
+	for count := 0; ; count++ {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot: %w", err)
+		}
+
+		if count >= MaxFiles {
+			return nil, fmt.Errorf("too many files in snapshot (limit: %d)", MaxFiles)
+		}
+		if header.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("unsupported entry %s in snapshot", header.Name)
+		}
+		if header.Size > MaxFileSize {
+			return nil, fmt.Errorf("file %s exceeds maximum size limit (%d bytes)", header.Name, MaxFileSize)
+		}
+		totalSize += header.Size
+		if totalSize > MaxTotalSize {
+			return nil, fmt.Errorf("snapshot exceeds maximum size limit (%d bytes)", MaxTotalSize)
+		}
+
+		data, err := io.ReadAll(io.LimitReader(tr, header.Size))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from snapshot: %w", header.Name, err)
+		}
+
+		switch {
+		case header.Name == ManifestName:
+			manifest = data
+		case strings.HasPrefix(header.Name, filesPrefix):
+			rel := strings.TrimPrefix(header.Name, filesPrefix)
+			if err := ValidatePath(rel); err != nil {
+				return nil, err
+			}
+			contents[rel] = data
+		default:
+			return nil, fmt.Errorf("unexpected entry %s in snapshot", header.Name)
+		}
+	}
+
+	if manifest == nil {
+		return nil, fmt.Errorf("snapshot has no %s", ManifestName)
+	}
+	snap := &Snapshot{}
+	if err := yaml.Unmarshal(manifest, snap); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot manifest: %w", err)
+	}
+	if snap.Version > CurrentVersion {
+		return nil, fmt.Errorf("snapshot has version %d, this release supports up to %d", snap.Version, CurrentVersion)
+	}
+
+	for i, file := range snap.Files {
+		if err := ValidatePath(file.Path); err != nil {
+			return nil, err
+		}
+		data, ok := contents[file.Path]
+		if !ok {
+			return nil, fmt.Errorf("snapshot is missing captured file %s", file.Path)
+		}
+		snap.Files[i].data = data
+	}
+	return snap, nil
+}
+
+// ValidatePath checks that a captured file path stays inside the home directory
+func ValidatePath(rel string) error {
+	if rel == "" || path.IsAbs(rel) || filepath.IsAbs(rel) || strings.Contains(rel, `\`) {
+		return fmt.Errorf("security violation: invalid snapshot path %q", rel)
+	}
+	if clean := path.Clean(rel); clean != rel || clean == ".." || strings.HasPrefix(clean, "../") {
+		return fmt.Errorf("security violation: invalid snapshot path %q", rel)
+	}
+	return nil
+}
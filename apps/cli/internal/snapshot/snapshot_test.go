@@ -0,0 +1,206 @@
+package snapshot_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/datastore/repository"
+	"github.com/jameswlane/devex/apps/cli/internal/mocks"
+	"github.com/jameswlane/devex/apps/cli/internal/platform"
+	"github.com/jameswlane/devex/apps/cli/internal/snapshot"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+var _ = Describe("Snapshot", func() {
+	var (
+		homeDir string
+		repo    *mocks.MockRepository
+		themes  types.ThemeRepository
+		apps    map[string]types.CrossPlatformApp
+		lookup  snapshot.AppLookup
+	)
+
+	writeHomeFile := func(rel, contents string) {
+		path := filepath.Join(homeDir, rel)
+		Expect(os.MkdirAll(filepath.Dir(path), 0750)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(contents), 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		homeDir = GinkgoT().TempDir()
+		repo = mocks.NewMockRepository()
+		themes = repository.NewThemeRepository(mocks.NewMockSystemRepository())
+		apps = map[string]types.CrossPlatformApp{
+			"docker": {
+				Name:     "docker",
+				Category: "Development",
+				AllPlatforms: types.OSConfig{
+					InstallMethod:  "apt",
+					InstallCommand: "docker.io",
+					Alternatives: []types.OSConfig{{
+						InstallMethod:        "dnf",
+						InstallCommand:       "moby-engine",
+						PlatformRequirements: []types.PlatformRequirement{{OS: "fedora"}},
+					}},
+				},
+			},
+			"git": {
+				Name:         "git",
+				AllPlatforms: types.OSConfig{InstallMethod: "apt", InstallCommand: "git"},
+			},
+		}
+		lookup = func(name string) (types.CrossPlatformApp, bool) {
+			app, ok := apps[name]
+			return app, ok
+		}
+	})
+
+	It("round-trips apps, themes and files through an archive", func() {
+		Expect(repo.AddApp("git")).To(Succeed())
+		Expect(repo.AddApp("docker")).To(Succeed())
+		Expect(themes.SetGlobalTheme("Tokyo Night")).To(Succeed())
+		writeHomeFile(".zshrc", "export EDITOR=nvim\n")
+		writeHomeFile(".gitconfig", "[user]\n\tname = Dev\n")
+		writeHomeFile(".devex/backups/gnome/gnome-settings-1.conf", "old")
+		writeHomeFile(".devex/backups/gnome/gnome-settings-2.conf", "new")
+		newer := filepath.Join(homeDir, ".devex/backups/gnome/gnome-settings-2.conf")
+		Expect(os.Chtimes(newer, time.Now().Add(time.Second), time.Now().Add(time.Second))).To(Succeed())
+
+		snap, err := snapshot.Collect(snapshot.CollectOptions{
+			HomeDir:  homeDir,
+			Repo:     repo,
+			Themes:   themes,
+			Lookup:   lookup,
+			Platform: platform.DetectionResult{OS: "linux", Distribution: "ubuntu", Version: "24.04", DesktopEnv: "gnome"},
+			Shell:    "/usr/bin/zsh",
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		archive := filepath.Join(GinkgoT().TempDir(), "snap.tar.gz")
+		Expect(snap.Write(archive)).To(Succeed())
+
+		loaded, err := snapshot.Open(archive)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loaded.Source.Distribution).To(Equal("ubuntu"))
+		Expect(loaded.Shell).To(Equal("zsh"))
+		Expect(loaded.Themes.Global).To(Equal("Tokyo Night"))
+		Expect(loaded.Apps).To(Equal([]snapshot.App{
+			{Name: "docker", Category: "Development", InstallMethod: "apt"},
+			{Name: "git", InstallMethod: "apt"},
+		}))
+
+		paths := map[string]string{}
+		for _, file := range loaded.Files {
+			paths[file.Path] = string(file.Data())
+		}
+		Expect(paths).To(HaveKeyWithValue(".zshrc", "export EDITOR=nvim\n"))
+		Expect(paths).To(HaveKey(".gitconfig"))
+		Expect(paths).To(HaveKeyWithValue(".devex/backups/gnome/gnome-settings-2.conf", "new"))
+		Expect(paths).ToNot(HaveKey(".devex/backups/gnome/gnome-settings-1.conf"))
+	})
+
+	It("rejects archives with paths outside the home directory", func() {
+		archive := filepath.Join(GinkgoT().TempDir(), "evil.tar.gz")
+		out, err := os.Create(archive)
+		Expect(err).ToNot(HaveOccurred())
+		gz := gzip.NewWriter(out)
+		tw := tar.NewWriter(gz)
+		Expect(tw.WriteHeader(&tar.Header{Name: "files/../.ssh/authorized_keys", Mode: 0600, Size: 1, Typeflag: tar.TypeReg})).To(Succeed())
+		_, err = tw.Write([]byte("x"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tw.Close()).To(Succeed())
+		Expect(gz.Close()).To(Succeed())
+		Expect(out.Close()).To(Succeed())
+
+		_, err = snapshot.Open(archive)
+		Expect(err).To(MatchError(ContainSubstring("security violation")))
+
+		Expect(snapshot.ValidatePath("/etc/passwd")).ToNot(Succeed())
+		Expect(snapshot.ValidatePath("a/../../b")).ToNot(Succeed())
+		Expect(snapshot.ValidatePath(".config/git/config")).To(Succeed())
+	})
+
+	Describe("ResolveApps", func() {
+		snap := &snapshot.Snapshot{Apps: []snapshot.App{
+			{Name: "docker", InstallMethod: "apt"},
+			{Name: "git", InstallMethod: "apt"},
+			{Name: "missing", InstallMethod: "apt"},
+		}}
+
+		It("switches to a matching alternative on another distribution", func() {
+			resolutions := snapshot.ResolveApps(snap, lookup, platform.DetectionResult{OS: "linux", Distribution: "fedora"})
+			Expect(resolutions).To(HaveLen(3))
+
+			Expect(resolutions[0].Installable()).To(BeTrue())
+			Expect(resolutions[0].SourceMethod).To(Equal("apt"))
+			Expect(resolutions[0].Method).To(Equal("dnf"))
+			Expect(resolutions[0].App.GetOSConfig().InstallCommand).To(Equal("moby-engine"))
+
+			Expect(resolutions[1].Method).To(Equal("apt"))
+			Expect(resolutions[2].Installable()).To(BeFalse())
+			Expect(resolutions[2].Reason).To(ContainSubstring("not defined"))
+		})
+
+		It("keeps the captured method on the same distribution", func() {
+			resolutions := snapshot.ResolveApps(snap, lookup, platform.DetectionResult{OS: "linux", Distribution: "ubuntu"})
+			Expect(resolutions[0].Method).To(Equal("apt"))
+		})
+	})
+
+	Describe("RestoreFiles", func() {
+		var snap *snapshot.Snapshot
+
+		BeforeEach(func() {
+			source := GinkgoT().TempDir()
+			Expect(os.WriteFile(filepath.Join(source, ".zshrc"), []byte("restored\n"), 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(source, ".gitconfig"), []byte("[core]\n"), 0600)).To(Succeed())
+
+			var err error
+			snap, err = snapshot.Collect(snapshot.CollectOptions{HomeDir: source, Repo: repo})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("keeps replaced files and skips identical ones", func() {
+			writeHomeFile(".zshrc", "mine\n")
+			writeHomeFile(".gitconfig", "[core]\n")
+
+			restored, err := snapshot.RestoreFiles(snap, homeDir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(restored).To(HaveLen(2))
+
+			Expect(os.ReadFile(filepath.Join(homeDir, ".zshrc"))).To(Equal([]byte("restored\n")))
+			Expect(os.ReadFile(filepath.Join(homeDir, ".zshrc"+snapshot.PreviousSuffix))).To(Equal([]byte("mine\n")))
+
+			for _, file := range restored {
+				Expect(file.Skipped).To(Equal(file.Path == ".gitconfig"))
+			}
+		})
+
+		It("restores only the requested kinds", func() {
+			restored, err := snapshot.RestoreFiles(snap, homeDir, snapshot.FileKindGit)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(restored).To(HaveLen(1))
+			Expect(filepath.Join(homeDir, ".zshrc")).ToNot(BeAnExistingFile())
+		})
+
+		It("refuses files that are not captured for their kind", func() {
+			for _, file := range []snapshot.File{
+				{Kind: snapshot.FileKindShell, Path: ".ssh/authorized_keys"},
+				{Kind: snapshot.FileKindGit, Path: ".zshrc"},
+				{Kind: snapshot.FileKindDesktop, Desktop: "gnome", Path: ".config/autostart/evil.desktop"},
+				{Kind: "other", Path: ".zshrc"},
+			} {
+				tampered := &snapshot.Snapshot{Files: []snapshot.File{file}}
+				_, err := snapshot.RestoreFiles(tampered, homeDir)
+				Expect(err).To(MatchError(ContainSubstring("security violation")), file.Path)
+			}
+			Expect(filepath.Join(homeDir, ".ssh")).ToNot(BeADirectory())
+		})
+	})
+})
@@ -0,0 +1,157 @@
+package teamsync
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+)
+
+// Tier holds the configuration files of one tier, keyed by path relative to the tier directory
+type Tier map[string][]byte
+
+// ReadTier reads the given files from dir. Missing files are skipped.
+func ReadTier(dir string, files []string) (Tier, error) {
+	tier := make(Tier)
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		tier[file] = data
+	}
+	return tier, nil
+}
+
+// ChangeKind describes how a configuration key changes
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "modified"
+)
+
+// Change is a top-level configuration key whose team value changes
+type Change struct {
+	Key    string
+	Kind   ChangeKind
+	File   string // team file that defines the key after the change, or before it for removals
+	Before string // YAML value before the change
+	After  string // YAML value after the change
+
+	// Shadowed is set when the user configuration defines the key, so the change does
+	// not affect the effective configuration
+	Shadowed bool
+}
+
+// Diff returns a unified diff of the key's value
+func (c Change) Diff() string {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:       difflib.SplitLines(c.Before),
+		B:       difflib.SplitLines(c.After),
+		Context: 2,
+	})
+	if err != nil {
+		return ""
+	}
+	// Drop the empty ---/+++ file headers
+	lines := strings.SplitAfter(diff, "\n")
+	if len(lines) > 2 && strings.HasPrefix(lines[0], "---") {
+		lines = lines[2:]
+	}
+	return strings.Join(lines, "")
+}
+
+// tierValue is the value of a top-level key in the merged team tier
+type tierValue struct {
+	value string
+	file  string
+}
+
+// DiffEffective compares the team tier before and after a sync. Files are merged in the order
+// given, later files replacing top-level keys of earlier ones, which is how the configuration
+// loader applies tiers. Keys defined by the user tier are marked as shadowed.
+func DiffEffective(before, after, user Tier, files []string) ([]Change, error) {
+	beforeValues, err := mergeTier(before, files)
+	if err != nil {
+		return nil, err
+	}
+	afterValues, err := mergeTier(after, files)
+	if err != nil {
+		return nil, err
+	}
+	userValues, err := mergeTier(user, files)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool)
+	for key := range beforeValues {
+		keys[key] = true
+	}
+	for key := range afterValues {
+		keys[key] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	var changes []Change
+	for _, key := range sorted {
+		old, hadOld := beforeValues[key]
+		updated, hasNew := afterValues[key]
+
+		change := Change{Key: key, Before: old.value, After: updated.value, File: updated.file}
+		switch {
+		case !hadOld:
+			change.Kind = ChangeAdded
+		case !hasNew:
+			change.Kind = ChangeRemoved
+			change.File = old.file
+		case old.value != updated.value:
+			change.Kind = ChangeModified
+		default:
+			continue
+		}
+		_, change.Shadowed = userValues[key]
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// mergeTier merges the top-level keys of a tier's files in order
+func mergeTier(tier Tier, files []string) (map[string]tierValue, error) {
+	values := make(map[string]tierValue)
+	for _, file := range files {
+		data, ok := tier[file]
+		if !ok {
+			continue
+		}
+
+		// Decoding into plain values drops comments and formatting, so only
+		// changes that affect the loaded configuration are reported
+		var document map[string]interface{}
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		for key, value := range document {
+			encoded, err := yaml.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode %s in %s: %w", key, file, err)
+			}
+			values[strings.ToLower(key)] = tierValue{value: string(encoded), file: file}
+		}
+	}
+	return values, nil
+}
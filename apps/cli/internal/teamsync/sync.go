@@ -0,0 +1,199 @@
+package teamsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Options configures a sync of the team configuration directory
+type Options struct {
+	Dir string // team configuration directory
+	URL string // repository to clone, or to switch to; empty keeps the current remote
+
+	// Overrides of the stored sync configuration; nil or empty keeps the stored value
+	Ref         string
+	TrustedKeys []string
+	Interval    *time.Duration
+
+	Files   []string // team configuration files in load order, relative to Dir
+	UserDir string   // user configuration directory, used to mark shadowed keys
+
+	// Force discards local edits, replaces a directory that is not a git repository
+	// and allows switching to a different remote
+	Force bool
+}
+
+// Plan is a fetched and verified revision that is ready to be applied
+type Plan struct {
+	Config       Config  // sync configuration saved when the plan is applied
+	Kind         RefKind // what Config.Ref points to
+	Commit       string
+	Previous     string // commit applied before this sync, empty on the first sync
+	Subject      string
+	Signer       string   // fingerprint of the trusted signing key, empty when verification is off
+	Files        []string // changed files as "<status>\t<path>"
+	Changes      []Change // changes to the effective configuration
+	LocalChanges []string // local edits that applying the plan discards
+
+	repo    *Repo
+	dir     string // final team configuration directory
+	staging string // fresh clone that replaces dir on Apply
+}
+
+// UpToDate reports whether the pinned revision is already applied
+func (p *Plan) UpToDate() bool {
+	return p.Commit == p.Previous && len(p.LocalChanges) == 0
+}
+
+// Prepare fetches the team repository, resolves the pinned ref, verifies its signature and
+// computes the changes applying it would make. Nothing in the team directory changes until
+// Apply is called; call Discard to drop a plan that will not be applied.
+func Prepare(ctx context.Context, opts Options) (*Plan, error) {
+	before, err := ReadTier(opts.Dir, opts.Files)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{dir: opts.Dir}
+	repo, err := Open(opts.Dir)
+	switch {
+	case errors.Is(err, ErrNotRepository):
+		if opts.URL == "" {
+			return nil, fmt.Errorf("%w, pass the repository URL to clone it", err)
+		}
+		if entries, readErr := os.ReadDir(opts.Dir); readErr == nil && len(entries) > 0 && !opts.Force {
+			return nil, fmt.Errorf("team directory %s already exists and is not a git repository, use --force to replace it", opts.Dir)
+		}
+		plan.staging = filepath.Join(filepath.Dir(opts.Dir), "."+filepath.Base(opts.Dir)+".sync")
+		if err := os.RemoveAll(plan.staging); err != nil {
+			return nil, fmt.Errorf("failed to remove stale clone: %w", err)
+		}
+		if repo, err = Clone(ctx, opts.URL, plan.staging); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		if err := checkRemote(ctx, repo, opts); err != nil {
+			return nil, err
+		}
+		if plan.LocalChanges, err = repo.LocalChanges(ctx); err != nil {
+			return nil, fmt.Errorf("failed to check for local changes: %w", err)
+		}
+		if len(plan.LocalChanges) > 0 && !opts.Force {
+			return nil, fmt.Errorf("%w: %v, use --force to discard them", ErrLocalChanges, plan.LocalChanges)
+		}
+		if err := repo.Fetch(ctx); err != nil {
+			return nil, err
+		}
+	}
+	plan.repo = repo
+
+	if err := plan.resolve(ctx, opts, before); err != nil {
+		plan.Discard()
+		return nil, err
+	}
+	return plan, nil
+}
+
+// checkRemote switches an existing repository to opts.URL when forced
+func checkRemote(ctx context.Context, repo *Repo, opts Options) error {
+	if opts.URL == "" {
+		return nil
+	}
+	current, err := repo.RemoteURL(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read team repository remote: %w", err)
+	}
+	if current == opts.URL {
+		return nil
+	}
+	if !opts.Force {
+		return fmt.Errorf("team configuration tracks %s, use --force to switch to %s", current, opts.URL)
+	}
+	return repo.SetRemoteURL(ctx, opts.URL)
+}
+
+// resolve pins, verifies and diffs the revision to apply
+func (p *Plan) resolve(ctx context.Context, opts Options, before Tier) error {
+	cfg, err := p.repo.LoadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if opts.Ref != "" {
+		cfg.Ref = opts.Ref
+	}
+	if opts.TrustedKeys != nil {
+		cfg.TrustedKeys = opts.TrustedKeys
+	}
+	if opts.Interval != nil {
+		cfg.Interval = *opts.Interval
+	}
+	if cfg.Ref == "" {
+		if cfg.Ref, err = p.repo.DefaultBranch(ctx); err != nil {
+			return err
+		}
+	}
+	p.Config = cfg
+	p.Previous = cfg.Applied
+
+	if p.Commit, p.Kind, err = p.repo.Resolve(ctx, cfg.Ref); err != nil {
+		return err
+	}
+	p.Subject = p.repo.Subject(ctx, p.Commit)
+
+	if len(cfg.TrustedKeys) > 0 {
+		if p.Signer, err = p.repo.Verify(ctx, cfg.Ref, p.Kind, p.Commit, cfg.TrustedKeys); err != nil {
+			return err
+		}
+	}
+
+	if p.Files, err = p.repo.ChangedFiles(ctx, p.Previous, p.Commit); err != nil {
+		return fmt.Errorf("failed to list changed files: %w", err)
+	}
+
+	after, err := p.repo.ReadTierAt(ctx, p.Commit, opts.Files)
+	if err != nil {
+		return err
+	}
+	user, err := ReadTier(opts.UserDir, opts.Files)
+	if err != nil {
+		return err
+	}
+	p.Changes, err = DiffEffective(before, after, user, opts.Files)
+	return err
+}
+
+// Apply checks out the planned revision and saves the sync configuration
+func (p *Plan) Apply(ctx context.Context) error {
+	if p.staging != "" {
+		if err := os.RemoveAll(p.dir); err != nil {
+			return fmt.Errorf("failed to replace team directory: %w", err)
+		}
+		if err := os.Rename(p.staging, p.dir); err != nil {
+			return fmt.Errorf("failed to move team repository into place: %w", err)
+		}
+		p.repo.Dir = p.dir
+		p.staging = ""
+	}
+
+	if err := p.repo.SaveConfig(ctx, p.Config); err != nil {
+		return err
+	}
+	if err := p.repo.Checkout(ctx, p.Commit); err != nil {
+		return err
+	}
+	return p.repo.MarkSynced(ctx, time.Now())
+}
+
+// Discard removes the fresh clone of a plan that is not applied
+func (p *Plan) Discard() {
+	if p.staging != "" {
+		_ = os.RemoveAll(p.staging)
+		p.staging = ""
+	}
+}
@@ -0,0 +1,366 @@
+// Package teamsync keeps the team configuration directory in sync with a git repository.
+// The directory is pinned to a branch, tag or commit; the pin, the trusted signing keys and
+// the background sync interval are stored in the repository's git config under "devex.".
+package teamsync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// git config keys holding the sync settings
+const (
+	keyRef         = "devex.ref"
+	keyTrustedKey  = "devex.trustedkey"
+	keyInterval    = "devex.syncinterval"
+	keyLastSync    = "devex.lastsync"
+	keyApplied     = "devex.applied"
+	remoteName     = "origin"
+	gitTimeout     = 5 * time.Minute
+	lastSyncFormat = time.RFC3339
+)
+
+// ErrNotRepository is returned when the team directory is not a synced git repository
+var ErrNotRepository = errors.New("team configuration is not a git repository")
+
+// ErrLocalChanges is returned when the team directory has uncommitted edits
+var ErrLocalChanges = errors.New("team configuration has local changes")
+
+// RefKind classifies what a pinned ref points to
+type RefKind string
+
+const (
+	// RefBranch follows the tip of a remote branch on every sync
+	RefBranch RefKind = "branch"
+	// RefTag stays on a tag
+	RefTag RefKind = "tag"
+	// RefCommit stays on a single commit
+	RefCommit RefKind = "commit"
+)
+
+// Config is the sync configuration stored in the team repository
+type Config struct {
+	Ref         string        // branch, tag or commit the team config is pinned to
+	TrustedKeys []string      // GPG or SSH key fingerprints allowed to sign the pinned revision
+	Interval    time.Duration // background sync interval, 0 disables background sync
+	LastSync    time.Time
+	Applied     string // commit currently checked out by sync
+}
+
+// Due reports whether a background sync should run at now
+func (c Config) Due(now time.Time) bool {
+	return c.Interval > 0 && now.Sub(c.LastSync) >= c.Interval
+}
+
+// Repo is a team configuration directory backed by git
+type Repo struct {
+	Dir string
+}
+
+// Open returns the repository in dir, or ErrNotRepository if dir is not a git checkout
+func Open(dir string) (*Repo, error) {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotRepository, dir)
+	}
+	return &Repo{Dir: dir}, nil
+}
+
+// Clone clones url into dir without checking out any files, so that the first revision can
+// be verified and reviewed before it is applied. dir must not exist or be empty.
+func Clone(ctx context.Context, url, dir string) (*Repo, error) {
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("team directory %s exists and is not empty", dir)
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create team directory: %w", err)
+	}
+	if _, err := runGit(ctx, "", "clone", "--no-checkout", "--origin", remoteName, "--", url, dir); err != nil {
+		return nil, fmt.Errorf("failed to clone %s: %w", url, err)
+	}
+	return &Repo{Dir: dir}, nil
+}
+
+// git runs a git command inside the repository and returns its standard output
+func (r *Repo) git(ctx context.Context, args ...string) (string, error) {
+	return runGit(ctx, r.Dir, args...)
+}
+
+// gitCombined runs a git command inside the repository and returns its standard output and error
+func (r *Repo) gitCombined(ctx context.Context, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, gitTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", r.Dir}, args...)...)
+	cmd.Env = gitEnv()
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+// gitEnv disables credential prompts and localized output
+func gitEnv() []string {
+	return append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "LC_ALL=C")
+}
+
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, gitTimeout)
+	defer cancel()
+
+	command := args[0]
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = gitEnv()
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return stdout.String(), fmt.Errorf("git %s: %s", command, msg)
+		}
+		return stdout.String(), fmt.Errorf("git %s: %w", command, err)
+	}
+	return stdout.String(), nil
+}
+
+// RemoteURL returns the URL the repository fetches from
+func (r *Repo) RemoteURL(ctx context.Context) (string, error) {
+	out, err := r.git(ctx, "remote", "get-url", remoteName)
+	return strings.TrimSpace(out), err
+}
+
+// SetRemoteURL points the repository at a different URL
+func (r *Repo) SetRemoteURL(ctx context.Context, url string) error {
+	_, err := r.git(ctx, "remote", "set-url", remoteName, url)
+	return err
+}
+
+// Fetch downloads new commits and tags from the remote
+func (r *Repo) Fetch(ctx context.Context) error {
+	if _, err := r.git(ctx, "fetch", "--prune", "--tags", "--force", remoteName); err != nil {
+		return fmt.Errorf("failed to fetch team configuration: %w", err)
+	}
+	return nil
+}
+
+// DefaultBranch returns the branch the remote's HEAD points to
+func (r *Repo) DefaultBranch(ctx context.Context) (string, error) {
+	out, err := r.git(ctx, "symbolic-ref", "--short", "refs/remotes/"+remoteName+"/HEAD")
+	if err != nil {
+		return "", fmt.Errorf("cannot determine the default branch, pass --ref: %w", err)
+	}
+	return strings.TrimPrefix(strings.TrimSpace(out), remoteName+"/"), nil
+}
+
+// Resolve returns the commit a ref points to. Remote branches take precedence over tags,
+// tags over commits.
+func (r *Repo) Resolve(ctx context.Context, ref string) (string, RefKind, error) {
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return "", "", fmt.Errorf("invalid ref %q", ref)
+	}
+	candidates := []struct {
+		name string
+		kind RefKind
+	}{
+		{"refs/remotes/" + remoteName + "/" + ref, RefBranch},
+		{"refs/tags/" + ref, RefTag},
+		{ref, RefCommit},
+	}
+	for _, candidate := range candidates {
+		out, err := r.git(ctx, "rev-parse", "--verify", "--quiet", candidate.name+"^{commit}")
+		if err == nil {
+			return strings.TrimSpace(out), candidate.kind, nil
+		}
+	}
+	return "", "", fmt.Errorf("ref %q not found in the team repository", ref)
+}
+
+// LocalChanges lists files edited, added or deleted in the working tree since the last sync.
+// A fresh clone that has never been applied has no local changes.
+func (r *Repo) LocalChanges(ctx context.Context) ([]string, error) {
+	cfg, err := r.LoadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Applied == "" {
+		return nil, nil
+	}
+
+	out, err := r.git(ctx, "status", "--porcelain", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	var changes []string
+	for _, line := range strings.Split(out, "\n") {
+		if len(line) > 3 {
+			changes = append(changes, strings.TrimSpace(line[3:]))
+		}
+	}
+	return changes, nil
+}
+
+// Checkout replaces the working tree with commit. Local edits are discarded, so callers must
+// check LocalChanges first.
+func (r *Repo) Checkout(ctx context.Context, commit string) error {
+	if _, err := r.git(ctx, "checkout", "--force", "--detach", commit); err != nil {
+		return fmt.Errorf("failed to check out %s: %w", ShortCommit(commit), err)
+	}
+	if _, err := r.git(ctx, "clean", "-fdq"); err != nil {
+		return fmt.Errorf("failed to remove local files: %w", err)
+	}
+	_, err := r.git(ctx, "config", keyApplied, commit)
+	return err
+}
+
+// LoadConfig reads the sync configuration from the repository's git config
+func (r *Repo) LoadConfig(ctx context.Context) (Config, error) {
+	var cfg Config
+	cfg.Ref = r.configValue(ctx, keyRef)
+	cfg.Applied = r.configValue(ctx, keyApplied)
+
+	if out, err := r.git(ctx, "config", "--get-all", keyTrustedKey); err == nil {
+		for _, key := range strings.Split(out, "\n") {
+			if key = strings.TrimSpace(key); key != "" {
+				cfg.TrustedKeys = append(cfg.TrustedKeys, key)
+			}
+		}
+	}
+	if value := r.configValue(ctx, keyInterval); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid %s %q: %w", keyInterval, value, err)
+		}
+		cfg.Interval = interval
+	}
+	if value := r.configValue(ctx, keyLastSync); value != "" {
+		if lastSync, err := time.Parse(lastSyncFormat, value); err == nil {
+			cfg.LastSync = lastSync
+		}
+	}
+	return cfg, nil
+}
+
+// SaveConfig writes the pin, trusted keys and interval to the repository's git config
+func (r *Repo) SaveConfig(ctx context.Context, cfg Config) error {
+	if _, err := r.git(ctx, "config", keyRef, cfg.Ref); err != nil {
+		return fmt.Errorf("failed to save pinned ref: %w", err)
+	}
+
+	_, _ = r.git(ctx, "config", "--unset-all", keyTrustedKey)
+	for _, key := range cfg.TrustedKeys {
+		if _, err := r.git(ctx, "config", "--add", keyTrustedKey, key); err != nil {
+			return fmt.Errorf("failed to save trusted key: %w", err)
+		}
+	}
+
+	if cfg.Interval > 0 {
+		if _, err := r.git(ctx, "config", keyInterval, cfg.Interval.String()); err != nil {
+			return fmt.Errorf("failed to save sync interval: %w", err)
+		}
+	} else {
+		_, _ = r.git(ctx, "config", "--unset", keyInterval)
+	}
+	return nil
+}
+
+// MarkSynced records the time of the last successful sync
+func (r *Repo) MarkSynced(ctx context.Context, at time.Time) error {
+	_, err := r.git(ctx, "config", keyLastSync, at.UTC().Format(lastSyncFormat))
+	return err
+}
+
+func (r *Repo) configValue(ctx context.Context, key string) string {
+	out, err := r.git(ctx, "config", "--get", key)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// ReadTierAt reads the given files as they are at commit. Files missing at commit are skipped.
+func (r *Repo) ReadTierAt(ctx context.Context, commit string, files []string) (Tier, error) {
+	out, err := r.git(ctx, "ls-tree", "-r", "--name-only", commit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files at %s: %w", ShortCommit(commit), err)
+	}
+	present := make(map[string]bool)
+	for _, name := range strings.Split(out, "\n") {
+		present[name] = true
+	}
+
+	tier := make(Tier)
+	for _, file := range files {
+		if !present[file] {
+			continue
+		}
+		content, err := r.git(ctx, "show", commit+":"+file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s at %s: %w", file, ShortCommit(commit), err)
+		}
+		tier[file] = []byte(content)
+	}
+	return tier, nil
+}
+
+// ChangedFiles lists files that differ between two commits. An empty from lists every file of to.
+func (r *Repo) ChangedFiles(ctx context.Context, from, to string) ([]string, error) {
+	args := []string{"diff", "--name-status", "--no-renames", from, to}
+	if from == "" {
+		args = []string{"ls-tree", "-r", "--name-only", to}
+	}
+	out, err := r.git(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line == "" {
+			continue
+		}
+		if from == "" {
+			line = "A\t" + line
+		}
+		files = append(files, line)
+	}
+	return files, nil
+}
+
+// Subject returns the one-line summary of a commit
+func (r *Repo) Subject(ctx context.Context, commit string) string {
+	out, err := r.git(ctx, "log", "-1", "--format=%s", commit)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// ShortCommit abbreviates a commit hash for display
+func ShortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+// ParseInterval parses a background sync interval such as "6h" or "0" to disable it
+func ParseInterval(value string) (time.Duration, error) {
+	if value == "" || value == "0" {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid sync interval %q: %w", value, err)
+	}
+	if interval < time.Minute {
+		return 0, fmt.Errorf("sync interval %s is too short, use at least 1m", interval)
+	}
+	return interval, nil
+}
@@ -0,0 +1,13 @@
+package teamsync_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTeamsync(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Teamsync Suite")
+}
@@ -0,0 +1,352 @@
+package teamsync_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/teamsync"
+)
+
+var files = []string{"terminal.yaml", "shell.yaml", "environments/dev/terminal.yaml"}
+
+// git runs a git command in dir with a throwaway identity and global config
+func git(dir string, args ...string) string {
+	GinkgoHelper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	out, err := cmd.CombinedOutput()
+	Expect(err).NotTo(HaveOccurred(), string(out))
+	return strings.TrimSpace(string(out))
+}
+
+func writeFile(dir, name, content string) {
+	GinkgoHelper()
+	path := filepath.Join(dir, name)
+	Expect(os.MkdirAll(filepath.Dir(path), 0750)).To(Succeed())
+	Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+}
+
+func commit(dir, message string, extra ...string) string {
+	GinkgoHelper()
+	git(dir, "add", "-A")
+	git(dir, append([]string{"commit", "-q", "-m", message}, extra...)...)
+	return git(dir, "rev-parse", "HEAD")
+}
+
+var _ = Describe("Team sync", func() {
+	var (
+		ctx      context.Context
+		origin   string
+		teamDir  string
+		userDir  string
+		options  teamsync.Options
+		firstRev string
+	)
+
+	BeforeEach(func() {
+		if _, err := exec.LookPath("git"); err != nil {
+			Skip("git is not installed")
+		}
+		ctx = context.Background()
+		root := GinkgoT().TempDir()
+
+		globalConfig := filepath.Join(root, "gitconfig")
+		Expect(os.WriteFile(globalConfig, []byte("[user]\n\tname = Team\n\temail = team@example.com\n"), 0600)).To(Succeed())
+		GinkgoT().Setenv("GIT_CONFIG_GLOBAL", globalConfig)
+		GinkgoT().Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+		origin = filepath.Join(root, "origin")
+		teamDir = filepath.Join(root, "team")
+		userDir = filepath.Join(root, "user")
+		Expect(os.MkdirAll(origin, 0750)).To(Succeed())
+		git(origin, "init", "-q", "-b", "main")
+		writeFile(origin, "terminal.yaml", "apps:\n  - git\n")
+		writeFile(origin, "shell.yaml", "shell: zsh\n")
+		firstRev = commit(origin, "Initial team config")
+
+		options = teamsync.Options{Dir: teamDir, URL: origin, Files: files, UserDir: userDir}
+	})
+
+	sync := func(opts teamsync.Options) *teamsync.Plan {
+		GinkgoHelper()
+		plan, err := teamsync.Prepare(ctx, opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan.Apply(ctx)).To(Succeed())
+		return plan
+	}
+
+	It("clones the default branch without touching the team directory until applied", func() {
+		plan, err := teamsync.Prepare(ctx, options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan.Config.Ref).To(Equal("main"))
+		Expect(plan.Kind).To(Equal(teamsync.RefBranch))
+		Expect(plan.Commit).To(Equal(firstRev))
+		Expect(plan.Changes).To(HaveLen(2))
+		Expect(plan.Changes[0].Key).To(Equal("apps"))
+		Expect(plan.Changes[0].Kind).To(Equal(teamsync.ChangeAdded))
+		Expect(teamDir).NotTo(BeADirectory())
+
+		Expect(plan.Apply(ctx)).To(Succeed())
+		Expect(filepath.Join(teamDir, "shell.yaml")).To(BeARegularFile())
+
+		repo, err := teamsync.Open(teamDir)
+		Expect(err).NotTo(HaveOccurred())
+		cfg, err := repo.LoadConfig(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Ref).To(Equal("main"))
+		Expect(cfg.Applied).To(Equal(firstRev))
+		Expect(cfg.LastSync).To(BeTemporally("~", time.Now(), time.Minute))
+	})
+
+	It("discards a plan that is not applied", func() {
+		plan, err := teamsync.Prepare(ctx, options)
+		Expect(err).NotTo(HaveOccurred())
+		plan.Discard()
+
+		entries, err := os.ReadDir(filepath.Dir(teamDir))
+		Expect(err).NotTo(HaveOccurred())
+		for _, entry := range entries {
+			Expect(entry.Name()).NotTo(ContainSubstring("team"))
+		}
+	})
+
+	It("follows a branch and reports changes to the effective configuration", func() {
+		sync(options)
+		writeFile(origin, "shell.yaml", "shell: fish\n")
+		writeFile(origin, "environments/dev/terminal.yaml", "apps:\n  - git\n  - curl\n")
+		second := commit(origin, "Switch to fish")
+
+		plan, err := teamsync.Prepare(ctx, teamsync.Options{Dir: teamDir, Files: files, UserDir: userDir})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan.Commit).To(Equal(second))
+		Expect(plan.Previous).To(Equal(firstRev))
+		Expect(plan.Files).To(ConsistOf("M\tshell.yaml", "A\tenvironments/dev/terminal.yaml"))
+		Expect(plan.Changes).To(HaveLen(2))
+		Expect(plan.Changes[0].Key).To(Equal("apps"))
+		Expect(plan.Changes[0].File).To(Equal("environments/dev/terminal.yaml"))
+		Expect(plan.Changes[0].Diff()).To(ContainSubstring("+- curl"))
+		Expect(plan.Changes[1].Key).To(Equal("shell"))
+	})
+
+	It("stays on a pinned tag when the branch moves", func() {
+		git(origin, "tag", "v1")
+		writeFile(origin, "shell.yaml", "shell: fish\n")
+		commit(origin, "Switch to fish")
+
+		opts := options
+		opts.Ref = "v1"
+		plan := sync(opts)
+		Expect(plan.Kind).To(Equal(teamsync.RefTag))
+		Expect(plan.Commit).To(Equal(firstRev))
+
+		plan, err := teamsync.Prepare(ctx, teamsync.Options{Dir: teamDir, Files: files, UserDir: userDir})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan.UpToDate()).To(BeTrue())
+	})
+
+	It("pins to a commit", func() {
+		commit(origin, "Empty", "--allow-empty")
+		opts := options
+		opts.Ref = firstRev[:10]
+		plan := sync(opts)
+		Expect(plan.Kind).To(Equal(teamsync.RefCommit))
+		Expect(plan.Commit).To(Equal(firstRev))
+	})
+
+	It("refuses local edits unless forced", func() {
+		sync(options)
+		writeFile(teamDir, "shell.yaml", "shell: bash\n")
+		writeFile(teamDir, "fonts.yaml", "fonts: []\n")
+
+		opts := teamsync.Options{Dir: teamDir, Files: files, UserDir: userDir}
+		_, err := teamsync.Prepare(ctx, opts)
+		Expect(err).To(MatchError(teamsync.ErrLocalChanges))
+
+		opts.Force = true
+		plan := sync(opts)
+		Expect(plan.LocalChanges).To(ConsistOf("shell.yaml", "fonts.yaml"))
+		Expect(os.ReadFile(filepath.Join(teamDir, "shell.yaml"))).To(Equal([]byte("shell: zsh\n")))
+		Expect(filepath.Join(teamDir, "fonts.yaml")).NotTo(BeAnExistingFile())
+	})
+
+	It("refuses to replace a directory that is not a git repository unless forced", func() {
+		writeFile(teamDir, "shell.yaml", "shell: bash\n")
+
+		_, err := teamsync.Prepare(ctx, options)
+		Expect(err).To(MatchError(ContainSubstring("not a git repository")))
+
+		opts := options
+		opts.Force = true
+		plan := sync(opts)
+		Expect(plan.Changes).To(ContainElement(HaveField("Key", "shell")))
+		Expect(os.ReadFile(filepath.Join(teamDir, "shell.yaml"))).To(Equal([]byte("shell: zsh\n")))
+	})
+
+	It("refuses to switch remotes unless forced", func() {
+		sync(options)
+		other := filepath.Join(filepath.Dir(origin), "other")
+		git(filepath.Dir(origin), "clone", "-q", origin, other)
+
+		opts := options
+		opts.URL = other
+		_, err := teamsync.Prepare(ctx, opts)
+		Expect(err).To(MatchError(ContainSubstring("use --force to switch")))
+	})
+
+	It("saves the trusted keys and interval", func() {
+		interval := 6 * time.Hour
+		opts := options
+		opts.Interval = &interval
+		sync(opts)
+
+		repo, err := teamsync.Open(teamDir)
+		Expect(err).NotTo(HaveOccurred())
+		cfg, err := repo.LoadConfig(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Interval).To(Equal(interval))
+		Expect(cfg.Due(time.Now())).To(BeFalse())
+		Expect(cfg.Due(time.Now().Add(7 * time.Hour))).To(BeTrue())
+	})
+
+	It("rejects unsigned revisions when trusted keys are configured", func() {
+		opts := options
+		opts.TrustedKeys = []string{"3AA5C34371567BD2"}
+		_, err := teamsync.Prepare(ctx, opts)
+		Expect(err).To(MatchError(teamsync.ErrUntrustedSignature))
+	})
+
+	Context("with SSH signatures", func() {
+		var fingerprint string
+
+		BeforeEach(func() {
+			if _, err := exec.LookPath("ssh-keygen"); err != nil {
+				Skip("ssh-keygen is not installed")
+			}
+			keyDir := GinkgoT().TempDir()
+			key := filepath.Join(keyDir, "signing")
+			out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "team", "-f", key).CombinedOutput()
+			Expect(err).NotTo(HaveOccurred(), string(out))
+
+			publicKey, err := os.ReadFile(key + ".pub")
+			Expect(err).NotTo(HaveOccurred())
+			allowedSigners := filepath.Join(keyDir, "allowed_signers")
+			Expect(os.WriteFile(allowedSigners, append([]byte("team@example.com "), publicKey...), 0600)).To(Succeed())
+
+			out, err = exec.Command("ssh-keygen", "-l", "-f", key+".pub").CombinedOutput()
+			Expect(err).NotTo(HaveOccurred(), string(out))
+			fingerprint = strings.Fields(string(out))[1]
+
+			git(origin, "config", "--global", "gpg.format", "ssh")
+			git(origin, "config", "--global", "user.signingkey", key)
+			git(origin, "config", "--global", "gpg.ssh.allowedSignersFile", allowedSigners)
+		})
+
+		It("accepts a commit signed by a trusted key", func() {
+			writeFile(origin, "shell.yaml", "shell: fish\n")
+			commit(origin, "Signed change", "-S")
+
+			opts := options
+			opts.TrustedKeys = []string{fingerprint}
+			plan := sync(opts)
+			Expect(plan.Signer).To(Equal(fingerprint))
+		})
+
+		It("verifies the signature of an annotated tag", func() {
+			git(origin, "tag", "-s", "-m", "Release", "v2")
+
+			opts := options
+			opts.Ref = "v2"
+			opts.TrustedKeys = []string{fingerprint}
+			plan := sync(opts)
+			Expect(plan.Signer).To(Equal(fingerprint))
+		})
+
+		It("rejects a commit signed by another key", func() {
+			writeFile(origin, "shell.yaml", "shell: fish\n")
+			commit(origin, "Signed change", "-S")
+
+			opts := options
+			opts.TrustedKeys = []string{"SHA256:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"}
+			_, err := teamsync.Prepare(ctx, opts)
+			Expect(err).To(MatchError(teamsync.ErrUntrustedSignature))
+			Expect(err.Error()).To(ContainSubstring(fingerprint))
+		})
+	})
+})
+
+var _ = Describe("DiffEffective", func() {
+	It("ignores formatting and marks keys overridden by the user", func() {
+		before := teamsync.Tier{"terminal.yaml": []byte("# comment\napps: [git]\nshell: zsh\n")}
+		after := teamsync.Tier{"terminal.yaml": []byte("apps:\n  - git\nshell: fish\nfonts: []\n")}
+		user := teamsync.Tier{"terminal.yaml": []byte("shell: bash\n")}
+
+		changes, err := teamsync.DiffEffective(before, after, user, []string{"terminal.yaml"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changes).To(HaveLen(2))
+		Expect(changes[0].Key).To(Equal("fonts"))
+		Expect(changes[0].Kind).To(Equal(teamsync.ChangeAdded))
+		Expect(changes[0].Shadowed).To(BeFalse())
+		Expect(changes[1].Key).To(Equal("shell"))
+		Expect(changes[1].Kind).To(Equal(teamsync.ChangeModified))
+		Expect(changes[1].Shadowed).To(BeTrue())
+	})
+
+	It("applies later files over earlier ones", func() {
+		order := []string{"terminal.yaml", "environments/dev/terminal.yaml"}
+		before := teamsync.Tier{"terminal.yaml": []byte("shell: zsh\n"), "environments/dev/terminal.yaml": []byte("shell: fish\n")}
+		after := teamsync.Tier{"terminal.yaml": []byte("shell: bash\n"), "environments/dev/terminal.yaml": []byte("shell: fish\n")}
+
+		changes, err := teamsync.DiffEffective(before, after, teamsync.Tier{}, order)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changes).To(BeEmpty())
+	})
+
+	It("reports removed keys", func() {
+		changes, err := teamsync.DiffEffective(
+			teamsync.Tier{"shell.yaml": []byte("shell: zsh\n")},
+			teamsync.Tier{},
+			teamsync.Tier{},
+			[]string{"shell.yaml"},
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changes).To(ConsistOf(HaveField("Kind", teamsync.ChangeRemoved)))
+		Expect(changes[0].File).To(Equal("shell.yaml"))
+	})
+})
+
+var _ = Describe("Trusted keys", func() {
+	DescribeTable("ValidateTrustedKey",
+		func(key string, valid bool) {
+			err := teamsync.ValidateTrustedKey(key)
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("full GPG fingerprint with spaces", "5E6F 7A8B 9C0D 1E2F 3A4B  5C6D 7E8F 9A0B 1C2D 3E4F", true),
+		Entry("long key ID", "0x3AA5C34371567BD2", true),
+		Entry("short key ID", "71567BD2", false),
+		Entry("not hex", "ZZZZC34371567BD2", false),
+		Entry("SSH fingerprint", "SHA256:jHXm5dcJi3IgYJn4DWCnJFsbtmPPrXh9Y2v3Zq0nJfw", true),
+		Entry("truncated SSH fingerprint", "SHA256:abc", false),
+	)
+
+	It("normalizes GPG fingerprints", func() {
+		Expect(teamsync.NormalizeFingerprint(" 0x3aa5 c343 7156 7bd2 ")).To(Equal("3AA5C34371567BD2"))
+		Expect(teamsync.NormalizeFingerprint("SHA256:AbC")).To(Equal("SHA256:AbC"))
+	})
+
+	It("parses background sync intervals", func() {
+		Expect(teamsync.ParseInterval("0")).To(BeZero())
+		Expect(teamsync.ParseInterval("6h")).To(Equal(6 * time.Hour))
+		_, err := teamsync.ParseInterval("10s")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,113 @@
+package teamsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrUntrustedSignature is returned when a revision is unsigned or signed by a key that is not trusted
+var ErrUntrustedSignature = errors.New("revision is not signed by a trusted key")
+
+var (
+	// gpgValidSig matches the machine-readable status line gpg emits for a good signature:
+	// VALIDSIG <signing key fingerprint> ... <primary key fingerprint>
+	gpgValidSig = regexp.MustCompile(`(?m)^\[GNUPG:\] VALIDSIG ([0-9A-Fa-f]+)(?: .* ([0-9A-Fa-f]{32,}))?`)
+
+	// sshGoodSig matches the message git prints for a good SSH signature
+	sshGoodSig = regexp.MustCompile(`Good "git" signature .* with \S+ key (SHA256:[A-Za-z0-9+/=]+)`)
+)
+
+// Verify checks that the pinned revision is signed by one of the trusted keys and returns the
+// fingerprint of the signing key. Annotated tags are verified through the tag signature, every
+// other ref through the signature of the commit it resolves to.
+//
+// GPG keys must be in the user's keyring and SSH keys listed in git's gpg.ssh.allowedSignersFile;
+// the trusted key list then restricts which of those keys may sign team configuration.
+func (r *Repo) Verify(ctx context.Context, ref string, kind RefKind, commit string, trusted []string) (string, error) {
+	args := []string{"verify-commit", "--raw", commit}
+	if kind == RefTag {
+		if objectType, err := r.git(ctx, "cat-file", "-t", "refs/tags/"+ref); err == nil && strings.TrimSpace(objectType) == "tag" {
+			args = []string{"verify-tag", "--raw", "refs/tags/" + ref}
+		}
+	}
+
+	// git reports signature details on stderr
+	out, err := r.gitCombined(ctx, args...)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s has no valid signature", ErrUntrustedSignature, ShortCommit(commit))
+	}
+
+	signers := parseSigners(out)
+	for _, signer := range signers {
+		if isTrusted(signer, trusted) {
+			return signer, nil
+		}
+	}
+	if len(signers) == 0 {
+		return "", fmt.Errorf("%w: %s has no recognizable signature", ErrUntrustedSignature, ShortCommit(commit))
+	}
+	return "", fmt.Errorf("%w: %s is signed by %s", ErrUntrustedSignature, ShortCommit(commit), signers[0])
+}
+
+// parseSigners extracts the fingerprints of valid signatures from git verify output.
+// For GPG both the signing subkey and the primary key fingerprint are returned.
+func parseSigners(output string) []string {
+	var signers []string
+	for _, match := range gpgValidSig.FindAllStringSubmatch(output, -1) {
+		signers = append(signers, strings.ToUpper(match[1]))
+		if match[2] != "" && !strings.EqualFold(match[2], match[1]) {
+			signers = append(signers, strings.ToUpper(match[2]))
+		}
+	}
+	for _, match := range sshGoodSig.FindAllStringSubmatch(output, -1) {
+		signers = append(signers, match[1])
+	}
+	return signers
+}
+
+// isTrusted reports whether a signer fingerprint is in the trusted list. GPG fingerprints are
+// compared case-insensitively ignoring spaces and may be given as a 16-digit long key ID.
+func isTrusted(signer string, trusted []string) bool {
+	for _, key := range trusted {
+		if strings.HasPrefix(key, "SHA256:") {
+			if key == signer {
+				return true
+			}
+			continue
+		}
+		normalized := NormalizeFingerprint(key)
+		if len(normalized) >= 16 && strings.HasSuffix(signer, normalized) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeFingerprint canonicalizes a trusted key entry. GPG fingerprints are upper-cased with
+// spaces and a 0x prefix removed; SSH fingerprints ("SHA256:...") are kept as they are.
+func NormalizeFingerprint(key string) string {
+	key = strings.TrimSpace(key)
+	if strings.HasPrefix(key, "SHA256:") {
+		return key
+	}
+	key = strings.TrimPrefix(strings.TrimPrefix(key, "0x"), "0X")
+	return strings.ToUpper(strings.ReplaceAll(key, " ", ""))
+}
+
+// ValidateTrustedKey checks that a trusted key entry looks like a GPG or SSH fingerprint
+func ValidateTrustedKey(key string) error {
+	normalized := NormalizeFingerprint(key)
+	if strings.HasPrefix(normalized, "SHA256:") {
+		if len(normalized) < len("SHA256:")+20 {
+			return fmt.Errorf("invalid SSH key fingerprint %q", key)
+		}
+		return nil
+	}
+	if len(normalized) < 16 || strings.Trim(normalized, "0123456789ABCDEF") != "" {
+		return fmt.Errorf("invalid GPG key fingerprint %q, use the full fingerprint or a 16-digit key ID", key)
+	}
+	return nil
+}
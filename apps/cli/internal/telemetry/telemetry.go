@@ -0,0 +1,192 @@
+// Package telemetry exports the traces and metrics of a DevEx run, which would otherwise be
+// lost when the process exits.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+
+	"github.com/jameswlane/devex/apps/cli/internal/metrics"
+)
+
+// ServiceName identifies DevEx in traces and metrics
+const ServiceName = "devex"
+
+// metricPrefix prefixes the names of the exported metrics
+const metricPrefix = "devex."
+
+// otlpEndpointVariables configure the OTLP exporters without DevEx configuration
+var otlpEndpointVariables = []string{
+	"OTEL_EXPORTER_OTLP_ENDPOINT",
+	"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT",
+	"OTEL_EXPORTER_OTLP_METRICS_ENDPOINT",
+}
+
+// Config selects the exporters of a run
+type Config struct {
+	// Version is reported as the version of the service
+	Version string
+	// Textfile is the path of a Prometheus textfile written when the run ends, usually in
+	// the directory of the node exporter textfile collector
+	Textfile string
+	// OTLPEndpoint is the base URL of an OTLP/HTTP collector, e.g. http://localhost:4318.
+	// Without it, the OTEL_EXPORTER_OTLP_* environment variables enable the exporter.
+	OTLPEndpoint string
+}
+
+// Telemetry holds the exporters of a run
+type Telemetry struct {
+	config         Config
+	tracerProvider *sdktrace.TracerProvider
+	meterProvider  *sdkmetric.MeterProvider
+}
+
+// Start installs the OTLP exporters as the global OpenTelemetry providers when they are
+// configured. Otherwise spans are not recorded at all.
+func Start(ctx context.Context, config Config) (*Telemetry, error) {
+	t := &Telemetry{config: config}
+	if !otlpEnabled(config) {
+		return t, nil
+	}
+
+	var (
+		traceOptions  []otlptracehttp.Option
+		metricOptions []otlpmetrichttp.Option
+	)
+	if config.OTLPEndpoint != "" {
+		base, err := endpointURL(config.OTLPEndpoint)
+		if err != nil {
+			return nil, err
+		}
+		traceOptions = append(traceOptions, otlptracehttp.WithEndpointURL(base+"/v1/traces"))
+		metricOptions = append(metricOptions, otlpmetrichttp.WithEndpointURL(base+"/v1/metrics"))
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(ServiceName),
+		semconv.ServiceVersion(config.Version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe telemetry resource: %w", err)
+	}
+
+	traceExporter, err := otlptracehttp.New(ctx, traceOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+	metricExporter, err := otlpmetrichttp.New(ctx, metricOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP metric exporter: %w", err)
+	}
+
+	t.tracerProvider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(traceExporter),
+		sdktrace.WithResource(res),
+	)
+	t.meterProvider = sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)),
+		sdkmetric.WithResource(res),
+	)
+	otel.SetTracerProvider(t.tracerProvider)
+	otel.SetMeterProvider(t.meterProvider)
+	return t, nil
+}
+
+// Shutdown exports the metrics recorded during the run and the spans not exported yet. An
+// exporter that fails does not keep the others from exporting.
+func (t *Telemetry) Shutdown(ctx context.Context, recorded []metrics.Metric) error {
+	var errs []error
+	if t.meterProvider != nil {
+		errs = append(errs, recordMetrics(ctx, t.meterProvider.Meter("devex/telemetry"), recorded))
+		errs = append(errs, t.meterProvider.Shutdown(ctx))
+	}
+	if t.tracerProvider != nil {
+		errs = append(errs, t.tracerProvider.Shutdown(ctx))
+	}
+	if t.config.Textfile != "" {
+		errs = append(errs, metrics.WriteTextfile(t.config.Textfile, recorded))
+	}
+	return errors.Join(errs...)
+}
+
+// recordMetrics records the metrics of the collector with OpenTelemetry instruments: counts
+// on counters and durations on histograms in seconds
+func recordMetrics(ctx context.Context, meter metric.Meter, recorded []metrics.Metric) error {
+	counters := map[metrics.MetricType]metric.Float64Counter{}
+	histograms := map[metrics.MetricType]metric.Float64Histogram{}
+
+	var errs []error
+	for _, m := range recorded {
+		name := metricPrefix + string(m.Type)
+		attributes := metric.WithAttributes(tagAttributes(m.Tags)...)
+
+		if m.IsDuration() {
+			histogram, ok := histograms[m.Type]
+			if !ok {
+				var err error
+				if histogram, err = meter.Float64Histogram(name, metric.WithUnit("s")); err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				histograms[m.Type] = histogram
+			}
+			histogram.Record(ctx, m.Duration.Seconds(), attributes)
+			continue
+		}
+
+		counter, ok := counters[m.Type]
+		if !ok {
+			var err error
+			if counter, err = meter.Float64Counter(name); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			counters[m.Type] = counter
+		}
+		counter.Add(ctx, m.Value, attributes)
+	}
+	return errors.Join(errs...)
+}
+
+func tagAttributes(tags map[string]string) []attribute.KeyValue {
+	attributes := make([]attribute.KeyValue, 0, len(tags))
+	for key, value := range tags {
+		attributes = append(attributes, attribute.String(key, value))
+	}
+	return attributes
+}
+
+func otlpEnabled(config Config) bool {
+	if config.OTLPEndpoint != "" {
+		return true
+	}
+	for _, variable := range otlpEndpointVariables {
+		if os.Getenv(variable) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// endpointURL validates the base URL of a collector
+func endpointURL(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid OTLP endpoint %q, expected a URL such as http://localhost:4318", endpoint)
+	}
+	return strings.TrimSuffix(u.String(), "/"), nil
+}
@@ -0,0 +1,169 @@
+package telemetry_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	metricsv1 "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	tracev1 "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/jameswlane/devex/apps/cli/internal/metrics"
+	"github.com/jameswlane/devex/apps/cli/internal/telemetry"
+)
+
+// collector is a local OTLP/HTTP collector keeping the requests it receives
+type collector struct {
+	mu      sync.Mutex
+	traces  []*tracev1.ExportTraceServiceRequest
+	metrics []*metricsv1.ExportMetricsServiceRequest
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch r.URL.Path {
+	case "/v1/traces":
+		request := &tracev1.ExportTraceServiceRequest{}
+		if err := proto.Unmarshal(body, request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		c.traces = append(c.traces, request)
+	case "/v1/metrics":
+		request := &metricsv1.ExportMetricsServiceRequest{}
+		if err := proto.Unmarshal(body, request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		c.metrics = append(c.metrics, request)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(http.StatusOK)
+}
+
+var _ = Describe("Telemetry", func() {
+	var (
+		ctx       context.Context
+		received  *collector
+		server    *httptest.Server
+		tempDir   string
+		installed []metrics.Metric
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		received = &collector{}
+		server = httptest.NewServer(received)
+		tempDir = GinkgoT().TempDir()
+
+		recorder := metrics.NewInMemoryCollector()
+		tags := map[string]string{"installer": "apt", "package": "git"}
+		recorder.RecordCount(metrics.MetricInstallStarted, tags)
+		recorder.RecordCount(metrics.MetricInstallSucceeded, tags)
+		recorder.RecordDuration(metrics.MetricInstallDuration, 2*time.Second, tags)
+		installed = recorder.Metrics()
+	})
+
+	AfterEach(func() {
+		server.Close()
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	})
+
+	It("exports span trees and metrics to an OTLP collector", func() {
+		t, err := telemetry.Start(ctx, telemetry.Config{Version: "1.2.3", OTLPEndpoint: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+
+		tracer := otel.Tracer("devex/test")
+		parentCtx, parent := tracer.Start(ctx, "install_app")
+		_, child := tracer.Start(parentCtx, "install_step.pre-install")
+		child.End()
+		parent.End()
+
+		Expect(t.Shutdown(ctx, installed)).To(Succeed())
+
+		received.mu.Lock()
+		defer received.mu.Unlock()
+
+		spans := map[string][]byte{}
+		parents := map[string][]byte{}
+		for _, request := range received.traces {
+			for _, resourceSpans := range request.GetResourceSpans() {
+				for _, scopeSpans := range resourceSpans.GetScopeSpans() {
+					for _, span := range scopeSpans.GetSpans() {
+						spans[span.GetName()] = span.GetSpanId()
+						parents[span.GetName()] = span.GetParentSpanId()
+					}
+				}
+			}
+		}
+		Expect(spans).To(HaveKey("install_app"))
+		Expect(parents["install_step.pre-install"]).To(Equal(spans["install_app"]))
+
+		var names []string
+		for _, request := range received.metrics {
+			for _, resourceMetrics := range request.GetResourceMetrics() {
+				for _, attribute := range resourceMetrics.GetResource().GetAttributes() {
+					if attribute.GetKey() == "service.name" {
+						Expect(attribute.GetValue().GetStringValue()).To(Equal(telemetry.ServiceName))
+					}
+				}
+				for _, scopeMetrics := range resourceMetrics.GetScopeMetrics() {
+					for _, metric := range scopeMetrics.GetMetrics() {
+						names = append(names, metric.GetName())
+						if metric.GetName() == "devex.install.duration" {
+							Expect(metric.GetUnit()).To(Equal("s"))
+							Expect(metric.GetHistogram().GetDataPoints()[0].GetSum()).To(Equal(2.0))
+						}
+					}
+				}
+			}
+		}
+		Expect(names).To(ContainElements("devex.install.started", "devex.install.succeeded", "devex.install.duration"))
+	})
+
+	It("writes the Prometheus textfile when the run ends", func() {
+		path := filepath.Join(tempDir, "devex.prom")
+		t, err := telemetry.Start(ctx, telemetry.Config{Textfile: path})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(t.Shutdown(ctx, installed)).To(Succeed())
+
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`devex_install_succeeded_total{installer="apt",package="git"} 1`))
+		Expect(received.traces).To(BeEmpty())
+	})
+
+	It("rejects an endpoint that is not an HTTP URL", func() {
+		_, err := telemetry.Start(ctx, telemetry.Config{OTLPEndpoint: "localhost:4318"})
+		Expect(err).To(MatchError(ContainSubstring("invalid OTLP endpoint")))
+	})
+
+	It("does nothing without exporters", func() {
+		t, err := telemetry.Start(ctx, telemetry.Config{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.Shutdown(ctx, installed)).To(Succeed())
+		Expect(received.traces).To(BeEmpty())
+		Expect(received.metrics).To(BeEmpty())
+	})
+})
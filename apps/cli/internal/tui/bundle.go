@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jameswlane/devex/apps/cli/internal/bundle"
+	"github.com/jameswlane/devex/apps/cli/internal/config"
+	"github.com/jameswlane/devex/apps/cli/internal/installers"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+// miseInstallsArchive is the tarball of installed tool versions written by the mise plugin's download command
+const miseInstallsArchive = "mise-installs.tar.gz"
+
+// StartBundleInstallation runs the installation TUI installing every app from the payloads of an
+// extracted offline bundle instead of the network. apps must be configured with the OS
+// configuration recorded in the bundle. Versions are not re-resolved: the bundle holds exactly
+// the packages that were downloaded when it was created.
+func StartBundleInstallation(ctx context.Context, apps []types.CrossPlatformApp, contents *bundle.Bundle, repo types.Repository, settings config.CrossPlatformSettings) error {
+	if contents == nil {
+		return fmt.Errorf("no bundle to install from")
+	}
+	return runInstallation(ctx, apps, repo, settings, nil, nil, contents)
+}
+
+// executeBundleInstall installs an app from its bundled payload
+func (si *StreamingInstaller) executeBundleInstall(ctx context.Context, app types.CrossPlatformApp, osConfig *types.OSConfig) error {
+	bundled, ok := si.bundle.App(app.Name)
+	if !ok {
+		return fmt.Errorf("%s is not included in the bundle", app.Name)
+	}
+	if bundled.Method() != osConfig.InstallMethod {
+		return fmt.Errorf("%s was bundled for %s, not %s", app.Name, bundled.Method(), osConfig.InstallMethod)
+	}
+
+	switch osConfig.InstallMethod {
+	case "apt":
+		return si.executeBundleAptInstall(ctx, bundled, osConfig)
+	case "dnf":
+		rpms := si.bundlePaths(bundled, ".rpm")
+		if len(rpms) == 0 {
+			return fmt.Errorf("the bundle has no packages for %s", app.Name)
+		}
+		si.sendLog("INFO", fmt.Sprintf("Installing %d bundled package(s) for %s", len(rpms), app.Name))
+		return si.executeCommandStream(ctx, "sudo dnf install -y --disablerepo=* "+strings.Join(rpms, " "))
+	case "appimage":
+		appImages := bundledFiles(bundled, ".AppImage")
+		if len(appImages) != 1 {
+			return fmt.Errorf("the bundle has no AppImage for %s", app.Name)
+		}
+		appConfig := types.AppConfig{
+			BaseConfig:     types.BaseConfig{Name: app.Name},
+			InstallMethod:  osConfig.InstallMethod,
+			InstallCommand: osConfig.InstallCommand,
+			DownloadURL:    osConfig.DownloadURL,
+		}
+		// The signature was verified when the bundle was created; the bundle checksum covers the copy
+		return installers.RunInstallCommandWithOptions(ctx, appConfig, map[string]string{
+			"file":   si.bundle.Path(appImages[0]),
+			"sha256": appImages[0].SHA256,
+		}, si.repo)
+	case "curlpipe":
+		scripts := si.bundlePaths(bundled, bundle.ScriptName)
+		if len(scripts) != 1 {
+			return fmt.Errorf("the bundle has no installer script for %s", app.Name)
+		}
+		if osConfig.SHA256 != "" {
+			if err := verifyScriptChecksum(osConfig.DownloadURL, scripts[0], osConfig.SHA256); err != nil {
+				return err
+			}
+		}
+		si.sendLog("INFO", fmt.Sprintf("Running bundled installer script for %s", app.Name))
+		return si.validateExecuteScript(ctx, app.Name, scripts[0])
+	case "mise":
+		archives := si.bundlePaths(bundled, miseInstallsArchive)
+		if len(archives) != 1 {
+			return fmt.Errorf("the bundle has no tool archive for %s", app.Name)
+		}
+		si.sendLog("INFO", fmt.Sprintf("Installing %s from bundled mise tools...", app.Name))
+		miseCommand := fmt.Sprintf(`export PATH="$HOME/.local/bin:$PATH" && data="${MISE_DATA_DIR:-$HOME/.local/share/mise}" && mkdir -p "$data" && tar -xzf %s -C "$data" && if command -v mise >/dev/null 2>&1; then MISE_OFFLINE=1 mise use --global %s; else echo "mise not found in PATH"; exit 1; fi`, archives[0], osConfig.InstallCommand)
+		bashCommand := fmt.Sprintf("bash -c '%s'", strings.ReplaceAll(miseCommand, "'", "'\"'\"'"))
+		return si.executeCommandStream(ctx, bashCommand)
+	default:
+		return fmt.Errorf("install method %s cannot be installed from a bundle", osConfig.InstallMethod)
+	}
+}
+
+// executeBundleAptInstall adds the app's APT sources with their bundled signing keys, so later
+// updates come from the same repositories, and installs the bundled .deb files without downloading
+func (si *StreamingInstaller) executeBundleAptInstall(ctx context.Context, bundled bundle.App, osConfig *types.OSConfig) error {
+	for _, source := range osConfig.AptSources {
+		si.sendLog("INFO", fmt.Sprintf("Adding APT source: %s", source.SourceName))
+
+		if source.KeySource != "" {
+			key, ok := bundled.Key(source.KeySource)
+			if !ok {
+				return fmt.Errorf("the bundle has no signing key for %s", source.SourceName)
+			}
+			if err := si.addBundledGPGKey(ctx, si.bundle.Path(key), source.KeyName, source.RequireDearmor); err != nil {
+				return fmt.Errorf("failed to add GPG key for %s: %w", source.SourceName, err)
+			}
+		}
+
+		if source.SourceRepo != "" {
+			addSourceCmd := fmt.Sprintf("echo '%s' | sudo tee %s > /dev/null",
+				source.SourceRepo, source.SourceName)
+			if err := si.executeCommandStream(ctx, addSourceCmd); err != nil {
+				return fmt.Errorf("failed to add APT source %s: %w", source.SourceName, err)
+			}
+		}
+	}
+
+	debs := si.bundlePaths(bundled, ".deb")
+	if len(debs) == 0 {
+		return fmt.Errorf("the bundle has no packages for %s", bundled.Name)
+	}
+	si.sendLog("INFO", fmt.Sprintf("Installing %d bundled package(s) for %s", len(debs), bundled.Name))
+	return si.executeCommandStream(ctx, "sudo apt-get install -y --no-download "+strings.Join(debs, " "))
+}
+
+// addBundledGPGKey installs a bundled signing key into the APT keyrings
+func (si *StreamingInstaller) addBundledGPGKey(ctx context.Context, keyPath, keyName string, requireDearmor bool) error {
+	checkExistsCmd := fmt.Sprintf("test -f %s", keyName)
+	if err := si.executeCommandStream(ctx, checkExistsCmd); err == nil {
+		si.sendLog("INFO", "GPG key file already exists")
+		return nil
+	}
+
+	if err := si.executeCommandStream(ctx, "sudo mkdir -p /etc/apt/keyrings"); err != nil {
+		return fmt.Errorf("failed to create keyrings directory: %w", err)
+	}
+	if requireDearmor {
+		return si.executeCommandStream(ctx, fmt.Sprintf("sudo gpg --dearmor -o %s %s", keyName, keyPath))
+	}
+	return si.executeCommandStream(ctx, fmt.Sprintf("sudo install -m 0644 %s %s", keyPath, keyName))
+}
+
+// bundlePaths returns the extracted locations of the app's bundled files whose name ends with suffix
+func (si *StreamingInstaller) bundlePaths(bundled bundle.App, suffix string) []string {
+	var paths []string
+	for _, file := range bundledFiles(bundled, suffix) {
+		paths = append(paths, si.bundle.Path(file))
+	}
+	return paths
+}
+
+// bundledFiles returns the app's bundled files whose name ends with suffix
+func bundledFiles(bundled bundle.App, suffix string) []bundle.File {
+	var files []bundle.File
+	for _, file := range bundled.Files {
+		if strings.HasSuffix(file.Name(), suffix) {
+			files = append(files, file)
+		}
+	}
+	return files
+}
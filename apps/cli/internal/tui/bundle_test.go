@@ -0,0 +1,105 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/bundle"
+	"github.com/jameswlane/devex/apps/cli/internal/mocks"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+var _ = Describe("Bundle installation", func() {
+	var (
+		ctx          context.Context
+		mockExecutor *MockCommandExecutorForStreaming
+		installer    *StreamingInstaller
+		contents     *bundle.Bundle
+	)
+
+	const script = "#!/bin/sh\necho installing\n"
+	const scriptSHA256 = "4b4e3f0d1d6ef0ad0c7cbd05dfb8e8fe0e9a0fc0e6dd4a1e1d3f5c04b1a1f1c2"
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		mockExecutor = NewMockCommandExecutorForStreaming()
+		installer = NewStreamingInstallerWithExecutor(nil, mocks.NewMockRepository(), ctx, mockExecutor, getTestSettings())
+		installer.config.InstallationTimeout = 5 * time.Second
+
+		dir := GinkgoT().TempDir()
+		for rel, data := range map[string]string{
+			"apps/editor/editor_1.0_amd64.deb":    "deb",
+			"apps/editor/libeditor_1.0_amd64.deb": "deb",
+			"keys/editor/0-editor.gpg":            "key",
+			"apps/tool/install.sh":                script,
+		} {
+			Expect(os.MkdirAll(filepath.Join(dir, filepath.Dir(rel)), 0750)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, rel), []byte(data), 0600)).To(Succeed())
+		}
+		contents = &bundle.Bundle{Dir: dir, Manifest: bundle.Manifest{Apps: []bundle.App{
+			{
+				Name:   "editor",
+				Config: types.OSConfig{InstallMethod: "apt"},
+				Files: []bundle.File{
+					{Path: "apps/editor/editor_1.0_amd64.deb"},
+					{Path: "apps/editor/libeditor_1.0_amd64.deb"},
+				},
+				Keys: []bundle.File{{Path: "keys/editor/0-editor.gpg", Source: "https://example.com/key.gpg"}},
+			},
+			{
+				Name:   "tool",
+				Config: types.OSConfig{InstallMethod: "curlpipe"},
+				Files:  []bundle.File{{Path: "apps/tool/install.sh"}},
+			},
+		}}}
+		installer.bundle = contents
+	})
+
+	It("should install bundled packages with their signing keys without downloading", func() {
+		app := types.CrossPlatformApp{Name: "editor"}
+		osConfig := &types.OSConfig{
+			InstallMethod:  "apt",
+			InstallCommand: "editor",
+			AptSources: []types.AptSource{{
+				KeySource:  "https://example.com/key.gpg",
+				KeyName:    "/etc/apt/keyrings/editor.gpg",
+				SourceRepo: "deb [signed-by=/etc/apt/keyrings/editor.gpg] https://example.com/apt stable main",
+				SourceName: "/etc/apt/sources.list.d/editor.list",
+			}},
+		}
+
+		Expect(installer.executeInstallCommand(ctx, app, osConfig)).To(Succeed())
+
+		commands := mockExecutor.GetCommands()
+		Expect(commands).NotTo(ContainElement(ContainSubstring("curl")))
+		Expect(commands).NotTo(ContainElement("sudo apt-get update"))
+		Expect(commands[len(commands)-1]).To(Equal("sudo apt-get install -y --no-download " +
+			filepath.Join(contents.Dir, "apps/editor/editor_1.0_amd64.deb") + " " +
+			filepath.Join(contents.Dir, "apps/editor/libeditor_1.0_amd64.deb")))
+
+		validator := NewDefaultCommandExecutor()
+		for _, command := range commands {
+			Expect(validator.ValidateCommand(command)).To(Succeed(), command)
+		}
+	})
+
+	It("should refuse bundled scripts that do not match the pinned checksum", func() {
+		app := types.CrossPlatformApp{Name: "tool"}
+		osConfig := &types.OSConfig{InstallMethod: "curlpipe", DownloadURL: "https://example.com/install.sh", SHA256: scriptSHA256}
+
+		Expect(installer.executeInstallCommand(ctx, app, osConfig)).To(MatchError(ContainSubstring("changed: expected sha256 " + scriptSHA256)))
+		Expect(mockExecutor.GetCommands()).To(BeEmpty())
+	})
+
+	It("should refuse apps that are not in the bundle", func() {
+		app := types.CrossPlatformApp{Name: "missing"}
+		err := installer.executeInstallCommand(ctx, app, &types.OSConfig{InstallMethod: "apt", InstallCommand: "missing"})
+		Expect(err).To(MatchError(ContainSubstring("not included in the bundle")))
+		Expect(mockExecutor.GetCommands()).To(BeEmpty())
+	})
+})
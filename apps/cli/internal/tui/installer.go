@@ -3,6 +3,8 @@ package tui
 import (
 	"bufio"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -20,16 +22,30 @@ import (
 	"unicode"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jameswlane/devex/apps/cli/internal/audit"
+	"github.com/jameswlane/devex/apps/cli/internal/bootstrap"
+	"github.com/jameswlane/devex/apps/cli/internal/bundle"
+	"github.com/jameswlane/devex/apps/cli/internal/cache"
 	"github.com/jameswlane/devex/apps/cli/internal/config"
+	"github.com/jameswlane/devex/apps/cli/internal/installers"
+	"github.com/jameswlane/devex/apps/cli/internal/lockfile"
 	"github.com/jameswlane/devex/apps/cli/internal/log"
+	"github.com/jameswlane/devex/apps/cli/internal/metrics"
 	"github.com/jameswlane/devex/apps/cli/internal/performance"
 	"github.com/jameswlane/devex/apps/cli/internal/platform"
 	progresspkg "github.com/jameswlane/devex/apps/cli/internal/progress"
 	"github.com/jameswlane/devex/apps/cli/internal/security"
 	"github.com/jameswlane/devex/apps/cli/internal/types"
+	"github.com/jameswlane/devex/apps/cli/internal/undo"
 	"github.com/jameswlane/devex/apps/cli/internal/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("devex/tui/installer")
+
 // Command constants for improved maintainability
 const (
 	// Package managers
@@ -87,6 +103,9 @@ type InstallerConfig struct {
 
 	// Error handling
 	FailOnDatabaseErrors bool // Whether database errors should fail the installation
+
+	// Scheduling
+	MaxParallelInstalls int // Maximum number of apps installed concurrently
 }
 
 // DefaultInstallerConfig returns the default configuration
@@ -101,6 +120,7 @@ func DefaultInstallerConfig() InstallerConfig {
 		MaxLogLines:          1000,
 		HTTPTimeout:          30 * time.Second,
 		FailOnDatabaseErrors: false, // By default, don't fail on DB errors
+		MaxParallelInstalls:  4,
 	}
 }
 
@@ -226,6 +246,10 @@ type StreamingInstaller struct {
 	config              InstallerConfig                  // Configuration settings
 	performanceAnalyzer *performance.PerformanceAnalyzer // Performance analysis and warnings
 	progressManager     *progresspkg.ProgressManager     // Optional progress manager for enhanced tracking
+	journal             *installJournal                  // Optional session journal for resuming interrupted runs
+	versions            *versionPinner                   // Optional version pinning and devex.lock recording
+	scriptCache         *cache.CacheManager              // Approved copies of hash-pinned installer scripts
+	bundle              *bundle.Bundle                   // Optional offline bundle that payloads are installed from
 }
 
 // SecureString represents a string that should be scrubbed from memory to prevent
@@ -537,6 +561,7 @@ func NewStreamingInstaller(program *tea.Program, repo types.Repository, ctx cont
 		cancel:              cancel,
 		config:              DefaultInstallerConfig(),
 		performanceAnalyzer: analyzer,
+		scriptCache:         newScriptCache(settings),
 	}
 }
 
@@ -559,6 +584,7 @@ func NewStreamingInstallerWithSecureExecutor(program *tea.Program, repo types.Re
 		cancel:              cancel,
 		config:              DefaultInstallerConfig(),
 		performanceAnalyzer: analyzer,
+		scriptCache:         newScriptCache(settings),
 	}
 }
 
@@ -581,7 +607,19 @@ func NewStreamingInstallerWithExecutor(program *tea.Program, repo types.Reposito
 		cancel:              cancel,
 		config:              DefaultInstallerConfig(),
 		performanceAnalyzer: analyzer,
+		scriptCache:         newScriptCache(settings),
+	}
+}
+
+// newScriptCache opens the cache holding approved installer scripts. Without it, pinned
+// scripts are still verified but always downloaded.
+func newScriptCache(settings config.CrossPlatformSettings) *cache.CacheManager {
+	cacheManager, err := cache.NewCacheManager(settings)
+	if err != nil {
+		log.Warn("Failed to initialize script cache", "error", err)
+		return nil
 	}
+	return cacheManager
 }
 
 // SetProgressManager sets the progress manager for enhanced progress tracking
@@ -594,61 +632,6 @@ func (si *StreamingInstaller) GetProgressManager() *progresspkg.ProgressManager
 	return si.progressManager
 }
 
-// InstallApps installs multiple applications sequentially with streaming output and context cancellation.
-// It processes each app in the provided slice, handling errors and context cancellation gracefully.
-// If context cancellation occurs, the installation stops immediately and returns the cancellation error.
-// Individual app failures are logged but don't stop the overall installation process, unless caused by cancellation.
-//
-// Parameters:
-//   - ctx: Context for cancellation and timeout control
-//   - apps: Slice of CrossPlatformApp configurations to install
-//   - settings: Installation settings including verbosity and dry-run flags
-//
-// Returns:
-//   - error: nil on success, context.Canceled if cancelled, or other error on critical failures
-func (si *StreamingInstaller) InstallApps(ctx context.Context, apps []types.CrossPlatformApp, settings config.CrossPlatformSettings) error {
-	for i, app := range apps {
-		// Check for context cancellation before each app
-		select {
-		case <-ctx.Done():
-			si.sendLog("INFO", "Installation cancelled before starting next app")
-			return ctx.Err()
-		default:
-		}
-
-		// Send app started message to update TUI display
-		if si.program != nil {
-			si.program.Send(AppStartedMsg{
-				AppName:  app.Name,
-				AppIndex: i,
-			})
-		}
-
-		if err := si.InstallApp(ctx, app, settings); err != nil {
-			si.sendLog("ERROR", fmt.Sprintf("Failed to install %s: %v", app.Name, err))
-			if si.program != nil {
-				si.program.Send(AppCompleteMsg{
-					AppName: app.Name,
-					Error:   err,
-				})
-			}
-			// If the error is due to context cancellation, stop installation entirely
-			if errors.Is(err, context.Canceled) || ctx.Err() != nil {
-				return ctx.Err()
-			}
-			continue
-		}
-
-		if si.program != nil {
-			si.program.Send(AppCompleteMsg{
-				AppName: app.Name,
-				Error:   nil,
-			})
-		}
-	}
-	return nil
-}
-
 // InstallApp installs a single application with streaming output and comprehensive error handling.
 // It executes the complete installation lifecycle: validation, pre-install commands, main installation,
 // post-install commands, and database registration. All command execution is validated for security.
@@ -663,13 +646,40 @@ func (si *StreamingInstaller) InstallApps(ctx context.Context, apps []types.Cros
 //   - Database registration of the successfully installed app
 //   - Post-installation performance tracking
 //
+// When the installer journals a resumed session, steps that already finished for the app are not run again.
+// Packages are pinned to the app's version constraint (or locked version) and the installed versions are
+// recorded for devex.lock.
+//
 // Parameters:
 //   - app: CrossPlatformApp configuration containing installation instructions
 //   - settings: Installation settings including verbosity flags
 //
+// The installation and each of its steps are traced as spans, and its outcome and duration are
+// recorded in the metrics of the run.
+//
 // Returns:
 //   - error: nil on success, or detailed error indicating which phase failed
 func (si *StreamingInstaller) InstallApp(ctx context.Context, app types.CrossPlatformApp, settings config.CrossPlatformSettings) error {
+	installMethod := app.GetOSConfig().InstallMethod
+	ctx, span := tracer.Start(ctx, "install_app", trace.WithAttributes(
+		attribute.String("app", app.Name),
+		attribute.String("install_method", installMethod),
+	))
+	defer span.End()
+
+	timer := metrics.StartInstallation(installMethod, app.Name)
+	if err := si.installApp(ctx, app, settings); err != nil {
+		timer.Failure(err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Installation failed")
+		return err
+	}
+	timer.Success()
+	return nil
+}
+
+// installApp runs the installation lifecycle of InstallApp
+func (si *StreamingInstaller) installApp(ctx context.Context, app types.CrossPlatformApp, settings config.CrossPlatformSettings) error {
 	si.sendLog("INFO", fmt.Sprintf("Starting installation of %s", app.Name))
 
 	startTime := time.Now()
@@ -726,34 +736,73 @@ func (si *StreamingInstaller) InstallApp(ctx context.Context, app types.CrossPla
 	}
 
 	// Handle pre-install commands
-	if len(osConfig.PreInstall) > 0 {
-		si.sendLog("INFO", "Executing pre-install commands...")
-		if err := si.executeCommands(ctx, osConfig.PreInstall); err != nil {
-			si.recordFailedInstallation(app.Name, startTime, err)
-			return fmt.Errorf("pre-install failed: %w", err)
+	if si.journal.needsStep(app.Name, types.InstallStepPreInstall) {
+		if len(osConfig.PreInstall) > 0 {
+			si.sendLog("INFO", "Executing pre-install commands...")
+			if err := traceStep(ctx, app.Name, types.InstallStepPreInstall, func(ctx context.Context) error {
+				return si.executeCommands(audit.WithTrigger(ctx, app.Name, string(types.InstallStepPreInstall)), osConfig.PreInstall)
+			}); err != nil {
+				si.recordFailedInstallation(app.Name, startTime, err)
+				return fmt.Errorf("pre-install failed: %w", err)
+			}
 		}
+		si.journal.stepFinished(app.Name, types.InstallStepPreInstall)
 	}
 
 	// Check and install platform dependencies before main installation
-	if err := si.checkAndInstallDependencies(ctx, osConfig); err != nil {
-		si.recordFailedInstallation(app.Name, startTime, err)
-		return fmt.Errorf("dependency checking failed: %w", err)
+	if si.journal.needsStep(app.Name, types.InstallStepDependencies) {
+		if err := traceStep(ctx, app.Name, types.InstallStepDependencies, func(ctx context.Context) error {
+			return si.checkAndInstallDependencies(audit.WithTrigger(ctx, app.Name, string(types.InstallStepDependencies)), osConfig)
+		}); err != nil {
+			si.recordFailedInstallation(app.Name, startTime, err)
+			return fmt.Errorf("dependency checking failed: %w", err)
+		}
+		si.journal.stepFinished(app.Name, types.InstallStepDependencies)
 	}
 
 	// Execute main installation command
-	si.sendLog("INFO", fmt.Sprintf("Installing %s using %s...", app.Name, osConfig.InstallMethod))
-	if err := si.executeInstallCommand(ctx, app, &osConfig); err != nil {
-		si.recordFailedInstallation(app.Name, startTime, err)
-		return fmt.Errorf("installation failed: %w", err)
+	if si.journal.needsStep(app.Name, types.InstallStepInstall) {
+		si.sendLog("INFO", fmt.Sprintf("Installing %s using %s...", app.Name, osConfig.InstallMethod))
+		command, err := si.pinVersions(app, osConfig)
+		if err != nil {
+			si.recordFailedInstallation(app.Name, startTime, err)
+			return fmt.Errorf("version resolution failed: %w", err)
+		}
+		pinnedConfig := osConfig
+		pinnedConfig.InstallCommand = command
+		newPackage := si.isNewPackage(ctx, osConfig)
+		if err := traceStep(ctx, app.Name, types.InstallStepInstall, func(ctx context.Context) error {
+			return si.executeInstallCommand(audit.WithTrigger(ctx, app.Name, string(types.InstallStepInstall)), app, &pinnedConfig)
+		}); err != nil {
+			si.recordFailedInstallation(app.Name, startTime, err)
+			return fmt.Errorf("installation failed: %w", err)
+		}
+		if newPackage {
+			undo.ChangeSetFrom(ctx).Add(undo.Change{
+				Kind:   undo.ChangePackageInstalled,
+				App:    app.Name,
+				Method: osConfig.InstallMethod,
+				Target: osConfig.InstallCommand,
+			})
+		}
+		si.journal.stepFinished(app.Name, types.InstallStepInstall)
+	} else {
+		si.sendLog("INFO", fmt.Sprintf("%s was installed by the interrupted session, resuming after installation", app.Name))
 	}
 
 	// Handle post-install commands
-	if len(osConfig.PostInstall) > 0 {
-		si.sendLog("INFO", "Executing post-install commands...")
-		if err := si.executeCommands(ctx, osConfig.PostInstall); err != nil {
+	if si.journal.needsStep(app.Name, types.InstallStepPostInstall) {
+		if err := traceStep(ctx, app.Name, types.InstallStepPostInstall, func(ctx context.Context) error {
+			if len(osConfig.PostInstall) == 0 {
+				return nil
+			}
+			si.sendLog("INFO", "Executing post-install commands...")
+			return si.executeCommands(audit.WithTrigger(ctx, app.Name, string(types.InstallStepPostInstall)), osConfig.PostInstall)
+		}); err != nil {
 			si.recordFailedInstallation(app.Name, startTime, err)
 			return fmt.Errorf("post-install failed: %w", err)
 		}
+		si.journal.stepFinished(app.Name, types.InstallStepPostInstall)
 	}
 
 	// Apply selected theme if user made a choice and themes are available
@@ -788,8 +837,12 @@ func (si *StreamingInstaller) InstallApp(ctx context.Context, app types.CrossPla
 	} else {
 		si.sendLog("INFO", fmt.Sprintf("App %s (via %s) registered in database successfully",
 			app.Name, si.getInstallerType()))
+		si.journal.stepFinished(app.Name, types.InstallStepRegister)
 	}
 
+	// Record the exact installed versions for devex.lock
+	si.recordVersions(app, osConfig)
+
 	// Record post-installation performance metrics
 	if si.performanceAnalyzer != nil {
 		// Estimate download size (we don't have exact size without more complex tracking)
@@ -809,6 +862,22 @@ func (si *StreamingInstaller) InstallApp(ctx context.Context, app types.CrossPla
 	return nil
 }
 
+// traceStep runs a step of an app installation in its own span
+func traceStep(ctx context.Context, appName string, step types.InstallStep, run func(context.Context) error) error {
+	ctx, span := tracer.Start(ctx, "install_step."+string(step), trace.WithAttributes(
+		attribute.String("app", appName),
+		attribute.String("step", string(step)),
+	))
+	defer span.End()
+
+	if err := run(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Installation step failed")
+		return err
+	}
+	return nil
+}
+
 // recordFailedInstallation records performance metrics for failed installations
 func (si *StreamingInstaller) recordFailedInstallation(appName string, startTime time.Time, err error) {
 	if si.performanceAnalyzer != nil {
@@ -819,8 +888,26 @@ func (si *StreamingInstaller) recordFailedInstallation(appName string, startTime
 	}
 }
 
+// isNewPackage reports whether installing osConfig adds a package the operation's change set
+// should record: the install method has an installer and the package was not installed before
+func (si *StreamingInstaller) isNewPackage(ctx context.Context, osConfig types.OSConfig) bool {
+	if undo.ChangeSetFrom(ctx) == nil {
+		return false
+	}
+	installer := installers.GetInstaller(ctx, osConfig.InstallMethod)
+	if installer == nil {
+		return false
+	}
+	installed, err := installer.IsInstalled(osConfig.InstallCommand)
+	return err == nil && !installed
+}
+
 // executeInstallCommand executes the main installation command
 func (si *StreamingInstaller) executeInstallCommand(ctx context.Context, app types.CrossPlatformApp, osConfig *types.OSConfig) error {
+	if si.bundle != nil {
+		return si.executeBundleInstall(ctx, app, osConfig)
+	}
+
 	switch osConfig.InstallMethod {
 	case "apt":
 		return si.executeAptInstall(ctx, app, osConfig)
@@ -828,6 +915,8 @@ func (si *StreamingInstaller) executeInstallCommand(ctx context.Context, app typ
 		return si.executeCurlPipeInstall(ctx, app, osConfig)
 	case "docker":
 		return si.executeDockerInstall(ctx, app, osConfig)
+	case "appimage":
+		return si.executeAppImageInstall(ctx, app, osConfig)
 	case "mise":
 		return si.executeMiseInstall(ctx, app, osConfig)
 	case "dnf", "yum", "pacman", "zypper", "brew", "apk", "emerge", "eopkg", "flatpak", "snap", "xbps", "yay":
@@ -857,11 +946,15 @@ func (si *StreamingInstaller) executeAptInstall(ctx context.Context, app types.C
 
 		// Add the repository source
 		if source.SourceRepo != "" {
+			_, statErr := os.Stat(source.SourceName)
 			addSourceCmd := fmt.Sprintf("echo '%s' | sudo tee %s > /dev/null",
 				source.SourceRepo, source.SourceName)
 			if err := si.executeCommandStream(ctx, addSourceCmd); err != nil {
 				return fmt.Errorf("failed to add APT source %s: %w", source.SourceName, err)
 			}
+			if errors.Is(statErr, os.ErrNotExist) {
+				undo.ChangeSetFrom(ctx).Add(undo.Change{Kind: undo.ChangeRepository, App: app.Name, Target: source.SourceName})
+			}
 		}
 	}
 
@@ -899,17 +992,22 @@ func (si *StreamingInstaller) addModernGPGKey(ctx context.Context, keyURL, keyNa
 		return fmt.Errorf("failed to create keyrings directory: %w", err)
 	}
 
+	var err error
 	if requireDearmor {
 		// Download and dearmor the key in one command
 		downloadAndDearmorCmd := fmt.Sprintf("curl -fsSL %s | sudo gpg --dearmor -o %s", keyURL, keyName)
 		si.sendLog("INFO", fmt.Sprintf("Downloading and dearmorying GPG key: %s", downloadAndDearmorCmd))
-		return si.executeCommandStream(ctx, downloadAndDearmorCmd)
+		err = si.executeCommandStream(ctx, downloadAndDearmorCmd)
 	} else {
 		// Download the key directly
 		downloadCmd := fmt.Sprintf("curl -fsSL %s | sudo tee %s > /dev/null", keyURL, keyName)
 		si.sendLog("INFO", fmt.Sprintf("Downloading GPG key: %s", downloadCmd))
-		return si.executeCommandStream(ctx, downloadCmd)
+		err = si.executeCommandStream(ctx, downloadCmd)
+	}
+	if err == nil {
+		undo.ChangeSetFrom(ctx).Add(undo.Change{Kind: undo.ChangeKey, Target: keyName})
 	}
+	return err
 }
 
 // executePackageManagerInstall handles package manager installations with intelligent updates
@@ -964,6 +1062,10 @@ func (si *StreamingInstaller) executeCurlPipeInstall(ctx context.Context, app ty
 		return fmt.Errorf("URL validation failed: %w", err)
 	}
 
+	if osConfig.SHA256 != "" {
+		return si.executePinnedScript(ctx, app.Name, osConfig.DownloadURL, osConfig.SHA256)
+	}
+
 	si.sendLog("INFO", fmt.Sprintf("Downloading from validated URL: %s", osConfig.DownloadURL))
 
 	// SECURITY ENHANCEMENT: Download-validate-execute pattern instead of direct pipe
@@ -987,15 +1089,79 @@ func (si *StreamingInstaller) downloadValidateExecute(ctx context.Context, appNa
 		return fmt.Errorf("failed to download script: %w", err)
 	}
 
+	return si.validateExecuteScript(ctx, appName, tmpFile)
+}
+
+// executePinnedScript runs an installer script whose SHA-256 is pinned in the app configuration
+// SECURITY: The approved copy in the cache is reused while it matches the pin, so re-runs and
+// offline reinstalls execute the reviewed bytes. A fresh download that does not match the pin
+// is never executed.
+func (si *StreamingInstaller) executePinnedScript(ctx context.Context, appName, downloadURL, checksum string) error {
+	if si.scriptCache != nil {
+		entry, err := si.scriptCache.ApprovedScript(downloadURL, checksum)
+		if err != nil {
+			si.sendLog("WARN", fmt.Sprintf("Failed to read script cache: %v", err))
+		} else if entry != nil {
+			si.sendLog("INFO", fmt.Sprintf("Using approved copy of %s (sha256 %s)", downloadURL, entry.Checksum))
+			return si.validateExecuteScript(ctx, appName, entry.Path)
+		}
+	}
+
+	si.sendLog("INFO", fmt.Sprintf("Downloading pinned script from validated URL: %s", downloadURL))
+
+	tmpFile, err := si.createTempScript()
+	if err != nil {
+		si.sendLog("ERROR", fmt.Sprintf("Failed to create temporary script file: %v", err))
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer si.cleanupTempScript(tmpFile)
+
+	if err := si.downloadScript(downloadURL, tmpFile); err != nil {
+		si.sendLog("ERROR", fmt.Sprintf("Failed to download script: %v", err))
+		return fmt.Errorf("failed to download script: %w", err)
+	}
+
+	if si.scriptCache == nil {
+		if err := verifyScriptChecksum(downloadURL, tmpFile, checksum); err != nil {
+			si.sendLog("ERROR", err.Error())
+			return err
+		}
+		return si.validateExecuteScript(ctx, appName, tmpFile)
+	}
+
+	entry, err := si.scriptCache.ApproveScript(downloadURL, tmpFile, checksum, map[string]string{"app": appName})
+	if err != nil {
+		si.sendLog("ERROR", fmt.Sprintf("Script verification failed for %s: %v", appName, err))
+		return fmt.Errorf("script verification failed: %w", err)
+	}
+	si.sendLog("INFO", fmt.Sprintf("Script matches pinned sha256, stored approved copy for %s", appName))
+	return si.validateExecuteScript(ctx, appName, entry.Path)
+}
+
+// verifyScriptChecksum checks a downloaded script against its pinned SHA-256
+func verifyScriptChecksum(downloadURL, path, checksum string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read script: %w", err)
+	}
+	sum := sha256.Sum256(content)
+	if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, checksum) {
+		return &cache.ScriptMismatchError{URL: downloadURL, Expected: strings.ToLower(checksum), Actual: actual}
+	}
+	return nil
+}
+
+// validateExecuteScript validates a downloaded script and executes it
+func (si *StreamingInstaller) validateExecuteScript(ctx context.Context, appName, scriptPath string) error {
 	// Validate script content for basic safety
-	if err := si.validateScriptContent(tmpFile); err != nil {
+	if err := si.validateScriptContent(scriptPath); err != nil {
 		si.sendLog("ERROR", fmt.Sprintf("Script validation failed: %v", err))
 		return fmt.Errorf("script validation failed: %w", err)
 	}
 
 	// Execute validated script
 	si.sendLog("INFO", fmt.Sprintf("Executing validated script for %s", appName))
-	command := fmt.Sprintf("bash %s", tmpFile)
+	command := fmt.Sprintf("bash %s", scriptPath)
 	return si.executeCommandStream(ctx, command)
 }
 
@@ -1140,7 +1306,33 @@ func (si *StreamingInstaller) cleanupTempScript(filepath string) {
 // executeDockerInstall handles Docker container installations
 func (si *StreamingInstaller) executeDockerInstall(ctx context.Context, app types.CrossPlatformApp, osConfig *types.OSConfig) error {
 	si.sendLog("INFO", fmt.Sprintf("Starting Docker installation for %s", app.Name))
-	return si.executeCommandStream(ctx, osConfig.InstallCommand)
+
+	// The docker plugin runs the container from its spec, so repeated installs reuse it
+	return si.executePluginInstall(ctx, app, osConfig)
+}
+
+// executeAppImageInstall downloads an AppImage through the appimage plugin, which verifies
+// the configured checksum and signature before making it executable
+func (si *StreamingInstaller) executeAppImageInstall(ctx context.Context, app types.CrossPlatformApp, osConfig *types.OSConfig) error {
+	si.sendLog("INFO", fmt.Sprintf("Downloading AppImage for %s", app.Name))
+	return si.executePluginInstall(ctx, app, osConfig)
+}
+
+// executePluginInstall installs an app with the installer of its install method, passing the
+// settings that method understands
+func (si *StreamingInstaller) executePluginInstall(ctx context.Context, app types.CrossPlatformApp, osConfig *types.OSConfig) error {
+	appConfig := types.AppConfig{
+		BaseConfig:     types.BaseConfig{Name: app.Name},
+		InstallMethod:  osConfig.InstallMethod,
+		InstallCommand: osConfig.InstallCommand,
+		DockerOptions:  osConfig.DockerOptions,
+		DownloadURL:    osConfig.DownloadURL,
+		SHA256:         osConfig.SHA256,
+		Signature:      osConfig.Signature,
+		SignatureKey:   osConfig.SignatureKey,
+	}
+	// Messages the plugin streams while it installs are shown in the installer log
+	return installers.RunInstallCommand(installers.WithOutput(ctx, si.sendLog), appConfig, si.repo)
 }
 
 // executeMiseInstall handles mise tool installations with proper command construction
@@ -1234,9 +1426,10 @@ func (si *StreamingInstaller) executeCommandStream(ctx context.Context, command
 		return err
 	}
 
-	// Start command
+	// Start command, recording it in the audit log once it finished
+	execution := audit.Begin(timeoutCtx, cmd)
 	if err := cmd.Start(); err != nil {
-		return err
+		return execution.End(err)
 	}
 
 	// Use WaitGroup to ensure all goroutines complete
@@ -1261,7 +1454,7 @@ func (si *StreamingInstaller) executeCommandStream(ctx context.Context, command
 	}()
 
 	// Wait for command completion
-	cmdErr := cmd.Wait()
+	cmdErr := execution.End(cmd.Wait())
 
 	// Note: cmd.Wait() automatically closes stdout, stderr, and stdin pipes
 	// so we don't need to close them manually to avoid "file already closed" errors
@@ -1531,9 +1724,47 @@ func (si *StreamingInstaller) sendLog(level, message string) {
 //   - repo: Repository interface for app state persistence
 //   - settings: Installation settings including verbosity and dry-run options
 //
+// Progress is journaled as an installation session when the repository supports it. An incomplete
+// earlier session is never resumed here; a hint points to 'devex install --resume' (ResumeInstallation).
+//
 // Returns:
 //   - error: nil on successful TUI completion, or error from TUI framework or installation
 func StartInstallation(ctx context.Context, apps []types.CrossPlatformApp, repo types.Repository, settings config.CrossPlatformSettings) error {
+	return runInstallation(ctx, apps, repo, settings, nil, openVersionPinner(ctx, settings), nil)
+}
+
+// StartLockedInstallation runs the installation TUI installing the exact package versions recorded in
+// lock. Apps missing from the lockfile, or locked with a different install method, are installed at their
+// configured constraints with a warning. The lockfile itself is not modified.
+func StartLockedInstallation(ctx context.Context, apps []types.CrossPlatformApp, lock *lockfile.Lockfile, repo types.Repository, settings config.CrossPlatformSettings) error {
+	if lock == nil {
+		return fmt.Errorf("no lockfile to install from")
+	}
+	return runInstallation(ctx, apps, repo, settings, nil, newVersionPinner(ctx, lock, "", true), nil)
+}
+
+// ResumeInstallation continues an interrupted installation session in the TUI. Apps the session
+// already completed are skipped and the remaining apps continue from their first unfinished step.
+// apps must contain the configurations of the apps planned by the session.
+func ResumeInstallation(ctx context.Context, session *types.InstallSession, apps []types.CrossPlatformApp, repo types.Repository, settings config.CrossPlatformSettings) error {
+	if session == nil {
+		return fmt.Errorf("no installation session to resume")
+	}
+	return runInstallation(ctx, apps, repo, settings, session, openVersionPinner(ctx, settings), nil)
+}
+
+// collectPluginConsent asks for consent to the permissions of the plugins installing apps while
+// the terminal is free, then suspends consent prompts until the returned function is called
+func collectPluginConsent(apps []types.CrossPlatformApp) (resume func()) {
+	if err := installers.EnsurePermissions(apps); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+	return bootstrap.SuspendPermissionPrompts()
+}
+
+// runInstallation runs the installation TUI, continuing the given session when resume is set and
+// installing payloads from contents when an offline bundle is given
+func runInstallation(ctx context.Context, apps []types.CrossPlatformApp, repo types.Repository, settings config.CrossPlatformSettings, resume *types.InstallSession, versions *versionPinner, contents *bundle.Bundle) error {
 	// Add recovery mechanism to prevent panics from hanging the application
 	defer func() {
 		if r := recover(); r != nil {
@@ -1547,6 +1778,17 @@ func StartInstallation(ctx context.Context, apps []types.CrossPlatformApp, repo
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	// Journal progress so an interrupted run can be resumed; apps completed by a resumed session are dropped
+	journal, apps := openInstallJournal(repo, apps, resume)
+	if len(apps) == 0 {
+		journal.finish(0, nil)
+		fmt.Println("All applications from the installation session are already installed.")
+		return nil
+	}
+
+	// Plugins cannot ask for consent once the TUI owns the terminal
+	defer collectPluginConsent(apps)()
+
 	// Create TUI model
 	m := NewModel(apps)
 
@@ -1562,6 +1804,9 @@ func StartInstallation(ctx context.Context, apps []types.CrossPlatformApp, repo
 
 	// Create streaming installer with context
 	installer := NewStreamingInstaller(p, repo, ctx, settings)
+	installer.journal = journal
+	installer.versions = versions
+	installer.bundle = contents
 	defer installer.cancel() // Ensure cleanup
 
 	// Start installation in background with context cancellation
@@ -1576,6 +1821,7 @@ func StartInstallation(ctx context.Context, apps []types.CrossPlatformApp, repo
 		case <-ctx.Done():
 			// Installation was cancelled
 			installer.sendLog("INFO", "Installation cancelled by user")
+			journal.finish(0, ctx.Err())
 			return
 		case <-time.After(installer.config.InitializationDelay):
 			// Let TUI initialize before starting installation
@@ -1584,6 +1830,9 @@ func StartInstallation(ctx context.Context, apps []types.CrossPlatformApp, repo
 			} else {
 				installer.sendLog("INFO", "Installation completed successfully")
 			}
+			if err := versions.save(); err != nil {
+				installer.sendLog("WARN", fmt.Sprintf("Failed to update %s: %v", lockfile.FileName, err))
+			}
 
 			// Send quit message to exit TUI - use a timer instead of blocking sleep
 			if p != nil {
@@ -1604,6 +1853,11 @@ func StartInstallation(ctx context.Context, apps []types.CrossPlatformApp, repo
 	// SECURITY: Clean up channels to prevent memory leaks
 	m.CleanupChannels()
 
+	// The alternate screen hides anything printed while the TUI runs
+	if hint := journal.resumeHint(); hint != "" {
+		fmt.Println(hint)
+	}
+
 	return err
 }
 
@@ -1704,6 +1958,10 @@ func (si *StreamingInstaller) applySelectedTheme(ctx context.Context, appName st
 			continue
 		}
 
+		if err := undo.ChangeSetFrom(ctx).SaveFile(appName, destination); err != nil {
+			si.sendLog("WARN", fmt.Sprintf("Failed to record %s for undo: %v", destination, err))
+		}
+
 		// Copy the theme file
 		copyCmd := fmt.Sprintf("cp '%s' '%s'", source, destination)
 		if err := si.executeCommandStream(ctx, copyCmd); err != nil {
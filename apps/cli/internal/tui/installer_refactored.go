@@ -9,6 +9,7 @@ import (
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jameswlane/devex/apps/cli/internal/audit"
 	"github.com/jameswlane/devex/apps/cli/internal/config"
 	"github.com/jameswlane/devex/apps/cli/internal/installer/executor"
 	"github.com/jameswlane/devex/apps/cli/internal/installer/script"
@@ -97,7 +98,7 @@ func (tea *themeExecutorAdapter) Execute(ctx context.Context, command string) er
 	if err != nil {
 		return err
 	}
-	return cmd.Run()
+	return audit.Run(ctx, cmd)
 }
 
 // InstallApps installs multiple applications using modular components
@@ -175,21 +176,21 @@ func (rsi *RefactoredStreamingInstaller) InstallApp(ctx context.Context, app typ
 
 	// Execute pre-install commands
 	if len(osConfig.PreInstall) > 0 {
-		if err := rsi.executeCommands(ctx, osConfig.PreInstall); err != nil {
+		if err := rsi.executeCommands(audit.WithTrigger(ctx, app.Name, string(types.InstallStepPreInstall)), osConfig.PreInstall); err != nil {
 			rsi.recordFailedInstallation(app.Name, startTime, err)
 			return fmt.Errorf("pre-install failed: %w", err)
 		}
 	}
 
 	// Execute main installation
-	if err := rsi.executeInstallCommand(ctx, app, &osConfig); err != nil {
+	if err := rsi.executeInstallCommand(audit.WithTrigger(ctx, app.Name, string(types.InstallStepInstall)), app, &osConfig); err != nil {
 		rsi.recordFailedInstallation(app.Name, startTime, err)
 		return fmt.Errorf("installation failed: %w", err)
 	}
 
 	// Execute post-install commands
 	if len(osConfig.PostInstall) > 0 {
-		if err := rsi.executeCommands(ctx, osConfig.PostInstall); err != nil {
+		if err := rsi.executeCommands(audit.WithTrigger(ctx, app.Name, string(types.InstallStepPostInstall)), osConfig.PostInstall); err != nil {
 			rsi.recordFailedInstallation(app.Name, startTime, err)
 			return fmt.Errorf("post-install failed: %w", err)
 		}
@@ -249,7 +250,7 @@ func (rsi *RefactoredStreamingInstaller) executeCurlPipeInstall(ctx context.Cont
 		return fmt.Errorf("failed to execute script: %w", err)
 	}
 
-	return cmd.Run()
+	return audit.Run(ctx, cmd)
 }
 
 // executeCommands executes a list of commands
@@ -295,7 +296,7 @@ func (rsi *RefactoredStreamingInstaller) executeCommand(ctx context.Context, com
 		return err
 	}
 
-	return cmd.Run()
+	return audit.Run(ctx, cmd)
 }
 
 // handleThemeSelection handles theme selection using theme manager
@@ -383,6 +384,9 @@ func StartRefactoredInstallation(apps []types.CrossPlatformApp, repo types.Repos
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Plugins cannot ask for consent once the TUI owns the terminal
+	defer collectPluginConsent(apps)()
+
 	// Create TUI model
 	m := NewModel(apps)
 	p := tea.NewProgram(m, tea.WithAltScreen())
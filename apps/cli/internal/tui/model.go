@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -97,7 +98,7 @@ type Model struct {
 
 	// Application state
 	apps          []types.CrossPlatformApp
-	currentApp    int
+	runningApps   []int // Indexes of the apps being installed, in the order they started
 	completedApps int64 // Atomic counter for completed apps to prevent race conditions
 	status        string
 	logs          *CircularBuffer // PERFORMANCE: Use circular buffer for efficient log storage
@@ -191,7 +192,6 @@ func NewModel(apps []types.CrossPlatformApp) *Model {
 		textInput:      ti,
 		viewport:       vp,
 		apps:           apps,
-		currentApp:     0,
 		completedApps:  0,
 		status:         "Ready to install applications",
 		logs:           NewCircularBuffer(maxLogLines), // PERFORMANCE: Use circular buffer with configurable size
@@ -301,12 +301,21 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case AppStartedMsg:
-		// App installation started - update current app display
-		m.currentApp = msg.AppIndex
-		m.status = fmt.Sprintf("Installing %s...", msg.AppName)
+		// App installation started - the scheduler installs independent apps in parallel
+		if msg.AppIndex >= 0 && msg.AppIndex < len(m.apps) && !slices.Contains(m.runningApps, msg.AppIndex) {
+			m.runningApps = append(m.runningApps, msg.AppIndex)
+		}
+		if len(m.runningApps) > 0 {
+			m.status = m.installingStatus()
+		} else {
+			m.status = fmt.Sprintf("Installing %s...", msg.AppName)
+		}
 
 	case AppCompleteMsg:
 		// App installation completed
+		m.runningApps = slices.DeleteFunc(m.runningApps, func(index int) bool {
+			return m.apps[index].Name == msg.AppName
+		})
 		// SECURITY: Prevent double-counting using thread-safe sync.Map
 		if _, alreadyProcessed := m.appStatus.LoadOrStore(msg.AppName, true); alreadyProcessed {
 			// App already processed, ignore duplicate message
@@ -342,7 +351,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			// All apps completed - update display
 			m.status = "All applications installed successfully!"
-			m.currentApp = len(m.apps) // Hide current app display
+			m.runningApps = nil
 		}
 
 	case progress.FrameMsg:
@@ -483,9 +492,37 @@ func (m *Model) renderLeftPane(width int) string {
 		}
 	}
 
+	// Apps installed in parallel are listed, a single app is shown in detail
+	if len(m.runningApps) > 1 && lineCount < maxLines-3 {
+		if !addLine(lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("212")).
+			Render(fmt.Sprintf("📦 Installing %d apps", len(m.runningApps)))) {
+			return leftStyle.Render(content.String())
+		}
+		for _, index := range m.runningApps {
+			appDetails := m.getAppDetails(m.apps[index])
+			if !addLine(lipgloss.NewStyle().
+				Foreground(lipgloss.Color("246")).
+				Render(fmt.Sprintf("%s %s (%s)", getMethodIcon(appDetails.InstallMethod), appDetails.Name, appDetails.InstallMethod))) {
+				return leftStyle.Render(content.String())
+			}
+		}
+		if !addLine("") {
+			return leftStyle.Render(content.String())
+		}
+		if !m.startTime.IsZero() && lineCount < maxLines-2 {
+			if !addLine(lipgloss.NewStyle().
+				Foreground(lipgloss.Color("246")).
+				Render(fmt.Sprintf("⏱️  Elapsed: %s", formatDuration(time.Since(m.startTime))))) {
+				return leftStyle.Render(content.String())
+			}
+		}
+	}
+
 	// Current app detailed information
-	if m.currentApp < len(m.apps) && lineCount < maxLines-5 { // Keep some buffer space
-		app := m.apps[m.currentApp]
+	if len(m.runningApps) == 1 && lineCount < maxLines-5 { // Keep some buffer space
+		app := m.apps[m.runningApps[0]]
 		appDetails := m.getAppDetails(app)
 
 		// App Name and Category
@@ -617,6 +654,15 @@ func (m *Model) renderLeftPane(width int) string {
 	return leftStyle.Render(strings.TrimRight(content.String(), "\n"))
 }
 
+// installingStatus describes the apps being installed
+func (m *Model) installingStatus() string {
+	names := make([]string, 0, len(m.runningApps))
+	for _, index := range m.runningApps {
+		names = append(names, m.apps[index].Name)
+	}
+	return fmt.Sprintf("Installing %s...", strings.Join(names, ", "))
+}
+
 // getAppDetails extracts detailed information about an app for display
 func (m *Model) getAppDetails(app types.CrossPlatformApp) AppDisplayInfo {
 	osConfig := app.GetOSConfig()
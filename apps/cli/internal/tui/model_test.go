@@ -19,7 +19,7 @@ var _ = Describe("Model", func() {
 
 			// Verify initial state
 			Expect(model.apps).To(Equal(apps))
-			Expect(model.currentApp).To(Equal(0))
+			Expect(model.runningApps).To(BeEmpty())
 			Expect(model.status).To(Equal("Ready to install applications"))
 			Expect(model.logs.Size()).To(Equal(0))
 			Expect(model.needsInput).To(BeFalse())
@@ -297,6 +297,30 @@ var _ = Describe("Model", func() {
 		})
 	})
 
+	Describe("Parallel Installs", func() {
+		It("should show every app being installed", func() {
+			model := NewModel(createTestApps())
+			updatedModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 50})
+			model = updatedModel.(*Model)
+
+			updatedModel, _ = model.Update(AppStartedMsg{AppName: "test-app-1", AppIndex: 0})
+			updatedModel, _ = updatedModel.Update(AppStartedMsg{AppName: "test-app-3", AppIndex: 2})
+			model = updatedModel.(*Model)
+
+			Expect(model.runningApps).To(Equal([]int{0, 2}))
+			Expect(model.status).To(Equal("Installing test-app-1, test-app-3..."))
+			view := model.View()
+			Expect(view).To(ContainSubstring("Installing 2 apps"))
+			Expect(view).To(ContainSubstring("test-app-1"))
+			Expect(view).To(ContainSubstring("test-app-3"))
+
+			updatedModel, _ = model.Update(AppCompleteMsg{AppName: "test-app-1"})
+			model = updatedModel.(*Model)
+			Expect(model.runningApps).To(Equal([]int{2}))
+			Expect(model.View()).To(ContainSubstring("📦 test-app-3"))
+		})
+	})
+
 	Describe("All Apps Completed", func() {
 		It("should handle completion of all apps", func() {
 			apps := createTestApps()
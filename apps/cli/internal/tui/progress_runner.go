@@ -38,6 +38,9 @@ func NewProgressRunner(ctx context.Context, settings config.CrossPlatformSetting
 
 // RunInstallation runs application installation with progress tracking
 func (pr *ProgressRunner) RunInstallation(apps []types.CrossPlatformApp, repo types.Repository, settings config.CrossPlatformSettings) error {
+	// Plugins cannot ask for consent once the TUI owns the terminal
+	defer collectPluginConsent(apps)()
+
 	// Start the TUI
 	go func() {
 		if _, err := pr.program.Run(); err != nil {
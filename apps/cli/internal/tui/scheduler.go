@@ -0,0 +1,409 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jameswlane/devex/apps/cli/internal/config"
+	progresspkg "github.com/jameswlane/devex/apps/cli/internal/progress"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+// exclusiveLock marks apps that must run with nothing else in flight
+const exclusiveLock = "*"
+
+// backendLocks maps install methods to the system lock their package manager holds.
+// Methods sharing a lock (e.g. apt and deb both take the dpkg lock) are never run concurrently.
+// mise, pip and docker have no system lock but write shared state (the mise config and
+// shims, site-packages, the image store) without coordinating, so each is serialized on its
+// own lock. Methods without an entry are run exclusively because their side effects are unknown.
+var backendLocks = map[string]string{
+	"apt":      "dpkg",
+	"deb":      "dpkg",
+	"dnf":      "rpm",
+	"yum":      "rpm",
+	"rpm":      "rpm",
+	"zypper":   "rpm",
+	"pacman":   "pacman",
+	"yay":      "pacman",
+	"apk":      "apk",
+	"emerge":   "portage",
+	"xbps":     "xbps",
+	"eopkg":    "eopkg",
+	"snap":     "snapd",
+	"brew":     "brew",
+	"nixpkgs":  "nix",
+	"nixflake": "nix",
+	"flatpak":  "",
+	"mise":     "mise",
+	"pip":      "pip",
+	"docker":   "docker",
+	"appimage": "",
+}
+
+// installLockKey returns the lock an install method holds: "" for none, exclusiveLock for unknown methods
+func installLockKey(method string) string {
+	lock, known := backendLocks[strings.ToLower(method)]
+	if !known {
+		return exclusiveLock
+	}
+	return lock
+}
+
+// installNode is a single app in the installation graph
+type installNode struct {
+	index      int
+	app        types.CrossPlatformApp
+	lock       string
+	dependents []int
+	pending    int // dependencies that have not finished yet
+	done       bool
+	started    bool
+	op         *progresspkg.Operation
+}
+
+// installResult is reported by a worker when an app finishes
+type installResult struct {
+	node *installNode
+	err  error
+}
+
+// buildInstallGraph links apps through the Dependencies of their platform configuration.
+// Dependencies that are not part of the batch are assumed to be satisfied already.
+func buildInstallGraph(apps []types.CrossPlatformApp) []*installNode {
+	nodes := make([]*installNode, len(apps))
+	byName := make(map[string]int, len(apps))
+
+	for i, app := range apps {
+		osConfig := app.GetOSConfig()
+		nodes[i] = &installNode{
+			index: i,
+			app:   app,
+			lock:  installLockKey(osConfig.InstallMethod),
+		}
+		key := strings.ToLower(app.Name)
+		if _, exists := byName[key]; !exists {
+			byName[key] = i
+		}
+	}
+
+	for _, node := range nodes {
+		seen := make(map[int]bool)
+		for _, dep := range node.app.GetOSConfig().Dependencies {
+			depIndex, ok := byName[strings.ToLower(strings.TrimSpace(dep))]
+			if !ok || depIndex == node.index || seen[depIndex] {
+				continue
+			}
+			seen[depIndex] = true
+			node.pending++
+			nodes[depIndex].dependents = append(nodes[depIndex].dependents, node.index)
+		}
+	}
+
+	return nodes
+}
+
+// installScheduler runs an installation graph with bounded parallelism and backend locks
+type installScheduler struct {
+	si          *StreamingInstaller
+	settings    config.CrossPlatformSettings
+	install     func(ctx context.Context, app types.CrossPlatformApp, settings config.CrossPlatformSettings) error
+	nodes       []*installNode
+	maxParallel int
+	running     int
+	locks       map[string]bool
+	exclusive   bool
+	results     chan installResult
+	parent      *progresspkg.Operation
+	finished    int
+}
+
+// InstallApps installs multiple applications with streaming output and context cancellation.
+// Apps are scheduled as a dependency graph built from each app's platform Dependencies:
+// independent apps run in parallel (up to InstallerConfig.MaxParallelInstalls), apps whose
+// backends share a system lock such as apt/dpkg or dnf/rpm run one at a time, and apps whose
+// dependencies failed are skipped. Input order is used as the tie-breaker, so a MaxParallelInstalls
+// of 1 installs strictly in the given order.
+//
+// Individual app failures are logged but don't stop the overall installation process, unless caused by cancellation.
+// If context cancellation occurs, no new apps are started, running apps are awaited and the cancellation error is returned.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - apps: Slice of CrossPlatformApp configurations to install
+//   - settings: Installation settings including verbosity and dry-run flags
+//
+// Returns:
+//   - error: nil on success, context.Canceled if cancelled, or other error on critical failures
+func (si *StreamingInstaller) InstallApps(ctx context.Context, apps []types.CrossPlatformApp, settings config.CrossPlatformSettings) error {
+	select {
+	case <-ctx.Done():
+		si.sendLog("INFO", "Installation cancelled before starting next app")
+		return ctx.Err()
+	default:
+	}
+
+	maxParallel := si.config.MaxParallelInstalls
+	if maxParallel < 1 {
+		maxParallel = 1
+	}
+
+	s := newInstallScheduler(si, apps, settings, maxParallel)
+	s.startProgress()
+
+	return s.run(ctx)
+}
+
+// newInstallScheduler creates a scheduler that installs each app with si.InstallApp
+func newInstallScheduler(si *StreamingInstaller, apps []types.CrossPlatformApp, settings config.CrossPlatformSettings, maxParallel int) *installScheduler {
+	return &installScheduler{
+		si:          si,
+		settings:    settings,
+		install:     si.InstallApp,
+		nodes:       buildInstallGraph(apps),
+		maxParallel: maxParallel,
+		locks:       make(map[string]bool),
+		results:     make(chan installResult, len(apps)),
+	}
+}
+
+// startProgress registers the batch and one child operation per app with the progress tracker
+func (s *installScheduler) startProgress() {
+	if s.si.progressManager == nil {
+		return
+	}
+
+	tracker := s.si.progressManager.GetTracker()
+	parentID := "install-apps"
+	s.parent = tracker.StartOperation(parentID, "Installing applications",
+		fmt.Sprintf("%d application(s)", len(s.nodes)), progresspkg.OperationInstall)
+	s.parent.SetStatus(progresspkg.StatusRunning)
+
+	for _, node := range s.nodes {
+		osConfig := node.app.GetOSConfig()
+		node.op = tracker.StartChildOperation(parentID, fmt.Sprintf("%s/%d-%s", parentID, node.index, node.app.Name),
+			node.app.Name, node.app.Description, progresspkg.OperationInstall)
+		node.op.SetMetadata("install_method", osConfig.InstallMethod)
+	}
+}
+
+// run drives the graph until every app has finished, been skipped, or the context is cancelled
+func (s *installScheduler) run(ctx context.Context) error {
+	failed := 0
+	var cancelErr error
+
+	for s.finished < len(s.nodes) {
+		if cancelErr == nil && ctx.Err() != nil {
+			cancelErr = ctx.Err()
+			s.si.sendLog("INFO", "Installation cancelled, waiting for running apps to finish")
+		}
+
+		if cancelErr == nil {
+			s.launchReady(ctx)
+		}
+
+		if s.running == 0 {
+			if cancelErr != nil {
+				break
+			}
+			// Nothing is running and nothing could start: the remaining apps form a cycle.
+			if node := s.firstUnstarted(); node != nil {
+				s.si.sendLog("WARN", fmt.Sprintf("Dependency cycle detected involving %s, installing in listed order", node.app.Name))
+				s.start(ctx, node)
+			}
+		}
+
+		result := <-s.results
+		s.release(result.node)
+
+		if result.err != nil {
+			failed++
+			s.complete(result.node, result.err)
+			if errors.Is(result.err, context.Canceled) || ctx.Err() != nil {
+				if cancelErr == nil {
+					cancelErr = ctx.Err()
+					if cancelErr == nil {
+						cancelErr = result.err
+					}
+				}
+				continue
+			}
+			failed += s.skipDependents(result.node)
+			continue
+		}
+
+		s.complete(result.node, nil)
+		for _, idx := range result.node.dependents {
+			s.nodes[idx].pending--
+		}
+	}
+
+	s.finishProgress(failed, cancelErr)
+	s.si.journal.finish(failed, cancelErr)
+	return cancelErr
+}
+
+// launchReady starts every app whose dependencies are done and whose backend lock is free
+func (s *installScheduler) launchReady(ctx context.Context) {
+	for _, node := range s.nodes {
+		if s.running >= s.maxParallel || s.exclusive {
+			return
+		}
+		if node.started || node.done || node.pending > 0 {
+			continue
+		}
+		if node.lock == exclusiveLock && s.running > 0 {
+			// Keep input order: do not let later apps overtake an exclusive app that is waiting
+			return
+		}
+		if node.lock != "" && s.locks[node.lock] {
+			continue
+		}
+		s.start(ctx, node)
+	}
+}
+
+// start acquires the node's lock and installs it in a worker goroutine
+func (s *installScheduler) start(ctx context.Context, node *installNode) {
+	node.started = true
+	s.running++
+	switch node.lock {
+	case exclusiveLock:
+		s.exclusive = true
+	case "":
+	default:
+		s.locks[node.lock] = true
+	}
+
+	if s.si.program != nil {
+		s.si.program.Send(AppStartedMsg{
+			AppName:  node.app.Name,
+			AppIndex: node.index,
+		})
+	}
+	if node.op != nil {
+		node.op.SetStatus(progresspkg.StatusRunning)
+	}
+	s.si.journal.appStarted(node.app.Name)
+
+	go func() {
+		var err error
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("installation panic: %v", r)
+			}
+			s.results <- installResult{node: node, err: err}
+		}()
+		err = s.install(ctx, node.app, s.settings)
+	}()
+}
+
+// release frees the worker slot and lock held by a finished node
+func (s *installScheduler) release(node *installNode) {
+	s.running--
+	switch node.lock {
+	case exclusiveLock:
+		s.exclusive = false
+	case "":
+	default:
+		delete(s.locks, node.lock)
+	}
+}
+
+// complete reports a finished app to the TUI and the progress tracker
+func (s *installScheduler) complete(node *installNode, err error) {
+	node.done = true
+	s.finished++
+	s.si.journal.appFinished(node.app.Name, err)
+
+	if err != nil {
+		s.si.sendLog("ERROR", fmt.Sprintf("Failed to install %s: %v", node.app.Name, err))
+		if node.op != nil {
+			node.op.Fail(err)
+		}
+	} else if node.op != nil {
+		node.op.Complete()
+	}
+
+	if s.si.program != nil {
+		s.si.program.Send(AppCompleteMsg{
+			AppName: node.app.Name,
+			Error:   err,
+		})
+	}
+
+	if s.parent != nil {
+		s.parent.SetProgress(float64(s.finished) / float64(len(s.nodes)))
+	}
+}
+
+// skipDependents marks every app that transitively depends on a failed app as skipped
+func (s *installScheduler) skipDependents(failed *installNode) int {
+	skipped := 0
+	queue := append([]int(nil), failed.dependents...)
+	for len(queue) > 0 {
+		node := s.nodes[queue[0]]
+		queue = queue[1:]
+		if node.done || node.started {
+			continue
+		}
+
+		reason := fmt.Sprintf("skipped because dependency %s failed", failed.app.Name)
+		node.done = true
+		s.finished++
+		skipped++
+
+		s.si.sendLog("WARN", fmt.Sprintf("Skipping %s: dependency %s failed", node.app.Name, failed.app.Name))
+		s.si.journal.appSkipped(node.app.Name, reason)
+		if node.op != nil {
+			node.op.Skip(reason)
+		}
+		if s.si.program != nil {
+			s.si.program.Send(AppCompleteMsg{
+				AppName: node.app.Name,
+				Error:   errors.New(reason),
+			})
+		}
+		queue = append(queue, node.dependents...)
+	}
+
+	if s.parent != nil {
+		s.parent.SetProgress(float64(s.finished) / float64(len(s.nodes)))
+	}
+	return skipped
+}
+
+// firstUnstarted returns the first app in input order that has not been started
+func (s *installScheduler) firstUnstarted() *installNode {
+	for _, node := range s.nodes {
+		if !node.started && !node.done {
+			return node
+		}
+	}
+	return nil
+}
+
+// finishProgress closes the batch operation and cancels apps that never started
+func (s *installScheduler) finishProgress(failed int, cancelErr error) {
+	if s.parent == nil {
+		return
+	}
+
+	for _, node := range s.nodes {
+		if !node.done && node.op != nil {
+			node.op.Cancel()
+		}
+	}
+
+	switch {
+	case cancelErr != nil:
+		s.parent.Cancel()
+	case failed > 0:
+		s.parent.SetDetails(fmt.Sprintf("%d of %d application(s) failed or were skipped", failed, len(s.nodes)))
+		s.parent.Complete()
+	default:
+		s.parent.Complete()
+	}
+}
@@ -0,0 +1,234 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jameswlane/devex/apps/cli/internal/config"
+	progresspkg "github.com/jameswlane/devex/apps/cli/internal/progress"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+func schedulerTestApp(name, method string, deps ...string) types.CrossPlatformApp {
+	osConfig := types.OSConfig{
+		InstallMethod:  method,
+		InstallCommand: name,
+		Dependencies:   deps,
+	}
+	return types.CrossPlatformApp{Name: name, Linux: osConfig, MacOS: osConfig, Windows: osConfig}
+}
+
+// recordingInstall tracks start/finish order and the peak number of concurrent installs
+type recordingInstall struct {
+	mutex      sync.Mutex
+	events     []string
+	active     map[string]bool
+	peak       int
+	overlaps   map[[2]string]bool
+	failures   map[string]bool
+	installDur time.Duration
+}
+
+func newRecordingInstall() *recordingInstall {
+	return &recordingInstall{
+		active:     make(map[string]bool),
+		overlaps:   make(map[[2]string]bool),
+		failures:   make(map[string]bool),
+		installDur: 20 * time.Millisecond,
+	}
+}
+
+func (r *recordingInstall) install(ctx context.Context, app types.CrossPlatformApp, _ config.CrossPlatformSettings) error {
+	r.mutex.Lock()
+	for other := range r.active {
+		r.overlaps[[2]string{other, app.Name}] = true
+		r.overlaps[[2]string{app.Name, other}] = true
+	}
+	r.active[app.Name] = true
+	if len(r.active) > r.peak {
+		r.peak = len(r.active)
+	}
+	r.events = append(r.events, "start:"+app.Name)
+	r.mutex.Unlock()
+
+	select {
+	case <-time.After(r.installDur):
+	case <-ctx.Done():
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.active, app.Name)
+	r.events = append(r.events, "end:"+app.Name)
+	if r.failures[app.Name] {
+		return errors.New("install failed")
+	}
+	return ctx.Err()
+}
+
+func (r *recordingInstall) indexOf(event string) int {
+	for i, e := range r.events {
+		if e == event {
+			return i
+		}
+	}
+	return -1
+}
+
+func newTestScheduler(apps []types.CrossPlatformApp, maxParallel int, rec *recordingInstall) *installScheduler {
+	si := NewStreamingInstallerWithExecutor(nil, &MockRepository{}, context.Background(), NewMockCommandExecutor(), getTestSettings())
+	s := newInstallScheduler(si, apps, config.CrossPlatformSettings{}, maxParallel)
+	s.install = rec.install
+	return s
+}
+
+func TestInstallScheduler_RunsIndependentBackendsInParallel(t *testing.T) {
+	rec := newRecordingInstall()
+	apps := []types.CrossPlatformApp{
+		schedulerTestApp("git", "apt"),
+		schedulerTestApp("node", "mise"),
+		schedulerTestApp("slack", "flatpak"),
+	}
+
+	require.NoError(t, newTestScheduler(apps, 4, rec).run(context.Background()))
+	assert.Equal(t, 3, rec.peak)
+}
+
+func TestInstallScheduler_SerializesSharedLockBackends(t *testing.T) {
+	rec := newRecordingInstall()
+	apps := []types.CrossPlatformApp{
+		schedulerTestApp("git", "apt"),
+		schedulerTestApp("chrome", "deb"),
+		schedulerTestApp("curl", "apt"),
+		schedulerTestApp("node", "mise"),
+	}
+
+	require.NoError(t, newTestScheduler(apps, 4, rec).run(context.Background()))
+	assert.False(t, rec.overlaps[[2]string{"git", "chrome"}], "apt and deb share the dpkg lock")
+	assert.False(t, rec.overlaps[[2]string{"git", "curl"}])
+	assert.False(t, rec.overlaps[[2]string{"chrome", "curl"}])
+	assert.True(t, rec.overlaps[[2]string{"git", "node"}], "mise does not hold the dpkg lock")
+}
+
+func TestInstallScheduler_SerializesEachUserSpaceBackend(t *testing.T) {
+	rec := newRecordingInstall()
+	apps := []types.CrossPlatformApp{
+		schedulerTestApp("node", "mise"),
+		schedulerTestApp("go", "mise"),
+		schedulerTestApp("black", "pip"),
+		schedulerTestApp("ruff", "pip"),
+		schedulerTestApp("postgres", "docker"),
+		schedulerTestApp("redis", "docker"),
+	}
+
+	require.NoError(t, newTestScheduler(apps, 6, rec).run(context.Background()))
+	assert.False(t, rec.overlaps[[2]string{"node", "go"}], "mise installs share the mise config")
+	assert.False(t, rec.overlaps[[2]string{"black", "ruff"}], "pip installs share site-packages")
+	assert.False(t, rec.overlaps[[2]string{"postgres", "redis"}], "docker installs share the image store")
+	assert.True(t, rec.overlaps[[2]string{"node", "black"}], "mise and pip hold different locks")
+}
+
+func TestInstallScheduler_WaitsForDependencies(t *testing.T) {
+	rec := newRecordingInstall()
+	apps := []types.CrossPlatformApp{
+		schedulerTestApp("Neovim", "flatpak", "fzf", "curl"),
+		schedulerTestApp("fzf", "mise"),
+		schedulerTestApp("curl", "apt"),
+	}
+
+	require.NoError(t, newTestScheduler(apps, 4, rec).run(context.Background()))
+	start := rec.indexOf("start:Neovim")
+	assert.Greater(t, start, rec.indexOf("end:fzf"))
+	assert.Greater(t, start, rec.indexOf("end:curl"))
+}
+
+func TestInstallScheduler_SkipsDependentsOfFailedApps(t *testing.T) {
+	rec := newRecordingInstall()
+	rec.failures["git"] = true
+	apps := []types.CrossPlatformApp{
+		schedulerTestApp("git", "apt"),
+		schedulerTestApp("LazyGit", "mise", "git"),
+		schedulerTestApp("gh", "mise", "lazygit"),
+		schedulerTestApp("node", "mise"),
+	}
+
+	s := newTestScheduler(apps, 4, rec)
+	s.si.progressManager = progresspkg.NewProgressManager(context.Background(), nil)
+	s.startProgress()
+
+	require.NoError(t, s.run(context.Background()))
+	assert.Equal(t, -1, rec.indexOf("start:LazyGit"))
+	assert.Equal(t, -1, rec.indexOf("start:gh"))
+	assert.NotEqual(t, -1, rec.indexOf("end:node"))
+
+	statuses := map[string]progresspkg.Status{}
+	for _, child := range s.parent.GetChildren() {
+		state := child.GetState()
+		statuses[state.Name] = state.Status
+	}
+	assert.Equal(t, progresspkg.StatusFailed, statuses["git"])
+	assert.Equal(t, progresspkg.StatusSkipped, statuses["LazyGit"])
+	assert.Equal(t, progresspkg.StatusSkipped, statuses["gh"])
+	assert.Equal(t, progresspkg.StatusCompleted, statuses["node"])
+}
+
+func TestInstallScheduler_UnknownMethodsRunExclusively(t *testing.T) {
+	rec := newRecordingInstall()
+	apps := []types.CrossPlatformApp{
+		schedulerTestApp("node", "mise"),
+		schedulerTestApp("docker", "curlpipe"),
+		schedulerTestApp("slack", "flatpak"),
+	}
+
+	require.NoError(t, newTestScheduler(apps, 4, rec).run(context.Background()))
+	assert.False(t, rec.overlaps[[2]string{"docker", "node"}])
+	assert.False(t, rec.overlaps[[2]string{"docker", "slack"}])
+}
+
+func TestInstallScheduler_SingleWorkerKeepsInputOrder(t *testing.T) {
+	rec := newRecordingInstall()
+	rec.installDur = time.Millisecond
+	apps := []types.CrossPlatformApp{
+		schedulerTestApp("a", "mise"),
+		schedulerTestApp("b", "flatpak"),
+		schedulerTestApp("c", "apt"),
+	}
+
+	require.NoError(t, newTestScheduler(apps, 1, rec).run(context.Background()))
+	assert.Equal(t, []string{"start:a", "end:a", "start:b", "end:b", "start:c", "end:c"}, rec.events)
+}
+
+func TestInstallScheduler_BreaksDependencyCycles(t *testing.T) {
+	rec := newRecordingInstall()
+	rec.installDur = time.Millisecond
+	apps := []types.CrossPlatformApp{
+		schedulerTestApp("a", "mise", "b"),
+		schedulerTestApp("b", "mise", "a"),
+	}
+
+	require.NoError(t, newTestScheduler(apps, 4, rec).run(context.Background()))
+	assert.NotEqual(t, -1, rec.indexOf("end:a"))
+	assert.NotEqual(t, -1, rec.indexOf("end:b"))
+}
+
+func TestInstallScheduler_StopsLaunchingOnCancellation(t *testing.T) {
+	rec := newRecordingInstall()
+	rec.installDur = time.Second
+	apps := []types.CrossPlatformApp{
+		schedulerTestApp("a", "apt"),
+		schedulerTestApp("b", "apt"),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	err := newTestScheduler(apps, 4, rec).run(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, -1, rec.indexOf("start:b"))
+}
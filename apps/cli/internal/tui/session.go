@@ -0,0 +1,221 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jameswlane/devex/apps/cli/internal/log"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+// installJournal records the progress of an installation session in the datastore so an
+// interrupted run can continue from the first incomplete step. A nil journal records nothing.
+type installJournal struct {
+	repo      types.SessionRepository
+	sessionID string
+	mutex     sync.Mutex
+	steps     map[string]types.InstallStep // last finished step per app
+	completed map[string]bool              // apps this run installed
+	previous  *types.InstallSession        // incomplete session left for 'devex install --resume'
+	abandoned bool                         // whether this run superseded the previous session
+}
+
+// openInstallJournal starts journaling an installation of apps.
+//
+// When resume is given, the journal continues that session and only the apps that have not
+// completed are returned. Without resume a new session is always created: the latest incomplete
+// session is left for 'devex install --resume', see resumeHint, and is only marked abandoned once
+// the new run has installed every app it did not complete.
+//
+// Repositories that cannot store sessions get a nil journal and the apps unchanged.
+func openInstallJournal(repo types.Repository, apps []types.CrossPlatformApp, resume *types.InstallSession) (*installJournal, []types.CrossPlatformApp) {
+	sessionRepo, ok := repo.(types.SessionRepository)
+	if !ok {
+		return nil, apps
+	}
+
+	journal := &installJournal{
+		repo:      sessionRepo,
+		steps:     make(map[string]types.InstallStep),
+		completed: make(map[string]bool),
+	}
+
+	if resume == nil {
+		latest, err := sessionRepo.LatestIncompleteSession()
+		if err != nil {
+			log.Warn("Failed to look up incomplete installation sessions", "error", err)
+		} else if latest != nil {
+			log.Info("Not resuming incomplete installation session without --resume", "session", latest.ID, "status", latest.Status)
+			journal.previous = latest
+		}
+	}
+
+	if resume != nil {
+		journal.sessionID = resume.ID
+		completed := make(map[string]bool)
+		for _, app := range resume.Apps {
+			key := strings.ToLower(app.AppName)
+			journal.steps[key] = app.Step
+			if app.Status == types.SessionAppCompleted {
+				completed[key] = true
+			}
+		}
+
+		remaining := make([]types.CrossPlatformApp, 0, len(apps))
+		for _, app := range apps {
+			if !completed[strings.ToLower(app.Name)] {
+				remaining = append(remaining, app)
+			}
+		}
+
+		if err := sessionRepo.SetSessionStatus(resume.ID, types.SessionStatusRunning); err != nil {
+			log.Warn("Failed to reopen installation session", "session", resume.ID, "error", err)
+		}
+		return journal, remaining
+	}
+
+	names := make([]string, len(apps))
+	for i, app := range apps {
+		names[i] = app.Name
+	}
+	journal.sessionID = newSessionID()
+	if err := sessionRepo.CreateSession(journal.sessionID, names); err != nil {
+		log.Warn("Failed to create installation session, progress will not be resumable", "error", err)
+		return nil, apps
+	}
+	return journal, apps
+}
+
+// newSessionID returns a sortable, unique-enough identifier for a new session
+func newSessionID() string {
+	return time.Now().UTC().Format("20060102T150405.000000000Z")
+}
+
+// SessionID returns the identifier of the journaled session
+func (j *installJournal) SessionID() string {
+	if j == nil {
+		return ""
+	}
+	return j.sessionID
+}
+
+// needsStep reports whether a step still has to run for an app
+func (j *installJournal) needsStep(appName string, step types.InstallStep) bool {
+	if j == nil {
+		return true
+	}
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return !j.steps[strings.ToLower(appName)].Completes(step)
+}
+
+// appStarted marks an app as running
+func (j *installJournal) appStarted(appName string) {
+	j.record(appName, types.SessionAppRunning, "")
+}
+
+// stepFinished records that a step of an app finished successfully
+func (j *installJournal) stepFinished(appName string, step types.InstallStep) {
+	if j == nil {
+		return
+	}
+	j.mutex.Lock()
+	j.steps[strings.ToLower(appName)] = step
+	j.mutex.Unlock()
+	j.record(appName, types.SessionAppRunning, "")
+}
+
+// appFinished records the outcome of an app installation
+func (j *installJournal) appFinished(appName string, err error) {
+	if err != nil {
+		j.record(appName, types.SessionAppFailed, err.Error())
+		return
+	}
+	if j != nil {
+		j.mutex.Lock()
+		j.completed[strings.ToLower(appName)] = true
+		j.mutex.Unlock()
+	}
+	j.record(appName, types.SessionAppCompleted, "")
+}
+
+// appSkipped records that an app was not attempted
+func (j *installJournal) appSkipped(appName, reason string) {
+	j.record(appName, types.SessionAppSkipped, reason)
+}
+
+// finish records the overall outcome of the session
+func (j *installJournal) finish(failed int, cancelErr error) {
+	if j == nil {
+		return
+	}
+
+	status := types.SessionStatusCompleted
+	switch {
+	case errors.Is(cancelErr, context.Canceled), errors.Is(cancelErr, context.DeadlineExceeded):
+		status = types.SessionStatusInterrupted
+	case failed > 0:
+		status = types.SessionStatusFailed
+	}
+
+	if err := j.repo.SetSessionStatus(j.sessionID, status); err != nil {
+		log.Warn("Failed to record installation session status", "session", j.sessionID, "error", err)
+	}
+
+	// A run that installed everything the previous session left over supersedes it
+	if j.previous != nil && j.covers(j.previous) {
+		if err := j.repo.SetSessionStatus(j.previous.ID, types.SessionStatusAbandoned); err != nil {
+			log.Warn("Failed to abandon previous installation session", "session", j.previous.ID, "error", err)
+			return
+		}
+		j.mutex.Lock()
+		j.abandoned = true
+		j.mutex.Unlock()
+	}
+}
+
+// covers reports whether this run installed every app a session did not complete
+func (j *installJournal) covers(session *types.InstallSession) bool {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	for _, app := range session.Apps {
+		if app.Status != types.SessionAppCompleted && !j.completed[strings.ToLower(app.AppName)] {
+			return false
+		}
+	}
+	return true
+}
+
+// resumeHint returns how to continue the incomplete session this run did not resume, or an
+// empty string when there is none or this run superseded it. It is shown once the TUI exits.
+func (j *installJournal) resumeHint() string {
+	if j == nil || j.previous == nil {
+		return ""
+	}
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if j.abandoned {
+		return ""
+	}
+	return fmt.Sprintf("The installation started %s did not complete (%s) and was not resumed.\n"+
+		"Run 'devex install --resume' to continue it.",
+		j.previous.CreatedAt.Local().Format("2006-01-02 15:04"), j.previous.Status)
+}
+
+// record writes an app's status together with its last finished step
+func (j *installJournal) record(appName, status, errMsg string) {
+	if j == nil {
+		return
+	}
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	step := j.steps[strings.ToLower(appName)]
+	if err := j.repo.UpdateSessionApp(j.sessionID, appName, status, step, errMsg); err != nil {
+		log.Warn("Failed to journal installation progress", "app", appName, "status", status, "error", err)
+	}
+}
@@ -0,0 +1,202 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jameswlane/devex/apps/cli/internal/config"
+	"github.com/jameswlane/devex/apps/cli/internal/datastore"
+	"github.com/jameswlane/devex/apps/cli/internal/datastore/repository"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+// journaledRepository combines the mock app repository with a real session store
+type journaledRepository struct {
+	*MockRepository
+	types.SessionRepository
+}
+
+func newJournaledRepository(t *testing.T) *journaledRepository {
+	db := datastore.NewInMemorySQLite()
+	t.Cleanup(func() { _ = db.Close() })
+	return &journaledRepository{
+		MockRepository:    &MockRepository{},
+		SessionRepository: repository.NewSessionRepository(db),
+	}
+}
+
+func sessionAppStatuses(t *testing.T, repo types.SessionRepository, id string) map[string]string {
+	session, err := repo.GetSession(id)
+	require.NoError(t, err)
+	require.NotNil(t, session)
+	statuses := make(map[string]string, len(session.Apps))
+	for _, app := range session.Apps {
+		statuses[app.AppName] = app.Status
+	}
+	return statuses
+}
+
+func TestOpenInstallJournal_WithoutSessionSupport(t *testing.T) {
+	apps := []types.CrossPlatformApp{schedulerTestApp("git", "apt")}
+
+	journal, remaining := openInstallJournal(&MockRepository{}, apps, nil)
+	assert.Nil(t, journal)
+	assert.Equal(t, apps, remaining)
+	assert.True(t, journal.needsStep("git", types.InstallStepInstall), "a nil journal runs every step")
+}
+
+func TestInstallScheduler_JournalsAppOutcomes(t *testing.T) {
+	repo := newJournaledRepository(t)
+	rec := newRecordingInstall()
+	rec.failures["git"] = true
+	apps := []types.CrossPlatformApp{
+		schedulerTestApp("git", "apt"),
+		schedulerTestApp("LazyGit", "mise", "git"),
+		schedulerTestApp("node", "mise"),
+	}
+
+	journal, _ := openInstallJournal(repo, apps, nil)
+	require.NotNil(t, journal)
+
+	s := newTestScheduler(apps, 4, rec)
+	s.si.journal = journal
+	require.NoError(t, s.run(context.Background()))
+
+	statuses := sessionAppStatuses(t, repo, journal.SessionID())
+	assert.Equal(t, types.SessionAppFailed, statuses["git"])
+	assert.Equal(t, types.SessionAppSkipped, statuses["LazyGit"])
+	assert.Equal(t, types.SessionAppCompleted, statuses["node"])
+
+	session, err := repo.GetSession(journal.SessionID())
+	require.NoError(t, err)
+	assert.Equal(t, types.SessionStatusFailed, session.Status)
+}
+
+func TestInstallScheduler_JournalsInterruption(t *testing.T) {
+	repo := newJournaledRepository(t)
+	rec := newRecordingInstall()
+	apps := []types.CrossPlatformApp{schedulerTestApp("git", "apt")}
+
+	journal, _ := openInstallJournal(repo, apps, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s := newTestScheduler(apps, 1, rec)
+	s.si.journal = journal
+	assert.ErrorIs(t, s.run(ctx), context.Canceled)
+
+	latest, err := repo.LatestIncompleteSession()
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, journal.SessionID(), latest.ID)
+	assert.Equal(t, types.SessionStatusInterrupted, latest.Status)
+}
+
+func TestOpenInstallJournal_ResumesFromFirstIncompleteStep(t *testing.T) {
+	repo := newJournaledRepository(t)
+	require.NoError(t, repo.CreateSession("s1", []string{"git", "docker", "node"}))
+	require.NoError(t, repo.UpdateSessionApp("s1", "git", types.SessionAppCompleted, types.InstallStepRegister, ""))
+	require.NoError(t, repo.UpdateSessionApp("s1", "docker", types.SessionAppFailed, types.InstallStepInstall, "post-install failed"))
+	require.NoError(t, repo.SetSessionStatus("s1", types.SessionStatusInterrupted))
+
+	session, err := repo.GetSession("s1")
+	require.NoError(t, err)
+	apps := []types.CrossPlatformApp{
+		schedulerTestApp("git", "apt"),
+		schedulerTestApp("Docker", "apt"),
+		schedulerTestApp("node", "mise"),
+	}
+
+	journal, remaining := openInstallJournal(repo, apps, session)
+	require.NotNil(t, journal)
+	assert.Equal(t, "s1", journal.SessionID())
+	require.Len(t, remaining, 2)
+	assert.Equal(t, "Docker", remaining[0].Name)
+
+	assert.False(t, journal.needsStep("Docker", types.InstallStepDependencies))
+	assert.False(t, journal.needsStep("Docker", types.InstallStepInstall))
+	assert.True(t, journal.needsStep("Docker", types.InstallStepPostInstall))
+	assert.True(t, journal.needsStep("node", types.InstallStepPreInstall))
+
+	reopened, err := repo.GetSession("s1")
+	require.NoError(t, err)
+	assert.Equal(t, types.SessionStatusRunning, reopened.Status)
+}
+
+func TestOpenInstallJournal_DoesNotResumeWithoutRequest(t *testing.T) {
+	repo := newJournaledRepository(t)
+	apps := []types.CrossPlatformApp{schedulerTestApp("git", "apt"), schedulerTestApp("node", "mise")}
+
+	require.NoError(t, repo.CreateSession("s1", []string{"git", "node"}))
+	require.NoError(t, repo.UpdateSessionApp("s1", "git", types.SessionAppCompleted, types.InstallStepRegister, ""))
+	require.NoError(t, repo.SetSessionStatus("s1", types.SessionStatusInterrupted))
+
+	// The same plan starts a new session and leaves the interrupted one for --resume
+	journal, remaining := openInstallJournal(repo, apps, nil)
+	require.NotNil(t, journal)
+	assert.NotEqual(t, "s1", journal.SessionID())
+	assert.Equal(t, apps, remaining)
+	assert.True(t, journal.needsStep("git", types.InstallStepInstall))
+
+	previous, err := repo.GetSession("s1")
+	require.NoError(t, err)
+	assert.Equal(t, types.SessionStatusInterrupted, previous.Status)
+
+	// A run that does not install node leaves the interrupted session and says how to resume it
+	journal.appFinished("git", nil)
+	journal.appFinished("node", errors.New("download failed"))
+	journal.finish(1, nil)
+	previous, err = repo.GetSession("s1")
+	require.NoError(t, err)
+	assert.Equal(t, types.SessionStatusInterrupted, previous.Status)
+	assert.Contains(t, journal.resumeHint(), "devex install --resume")
+
+}
+
+func TestInstallJournal_AbandonsSessionItCovers(t *testing.T) {
+	repo := newJournaledRepository(t)
+	require.NoError(t, repo.CreateSession("s1", []string{"git", "node"}))
+	require.NoError(t, repo.UpdateSessionApp("s1", "git", types.SessionAppCompleted, types.InstallStepRegister, ""))
+	require.NoError(t, repo.SetSessionStatus("s1", types.SessionStatusInterrupted))
+
+	// Installing node is enough since the interrupted session already installed git
+	journal, _ := openInstallJournal(repo, []types.CrossPlatformApp{schedulerTestApp("Node", "mise")}, nil)
+	require.NotNil(t, journal)
+	journal.appFinished("Node", nil)
+	journal.finish(0, nil)
+
+	previous, err := repo.GetSession("s1")
+	require.NoError(t, err)
+	assert.Equal(t, types.SessionStatusAbandoned, previous.Status)
+	assert.Empty(t, journal.resumeHint())
+}
+
+func TestInstallApp_SkipsStepsFinishedBySession(t *testing.T) {
+	repo := newJournaledRepository(t)
+	require.NoError(t, repo.CreateSession("s1", []string{"tool"}))
+	require.NoError(t, repo.UpdateSessionApp("s1", "tool", types.SessionAppFailed, types.InstallStepInstall, "post-install failed"))
+	session, err := repo.GetSession("s1")
+	require.NoError(t, err)
+
+	app := schedulerTestApp("tool", "curlpipe")
+	app.Linux.PostInstall = []types.InstallCommand{{Shell: "echo configured"}}
+	app.MacOS.PostInstall = app.Linux.PostInstall
+	app.Windows.PostInstall = app.Linux.PostInstall
+
+	journal, _ := openInstallJournal(repo, []types.CrossPlatformApp{app}, session)
+	executor := NewMockCommandExecutor()
+	si := NewStreamingInstallerWithExecutor(nil, repo, context.Background(), executor, getTestSettings())
+	si.journal = journal
+
+	require.NoError(t, si.InstallApp(context.Background(), app, config.CrossPlatformSettings{}))
+	assert.Equal(t, []string{"echo configured"}, executor.GetExecutedCommands())
+	assert.Contains(t, repo.installedApps, "tool")
+
+	journal.appFinished(app.Name, nil)
+	statuses := sessionAppStatuses(t, repo, "s1")
+	assert.Equal(t, types.SessionAppCompleted, statuses["tool"])
+}
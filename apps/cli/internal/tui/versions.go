@@ -0,0 +1,196 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jameswlane/devex/apps/cli/internal/config"
+	"github.com/jameswlane/devex/apps/cli/internal/installers"
+	"github.com/jameswlane/devex/apps/cli/internal/lockfile"
+	"github.com/jameswlane/devex/apps/cli/internal/log"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+// versionPinner resolves app version constraints to exact package versions before installation
+// and records the versions that ended up installed in devex.lock. In locked mode the versions
+// from the lockfile are installed instead and the lockfile is left untouched.
+type versionPinner struct {
+	path   string
+	locked bool
+	lookup func(method string) (types.VersionedInstaller, error)
+
+	mutex sync.Mutex
+	lock  *lockfile.Lockfile // nil when installed versions are not recorded
+	dirty bool
+}
+
+// newVersionPinner creates a pinner backed by the package manager plugins
+func newVersionPinner(ctx context.Context, lock *lockfile.Lockfile, path string, locked bool) *versionPinner {
+	return &versionPinner{
+		path:   path,
+		locked: locked,
+		lock:   lock,
+		lookup: func(method string) (types.VersionedInstaller, error) {
+			return installers.GetVersionedInstaller(ctx, method)
+		},
+	}
+}
+
+// openVersionPinner loads the user's lockfile so installed versions can be recorded in it.
+// Constraints are still resolved when the lockfile cannot be read, but nothing is recorded.
+func openVersionPinner(ctx context.Context, settings config.CrossPlatformSettings) *versionPinner {
+	if settings.HomeDir == "" {
+		return newVersionPinner(ctx, nil, "", false)
+	}
+
+	path := lockfile.DefaultPath(settings.HomeDir)
+	lock, err := lockfile.LoadOrNew(path)
+	if err != nil {
+		log.Warn("Lockfile is unreadable, installed versions will not be recorded", "path", path, "error", err)
+		return newVersionPinner(ctx, nil, path, false)
+	}
+	return newVersionPinner(ctx, lock, path, false)
+}
+
+// pinVersions returns the install command for app with its packages pinned to exact versions.
+// The command is returned unchanged when the app has no version constraint and nothing is locked.
+func (si *StreamingInstaller) pinVersions(app types.CrossPlatformApp, osConfig types.OSConfig) (string, error) {
+	p := si.versions
+	command := osConfig.InstallCommand
+	if p == nil {
+		return command, nil
+	}
+	constraint := app.GetVersionConstraint()
+
+	if p.locked && p.lock != nil {
+		entry, ok := p.lock.Find(app.Name)
+		switch {
+		case !ok:
+			si.sendLog("WARN", fmt.Sprintf("%s is not in the lockfile, installing without a locked version", app.Name))
+		case entry.InstallMethod != osConfig.InstallMethod:
+			si.sendLog("WARN", fmt.Sprintf("%s was locked with %s but installs with %s here, installing without a locked version",
+				app.Name, entry.InstallMethod, osConfig.InstallMethod))
+		default:
+			return si.pinLocked(app, osConfig, entry)
+		}
+	}
+
+	if constraint == "" {
+		return command, nil
+	}
+
+	installer, err := p.lookup(osConfig.InstallMethod)
+	if err != nil {
+		return "", fmt.Errorf("%s requires version %q: %w", app.Name, constraint, err)
+	}
+	resolved, err := installer.ResolveVersions(command, constraint)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve version %q for %s: %w", constraint, app.Name, err)
+	}
+
+	for _, version := range resolved {
+		si.sendLog("INFO", fmt.Sprintf("Resolved %s %s to %s", version.Name, constraint, version.Version))
+	}
+	return applyPinnedVersions(command, resolved), nil
+}
+
+// pinLocked pins each package of app to the version recorded in the lockfile
+func (si *StreamingInstaller) pinLocked(app types.CrossPlatformApp, osConfig types.OSConfig, entry lockfile.App) (string, error) {
+	command := osConfig.InstallCommand
+	installer, err := si.versions.lookup(osConfig.InstallMethod)
+	if errors.Is(err, installers.ErrVersionPinningUnsupported) {
+		si.sendLog("WARN", fmt.Sprintf("Cannot install the locked versions of %s: %v", app.Name, err))
+		return command, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var resolved []types.PackageVersion
+	for _, pkg := range entry.Packages {
+		versions, err := installer.ResolveVersions(pkg.Name, "="+pkg.Version)
+		if err != nil {
+			return "", fmt.Errorf("locked version %s of %s is not available: %w", pkg.Version, pkg.Name, err)
+		}
+		resolved = append(resolved, versions...)
+	}
+
+	si.sendLog("INFO", fmt.Sprintf("Installing %s at its locked versions", app.Name))
+	return applyPinnedVersions(command, resolved), nil
+}
+
+// recordVersions stores the installed versions of app's packages in the lockfile
+func (si *StreamingInstaller) recordVersions(app types.CrossPlatformApp, osConfig types.OSConfig) {
+	p := si.versions
+	if p == nil || p.lock == nil || p.locked {
+		return
+	}
+
+	installer, err := p.lookup(osConfig.InstallMethod)
+	if err != nil {
+		log.Debug("Not recording installed version", "app", app.Name, "reason", err)
+		return
+	}
+	installed, err := installer.InstalledVersions(osConfig.InstallCommand)
+	if err != nil {
+		si.sendLog("WARN", fmt.Sprintf("Failed to query the installed version of %s, it will not be locked: %v", app.Name, err))
+		return
+	}
+
+	entry := lockfile.App{
+		Name:          app.Name,
+		InstallMethod: osConfig.InstallMethod,
+		Constraint:    app.GetVersionConstraint(),
+	}
+	for _, version := range installed {
+		entry.Packages = append(entry.Packages, lockfile.Package{
+			Name:    version.Name,
+			Version: version.Version,
+			Source:  version.Source,
+		})
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.lock.Upsert(entry)
+	p.dirty = true
+}
+
+// save writes the lockfile if any installed versions were recorded
+func (p *versionPinner) save() error {
+	if p == nil {
+		return nil
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if !p.dirty {
+		return nil
+	}
+	if err := p.lock.Save(p.path); err != nil {
+		return err
+	}
+	p.dirty = false
+	return nil
+}
+
+// applyPinnedVersions replaces each package in an install command with the spec selecting its resolved version
+func applyPinnedVersions(command string, versions []types.PackageVersion) string {
+	specs := make(map[string]string, len(versions))
+	for _, version := range versions {
+		if version.Spec != "" {
+			specs[version.Name] = version.Spec
+		}
+	}
+
+	fields := strings.Fields(command)
+	for i, field := range fields {
+		if spec, ok := specs[field]; ok {
+			fields[i] = spec
+		}
+	}
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jameswlane/devex/apps/cli/internal/config"
+	"github.com/jameswlane/devex/apps/cli/internal/installers"
+	"github.com/jameswlane/devex/apps/cli/internal/lockfile"
+	"github.com/jameswlane/devex/apps/cli/internal/types"
+)
+
+// fakeVersionedInstaller resolves versions from a fixed list per package
+type fakeVersionedInstaller struct {
+	available map[string][]string
+	installed map[string]string
+	resolved  []string
+}
+
+func (f *fakeVersionedInstaller) ResolveVersions(command, constraint string) ([]types.PackageVersion, error) {
+	f.resolved = append(f.resolved, command+" "+constraint)
+	for _, version := range f.available[command] {
+		if constraint == "="+version || strings.HasPrefix(version, constraint) {
+			return []types.PackageVersion{{Name: command, Version: version, Spec: command + "-" + version}}, nil
+		}
+	}
+	return nil, fmt.Errorf("no version of %s matches %s", command, constraint)
+}
+
+func (f *fakeVersionedInstaller) InstalledVersions(command string) ([]types.PackageVersion, error) {
+	return []types.PackageVersion{{Name: command, Version: f.installed[command], Source: "fedora"}}, nil
+}
+
+func newPinningInstaller(pinner *versionPinner, backend *fakeVersionedInstaller) (*StreamingInstaller, *MockCommandExecutor) {
+	pinner.lookup = func(method string) (types.VersionedInstaller, error) {
+		if method != "dnf" {
+			return nil, installers.ErrVersionPinningUnsupported
+		}
+		return backend, nil
+	}
+	executor := NewMockCommandExecutor()
+	si := NewStreamingInstallerWithExecutor(nil, &MockRepository{}, context.Background(), executor, getTestSettings())
+	si.versions = pinner
+	return si, executor
+}
+
+func TestApplyPinnedVersions(t *testing.T) {
+	command := applyPinnedVersions("git  git-lfs", []types.PackageVersion{
+		{Name: "git", Spec: "git=1:2.43.0-1"},
+		{Name: "git-lfs"},
+	})
+	assert.Equal(t, "git=1:2.43.0-1 git-lfs", command)
+}
+
+func TestInstallApp_PinsVersionConstraintAndRecordsLock(t *testing.T) {
+	path := t.TempDir() + "/devex.lock"
+	backend := &fakeVersionedInstaller{
+		available: map[string][]string{"git": {"2.43.0", "2.39.2"}},
+		installed: map[string]string{"git": "2.39.2"},
+	}
+	si, executor := newPinningInstaller(&versionPinner{path: path, lock: lockfile.New()}, backend)
+
+	app := schedulerTestApp("git", "dnf")
+	app.Version = "2.39"
+	require.NoError(t, si.InstallApp(context.Background(), app, config.CrossPlatformSettings{}))
+	assert.Contains(t, executor.GetExecutedCommands(), "sudo dnf install -y git-2.39.2")
+
+	require.NoError(t, si.versions.save())
+	lock, err := lockfile.Load(path)
+	require.NoError(t, err)
+	entry, ok := lock.Find("git")
+	require.True(t, ok)
+	assert.Equal(t, "dnf", entry.InstallMethod)
+	assert.Equal(t, "2.39", entry.Constraint)
+	assert.Equal(t, []lockfile.Package{{Name: "git", Version: "2.39.2", Source: "fedora"}}, entry.Packages)
+}
+
+func TestInstallApp_FailsWhenConstraintCannotBePinned(t *testing.T) {
+	si, executor := newPinningInstaller(&versionPinner{}, &fakeVersionedInstaller{})
+
+	app := schedulerTestApp("tool", "curlpipe")
+	app.Version = "1.0"
+	err := si.InstallApp(context.Background(), app, config.CrossPlatformSettings{})
+	require.ErrorIs(t, err, installers.ErrVersionPinningUnsupported)
+	assert.Empty(t, executor.GetExecutedCommands())
+}
+
+func TestInstallApp_LockedInstallsLockedVersion(t *testing.T) {
+	lock := lockfile.New()
+	lock.Upsert(lockfile.App{Name: "git", InstallMethod: "dnf", Packages: []lockfile.Package{{Name: "git", Version: "2.39.2"}}})
+	backend := &fakeVersionedInstaller{available: map[string][]string{"git": {"2.43.0", "2.39.2"}}}
+	si, executor := newPinningInstaller(&versionPinner{lock: lock, locked: true}, backend)
+
+	// The locked version wins over the configured constraint
+	app := schedulerTestApp("git", "dnf")
+	app.Version = "2.43"
+	require.NoError(t, si.InstallApp(context.Background(), app, config.CrossPlatformSettings{}))
+	assert.Contains(t, executor.GetExecutedCommands(), "sudo dnf install -y git-2.39.2")
+	assert.Equal(t, []string{"git =2.39.2"}, backend.resolved)
+	assert.False(t, si.versions.dirty, "locked installs leave the lockfile untouched")
+
+	backend.available["git"] = []string{"2.43.0"}
+	err := si.InstallApp(context.Background(), app, config.CrossPlatformSettings{})
+	assert.ErrorContains(t, err, "locked version 2.39.2 of git is not available")
+}
@@ -3,6 +3,8 @@ package types
 import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/platform"
 )
 
 var _ = Describe("CrossPlatformApp", func() {
@@ -236,4 +238,62 @@ var _ = Describe("CrossPlatformApp", func() {
 			)
 		})
 	})
+
+	Describe("GetBestOSConfigFor", func() {
+		var app CrossPlatformApp
+
+		BeforeEach(func() {
+			app = CrossPlatformApp{
+				Name: "docker",
+				AllPlatforms: OSConfig{
+					InstallMethod:  "apt",
+					InstallCommand: "docker.io",
+					Alternatives: []OSConfig{
+						{
+							InstallMethod:        "dnf",
+							InstallCommand:       "moby-engine",
+							PlatformRequirements: []PlatformRequirement{{OS: "fedora"}},
+						},
+						{
+							InstallMethod:        "pacman",
+							InstallCommand:       "docker",
+							PlatformRequirements: []PlatformRequirement{{OS: "arch", Arch: "amd64"}},
+						},
+					},
+				},
+			}
+		})
+
+		It("keeps the unconstrained default on a matching distribution", func() {
+			best := app.GetBestOSConfigFor(testPlatform("ubuntu", "24.04", "amd64"))
+			Expect(best.InstallMethod).To(Equal("apt"))
+		})
+
+		It("prefers an alternative whose requirements name the distribution", func() {
+			best := app.GetBestOSConfigFor(testPlatform("fedora", "40", "amd64"))
+			Expect(best.InstallMethod).To(Equal("dnf"))
+			Expect(best.InstallCommand).To(Equal("moby-engine"))
+		})
+
+		It("checks the architecture of a requirement", func() {
+			Expect(app.GetBestOSConfigFor(testPlatform("arch", "", "amd64")).InstallMethod).To(Equal("pacman"))
+			Expect(app.GetBestOSConfigFor(testPlatform("arch", "", "arm64")).InstallMethod).To(Equal("apt"))
+		})
+
+		It("leaves the selection of GetBestOSConfig unchanged", func() {
+			Expect(app.GetBestOSConfig().InstallMethod).To(Equal("apt"))
+		})
+
+		It("matches minimum versions", func() {
+			requirement := PlatformRequirement{OS: "rhel", Version: "8+"}
+			Expect(requirement.matches(testPlatform("rhel", "9.3", "amd64"))).To(BeTrue())
+			Expect(requirement.matches(testPlatform("rhel", "8", "amd64"))).To(BeTrue())
+			Expect(requirement.matches(testPlatform("rhel", "7.9", "amd64"))).To(BeFalse())
+			Expect(PlatformRequirement{OS: "ubuntu", Version: "22.04"}.matches(testPlatform("ubuntu", "22.04.3", "amd64"))).To(BeTrue())
+		})
+	})
 })
+
+func testPlatform(distribution, version, arch string) platform.DetectionResult {
+	return platform.DetectionResult{OS: "linux", Distribution: distribution, Version: version, Architecture: arch}
+}
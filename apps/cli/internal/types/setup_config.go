@@ -11,7 +11,7 @@ type SetupConfig struct {
 	Timeouts SetupTimeouts `yaml:"timeouts"`
 
 	// Steps define the workflow screens/questions
-	Steps []SetupStep `yaml:"steps"`
+	Steps []SetupStep `yaml:"steps" jsonschema:"required"`
 
 	// Actions map action IDs to their implementations
 	Actions map[string]SetupAction `yaml:"actions,omitempty"`
@@ -36,16 +36,16 @@ type SetupTimeouts struct {
 // SetupStep represents a single screen/step in the setup workflow
 type SetupStep struct {
 	// Unique identifier for this step
-	ID string `yaml:"id"`
+	ID string `yaml:"id" jsonschema:"required"`
 
 	// Display name shown to users
-	Title string `yaml:"title"`
+	Title string `yaml:"title" jsonschema:"required"`
 
 	// Optional description/help text
 	Description string `yaml:"description,omitempty"`
 
 	// Type of step (question, info, action)
-	Type StepType `yaml:"type"`
+	Type StepType `yaml:"type" jsonschema:"required"`
 
 	// Question configuration (if type is "question")
 	Question *Question `yaml:"question,omitempty"`
@@ -78,13 +78,13 @@ const (
 // Question represents a user input question
 type Question struct {
 	// Type of question (text, select, multiselect)
-	Type QuestionType `yaml:"type"`
+	Type QuestionType `yaml:"type" jsonschema:"required"`
 
 	// Variable name to store the answer
-	Variable string `yaml:"variable"`
+	Variable string `yaml:"variable" jsonschema:"required"`
 
 	// Prompt text shown to user
-	Prompt string `yaml:"prompt"`
+	Prompt string `yaml:"prompt" jsonschema:"required"`
 
 	// Placeholder text for text inputs
 	Placeholder string `yaml:"placeholder,omitempty"`
@@ -121,7 +121,7 @@ type QuestionOption struct {
 	Label string `yaml:"label"`
 
 	// Value stored when selected
-	Value string `yaml:"value"`
+	Value string `yaml:"value" jsonschema:"required"`
 
 	// Description/help text for this option
 	Description string `yaml:"description,omitempty"`
@@ -136,12 +136,12 @@ type QuestionOption struct {
 // OptionsSource defines how to load options dynamically
 type OptionsSource struct {
 	// Type of source (config, system, plugin)
-	Type SourceType `yaml:"type"`
+	Type SourceType `yaml:"type" jsonschema:"required"`
 
 	// Path to config file or key (for config source)
 	Path string `yaml:"path,omitempty"`
 
-	// Key within the config (for config source)
+	// Key within the config (for config source), or the option set to query (for plugin source)
 	Key string `yaml:"key,omitempty"`
 
 	// System detection type (for system source)
@@ -149,6 +149,18 @@ type OptionsSource struct {
 
 	// Transform to apply to loaded options
 	Transform string `yaml:"transform,omitempty"`
+
+	// Plugin that answers the options query (for plugin source)
+	Plugin string `yaml:"plugin,omitempty"`
+
+	// Arguments of the option set, e.g. the tool whose versions are listed (for plugin source)
+	Args []string `yaml:"args,omitempty"`
+
+	// Flags passed to the plugin, e.g. limit: "10" (for plugin source)
+	Flags map[string]string `yaml:"flags,omitempty"`
+
+	// How long plugin results are cached, e.g. "30m" or "0" to always query (default 1h)
+	CacheTTL string `yaml:"cache_ttl,omitempty"`
 }
 
 // SourceType defines where options come from
@@ -185,7 +197,7 @@ type Validation struct {
 // InfoContent represents informational content to display
 type InfoContent struct {
 	// Message to display
-	Message string `yaml:"message"`
+	Message string `yaml:"message" jsonschema:"required"`
 
 	// Style/type of info (info, warning, error, success)
 	Style InfoStyle `yaml:"style,omitempty"`
@@ -207,7 +219,7 @@ const (
 // StepAction represents an action to execute
 type StepAction struct {
 	// Action type (install, configure, execute)
-	Type ActionType `yaml:"type"`
+	Type ActionType `yaml:"type" jsonschema:"required"`
 
 	// Action-specific parameters
 	Params map[string]interface{} `yaml:"params,omitempty"`
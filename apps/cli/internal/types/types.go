@@ -6,6 +6,12 @@ import (
 	"fmt"
 	"os/user"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jameswlane/devex/apps/cli/internal/platform"
 )
 
 // BaseConfig defines common fields shared across multiple configurations.
@@ -21,6 +27,7 @@ type AppConfig struct {
 	Default            bool               `mapstructure:"default" yaml:"default"`
 	InstallMethod      string             `mapstructure:"install_method" yaml:"install_method"`
 	InstallCommand     string             `mapstructure:"install_command" yaml:"install_command"`
+	Version            string             `mapstructure:"version" yaml:"version,omitempty"`
 	UninstallCommand   string             `mapstructure:"uninstall_command" yaml:"uninstall_command"`
 	Dependencies       []string           `mapstructure:"dependencies" yaml:"dependencies"`
 	SystemRequirements SystemRequirements `mapstructure:"system_requirements" yaml:"system_requirements,omitempty"`
@@ -33,6 +40,9 @@ type AppConfig struct {
 	Conflicts          []string           `mapstructure:"conflicts" yaml:"conflicts"`
 	DockerOptions      DockerOptions      `mapstructure:"docker_options" yaml:"docker_options"`
 	DownloadURL        string             `mapstructure:"download_url" yaml:"download_url"`
+	SHA256             string             `mapstructure:"sha256" yaml:"sha256,omitempty"`
+	Signature          string             `mapstructure:"signature" yaml:"signature,omitempty"`
+	SignatureKey       string             `mapstructure:"signature_key" yaml:"signature_key,omitempty"`
 	InstallDir         string             `mapstructure:"install_dir" yaml:"install_dir"`
 	Symlink            string             `mapstructure:"symlink" yaml:"symlink"`
 	ShellUpdates       []string           `mapstructure:"shell_updates" yaml:"shell_updates"`
@@ -67,10 +77,24 @@ func (a *AppConfig) Validate() error {
 
 // DockerOptions defines options for Docker containers.
 type DockerOptions struct {
-	Ports         []string `mapstructure:"ports" yaml:"ports"`
-	ContainerName string   `mapstructure:"container_name" yaml:"container_name"`
-	Environment   []string `mapstructure:"environment" yaml:"environment"`
-	RestartPolicy string   `mapstructure:"restart_policy" yaml:"restart_policy"`
+	Ports         []string           `mapstructure:"ports" yaml:"ports"`
+	ContainerName string             `mapstructure:"container_name" yaml:"container_name"`
+	Environment   []string           `mapstructure:"environment" yaml:"environment"`
+	RestartPolicy string             `mapstructure:"restart_policy" yaml:"restart_policy"`
+	Volumes       []string           `mapstructure:"volumes" yaml:"volumes,omitempty"`
+	Network       string             `mapstructure:"network" yaml:"network,omitempty"`
+	Healthcheck   *DockerHealthcheck `mapstructure:"healthcheck" yaml:"healthcheck,omitempty"`
+	Command       []string           `mapstructure:"command" yaml:"command,omitempty"`
+	Stack         string             `mapstructure:"stack" yaml:"stack,omitempty"` // run as a service of a devex-managed compose project
+}
+
+// DockerHealthcheck defines the health check of a Docker container.
+type DockerHealthcheck struct {
+	Test        string `mapstructure:"test" yaml:"test"`
+	Interval    string `mapstructure:"interval" yaml:"interval,omitempty"`
+	Timeout     string `mapstructure:"timeout" yaml:"timeout,omitempty"`
+	StartPeriod string `mapstructure:"start_period" yaml:"start_period,omitempty"`
+	Retries     int    `mapstructure:"retries" yaml:"retries,omitempty"`
 }
 
 // Validate checks the validity of DockerOptions.
@@ -236,6 +260,94 @@ type SystemRepository interface {
 	GetAll() (map[string]string, error)
 }
 
+// InstallStep is a phase of an app installation recorded in the session journal.
+// Steps are listed in execution order; a journaled step means the step finished successfully.
+type InstallStep string
+
+const (
+	InstallStepNone         InstallStep = ""
+	InstallStepPreInstall   InstallStep = "pre-install"
+	InstallStepDependencies InstallStep = "dependencies"
+	InstallStepInstall      InstallStep = "install"
+	InstallStepPostInstall  InstallStep = "post-install"
+	InstallStepRegister     InstallStep = "register"
+)
+
+// InstallSteps lists the journaled installation steps in execution order
+var InstallSteps = []InstallStep{
+	InstallStepPreInstall,
+	InstallStepDependencies,
+	InstallStepInstall,
+	InstallStepPostInstall,
+	InstallStepRegister,
+}
+
+// Completes reports whether having finished step s means that step other is also finished
+func (s InstallStep) Completes(other InstallStep) bool {
+	return installStepIndex(s) >= installStepIndex(other)
+}
+
+func installStepIndex(step InstallStep) int {
+	for i, candidate := range InstallSteps {
+		if candidate == step {
+			return i
+		}
+	}
+	return -1
+}
+
+// Installation session and per-app statuses
+const (
+	SessionStatusRunning     = "running"
+	SessionStatusCompleted   = "completed"
+	SessionStatusFailed      = "failed"
+	SessionStatusInterrupted = "interrupted"
+	SessionStatusAbandoned   = "abandoned"
+
+	SessionAppPending   = "pending"
+	SessionAppRunning   = "running"
+	SessionAppCompleted = "completed"
+	SessionAppFailed    = "failed"
+	SessionAppSkipped   = "skipped"
+)
+
+// InstallSessionApp is the journaled state of one app in an installation session
+type InstallSessionApp struct {
+	AppName  string
+	Position int
+	Status   string
+	Step     InstallStep // last step that finished successfully
+	Error    string
+}
+
+// InstallSession is a journaled installation run that can be resumed after an interruption
+type InstallSession struct {
+	ID        string
+	Status    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Apps      []InstallSessionApp
+}
+
+// Incomplete reports whether the session has apps that still need to be installed
+func (s *InstallSession) Incomplete() bool {
+	for _, app := range s.Apps {
+		if app.Status != SessionAppCompleted {
+			return true
+		}
+	}
+	return false
+}
+
+// SessionRepository persists installation session journals
+type SessionRepository interface {
+	CreateSession(id string, apps []string) error
+	UpdateSessionApp(sessionID, appName, status string, step InstallStep, errMsg string) error
+	SetSessionStatus(sessionID, status string) error
+	GetSession(sessionID string) (*InstallSession, error)
+	LatestIncompleteSession() (*InstallSession, error)
+}
+
 // ThemePreferences stores user theme preferences
 type ThemePreferences struct {
 	GlobalTheme string            `json:"global_theme"`
@@ -257,6 +369,28 @@ type BaseInstaller interface {
 	IsInstalled(command string) (bool, error)
 }
 
+// PackageVersion is an exact package version as resolved or reported by a package manager
+type PackageVersion struct {
+	Name    string
+	Version string
+	Source  string // Repository, remote or index the version comes from
+	Spec    string // Install argument selecting exactly this version, e.g. "git=1:2.43.0-1"
+}
+
+// VersionedInstaller is implemented by installers whose package manager can pin exact versions
+type VersionedInstaller interface {
+	// ResolveVersions returns the newest version of each package in command matching constraint
+	ResolveVersions(command, constraint string) ([]PackageVersion, error)
+	// InstalledVersions returns the installed version of each package in command
+	InstalledVersions(command string) ([]PackageVersion, error)
+}
+
+// OptionsInstaller is implemented by installers that accept install options beyond the package
+// names, such as the container spec of the docker installer
+type OptionsInstaller interface {
+	InstallWithOptions(command string, options map[string]string, repo Repository) error
+}
+
 type CommandExecutor interface {
 	RunCommand(ctx context.Context, name string, args ...string) (string, error)
 }
@@ -320,49 +454,55 @@ type ShellConfig interface {
 
 // Cross-Platform Configuration Types
 
-// OSConfig defines OS-specific installation configuration
 // PlatformRequirement defines OS and version requirements for an installation method
 type PlatformRequirement struct {
-	OS                   string   `mapstructure:"os" yaml:"os"`
-	Version              string   `mapstructure:"version" yaml:"version,omitempty"`
-	Arch                 string   `mapstructure:"arch" yaml:"arch,omitempty"`
-	PlatformDependencies []string `mapstructure:"dependencies" yaml:"dependencies,omitempty"`
+	OS                   string   `mapstructure:"os" yaml:"os" jsonschema:"required"`         // operating system or distribution ID, e.g. linux, ubuntu or fedora
+	Version              string   `mapstructure:"version" yaml:"version,omitempty"`           // distribution version, e.g. "22.04", or "8+" for 8 and newer
+	Arch                 string   `mapstructure:"arch" yaml:"arch,omitempty"`                 // CPU architecture, e.g. amd64 or arm64
+	PlatformDependencies []string `mapstructure:"dependencies" yaml:"dependencies,omitempty"` // packages installed first on this platform
 }
 
+// OSConfig defines OS-specific installation configuration
 type OSConfig struct {
-	InstallMethod        string                `mapstructure:"install_method" yaml:"install_method"`
-	InstallCommand       string                `mapstructure:"install_command" yaml:"install_command"`
-	UninstallCommand     string                `mapstructure:"uninstall_command" yaml:"uninstall_command"`
-	OfficialSupport      bool                  `mapstructure:"official_support" yaml:"official_support,omitempty"`
-	PlatformRequirements []PlatformRequirement `mapstructure:"platform_requirements" yaml:"platform_requirements,omitempty"`
+	InstallMethod        string                `mapstructure:"install_method" yaml:"install_method" jsonschema:"required"`   // package manager plugin that installs the app, e.g. apt or brew
+	InstallCommand       string                `mapstructure:"install_command" yaml:"install_command"`                       // packages or arguments passed to the install method
+	Version              string                `mapstructure:"version" yaml:"version,omitempty"`                             // version constraint on this platform, overrides the app version
+	UninstallCommand     string                `mapstructure:"uninstall_command" yaml:"uninstall_command"`                   // packages or arguments passed to remove the app
+	OfficialSupport      bool                  `mapstructure:"official_support" yaml:"official_support,omitempty"`           // whether the maintainers test this configuration
+	PlatformRequirements []PlatformRequirement `mapstructure:"platform_requirements" yaml:"platform_requirements,omitempty"` // platforms this configuration is selected on; any platform when empty
 	AptSources           []AptSource           `mapstructure:"apt_sources" yaml:"apt_sources,omitempty"`
 	BrewCask             bool                  `mapstructure:"brew_cask" yaml:"brew_cask,omitempty"`
 	BrewTap              string                `mapstructure:"brew_tap" yaml:"brew_tap,omitempty"`
 	DownloadURL          string                `mapstructure:"download_url" yaml:"download_url,omitempty"`
+	SHA256               string                `mapstructure:"sha256" yaml:"sha256,omitempty" jsonschema:"pattern=^[a-fA-F0-9]{64}$"` // expected checksum of the download
+	Signature            string                `mapstructure:"signature" yaml:"signature,omitempty"`                                  // URL of a detached OpenPGP signature of the download
+	SignatureKey         string                `mapstructure:"signature_key" yaml:"signature_key,omitempty"`                          // URL or path of the public key that made Signature
 	ExtractPath          string                `mapstructure:"extract_path" yaml:"extract_path,omitempty"`
 	Destination          string                `mapstructure:"destination" yaml:"destination,omitempty"`
-	Dependencies         []string              `mapstructure:"dependencies" yaml:"dependencies,omitempty"`
+	Dependencies         []string              `mapstructure:"dependencies" yaml:"dependencies,omitempty"` // names of apps installed before this one
 	SystemRequirements   SystemRequirements    `mapstructure:"system_requirements" yaml:"system_requirements,omitempty"`
 	PreInstall           []InstallCommand      `mapstructure:"pre_install" yaml:"pre_install,omitempty"`
 	PostInstall          []InstallCommand      `mapstructure:"post_install" yaml:"post_install,omitempty"`
-	Alternatives         []OSConfig            `mapstructure:"alternatives" yaml:"alternatives,omitempty"`
+	Alternatives         []OSConfig            `mapstructure:"alternatives" yaml:"alternatives,omitempty"` // configurations for other platforms, picked by platform_requirements
 	ConfigFiles          []ConfigFile          `mapstructure:"config_files" yaml:"config_files,omitempty"`
 	Themes               []Theme               `mapstructure:"themes" yaml:"themes,omitempty"`
 	CleanupFiles         []string              `mapstructure:"cleanup_files" yaml:"cleanup_files,omitempty"`
-	Conflicts            []string              `mapstructure:"conflicts" yaml:"conflicts,omitempty"`
+	Conflicts            []string              `mapstructure:"conflicts" yaml:"conflicts,omitempty"` // names of apps that cannot be installed together with this one
+	DockerOptions        DockerOptions         `mapstructure:"docker_options" yaml:"docker_options,omitempty"`
 }
 
 // CrossPlatformApp defines an application with OS-specific installation methods
 type CrossPlatformApp struct {
-	Name                string   `mapstructure:"name" yaml:"name"`
-	Description         string   `mapstructure:"description" yaml:"description"`
-	Category            string   `mapstructure:"category" yaml:"category"`
-	Default             bool     `mapstructure:"default" yaml:"default"`
-	DesktopEnvironments []string `mapstructure:"desktop_environments" yaml:"desktop_environments,omitempty"`
-	Linux               OSConfig `mapstructure:"linux" yaml:"linux,omitempty"`
-	MacOS               OSConfig `mapstructure:"macos" yaml:"macos,omitempty"`
-	Windows             OSConfig `mapstructure:"windows" yaml:"windows,omitempty"`
-	AllPlatforms        OSConfig `mapstructure:"all_platforms" yaml:"all_platforms,omitempty"`
+	Name                string   `mapstructure:"name" yaml:"name" jsonschema:"required"`                     // unique name, referenced by dependencies and conflicts
+	Description         string   `mapstructure:"description" yaml:"description" jsonschema:"required"`       // short description shown when selecting apps
+	Category            string   `mapstructure:"category" yaml:"category"`                                   // category the app is listed under, e.g. Development Tools
+	Default             bool     `mapstructure:"default" yaml:"default"`                                     // whether the app is installed by default
+	Version             string   `mapstructure:"version" yaml:"version,omitempty"`                           // version constraint, e.g. ">=2.40" or "~1.2"
+	DesktopEnvironments []string `mapstructure:"desktop_environments" yaml:"desktop_environments,omitempty"` // desktops the app suits, or "all"
+	Linux               OSConfig `mapstructure:"linux" yaml:"linux,omitempty"`                               // installation on Linux
+	MacOS               OSConfig `mapstructure:"macos" yaml:"macos,omitempty"`                               // installation on macOS
+	Windows             OSConfig `mapstructure:"windows" yaml:"windows,omitempty"`                           // installation on Windows
+	AllPlatforms        OSConfig `mapstructure:"all_platforms" yaml:"all_platforms,omitempty"`               // installation on every platform, overrides the OS specific ones
 }
 
 // GetOSConfig returns the appropriate OS configuration for the current platform
@@ -385,6 +525,15 @@ func (app *CrossPlatformApp) GetOSConfig() OSConfig {
 	}
 }
 
+// GetVersionConstraint returns the version constraint for the current platform.
+// An OS-specific version takes precedence over the app-wide one; empty means any version.
+func (app *CrossPlatformApp) GetVersionConstraint() string {
+	if version := app.GetOSConfig().Version; version != "" {
+		return version
+	}
+	return app.Version
+}
+
 // IsSupported checks if the app is supported on the current platform
 func (app *CrossPlatformApp) IsSupported() bool {
 	config := app.GetOSConfig()
@@ -415,6 +564,20 @@ func (app *CrossPlatformApp) IsCompatibleWithDesktopEnvironment(desktopEnv strin
 	return false
 }
 
+// currentPlatform caches the detected platform of this machine
+var (
+	currentPlatformOnce sync.Once
+	currentPlatform     platform.DetectionResult
+)
+
+// CurrentPlatform returns the detected platform of this machine
+func CurrentPlatform() platform.DetectionResult {
+	currentPlatformOnce.Do(func() {
+		currentPlatform = platform.DetectPlatform()
+	})
+	return currentPlatform
+}
+
 // IsPlatformSupported checks if the current platform meets the requirements
 func (config *OSConfig) IsPlatformSupported() bool {
 	// If no platform requirements specified, assume supported
@@ -444,6 +607,84 @@ func (config *OSConfig) IsPlatformSupported() bool {
 	return false
 }
 
+// IsPlatformSupportedOn checks if the target platform meets the requirements.
+// A requirement's os matches either the target OS or its distribution ID; version "8+"
+// means 8 or newer and any other version must match exactly or as a dotted prefix.
+func (config *OSConfig) IsPlatformSupportedOn(target platform.DetectionResult) bool {
+	// If no platform requirements specified, assume supported
+	if len(config.PlatformRequirements) == 0 {
+		return true
+	}
+
+	for _, req := range config.PlatformRequirements {
+		if req.matches(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether a single requirement is satisfied by the target platform
+func (req PlatformRequirement) matches(target platform.DetectionResult) bool {
+	knownDistro := target.Distribution != "" && target.Distribution != "unknown"
+
+	switch {
+	case req.OS == target.OS:
+	case knownDistro && req.OS == target.Distribution:
+	case !knownDistro && target.OS == "linux" && isLinuxDistribution(req.OS):
+		// Without distribution information any Linux requirement is accepted
+		return true
+	default:
+		return false
+	}
+
+	if req.Arch != "" && target.Architecture != "" && req.Arch != target.Architecture {
+		return false
+	}
+	if req.Version != "" && target.Version != "" && target.Version != "unknown" {
+		return versionSatisfies(target.Version, req.Version)
+	}
+	return true
+}
+
+// isLinuxDistribution reports whether os names a Linux distribution rather than an operating system
+func isLinuxDistribution(os string) bool {
+	switch os {
+	case "linux", "darwin", "windows":
+		return false
+	}
+	return os != ""
+}
+
+// versionSatisfies checks a detected version against a requirement such as "22.04" or "8+"
+func versionSatisfies(version, requirement string) bool {
+	if minimum, ok := strings.CutSuffix(requirement, "+"); ok {
+		return compareDottedVersions(version, minimum) >= 0
+	}
+	return version == requirement || strings.HasPrefix(version, requirement+".")
+}
+
+// compareDottedVersions compares dot-separated numeric versions segment by segment
+func compareDottedVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
 // GetBestOSConfig returns the best matching OS configuration based on platform requirements
 func (app *CrossPlatformApp) GetBestOSConfig() OSConfig {
 	// First try the default OS config
@@ -463,6 +704,48 @@ func (app *CrossPlatformApp) GetBestOSConfig() OSConfig {
 	return defaultConfig
 }
 
+// GetBestOSConfigFor returns the best matching OS configuration for an explicitly given target
+// platform, as used when importing a snapshot. A configuration whose platform requirements name
+// the target is preferred over one without requirements, so a Fedora alternative wins over an
+// unconstrained APT default on Fedora.
+func (app *CrossPlatformApp) GetBestOSConfigFor(target platform.DetectionResult) OSConfig {
+	defaultConfig := app.GetOSConfig()
+	candidates := append([]OSConfig{defaultConfig}, defaultConfig.Alternatives...)
+
+	// Explicit requirement matches first, then unconstrained configurations
+	for _, candidate := range candidates {
+		if len(candidate.PlatformRequirements) > 0 && candidate.IsPlatformSupportedOn(target) {
+			return candidate
+		}
+	}
+	for _, candidate := range candidates {
+		if len(candidate.PlatformRequirements) == 0 {
+			return candidate
+		}
+	}
+
+	// Return default if no better match found
+	return defaultConfig
+}
+
+// WithOSConfig returns a copy of the app that installs with osConfig on the current platform
+func (app CrossPlatformApp) WithOSConfig(osConfig OSConfig) CrossPlatformApp {
+	if app.AllPlatforms.InstallMethod != "" {
+		app.AllPlatforms = osConfig
+		return app
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		app.Linux = osConfig
+	case "darwin":
+		app.MacOS = osConfig
+	case "windows":
+		app.Windows = osConfig
+	}
+	return app
+}
+
 // Validate checks if the CrossPlatformApp configuration is valid
 func (app *CrossPlatformApp) Validate() error {
 	if app.Name == "" {
@@ -494,6 +777,7 @@ func (app *CrossPlatformApp) ToLegacyAppConfig() AppConfig {
 		Default:            app.Default,
 		InstallMethod:      osConfig.InstallMethod,
 		InstallCommand:     osConfig.InstallCommand,
+		Version:            app.GetVersionConstraint(),
 		UninstallCommand:   osConfig.UninstallCommand,
 		Dependencies:       osConfig.Dependencies,
 		PreInstall:         osConfig.PreInstall,
@@ -502,7 +786,11 @@ func (app *CrossPlatformApp) ToLegacyAppConfig() AppConfig {
 		AptSources:         osConfig.AptSources,
 		CleanupFiles:       osConfig.CleanupFiles,
 		Conflicts:          osConfig.Conflicts,
+		DockerOptions:      osConfig.DockerOptions,
 		DownloadURL:        osConfig.DownloadURL,
+		SHA256:             osConfig.SHA256,
+		Signature:          osConfig.Signature,
+		SignatureKey:       osConfig.SignatureKey,
 		InstallDir:         osConfig.Destination,
 		SystemRequirements: osConfig.SystemRequirements,
 	}
@@ -0,0 +1,232 @@
+package undo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ChangeKind identifies a reversible change an operation made to the system
+type ChangeKind string
+
+const (
+	ChangePackageInstalled ChangeKind = "package-installed" // reverted by uninstalling the package
+	ChangePackageRemoved   ChangeKind = "package-removed"   // reverted by reinstalling the package
+	ChangeRepository       ChangeKind = "repository"        // reverted by removing the source list
+	ChangeKey              ChangeKind = "key"               // reverted by removing the signing key
+	ChangeFile             ChangeKind = "file"              // reverted by restoring the backup or removing the file
+)
+
+// Change is a single system change recorded while an operation ran
+type Change struct {
+	Kind   ChangeKind `json:"kind" yaml:"kind"`
+	App    string     `json:"app,omitempty" yaml:"app,omitempty"`
+	Method string     `json:"method,omitempty" yaml:"method,omitempty"` // Install method of a package
+	Target string     `json:"target" yaml:"target"`                     // Package names or file path
+	Backup string     `json:"backup,omitempty" yaml:"backup,omitempty"` // Copy of the file the change replaced
+}
+
+// Describe returns what reverting the change does
+func (c Change) Describe() string {
+	switch c.Kind {
+	case ChangePackageInstalled:
+		return fmt.Sprintf("Uninstall %s (%s)", c.Target, c.Method)
+	case ChangePackageRemoved:
+		return fmt.Sprintf("Reinstall %s (%s)", c.Target, c.Method)
+	case ChangeRepository:
+		return fmt.Sprintf("Remove package repository %s", c.Target)
+	case ChangeKey:
+		return fmt.Sprintf("Remove signing key %s", c.Target)
+	case ChangeFile:
+		if c.Backup != "" {
+			return fmt.Sprintf("Restore previous %s", c.Target)
+		}
+		return fmt.Sprintf("Remove %s", c.Target)
+	default:
+		return fmt.Sprintf("Unknown change %s of %s", c.Kind, c.Target)
+	}
+}
+
+// ChangeSet collects the changes of an operation as it runs. A nil ChangeSet records nothing,
+// so installers can record unconditionally.
+type ChangeSet struct {
+	mu        sync.Mutex
+	backupDir string
+	saved     int
+	changes   []Change
+}
+
+// NewChangeSet creates a change set keeping copies of replaced files in backupDir
+func NewChangeSet(backupDir string) *ChangeSet {
+	return &ChangeSet{backupDir: backupDir}
+}
+
+// Add records a change
+func (cs *ChangeSet) Add(change Change) {
+	if cs == nil {
+		return
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.changes = append(cs.changes, change)
+}
+
+// Changes returns the recorded changes in the order they were made
+func (cs *ChangeSet) Changes() []Change {
+	if cs == nil {
+		return nil
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return append([]Change(nil), cs.changes...)
+}
+
+// SaveFile records that path is about to be written or removed. An existing file or directory
+// is copied first so reverting restores it; otherwise reverting removes path.
+func (cs *ChangeSet) SaveFile(app, path string) error {
+	if cs == nil {
+		return nil
+	}
+
+	change := Change{Kind: ChangeFile, App: app, Target: path}
+	if _, err := os.Lstat(path); err == nil {
+		cs.mu.Lock()
+		cs.saved++
+		change.Backup = filepath.Join(cs.backupDir, fmt.Sprintf("%03d-%s", cs.saved, filepath.Base(path)))
+		cs.mu.Unlock()
+		if err := copyTree(path, change.Backup); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	cs.Add(change)
+	return nil
+}
+
+type changeSetKey struct{}
+
+// WithChangeSet returns a context that records system changes in cs
+func WithChangeSet(ctx context.Context, cs *ChangeSet) context.Context {
+	return context.WithValue(ctx, changeSetKey{}, cs)
+}
+
+// ChangeSetFrom returns the change set recorded in a context, or nil
+func ChangeSetFrom(ctx context.Context) *ChangeSet {
+	if ctx == nil {
+		return nil
+	}
+	cs, _ := ctx.Value(changeSetKey{}).(*ChangeSet)
+	return cs
+}
+
+// SystemReverter reverts the changes that need a package manager or root privileges
+type SystemReverter interface {
+	UninstallPackage(ctx context.Context, change Change) error
+	InstallPackage(ctx context.Context, change Change) error
+	RemoveSystemFile(ctx context.Context, path string) error
+}
+
+// RevertChanges reverts changes in reverse order and returns the changes that could not be
+// reverted together with the reason for each
+func RevertChanges(ctx context.Context, changes []Change, reverter SystemReverter) ([]Change, []error) {
+	var failed []Change
+	var errs []error
+	for i := len(changes) - 1; i >= 0; i-- {
+		if err := revertChange(ctx, changes[i], reverter); err != nil {
+			failed = append(failed, changes[i])
+			errs = append(errs, fmt.Errorf("%s: %w", changes[i].Describe(), err))
+		}
+	}
+	return failed, errs
+}
+
+// revertChange reverts a single change
+func revertChange(ctx context.Context, change Change, reverter SystemReverter) error {
+	switch change.Kind {
+	case ChangeFile:
+		if err := os.RemoveAll(change.Target); err != nil {
+			return err
+		}
+		if change.Backup == "" {
+			return nil
+		}
+		return copyTree(change.Backup, change.Target)
+	}
+
+	if reverter == nil {
+		return fmt.Errorf("no package manager available to revert the change")
+	}
+	switch change.Kind {
+	case ChangePackageInstalled:
+		return reverter.UninstallPackage(ctx, change)
+	case ChangePackageRemoved:
+		return reverter.InstallPackage(ctx, change)
+	case ChangeRepository, ChangeKey:
+		return reverter.RemoveSystemFile(ctx, change.Target)
+	default:
+		return fmt.Errorf("unknown change kind %q", change.Kind)
+	}
+}
+
+// copyTree copies a file, symlink or directory tree, keeping permissions
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case entry.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		default:
+			if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+				return err
+			}
+			return copyFile(path, target, info.Mode().Perm())
+		}
+	})
+}
+
+// copyFile copies a regular file
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,129 @@
+package undo_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/undo"
+)
+
+// recordingReverter records the system changes it is asked to revert
+type recordingReverter struct {
+	reverted []string
+	fail     bool
+}
+
+func (r *recordingReverter) UninstallPackage(_ context.Context, change undo.Change) error {
+	return r.record("uninstall " + change.Target)
+}
+
+func (r *recordingReverter) InstallPackage(_ context.Context, change undo.Change) error {
+	return r.record("install " + change.Target)
+}
+
+func (r *recordingReverter) RemoveSystemFile(_ context.Context, path string) error {
+	return r.record("remove " + path)
+}
+
+func (r *recordingReverter) record(action string) error {
+	if r.fail {
+		return errors.New("package manager failed")
+	}
+	r.reverted = append(r.reverted, action)
+	return nil
+}
+
+var _ = Describe("Change sets", func() {
+	var (
+		dir     string
+		changes *undo.ChangeSet
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		changes = undo.NewChangeSet(filepath.Join(dir, "backups"))
+	})
+
+	It("is carried by the context", func() {
+		ctx := undo.WithChangeSet(context.Background(), changes)
+		Expect(undo.ChangeSetFrom(ctx)).To(BeIdenticalTo(changes))
+		Expect(undo.ChangeSetFrom(context.Background())).To(BeNil())
+	})
+
+	It("records nothing when nil", func() {
+		var none *undo.ChangeSet
+		none.Add(undo.Change{Kind: undo.ChangeKey, Target: "/etc/apt/keyrings/docker.gpg"})
+		Expect(none.SaveFile("docker", filepath.Join(dir, "file"))).To(Succeed())
+		Expect(none.Changes()).To(BeEmpty())
+	})
+
+	It("restores a replaced file", func() {
+		path := filepath.Join(dir, "config.toml")
+		Expect(os.WriteFile(path, []byte("original"), 0640)).To(Succeed())
+
+		Expect(changes.SaveFile("alacritty", path)).To(Succeed())
+		Expect(os.WriteFile(path, []byte("theme"), 0640)).To(Succeed())
+
+		failed, errs := undo.RevertChanges(context.Background(), changes.Changes(), nil)
+		Expect(errs).To(BeEmpty())
+		Expect(failed).To(BeEmpty())
+		Expect(os.ReadFile(path)).To(Equal([]byte("original")))
+		info, err := os.Stat(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0640)))
+	})
+
+	It("restores a replaced directory", func() {
+		path := filepath.Join(dir, "nvim")
+		Expect(os.MkdirAll(filepath.Join(path, "lua"), 0750)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(path, "lua", "init.lua"), []byte("original"), 0600)).To(Succeed())
+
+		Expect(changes.SaveFile("neovim", path)).To(Succeed())
+		Expect(os.RemoveAll(path)).To(Succeed())
+
+		_, errs := undo.RevertChanges(context.Background(), changes.Changes(), nil)
+		Expect(errs).To(BeEmpty())
+		Expect(os.ReadFile(filepath.Join(path, "lua", "init.lua"))).To(Equal([]byte("original")))
+	})
+
+	It("removes a file that did not exist before", func() {
+		path := filepath.Join(dir, "new.conf")
+		Expect(changes.SaveFile("app", path)).To(Succeed())
+		Expect(os.WriteFile(path, []byte("new"), 0600)).To(Succeed())
+
+		_, errs := undo.RevertChanges(context.Background(), changes.Changes(), nil)
+		Expect(errs).To(BeEmpty())
+		Expect(path).NotTo(BeAnExistingFile())
+	})
+
+	It("reverts system changes in reverse order", func() {
+		changes.Add(undo.Change{Kind: undo.ChangeKey, Target: "/etc/apt/keyrings/docker.gpg"})
+		changes.Add(undo.Change{Kind: undo.ChangeRepository, Target: "/etc/apt/sources.list.d/docker.list"})
+		changes.Add(undo.Change{Kind: undo.ChangePackageInstalled, Method: "apt", Target: "docker-ce"})
+
+		reverter := &recordingReverter{}
+		_, errs := undo.RevertChanges(context.Background(), changes.Changes(), reverter)
+		Expect(errs).To(BeEmpty())
+		Expect(reverter.reverted).To(Equal([]string{
+			"uninstall docker-ce",
+			"remove /etc/apt/sources.list.d/docker.list",
+			"remove /etc/apt/keyrings/docker.gpg",
+		}))
+	})
+
+	It("reports the changes it could not revert", func() {
+		changes.Add(undo.Change{Kind: undo.ChangePackageRemoved, Method: "apt", Target: "git"})
+
+		failed, errs := undo.RevertChanges(context.Background(), changes.Changes(), &recordingReverter{fail: true})
+		Expect(failed).To(HaveLen(1))
+		Expect(errs).To(ConsistOf(MatchError(ContainSubstring("Reinstall git (apt): package manager failed"))))
+
+		failed, _ = undo.RevertChanges(context.Background(), changes.Changes(), nil)
+		Expect(failed).To(HaveLen(1))
+	})
+})
@@ -1,10 +1,12 @@
 package undo
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -26,6 +28,7 @@ type UndoManager struct {
 	historyFile    string
 	backupManager  *backup.BackupManager
 	versionManager *version.VersionManager
+	reverter       SystemReverter
 }
 
 // UndoOperation represents a single undoable operation
@@ -41,6 +44,7 @@ type UndoOperation struct {
 	CanUndo     bool                   `json:"can_undo" yaml:"can_undo"`                         // Whether this operation can be undone
 	UndoRisks   []string               `json:"undo_risks,omitempty" yaml:"undo_risks,omitempty"` // Potential risks of undoing
 	Metadata    map[string]interface{} `json:"metadata,omitempty" yaml:"metadata,omitempty"`     // Additional operation data
+	Changes     []Change               `json:"changes,omitempty" yaml:"changes,omitempty"`       // System changes, reverted in reverse order
 }
 
 // UndoHistory tracks all undoable operations
@@ -55,6 +59,7 @@ type UndoResult struct {
 	OperationID  string   `json:"operation_id" yaml:"operation_id"`
 	RestoredFrom string   `json:"restored_from" yaml:"restored_from"`                     // backup or version
 	NewBackupID  string   `json:"new_backup_id,omitempty" yaml:"new_backup_id,omitempty"` // Backup created before undo
+	Reverted     int      `json:"reverted,omitempty" yaml:"reverted,omitempty"`           // System changes reverted
 	Warnings     []string `json:"warnings,omitempty" yaml:"warnings,omitempty"`
 	Message      string   `json:"message" yaml:"message"`
 }
@@ -71,6 +76,17 @@ func NewUndoManager(baseDir string) *UndoManager {
 	}
 }
 
+// SetSystemReverter sets how package and root-owned changes are reverted. Without it, undoing an
+// operation only reverts configuration and files in the user's home.
+func (um *UndoManager) SetSystemReverter(reverter SystemReverter) {
+	um.reverter = reverter
+}
+
+// ChangeDir returns the directory keeping the files an operation replaced
+func (um *UndoManager) ChangeDir(operationID string) string {
+	return filepath.Join(um.baseDir, "undo", operationID)
+}
+
 // RecordOperation records a new undoable operation
 func (um *UndoManager) RecordOperation(operation, description, target string, metadata map[string]interface{}) (*UndoOperation, error) {
 	// Get current version info
@@ -141,6 +157,37 @@ func (um *UndoManager) UpdateOperation(operationID string) error {
 	return um.saveHistory(history)
 }
 
+// AttachChanges records the system changes an operation made so undoing it reverts them
+func (um *UndoManager) AttachChanges(operationID string, changes []Change) error {
+	history, err := um.getHistory()
+	if err != nil {
+		return fmt.Errorf("failed to get history: %w", err)
+	}
+
+	for _, op := range history.Operations {
+		if op.ID == operationID {
+			op.Changes = append(op.Changes, changes...)
+			return um.saveHistory(history)
+		}
+	}
+
+	return fmt.Errorf("operation not found: %s", operationID)
+}
+
+// PreviewUndo describes the steps undoing an operation takes, in the order they are taken
+func (um *UndoManager) PreviewUndo(operationID string) ([]string, error) {
+	op, err := um.GetOperationDetails(operationID)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := []string{fmt.Sprintf("Restore DevEx configuration from backup %s", op.BackupID)}
+	for i := len(op.Changes) - 1; i >= 0; i-- {
+		steps = append(steps, op.Changes[i].Describe())
+	}
+	return steps, nil
+}
+
 // GetUndoableOperations returns recent operations that can be undone
 func (um *UndoManager) GetUndoableOperations(limit int) ([]*UndoOperation, error) {
 	history, err := um.getHistory()
@@ -231,8 +278,25 @@ func (um *UndoManager) UndoOperation(operationID string, force bool) (*UndoResul
 		result.Message = fmt.Sprintf("Restored from backup %s", targetOp.BackupID)
 	}
 
-	// Mark operation as undone
-	targetOp.CanUndo = false
+	// Revert system changes, newest first. Changes that fail are reported and kept on the
+	// operation, in the order they were made, so undoing it again retries them.
+	if len(targetOp.Changes) > 0 {
+		failed, errs := RevertChanges(context.Background(), targetOp.Changes, um.reverter)
+		for _, err := range errs {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Failed to revert: %v", err))
+		}
+		result.Reverted = len(targetOp.Changes) - len(failed)
+		slices.Reverse(failed)
+		targetOp.Changes = failed
+		if len(failed) == 0 {
+			_ = os.RemoveAll(um.ChangeDir(targetOp.ID))
+		} else {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%d changes were not reverted, run 'devex undo %s' to retry them", len(failed), targetOp.ID))
+		}
+	}
+
+	// Mark operation as undone, unless system changes are left to retry
+	targetOp.CanUndo = len(targetOp.Changes) > 0
 	now := time.Now()
 	history.LastUndo = &now
 
@@ -319,6 +383,7 @@ func (um *UndoManager) CleanupOldOperations() error {
 
 	// Optionally clean up old backups
 	for _, op := range oldOps {
+		_ = os.RemoveAll(um.ChangeDir(op.ID))
 		if op.BackupID != "" {
 			if err := um.backupManager.DeleteBackup(op.BackupID); err != nil {
 				// Log warning but don't fail the cleanup
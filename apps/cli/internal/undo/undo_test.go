@@ -0,0 +1,88 @@
+package undo_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/apps/cli/internal/undo"
+)
+
+var _ = Describe("UndoManager", func() {
+	var (
+		baseDir     string
+		undoManager *undo.UndoManager
+	)
+
+	BeforeEach(func() {
+		baseDir = GinkgoT().TempDir()
+		Expect(os.MkdirAll(filepath.Join(baseDir, "config"), 0750)).To(Succeed())
+		undoManager = undo.NewUndoManager(baseDir)
+	})
+
+	It("previews and reverts the system changes of an operation", func() {
+		op, err := undoManager.RecordOperation("install", "Installed applications: neovim", "neovim", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		path := filepath.Join(baseDir, "init.lua")
+		changes := undo.NewChangeSet(undoManager.ChangeDir(op.ID))
+		Expect(changes.SaveFile("neovim", path)).To(Succeed())
+		Expect(os.WriteFile(path, []byte("config"), 0600)).To(Succeed())
+		changes.Add(undo.Change{Kind: undo.ChangePackageInstalled, App: "neovim", Method: "apt", Target: "neovim"})
+		Expect(undoManager.AttachChanges(op.ID, changes.Changes())).To(Succeed())
+
+		steps, err := undoManager.PreviewUndo(op.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(steps).To(HaveLen(3))
+		Expect(steps[1]).To(Equal("Uninstall neovim (apt)"))
+		Expect(steps[2]).To(Equal("Remove " + path))
+
+		reverter := &recordingReverter{}
+		undoManager.SetSystemReverter(reverter)
+		result, err := undoManager.UndoOperation(op.ID, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Reverted).To(Equal(2))
+		Expect(reverter.reverted).To(Equal([]string{"uninstall neovim"}))
+		Expect(path).NotTo(BeAnExistingFile())
+	})
+
+	It("keeps changes that failed to revert so undo can retry them", func() {
+		op, err := undoManager.RecordOperation("install", "Installed applications: docker", "docker", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		path := filepath.Join(baseDir, "daemon.json")
+		changes := undo.NewChangeSet(undoManager.ChangeDir(op.ID))
+		changes.Add(undo.Change{Kind: undo.ChangeKey, Target: "/etc/apt/keyrings/docker.gpg"})
+		Expect(changes.SaveFile("docker", path)).To(Succeed())
+		Expect(os.WriteFile(path, []byte("{}"), 0600)).To(Succeed())
+		changes.Add(undo.Change{Kind: undo.ChangePackageInstalled, App: "docker", Method: "apt", Target: "docker-ce"})
+		Expect(undoManager.AttachChanges(op.ID, changes.Changes())).To(Succeed())
+
+		reverter := &recordingReverter{fail: true}
+		undoManager.SetSystemReverter(reverter)
+		result, err := undoManager.UndoOperation(op.ID, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Reverted).To(Equal(1))
+		Expect(result.Warnings).To(ContainElement(ContainSubstring("devex undo " + op.ID)))
+
+		details, err := undoManager.GetOperationDetails(op.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(details.CanUndo).To(BeTrue())
+		Expect(details.Changes).To(HaveLen(2))
+		Expect(details.Changes[0].Kind).To(Equal(undo.ChangeKey))
+		Expect(details.Changes[1].Kind).To(Equal(undo.ChangePackageInstalled))
+
+		reverter.fail = false
+		result, err = undoManager.UndoOperation(op.ID, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Reverted).To(Equal(2))
+		Expect(reverter.reverted).To(Equal([]string{"uninstall docker-ce", "remove /etc/apt/keyrings/docker.gpg"}))
+
+		details, err = undoManager.GetOperationDetails(op.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(details.CanUndo).To(BeFalse())
+		Expect(details.Changes).To(BeEmpty())
+	})
+})
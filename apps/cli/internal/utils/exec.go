@@ -4,6 +4,8 @@ import (
 	"context"
 	"os/exec"
 	"time"
+
+	"github.com/jameswlane/devex/apps/cli/internal/audit"
 )
 
 type OSCommandExecutor struct{}
@@ -16,13 +18,13 @@ func (OSCommandExecutor) RunShellCommand(command string) (string, error) {
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "bash", "-c", command)
-	output, err := cmd.CombinedOutput()
+	output, err := audit.CombinedOutput(ctx, cmd)
 	return string(output), err
 }
 
 func (OSCommandExecutor) RunCommand(ctx context.Context, name string, args ...string) (string, error) {
 	cmd := exec.CommandContext(ctx, name, args...)
-	output, err := cmd.CombinedOutput()
+	output, err := audit.CombinedOutput(ctx, cmd)
 	return string(output), err
 }
 
@@ -0,0 +1,6 @@
+-- Migration rollback: Remove installation session journal tables
+
+DROP INDEX IF EXISTS idx_install_sessions_status;
+
+DROP TABLE IF EXISTS install_session_apps;
+DROP TABLE IF EXISTS install_sessions;
@@ -0,0 +1,23 @@
+-- Migration: Add installation session journal tables
+-- Sessions record the planned apps of an install or setup run and how far each app got,
+-- so an interrupted run can be resumed with `devex install --resume`.
+
+CREATE TABLE IF NOT EXISTS install_sessions (
+    id TEXT PRIMARY KEY,
+    status TEXT NOT NULL, -- 'running', 'completed', 'failed', 'interrupted', 'abandoned'
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS install_session_apps (
+    session_id TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    app_name TEXT NOT NULL,
+    status TEXT NOT NULL, -- 'pending', 'running', 'completed', 'failed', 'skipped'
+    step TEXT NOT NULL DEFAULT '', -- last installation step that finished successfully
+    error TEXT NOT NULL DEFAULT '',
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (session_id, app_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_install_sessions_status ON install_sessions(status);
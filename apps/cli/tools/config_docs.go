@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jameswlane/devex/apps/cli/internal/configschema"
+)
+
+// configDocsOutput is the generated file, relative to the module root
+const configDocsOutput = "internal/configschema/docs_gen.go"
+
+// configDocs holds the comments and enum values of the configuration types
+type configDocs struct {
+	types  map[string]string
+	fields map[string]string
+	enums  map[string][]string // Go literals of the constants of each type
+}
+
+// GenerateConfigDocs extracts the doc comments and enum constants of the types reachable
+// from the configuration schemas, so the schemas can describe them at runtime.
+func GenerateConfigDocs() error {
+	root, err := moduleRoot()
+	if err != nil {
+		return err
+	}
+
+	reachable := map[string]reflect.Type{}
+	for _, kind := range configschema.Kinds {
+		t, _ := configschema.RootType(kind)
+		collectTypes(t, reachable)
+	}
+
+	docs := &configDocs{types: map[string]string{}, fields: map[string]string{}, enums: map[string][]string{}}
+	dirs := map[string]bool{}
+	for _, t := range reachable {
+		dirs[strings.TrimPrefix(t.PkgPath(), "github.com/jameswlane/devex/apps/cli/")] = true
+	}
+	for dir := range dirs {
+		if err := docs.parsePackage(filepath.Join(root, dir)); err != nil {
+			return err
+		}
+	}
+
+	source, err := docs.render(reachable)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(root, configDocsOutput), source, 0644)
+}
+
+// moduleRoot finds the directory of go.mod from the working directory, so the task runs
+// both from the module root and through go generate
+func moduleRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("go.mod not found")
+		}
+		dir = parent
+	}
+}
+
+// collectTypes records the named types of the module reachable from t, keyed like
+// "types.OSConfig"
+func collectTypes(t reflect.Type, seen map[string]reflect.Type) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if !strings.HasPrefix(t.PkgPath(), "github.com/jameswlane/devex/") {
+		return
+	}
+	key := typeKey(t)
+	if _, ok := seen[key]; ok {
+		return
+	}
+	seen[key] = t
+	if t.Kind() == reflect.Struct {
+		for i := 0; i < t.NumField(); i++ {
+			collectTypes(t.Field(i).Type, seen)
+		}
+	}
+}
+
+func typeKey(t reflect.Type) string {
+	return filepath.Base(t.PkgPath()) + "." + t.Name()
+}
+
+// parsePackage reads the comments and constants of the non-test files of a package
+func (d *configDocs) parsePackage(dir string) error {
+	fset := token.NewFileSet()
+	packages, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	for name, pkg := range packages {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok {
+					continue
+				}
+				switch gen.Tok {
+				case token.TYPE:
+					d.addTypes(name, gen)
+				case token.CONST:
+					d.addConstants(name, gen)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (d *configDocs) addTypes(pkg string, gen *ast.GenDecl) {
+	for _, spec := range gen.Specs {
+		typeSpec := spec.(*ast.TypeSpec)
+		key := pkg + "." + typeSpec.Name.Name
+		doc := typeSpec.Doc
+		if doc == nil && len(gen.Specs) == 1 {
+			doc = gen.Doc
+		}
+		if text := commentText(doc); text != "" {
+			d.types[key] = text
+		}
+
+		structType, ok := typeSpec.Type.(*ast.StructType)
+		if !ok {
+			continue
+		}
+		for _, field := range structType.Fields.List {
+			text := commentText(field.Doc)
+			if text == "" {
+				text = commentText(field.Comment)
+			}
+			if text == "" {
+				continue
+			}
+			for _, name := range field.Names {
+				d.fields[key+"."+name.Name] = text
+			}
+		}
+	}
+}
+
+// addConstants records typed constants as enum values, counting iota for blocks such as
+// SecurityLevel that only type the first constant
+func (d *configDocs) addConstants(pkg string, gen *ast.GenDecl) {
+	var typeName string
+	iota := -1
+	for i, spec := range gen.Specs {
+		valueSpec := spec.(*ast.ValueSpec)
+		if ident, ok := valueSpec.Type.(*ast.Ident); ok {
+			typeName = ident.Name
+			iota = -1
+		} else if len(valueSpec.Values) > 0 {
+			typeName = ""
+		}
+		if typeName == "" {
+			continue
+		}
+
+		var literal string
+		switch {
+		case len(valueSpec.Values) == 1:
+			switch value := valueSpec.Values[0].(type) {
+			case *ast.BasicLit:
+				literal = value.Value
+			case *ast.Ident:
+				if value.Name != "iota" {
+					continue
+				}
+				iota = i
+				literal = "0"
+			default:
+				continue
+			}
+		case iota >= 0:
+			literal = strconv.Itoa(i - iota)
+		default:
+			continue
+		}
+		key := pkg + "." + typeName
+		d.enums[key] = append(d.enums[key], literal)
+	}
+}
+
+// commentText joins the lines of a comment into a single line
+func commentText(group *ast.CommentGroup) string {
+	if group == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(group.Text()), " ")
+}
+
+// render writes the docs of the reachable types as Go source
+func (d *configDocs) render(reachable map[string]reflect.Type) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by \"go run ./tools config-docs\"; DO NOT EDIT.\n\n")
+	buf.WriteString("package configschema\n\n")
+
+	buf.WriteString("// typeDocs holds the doc comments of the configuration types\n")
+	buf.WriteString("var typeDocs = map[string]string{\n")
+	for _, key := range sortedKeys(d.types) {
+		if _, ok := reachable[key]; ok {
+			fmt.Fprintf(&buf, "%q: %q,\n", key, d.types[key])
+		}
+	}
+	buf.WriteString("}\n\n")
+
+	buf.WriteString("// fieldDocs holds the comments of the fields of the configuration types\n")
+	buf.WriteString("var fieldDocs = map[string]string{\n")
+	for _, key := range sortedKeys(d.fields) {
+		if _, ok := reachable[key[:strings.LastIndex(key, ".")]]; ok {
+			fmt.Fprintf(&buf, "%q: %q,\n", key, d.fields[key])
+		}
+	}
+	buf.WriteString("}\n\n")
+
+	buf.WriteString("// typeEnums holds the constants declared for the named types of the configuration\n")
+	buf.WriteString("var typeEnums = map[string][]any{\n")
+	for _, key := range sortedKeys(d.enums) {
+		if _, ok := reachable[key]; ok {
+			fmt.Fprintf(&buf, "%q: {%s},\n", key, strings.Join(d.enums[key], ", "))
+		}
+	}
+	buf.WriteString("}\n")
+
+	return format.Source(buf.Bytes())
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -7,7 +7,7 @@ import (
 
 func main() {
 	if len(os.Args) < 2 {
-		log.Fatalf("usage: go run main.go [task]\nAvailable tasks:\n  mise-registry")
+		log.Fatalf("usage: go run main.go [task]\nAvailable tasks:\n  mise-registry\n  config-docs")
 	}
 
 	switch os.Args[1] {
@@ -15,6 +15,10 @@ func main() {
 		if err := GenerateMiseRegistryYAML(); err != nil {
 			log.Fatal(err)
 		}
+	case "config-docs":
+		if err := GenerateConfigDocs(); err != nil {
+			log.Fatal(err)
+		}
 	default:
 		log.Fatalf("unknown task: %s", os.Args[1])
 	}
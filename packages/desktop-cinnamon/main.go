@@ -3,6 +3,7 @@ package main
 // Build timestamp: 2025-09-03 17:41:19
 
 import (
+	_ "embed"
 	"fmt"
 	"os"
 	"os/exec"
@@ -15,6 +16,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // CinnamonPlugin implements Cinnamon desktop environment configuration
 type CinnamonPlugin struct {
 	*sdk.BasePlugin
@@ -29,6 +35,7 @@ func NewCinnamonPlugin() *CinnamonPlugin {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"desktop", "cinnamon", "linux", "mint"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "configure",
@@ -65,6 +72,11 @@ func NewCinnamonPlugin() *CinnamonPlugin {
 				Description: "Restore Cinnamon settings from backup",
 				Usage:       "Restore Cinnamon configuration from a previous backup",
 			},
+			{
+				Name:        "options",
+				Description: "List installed themes as setup options",
+				Usage:       "Print installed themes as JSON options for setup questions (options themes|<kind>)",
+			},
 		},
 	}
 
@@ -75,6 +87,11 @@ func NewCinnamonPlugin() *CinnamonPlugin {
 
 // Execute handles command execution
 func (p *CinnamonPlugin) Execute(command string, args []string) error {
+	// Listing installed themes works without a running session, so setup can ask about them
+	if command == "options" {
+		return sdk.PrintThemeOptions(args, nil, sdk.ThemeGTK, sdk.ThemeCinnamon, sdk.ThemeIcons, sdk.ThemeCursors)
+	}
+
 	// Check if Cinnamon is available
 	if !isCinnamonAvailable() {
 		return fmt.Errorf("cinnamon desktop environment is not available on this system")
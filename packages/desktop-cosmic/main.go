@@ -3,6 +3,7 @@ package main
 // Build timestamp: 2025-09-03 17:41:19
 
 import (
+	_ "embed"
 	"fmt"
 	"os"
 	"os/exec"
@@ -15,6 +16,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // CosmicPlugin implements COSMIC desktop environment configuration
 type CosmicPlugin struct {
 	*sdk.BasePlugin
@@ -29,6 +35,7 @@ func NewCosmicPlugin() *CosmicPlugin {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"desktop", "cosmic", "linux", "system76", "rust"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "configure",
@@ -65,6 +72,11 @@ func NewCosmicPlugin() *CosmicPlugin {
 				Description: "Restore COSMIC settings from backup",
 				Usage:       "Restore COSMIC configuration from a previous backup",
 			},
+			{
+				Name:        "options",
+				Description: "List installed themes as setup options",
+				Usage:       "Print installed themes as JSON options for setup questions (options themes|<kind>)",
+			},
 		},
 	}
 
@@ -75,6 +87,11 @@ func NewCosmicPlugin() *CosmicPlugin {
 
 // Execute handles command execution
 func (p *CosmicPlugin) Execute(command string, args []string) error {
+	// Listing installed themes works without a running session, so setup can ask about them
+	if command == "options" {
+		return sdk.PrintThemeOptions(args, nil, sdk.ThemeGTK, sdk.ThemeIcons, sdk.ThemeCursors)
+	}
+
 	// Check if COSMIC is available
 	if !isCosmicAvailable() {
 		return fmt.Errorf("COSMIC desktop environment is not available on this system")
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	_ "embed"
 	"fmt"
 	"os"
 	"os/exec"
@@ -12,6 +13,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // GNOMEPlugin implements GNOME desktop environment configuration
 type GNOMEPlugin struct {
 	*sdk.BasePlugin
@@ -31,6 +37,7 @@ func NewGNOMEPlugin() *GNOMEPlugin {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"desktop", "gnome", "linux", "gtk"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "configure",
@@ -87,6 +94,11 @@ func NewGNOMEPlugin() *GNOMEPlugin {
 				Description: "List available backups",
 				Usage:       "List all available GNOME configuration backups",
 			},
+			{
+				Name:        "options",
+				Description: "List installed themes as setup options",
+				Usage:       "Print installed themes as JSON options for setup questions (options themes|<kind>)",
+			},
 		},
 	}
 
@@ -102,6 +114,11 @@ func NewGNOMEPlugin() *GNOMEPlugin {
 
 // Execute handles command execution
 func (p *GNOMEPlugin) Execute(command string, args []string) error {
+	// Listing installed themes works without a running session, so setup can ask about them
+	if command == "options" {
+		return sdk.PrintThemeOptions(args, currentTheme, sdk.ThemeGTK, sdk.ThemeShell, sdk.ThemeIcons, sdk.ThemeCursors)
+	}
+
 	// Check if GNOME is available
 	if !isGNOMEAvailable() {
 		return fmt.Errorf("GNOME desktop environment is not available on this system")
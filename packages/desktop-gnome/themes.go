@@ -7,6 +7,8 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
 )
 
 // ThemeManager handles GNOME theme operations
@@ -210,3 +212,22 @@ func (tm *ThemeManager) getCurrentGSetting(schema, key string) ([]byte, error) {
 	cmd := exec.Command("gsettings", "get", schema, key)
 	return cmd.Output()
 }
+
+// currentTheme returns the active theme of a kind so the options command can mark it as the default
+func currentTheme(kind string) string {
+	keys := map[string][2]string{
+		sdk.ThemeGTK:     {"org.gnome.desktop.interface", "gtk-theme"},
+		sdk.ThemeIcons:   {"org.gnome.desktop.interface", "icon-theme"},
+		sdk.ThemeCursors: {"org.gnome.desktop.interface", "cursor-theme"},
+		sdk.ThemeShell:   {"org.gnome.shell.extensions.user-theme", "name"},
+	}
+	key, ok := keys[kind]
+	if !ok || !sdk.CommandExists("gsettings") {
+		return ""
+	}
+	output, err := exec.Command("gsettings", "get", key[0], key[1]).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.Trim(strings.TrimSpace(string(output)), "'")
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	_ "embed"
 	"fmt"
 	"os"
 	"strings"
@@ -11,6 +12,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // KDEPlugin implements KDE Plasma desktop environment configuration
 type KDEPlugin struct {
 	*sdk.BasePlugin
@@ -30,6 +36,7 @@ func NewKDEPlugin() *KDEPlugin {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"desktop", "kde", "plasma", "linux", "qt"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "configure",
@@ -106,6 +113,11 @@ func NewKDEPlugin() *KDEPlugin {
 				Description: "List available backups",
 				Usage:       "List all available KDE configuration backups",
 			},
+			{
+				Name:        "options",
+				Description: "List installed themes as setup options",
+				Usage:       "Print installed themes as JSON options for setup questions (options themes|<kind>)",
+			},
 		},
 	}
 
@@ -121,6 +133,11 @@ func NewKDEPlugin() *KDEPlugin {
 
 // Execute handles command execution
 func (p *KDEPlugin) Execute(command string, args []string) error {
+	// Listing installed themes works without a running session, so setup can ask about them
+	if command == "options" {
+		return sdk.PrintThemeOptions(args, nil, sdk.ThemePlasma, sdk.ThemeLookAndFeel, sdk.ThemeIcons, sdk.ThemeCursors, sdk.ThemeKvantum)
+	}
+
 	// Check if KDE is available
 	if !isKDEAvailable() {
 		return fmt.Errorf("KDE Plasma desktop environment is not available on this system")
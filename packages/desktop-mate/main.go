@@ -3,6 +3,7 @@ package main
 // Build timestamp: 2025-09-03 17:41:19
 
 import (
+	_ "embed"
 	"fmt"
 	"os"
 	"os/exec"
@@ -15,6 +16,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // MATEPlugin implements MATE desktop environment configuration
 type MATEPlugin struct {
 	*sdk.BasePlugin
@@ -29,6 +35,7 @@ func NewMATEPlugin() *MATEPlugin {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"desktop", "mate", "linux", "gtk"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "configure",
@@ -65,6 +72,11 @@ func NewMATEPlugin() *MATEPlugin {
 				Description: "Restore MATE settings from backup",
 				Usage:       "Restore MATE configuration from a previous backup",
 			},
+			{
+				Name:        "options",
+				Description: "List installed themes as setup options",
+				Usage:       "Print installed themes as JSON options for setup questions (options themes|<kind>)",
+			},
 		},
 	}
 
@@ -75,6 +87,11 @@ func NewMATEPlugin() *MATEPlugin {
 
 // Execute handles command execution
 func (p *MATEPlugin) Execute(command string, args []string) error {
+	// Listing installed themes works without a running session, so setup can ask about them
+	if command == "options" {
+		return sdk.PrintThemeOptions(args, nil, sdk.ThemeGTK, sdk.ThemeMetacity, sdk.ThemeIcons, sdk.ThemeCursors)
+	}
+
 	// Check if MATE is available
 	if !isMATEAvailable() {
 		return fmt.Errorf("MATE desktop environment is not available on this system")
@@ -3,6 +3,7 @@ package main
 // Build timestamp: 2025-09-03 17:41:19
 
 import (
+	_ "embed"
 	"fmt"
 	"os"
 	"os/exec"
@@ -15,6 +16,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // PantheonPlugin implements Pantheon desktop environment configuration
 type PantheonPlugin struct {
 	*sdk.BasePlugin
@@ -29,6 +35,7 @@ func NewPantheonPlugin() *PantheonPlugin {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"desktop", "pantheon", "linux", "elementary"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "configure",
@@ -65,6 +72,11 @@ func NewPantheonPlugin() *PantheonPlugin {
 				Description: "Restore Pantheon settings from backup",
 				Usage:       "Restore Pantheon configuration from a previous backup",
 			},
+			{
+				Name:        "options",
+				Description: "List installed themes as setup options",
+				Usage:       "Print installed themes as JSON options for setup questions (options themes|<kind>)",
+			},
 		},
 	}
 
@@ -75,6 +87,11 @@ func NewPantheonPlugin() *PantheonPlugin {
 
 // Execute handles command execution
 func (p *PantheonPlugin) Execute(command string, args []string) error {
+	// Listing installed themes works without a running session, so setup can ask about them
+	if command == "options" {
+		return sdk.PrintThemeOptions(args, nil, sdk.ThemeGTK, sdk.ThemeIcons, sdk.ThemeCursors)
+	}
+
 	// Check if Pantheon is available
 	if !isPantheonAvailable() {
 		return fmt.Errorf("pantheon desktop environment is not available on this system")
@@ -4,6 +4,7 @@ package main
 
 import (
 	"context"
+	_ "embed"
 	"fmt"
 	"os"
 	"strings"
@@ -13,6 +14,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // APKPlugin implements the APK package manager
 type APKPlugin struct {
 	*sdk.PackageManagerPlugin
@@ -27,6 +33,7 @@ func NewAPKPlugin() *APKPlugin {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"package-manager", "apk", "alpine", "linux"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "install",
@@ -79,6 +86,21 @@ func NewAPKPlugin() *APKPlugin {
 				Description: "Show package information",
 				Usage:       "Display detailed information about a package",
 			},
+			{
+				Name:        "is-installed",
+				Description: "Check if packages are installed",
+				Usage:       "Exits 0 if all packages are installed, 1 if any is missing and 2 if the check fails",
+			},
+			{
+				Name:        "resolve",
+				Description: "Resolve a version constraint",
+				Usage:       "Print the newest available version matching --constraint as JSON, with the install spec that pins it",
+			},
+			{
+				Name:        "version",
+				Description: "Show installed package versions",
+				Usage:       "Print the installed version and repository of each package as JSON",
+			},
 		},
 	}
 
@@ -104,6 +126,12 @@ func (p *APKPlugin) Execute(command string, args []string) error {
 		return p.handleSearch(ctx, args)
 	case "list":
 		return p.handleList(ctx, args)
+	case "is-installed":
+		return p.handleIsInstalled(ctx, args)
+	case "resolve":
+		return p.ResolveVersions(ctx, args, p.availableVersions)
+	case "version":
+		return p.ReportVersions(ctx, args, p.installedVersion)
 	case "info":
 		return p.handleInfo(ctx, args)
 	default:
@@ -197,6 +225,85 @@ func (p *APKPlugin) handleInfo(ctx context.Context, args []string) error {
 	return nil
 }
 
+func (p *APKPlugin) handleIsInstalled(ctx context.Context, args []string) error {
+	return p.CheckInstalled(ctx, args, p.isPackageInstalled)
+}
+
+// isPackageInstalled queries the local package database with `apk info -e`
+func (p *APKPlugin) isPackageInstalled(ctx context.Context, pkg string) (bool, error) {
+	return sdk.CheckCommandWithContext(ctx, "apk", "info", "-e", pkg)
+}
+
+// availableVersions lists the versions offered by the configured repositories using `apk policy`
+func (p *APKPlugin) availableVersions(ctx context.Context, pkg string) ([]sdk.PackageInfo, error) {
+	output, err := sdk.ExecCommandOutputWithContext(ctx, "apk", "policy", pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []sdk.PackageInfo
+	for _, entry := range parsePolicy(output) {
+		if entry.source == "" {
+			continue // only installed locally, not available from a repository
+		}
+		versions = append(versions, sdk.PackageInfo{
+			Name:    pkg,
+			Version: entry.version,
+			Source:  entry.source,
+			Spec:    pkg + "=" + entry.version,
+		})
+	}
+	return versions, nil
+}
+
+// installedVersion reports the installed version of a package and the repository that provides it
+func (p *APKPlugin) installedVersion(ctx context.Context, pkg string) (sdk.PackageInfo, error) {
+	output, err := sdk.ExecCommandOutputWithContext(ctx, "apk", "policy", pkg)
+	if err != nil {
+		return sdk.PackageInfo{}, err
+	}
+
+	for _, entry := range parsePolicy(output) {
+		if entry.installed {
+			return sdk.PackageInfo{Name: pkg, Version: entry.version, Source: entry.source}, nil
+		}
+	}
+	return sdk.PackageInfo{}, sdk.ErrNotInstalled
+}
+
+// policyEntry is one version listed by `apk policy`
+type policyEntry struct {
+	version   string
+	source    string
+	installed bool
+}
+
+// parsePolicy parses `apk policy` output:
+//
+//	git policy:
+//	  2.43.0-r0:
+//	    lib/apk/db/installed
+//	    https://dl-cdn.alpinelinux.org/alpine/v3.19/main
+func parsePolicy(output string) []policyEntry {
+	var entries []policyEntry
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || strings.HasSuffix(trimmed, " policy:"):
+		case strings.HasSuffix(trimmed, ":") && !strings.Contains(trimmed, "/"):
+			entries = append(entries, policyEntry{version: strings.TrimSuffix(trimmed, ":")})
+		case len(entries) > 0:
+			entry := &entries[len(entries)-1]
+			if trimmed == "lib/apk/db/installed" {
+				entry.installed = true
+			} else if entry.source == "" {
+				entry.source = trimmed
+			}
+		}
+	}
+	return entries
+}
+
 func main() {
 	plugin := NewAPKPlugin()
 	sdk.HandleArgs(plugin, os.Args[1:])
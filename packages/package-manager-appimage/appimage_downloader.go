@@ -9,13 +9,21 @@ import (
 	"time"
 )
 
-// installAppImage downloads and installs an AppImage
-func (p *AppimagePlugin) installAppImage(downloadURL, binaryName, installLocation string) error {
+// installOptions are the install settings passed on the command line
+type installOptions struct {
+	URL          string
+	File         string // local copy of the download, installed instead of downloading URL
+	Location     string // gui or cli
+	Verification downloadVerification
+}
+
+// installAppImage downloads, verifies and installs an AppImage, recording where it came from
+func (p *AppimagePlugin) installAppImage(binaryName string, opts installOptions) error {
 	homeDir := os.Getenv("HOME")
 	var installDir string
 
 	// Determine installation directory
-	switch installLocation {
+	switch opts.Location {
 	case "gui":
 		installDir = filepath.Join(homeDir, "Applications")
 	case "cli":
@@ -31,31 +39,97 @@ func (p *AppimagePlugin) installAppImage(downloadURL, binaryName, installLocatio
 
 	binaryPath := filepath.Join(installDir, binaryName)
 
-	p.logger.Printf("Downloading AppImage to: %s\n", binaryPath)
+	// Download next to the destination and verify before the file becomes executable
+	var tmpPath, version string
+	if opts.File != "" {
+		p.logger.Printf("Copying AppImage from %s to: %s\n", opts.File, binaryPath)
+		copied, err := copyToTemp(opts.File, installDir, "."+binaryName+".download-*")
+		if err != nil {
+			return fmt.Errorf("failed to copy AppImage: %w", err)
+		}
+		tmpPath = copied
+	} else {
+		source, err := p.resolveSource(opts.URL)
+		if err != nil {
+			return err
+		}
+		p.logger.Printf("Downloading AppImage to: %s\n", binaryPath)
+		downloaded, etag, err := p.downloadToTemp(source.URL, installDir, binaryName)
+		if err != nil {
+			return fmt.Errorf("failed to download AppImage: %w", err)
+		}
+		tmpPath = downloaded
+		version = source.Version
+		if version == "" {
+			version = etag
+		}
+	}
+	defer func() { _ = os.Remove(tmpPath) }()
 
-	// Download the AppImage
-	if err := p.downloadFile(downloadURL, binaryPath); err != nil {
-		return fmt.Errorf("failed to download AppImage: %w", err)
+	sum, err := p.verifyDownload(tmpPath, opts.Verification)
+	if err != nil {
+		return fmt.Errorf("verification failed: %w", err)
 	}
 
 	// Set executable permissions
-	if err := os.Chmod(binaryPath, 0o755); err != nil {
+	if err := os.Chmod(tmpPath, 0o755); err != nil {
 		return fmt.Errorf("failed to set permissions on AppImage: %w", err)
 	}
+	if err := os.Rename(tmpPath, binaryPath); err != nil {
+		return fmt.Errorf("failed to install AppImage: %w", err)
+	}
 
 	// Create desktop entry for GUI apps
-	if installLocation == "gui" {
+	if opts.Location == "gui" {
 		if err := p.createDesktopEntry(binaryName, binaryPath); err != nil {
 			p.logger.Warning("Failed to create desktop entry: %v", err)
 			// Non-fatal error
 		}
 	}
 
+	record := &installRecord{
+		Name:         binaryName,
+		Path:         binaryPath,
+		Location:     opts.Location,
+		URL:          opts.URL,
+		SHA256:       sum,
+		PinnedSHA256: opts.Verification.SHA256,
+		Signature:    opts.Verification.Signature,
+		SignatureKey: opts.Verification.SignatureKey,
+		UpdateInfo:   readUpdateInfo(binaryPath),
+		Version:      version,
+		InstalledAt:  time.Now().UTC(),
+	}
+	if err := saveRecord(record); err != nil {
+		p.logger.Warning("Failed to record installation, updates will not be available: %v", err)
+	}
+
 	return nil
 }
 
-// downloadFile downloads a file from URL to local path
-func (p *AppimagePlugin) downloadFile(url, filepath string) error {
+// downloadToTemp downloads a URL to a new temporary file in dir and returns its path and the
+// entity tag the server sent with it
+func (p *AppimagePlugin) downloadToTemp(url, dir, binaryName string) (string, string, error) {
+	out, err := os.CreateTemp(dir, "."+binaryName+".download-*")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create file: %w", err)
+	}
+
+	etag, err := p.downloadFile(url, out)
+	if err != nil {
+		_ = out.Close()
+		_ = os.Remove(out.Name())
+		return "", "", err
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(out.Name())
+		return "", "", fmt.Errorf("failed to write file: %w", err)
+	}
+	return out.Name(), etag, nil
+}
+
+// downloadFile downloads a file from URL into out and returns its entity tag
+func (p *AppimagePlugin) downloadFile(url string, out io.Writer) (string, error) {
 	// Create HTTP client with timeout
 	client := &http.Client{
 		Timeout: 5 * time.Minute, // 5 minute timeout for large AppImages
@@ -63,26 +137,19 @@ func (p *AppimagePlugin) downloadFile(url, filepath string) error {
 
 	resp, err := client.Get(url)
 	if err != nil {
-		return fmt.Errorf("failed to download file: %w", err)
+		return "", fmt.Errorf("failed to download file: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("bad status: %s", resp.Status)
-	}
-
-	out, err := os.Create(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+		return "", fmt.Errorf("bad status: %s", resp.Status)
 	}
-	defer func() { _ = out.Close() }()
 
-	_, err = io.Copy(out, resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
 	}
 
-	return nil
+	return resp.Header.Get("ETag"), nil
 }
 
 // validateURLAccessibility checks if a URL is accessible
@@ -3,6 +3,7 @@ package main
 // Build timestamp: 2025-09-06
 
 import (
+	_ "embed"
 	"os"
 
 	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
@@ -10,6 +11,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // NewAppimagePlugin creates a new AppImage plugin
 func NewAppimagePlugin() *AppimagePlugin {
 	info := sdk.PluginInfo{
@@ -19,16 +25,43 @@ func NewAppimagePlugin() *AppimagePlugin {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"package-manager", "appimage", "linux", "portable", "gui"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "install",
 				Description: "Install AppImage applications",
 				Usage:       "Download and install AppImage with format: '<download_url> <binary_name>'",
 				Flags: map[string]string{
-					"gui": "Install to ~/Applications for GUI apps (default)",
-					"cli": "Install to ~/.local/bin for CLI tools",
+					"gui":           "Install to ~/Applications for GUI apps (default)",
+					"cli":           "Install to ~/.local/bin for CLI tools",
+					"url":           "Download URL, instead of the first argument",
+					"sha256":        "Expected SHA-256 checksum of the download",
+					"signature":     "URL of a detached OpenPGP signature of the download",
+					"signature-key": "URL or path of the public key that verifies the signature",
+					"file":          "Install a local copy of the download, e.g. from an offline bundle",
+				},
+			},
+			{
+				Name:        "download",
+				Description: "Download AppImages for offline installation",
+				Usage:       "Download and verify an AppImage into --dest as <binary_name>.AppImage with the install arguments",
+				Flags: map[string]string{
+					"dest": "Directory to download the AppImage to",
 				},
 			},
+			{
+				Name:        "update",
+				Description: "Update installed AppImages",
+				Usage:       "Update AppImages from their embedded update information or download URL, keeping the previous version",
+				Flags: map[string]string{
+					"check": "Only report which AppImages have updates",
+				},
+			},
+			{
+				Name:        "rollback",
+				Description: "Restore the previous version of AppImages",
+				Usage:       "Swap AppImages back to the version replaced by their last update",
+			},
 			{
 				Name:        "remove",
 				Description: "Remove AppImage applications",
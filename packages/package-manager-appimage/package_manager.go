@@ -34,37 +34,28 @@ func (p *AppimagePlugin) Execute(command string, args []string) error {
 		return p.handleRemove(args)
 	case "list":
 		return p.handleList(args)
+	case "update":
+		return p.handleUpdate(args)
+	case "rollback":
+		return p.handleRollback(args)
+	case "download":
+		return p.handleDownload(args)
 	default:
 		return fmt.Errorf("unknown command: %s", command)
 	}
 }
 
-// handleInstall installs AppImage applications
+// handleInstall installs AppImage applications. The download URL is either the first argument
+// or the --url option; --sha256 and --signature with --signature-key are verified before the
+// AppImage is made executable. --file installs a local copy of the download instead, e.g. one
+// taken from an offline bundle, and keeps --url as its update source.
 func (p *AppimagePlugin) handleInstall(args []string) error {
-	if len(args) < 2 {
-		return fmt.Errorf("usage: <download_url> <binary_name> [flags]")
-	}
-
-	downloadURL := args[0]
-	binaryName := args[1]
-
-	// Validate parameters first
-	if err := p.validateAppImageParameters(downloadURL, binaryName); err != nil {
-		return fmt.Errorf("parameter validation failed: %w", err)
-	}
-
-	// Parse flags
-	installLocation := "gui" // default to GUI apps
-	for i := 2; i < len(args); i++ {
-		switch args[i] {
-		case "--gui":
-			installLocation = "gui"
-		case "--cli":
-			installLocation = "cli"
-		}
+	opts, binaryName, err := p.parseInstallArgs(args)
+	if err != nil {
+		return err
 	}
 
-	p.logger.Printf("Installing AppImage: %s as %s\n", downloadURL, binaryName)
+	p.logger.Printf("Installing AppImage: %s as %s\n", opts.URL, binaryName)
 
 	// Check if already installed
 	if installed, err := p.isAppImageInstalled(binaryName); err != nil {
@@ -74,13 +65,15 @@ func (p *AppimagePlugin) handleInstall(args []string) error {
 		return nil
 	}
 
-	// Validate URL accessibility
-	if err := p.validateURLAccessibility(downloadURL); err != nil {
-		return fmt.Errorf("URL validation failed: %w", err)
+	// Validate URL accessibility, unless the AppImage is installed from a local copy
+	if opts.File == "" {
+		if err := p.validateURLAccessibility(opts.URL); err != nil {
+			return fmt.Errorf("URL validation failed: %w", err)
+		}
 	}
 
 	// Install the AppImage
-	if err := p.installAppImage(downloadURL, binaryName, installLocation); err != nil {
+	if err := p.installAppImage(binaryName, opts); err != nil {
 		return fmt.Errorf("failed to install AppImage: %w", err)
 	}
 
@@ -88,6 +81,92 @@ func (p *AppimagePlugin) handleInstall(args []string) error {
 	return nil
 }
 
+// handleDownload downloads and verifies an AppImage into --dest as <binary_name>.AppImage without
+// installing it, for installation on a machine without network access with install --file
+func (p *AppimagePlugin) handleDownload(args []string) error {
+	dest, rest, err := sdk.ParseDownloadArgs(args)
+	if err != nil {
+		return err
+	}
+	opts, binaryName, err := p.parseInstallArgs(rest)
+	if err != nil {
+		return err
+	}
+
+	downloadURL, err := p.resolveDownloadURL(opts.URL)
+	if err != nil {
+		return err
+	}
+	tmpPath, _, err := p.downloadToTemp(downloadURL, dest, binaryName)
+	if err != nil {
+		return fmt.Errorf("failed to download AppImage: %w", err)
+	}
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	sum, err := p.verifyDownload(tmpPath, opts.Verification)
+	if err != nil {
+		return fmt.Errorf("verification failed: %w", err)
+	}
+	target := filepath.Join(dest, binaryName+".AppImage")
+	if err := os.Rename(tmpPath, target); err != nil {
+		return fmt.Errorf("failed to save AppImage: %w", err)
+	}
+
+	p.logger.Success("Downloaded %s (sha256 %s)", target, sum)
+	return nil
+}
+
+// parseInstallArgs parses and validates the arguments of the install and download commands
+func (p *AppimagePlugin) parseInstallArgs(args []string) (installOptions, string, error) {
+	opts := installOptions{Location: "gui"} // default to GUI apps
+	var positional []string
+	for _, arg := range args {
+		name, value, _ := strings.Cut(arg, "=")
+		switch name {
+		case "--gui":
+			opts.Location = "gui"
+		case "--cli":
+			opts.Location = "cli"
+		case "--url":
+			opts.URL = value
+		case "--file":
+			opts.File = value
+		case "--sha256":
+			opts.Verification.SHA256 = value
+		case "--signature":
+			opts.Verification.Signature = value
+		case "--signature-key":
+			opts.Verification.SignatureKey = value
+		default:
+			if strings.HasPrefix(arg, "--") {
+				return opts, "", fmt.Errorf("unknown install option: %s", name)
+			}
+			positional = append(positional, arg)
+		}
+	}
+	if opts.URL == "" && len(positional) > 0 {
+		opts.URL, positional = positional[0], positional[1:]
+	}
+	if opts.URL == "" || len(positional) != 1 {
+		return opts, "", fmt.Errorf("usage: <download_url> <binary_name> [flags]")
+	}
+	binaryName := positional[0]
+
+	// Validate parameters first
+	if err := p.validateAppImageParameters(opts.URL, binaryName); err != nil {
+		return opts, "", fmt.Errorf("parameter validation failed: %w", err)
+	}
+	if err := p.validateVerification(opts.Verification); err != nil {
+		return opts, "", fmt.Errorf("parameter validation failed: %w", err)
+	}
+	if opts.File != "" {
+		if info, err := os.Stat(opts.File); err != nil || !info.Mode().IsRegular() {
+			return opts, "", fmt.Errorf("parameter validation failed: %s is not a file", opts.File)
+		}
+	}
+	return opts, binaryName, nil
+}
+
 // handleRemove removes AppImage applications
 func (p *AppimagePlugin) handleRemove(args []string) error {
 	if len(args) == 0 {
@@ -144,6 +223,11 @@ func (p *AppimagePlugin) handleRemove(args []string) error {
 			}
 		}
 
+		// Remove the install record and the version kept for rollback
+		if err := removeRecord(binaryName); err != nil {
+			p.logger.Warning("Failed to remove install record: %v", err)
+		}
+
 		if removed {
 			p.logger.Success("AppImage %s removed successfully", binaryName)
 		} else {
@@ -191,17 +275,16 @@ func (p *AppimagePlugin) handleIsInstalled(args []string) error {
 		return fmt.Errorf("invalid binary name: %w", err)
 	}
 
-	if installed, err := p.isAppImageInstalled(binaryName); err != nil {
-		p.logger.Printf("Error checking if AppImage %s is installed: %v\n", binaryName, err)
-		os.Exit(1)
-	} else if installed {
-		p.logger.Printf("AppImage %s is installed\n", binaryName)
-		os.Exit(0)
-	} else {
+	installed, err := p.isAppImageInstalled(binaryName)
+	if err != nil {
+		return fmt.Errorf("failed to check if AppImage %s is installed: %w", binaryName, err)
+	}
+	if !installed {
 		p.logger.Printf("AppImage %s is not installed\n", binaryName)
-		os.Exit(1)
+		return &sdk.NotInstalledError{Packages: []string{binaryName}}
 	}
 
+	p.logger.Printf("AppImage %s is installed\n", binaryName)
 	return nil
 }
 
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// installRecord remembers where an AppImage came from so it can be updated and rolled back
+type installRecord struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Location string `json:"location"` // gui or cli
+	URL      string `json:"url"`      // download URL as configured, possibly a "latest" URL
+
+	SHA256       string `json:"sha256"`                  // checksum of the installed file
+	PinnedSHA256 string `json:"pinned_sha256,omitempty"` // checksum the app configuration requires
+	Signature    string `json:"signature,omitempty"`
+	SignatureKey string `json:"signature_key,omitempty"`
+
+	// UpdateInfo is the update information embedded in the AppImage, e.g. "zsync|<url>"
+	UpdateInfo string `json:"update_info,omitempty"`
+	// Version is the release tag or HTTP entity tag of the installed download, which tells
+	// whether a newer file is published when there is no zsync checksum
+	Version string `json:"version,omitempty"`
+
+	PreviousSHA256 string    `json:"previous_sha256,omitempty"` // checksum of the version kept for rollback
+	InstalledAt    time.Time `json:"installed_at"`
+	UpdatedAt      time.Time `json:"updated_at,omitempty"`
+}
+
+// stateDir returns the directory holding install records and previous versions
+func stateDir() string {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(os.Getenv("HOME"), ".local", "share")
+	}
+	return filepath.Join(dataHome, "devex", "appimages")
+}
+
+// recordPath returns the install record of an AppImage
+func recordPath(binaryName string) string {
+	return filepath.Join(stateDir(), binaryName+".json")
+}
+
+// previousPath returns where the previous version of an AppImage is kept
+func previousPath(binaryName string) string {
+	return filepath.Join(stateDir(), binaryName+".previous")
+}
+
+// loadRecord reads the install record of an AppImage
+func loadRecord(binaryName string) (*installRecord, error) {
+	data, err := os.ReadFile(recordPath(binaryName))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("AppImage %s was not installed by devex, reinstall it to enable updates", binaryName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read install record: %w", err)
+	}
+
+	var record installRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("invalid install record for %s: %w", binaryName, err)
+	}
+	return &record, nil
+}
+
+// saveRecord writes the install record of an AppImage
+func saveRecord(record *installRecord) error {
+	if err := os.MkdirAll(stateDir(), 0o700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode install record: %w", err)
+	}
+	if err := os.WriteFile(recordPath(record.Name), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write install record: %w", err)
+	}
+	return nil
+}
+
+// removeRecord deletes the install record and previous version of an AppImage
+func removeRecord(binaryName string) error {
+	for _, path := range []string{recordPath(binaryName), previousPath(binaryName)} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// recordedAppImages returns the names of all AppImages with an install record
+func recordedAppImages() ([]string, error) {
+	entries, err := os.ReadDir(stateDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".json"); ok && !entry.IsDir() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// copyToTemp copies src to a new temporary file in dir, so it can be renamed into place
+func copyToTemp(src, dir, pattern string) (string, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		_ = os.Remove(out.Name())
+		return "", fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(out.Name())
+		return "", fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	return out.Name(), nil
+}
+
+// keepPrevious stores the installed file as the previous version of an AppImage
+func keepPrevious(record *installRecord) error {
+	if err := os.MkdirAll(stateDir(), 0o700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	tmp, err := copyToTemp(record.Path, stateDir(), "."+record.Name+".previous-*")
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, previousPath(record.Name)); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to keep previous version: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,491 @@
+package main
+
+import (
+	"bufio"
+	"debug/elf"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// githubReleasePage matches GitHub release page URLs, which are resolved to an AppImage asset
+var githubReleasePage = regexp.MustCompile(`^https://github\.com/([\w.-]+)/([\w.-]+)/releases/(latest|tag/([^/]+))/?$`)
+
+// githubAsset is a release asset as returned by the GitHub API
+type githubAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// githubRelease is a release as returned by the GitHub API
+type githubRelease struct {
+	TagName string        `json:"tag_name"`
+	Assets  []githubAsset `json:"assets"`
+}
+
+// updateSource is where the newest version of an AppImage is downloaded from
+type updateSource struct {
+	URL       string
+	SHA1      string // from zsync metadata, used to skip downloads when already up to date
+	Version   string // release tag of the file, compared with installRecord.Version when there is no SHA1
+	Signature string // detached signature published with the file in the same release
+}
+
+// readUpdateInfo returns the update information embedded in an AppImage's .upd_info section
+func readUpdateInfo(binaryPath string) string {
+	file, err := elf.Open(binaryPath)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = file.Close() }()
+
+	section := file.Section(".upd_info")
+	if section == nil {
+		return ""
+	}
+	data, err := section.Data()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(data), "\x00"))
+}
+
+// resolveDownloadURL turns a GitHub release page URL into the URL of its AppImage asset;
+// other URLs, including ".../releases/latest/download/<file>" redirects, are used as they are
+func (p *AppimagePlugin) resolveDownloadURL(downloadURL string) (string, error) {
+	source, err := p.resolveSource(downloadURL)
+	if err != nil {
+		return "", err
+	}
+	return source.URL, nil
+}
+
+// resolveSource resolves a download URL like resolveDownloadURL, together with the tag and
+// signature asset of the release when it is a GitHub release page
+func (p *AppimagePlugin) resolveSource(downloadURL string) (*updateSource, error) {
+	match := githubReleasePage.FindStringSubmatch(downloadURL)
+	if match == nil {
+		return &updateSource{URL: downloadURL}, nil
+	}
+
+	tag := "latest"
+	if match[4] != "" {
+		tag = match[4]
+	}
+	release, err := fetchGitHubRelease(match[1], match[2], tag)
+	if err != nil {
+		return nil, err
+	}
+	asset, err := pickAppImageAsset(release.Assets)
+	if err != nil {
+		return nil, fmt.Errorf("%s/%s: %w", match[1], match[2], err)
+	}
+	p.logger.Debug("Resolved %s to %s", downloadURL, asset.URL)
+	if err := p.validateURL(asset.URL); err != nil {
+		return nil, err
+	}
+	return &updateSource{
+		URL:       asset.URL,
+		Version:   release.TagName,
+		Signature: signatureAsset(release.Assets, asset.Name),
+	}, nil
+}
+
+// resolveUpdate finds where the newest version of an installed AppImage is published, using its
+// embedded update information when it has any and its download URL otherwise
+func (p *AppimagePlugin) resolveUpdate(record *installRecord) (*updateSource, error) {
+	fields := strings.Split(record.UpdateInfo, "|")
+	switch fields[0] {
+	case "zsync":
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid update information %q", record.UpdateInfo)
+		}
+		return p.zsyncSource(fields[1])
+	case "gh-releases-zsync":
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("invalid update information %q", record.UpdateInfo)
+		}
+		release, err := fetchGitHubRelease(fields[1], fields[2], fields[3])
+		if err != nil {
+			return nil, err
+		}
+		for _, asset := range release.Assets {
+			if matched, _ := path.Match(fields[4], asset.Name); matched {
+				source, err := p.zsyncSource(asset.URL)
+				if err != nil {
+					return nil, err
+				}
+				source.Version = release.TagName
+				source.Signature = signatureAsset(release.Assets, path.Base(source.URL))
+				return source, nil
+			}
+		}
+		return nil, fmt.Errorf("no release asset of %s/%s matches %s", fields[1], fields[2], fields[4])
+	case "":
+	default:
+		p.logger.Debug("Unsupported update information %q, using the download URL", record.UpdateInfo)
+	}
+
+	return p.resolveSource(record.URL)
+}
+
+// signatureAsset returns the URL of the detached signature of fileName among release assets
+func signatureAsset(assets []githubAsset, fileName string) string {
+	for _, suffix := range []string{".sig", ".asc"} {
+		for _, asset := range assets {
+			if asset.Name == fileName+suffix {
+				return asset.URL
+			}
+		}
+	}
+	return ""
+}
+
+// signatureURL returns where the signature of a new version of an AppImage is published: the
+// signature asset of its release, or the download URL with the extension of the signature
+// recorded for the installed version. The recorded signature URL belongs to the installed
+// version and never verifies a new one.
+func signatureURL(record *installRecord, source *updateSource) string {
+	if record.Signature == "" {
+		return ""
+	}
+	if source.Signature != "" {
+		return source.Signature
+	}
+	if strings.HasSuffix(record.Signature, ".asc") {
+		return source.URL + ".asc"
+	}
+	return source.URL + ".sig"
+}
+
+// remoteETag asks the server for the entity tag of a download without downloading it
+func remoteETag(downloadURL string) string {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Head(downloadURL)
+	if err != nil {
+		return ""
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+	return resp.Header.Get("ETag")
+}
+
+// zsyncSource reads the header of a zsync file for the URL and SHA-1 of the file it describes
+func (p *AppimagePlugin) zsyncSource(zsyncURL string) (*updateSource, error) {
+	if err := p.validateURL(zsyncURL); err != nil {
+		return nil, fmt.Errorf("invalid zsync URL: %w", err)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(zsyncURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch update metadata: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch update metadata: %s", resp.Status)
+	}
+
+	header := parseZsyncHeader(io.LimitReader(resp.Body, 64*1024))
+	target := header["URL"]
+	if target == "" {
+		target = header["Filename"]
+	}
+	if target == "" {
+		return nil, fmt.Errorf("update metadata %s does not name a file", zsyncURL)
+	}
+
+	base, err := url.Parse(zsyncURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid zsync URL: %w", err)
+	}
+	ref, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL in update metadata: %w", err)
+	}
+	downloadURL := base.ResolveReference(ref).String()
+	if err := p.validateURL(downloadURL); err != nil {
+		return nil, fmt.Errorf("invalid URL in update metadata: %w", err)
+	}
+	return &updateSource{URL: downloadURL, SHA1: header["SHA-1"]}, nil
+}
+
+// parseZsyncHeader reads the "Key: value" lines that precede the checksums of a zsync file
+func parseZsyncHeader(r io.Reader) map[string]string {
+	header := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			header[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return header
+}
+
+// fetchGitHubRelease returns a GitHub release with its assets; tag "latest" selects the newest release
+func fetchGitHubRelease(owner, repo, tag string) (*githubRelease, error) {
+	endpoint := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/latest", owner, repo)
+	if tag != "latest" {
+		endpoint = fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/tags/%s", owner, repo, url.PathEscape(tag))
+	}
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create release request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query release %s of %s/%s: %w", tag, owner, repo, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to query release %s of %s/%s: %s", tag, owner, repo, resp.Status)
+	}
+
+	var release githubRelease
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 10*1024*1024)).Decode(&release); err != nil {
+		return nil, fmt.Errorf("invalid release data for %s/%s: %w", owner, repo, err)
+	}
+	return &release, nil
+}
+
+// pickAppImageAsset selects the AppImage built for this machine from release assets
+func pickAppImageAsset(assets []githubAsset) (*githubAsset, error) {
+	arches := map[string][]string{
+		"amd64": {"x86_64", "amd64", "x64"},
+		"arm64": {"aarch64", "arm64"},
+		"386":   {"i386", "i686"},
+		"arm":   {"armhf", "armv7"},
+	}[runtime.GOARCH]
+
+	var fallback *githubAsset
+	for i, asset := range assets {
+		name := strings.ToLower(asset.Name)
+		if !strings.HasSuffix(name, ".appimage") {
+			continue
+		}
+		for _, arch := range arches {
+			if strings.Contains(name, arch) {
+				return &assets[i], nil
+			}
+		}
+		if fallback == nil {
+			fallback = &assets[i]
+		}
+	}
+	if fallback == nil {
+		return nil, fmt.Errorf("release has no AppImage asset")
+	}
+	return fallback, nil
+}
+
+// handleUpdate updates installed AppImages, all of them when no names are given. The previous
+// version of each updated AppImage is kept for rollback.
+func (p *AppimagePlugin) handleUpdate(args []string) error {
+	checkOnly := false
+	var names []string
+	for _, arg := range args {
+		switch arg {
+		case "--check":
+			checkOnly = true
+		default:
+			if err := p.validateBinaryName(arg); err != nil {
+				return fmt.Errorf("invalid binary name '%s': %w", arg, err)
+			}
+			names = append(names, arg)
+		}
+	}
+
+	if len(names) == 0 {
+		recorded, err := recordedAppImages()
+		if err != nil {
+			return err
+		}
+		if len(recorded) == 0 {
+			p.logger.Printf("No AppImages installed by devex\n")
+			return nil
+		}
+		names = recorded
+	}
+
+	var failed []string
+	for _, name := range names {
+		if err := p.updateAppImage(name, checkOnly); err != nil {
+			p.logger.ErrorMsg("%s: %v", name, err)
+			failed = append(failed, name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to update: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// updateAppImage downloads the newest version of an AppImage and swaps it in atomically
+func (p *AppimagePlugin) updateAppImage(name string, checkOnly bool) error {
+	record, err := loadRecord(name)
+	if err != nil {
+		return err
+	}
+	if record.PinnedSHA256 != "" {
+		p.logger.Printf("%s is pinned to sha256 %s, update the checksum in its configuration to upgrade\n", name, record.PinnedSHA256)
+		return nil
+	}
+
+	source, err := p.resolveUpdate(record)
+	if err != nil {
+		return err
+	}
+
+	if source.SHA1 != "" {
+		current, err := fileDigest(record.Path, newSHA1())
+		if err == nil && strings.EqualFold(current, source.SHA1) {
+			p.logger.Printf("%s is up to date\n", name)
+			return nil
+		}
+		if checkOnly {
+			p.logger.Printf("%s has an update available\n", name)
+			return nil
+		}
+	} else {
+		// Without a checksum the release tag or the entity tag tells whether the file changed
+		if source.Version == "" {
+			source.Version = remoteETag(source.URL)
+		}
+		if source.Version != "" && record.Version != "" {
+			if source.Version == record.Version {
+				p.logger.Printf("%s is up to date\n", name)
+				return nil
+			}
+			if checkOnly {
+				p.logger.Printf("%s has an update available (%s)\n", name, source.Version)
+				return nil
+			}
+		}
+	}
+
+	dir := filepath.Dir(record.Path)
+	tmpPath, etag, err := p.downloadToTemp(source.URL, dir, name)
+	if err != nil {
+		return fmt.Errorf("failed to download update: %w", err)
+	}
+	defer func() { _ = os.Remove(tmpPath) }()
+	if source.Version == "" {
+		source.Version = etag
+	}
+
+	signature := signatureURL(record, source)
+	sum, err := p.verifyDownload(tmpPath, downloadVerification{
+		SHA1:         source.SHA1,
+		Signature:    signature,
+		SignatureKey: record.SignatureKey,
+	})
+	if err != nil {
+		return fmt.Errorf("verification failed: %w", err)
+	}
+	if strings.EqualFold(sum, record.SHA256) {
+		if record.Version != source.Version {
+			record.Version = source.Version
+			if err := saveRecord(record); err != nil {
+				return err
+			}
+		}
+		p.logger.Printf("%s is up to date\n", name)
+		return nil
+	}
+	if checkOnly {
+		p.logger.Printf("%s has an update available\n", name)
+		return nil
+	}
+
+	if err := keepPrevious(record); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0o755); err != nil {
+		return fmt.Errorf("failed to set permissions on AppImage: %w", err)
+	}
+	if err := os.Rename(tmpPath, record.Path); err != nil {
+		return fmt.Errorf("failed to replace AppImage: %w", err)
+	}
+
+	record.PreviousSHA256 = record.SHA256
+	record.SHA256 = sum
+	record.Signature = signature
+	record.Version = source.Version
+	record.UpdateInfo = readUpdateInfo(record.Path)
+	record.UpdatedAt = time.Now().UTC()
+	if err := saveRecord(record); err != nil {
+		return err
+	}
+
+	p.logger.Success("AppImage %s updated, run 'rollback %s' to restore the previous version", name, name)
+	return nil
+}
+
+// handleRollback restores the version of an AppImage that was replaced by its last update. The
+// replaced version is kept in turn, so a rollback can itself be undone.
+func (p *AppimagePlugin) handleRollback(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no AppImage names specified")
+	}
+
+	for _, name := range args {
+		if err := p.validateBinaryName(name); err != nil {
+			return fmt.Errorf("invalid binary name '%s': %w", name, err)
+		}
+		record, err := loadRecord(name)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(previousPath(name)); err != nil {
+			return fmt.Errorf("no previous version of %s is available", name)
+		}
+
+		restored, err := copyToTemp(previousPath(name), filepath.Dir(record.Path), "."+name+".rollback-*")
+		if err != nil {
+			return err
+		}
+		if err := os.Chmod(restored, 0o755); err != nil {
+			_ = os.Remove(restored)
+			return fmt.Errorf("failed to set permissions on AppImage: %w", err)
+		}
+		if err := keepPrevious(record); err != nil {
+			_ = os.Remove(restored)
+			return err
+		}
+		if err := os.Rename(restored, record.Path); err != nil {
+			_ = os.Remove(restored)
+			return fmt.Errorf("failed to restore AppImage: %w", err)
+		}
+
+		record.SHA256, record.PreviousSHA256 = record.PreviousSHA256, record.SHA256
+		record.UpdateInfo = readUpdateInfo(record.Path)
+		record.UpdatedAt = time.Now().UTC()
+		if err := saveRecord(record); err != nil {
+			return err
+		}
+		p.logger.Success("AppImage %s rolled back", name)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// serveAs routes every request of the default HTTP transport to handler, so URLs on public
+// host names, which pass URL validation, are answered locally
+func serveAs(handler http.Handler) {
+	server := httptest.NewServer(handler)
+	original := http.DefaultTransport
+	http.DefaultTransport = &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+		},
+	}
+	DeferCleanup(func() {
+		http.DefaultTransport = original
+		server.Close()
+	})
+}
+
+var _ = Describe("Updates", func() {
+	var plugin *AppimagePlugin
+
+	BeforeEach(func() {
+		plugin = NewAppimagePlugin()
+		GinkgoT().Setenv("XDG_DATA_HOME", GinkgoT().TempDir())
+	})
+
+	Describe("parseZsyncHeader", func() {
+		It("reads the header lines up to the checksums", func() {
+			header := parseZsyncHeader(strings.NewReader(
+				"zsync: 0.6.2\nFilename: app-2.0.AppImage\nURL: app-2.0.AppImage\nSHA-1: abc123\n\n\x00binary checksums: ignored\n"))
+			Expect(header).To(Equal(map[string]string{
+				"zsync":    "0.6.2",
+				"Filename": "app-2.0.AppImage",
+				"URL":      "app-2.0.AppImage",
+				"SHA-1":    "abc123",
+			}))
+		})
+	})
+
+	Describe("resolveUpdate", func() {
+		It("rejects malformed update information", func() {
+			for _, info := range []string{"zsync", "zsync|a|b", "gh-releases-zsync|owner|repo|latest"} {
+				_, err := plugin.resolveUpdate(&installRecord{UpdateInfo: info})
+				Expect(err).To(MatchError(ContainSubstring("invalid update information")), info)
+			}
+		})
+
+		It("falls back to the download URL without update information", func() {
+			source, err := plugin.resolveUpdate(&installRecord{URL: "http://downloads.example.com/app.AppImage", UpdateInfo: "bintray-zsync|x"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(source).To(Equal(&updateSource{URL: "http://downloads.example.com/app.AppImage"}))
+		})
+
+		It("resolves the file named by zsync metadata relative to it", func() {
+			serveAs(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.URL.Path).To(Equal("/releases/app.AppImage.zsync"))
+				_, _ = w.Write([]byte("zsync: 0.6.2\nURL: app-2.0.AppImage\nSHA-1: ABC123\n\n"))
+			}))
+
+			source, err := plugin.resolveUpdate(&installRecord{UpdateInfo: "zsync|http://updates.example.com/releases/app.AppImage.zsync"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(source).To(Equal(&updateSource{URL: "http://updates.example.com/releases/app-2.0.AppImage", SHA1: "ABC123"}))
+		})
+
+		It("refuses zsync metadata pointing at a local address", func() {
+			serveAs(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("URL: http://127.0.0.1/app.AppImage\n\n"))
+			}))
+
+			_, err := plugin.resolveUpdate(&installRecord{UpdateInfo: "zsync|http://updates.example.com/app.AppImage.zsync"})
+			Expect(err).To(MatchError(ContainSubstring("invalid URL in update metadata")))
+		})
+	})
+
+	Describe("signatureURL", func() {
+		It("verifies a new version with the signature of its release or next to its download", func() {
+			record := &installRecord{Signature: "https://example.com/app-1.0.AppImage.asc"}
+			Expect(signatureURL(record, &updateSource{URL: "https://example.com/app-2.0.AppImage"})).
+				To(Equal("https://example.com/app-2.0.AppImage.asc"))
+			Expect(signatureURL(record, &updateSource{URL: "https://example.com/app-2.0.AppImage", Signature: "https://example.com/v2/app.sig"})).
+				To(Equal("https://example.com/v2/app.sig"))
+
+			record.Signature = "https://example.com/app-1.0.AppImage.sig"
+			Expect(signatureURL(record, &updateSource{URL: "https://example.com/app-2.0.AppImage"})).
+				To(Equal("https://example.com/app-2.0.AppImage.sig"))
+		})
+
+		It("does not ask for a signature the installed version did not have", func() {
+			Expect(signatureURL(&installRecord{}, &updateSource{URL: "https://example.com/app.AppImage", Signature: "https://example.com/app.AppImage.sig"})).
+				To(BeEmpty())
+		})
+
+		It("finds the signature asset of a release", func() {
+			assets := []githubAsset{
+				{Name: "app-x86_64.AppImage", URL: "https://example.com/app-x86_64.AppImage"},
+				{Name: "app-aarch64.AppImage.sig", URL: "https://example.com/app-aarch64.AppImage.sig"},
+				{Name: "app-x86_64.AppImage.sig", URL: "https://example.com/app-x86_64.AppImage.sig"},
+			}
+			Expect(signatureAsset(assets, "app-x86_64.AppImage")).To(Equal("https://example.com/app-x86_64.AppImage.sig"))
+			Expect(signatureAsset(assets, "other.AppImage")).To(BeEmpty())
+		})
+	})
+
+	Describe("update and rollback", func() {
+		var (
+			binPath   string
+			served    []byte
+			etag      string
+			downloads int
+		)
+
+		BeforeEach(func() {
+			served = fakeAppImage("2.0")
+			etag = ""
+			downloads = 0
+			serveAs(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/app.AppImage.zsync":
+					sha1Sum, err := fileDigest(filepath.Join(filepath.Dir(binPath), "served"), newSHA1())
+					Expect(err).NotTo(HaveOccurred())
+					_, _ = w.Write([]byte("URL: app.AppImage\nSHA-1: " + sha1Sum + "\n\n"))
+				default:
+					if etag != "" {
+						w.Header().Set("ETag", etag)
+					}
+					if r.Method == http.MethodGet {
+						downloads++
+						_, _ = w.Write(served)
+					}
+				}
+			}))
+
+			binPath = filepath.Join(GinkgoT().TempDir(), "app")
+			Expect(os.WriteFile(binPath, fakeAppImage("1.0"), 0o755)).To(Succeed())
+			Expect(saveRecord(&installRecord{
+				Name:   "app",
+				Path:   binPath,
+				URL:    "http://downloads.example.com/app.AppImage",
+				SHA256: digestOf(fakeAppImage("1.0")),
+			})).To(Succeed())
+		})
+
+		It("replaces the AppImage and restores the previous version on rollback", func() {
+			Expect(plugin.updateAppImage("app", false)).To(Succeed())
+			Expect(os.ReadFile(binPath)).To(Equal(fakeAppImage("2.0")))
+			Expect(os.ReadFile(previousPath("app"))).To(Equal(fakeAppImage("1.0")))
+
+			record, err := loadRecord("app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(record.SHA256).To(Equal(digestOf(fakeAppImage("2.0"))))
+			Expect(record.PreviousSHA256).To(Equal(digestOf(fakeAppImage("1.0"))))
+			Expect(record.Version).To(BeEmpty())
+
+			Expect(plugin.handleRollback([]string{"app"})).To(Succeed())
+			Expect(os.ReadFile(binPath)).To(Equal(fakeAppImage("1.0")))
+			Expect(os.ReadFile(previousPath("app"))).To(Equal(fakeAppImage("2.0")))
+
+			record, err = loadRecord("app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(record.SHA256).To(Equal(digestOf(fakeAppImage("1.0"))))
+			Expect(record.PreviousSHA256).To(Equal(digestOf(fakeAppImage("2.0"))))
+		})
+
+		It("only reports an update with --check", func() {
+			Expect(plugin.handleUpdate([]string{"--check", "app"})).To(Succeed())
+			Expect(os.ReadFile(binPath)).To(Equal(fakeAppImage("1.0")))
+			Expect(previousPath("app")).NotTo(BeAnExistingFile())
+		})
+
+		It("keeps the installed version when the download fails verification", func() {
+			served = []byte("<html>rate limited</html>")
+			Expect(plugin.updateAppImage("app", false)).To(MatchError(ContainSubstring("verification failed")))
+			Expect(os.ReadFile(binPath)).To(Equal(fakeAppImage("1.0")))
+
+			entries, err := os.ReadDir(filepath.Dir(binPath))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(1), "the download is cleaned up")
+		})
+
+		It("does not download when zsync metadata shows the installed version", func() {
+			Expect(os.WriteFile(filepath.Join(filepath.Dir(binPath), "served"), fakeAppImage("1.0"), 0o600)).To(Succeed())
+			served = []byte("must not be downloaded")
+			record, err := loadRecord("app")
+			Expect(err).NotTo(HaveOccurred())
+			record.UpdateInfo = "zsync|http://downloads.example.com/app.AppImage.zsync"
+			Expect(saveRecord(record)).To(Succeed())
+
+			Expect(plugin.updateAppImage("app", false)).To(Succeed())
+			Expect(os.ReadFile(binPath)).To(Equal(fakeAppImage("1.0")))
+		})
+
+		It("compares entity tags instead of downloading without a zsync checksum", func() {
+			record, err := loadRecord("app")
+			Expect(err).NotTo(HaveOccurred())
+			record.Version = `"v1"`
+			Expect(saveRecord(record)).To(Succeed())
+
+			etag = `"v1"`
+			Expect(plugin.handleUpdate([]string{"--check", "app"})).To(Succeed())
+			Expect(plugin.updateAppImage("app", false)).To(Succeed())
+			Expect(downloads).To(BeZero())
+			Expect(os.ReadFile(binPath)).To(Equal(fakeAppImage("1.0")))
+
+			etag = `"v2"`
+			Expect(plugin.handleUpdate([]string{"--check", "app"})).To(Succeed())
+			Expect(downloads).To(BeZero())
+
+			Expect(plugin.updateAppImage("app", false)).To(Succeed())
+			Expect(downloads).To(Equal(1))
+			Expect(os.ReadFile(binPath)).To(Equal(fakeAppImage("2.0")))
+			record, err = loadRecord("app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(record.Version).To(Equal(`"v2"`))
+		})
+
+		It("refuses to roll back without a previous version", func() {
+			Expect(plugin.handleRollback([]string{"app"})).To(MatchError(ContainSubstring("no previous version")))
+		})
+	})
+})
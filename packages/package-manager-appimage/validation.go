@@ -7,6 +7,8 @@ import (
 	"strings"
 )
 
+var sha256Regex = regexp.MustCompile(`^[a-fA-F0-9]{64}$`)
+
 const (
 	// MaxBinaryNameLength defines the maximum allowed binary name length
 	MaxBinaryNameLength = 100
@@ -117,6 +119,22 @@ func (p *AppimagePlugin) validateBinaryName(binaryName string) error {
 	return nil
 }
 
+// validateVerification validates the checksum and signature settings of an install
+func (p *AppimagePlugin) validateVerification(v downloadVerification) error {
+	if v.SHA256 != "" && !sha256Regex.MatchString(v.SHA256) {
+		return fmt.Errorf("sha256 must be 64 hexadecimal characters")
+	}
+	if v.Signature != "" {
+		if v.SignatureKey == "" {
+			return fmt.Errorf("a signature requires --signature-key")
+		}
+		if err := p.validateURL(v.Signature); err != nil {
+			return fmt.Errorf("invalid signature URL: %w", err)
+		}
+	}
+	return nil
+}
+
 // ValidateInstallLocation validates installation location
 func (p *AppimagePlugin) ValidateInstallLocation(location string) error {
 	validLocations := []string{"gui", "cli"}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeAppImage returns contents that pass the AppImage format check
+func fakeAppImage(version string) []byte {
+	return []byte("\x7fELF fake appimage " + version)
+}
+
+// digestOf returns the hex SHA-256 of data
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+var _ = Describe("Download verification", func() {
+	var (
+		plugin *AppimagePlugin
+		path   string
+	)
+
+	BeforeEach(func() {
+		plugin = NewAppimagePlugin()
+		path = filepath.Join(GinkgoT().TempDir(), "app.AppImage")
+		Expect(os.WriteFile(path, fakeAppImage("1.0"), 0o600)).To(Succeed())
+	})
+
+	It("returns the SHA-256 of a matching download", func() {
+		sum, err := plugin.verifyDownload(path, downloadVerification{SHA256: strings.ToUpper(digestOf(fakeAppImage("1.0")))})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sum).To(Equal(digestOf(fakeAppImage("1.0"))))
+	})
+
+	It("rejects a SHA-256 mismatch", func() {
+		_, err := plugin.verifyDownload(path, downloadVerification{SHA256: digestOf([]byte("other"))})
+		Expect(err).To(MatchError(ContainSubstring("checksum mismatch: expected sha256")))
+	})
+
+	It("rejects a SHA-1 that differs from the update metadata", func() {
+		_, err := plugin.verifyDownload(path, downloadVerification{SHA1: strings.Repeat("0", 40)})
+		Expect(err).To(MatchError(ContainSubstring("update metadata lists sha1")))
+
+		sha1Sum, err := fileDigest(path, newSHA1())
+		Expect(err).NotTo(HaveOccurred())
+		_, err = plugin.verifyDownload(path, downloadVerification{SHA1: sha1Sum})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rejects downloads that are not AppImages", func() {
+		Expect(os.WriteFile(path, []byte("<html>Not Found</html>"), 0o600)).To(Succeed())
+		_, err := plugin.verifyDownload(path, downloadVerification{})
+		Expect(err).To(MatchError("downloaded file is not an AppImage"))
+	})
+
+	It("requires a key to verify a signature", func() {
+		_, err := plugin.verifyDownload(path, downloadVerification{Signature: "https://example.com/app.AppImage.sig"})
+		Expect(err).To(MatchError(ContainSubstring("a signature key is required")))
+	})
+})
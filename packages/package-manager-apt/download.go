@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+// handleDownload fetches packages with their complete dependency closure into --dest for an
+// offline installation. The local package database is ignored, so dependencies that happen to
+// be installed on this machine are downloaded as well; the target machine may not have them.
+func (a *APTInstaller) handleDownload(ctx context.Context, args []string) error {
+	dest, packages, err := sdk.ParseDownloadArgs(args)
+	if err != nil {
+		return err
+	}
+	for _, pkg := range packages {
+		if err := a.validatePackageName(pkg); err != nil {
+			return fmt.Errorf("invalid package name '%s': %w", pkg, err)
+		}
+	}
+
+	// APT downloads into <archives>/partial and locks the archives directory
+	partial := filepath.Join(dest, "partial")
+	if err := os.MkdirAll(partial, 0755); err != nil {
+		return fmt.Errorf("failed to prepare download directory: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(partial)
+		_ = os.Remove(filepath.Join(dest, "lock"))
+	}()
+
+	a.getLogger().Printf("Downloading packages to %s: %s\n", dest, strings.Join(packages, ", "))
+	cmdArgs := []string{
+		"install", "--download-only", "--yes", "--no-install-recommends",
+		"-o", "Dir::Cache::archives=" + dest,
+		"-o", "Dir::State::status=/dev/null",
+		"-o", "Debug::NoLocking=1",
+	}
+	if err := sdk.ExecCommandWithContext(ctx, false, "apt-get", append(cmdArgs, packages...)...); err != nil {
+		return fmt.Errorf("failed to download packages: %w", err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ package main
 // Enhanced with improved error handling and diagnostics
 
 import (
+	_ "embed"
 	"fmt"
 	"os"
 
@@ -12,6 +13,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // NewAPTPlugin creates a new APT plugin
 func NewAPTPlugin() *APTInstaller {
 	info := sdk.PluginInfo{
@@ -21,6 +27,7 @@ func NewAPTPlugin() *APTInstaller {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"package-manager", "apt", "debian", "ubuntu", "linux"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "install",
@@ -79,6 +86,27 @@ func NewAPTPlugin() *APTInstaller {
 				Description: "Check if a package is installed",
 				Usage:       "Returns exit code 0 if package is installed, 1 if not",
 			},
+			{
+				Name:        "resolve",
+				Description: "Resolve a version constraint",
+				Usage:       "Print the newest available version matching --constraint as JSON, with the install spec that pins it",
+				Flags: map[string]string{
+					"constraint": "Version constraint, e.g. 2.43 or >=2.40,<3",
+				},
+			},
+			{
+				Name:        "version",
+				Description: "Show installed package versions",
+				Usage:       "Print the installed version and origin of each package as JSON",
+			},
+			{
+				Name:        "download",
+				Description: "Download packages for offline installation",
+				Usage:       "Download packages and all of their dependencies into --dest without installing them",
+				Flags: map[string]string{
+					"dest": "Directory to download the .deb files to",
+				},
+			},
 			{
 				Name:        "add-repository",
 				Description: "Add a new APT repository with GPG key",
@@ -3,9 +3,12 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	"gopkg.in/yaml.v3"
+
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
 )
 
 // PluginMetadata represents the structure of metadata.yaml
@@ -45,6 +48,29 @@ func TestMetadataValidYAML(t *testing.T) {
 	}
 }
 
+func TestMetadataPermissions(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join(".", "metadata.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to read metadata.yaml: %v", err)
+	}
+
+	permissions, err := sdk.ParsePermissionManifest(content)
+	if err != nil {
+		t.Fatalf("Invalid permission manifest: %v", err)
+	}
+	if permissions == nil {
+		t.Fatal("permissions section is required")
+	}
+	if !permissions.Sudo || !permissions.Network {
+		t.Error("APT needs sudo and network access")
+	}
+	for _, command := range []string{"apt-get", "apt-cache", "dpkg-query"} {
+		if !slices.Contains(permissions.Exec, command) {
+			t.Errorf("exec permission for %s is missing", command)
+		}
+	}
+}
+
 func TestMetadataRequiredFields(t *testing.T) {
 	metadataPath := filepath.Join(".", "metadata.yaml")
 	content, err := os.ReadFile(metadataPath)
@@ -80,6 +80,12 @@ func (a *APTInstaller) Execute(command string, args []string) error {
 		return a.handleInfo(ctx, args)
 	case "is-installed":
 		return a.handleIsInstalled(ctx, args)
+	case "resolve":
+		return a.handleResolve(ctx, args)
+	case "version":
+		return a.handleVersion(ctx, args)
+	case "download":
+		return a.handleDownload(ctx, args)
 	case "add-repository":
 		return a.handleAddRepository(ctx, args)
 	case "remove-repository":
@@ -360,7 +366,7 @@ func (a *APTInstaller) handleIsInstalled(ctx context.Context, args []string) err
 		return fmt.Errorf("no packages specified")
 	}
 
-	allInstalled := true
+	var missing []string
 	for _, pkg := range args {
 		if err := a.validatePackageName(pkg); err != nil {
 			return fmt.Errorf("invalid package name '%s': %w", pkg, err)
@@ -375,12 +381,12 @@ func (a *APTInstaller) handleIsInstalled(ctx context.Context, args []string) err
 			a.getLogger().Success("Package %s is installed", pkg)
 		} else {
 			a.getLogger().ErrorMsg("Package %s is not installed", pkg)
-			allInstalled = false
+			missing = append(missing, pkg)
 		}
 	}
 
-	if !allInstalled {
-		return fmt.Errorf("one or more packages are not installed")
+	if len(missing) > 0 {
+		return &sdk.NotInstalledError{Packages: missing}
 	}
 	return nil
 }
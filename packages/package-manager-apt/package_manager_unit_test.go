@@ -578,6 +578,24 @@ var _ = Describe("APT Package Manager Unit Tests", func() {
 		})
 	})
 
+	Describe("Version Resolution", func() {
+		It("parses apt-cache madison output into pinnable versions", func() {
+			output := `       git | 1:2.43.0-1ubuntu7.1 | http://archive.ubuntu.com/ubuntu noble-updates/main amd64 Packages
+       git | 1:2.43.0-1ubuntu7 | http://archive.ubuntu.com/ubuntu noble/main amd64 Packages
+  git-man | 1:2.43.0-1ubuntu7 | http://archive.ubuntu.com/ubuntu noble/main amd64 Packages`
+
+			versions := main.ParseMadison("git", output)
+			Expect(versions).To(HaveLen(2))
+			Expect(versions[0].Version).To(Equal("1:2.43.0-1ubuntu7.1"))
+			Expect(versions[0].Source).To(Equal("http://archive.ubuntu.com/ubuntu noble-updates/main"))
+			Expect(versions[0].Spec).To(Equal("git=1:2.43.0-1ubuntu7.1"))
+
+			selected, err := sdk.SelectVersion("git", "2.43", versions)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(selected.Version).To(Equal("1:2.43.0-1ubuntu7.1"))
+		})
+	})
+
 	Describe("Concurrent Validation Safety", func() {
 		Context("multiple validation operations", func() {
 			It("should handle concurrent validation requests safely", func() {
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+// handleResolve prints the newest available version of each package matching --constraint
+func (a *APTInstaller) handleResolve(ctx context.Context, args []string) error {
+	return a.ResolveVersions(ctx, args, a.availableVersions)
+}
+
+// handleVersion prints the installed version and origin of each package
+func (a *APTInstaller) handleVersion(ctx context.Context, args []string) error {
+	return a.ReportVersions(ctx, args, a.installedVersion)
+}
+
+// availableVersions lists every version of a package known to the configured repositories
+func (a *APTInstaller) availableVersions(ctx context.Context, packageName string) ([]sdk.PackageInfo, error) {
+	if err := a.validatePackageName(packageName); err != nil {
+		return nil, err
+	}
+
+	output, err := sdk.ExecCommandOutputWithTimeoutAndOperation(a.GetTimeout("search"), "search", "apt-cache", "madison", packageName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+	return parseMadison(packageName, output), nil
+}
+
+// installedVersion reports the installed version of a package and the repository it came from
+func (a *APTInstaller) installedVersion(ctx context.Context, packageName string) (sdk.PackageInfo, error) {
+	installed, err := a.isPackageInstalled(packageName)
+	if err != nil {
+		return sdk.PackageInfo{}, err
+	}
+	if !installed {
+		return sdk.PackageInfo{}, sdk.ErrNotInstalled
+	}
+
+	version, err := sdk.ExecCommandOutputWithTimeoutAndOperation(a.GetTimeout("search"), "search", "dpkg-query", "-W", "-f=${Version}", packageName)
+	if err != nil {
+		return sdk.PackageInfo{}, fmt.Errorf("failed to query installed version: %w", err)
+	}
+
+	info := sdk.PackageInfo{Name: packageName, Version: strings.TrimSpace(version)}
+	if available, err := a.availableVersions(ctx, packageName); err == nil {
+		for _, candidate := range available {
+			if candidate.Version == info.Version {
+				info.Source = candidate.Source
+				break
+			}
+		}
+	}
+	return info, nil
+}
+
+// ParseMadison parses apt-cache madison output (exported for testing)
+func ParseMadison(packageName, output string) []sdk.PackageInfo {
+	return parseMadison(packageName, output)
+}
+
+// parseMadison parses "apt-cache madison" output:
+//
+//	git | 1:2.43.0-1ubuntu7.1 | http://archive.ubuntu.com/ubuntu noble-updates/main amd64 Packages
+func parseMadison(packageName, output string) []sdk.PackageInfo {
+	var versions []sdk.PackageInfo
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "|")
+		if len(fields) < 3 || strings.TrimSpace(fields[0]) != packageName {
+			continue
+		}
+
+		version := strings.TrimSpace(fields[1])
+		source := strings.TrimSpace(fields[2])
+		if parts := strings.Fields(source); len(parts) >= 2 {
+			// Keep the mirror and suite/component, drop the architecture and index type
+			source = parts[0] + " " + parts[1]
+		}
+
+		versions = append(versions, sdk.PackageInfo{
+			Name:    packageName,
+			Version: version,
+			Source:  source,
+			Spec:    packageName + "=" + version,
+		})
+	}
+	return versions
+}
@@ -4,6 +4,8 @@ package main
 
 import (
 	"context"
+	_ "embed"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -13,6 +15,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // BrewPlugin implements the Homebrew package manager
 type BrewPlugin struct {
 	*sdk.PackageManagerPlugin
@@ -27,6 +34,7 @@ func NewBrewPlugin() *BrewPlugin {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"brew", "homebrew", "macos", "linux"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "install",
@@ -53,6 +61,21 @@ func NewBrewPlugin() *BrewPlugin {
 				Description: "List packages",
 				Usage:       "List installed packages",
 			},
+			{
+				Name:        "is-installed",
+				Description: "Check if packages are installed",
+				Usage:       "Exits 0 if all packages are installed, 1 if any is missing and 2 if the check fails",
+			},
+			{
+				Name:        "resolve",
+				Description: "Resolve a version constraint",
+				Usage:       "Print the newest available version matching --constraint as JSON, with the install spec that pins it",
+			},
+			{
+				Name:        "version",
+				Description: "Show installed package versions",
+				Usage:       "Print the installed version and tap of each package as JSON",
+			},
 		},
 	}
 
@@ -78,6 +101,12 @@ func (p *BrewPlugin) Execute(command string, args []string) error {
 		return p.handleSearch(ctx, args)
 	case "list":
 		return p.handleList(ctx, args)
+	case "is-installed":
+		return p.handleIsInstalled(ctx, args)
+	case "resolve":
+		return p.ResolveVersions(ctx, args, p.availableVersions)
+	case "version":
+		return p.ReportVersions(ctx, args, p.installedVersion)
 	default:
 		return fmt.Errorf("unknown command: %s", command)
 	}
@@ -126,6 +155,85 @@ func (p *BrewPlugin) handleList(ctx context.Context, args []string) error {
 	return sdk.ExecCommandWithContext(ctx, false, "brew", "list")
 }
 
+func (p *BrewPlugin) handleIsInstalled(ctx context.Context, args []string) error {
+	return p.CheckInstalled(ctx, args, p.isPackageInstalled)
+}
+
+// isPackageInstalled queries the local package database with `brew list`
+func (p *BrewPlugin) isPackageInstalled(ctx context.Context, pkg string) (bool, error) {
+	return sdk.CheckCommandWithContext(ctx, "brew", "list", pkg)
+}
+
+// brewInfo is the subset of `brew info --json=v2` used for version resolution
+type brewInfo struct {
+	Formulae []struct {
+		Tap      string `json:"tap"`
+		Versions struct {
+			Stable string `json:"stable"`
+		} `json:"versions"`
+		Installed []struct {
+			Version string `json:"version"`
+		} `json:"installed"`
+	} `json:"formulae"`
+	Casks []struct {
+		Tap       string `json:"tap"`
+		Version   string `json:"version"`
+		Installed string `json:"installed"`
+	} `json:"casks"`
+}
+
+// queryInfo runs `brew info --json=v2` for a formula or cask
+func (p *BrewPlugin) queryInfo(ctx context.Context, pkg string) (*brewInfo, error) {
+	output, err := sdk.ExecCommandOutputWithContext(ctx, "brew", "info", "--json=v2", pkg)
+	if err != nil {
+		return nil, fmt.Errorf("no formula or cask named %s: %w", pkg, err)
+	}
+
+	var info brewInfo
+	if err := json.Unmarshal([]byte(output), &info); err != nil {
+		return nil, fmt.Errorf("failed to parse brew info: %w", err)
+	}
+	return &info, nil
+}
+
+// availableVersions reports the version Homebrew currently ships.
+// Older releases are only available as separate versioned formulae (e.g. node@20), so there is a single candidate.
+func (p *BrewPlugin) availableVersions(ctx context.Context, pkg string) ([]sdk.PackageInfo, error) {
+	info, err := p.queryInfo(ctx, pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(info.Formulae) > 0:
+		formula := info.Formulae[0]
+		return []sdk.PackageInfo{{Name: pkg, Version: formula.Versions.Stable, Source: formula.Tap, Spec: pkg}}, nil
+	case len(info.Casks) > 0:
+		cask := info.Casks[0]
+		return []sdk.PackageInfo{{Name: pkg, Version: cask.Version, Source: cask.Tap, Spec: pkg}}, nil
+	}
+	return nil, nil
+}
+
+// installedVersion reports the installed version of a formula or cask and its tap
+func (p *BrewPlugin) installedVersion(ctx context.Context, pkg string) (sdk.PackageInfo, error) {
+	info, err := p.queryInfo(ctx, pkg)
+	if err != nil {
+		return sdk.PackageInfo{}, err
+	}
+
+	if len(info.Formulae) > 0 {
+		formula := info.Formulae[0]
+		if len(formula.Installed) > 0 {
+			return sdk.PackageInfo{Name: pkg, Version: formula.Installed[len(formula.Installed)-1].Version, Source: formula.Tap}, nil
+		}
+	}
+	if len(info.Casks) > 0 && info.Casks[0].Installed != "" {
+		return sdk.PackageInfo{Name: pkg, Version: info.Casks[0].Installed, Source: info.Casks[0].Tap}, nil
+	}
+	return sdk.PackageInfo{}, sdk.ErrNotInstalled
+}
+
 func main() {
 	plugin := NewBrewPlugin()
 	sdk.HandleArgs(plugin, os.Args[1:])
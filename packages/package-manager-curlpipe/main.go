@@ -3,6 +3,7 @@ package main
 // Build timestamp: 2025-09-06
 
 import (
+	_ "embed"
 	"os"
 
 	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
@@ -10,6 +11,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // NewCurlpipePlugin creates a new Curl Pipe plugin
 func NewCurlpipePlugin() *CurlpipePlugin {
 	info := sdk.PluginInfo{
@@ -19,6 +25,7 @@ func NewCurlpipePlugin() *CurlpipePlugin {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"package-manager", "curl", "download", "script", "installation"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "install",
@@ -27,6 +34,7 @@ func NewCurlpipePlugin() *CurlpipePlugin {
 				Flags: map[string]string{
 					"dry-run": "Show what would be executed without running it",
 					"force":   "Skip domain validation (use with caution)",
+					"sha256":  "Only execute the script if its SHA-256 matches this checksum",
 				},
 			},
 			{
@@ -44,14 +44,17 @@ func (p *CurlpipePlugin) handleInstall(ctx context.Context, args []string) error
 	// Parse flags
 	dryRun := false
 	force := false
+	checksum := ""
 	scriptURLs := []string{}
 
 	for _, arg := range args {
-		switch arg {
-		case "--dry-run":
+		switch {
+		case arg == "--dry-run":
 			dryRun = true
-		case "--force":
+		case arg == "--force":
 			force = true
+		case strings.HasPrefix(arg, "--sha256="):
+			checksum = strings.TrimPrefix(arg, "--sha256=")
 		default:
 			scriptURLs = append(scriptURLs, arg)
 		}
@@ -61,6 +64,15 @@ func (p *CurlpipePlugin) handleInstall(ctx context.Context, args []string) error
 		return fmt.Errorf("no URLs specified for installation")
 	}
 
+	if checksum != "" {
+		if !sha256Regex.MatchString(checksum) {
+			return fmt.Errorf("invalid sha256 '%s': must be 64 hexadecimal characters", checksum)
+		}
+		if len(scriptURLs) > 1 {
+			return fmt.Errorf("--sha256 pins a single script, got %d URLs", len(scriptURLs))
+		}
+	}
+
 	// Validate all URLs first
 	for _, scriptURL := range scriptURLs {
 		if err := p.ValidateScriptURL(scriptURL); err != nil {
@@ -83,11 +95,18 @@ func (p *CurlpipePlugin) handleInstall(ctx context.Context, args []string) error
 			continue
 		}
 
-		// Execute the installation script
-		p.logger.Printf("Executing script from: %s\n", scriptURL)
-		curlCmd := fmt.Sprintf("curl -fsSL %s | sh", scriptURL)
-		if err := sdk.ExecCommandWithContext(ctx, true, "bash", "-c", curlCmd); err != nil {
-			return fmt.Errorf("failed to execute script from '%s': %w", scriptURL, err)
+		if checksum != "" {
+			// Only the bytes that match the pin are executed, never a second download
+			if err := p.ExecutePinnedScript(ctx, scriptURL, checksum); err != nil {
+				return fmt.Errorf("failed to execute script from '%s': %w", scriptURL, err)
+			}
+		} else {
+			// Execute the installation script
+			p.logger.Printf("Executing script from: %s\n", scriptURL)
+			curlCmd := fmt.Sprintf("curl -fsSL %s | sh", scriptURL)
+			if err := sdk.ExecCommandWithContext(ctx, true, "bash", "-c", curlCmd); err != nil {
+				return fmt.Errorf("failed to execute script from '%s': %w", scriptURL, err)
+			}
 		}
 
 		p.logger.Success("Successfully executed script from %s", scriptURL)
@@ -2,7 +2,11 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"os"
+	"regexp"
 	"strings"
 
 	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
@@ -33,19 +37,22 @@ func (p *CurlpipePlugin) handlePreview(ctx context.Context, args []string) error
 	p.logger.Printf("%s\n", strings.Repeat("=", 60))
 
 	// Download and display the script
-	if err := p.downloadScript(ctx, scriptURL); err != nil {
+	content, err := p.downloadScript(ctx, scriptURL)
+	if err != nil {
 		return fmt.Errorf("failed to download script from '%s': %w", scriptURL, err)
 	}
+	p.logger.Printf("%s\n", p.SanitizeScriptContent(content))
 
 	p.logger.Printf("%s\n", strings.Repeat("=", 60))
 	p.logger.Printf("End of script preview from: %s\n", scriptURL)
+	p.logger.Printf("SHA-256: %s (pin it with sha256 in the app configuration)\n", ScriptChecksum(content))
 	return nil
 }
 
-// downloadScript downloads a script from the given URL
-func (p *CurlpipePlugin) downloadScript(ctx context.Context, scriptURL string) error {
+// downloadScript downloads a script from the given URL and returns its exact bytes
+func (p *CurlpipePlugin) downloadScript(ctx context.Context, scriptURL string) (string, error) {
 	// Use curl to download the script content
-	return sdk.ExecCommandWithContext(ctx, false, "curl", "-fsSL", scriptURL)
+	return sdk.ExecCommandOutputWithContext(ctx, "curl", "-fsSL", scriptURL)
 }
 
 // DownloadScriptToString downloads a script and returns its content as a string
@@ -132,6 +139,57 @@ func (p *CurlpipePlugin) ExecuteScriptFromURL(ctx context.Context, scriptURL str
 	return nil
 }
 
+// sha256Regex matches a hex-encoded SHA-256 checksum
+var sha256Regex = regexp.MustCompile(`^[a-fA-F0-9]{64}$`)
+
+// ScriptChecksum returns the hex-encoded SHA-256 of script content
+func ScriptChecksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyScriptChecksum checks script content against a pinned SHA-256
+func (p *CurlpipePlugin) VerifyScriptChecksum(content, expected string) error {
+	if actual := ScriptChecksum(content); !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("checksum mismatch: expected sha256 %s, got %s (run 'preview' to review the new script)", strings.ToLower(expected), actual)
+	}
+	return nil
+}
+
+// ExecutePinnedScript downloads a script, checks it against its pinned SHA-256 and executes
+// exactly the verified bytes
+func (p *CurlpipePlugin) ExecutePinnedScript(ctx context.Context, scriptURL, checksum string) error {
+	p.logger.Printf("Executing pinned script from: %s\n", scriptURL)
+
+	content, err := p.downloadScript(ctx, scriptURL)
+	if err != nil {
+		return fmt.Errorf("failed to download script: %w", err)
+	}
+	if err := p.VerifyScriptChecksum(content, checksum); err != nil {
+		return err
+	}
+	if err := p.ValidateScriptContent(content); err != nil {
+		return fmt.Errorf("script validation failed: %w", err)
+	}
+
+	scriptFile, err := os.CreateTemp("", "devex-curlpipe-*.sh")
+	if err != nil {
+		return fmt.Errorf("failed to create script file: %w", err)
+	}
+	defer func() { _ = os.Remove(scriptFile.Name()) }()
+
+	if _, err := scriptFile.WriteString(content); err != nil {
+		_ = scriptFile.Close()
+		return fmt.Errorf("failed to write script file: %w", err)
+	}
+	if err := scriptFile.Close(); err != nil {
+		return fmt.Errorf("failed to write script file: %w", err)
+	}
+
+	p.logger.Debug("Script matches pinned sha256 %s", strings.ToLower(checksum))
+	return sdk.ExecCommandWithContext(ctx, true, "bash", scriptFile.Name())
+}
+
 // CheckCurlAvailability checks if curl is available on the system
 func (p *CurlpipePlugin) CheckCurlAvailability() error {
 	if !sdk.CommandExists("curl") {
@@ -363,4 +363,38 @@ echo "Installation complete"`
 			})
 		})
 	})
+
+	Describe("VerifyScriptChecksum", func() {
+		const script = "#!/bin/sh\necho install\n"
+
+		It("should accept a script that matches the pinned checksum", func() {
+			checksum := main.ScriptChecksum(script)
+			Expect(checksum).To(HaveLen(64))
+			Expect(plugin.VerifyScriptChecksum(script, checksum)).To(Succeed())
+			Expect(plugin.VerifyScriptChecksum(script, strings.ToUpper(checksum))).To(Succeed())
+		})
+
+		It("should reject a changed script", func() {
+			checksum := main.ScriptChecksum(script)
+			err := plugin.VerifyScriptChecksum(script+"curl https://example.com | sh\n", checksum)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("checksum mismatch"))
+			Expect(err.Error()).To(ContainSubstring(checksum))
+		})
+	})
+
+	Describe("install with --sha256", func() {
+		It("should reject malformed checksums", func() {
+			err := plugin.Execute("install", []string{"--dry-run", "--sha256=abc", "https://get.docker.com"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("64 hexadecimal characters"))
+		})
+
+		It("should reject a checksum shared by several scripts", func() {
+			checksum := strings.Repeat("a", 64)
+			err := plugin.Execute("install", []string{"--dry-run", "--sha256=" + checksum, "https://get.docker.com", "https://sh.rustup.rs"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("single script"))
+		})
+	})
 })
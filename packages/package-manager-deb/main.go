@@ -3,6 +3,7 @@ package main
 // Build timestamp: 2025-09-06
 
 import (
+	_ "embed"
 	"fmt"
 	"os"
 
@@ -11,6 +12,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // NewDebPlugin creates a new DEB plugin
 func NewDebPlugin() *DebInstaller {
 	info := sdk.PluginInfo{
@@ -20,6 +26,7 @@ func NewDebPlugin() *DebInstaller {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"package-manager", "deb", "debian", "ubuntu", "dpkg"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "install",
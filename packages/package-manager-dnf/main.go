@@ -4,6 +4,7 @@ package main
 
 import (
 	"context"
+	_ "embed"
 	"fmt"
 	"os"
 	"strings"
@@ -13,6 +14,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // DnfPlugin implements the DNF package manager
 type DnfPlugin struct {
 	*sdk.PackageManagerPlugin
@@ -27,6 +33,7 @@ func NewDnfPlugin() *DnfPlugin {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"dnf", "fedora", "rhel", "linux"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "install",
@@ -53,6 +60,29 @@ func NewDnfPlugin() *DnfPlugin {
 				Description: "List packages",
 				Usage:       "List installed packages",
 			},
+			{
+				Name:        "is-installed",
+				Description: "Check if packages are installed",
+				Usage:       "Exits 0 if all packages are installed, 1 if any is missing and 2 if the check fails",
+			},
+			{
+				Name:        "resolve",
+				Description: "Resolve a version constraint",
+				Usage:       "Print the newest available version matching --constraint as JSON, with the install spec that pins it",
+			},
+			{
+				Name:        "download",
+				Description: "Download packages for offline installation",
+				Usage:       "Download packages and all of their dependencies into --dest without installing them",
+				Flags: map[string]string{
+					"dest": "Directory to download the .rpm files to",
+				},
+			},
+			{
+				Name:        "version",
+				Description: "Show installed package versions",
+				Usage:       "Print the installed version and repository of each package as JSON",
+			},
 		},
 	}
 
@@ -78,6 +108,14 @@ func (p *DnfPlugin) Execute(command string, args []string) error {
 		return p.handleSearch(ctx, args)
 	case "list":
 		return p.handleList(ctx, args)
+	case "is-installed":
+		return p.handleIsInstalled(ctx, args)
+	case "resolve":
+		return p.ResolveVersions(ctx, args, p.availableVersions)
+	case "version":
+		return p.ReportVersions(ctx, args, p.installedVersion)
+	case "download":
+		return p.handleDownload(ctx, args)
 	default:
 		return fmt.Errorf("unknown command: %s", command)
 	}
@@ -126,6 +164,79 @@ func (p *DnfPlugin) handleList(ctx context.Context, args []string) error {
 	return sdk.ExecCommandWithContext(ctx, false, "dnf", "list")
 }
 
+// handleDownload fetches packages with all of their dependencies into --dest for an offline
+// installation, including dependencies that are already installed on this machine
+func (p *DnfPlugin) handleDownload(ctx context.Context, args []string) error {
+	dest, packages, err := sdk.ParseDownloadArgs(args)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Downloading packages to %s: %s\n", dest, strings.Join(packages, ", "))
+	cmdArgs := append([]string{"download", "--resolve", "--alldeps", "--destdir", dest}, packages...)
+	return sdk.ExecCommandWithContext(ctx, false, "dnf", cmdArgs...)
+}
+
+func (p *DnfPlugin) handleIsInstalled(ctx context.Context, args []string) error {
+	return p.CheckInstalled(ctx, args, p.isPackageInstalled)
+}
+
+// isPackageInstalled queries the local package database with `rpm -q`
+func (p *DnfPlugin) isPackageInstalled(ctx context.Context, pkg string) (bool, error) {
+	return sdk.CheckCommandWithContext(ctx, "rpm", "-q", pkg)
+}
+
+// availableVersions lists every version of a package offered by the enabled repositories
+func (p *DnfPlugin) availableVersions(ctx context.Context, pkg string) ([]sdk.PackageInfo, error) {
+	output, err := sdk.ExecCommandOutputWithContext(ctx, "dnf", "repoquery", "--quiet", "--available", "--qf", "%{evr} %{repoid}\n", pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []sdk.PackageInfo
+	for _, entry := range parseRepoquery(output) {
+		versions = append(versions, sdk.PackageInfo{
+			Name:    pkg,
+			Version: entry[0],
+			Source:  entry[1],
+			Spec:    pkg + "-" + entry[0],
+		})
+	}
+	return versions, nil
+}
+
+// installedVersion reports the installed version of a package and the repository it was installed from
+func (p *DnfPlugin) installedVersion(ctx context.Context, pkg string) (sdk.PackageInfo, error) {
+	output, err := sdk.ExecCommandOutputWithContext(ctx, "dnf", "repoquery", "--quiet", "--installed", "--qf", "%{evr} %{from_repo}\n", pkg)
+	if err != nil {
+		return sdk.PackageInfo{}, err
+	}
+
+	entries := parseRepoquery(output)
+	if len(entries) == 0 {
+		return sdk.PackageInfo{}, sdk.ErrNotInstalled
+	}
+	latest := entries[len(entries)-1]
+	return sdk.PackageInfo{Name: pkg, Version: latest[0], Source: latest[1]}, nil
+}
+
+// parseRepoquery splits "evr repository" lines produced by dnf repoquery
+func parseRepoquery(output string) [][2]string {
+	var entries [][2]string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		entry := [2]string{fields[0], ""}
+		if len(fields) > 1 {
+			entry[1] = fields[1]
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
 func main() {
 	plugin := NewDnfPlugin()
 	sdk.HandleArgs(plugin, os.Args[1:])
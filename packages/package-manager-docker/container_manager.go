@@ -8,46 +8,116 @@ import (
 	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
 )
 
-// handleInstall handles container installation/running
+// handleInstall runs containers from images or from a container spec. Running the same spec
+// again is a no-op, and containers of a stack run as services of its compose project.
 func (d *DockerInstaller) handleInstall(ctx context.Context, args []string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("no image specified")
+	for _, arg := range args {
+		if arg == "--engine" {
+			return d.handleEnsureInstalled(ctx, nil)
+		}
 	}
 
-	image := args[0]
-	d.logger.Printf("Running container from image: %s\n", image)
-
-	// Build docker run command
-	runCmd := []string{"run", "-d"}
+	specs, err := parseInstallArgs(args)
+	if err != nil {
+		return err
+	}
+	for _, spec := range specs {
+		if err := d.ValidateContainerSpec(spec); err != nil {
+			return err
+		}
+	}
 
-	// Add image
-	runCmd = append(runCmd, image)
+	for _, spec := range specs {
+		if spec.Stack != "" {
+			err = d.runStackService(ctx, spec)
+		} else {
+			err = d.runContainer(ctx, spec)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	if err := sdk.ExecCommandWithContext(ctx, false, "docker", runCmd...); err != nil {
-		return fmt.Errorf("failed to run container: %w", err)
+// handleIsInstalled checks whether a container exists for each argument, matching the
+// devex-managed containers by name, image or application before plain container names
+func (d *DockerInstaller) handleIsInstalled(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no containers specified")
 	}
 
-	d.logger.Success("Container started successfully")
+	for _, ref := range args {
+		containers, err := d.findManagedContainers(ctx, ref)
+		if err != nil {
+			return err
+		}
+		if len(containers) > 0 {
+			for _, container := range containers {
+				state := "stopped"
+				if container.Running {
+					state = "running"
+				}
+				d.logger.Printf("%s is installed as container %s (%s)\n", ref, container.Name, state)
+			}
+			continue
+		}
+
+		exists, err := containerExists(ctx, ref)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%s: %w", ref, sdk.ErrNotInstalled)
+		}
+		d.logger.Printf("%s is installed\n", ref)
+	}
 	return nil
 }
 
-// handleRemove removes containers
+// handleRemove removes containers. Devex-managed containers can be referred to by name, image
+// or application; services of a stack are removed from its compose project.
 func (d *DockerInstaller) handleRemove(ctx context.Context, args []string) error {
-	if len(args) == 0 {
+	var refs []string
+	volumes := false
+	for _, arg := range args {
+		switch arg {
+		case "--volumes":
+			volumes = true
+		case "--force":
+			// Containers are always stopped before removal
+		default:
+			refs = append(refs, arg)
+		}
+	}
+	if len(refs) == 0 {
 		return fmt.Errorf("no containers specified")
 	}
 
-	d.logger.Printf("Removing containers: %s\n", strings.Join(args, ", "))
+	d.logger.Printf("Removing containers: %s\n", strings.Join(refs, ", "))
 
-	for _, container := range args {
-		// Stop container first
-		if err := sdk.ExecCommandWithContext(ctx, false, "docker", "stop", container); err != nil {
-			d.logger.Warning("Failed to stop container %s: %v", container, err)
+	for _, ref := range refs {
+		containers, err := d.findManagedContainers(ctx, ref)
+		if err != nil {
+			return err
+		}
+		if len(containers) == 0 {
+			// Not managed by devex, remove the container of that name
+			if err := d.removeContainer(ctx, ref, volumes); err != nil {
+				return err
+			}
+			continue
 		}
 
-		// Remove container
-		if err := sdk.ExecCommandWithContext(ctx, false, "docker", "rm", container); err != nil {
-			return fmt.Errorf("failed to remove container %s: %w", container, err)
+		for _, container := range containers {
+			if container.Stack != "" && container.Service != "" {
+				err = d.removeStackService(ctx, container, volumes)
+			} else {
+				err = d.removeContainer(ctx, container.Name, volumes)
+			}
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -55,6 +125,18 @@ func (d *DockerInstaller) handleRemove(ctx context.Context, args []string) error
 	return nil
 }
 
+// removeContainer stops and removes a single container
+func (d *DockerInstaller) removeContainer(ctx context.Context, name string, volumes bool) error {
+	rmArgs := []string{"rm", "-f"}
+	if volumes {
+		rmArgs = append(rmArgs, "-v")
+	}
+	if err := sdk.ExecCommandWithContext(ctx, false, "docker", append(rmArgs, name)...); err != nil {
+		return fmt.Errorf("failed to remove container %s: %w", name, err)
+	}
+	return nil
+}
+
 // handleStart starts stopped containers
 func (d *DockerInstaller) handleStart(ctx context.Context, args []string) error {
 	if len(args) == 0 {
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+var (
+	envNameRegex       = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	restartPolicyRegex = regexp.MustCompile(`^(no|always|unless-stopped|on-failure(:\d+)?)$`)
+	durationRegex      = regexp.MustCompile(`^(\d+(ns|us|ms|s|m|h))+$`)
+)
+
+// managedContainer is a container found through the devex labels
+type managedContainer struct {
+	Name    string
+	Image   string
+	App     string
+	Stack   string
+	Service string
+	Running bool
+}
+
+// parseInstallArgs turns the install arguments into container specs. A --spec option carries a
+// full JSON spec for one image; otherwise each image gets a spec built from the --name, --port,
+// --env, --volume, --restart and --network flags.
+func parseInstallArgs(args []string) ([]*sdk.ContainerSpec, error) {
+	var images []string
+	var encoded string
+	flags := &sdk.ContainerSpec{}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "--") {
+			images = append(images, arg)
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		if name == "detach" {
+			// Containers always run in the background
+			continue
+		}
+		if !hasValue {
+			// Accept "--name value" as well as "--name=value"
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("option --%s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		switch name {
+		case sdk.ContainerSpecOption:
+			encoded = value
+		case "name":
+			flags.Name = value
+		case "port":
+			flags.Ports = append(flags.Ports, value)
+		case "env":
+			flags.Environment = append(flags.Environment, value)
+		case "volume":
+			flags.Volumes = append(flags.Volumes, value)
+		case "restart":
+			flags.RestartPolicy = value
+		case "network":
+			flags.Network = value
+		default:
+			return nil, fmt.Errorf("unknown install option: --%s", name)
+		}
+	}
+
+	if encoded != "" {
+		spec, err := sdk.DecodeContainerSpec(encoded)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case len(images) > 1:
+			return nil, fmt.Errorf("a container spec applies to a single image, got %d", len(images))
+		case len(images) == 1 && spec.Image == "":
+			spec.Image = images[0]
+		case len(images) == 1 && spec.Image != images[0]:
+			return nil, fmt.Errorf("container spec is for image %s, not %s", spec.Image, images[0])
+		}
+		return []*sdk.ContainerSpec{spec}, nil
+	}
+
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no image specified")
+	}
+	if flags.Name != "" && len(images) > 1 {
+		return nil, fmt.Errorf("--name applies to a single image, got %d", len(images))
+	}
+
+	specs := make([]*sdk.ContainerSpec, 0, len(images))
+	for _, image := range images {
+		spec := *flags
+		spec.Image = image
+		specs = append(specs, &spec)
+	}
+	return specs, nil
+}
+
+// ValidateContainerSpec checks every field of a container spec before anything is run
+func (d *DockerInstaller) ValidateContainerSpec(spec *sdk.ContainerSpec) error {
+	if err := d.ValidateImageName(spec.Image); err != nil {
+		return err
+	}
+	if err := d.ValidateContainerName(spec.ContainerName()); err != nil {
+		return err
+	}
+	for _, port := range spec.Ports {
+		if err := d.ValidatePortMapping(port); err != nil {
+			return fmt.Errorf("port %q: %w", port, err)
+		}
+	}
+	for _, env := range spec.Environment {
+		name, _, _ := strings.Cut(env, "=")
+		if !envNameRegex.MatchString(name) || strings.ContainsRune(env, 0) {
+			return fmt.Errorf("invalid environment variable %q: expected NAME=value", env)
+		}
+	}
+	for _, volume := range spec.Volumes {
+		if !strings.Contains(volume, ":") || strings.ContainsRune(volume, 0) || strings.HasPrefix(volume, "-") {
+			return fmt.Errorf("invalid volume %q: expected source:target[:options]", volume)
+		}
+	}
+	if spec.RestartPolicy != "" && !restartPolicyRegex.MatchString(spec.RestartPolicy) {
+		return fmt.Errorf("invalid restart policy %q: expected no, always, unless-stopped or on-failure[:N]", spec.RestartPolicy)
+	}
+	if spec.Network != "" {
+		if err := d.ValidateContainerName(spec.Network); err != nil {
+			return fmt.Errorf("invalid network name: %w", err)
+		}
+	}
+	if spec.Stack != "" && !stackNameRegex.MatchString(spec.Stack) {
+		return fmt.Errorf("invalid stack name %q: use lowercase letters, digits, hyphens and underscores", spec.Stack)
+	}
+	if hc := spec.Healthcheck; hc != nil {
+		if strings.TrimSpace(hc.Test) == "" {
+			return fmt.Errorf("healthcheck requires a test command")
+		}
+		for _, duration := range []string{hc.Interval, hc.Timeout, hc.StartPeriod} {
+			if duration != "" && !durationRegex.MatchString(duration) {
+				return fmt.Errorf("invalid healthcheck duration %q", duration)
+			}
+		}
+		if hc.Retries < 0 {
+			return fmt.Errorf("healthcheck retries cannot be negative")
+		}
+	}
+	return nil
+}
+
+// RunArgs builds the docker run arguments for a spec, labelled so devex can find the
+// container again
+func RunArgs(spec *sdk.ContainerSpec) []string {
+	args := []string{"run", "-d", "--name", spec.ContainerName()}
+
+	labels := spec.Labels()
+	for _, key := range sortedKeys(labels) {
+		args = append(args, "--label", key+"="+labels[key])
+	}
+	for _, port := range spec.Ports {
+		args = append(args, "-p", port)
+	}
+	for _, env := range spec.Environment {
+		args = append(args, "-e", env)
+	}
+	for _, volume := range spec.Volumes {
+		args = append(args, "-v", volume)
+	}
+	if spec.RestartPolicy != "" {
+		args = append(args, "--restart", spec.RestartPolicy)
+	}
+	if spec.Network != "" {
+		args = append(args, "--network", spec.Network)
+	}
+	if hc := spec.Healthcheck; hc != nil {
+		args = append(args, "--health-cmd", hc.Test)
+		if hc.Interval != "" {
+			args = append(args, "--health-interval", hc.Interval)
+		}
+		if hc.Timeout != "" {
+			args = append(args, "--health-timeout", hc.Timeout)
+		}
+		if hc.StartPeriod != "" {
+			args = append(args, "--health-start-period", hc.StartPeriod)
+		}
+		if hc.Retries > 0 {
+			args = append(args, "--health-retries", fmt.Sprint(hc.Retries))
+		}
+	}
+
+	args = append(args, spec.Image)
+	return append(args, spec.Command...)
+}
+
+// runContainer starts the container of a spec. An existing container created from the same
+// spec is left alone (and started if stopped); a managed container created from a different
+// spec is recreated.
+func (d *DockerInstaller) runContainer(ctx context.Context, spec *sdk.ContainerSpec) error {
+	name := spec.ContainerName()
+
+	output, err := sdk.ExecCommandOutputWithContext(ctx, "docker", "ps", "-a",
+		"--filter", "name=^/?"+regexp.QuoteMeta(name)+"$",
+		"--format", `{{.Label "`+sdk.ContainerLabelManaged+`"}}	{{.Label "`+sdk.ContainerLabelSpec+`"}}	{{.State}}`)
+	if err != nil {
+		return fmt.Errorf("failed to look up container %s: %w", name, err)
+	}
+
+	if line := strings.TrimSpace(output); line != "" {
+		fields := strings.Split(line, "\t")
+		for len(fields) < 3 {
+			fields = append(fields, "")
+		}
+		if fields[0] != "true" {
+			return fmt.Errorf("container %s already exists and is not managed by devex; remove it or choose another container name", name)
+		}
+		if fields[1] == spec.Hash() {
+			if fields[2] != "running" {
+				if err := sdk.ExecCommandWithContext(ctx, false, "docker", "start", name); err != nil {
+					return fmt.Errorf("failed to start container %s: %w", name, err)
+				}
+			}
+			d.logger.Success("Container %s is up to date", name)
+			return nil
+		}
+
+		d.logger.Printf("Configuration of %s changed, recreating the container\n", name)
+		if err := sdk.ExecCommandWithContext(ctx, false, "docker", "rm", "-f", name); err != nil {
+			return fmt.Errorf("failed to remove outdated container %s: %w", name, err)
+		}
+	}
+
+	if spec.Network != "" {
+		if err := d.ensureNetwork(ctx, spec.Network); err != nil {
+			return err
+		}
+	}
+
+	d.logger.Printf("Running container %s from image %s\n", name, spec.Image)
+	if err := sdk.ExecCommandWithContext(ctx, false, "docker", RunArgs(spec)...); err != nil {
+		return fmt.Errorf("failed to run container %s: %w", name, err)
+	}
+	d.logger.Success("Container %s started", name)
+	return nil
+}
+
+// ensureNetwork creates a user-defined network unless it already exists
+func (d *DockerInstaller) ensureNetwork(ctx context.Context, network string) error {
+	if _, err := sdk.ExecCommandOutputWithContext(ctx, "docker", "network", "inspect", network); err == nil {
+		return nil
+	}
+	if err := sdk.ExecCommandWithContext(ctx, false, "docker", "network", "create",
+		"--label", sdk.ContainerLabelManaged+"=true", network); err != nil {
+		return fmt.Errorf("failed to create network %s: %w", network, err)
+	}
+	return nil
+}
+
+// findManagedContainers returns the devex-managed containers whose name, image or app is ref
+func (d *DockerInstaller) findManagedContainers(ctx context.Context, ref string) ([]managedContainer, error) {
+	output, err := sdk.ExecCommandOutputWithContext(ctx, "docker", "ps", "-a",
+		"--filter", "label="+sdk.ContainerLabelManaged+"=true",
+		"--format", `{{.Names}}	{{.Label "`+sdk.ContainerLabelImage+`"}}	{{.Label "`+sdk.ContainerLabelApp+`"}}	{{.Label "`+
+			sdk.ContainerLabelStack+`"}}	{{.Label "com.docker.compose.service"}}	{{.State}}`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	var matches []managedContainer
+	for _, container := range parseManagedContainers(output) {
+		if container.Name == ref || container.Image == ref || (container.App != "" && container.App == ref) {
+			matches = append(matches, container)
+		}
+	}
+	return matches, nil
+}
+
+// parseManagedContainers parses the tab-separated docker ps output used by findManagedContainers
+func parseManagedContainers(output string) []managedContainer {
+	var containers []managedContainer
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		for len(fields) < 6 {
+			fields = append(fields, "")
+		}
+		containers = append(containers, managedContainer{
+			Name:    fields[0],
+			Image:   fields[1],
+			App:     fields[2],
+			Stack:   fields[3],
+			Service: fields[4],
+			Running: fields[5] == "running",
+		})
+	}
+	return containers
+}
+
+// containerExists reports whether a container with exactly this name exists
+func containerExists(ctx context.Context, name string) (bool, error) {
+	output, err := sdk.ExecCommandOutputWithContext(ctx, "docker", "ps", "-a", "-q",
+		"--filter", "name=^/?"+regexp.QuoteMeta(name)+"$")
+	if err != nil {
+		return false, fmt.Errorf("failed to look up container %s: %w", name, err)
+	}
+	return strings.TrimSpace(output) != "", nil
+}
@@ -0,0 +1,70 @@
+package main_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	main "github.com/jameswlane/devex/packages/package-manager-docker"
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+var _ = Describe("Container Specs", func() {
+	var (
+		dockerInstaller *main.DockerInstaller
+		spec            *sdk.ContainerSpec
+	)
+
+	BeforeEach(func() {
+		dockerInstaller = main.NewDockerPlugin()
+		spec = &sdk.ContainerSpec{
+			App:           "postgresql",
+			Image:         "postgres:16",
+			Ports:         []string{"5432:5432"},
+			Environment:   []string{"POSTGRES_PASSWORD=postgres"},
+			Volumes:       []string{"postgres-data:/var/lib/postgresql/data"},
+			RestartPolicy: "unless-stopped",
+			Healthcheck:   &sdk.ContainerHealthcheck{Test: "pg_isready -U postgres", Interval: "10s", Retries: 5},
+		}
+	})
+
+	Describe("RunArgs", func() {
+		It("passes every option to docker run and labels the container", func() {
+			args := main.RunArgs(spec)
+
+			Expect(args[:4]).To(Equal([]string{"run", "-d", "--name", "devex-postgresql"}))
+			Expect(args).To(ContainElements("sh.devex.managed=true", "sh.devex.app=postgresql", "sh.devex.spec="+spec.Hash()))
+			Expect(args).To(ContainElements("5432:5432", "POSTGRES_PASSWORD=postgres", "postgres-data:/var/lib/postgresql/data"))
+			Expect(args).To(ContainElements("--restart", "unless-stopped", "--health-cmd", "pg_isready -U postgres", "--health-retries", "5"))
+			Expect(args[len(args)-1]).To(Equal("postgres:16"))
+		})
+
+		It("produces the same arguments for the same spec", func() {
+			Expect(main.RunArgs(spec)).To(Equal(main.RunArgs(spec)))
+		})
+
+		It("appends the command after the image", func() {
+			spec.Command = []string{"redis-server", "--appendonly", "yes"}
+			args := main.RunArgs(spec)
+			Expect(args[len(args)-4:]).To(Equal([]string{"postgres:16", "redis-server", "--appendonly", "yes"}))
+		})
+	})
+
+	Describe("ValidateContainerSpec", func() {
+		It("accepts a complete spec", func() {
+			Expect(dockerInstaller.ValidateContainerSpec(spec)).To(Succeed())
+		})
+
+		DescribeTable("rejects invalid fields",
+			func(modify func(*sdk.ContainerSpec), message string) {
+				modify(spec)
+				Expect(dockerInstaller.ValidateContainerSpec(spec)).To(MatchError(ContainSubstring(message)))
+			},
+			Entry("port", func(s *sdk.ContainerSpec) { s.Ports = []string{"80:80;rm"} }, "invalid port mapping"),
+			Entry("environment", func(s *sdk.ContainerSpec) { s.Environment = []string{"1BAD=x"} }, "invalid environment variable"),
+			Entry("volume", func(s *sdk.ContainerSpec) { s.Volumes = []string{"data"} }, "invalid volume"),
+			Entry("restart policy", func(s *sdk.ContainerSpec) { s.RestartPolicy = "sometimes" }, "invalid restart policy"),
+			Entry("stack", func(s *sdk.ContainerSpec) { s.Stack = "My Stack" }, "invalid stack name"),
+			Entry("healthcheck", func(s *sdk.ContainerSpec) { s.Healthcheck.Interval = "soon" }, "invalid healthcheck duration"),
+		)
+	})
+})
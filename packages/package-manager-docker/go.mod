@@ -6,6 +6,7 @@ require (
 	github.com/jameswlane/devex/packages/plugin-sdk v0.0.1
 	github.com/onsi/ginkgo/v2 v2.25.2
 	github.com/onsi/gomega v1.38.2
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 replace github.com/jameswlane/devex/packages/plugin-sdk => ../plugin-sdk
@@ -3,6 +3,7 @@ package main
 // Build timestamp: 2025-09-06
 
 import (
+	_ "embed"
 	"fmt"
 	"os"
 
@@ -11,6 +12,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // NewDockerPlugin creates a new Docker plugin
 func NewDockerPlugin() *DockerInstaller {
 	info := sdk.PluginInfo{
@@ -20,24 +26,33 @@ func NewDockerPlugin() *DockerInstaller {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"package-manager", "docker", "containers", "linux"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "install",
 				Description: "Install and run Docker containers",
 				Usage:       "Install Docker containers or Docker Engine",
 				Flags: map[string]string{
-					"name":   "Name for the container",
-					"port":   "Port mapping (e.g., 8080:80)",
-					"env":    "Environment variables",
-					"volume": "Volume mounts",
-					"detach": "Run container in background",
-					"engine": "Install Docker Engine instead of containers",
+					"name":    "Name for the container",
+					"port":    "Port mapping (e.g., 8080:80)",
+					"env":     "Environment variables",
+					"volume":  "Volume mounts",
+					"restart": "Restart policy (no, always, unless-stopped, on-failure[:N])",
+					"network": "Network to connect the container to",
+					"spec":    "Full container specification as JSON",
+					"detach":  "Run container in background",
+					"engine":  "Install Docker Engine instead of containers",
 				},
 			},
+			{
+				Name:        "is-installed",
+				Description: "Check if containers exist",
+				Usage:       "Check for containers by name, or devex-managed containers by image or application",
+			},
 			{
 				Name:        "remove",
 				Description: "Remove Docker containers",
-				Usage:       "Remove Docker containers from the system",
+				Usage:       "Remove containers by name, or devex-managed containers by image or application",
 				Flags: map[string]string{
 					"force":   "Force removal of running containers",
 					"volumes": "Remove associated volumes",
@@ -141,6 +156,14 @@ func NewDockerPlugin() *DockerInstaller {
 					"detach":  "Run in background",
 				},
 			},
+			{
+				Name:        "stack",
+				Description: "Manage devex service stacks",
+				Usage:       "List, start, stop or inspect the compose projects devex manages (list, up, down, ps)",
+				Flags: map[string]string{
+					"volumes": "Also remove the volumes of the stack (down)",
+				},
+			},
 		},
 	}
 
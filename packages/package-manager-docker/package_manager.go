@@ -27,6 +27,11 @@ type DockerInstaller struct {
 	logger sdk.Logger
 }
 
+// SetLogger sets the logger for the Docker installer
+func (d *DockerInstaller) SetLogger(logger sdk.Logger) {
+	d.logger = logger
+}
+
 // Execute handles command execution
 func (d *DockerInstaller) Execute(command string, args []string) error {
 	ctx := context.Background()
@@ -46,6 +51,8 @@ func (d *DockerInstaller) Execute(command string, args []string) error {
 		return d.handleInstall(ctx, args)
 	case "remove":
 		return d.handleRemove(ctx, args)
+	case "is-installed":
+		return d.handleIsInstalled(ctx, args)
 	case "start":
 		return d.handleStart(ctx, args)
 	case "stop":
@@ -70,6 +77,8 @@ func (d *DockerInstaller) Execute(command string, args []string) error {
 		return d.handleRmi(ctx, args)
 	case "compose":
 		return d.handleCompose(ctx, args)
+	case "stack":
+		return d.handleStack(ctx, args)
 	default:
 		return fmt.Errorf("unknown command: '%s'", command)
 	}
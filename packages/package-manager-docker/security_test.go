@@ -14,6 +14,11 @@ var _ = Describe("Docker Security", func() {
 
 	BeforeEach(func() {
 		dockerInstaller = main.NewDockerPlugin()
+
+		// Execute exits the process when docker is missing
+		if !dockerInstaller.IsAvailable() {
+			Skip("requires docker to be installed")
+		}
 	})
 
 	Describe("Basic Security Validation", func() {
@@ -0,0 +1,437 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+var stackNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// composeFile is the subset of the compose specification devex writes for its stacks
+type composeFile struct {
+	Name     string                     `yaml:"name"`
+	Services map[string]*composeService `yaml:"services"`
+	Volumes  map[string]*composeVolume  `yaml:"volumes,omitempty"`
+	Networks map[string]*composeNetwork `yaml:"networks,omitempty"`
+}
+
+type composeService struct {
+	Image         string              `yaml:"image"`
+	ContainerName string              `yaml:"container_name"`
+	Command       []string            `yaml:"command,omitempty"`
+	Ports         []string            `yaml:"ports,omitempty"`
+	Environment   []string            `yaml:"environment,omitempty"`
+	Volumes       []string            `yaml:"volumes,omitempty"`
+	Restart       string              `yaml:"restart,omitempty"`
+	Networks      []string            `yaml:"networks,omitempty"`
+	Healthcheck   *composeHealthcheck `yaml:"healthcheck,omitempty"`
+	Labels        map[string]string   `yaml:"labels"`
+}
+
+type composeHealthcheck struct {
+	Test        []string `yaml:"test"`
+	Interval    string   `yaml:"interval,omitempty"`
+	Timeout     string   `yaml:"timeout,omitempty"`
+	StartPeriod string   `yaml:"start_period,omitempty"`
+	Retries     int      `yaml:"retries,omitempty"`
+}
+
+type composeVolume struct {
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+type composeNetwork struct {
+	Name   string            `yaml:"name"`
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+// stacksDir returns the directory holding the compose files of devex-managed stacks
+func stacksDir() (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, "devex", "stacks"), nil
+}
+
+// stackFilePath returns the compose file of a stack
+func stackFilePath(stack string) (string, error) {
+	if !stackNameRegex.MatchString(stack) {
+		return "", fmt.Errorf("invalid stack name %q: use lowercase letters, digits, hyphens and underscores", stack)
+	}
+	dir, err := stacksDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, stack, "compose.yaml"), nil
+}
+
+// stackProject returns the compose project name of a stack
+func stackProject(stack string) string {
+	return "devex-" + stack
+}
+
+// stackServiceName returns the compose service name of a spec
+func stackServiceName(spec *sdk.ContainerSpec) string {
+	return strings.TrimPrefix(spec.ContainerName(), "devex-")
+}
+
+// loadStack reads the compose file of a stack, returning an empty stack if it does not exist
+func loadStack(stack string) (*composeFile, string, error) {
+	path, err := stackFilePath(stack)
+	if err != nil {
+		return nil, "", err
+	}
+
+	compose := &composeFile{Name: stackProject(stack), Services: make(map[string]*composeService)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return compose, path, nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read stack %s: %w", stack, err)
+	}
+	if err := yaml.Unmarshal(data, compose); err != nil {
+		return nil, "", fmt.Errorf("failed to parse stack %s: %w", stack, err)
+	}
+	if compose.Services == nil {
+		compose.Services = make(map[string]*composeService)
+	}
+	return compose, path, nil
+}
+
+// saveStack writes the compose file of a stack
+func saveStack(compose *composeFile, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create stack directory: %w", err)
+	}
+	data, err := yaml.Marshal(compose)
+	if err != nil {
+		return fmt.Errorf("failed to encode stack: %w", err)
+	}
+	// The file may hold credentials passed as environment variables
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write stack: %w", err)
+	}
+	return nil
+}
+
+// addServiceToStack adds or replaces the service of a spec in a compose file, declaring the named
+// volumes and the network it uses
+func addServiceToStack(compose *composeFile, spec *sdk.ContainerSpec) string {
+	managed := map[string]string{sdk.ContainerLabelManaged: "true", sdk.ContainerLabelStack: spec.Stack}
+
+	service := &composeService{
+		Image:         spec.Image,
+		ContainerName: spec.ContainerName(),
+		Command:       spec.Command,
+		Ports:         spec.Ports,
+		Environment:   spec.Environment,
+		Volumes:       spec.Volumes,
+		Restart:       spec.RestartPolicy,
+		Labels:        spec.Labels(),
+	}
+	if hc := spec.Healthcheck; hc != nil {
+		service.Healthcheck = &composeHealthcheck{
+			Test:        []string{"CMD-SHELL", hc.Test},
+			Interval:    hc.Interval,
+			Timeout:     hc.Timeout,
+			StartPeriod: hc.StartPeriod,
+			Retries:     hc.Retries,
+		}
+	}
+
+	for _, volume := range spec.Volumes {
+		source, _, _ := strings.Cut(volume, ":")
+		if source == "" || strings.ContainsAny(source[:1], "/.~") {
+			continue // bind mount
+		}
+		if compose.Volumes == nil {
+			compose.Volumes = make(map[string]*composeVolume)
+		}
+		compose.Volumes[source] = &composeVolume{Labels: managed}
+	}
+
+	if spec.Network != "" {
+		// Services on a custom network keep the default one so the stack can still reach them
+		service.Networks = []string{"default", spec.Network}
+		if compose.Networks == nil {
+			compose.Networks = make(map[string]*composeNetwork)
+		}
+		compose.Networks[spec.Network] = &composeNetwork{Name: spec.Network, Labels: managed}
+	}
+
+	name := stackServiceName(spec)
+	compose.Services[name] = service
+	return name
+}
+
+// removeServiceFromStack removes a service from a compose file along with the volumes and networks
+// no other service uses. It reports whether the service was part of the stack and returns the
+// named volumes that were dropped.
+func removeServiceFromStack(compose *composeFile, name string) (bool, []string) {
+	if _, ok := compose.Services[name]; !ok {
+		return false, nil
+	}
+	delete(compose.Services, name)
+
+	usedVolumes := make(map[string]bool)
+	usedNetworks := make(map[string]bool)
+	for _, service := range compose.Services {
+		for _, volume := range service.Volumes {
+			source, _, _ := strings.Cut(volume, ":")
+			usedVolumes[source] = true
+		}
+		for _, network := range service.Networks {
+			usedNetworks[network] = true
+		}
+	}
+
+	var dropped []string
+	for _, volume := range sortedKeys(compose.Volumes) {
+		if !usedVolumes[volume] {
+			delete(compose.Volumes, volume)
+			dropped = append(dropped, volume)
+		}
+	}
+	for network := range compose.Networks {
+		if !usedNetworks[network] {
+			delete(compose.Networks, network)
+		}
+	}
+	return true, dropped
+}
+
+// composeCommand returns the command and leading arguments that run Docker Compose, preferring
+// the compose plugin over the standalone docker-compose
+func composeCommand(ctx context.Context) (string, []string, error) {
+	if !sdk.CommandExists("docker") {
+		return "", nil, fmt.Errorf("docker is not installed")
+	}
+	if _, err := sdk.ExecCommandOutputWithContext(ctx, "docker", "compose", "version"); err == nil {
+		return "docker", []string{"compose"}, nil
+	}
+	if sdk.CommandExists("docker-compose") {
+		return "docker-compose", nil, nil
+	}
+	return "", nil, fmt.Errorf("docker compose is not available")
+}
+
+// runStackCompose runs a Docker Compose command against the compose file of a stack
+func (d *DockerInstaller) runStackCompose(ctx context.Context, stack, path string, args ...string) error {
+	command, prefix, err := composeCommand(ctx)
+	if err != nil {
+		return err
+	}
+	composeArgs := append(prefix, "-p", stackProject(stack), "-f", path)
+	return sdk.ExecCommandWithContext(ctx, false, command, append(composeArgs, args...)...)
+}
+
+// runStackService adds the service of a spec to its stack and brings it up. Compose recreates
+// the container only when its configuration changed.
+func (d *DockerInstaller) runStackService(ctx context.Context, spec *sdk.ContainerSpec) error {
+	compose, path, err := loadStack(spec.Stack)
+	if err != nil {
+		return err
+	}
+
+	name := spec.ContainerName()
+	if exists, err := containerExists(ctx, name); err != nil {
+		return err
+	} else if exists {
+		containers, err := d.findManagedContainers(ctx, name)
+		if err != nil {
+			return err
+		}
+		if len(containers) == 0 {
+			return fmt.Errorf("container %s already exists and is not managed by devex; remove it or choose another container name", name)
+		}
+		if containers[0].Stack != spec.Stack {
+			// Compose cannot adopt a container it did not create
+			if err := sdk.ExecCommandWithContext(ctx, false, "docker", "rm", "-f", name); err != nil {
+				return fmt.Errorf("failed to remove container %s: %w", name, err)
+			}
+		}
+	}
+
+	service := addServiceToStack(compose, spec)
+	if err := saveStack(compose, path); err != nil {
+		return err
+	}
+
+	d.logger.Printf("Starting %s in stack %s\n", service, spec.Stack)
+	if err := d.runStackCompose(ctx, spec.Stack, path, "up", "-d", service); err != nil {
+		return fmt.Errorf("failed to start %s in stack %s: %w", service, spec.Stack, err)
+	}
+	d.logger.Success("Service %s is running in stack %s", service, spec.Stack)
+	return nil
+}
+
+// removeStackService stops and removes the service of a container, tearing the stack down when
+// it was the last one
+func (d *DockerInstaller) removeStackService(ctx context.Context, container managedContainer, volumes bool) error {
+	stack, service := container.Stack, container.Service
+	compose, path, err := loadStack(stack)
+	if err != nil {
+		return err
+	}
+
+	found, dropped := removeServiceFromStack(compose, service)
+	if !found {
+		// The compose file no longer lists the service; remove the container directly
+		return sdk.ExecCommandWithContext(ctx, false, "docker", "rm", "-f", container.Name)
+	}
+
+	if len(compose.Services) == 0 {
+		args := []string{"down", "--remove-orphans"}
+		if volumes {
+			args = append(args, "--volumes")
+		}
+		if err := d.runStackCompose(ctx, stack, path, args...); err != nil {
+			return fmt.Errorf("failed to stop stack %s: %w", stack, err)
+		}
+		if err := os.RemoveAll(filepath.Dir(path)); err != nil {
+			return fmt.Errorf("failed to remove stack %s: %w", stack, err)
+		}
+		d.logger.Success("Removed stack %s", stack)
+		return nil
+	}
+
+	args := []string{"rm", "--stop", "--force"}
+	if volumes {
+		args = append(args, "--volumes")
+	}
+	if err := d.runStackCompose(ctx, stack, path, append(args, service)...); err != nil {
+		return fmt.Errorf("failed to remove %s from stack %s: %w", service, stack, err)
+	}
+	if volumes {
+		for _, volume := range dropped {
+			if err := sdk.ExecCommandWithContext(ctx, false, "docker", "volume", "rm", stackProject(stack)+"_"+volume); err != nil {
+				d.logger.Warning("Failed to remove volume %s: %v", volume, err)
+			}
+		}
+	}
+	return saveStack(compose, path)
+}
+
+// handleStack manages the compose stacks devex created: list, up, down and ps
+func (d *DockerInstaller) handleStack(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: stack <list|up|down|ps> [stack] [--volumes]")
+	}
+
+	action := args[0]
+	var stack string
+	volumes := false
+	for _, arg := range args[1:] {
+		switch {
+		case arg == "--volumes":
+			volumes = true
+		case strings.HasPrefix(arg, "-"):
+			return fmt.Errorf("unknown stack option: %s", arg)
+		case stack == "":
+			stack = arg
+		default:
+			return fmt.Errorf("unexpected argument: %s", arg)
+		}
+	}
+
+	if action == "list" {
+		stacks, err := listStacks()
+		if err != nil {
+			return err
+		}
+		if len(stacks) == 0 {
+			d.logger.Printf("No stacks managed by devex\n")
+			return nil
+		}
+		for _, name := range stacks {
+			compose, _, err := loadStack(name)
+			if err != nil {
+				return err
+			}
+			services := make([]string, 0, len(compose.Services))
+			for service := range compose.Services {
+				services = append(services, service)
+			}
+			sort.Strings(services)
+			d.logger.Printf("%s: %s\n", name, strings.Join(services, ", "))
+		}
+		return nil
+	}
+
+	if stack == "" {
+		return fmt.Errorf("no stack specified")
+	}
+	path, err := stackFilePath(stack)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("stack %s does not exist", stack)
+	}
+
+	switch action {
+	case "up":
+		return d.runStackCompose(ctx, stack, path, "up", "-d")
+	case "down":
+		downArgs := []string{"down"}
+		if volumes {
+			downArgs = append(downArgs, "--volumes")
+		}
+		return d.runStackCompose(ctx, stack, path, downArgs...)
+	case "ps":
+		return d.runStackCompose(ctx, stack, path, "ps")
+	default:
+		return fmt.Errorf("unknown stack action: %s", action)
+	}
+}
+
+// listStacks returns the names of the stacks with a compose file
+func listStacks() ([]string, error) {
+	dir, err := stacksDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stacks: %w", err)
+	}
+
+	var stacks []string
+	for _, entry := range entries {
+		if !entry.IsDir() || !stackNameRegex.MatchString(entry.Name()) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, entry.Name(), "compose.yaml")); err == nil {
+			stacks = append(stacks, entry.Name())
+		}
+	}
+	return stacks, nil
+}
+
+// sortedKeys returns the keys of a map in order so generated arguments are stable
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -4,6 +4,7 @@ package main
 
 import (
 	"context"
+	_ "embed"
 	"fmt"
 	"os"
 	"strings"
@@ -13,6 +14,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // EmergePlugin implements the Emerge package manager
 type EmergePlugin struct {
 	*sdk.PackageManagerPlugin
@@ -27,6 +33,7 @@ func NewEmergePlugin() *EmergePlugin {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"emerge", "portage", "gentoo", "linux"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "install",
@@ -53,6 +60,11 @@ func NewEmergePlugin() *EmergePlugin {
 				Description: "List packages",
 				Usage:       "List installed packages",
 			},
+			{
+				Name:        "is-installed",
+				Description: "Check if packages are installed",
+				Usage:       "Exits 0 if all packages are installed, 1 if any is missing and 2 if the check fails",
+			},
 		},
 	}
 
@@ -78,6 +90,8 @@ func (p *EmergePlugin) Execute(command string, args []string) error {
 		return p.handleSearch(ctx, args)
 	case "list":
 		return p.handleList(ctx, args)
+	case "is-installed":
+		return p.handleIsInstalled(ctx, args)
 	default:
 		return fmt.Errorf("unknown command: %s", command)
 	}
@@ -126,6 +140,15 @@ func (p *EmergePlugin) handleList(ctx context.Context, args []string) error {
 	return sdk.ExecCommandWithContext(ctx, false, "emerge", "list")
 }
 
+func (p *EmergePlugin) handleIsInstalled(ctx context.Context, args []string) error {
+	return p.CheckInstalled(ctx, args, p.isPackageInstalled)
+}
+
+// isPackageInstalled queries the local package database with `portageq has_version`
+func (p *EmergePlugin) isPackageInstalled(ctx context.Context, pkg string) (bool, error) {
+	return sdk.CheckCommandWithContext(ctx, "portageq", "has_version", "/", pkg)
+}
+
 func main() {
 	plugin := NewEmergePlugin()
 	sdk.HandleArgs(plugin, os.Args[1:])
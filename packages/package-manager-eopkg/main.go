@@ -4,6 +4,7 @@ package main
 
 import (
 	"context"
+	_ "embed"
 	"fmt"
 	"os"
 	"strings"
@@ -13,6 +14,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // EopkgPlugin implements the Eopkg package manager
 type EopkgPlugin struct {
 	*sdk.PackageManagerPlugin
@@ -27,6 +33,7 @@ func NewEopkgPlugin() *EopkgPlugin {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"eopkg", "solus", "linux"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "install",
@@ -53,6 +60,11 @@ func NewEopkgPlugin() *EopkgPlugin {
 				Description: "List packages",
 				Usage:       "List installed packages",
 			},
+			{
+				Name:        "is-installed",
+				Description: "Check if packages are installed",
+				Usage:       "Exits 0 if all packages are installed, 1 if any is missing and 2 if the check fails",
+			},
 		},
 	}
 
@@ -78,6 +90,8 @@ func (p *EopkgPlugin) Execute(command string, args []string) error {
 		return p.handleSearch(ctx, args)
 	case "list":
 		return p.handleList(ctx, args)
+	case "is-installed":
+		return p.handleIsInstalled(ctx, args)
 	default:
 		return fmt.Errorf("unknown command: %s", command)
 	}
@@ -126,6 +140,26 @@ func (p *EopkgPlugin) handleList(ctx context.Context, args []string) error {
 	return sdk.ExecCommandWithContext(ctx, false, "eopkg", "list")
 }
 
+func (p *EopkgPlugin) handleIsInstalled(ctx context.Context, args []string) error {
+	return p.CheckInstalled(ctx, args, p.isPackageInstalled)
+}
+
+// isPackageInstalled looks the package up in `eopkg list-installed`, whose lines start with the package name
+func (p *EopkgPlugin) isPackageInstalled(ctx context.Context, pkg string) (bool, error) {
+	output, err := sdk.ExecCommandOutputWithContext(ctx, "eopkg", "list-installed")
+	if err != nil {
+		return false, err
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == pkg {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func main() {
 	plugin := NewEopkgPlugin()
 	sdk.HandleArgs(plugin, os.Args[1:])
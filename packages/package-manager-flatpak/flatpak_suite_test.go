@@ -0,0 +1,14 @@
+package main_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// TestFlatpak runs the unit specs, and the integration specs when built with -tags integration
+func TestFlatpak(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Flatpak Package Manager Suite")
+}
@@ -3,9 +3,7 @@
 package main_test
 
 import (
-	"context"
 	"os"
-	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -15,16 +13,11 @@ import (
 
 var _ = Describe("Flatpak Package Manager Integration Tests", func() {
 	var (
-		plugin     *main.FlatpakInstaller
-		ctx        context.Context
-		cancelFunc context.CancelFunc
-		tmpDir     string
+		plugin *main.FlatpakInstaller
+		tmpDir string
 	)
 
 	BeforeEach(func() {
-		// Create context with timeout for all operations
-		ctx, cancelFunc = context.WithTimeout(context.Background(), 30*time.Second)
-
 		// Create temporary directory for test files
 		var err error
 		tmpDir, err = os.MkdirTemp("", "flatpak-integration-test-")
@@ -35,7 +28,6 @@ var _ = Describe("Flatpak Package Manager Integration Tests", func() {
 	})
 
 	AfterEach(func() {
-		cancelFunc()
 		if tmpDir != "" {
 			os.RemoveAll(tmpDir)
 		}
@@ -377,8 +369,8 @@ var _ = Describe("Flatpak Package Manager Integration Tests", func() {
 			It("should handle search with no results", func() {
 				Skip("Requires Flatpak with Flathub configured")
 
-				err := plugin.Execute("search", []string{"nonexistent-app-xyz-123"})
 				// Search might not fail even with no results
+				_ = plugin.Execute("search", []string{"nonexistent-app-xyz-123"})
 			})
 
 			It("should require search terms", func() {
@@ -459,9 +451,8 @@ var _ = Describe("Flatpak Package Manager Integration Tests", func() {
 			It("should check if applications are installed", func() {
 				Skip("Requires Flatpak with installed applications")
 
-				// Check a commonly installed runtime
-				err := plugin.Execute("is-installed", []string{"org.freedesktop.Platform"})
-				// This might succeed or fail depending on what's installed
+				// Check a commonly installed runtime; this might succeed or fail depending on what's installed
+				_ = plugin.Execute("is-installed", []string{"org.freedesktop.Platform"})
 			})
 
 			It("should fail for non-installed applications", func() {
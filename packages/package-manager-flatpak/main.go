@@ -3,6 +3,7 @@ package main
 // Build timestamp: 2025-09-06
 
 import (
+	_ "embed"
 	"fmt"
 	"os"
 
@@ -11,6 +12,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // NewFlatpakPlugin creates a new Flatpak plugin
 func NewFlatpakPlugin() *FlatpakInstaller {
 	info := sdk.PluginInfo{
@@ -20,6 +26,7 @@ func NewFlatpakPlugin() *FlatpakInstaller {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"package-manager", "flatpak", "universal", "linux", "sandboxed"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "install",
@@ -102,6 +109,11 @@ func NewFlatpakPlugin() *FlatpakInstaller {
 					"system": "Add system-wide",
 				},
 			},
+			{
+				Name:        "options",
+				Description: "List configured remotes as setup options",
+				Usage:       "Print the configured remotes as JSON options for setup questions (options remotes)",
+			},
 		},
 	}
 
@@ -59,6 +59,8 @@ func (f *FlatpakInstaller) Execute(command string, args []string) error {
 		return f.handleIsInstalled(ctx, args)
 	case "info":
 		return f.handleInfo(ctx, args)
+	case "options":
+		return f.handleOptions(ctx, args)
 	default:
 		return fmt.Errorf("unknown command: %s", command)
 	}
@@ -233,7 +235,7 @@ func (f *FlatpakInstaller) handleIsInstalled(ctx context.Context, args []string)
 		return fmt.Errorf("no applications specified")
 	}
 
-	allInstalled := true
+	var missing []string
 	for _, app := range args {
 		if err := f.validateAppID(app); err != nil {
 			return fmt.Errorf("invalid application ID '%s': %w", app, err)
@@ -248,12 +250,12 @@ func (f *FlatpakInstaller) handleIsInstalled(ctx context.Context, args []string)
 			f.logger.Success("Application %s is installed", app)
 		} else {
 			f.logger.ErrorMsg("Application %s is not installed", app)
-			allInstalled = false
+			missing = append(missing, app)
 		}
 	}
 
-	if !allInstalled {
-		return fmt.Errorf("one or more applications are not installed")
+	if len(missing) > 0 {
+		return &sdk.NotInstalledError{Packages: missing}
 	}
 	return nil
 }
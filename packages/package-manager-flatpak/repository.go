@@ -118,6 +118,59 @@ func (f *FlatpakInstaller) handleRemoteList(ctx context.Context, args []string)
 	return sdk.ExecCommandWithContext(ctx, false, "flatpak", "remote-list")
 }
 
+// handleOptions prints the configured remotes as setup options, preferring Flathub as the default
+func (f *FlatpakInstaller) handleOptions(ctx context.Context, args []string) error {
+	set, _, _, err := sdk.ParseOptionsArgs(args)
+	if err != nil {
+		return err
+	}
+	if set != "remotes" {
+		return fmt.Errorf("unknown option set %q, available: remotes", set)
+	}
+
+	output, err := sdk.ExecCommandOutputWithContext(ctx, "flatpak", "remotes", "--columns=name,title,url")
+	if err != nil {
+		return fmt.Errorf("failed to list remotes: %w", err)
+	}
+	return sdk.PrintOptions(ParseRemoteOptions(output))
+}
+
+// ParseRemoteOptions converts the tab-separated output of 'flatpak remotes --columns=name,title,url'
+// into options. A remote configured both system-wide and per user is listed once.
+func ParseRemoteOptions(output string) []sdk.OptionInfo {
+	var options []sdk.OptionInfo
+	seen := make(map[string]bool)
+	defaultIndex := -1
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\t")
+		name := strings.TrimSpace(fields[0])
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		option := sdk.OptionInfo{Value: name, Label: name}
+		if len(fields) > 1 && strings.TrimSpace(fields[1]) != "" {
+			option.Label = strings.TrimSpace(fields[1])
+		}
+		if len(fields) > 2 {
+			option.Description = strings.TrimSpace(fields[2])
+		}
+		if name == "flathub" {
+			defaultIndex = len(options)
+		}
+		options = append(options, option)
+	}
+
+	if defaultIndex < 0 && len(options) > 0 {
+		defaultIndex = 0
+	}
+	if defaultIndex >= 0 {
+		options[defaultIndex].Default = true
+	}
+	return options
+}
+
 // remoteExists checks if a remote repository exists
 func (f *FlatpakInstaller) remoteExists(ctx context.Context, remoteName string) (bool, error) {
 	output, err := sdk.ExecCommandOutputWithContext(ctx, "flatpak", "remote-list")
@@ -17,6 +17,13 @@ var _ = Describe("Flatpak Security", func() {
 	})
 
 	Describe("Input Validation Security", func() {
+		BeforeEach(func() {
+			// Execute exits the process when flatpak is missing
+			if !flatpakInstaller.IsAvailable() {
+				Skip("requires flatpak to be installed")
+			}
+		})
+
 		Context("command argument validation", func() {
 			It("should reject malicious flatpak commands", func() {
 				maliciousArgs := []string{
@@ -81,7 +88,31 @@ var _ = Describe("Flatpak Security", func() {
 		})
 	})
 
+	Describe("Remote options", func() {
+		It("lists each remote once and defaults to Flathub", func() {
+			output := "fedora\tFedora Flatpaks\toci+https://registry.fedoraproject.org\n" +
+				"flathub\tFlathub\thttps://dl.flathub.org/repo/\n" +
+				"flathub\tFlathub\thttps://dl.flathub.org/repo/\n"
+
+			options := main.ParseRemoteOptions(output)
+			Expect(options).To(HaveLen(2))
+			Expect(options[0].Value).To(Equal("fedora"))
+			Expect(options[0].Label).To(Equal("Fedora Flatpaks"))
+			Expect(options[0].Default).To(BeFalse())
+			Expect(options[1].Value).To(Equal("flathub"))
+			Expect(options[1].Description).To(Equal("https://dl.flathub.org/repo/"))
+			Expect(options[1].Default).To(BeTrue())
+		})
+	})
+
 	Describe("Error Message Security", func() {
+		BeforeEach(func() {
+			// Execute exits the process when flatpak is missing
+			if !flatpakInstaller.IsAvailable() {
+				Skip("requires flatpak to be installed")
+			}
+		})
+
 		Context("error message sanitization", func() {
 			It("should not leak sensitive information in error messages", func() {
 				maliciousInputs := []string{
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+// InstallsArchive is the tarball written by the download command. It holds the installs
+// directory of a mise data directory and is extracted into the data directory of the target.
+const InstallsArchive = "mise-installs.tar.gz"
+
+// HandleDownload installs tools into a scratch mise data directory and packs the installed
+// versions into --dest as InstallsArchive, for installation on a machine without network access
+func (m *MisePlugin) HandleDownload(ctx context.Context, args []string) error {
+	dest, tools, err := sdk.ParseDownloadArgs(args)
+	if err != nil {
+		return err
+	}
+	for _, tool := range tools {
+		if err := m.ValidateToolSpec(tool); err != nil {
+			return fmt.Errorf("invalid tool specification '%s': %w", tool, err)
+		}
+	}
+
+	dataDir, err := os.MkdirTemp("", "devex-mise-download-*")
+	if err != nil {
+		return fmt.Errorf("failed to create scratch data directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dataDir) }()
+
+	// The mise child processes inherit the scratch data directory
+	if err := os.Setenv("MISE_DATA_DIR", dataDir); err != nil {
+		return fmt.Errorf("failed to set MISE_DATA_DIR: %w", err)
+	}
+
+	m.logger.Printf("Downloading tools with Mise: %s\n", strings.Join(tools, ", "))
+	for _, tool := range tools {
+		if err := sdk.ExecCommandWithContext(ctx, false, "mise", "install", tool); err != nil {
+			return fmt.Errorf("failed to download tool '%s': %w", tool, err)
+		}
+	}
+
+	archive := filepath.Join(dest, InstallsArchive)
+	if err := writeInstallsArchive(filepath.Join(dataDir, "installs"), archive); err != nil {
+		_ = os.Remove(archive)
+		return fmt.Errorf("failed to pack installed tools: %w", err)
+	}
+
+	m.logger.Success("Downloaded tools to %s", archive)
+	return nil
+}
+
+// writeInstallsArchive packs the installs directory into a gzip-compressed tarball whose
+// entries start with "installs/"
+func writeInstallsArchive(installsDir, archive string) error {
+	out, err := os.Create(archive)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	root := filepath.Dir(installsDir)
+	walkErr := filepath.Walk(installsDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = file.Close() }()
+		_, err = io.Copy(tw, file)
+		return err
+	})
+
+	for _, closer := range []io.Closer{tw, gz, out} {
+		if err := closer.Close(); err != nil && walkErr == nil {
+			walkErr = err
+		}
+	}
+	return walkErr
+}
@@ -3,6 +3,7 @@ package main
 // Build timestamp: 2025-09-06
 
 import (
+	_ "embed"
 	"os"
 
 	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
@@ -10,6 +11,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // NewMisePlugin creates a new Mise plugin
 func NewMisePlugin() *MisePlugin {
 	info := sdk.PluginInfo{
@@ -19,6 +25,7 @@ func NewMisePlugin() *MisePlugin {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"package-manager", "mise", "tools", "development", "version-manager"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "install",
@@ -64,6 +71,29 @@ func NewMisePlugin() *MisePlugin {
 				Description: "Check if a tool is installed",
 				Usage:       "Returns exit code 0 if tool is installed, 1 if not",
 			},
+			{
+				Name:        "resolve",
+				Description: "Resolve a version constraint",
+				Usage:       "Print the newest remote version matching --constraint as JSON, with the tool spec that pins it",
+			},
+			{
+				Name:        "version",
+				Description: "Show active tool versions",
+				Usage:       "Print the active version of each tool as JSON",
+			},
+			{
+				Name:        "options",
+				Description: "List installable versions as setup options",
+				Usage:       "Print the newest remote versions of a tool as JSON options (options versions <tool> [prefix] [--limit=N])",
+			},
+			{
+				Name:        "download",
+				Description: "Download tools for offline installation",
+				Usage:       "Install tools into a scratch data directory and pack them into --dest as mise-installs.tar.gz",
+				Flags: map[string]string{
+					"dest": "Directory to write the tool archive to",
+				},
+			},
 		},
 	}
 
@@ -40,6 +40,14 @@ func (m *MisePlugin) Execute(command string, args []string) error {
 		return m.HandleEnsureInstalled(ctx, args)
 	case "is-installed":
 		return m.HandleIsInstalled(ctx, args)
+	case "resolve":
+		return m.HandleResolve(ctx, args)
+	case "version":
+		return m.HandleVersion(ctx, args)
+	case "options":
+		return m.HandleOptions(ctx, args)
+	case "download":
+		return m.HandleDownload(ctx, args)
 	default:
 		return fmt.Errorf("unknown command: %s", command)
 	}
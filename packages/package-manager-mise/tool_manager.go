@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
@@ -198,9 +199,122 @@ func (m *MisePlugin) HandleIsInstalled(ctx context.Context, args []string) error
 	// Check if tool is installed
 	output, err := sdk.ExecCommandOutputWithContext(ctx, "mise", "current", tool)
 	if err != nil || strings.TrimSpace(output) == "" {
-		return fmt.Errorf("tool '%s' is not installed", tool)
+		return fmt.Errorf("tool '%s' is %w", tool, sdk.ErrNotInstalled)
 	}
 
 	m.logger.Success("Tool %s is installed: %s", tool, strings.TrimSpace(output))
 	return nil
 }
+
+// HandleResolve prints the newest remote version of each tool matching --constraint.
+// A version in the tool specification (node@20) is ignored in favour of the constraint.
+func (m *MisePlugin) HandleResolve(ctx context.Context, args []string) error {
+	return m.ResolveVersions(ctx, args, func(ctx context.Context, toolSpec string) ([]sdk.PackageInfo, error) {
+		if err := m.ValidateToolSpec(toolSpec); err != nil {
+			return nil, fmt.Errorf("invalid tool specification '%s': %w", toolSpec, err)
+		}
+		tool := toolName(toolSpec)
+
+		output, err := sdk.ExecCommandOutputWithContext(ctx, "mise", "ls-remote", tool)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list remote versions of %s: %w", tool, err)
+		}
+
+		var versions []sdk.PackageInfo
+		for _, line := range strings.Split(output, "\n") {
+			version := strings.TrimSpace(line)
+			if version == "" {
+				continue
+			}
+			versions = append(versions, sdk.PackageInfo{
+				Name:    toolSpec,
+				Version: version,
+				Source:  "mise",
+				Spec:    tool + "@" + version,
+			})
+		}
+		return versions, nil
+	})
+}
+
+// HandleVersion prints the active version of each tool
+func (m *MisePlugin) HandleVersion(ctx context.Context, args []string) error {
+	return m.ReportVersions(ctx, args, func(ctx context.Context, toolSpec string) (sdk.PackageInfo, error) {
+		if err := m.ValidateToolSpec(toolSpec); err != nil {
+			return sdk.PackageInfo{}, fmt.Errorf("invalid tool specification '%s': %w", toolSpec, err)
+		}
+
+		output, err := sdk.ExecCommandOutputWithContext(ctx, "mise", "current", toolName(toolSpec))
+		version := strings.TrimSpace(output)
+		if err != nil || version == "" {
+			return sdk.PackageInfo{}, sdk.ErrNotInstalled
+		}
+		// mise current prints every active version separated by spaces; the first one wins
+		return sdk.PackageInfo{Name: toolSpec, Version: strings.Fields(version)[0], Source: "mise"}, nil
+	})
+}
+
+// defaultOptionLimit is the number of versions offered when options is called without --limit
+const defaultOptionLimit = 20
+
+// HandleOptions prints the newest remote versions of a tool, newest first, as setup options.
+// An optional prefix narrows the versions (options versions node 20).
+func (m *MisePlugin) HandleOptions(ctx context.Context, args []string) error {
+	set, flags, rest, err := sdk.ParseOptionsArgs(args)
+	if err != nil {
+		return err
+	}
+	if set != "versions" {
+		return fmt.Errorf("unknown option set %q, available: versions", set)
+	}
+	if len(rest) == 0 {
+		return fmt.Errorf("no tool specified")
+	}
+
+	tool := toolName(rest[0])
+	if err := m.ValidateToolSpec(tool); err != nil {
+		return fmt.Errorf("invalid tool specification '%s': %w", rest[0], err)
+	}
+
+	limit := defaultOptionLimit
+	if value, ok := flags["limit"]; ok {
+		if limit, err = strconv.Atoi(value); err != nil || limit <= 0 {
+			return fmt.Errorf("invalid --limit %q: must be a positive number", value)
+		}
+	}
+
+	lsArgs := []string{"ls-remote", tool}
+	if len(rest) > 1 {
+		if err := m.ValidateCommandArg(rest[1]); err != nil {
+			return fmt.Errorf("invalid version prefix '%s': %w", rest[1], err)
+		}
+		lsArgs = append(lsArgs, rest[1])
+	}
+
+	output, err := sdk.ExecCommandOutputWithContext(ctx, "mise", lsArgs...)
+	if err != nil {
+		return fmt.Errorf("failed to list remote versions of %s: %w", tool, err)
+	}
+
+	// mise lists versions oldest first
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	var options []sdk.OptionInfo
+	for i := len(lines) - 1; i >= 0 && len(options) < limit; i-- {
+		version := strings.TrimSpace(lines[i])
+		if version == "" {
+			continue
+		}
+		options = append(options, sdk.OptionInfo{
+			Value:   version,
+			Label:   tool + " " + version,
+			Default: len(options) == 0,
+		})
+	}
+	return sdk.PrintOptions(options)
+}
+
+// toolName strips the version from a tool specification such as node@20
+func toolName(toolSpec string) string {
+	name, _, _ := strings.Cut(toolSpec, "@")
+	return name
+}
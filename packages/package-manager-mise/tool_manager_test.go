@@ -69,6 +69,20 @@ var _ = Describe("Tool Manager", func() {
 		})
 	})
 
+	Describe("HandleDownload", func() {
+		It("should require a destination", func() {
+			err := plugin.HandleDownload(context.Background(), []string{"node@20"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("no destination"))
+		})
+
+		It("should return error for invalid tool specification", func() {
+			err := plugin.HandleDownload(context.Background(), []string{sdk.DestFlag + GinkgoT().TempDir(), "tool;echo hacked"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid tool specification"))
+		})
+	})
+
 	Describe("HandleRemove", func() {
 		Context("with valid tools", func() {
 			It("should validate tool before removal", func() {
@@ -159,6 +173,32 @@ var _ = Describe("Tool Manager", func() {
 		})
 	})
 
+	Describe("HandleOptions", func() {
+		It("should list remote versions newest first", func() {
+			Skip("Integration test - requires mise to be installed")
+		})
+
+		It("should reject unknown option sets", func() {
+			err := plugin.HandleOptions(context.Background(), []string{"plugins"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("unknown option set"))
+		})
+
+		It("should require a tool", func() {
+			err := plugin.HandleOptions(context.Background(), []string{"versions"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("no tool specified"))
+		})
+
+		It("should validate the tool and limit", func() {
+			err := plugin.HandleOptions(context.Background(), []string{"versions", "node;echo"})
+			Expect(err).To(MatchError(ContainSubstring("invalid tool specification")))
+
+			err = plugin.HandleOptions(context.Background(), []string{"--limit=0", "versions", "node"})
+			Expect(err).To(MatchError(ContainSubstring("invalid --limit")))
+		})
+	})
+
 	Describe("Error Handling", func() {
 		It("should provide actionable error messages", func() {
 			err := plugin.HandleInstall(context.Background(), []string{"tool;echo hacked"})
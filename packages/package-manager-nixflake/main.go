@@ -4,6 +4,7 @@ package main
 
 import (
 	"context"
+	_ "embed"
 	"fmt"
 	"os"
 	"strings"
@@ -13,6 +14,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // NixflakePlugin implements the Nix Flake package manager
 type NixflakePlugin struct {
 	*sdk.PackageManagerPlugin
@@ -27,6 +33,7 @@ func NewNixflakePlugin() *NixflakePlugin {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"nix", "flake", "functional"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "install",
@@ -53,6 +60,11 @@ func NewNixflakePlugin() *NixflakePlugin {
 				Description: "List packages",
 				Usage:       "List installed packages",
 			},
+			{
+				Name:        "is-installed",
+				Description: "Check if packages are installed",
+				Usage:       "Exits 0 if all packages are installed, 1 if any is missing and 2 if the check fails",
+			},
 		},
 	}
 
@@ -78,6 +90,8 @@ func (p *NixflakePlugin) Execute(command string, args []string) error {
 		return p.handleSearch(ctx, args)
 	case "list":
 		return p.handleList(ctx, args)
+	case "is-installed":
+		return p.handleIsInstalled(ctx, args)
 	default:
 		return fmt.Errorf("unknown command: %s", command)
 	}
@@ -126,6 +140,26 @@ func (p *NixflakePlugin) handleList(ctx context.Context, args []string) error {
 	return sdk.ExecCommandWithContext(ctx, false, "nix", "list")
 }
 
+func (p *NixflakePlugin) handleIsInstalled(ctx context.Context, args []string) error {
+	return p.CheckInstalled(ctx, args, p.isPackageInstalled)
+}
+
+// isPackageInstalled looks the package up in `nix profile list`. Profile entries reference
+// packages as flake attributes (e.g. nixpkgs#git or legacyPackages.x86_64-linux.git).
+func (p *NixflakePlugin) isPackageInstalled(ctx context.Context, pkg string) (bool, error) {
+	output, err := sdk.ExecCommandOutputWithContext(ctx, "nix", "profile", "list")
+	if err != nil {
+		return false, err
+	}
+
+	for _, field := range strings.Fields(output) {
+		if field == pkg || strings.HasSuffix(field, "#"+pkg) || strings.HasSuffix(field, "."+pkg) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func main() {
 	plugin := NewNixflakePlugin()
 	sdk.HandleArgs(plugin, os.Args[1:])
@@ -4,6 +4,7 @@ package main
 
 import (
 	"context"
+	_ "embed"
 	"fmt"
 	"os"
 	"strings"
@@ -13,6 +14,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // NixpkgsPlugin implements the Nixpkgs package manager
 type NixpkgsPlugin struct {
 	*sdk.PackageManagerPlugin
@@ -27,6 +33,7 @@ func NewNixpkgsPlugin() *NixpkgsPlugin {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"nix", "nixpkgs", "functional"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "install",
@@ -53,6 +60,11 @@ func NewNixpkgsPlugin() *NixpkgsPlugin {
 				Description: "List packages",
 				Usage:       "List installed packages",
 			},
+			{
+				Name:        "is-installed",
+				Description: "Check if packages are installed",
+				Usage:       "Exits 0 if all packages are installed, 1 if any is missing and 2 if the check fails",
+			},
 		},
 	}
 
@@ -78,6 +90,8 @@ func (p *NixpkgsPlugin) Execute(command string, args []string) error {
 		return p.handleSearch(ctx, args)
 	case "list":
 		return p.handleList(ctx, args)
+	case "is-installed":
+		return p.handleIsInstalled(ctx, args)
 	default:
 		return fmt.Errorf("unknown command: %s", command)
 	}
@@ -126,6 +140,15 @@ func (p *NixpkgsPlugin) handleList(ctx context.Context, args []string) error {
 	return sdk.ExecCommandWithContext(ctx, false, "nix-env", "list")
 }
 
+func (p *NixpkgsPlugin) handleIsInstalled(ctx context.Context, args []string) error {
+	return p.CheckInstalled(ctx, args, p.isPackageInstalled)
+}
+
+// isPackageInstalled queries the local package database with `nix-env -q`
+func (p *NixpkgsPlugin) isPackageInstalled(ctx context.Context, pkg string) (bool, error) {
+	return sdk.CheckCommandWithContext(ctx, "nix-env", "-q", pkg)
+}
+
 func main() {
 	plugin := NewNixpkgsPlugin()
 	sdk.HandleArgs(plugin, os.Args[1:])
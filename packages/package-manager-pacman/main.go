@@ -4,6 +4,7 @@ package main
 
 import (
 	"context"
+	_ "embed"
 	"fmt"
 	"os"
 	"strings"
@@ -13,6 +14,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // PacmanPlugin implements the Pacman package manager
 type PacmanPlugin struct {
 	*sdk.PackageManagerPlugin
@@ -27,6 +33,7 @@ func NewPacmanPlugin() *PacmanPlugin {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"pacman", "arch", "linux"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "install",
@@ -53,6 +60,21 @@ func NewPacmanPlugin() *PacmanPlugin {
 				Description: "List packages",
 				Usage:       "List installed packages",
 			},
+			{
+				Name:        "is-installed",
+				Description: "Check if packages are installed",
+				Usage:       "Exits 0 if all packages are installed, 1 if any is missing and 2 if the check fails",
+			},
+			{
+				Name:        "resolve",
+				Description: "Resolve a version constraint",
+				Usage:       "Print the newest available version matching --constraint as JSON, with the install spec that pins it",
+			},
+			{
+				Name:        "version",
+				Description: "Show installed package versions",
+				Usage:       "Print the installed version and repository of each package as JSON",
+			},
 		},
 	}
 
@@ -78,6 +100,12 @@ func (p *PacmanPlugin) Execute(command string, args []string) error {
 		return p.handleSearch(ctx, args)
 	case "list":
 		return p.handleList(ctx, args)
+	case "is-installed":
+		return p.handleIsInstalled(ctx, args)
+	case "resolve":
+		return p.ResolveVersions(ctx, args, p.availableVersions)
+	case "version":
+		return p.ReportVersions(ctx, args, p.installedVersion)
 	default:
 		return fmt.Errorf("unknown command: %s", command)
 	}
@@ -126,6 +154,62 @@ func (p *PacmanPlugin) handleList(ctx context.Context, args []string) error {
 	return sdk.ExecCommandWithContext(ctx, false, "pacman", "list")
 }
 
+func (p *PacmanPlugin) handleIsInstalled(ctx context.Context, args []string) error {
+	return p.CheckInstalled(ctx, args, p.isPackageInstalled)
+}
+
+// isPackageInstalled queries the local package database with `pacman -Q`
+func (p *PacmanPlugin) isPackageInstalled(ctx context.Context, pkg string) (bool, error) {
+	return sdk.CheckCommandWithContext(ctx, "pacman", "-Q", pkg)
+}
+
+// availableVersions reports the version offered by the sync repositories.
+// Pacman only installs the current repository version, so there is a single candidate.
+func (p *PacmanPlugin) availableVersions(ctx context.Context, pkg string) ([]sdk.PackageInfo, error) {
+	output, err := sdk.ExecCommandOutputWithContext(ctx, "pacman", "-Si", pkg)
+	if err != nil {
+		return nil, fmt.Errorf("package %s not found in the sync repositories: %w", pkg, err)
+	}
+
+	fields := parseInfoFields(output)
+	return []sdk.PackageInfo{{
+		Name:    pkg,
+		Version: fields["Version"],
+		Source:  fields["Repository"],
+		Spec:    pkg,
+	}}, nil
+}
+
+// installedVersion reports the installed version of a package and the repository that provides it
+func (p *PacmanPlugin) installedVersion(ctx context.Context, pkg string) (sdk.PackageInfo, error) {
+	output, err := sdk.ExecCommandOutputWithContext(ctx, "pacman", "-Qi", pkg)
+	if err != nil {
+		return sdk.PackageInfo{}, sdk.ErrNotInstalled
+	}
+
+	info := sdk.PackageInfo{Name: pkg, Version: parseInfoFields(output)["Version"], Source: "local"}
+	if available, err := p.availableVersions(ctx, pkg); err == nil && available[0].Version == info.Version {
+		info.Source = available[0].Source
+	}
+	return info, nil
+}
+
+// parseInfoFields parses the "Key : Value" lines printed by pacman -Si and -Qi
+func parseInfoFields(output string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if _, exists := fields[key]; !exists {
+			fields[key] = strings.TrimSpace(value)
+		}
+	}
+	return fields
+}
+
 func main() {
 	plugin := NewPacmanPlugin()
 	sdk.HandleArgs(plugin, os.Args[1:])
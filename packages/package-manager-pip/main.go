@@ -3,6 +3,7 @@ package main
 // Build timestamp: 2025-09-06
 
 import (
+	_ "embed"
 	"os"
 
 	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
@@ -10,6 +11,11 @@ import (
 
 var version = "dev" // Set by goreleaser
 
+// metadata is the plugin manifest, which declares the permissions the plugin requests
+//
+//go:embed metadata.yaml
+var metadata []byte
+
 // PipPlugin implements the Pip package manager
 type PipPlugin struct {
 	*sdk.PackageManagerPlugin
@@ -25,6 +31,7 @@ func NewPipPlugin() *PipPlugin {
 		Author:      "DevEx Team",
 		Repository:  "https://github.com/jameswlane/devex",
 		Tags:        []string{"package-manager", "pip", "python", "packages", "virtual-environment"},
+		Permissions: sdk.MustParsePermissionManifest(metadata),
 		Commands: []sdk.PluginCommand{
 			{
 				Name:        "install",
@@ -72,6 +79,16 @@ func NewPipPlugin() *PipPlugin {
 				Description: "Check if a package is installed",
 				Usage:       "Returns exit code 0 if package is installed, 1 if not",
 			},
+			{
+				Name:        "resolve",
+				Description: "Resolve a version constraint",
+				Usage:       "Print the newest version on the package index matching --constraint as JSON, with the install spec that pins it",
+			},
+			{
+				Name:        "version",
+				Description: "Show installed package versions",
+				Usage:       "Print the installed version of each package as JSON",
+			},
 			{
 				Name:        "create-venv",
 				Description: "Create a virtual environment",
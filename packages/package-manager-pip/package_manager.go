@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 
 	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
@@ -16,6 +17,10 @@ func (p *PipPlugin) Execute(command string, args []string) error {
 	switch command {
 	case "is-installed":
 		return p.handleIsInstalled(ctx, args)
+	case "resolve":
+		return p.handleResolve(ctx, args)
+	case "version":
+		return p.handleVersion(ctx, args)
 	case "create-venv":
 		return p.handleCreateVenv(ctx, args)
 	case "freeze":
@@ -90,7 +95,7 @@ func (p *PipPlugin) handleInstall(ctx context.Context, args []string) error {
 	}
 
 	cmdArgs = append(cmdArgs, processedArgs...)
-	return sdk.ExecCommandWithContext(ctx, true, "pip", cmdArgs...)
+	return sdk.ExecCommandWithContext(ctx, false, "pip", p.installArgs(ctx, cmdArgs...)...)
 }
 
 // handleRemove removes Python packages
@@ -131,14 +136,14 @@ func (p *PipPlugin) handleRemove(ctx context.Context, args []string) error {
 	}
 
 	cmdArgs = append(cmdArgs, processedArgs...)
-	return sdk.ExecCommandWithContext(ctx, true, "pip", cmdArgs...)
+	return sdk.ExecCommandWithContext(ctx, false, "pip", cmdArgs...)
 }
 
 // handleUpdate updates Python packages
 func (p *PipPlugin) handleUpdate(ctx context.Context, args []string) error {
 	// First update pip itself
 	p.logger.Printf("Updating pip...\n")
-	if err := sdk.ExecCommandWithContext(ctx, true, "pip", "install", "--upgrade", "pip"); err != nil {
+	if err := sdk.ExecCommandWithContext(ctx, false, "pip", p.installArgs(ctx, "install", "--upgrade", "pip")...); err != nil {
 		p.logger.Warning("Failed to update pip: %v", err)
 	}
 
@@ -166,7 +171,7 @@ func (p *PipPlugin) handleUpdate(ctx context.Context, args []string) error {
 		if len(processedArgs) > 0 {
 			p.logger.Printf("Updating packages: %s\n", strings.Join(processedArgs, ", "))
 			cmdArgs := append([]string{"install", "--upgrade"}, processedArgs...)
-			return sdk.ExecCommandWithContext(ctx, true, "pip", cmdArgs...)
+			return sdk.ExecCommandWithContext(ctx, false, "pip", p.installArgs(ctx, cmdArgs...)...)
 		}
 	}
 
@@ -238,14 +243,16 @@ func (p *PipPlugin) handleIsInstalled(ctx context.Context, args []string) error
 		return fmt.Errorf("invalid package name: %w", err)
 	}
 
-	if err := sdk.ExecCommandWithContext(ctx, false, "pip", "show", packageName); err != nil {
+	installed, err := sdk.CheckCommandWithContext(ctx, "pip", "show", packageName)
+	if err != nil {
+		return fmt.Errorf("failed to check installation status of %s: %w", packageName, err)
+	}
+	if !installed {
 		p.logger.Printf("Package %s is not installed\n", packageName)
-		os.Exit(1)
-	} else {
-		p.logger.Printf("Package %s is installed\n", packageName)
-		os.Exit(0)
+		return &sdk.NotInstalledError{Packages: []string{packageName}}
 	}
 
+	p.logger.Printf("Package %s is installed\n", packageName)
 	return nil
 }
 
@@ -273,7 +280,7 @@ func (p *PipPlugin) updateAllPackages(ctx context.Context) error {
 		if parts := strings.Split(line, "=="); len(parts) >= 1 {
 			packageName := parts[0]
 			p.logger.Printf("Updating %s...\n", packageName)
-			if err := sdk.ExecCommandWithContext(ctx, true, "pip", "install", "--upgrade", packageName); err != nil {
+			if err := sdk.ExecCommandWithContext(ctx, false, "pip", p.installArgs(ctx, "install", "--upgrade", packageName)...); err != nil {
 				p.logger.Warning("Failed to update %s: %v", packageName, err)
 			}
 		}
@@ -282,3 +289,12 @@ func (p *PipPlugin) updateAllPackages(ctx context.Context) error {
 	p.logger.Success("Package updates completed")
 	return nil
 }
+
+// installArgs adds --user to pip install arguments outside a virtual environment. The plugin
+// runs without sudo, so it installs into the user's site-packages instead of the system's.
+func (p *PipPlugin) installArgs(ctx context.Context, args ...string) []string {
+	if p.isVirtualEnvActive(ctx) || slices.Contains(args, "--user") {
+		return args
+	}
+	return append(args, "--user")
+}
@@ -37,9 +37,9 @@ func (p *PipPlugin) handleCreateVenv(ctx context.Context, args []string) error {
 	}
 
 	// Create virtual environment
-	if err := sdk.ExecCommandWithContext(ctx, true, "python3", "-m", "venv", venvName); err != nil {
+	if err := sdk.ExecCommandWithContext(ctx, false, "python3", "-m", "venv", venvName); err != nil {
 		// Try python if python3 is not available
-		if err2 := sdk.ExecCommandWithContext(ctx, true, "python", "-m", "venv", venvName); err2 != nil {
+		if err2 := sdk.ExecCommandWithContext(ctx, false, "python", "-m", "venv", venvName); err2 != nil {
 			return fmt.Errorf("failed to create virtual environment: %w (also tried python: %v)", err, err2)
 		}
 	}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+// pipSource is reported as the origin of packages installed from the configured package index
+const pipSource = "pypi"
+
+// handleResolve prints the newest version on the package index matching --constraint
+func (p *PipPlugin) handleResolve(ctx context.Context, args []string) error {
+	return p.ResolveVersions(ctx, args, p.availableVersions)
+}
+
+// handleVersion prints the installed version of each package
+func (p *PipPlugin) handleVersion(ctx context.Context, args []string) error {
+	return p.ReportVersions(ctx, args, p.installedVersion)
+}
+
+// availableVersions lists the versions published on the package index using `pip index versions`
+func (p *PipPlugin) availableVersions(ctx context.Context, packageName string) ([]sdk.PackageInfo, error) {
+	if err := p.validatePackageName(packageName); err != nil {
+		return nil, fmt.Errorf("invalid package name: %w", err)
+	}
+
+	output, err := sdk.ExecCommandOutputWithContext(ctx, "pip", "index", "versions", packageName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query package index: %w", err)
+	}
+
+	var versions []sdk.PackageInfo
+	for _, version := range parseIndexVersions(output) {
+		versions = append(versions, sdk.PackageInfo{
+			Name:    packageName,
+			Version: version,
+			Source:  pipSource,
+			Spec:    packageName + "==" + version,
+		})
+	}
+	return versions, nil
+}
+
+// installedVersion reports the installed version of a package using `pip show`
+func (p *PipPlugin) installedVersion(ctx context.Context, packageName string) (sdk.PackageInfo, error) {
+	if err := p.validatePackageName(packageName); err != nil {
+		return sdk.PackageInfo{}, fmt.Errorf("invalid package name: %w", err)
+	}
+
+	output, err := sdk.ExecCommandOutputWithContext(ctx, "pip", "show", packageName)
+	if err != nil {
+		return sdk.PackageInfo{}, sdk.ErrNotInstalled
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		if version, found := strings.CutPrefix(line, "Version:"); found {
+			return sdk.PackageInfo{Name: packageName, Version: strings.TrimSpace(version), Source: pipSource}, nil
+		}
+	}
+	return sdk.PackageInfo{}, fmt.Errorf("pip show did not report a version for %s", packageName)
+}
+
+// parseIndexVersions extracts the version list from `pip index versions` output:
+//
+//	requests (2.31.0)
+//	Available versions: 2.31.0, 2.30.0, 2.29.0
+func parseIndexVersions(output string) []string {
+	for _, line := range strings.Split(output, "\n") {
+		list, found := strings.CutPrefix(strings.TrimSpace(line), "Available versions:")
+		if !found {
+			continue
+		}
+		var versions []string
+		for _, version := range strings.Split(list, ",") {
+			if version = strings.TrimSpace(version); version != "" {
+				versions = append(versions, version)
+			}
+		}
+		return versions
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package sdk
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Labels the docker plugin sets on the containers it manages
+const (
+	ContainerLabelManaged = "sh.devex.managed"
+	ContainerLabelApp     = "sh.devex.app"
+	ContainerLabelImage   = "sh.devex.image"
+	ContainerLabelStack   = "sh.devex.stack"
+	ContainerLabelSpec    = "sh.devex.spec" // hash of the ContainerSpec the container was created from
+)
+
+// ContainerSpecOption is the install option that carries a JSON-encoded ContainerSpec
+const ContainerSpecOption = "spec"
+
+// ContainerSpec describes how the docker plugin runs the container of an application
+type ContainerSpec struct {
+	// App is the devex application the container belongs to
+	App   string `json:"app,omitempty"`
+	Image string `json:"image"`
+
+	// Name of the container; derived from App or Image when empty
+	Name string `json:"name,omitempty"`
+
+	Ports         []string              `json:"ports,omitempty"`
+	Environment   []string              `json:"environment,omitempty"`
+	Volumes       []string              `json:"volumes,omitempty"`
+	RestartPolicy string                `json:"restart_policy,omitempty"`
+	Network       string                `json:"network,omitempty"`
+	Healthcheck   *ContainerHealthcheck `json:"healthcheck,omitempty"`
+	Command       []string              `json:"command,omitempty"`
+
+	// Stack runs the container as a service of the devex-managed compose project of that name
+	Stack string `json:"stack,omitempty"`
+}
+
+// ContainerHealthcheck is the health check of a container
+type ContainerHealthcheck struct {
+	// Test is a shell command; the container is healthy when it exits with 0
+	Test        string `json:"test"`
+	Interval    string `json:"interval,omitempty"`
+	Timeout     string `json:"timeout,omitempty"`
+	StartPeriod string `json:"start_period,omitempty"`
+	Retries     int    `json:"retries,omitempty"`
+}
+
+var containerNameUnsafe = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// ContainerName returns the container name, deriving "devex-<app>" or "devex-<image>" when
+// no name is set so repeated installs find the same container
+func (s *ContainerSpec) ContainerName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+
+	base := s.App
+	if base == "" {
+		base = s.Image
+		if slash := strings.LastIndex(base, "/"); slash >= 0 {
+			base = base[slash+1:]
+		}
+		base, _, _ = strings.Cut(base, "@")
+		base, _, _ = strings.Cut(base, ":")
+	}
+	base = strings.Trim(containerNameUnsafe.ReplaceAllString(strings.ToLower(base), "-"), "-.")
+	return "devex-" + base
+}
+
+// Hash returns a short digest of the spec, recorded in a label to detect configuration changes
+func (s *ContainerSpec) Hash() string {
+	data, _ := json.Marshal(s)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// Labels returns the labels that mark a container as managed by devex
+func (s *ContainerSpec) Labels() map[string]string {
+	labels := map[string]string{
+		ContainerLabelManaged: "true",
+		ContainerLabelImage:   s.Image,
+		ContainerLabelSpec:    s.Hash(),
+	}
+	if s.App != "" {
+		labels[ContainerLabelApp] = s.App
+	}
+	if s.Stack != "" {
+		labels[ContainerLabelStack] = s.Stack
+	}
+	return labels
+}
+
+// Encode returns the JSON form of the spec sent in the ContainerSpecOption install option
+func (s *ContainerSpec) Encode() (string, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode container spec: %w", err)
+	}
+	return string(data), nil
+}
+
+// DecodeContainerSpec parses a spec encoded with ContainerSpec.Encode
+func DecodeContainerSpec(data string) (*ContainerSpec, error) {
+	var spec ContainerSpec
+	if err := json.Unmarshal([]byte(data), &spec); err != nil {
+		return nil, fmt.Errorf("invalid container spec: %w", err)
+	}
+	return &spec, nil
+}
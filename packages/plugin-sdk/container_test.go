@@ -0,0 +1,44 @@
+package sdk_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+var _ = Describe("ContainerSpec", func() {
+	It("derives stable container names", func() {
+		Expect((&sdk.ContainerSpec{Image: "postgres:16", Name: "pg"}).ContainerName()).To(Equal("pg"))
+		Expect((&sdk.ContainerSpec{Image: "postgres:16", App: "PostgreSQL"}).ContainerName()).To(Equal("devex-postgresql"))
+		Expect((&sdk.ContainerSpec{Image: "ghcr.io/acme/redis-stack:7.2"}).ContainerName()).To(Equal("devex-redis-stack"))
+		Expect((&sdk.ContainerSpec{Image: "My App"}).ContainerName()).To(Equal("devex-my-app"))
+	})
+
+	It("changes the hash when the configuration changes", func() {
+		spec := &sdk.ContainerSpec{Image: "redis:7", Ports: []string{"6379:6379"}}
+		hash := spec.Hash()
+		Expect(spec.Hash()).To(Equal(hash))
+
+		spec.Ports = []string{"6380:6379"}
+		Expect(spec.Hash()).NotTo(Equal(hash))
+		Expect(spec.Labels()).To(HaveKeyWithValue(sdk.ContainerLabelSpec, spec.Hash()))
+		Expect(spec.Labels()).To(HaveKeyWithValue(sdk.ContainerLabelManaged, "true"))
+	})
+
+	It("round-trips through the install option", func() {
+		spec := &sdk.ContainerSpec{
+			App:         "PostgreSQL",
+			Image:       "postgres:16",
+			Environment: []string{"POSTGRES_PASSWORD=p@ss$word"},
+			Healthcheck: &sdk.ContainerHealthcheck{Test: "pg_isready -U postgres", Retries: 5},
+			Stack:       "databases",
+		}
+		encoded, err := spec.Encode()
+		Expect(err).NotTo(HaveOccurred())
+
+		decoded, err := sdk.DecodeContainerSpec(encoded)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decoded).To(Equal(spec))
+	})
+})
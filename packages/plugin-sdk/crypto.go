@@ -219,95 +219,3 @@ func (v *SystemGPGVerifier) VerifyDetachedSignature(filePath, signaturePath stri
 	}
 	return nil
 }
-
-// Enhanced verifyPluginSignature implementation
-func (d *Downloader) verifyPluginSignature(pluginPath, downloadURL string) error {
-	if d.publicKeyPath == "" {
-		if d.logger != nil {
-			d.logger.Warning("Plugin signature verification skipped: no public key configured. To enable verification, set publicKeyPath in DownloaderConfig")
-		}
-		return nil
-	}
-
-	// Generate signature URL (assuming .sig suffix)
-	signatureURL := downloadURL + ".sig"
-
-	// Try modern Go crypto first
-	verifier := NewGPGVerifier()
-	
-	// Load public key
-	if err := verifier.LoadPublicKey(d.publicKeyPath); err != nil {
-		if d.logger != nil {
-			d.logger.Warning("Failed to load public key with Go crypto: %v", err)
-		}
-		
-		// Fallback to system GPG
-		return d.verifyWithSystemGPG(pluginPath, signatureURL)
-	}
-
-	// Verify using Go crypto
-	if err := verifier.VerifySignatureFromURL(pluginPath, signatureURL); err != nil {
-		if d.logger != nil {
-			d.logger.Warning("Go crypto verification failed: %v", err)
-		}
-		
-		// Fallback to system GPG
-		return d.verifyWithSystemGPG(pluginPath, signatureURL)
-	}
-
-	if d.logger != nil {
-		d.logger.Success("Plugin signature verification successful")
-	}
-	return nil
-}
-
-// verifyWithSystemGPG falls back to system GPG for verification
-func (d *Downloader) verifyWithSystemGPG(pluginPath, signatureURL string) error {
-	verifier, err := NewSystemGPGVerifier()
-	if err != nil {
-		return fmt.Errorf("system GPG not available: %w", err)
-	}
-
-	// Import public key if it's a file path
-	if !strings.HasPrefix(d.publicKeyPath, "http") && FileExists(d.publicKeyPath) {
-		if err := verifier.ImportPublicKey(d.publicKeyPath); err != nil {
-			return fmt.Errorf("failed to import public key: %w", err)
-		}
-	}
-
-	// Download signature
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Get(signatureURL)
-	if err != nil {
-		return fmt.Errorf("failed to download signature: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("signature not available: HTTP %d", resp.StatusCode)
-	}
-
-	// Create temporary signature file
-	tempSig, err := os.CreateTemp("", "plugin-sig-*.sig")
-	if err != nil {
-		return fmt.Errorf("failed to create temp signature file: %w", err)
-	}
-	defer func() { _ = os.Remove(tempSig.Name()) }()
-	defer func() { _ = tempSig.Close() }()
-
-	if _, err := io.Copy(tempSig, resp.Body); err != nil {
-		return fmt.Errorf("failed to save signature: %w", err)
-	}
-
-	_ = tempSig.Close() // Close before verification
-
-	// Verify signature
-	if err := verifier.VerifyDetachedSignature(pluginPath, tempSig.Name()); err != nil {
-		return fmt.Errorf("signature verification failed: %w", err)
-	}
-
-	if d.logger != nil {
-		d.logger.Success("Plugin signature verification successful (system GPG)")
-	}
-	return nil
-}
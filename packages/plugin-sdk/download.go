@@ -0,0 +1,46 @@
+package sdk
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MethodDownload is the command of package manager plugins that fetches packages, and everything
+// needed to install them, into a directory without installing them, e.g.
+// "download --dest=/tmp/bundle/git git". The directory is installed from on a machine without
+// network access.
+const MethodDownload = "download"
+
+// DestFlag carries the destination directory of a download command
+const DestFlag = "--dest="
+
+// ParseDownloadArgs separates the --dest flag from the remaining arguments of a download command
+// and creates the destination directory. The destination is returned as an absolute path.
+func ParseDownloadArgs(args []string) (string, []string, error) {
+	dest := ""
+	rest := make([]string, 0, len(args))
+	for _, arg := range args {
+		if strings.HasPrefix(arg, DestFlag) {
+			dest = strings.TrimPrefix(arg, DestFlag)
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	if dest == "" {
+		return "", nil, fmt.Errorf("no destination specified, use %sDIR", DestFlag)
+	}
+	if len(rest) == 0 {
+		return "", nil, fmt.Errorf("no packages specified")
+	}
+
+	dest, err := filepath.Abs(dest)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid destination: %w", err)
+	}
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return "", nil, fmt.Errorf("failed to create destination: %w", err)
+	}
+	return dest, rest, nil
+}
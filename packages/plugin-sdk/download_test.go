@@ -0,0 +1,30 @@
+package sdk_test
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+var _ = Describe("ParseDownloadArgs", func() {
+	It("separates the destination from the packages and creates it", func() {
+		dest := filepath.Join(GinkgoT().TempDir(), "bundle", "git")
+
+		parsed, packages, err := sdk.ParseDownloadArgs([]string{"git", sdk.DestFlag + dest, "curl"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parsed).To(Equal(dest))
+		Expect(packages).To(Equal([]string{"git", "curl"}))
+		Expect(dest).To(BeADirectory())
+	})
+
+	It("requires a destination and packages", func() {
+		_, _, err := sdk.ParseDownloadArgs([]string{"git"})
+		Expect(err).To(MatchError(ContainSubstring("no destination")))
+
+		_, _, err = sdk.ParseDownloadArgs([]string{sdk.DestFlag + GinkgoT().TempDir()})
+		Expect(err).To(MatchError(ContainSubstring("no packages")))
+	})
+})
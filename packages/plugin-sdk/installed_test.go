@@ -0,0 +1,84 @@
+package sdk_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+var _ = Describe("is-installed contract", func() {
+	Describe("IsInstalledExitCode", func() {
+		It("maps results onto the documented exit codes", func() {
+			Expect(sdk.IsInstalledExitCode(nil)).To(Equal(sdk.ExitCodeInstalled))
+			Expect(sdk.IsInstalledExitCode(&sdk.NotInstalledError{Packages: []string{"git"}})).To(Equal(sdk.ExitCodeNotInstalled))
+			Expect(sdk.IsInstalledExitCode(fmt.Errorf("wrapped: %w", sdk.ErrNotInstalled))).To(Equal(sdk.ExitCodeNotInstalled))
+			Expect(sdk.IsInstalledExitCode(errors.New("rpm database is locked"))).To(Equal(sdk.ExitCodeCheckFailed))
+		})
+	})
+
+	Describe("PackageManagerPlugin.CheckInstalled", func() {
+		var plugin *sdk.PackageManagerPlugin
+
+		BeforeEach(func() {
+			plugin = sdk.NewPackageManagerPlugin(sdk.PluginInfo{Name: "package-manager-test"}, "true")
+		})
+
+		installedSet := func(names ...string) func(context.Context, string) (bool, error) {
+			return func(_ context.Context, pkg string) (bool, error) {
+				for _, name := range names {
+					if name == pkg {
+						return true, nil
+					}
+				}
+				return false, nil
+			}
+		}
+
+		It("succeeds when every package is installed", func() {
+			Expect(plugin.CheckInstalled(context.Background(), []string{"git", "curl"}, installedSet("git", "curl"))).To(Succeed())
+		})
+
+		It("lists the missing packages", func() {
+			err := plugin.CheckInstalled(context.Background(), []string{"git", "curl", "jq"}, installedSet("git"))
+
+			var notInstalled *sdk.NotInstalledError
+			Expect(errors.As(err, &notInstalled)).To(BeTrue())
+			Expect(notInstalled.Packages).To(Equal([]string{"curl", "jq"}))
+		})
+
+		It("reports check failures separately from missing packages", func() {
+			failing := func(context.Context, string) (bool, error) {
+				return false, errors.New("database is locked")
+			}
+			err := plugin.CheckInstalled(context.Background(), []string{"git"}, failing)
+			Expect(err).To(HaveOccurred())
+			Expect(errors.Is(err, sdk.ErrNotInstalled)).To(BeFalse())
+		})
+
+		It("rejects missing and flag-like package names", func() {
+			Expect(plugin.CheckInstalled(context.Background(), nil, installedSet())).ToNot(Succeed())
+			err := plugin.CheckInstalled(context.Background(), []string{"--all"}, installedSet())
+			Expect(sdk.IsInstalledExitCode(err)).To(Equal(sdk.ExitCodeCheckFailed))
+		})
+	})
+
+	Describe("CheckCommandWithContext", func() {
+		It("distinguishes a failed query from a command that cannot run", func() {
+			ok, err := sdk.CheckCommandWithContext(context.Background(), "true")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+
+			ok, err = sdk.CheckCommandWithContext(context.Background(), "false")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+
+			_, err = sdk.CheckCommandWithContext(context.Background(), "devex-command-that-does-not-exist")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
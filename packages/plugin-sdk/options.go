@@ -0,0 +1,231 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// OptionInfo is a choice a plugin offers for a setup question. The options command prints one
+// OptionInfo per line as JSON; protocol requests return them in RPCResult.Options.
+type OptionInfo struct {
+	Value       string `json:"value"`
+	Label       string `json:"label,omitempty"`
+	Description string `json:"description,omitempty"`
+	Default     bool   `json:"default,omitempty"`
+}
+
+// PrintOption writes an option as one JSON line, the output format of the options command
+func PrintOption(option OptionInfo) error {
+	return json.NewEncoder(os.Stdout).Encode(option)
+}
+
+// PrintOptions writes every option with PrintOption
+func PrintOptions(options []OptionInfo) error {
+	for _, option := range options {
+		if err := PrintOption(option); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseOptionLines decodes the output of the options command. Lines that are not JSON are ignored.
+func ParseOptionLines(output string) ([]OptionInfo, error) {
+	var options []OptionInfo
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var option OptionInfo
+		if err := json.Unmarshal([]byte(line), &option); err != nil {
+			return nil, fmt.Errorf("invalid option line %q: %w", line, err)
+		}
+		if option.Value == "" {
+			return nil, fmt.Errorf("option line %q has no value", line)
+		}
+		options = append(options, option)
+	}
+	return options, nil
+}
+
+// ParseOptionsArgs splits the arguments of an options command into the option set, the
+// --name=value flags and the remaining arguments, e.g. "--limit=10 versions node"
+func ParseOptionsArgs(args []string) (set string, flags map[string]string, rest []string, err error) {
+	flags = make(map[string]string)
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--") {
+			name, value, _ := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+			flags[name] = value
+			continue
+		}
+		if set == "" {
+			set = arg
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	if set == "" {
+		return "", nil, nil, fmt.Errorf("no option set specified")
+	}
+	return set, flags, rest, nil
+}
+
+// Theme kinds understood by InstalledThemes
+const (
+	ThemeGTK         = "gtk"
+	ThemeIcons       = "icons"
+	ThemeCursors     = "cursors"
+	ThemeShell       = "shell"         // GNOME Shell themes
+	ThemeXfwm        = "xfwm4"         // Xfce window manager themes
+	ThemeMetacity    = "metacity"      // Metacity/Marco window manager themes
+	ThemeCinnamon    = "cinnamon"      // Cinnamon desktop themes
+	ThemePlasma      = "plasma"        // Plasma desktop themes
+	ThemeLookAndFeel = "look-and-feel" // Plasma global themes
+	ThemeKvantum     = "kvantum"       // Kvantum Qt themes
+)
+
+// themeLocation describes where themes of a kind live, relative to an XDG data directory,
+// and the entries that mark a directory as a theme of that kind (any one of them suffices)
+type themeLocation struct {
+	dir     string
+	markers []string
+	legacy  string // directory relative to $HOME used before XDG, e.g. ".themes"
+}
+
+var themeLocations = map[string]themeLocation{
+	ThemeGTK:         {dir: "themes", markers: []string{"gtk-4.0", "gtk-3.0", "gtk-2.0"}, legacy: ".themes"},
+	ThemeIcons:       {dir: "icons", markers: []string{"index.theme"}, legacy: ".icons"},
+	ThemeCursors:     {dir: "icons", markers: []string{"cursors"}, legacy: ".icons"},
+	ThemeShell:       {dir: "themes", markers: []string{"gnome-shell"}, legacy: ".themes"},
+	ThemeXfwm:        {dir: "themes", markers: []string{"xfwm4"}, legacy: ".themes"},
+	ThemeMetacity:    {dir: "themes", markers: []string{"metacity-1"}, legacy: ".themes"},
+	ThemeCinnamon:    {dir: "themes", markers: []string{"cinnamon"}, legacy: ".themes"},
+	ThemePlasma:      {dir: "plasma/desktoptheme"},
+	ThemeLookAndFeel: {dir: "plasma/look-and-feel"},
+	ThemeKvantum:     {dir: "Kvantum"},
+}
+
+// themeDataDirs returns the XDG data directories, user directory first
+func themeDataDirs() []string {
+	var dirs []string
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		dirs = append(dirs, dataHome)
+	} else if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".local", "share"))
+	}
+
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+	for _, dir := range strings.Split(dataDirs, ":") {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// InstalledThemes lists the themes of a kind installed for the user or system-wide, sorted by
+// name. Themes installed for the user are described as such.
+func InstalledThemes(kind string) ([]OptionInfo, error) {
+	location, ok := themeLocations[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown theme kind %q", kind)
+	}
+
+	var roots []string
+	home, homeErr := os.UserHomeDir()
+	if location.legacy != "" && homeErr == nil {
+		roots = append(roots, filepath.Join(home, location.legacy))
+	}
+	for _, dataDir := range themeDataDirs() {
+		roots = append(roots, filepath.Join(dataDir, filepath.FromSlash(location.dir)))
+	}
+
+	seen := make(map[string]bool)
+	var themes []OptionInfo
+	for _, root := range roots {
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			continue
+		}
+		userTheme := homeErr == nil && strings.HasPrefix(root, home+string(filepath.Separator))
+		for _, entry := range entries {
+			name := entry.Name()
+			if seen[name] || strings.HasPrefix(name, ".") {
+				continue
+			}
+			path := filepath.Join(root, name)
+			if info, err := os.Stat(path); err != nil || !info.IsDir() {
+				continue
+			}
+			if !hasThemeMarker(path, location.markers) {
+				continue
+			}
+
+			seen[name] = true
+			option := OptionInfo{Value: name, Label: name}
+			if userTheme {
+				option.Description = "Installed for the current user"
+			}
+			themes = append(themes, option)
+		}
+	}
+
+	sort.Slice(themes, func(i, j int) bool {
+		return strings.ToLower(themes[i].Value) < strings.ToLower(themes[j].Value)
+	})
+	return themes, nil
+}
+
+// hasThemeMarker reports whether dir contains one of markers; no markers accepts any directory
+func hasThemeMarker(dir string, markers []string) bool {
+	if len(markers) == 0 {
+		return true
+	}
+	for _, marker := range markers {
+		if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintThemeOptions implements the options command of desktop plugins. The "themes" option set
+// lists the first of kinds; every kind can also be requested by name, e.g. "options icons".
+// current, when not nil, returns the active theme of a kind so it can be marked as the default.
+func PrintThemeOptions(args []string, current func(kind string) string, kinds ...string) error {
+	set, _, _, err := ParseOptionsArgs(args)
+	if err != nil {
+		return err
+	}
+
+	kind := ""
+	for _, supported := range kinds {
+		if set == supported || set == "themes" && kind == "" {
+			kind = supported
+		}
+	}
+	if kind == "" {
+		return fmt.Errorf("unknown option set %q, available: themes, %s", set, strings.Join(kinds, ", "))
+	}
+
+	themes, err := InstalledThemes(kind)
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		if active := current(kind); active != "" {
+			for i := range themes {
+				themes[i].Default = themes[i].Value == active
+			}
+		}
+	}
+	return PrintOptions(themes)
+}
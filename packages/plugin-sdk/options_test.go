@@ -0,0 +1,90 @@
+package sdk_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+var _ = Describe("Plugin options", func() {
+	Describe("ParseOptionsArgs", func() {
+		It("separates flags, the option set and its arguments", func() {
+			set, flags, rest, err := sdk.ParseOptionsArgs([]string{"--limit=5", "--all", "versions", "node"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(set).To(Equal("versions"))
+			Expect(flags).To(Equal(map[string]string{"limit": "5", "all": ""}))
+			Expect(rest).To(Equal([]string{"node"}))
+		})
+
+		It("requires an option set", func() {
+			_, _, _, err := sdk.ParseOptionsArgs([]string{"--limit=5"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("ParseOptionLines", func() {
+		It("rejects options without a value", func() {
+			_, err := sdk.ParseOptionLines(`{"label":"Nameless"}`)
+			Expect(err).To(MatchError(ContainSubstring("has no value")))
+		})
+	})
+
+	Describe("InstalledThemes", func() {
+		var home, system string
+
+		makeTheme := func(root, name, marker string) {
+			Expect(os.MkdirAll(filepath.Join(root, name, marker), 0755)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			home = GinkgoT().TempDir()
+			system = GinkgoT().TempDir()
+			GinkgoT().Setenv("HOME", home)
+			GinkgoT().Setenv("XDG_DATA_HOME", "")
+			GinkgoT().Setenv("XDG_DATA_DIRS", system)
+		})
+
+		It("lists themes of the requested kind from user and system directories", func() {
+			makeTheme(filepath.Join(home, ".themes"), "Nordic", "gtk-3.0")
+			makeTheme(filepath.Join(home, ".local", "share", "themes"), "Yaru-dark", "gnome-shell")
+			makeTheme(filepath.Join(system, "themes"), "Adwaita", "gtk-3.0")
+			makeTheme(filepath.Join(system, "themes"), "Nordic", "gtk-3.0")
+
+			themes, err := sdk.InstalledThemes(sdk.ThemeGTK)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(themes).To(Equal([]sdk.OptionInfo{
+				{Value: "Adwaita", Label: "Adwaita"},
+				{Value: "Nordic", Label: "Nordic", Description: "Installed for the current user"},
+			}))
+
+			shellThemes, err := sdk.InstalledThemes(sdk.ThemeShell)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shellThemes).To(HaveLen(1))
+			Expect(shellThemes[0].Value).To(Equal("Yaru-dark"))
+		})
+
+		It("tells icon themes and cursor themes apart", func() {
+			icons := filepath.Join(system, "icons")
+			Expect(os.MkdirAll(filepath.Join(icons, "Papirus"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(icons, "Papirus", "index.theme"), []byte("[Icon Theme]\n"), 0600)).To(Succeed())
+			makeTheme(icons, "Bibata", "cursors")
+
+			iconThemes, err := sdk.InstalledThemes(sdk.ThemeIcons)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(iconThemes).To(ConsistOf(HaveField("Value", "Papirus")))
+
+			cursorThemes, err := sdk.InstalledThemes(sdk.ThemeCursors)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cursorThemes).To(ConsistOf(HaveField("Value", "Bibata")))
+		})
+
+		It("rejects unknown kinds", func() {
+			_, err := sdk.InstalledThemes("wallpapers")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
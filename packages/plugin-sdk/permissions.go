@@ -0,0 +1,342 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Plugins declare the access they need in the permissions section of their metadata.yaml,
+// which is embedded in the binary and reported with --plugin-info. The CLI shows the
+// permissions on first install, asks for consent and runs the plugin confined to the
+// permissions that were granted.
+
+const (
+	// PermissionsEnvVar carries the permissions a plugin runs with. The sandbox launcher applies
+	// them and the SDK command helpers inside the plugin check them.
+	PermissionsEnvVar = "DEVEX_PLUGIN_PERMISSIONS"
+
+	// PermissionViolationExitCode is the exit code of a plugin that attempted something its
+	// permission manifest does not allow (EX_NOPERM from sysexits.h)
+	PermissionViolationExitCode = 77
+
+	// permissionsDir holds the permissions granted to installed plugins, relative to the plugin
+	// directory
+	permissionsDir = "permissions"
+)
+
+// PluginPermissions is the permission manifest of a plugin
+type PluginPermissions struct {
+	// Sudo allows running commands as root with sudo
+	Sudo bool `json:"sudo,omitempty" yaml:"sudo,omitempty"`
+	// Network allows TCP connections
+	Network bool `json:"network,omitempty" yaml:"network,omitempty"`
+	// Write lists the files and directories the plugin may modify. Paths may start with ~/.
+	Write []string `json:"write,omitempty" yaml:"write,omitempty"`
+	// Exec lists the commands the plugin may run, by name or path. Paths may start with ~/;
+	// directories allow every program beneath them.
+	Exec []string `json:"exec,omitempty" yaml:"exec,omitempty"`
+	// Env lists environment variables passed to the plugin in addition to the basic ones
+	Env []string `json:"env,omitempty" yaml:"env,omitempty"`
+}
+
+// ParsePermissionManifest reads the permissions section of a plugin's metadata.yaml. It returns
+// nil when the file declares no permissions.
+func ParsePermissionManifest(metadata []byte) (*PluginPermissions, error) {
+	var manifest struct {
+		Permissions *PluginPermissions `yaml:"permissions"`
+	}
+	if err := yaml.Unmarshal(metadata, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid plugin metadata: %w", err)
+	}
+	if manifest.Permissions == nil {
+		return nil, nil
+	}
+	if err := manifest.Permissions.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid permission manifest: %w", err)
+	}
+	return manifest.Permissions, nil
+}
+
+// MustParsePermissionManifest is like ParsePermissionManifest but panics on an invalid manifest.
+// It is meant for the metadata.yaml embedded in a plugin binary.
+func MustParsePermissionManifest(metadata []byte) *PluginPermissions {
+	permissions, err := ParsePermissionManifest(metadata)
+	if err != nil {
+		panic(err)
+	}
+	return permissions
+}
+
+// Validate checks that write paths are absolute and commands are named
+func (p *PluginPermissions) Validate() error {
+	for _, path := range p.Write {
+		if !isManifestPath(path) {
+			return fmt.Errorf("write path %q must be absolute or start with ~/", path)
+		}
+	}
+	for _, command := range p.Exec {
+		if command == "" || (strings.ContainsRune(command, '/') && !isManifestPath(command)) {
+			return fmt.Errorf("exec entry %q must be a command name, an absolute path or start with ~/", command)
+		}
+	}
+	for _, name := range p.Env {
+		if name == "" || strings.ContainsAny(name, "= ") {
+			return fmt.Errorf("invalid environment variable name %q", name)
+		}
+	}
+	return nil
+}
+
+// Describe lists the permissions in words, for consent prompts. A nil manifest grants full
+// access.
+func (p *PluginPermissions) Describe() []string {
+	if p == nil {
+		return []string{"full access to your user account (the plugin has no permission manifest)"}
+	}
+
+	var lines []string
+	if p.Sudo {
+		lines = append(lines, "run commands as root with sudo")
+	}
+	if p.Network {
+		lines = append(lines, "connect to the network")
+	}
+	for _, path := range p.Write {
+		lines = append(lines, "write to "+path)
+	}
+	for _, command := range p.Exec {
+		lines = append(lines, "run "+command)
+	}
+	for _, name := range p.Env {
+		lines = append(lines, "read the environment variable "+name)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "no access beyond reading files")
+	}
+	return lines
+}
+
+// Exceeds lists the permissions of p that granted does not cover. A nil manifest
+// stands for full access.
+func (p *PluginPermissions) Exceeds(granted *PluginPermissions) []string {
+	if granted == nil {
+		return nil
+	}
+	if p == nil {
+		return p.Describe()
+	}
+
+	var missing []string
+	if p.Sudo && !granted.Sudo {
+		missing = append(missing, "run commands as root with sudo")
+	}
+	if p.Network && !granted.Network {
+		missing = append(missing, "connect to the network")
+	}
+	for _, path := range p.Write {
+		if !slices.ContainsFunc(granted.Write, func(allowed string) bool { return pathWithin(path, allowed) }) {
+			missing = append(missing, "write to "+path)
+		}
+	}
+	for _, command := range p.Exec {
+		if !slices.Contains(granted.Exec, command) {
+			missing = append(missing, "run "+command)
+		}
+	}
+	for _, name := range p.Env {
+		if !slices.Contains(granted.Env, name) {
+			missing = append(missing, "read the environment variable "+name)
+		}
+	}
+	return missing
+}
+
+// AllowsCommand reports whether a command may be run. name is a command name or path; a path
+// is allowed when it matches an exec entry or lies beneath a directory entry.
+func (p *PluginPermissions) AllowsCommand(name string) bool {
+	for _, allowed := range p.Exec {
+		allowed = expandHome(allowed)
+		if allowed == name || allowed == filepath.Base(name) {
+			return true
+		}
+		if filepath.IsAbs(allowed) && filepath.IsAbs(name) && pathWithin(name, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsRootCommand reports whether a command may be run as root. Unlike AllowsCommand, a path
+// must be listed itself or lie beneath a listed directory: a program the plugin wrote elsewhere
+// must not run as root because its name matches an entry.
+func (p *PluginPermissions) allowsRootCommand(name string) bool {
+	if !strings.ContainsRune(name, '/') {
+		return !strings.HasPrefix(name, "-") && slices.Contains(p.Exec, name)
+	}
+	if !filepath.IsAbs(name) {
+		return false
+	}
+	return slices.ContainsFunc(p.Exec, func(allowed string) bool {
+		allowed = expandHome(allowed)
+		return filepath.IsAbs(allowed) && pathWithin(name, allowed)
+	})
+}
+
+// isManifestPath reports whether a manifest path is absolute or relative to the home directory
+func isManifestPath(path string) bool {
+	return path == "~" || strings.HasPrefix(path, "~/") || filepath.IsAbs(path)
+}
+
+// pathWithin reports whether path is dir or lies beneath it
+func pathWithin(path, dir string) bool {
+	path, dir = filepath.Clean(path), filepath.Clean(dir)
+	return path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, "/")+"/")
+}
+
+// expandHome resolves a leading ~ in a manifest path
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// PermissionViolationError is returned when a plugin attempts something its permission manifest
+// does not allow
+type PermissionViolationError struct {
+	Plugin     string
+	Permission string
+}
+
+func (e *PermissionViolationError) Error() string {
+	if e.Permission == "" {
+		return fmt.Sprintf("plugin %s violated its permission manifest", e.Plugin)
+	}
+	return fmt.Sprintf("plugin %s violated its permission manifest: %s is not permitted", e.Plugin, e.Permission)
+}
+
+// PermissionsNotGrantedError is returned when a plugin requests permissions the user has not
+// granted
+type PermissionsNotGrantedError struct {
+	Plugin    string
+	Requested *PluginPermissions
+	Missing   []string
+}
+
+func (e *PermissionsNotGrantedError) Error() string {
+	return fmt.Sprintf("plugin %s requests permissions that have not been granted: %s", e.Plugin, strings.Join(e.Missing, ", "))
+}
+
+// PermissionGrant records the permissions a user granted to an installed plugin
+type PermissionGrant struct {
+	// Permissions granted, or nil when the plugin has no manifest and was granted full access
+	Permissions *PluginPermissions `json:"permissions"`
+	GrantedAt   time.Time          `json:"granted_at"`
+}
+
+// Covers reports whether the grant allows everything a manifest requests, listing what it does
+// not allow otherwise
+func (g *PermissionGrant) Covers(requested *PluginPermissions) (bool, []string) {
+	if g == nil {
+		return false, requested.Describe()
+	}
+	missing := requested.Exceeds(g.Permissions)
+	return len(missing) == 0, missing
+}
+
+// PermissionGrantPath returns where the permission grant of an installed plugin is stored
+func PermissionGrantPath(pluginDir, pluginName string) string {
+	return filepath.Join(pluginDir, permissionsDir, pluginName+".json")
+}
+
+// SavePermissionGrant stores the permissions granted to an installed plugin. A nil grant
+// removes a stored one.
+func SavePermissionGrant(pluginDir, pluginName string, grant *PermissionGrant) error {
+	path := PermissionGrantPath(pluginDir, pluginName)
+	if grant == nil {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove permission grant: %w", err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(grant, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode permission grant: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create permissions directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save permission grant: %w", err)
+	}
+	return nil
+}
+
+// LoadPermissionGrant reads the permissions granted to an installed plugin, returning nil when
+// none have been granted
+func LoadPermissionGrant(pluginDir, pluginName string) (*PermissionGrant, error) {
+	data, err := os.ReadFile(PermissionGrantPath(pluginDir, pluginName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read permission grant: %w", err)
+	}
+
+	var grant PermissionGrant
+	if err := json.Unmarshal(data, &grant); err != nil {
+		return nil, fmt.Errorf("invalid permission grant for %s: %w", pluginName, err)
+	}
+	return &grant, nil
+}
+
+// pluginPermissionsFromEnv returns the permissions the running plugin was started with, or nil
+// when it runs outside the sandbox
+func pluginPermissionsFromEnv() *PluginPermissions {
+	value := os.Getenv(PermissionsEnvVar)
+	if value == "" {
+		return nil
+	}
+	var permissions PluginPermissions
+	if err := json.Unmarshal([]byte(value), &permissions); err != nil {
+		// A corrupt value must not lift the restrictions
+		return &PluginPermissions{}
+	}
+	return &permissions
+}
+
+// runningPluginName returns the name of the running plugin, from its executable name
+func runningPluginName() string {
+	return strings.TrimPrefix(filepath.Base(os.Args[0]), "devex-plugin-")
+}
+
+// CheckCommandPermission returns a PermissionViolationError when the running plugin may not run
+// a command. It allows everything outside the sandbox.
+func CheckCommandPermission(useSudo bool, name string) error {
+	permissions := pluginPermissionsFromEnv()
+	if permissions == nil {
+		return nil
+	}
+
+	plugin := runningPluginName()
+	if useSudo && !permissions.Sudo {
+		return &PermissionViolationError{Plugin: plugin, Permission: "sudo"}
+	}
+	if !permissions.AllowsCommand(name) {
+		return &PermissionViolationError{Plugin: plugin, Permission: "running " + name}
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package sdk_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+var _ = Describe("Plugin permissions", func() {
+	It("parses the permission manifest of metadata.yaml", func() {
+		permissions, err := sdk.ParsePermissionManifest([]byte(`
+name: package-manager-test
+permissions:
+  sudo: true
+  network: true
+  write: [/etc/apt, ~/.cache/test]
+  exec: [apt-get, /usr/bin/dpkg]
+  env: [DEBIAN_FRONTEND]
+`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(permissions.Sudo).To(BeTrue())
+		Expect(permissions.Write).To(Equal([]string{"/etc/apt", "~/.cache/test"}))
+		Expect(permissions.Describe()).To(ContainElements("run commands as root with sudo", "run apt-get", "write to ~/.cache/test"))
+
+		permissions, err = sdk.ParsePermissionManifest([]byte("name: legacy\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(permissions).To(BeNil())
+
+		_, err = sdk.ParsePermissionManifest([]byte("permissions:\n  write: [relative/path]\n"))
+		Expect(err).To(MatchError(ContainSubstring("must be absolute")))
+		Expect(func() { sdk.MustParsePermissionManifest([]byte("permissions:\n  exec: [bin/tool]\n")) }).To(Panic())
+	})
+
+	It("reports permissions a grant does not cover", func() {
+		granted := &sdk.PermissionGrant{Permissions: &sdk.PluginPermissions{Write: []string{"/var/lib"}, Exec: []string{"git"}}}
+
+		covered, _ := granted.Covers(&sdk.PluginPermissions{Write: []string{"/var/lib/test"}, Exec: []string{"git"}})
+		Expect(covered).To(BeTrue())
+
+		covered, missing := granted.Covers(&sdk.PluginPermissions{Network: true, Write: []string{"/var/libfoo"}, Exec: []string{"curl"}})
+		Expect(covered).To(BeFalse())
+		Expect(missing).To(Equal([]string{"connect to the network", "write to /var/libfoo", "run curl"}))
+
+		covered, _ = granted.Covers(nil)
+		Expect(covered).To(BeFalse(), "a plugin without a manifest needs full access")
+		covered, _ = (&sdk.PermissionGrant{}).Covers(nil)
+		Expect(covered).To(BeTrue())
+
+		covered, _ = (*sdk.PermissionGrant)(nil).Covers(&sdk.PluginPermissions{})
+		Expect(covered).To(BeFalse())
+	})
+
+	It("scrubs the environment of confined plugins", func() {
+		env := []string{
+			"PATH=/usr/bin:/bin", "HOME=/home/dev", "LANG=C.UTF-8", "LC_ALL=C", "DEVEX_DEBUG=1",
+			"GITHUB_TOKEN=secret", "LD_PRELOAD=/tmp/evil.so", "HTTPS_PROXY=http://proxy:3128",
+			"DEBIAN_FRONTEND=noninteractive", sdk.PermissionsEnvVar + "={}",
+		}
+
+		scrubbed := sdk.SandboxEnvironment(env, &sdk.PluginPermissions{Env: []string{"DEBIAN_FRONTEND"}})
+		Expect(scrubbed).To(ContainElements("PATH=/usr/bin:/bin", "LC_ALL=C", "DEVEX_DEBUG=1", "DEBIAN_FRONTEND=noninteractive"))
+		Expect(scrubbed).ToNot(ContainElements("GITHUB_TOKEN=secret", "HTTPS_PROXY=http://proxy:3128"))
+		Expect(scrubbed).To(ContainElement(sdk.PermissionsEnvVar + `={"env":["DEBIAN_FRONTEND"]}`))
+
+		Expect(sdk.SandboxEnvironment(env, &sdk.PluginPermissions{Network: true})).To(ContainElement("HTTPS_PROXY=http://proxy:3128"))
+
+		unrestricted := sdk.SandboxEnvironment(env, nil)
+		Expect(unrestricted).To(ContainElement("GITHUB_TOKEN=secret"))
+		Expect(unrestricted).ToNot(ContainElement("LD_PRELOAD=/tmp/evil.so"))
+		Expect(strings.Join(unrestricted, "\n")).ToNot(ContainSubstring(sdk.PermissionsEnvVar))
+	})
+
+	It("checks commands run through the SDK against the manifest", func() {
+		GinkgoT().Setenv(sdk.PermissionsEnvVar, `{"exec":["git","/opt/tools"]}`)
+
+		Expect(sdk.CheckCommandPermission(false, "git")).To(Succeed())
+		Expect(sdk.CheckCommandPermission(false, "/opt/tools/bin/lint")).To(Succeed())
+
+		err := sdk.CheckCommandPermission(false, "curl")
+		var violation *sdk.PermissionViolationError
+		Expect(errors.As(err, &violation)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("running curl is not permitted")))
+		Expect(sdk.CheckCommandPermission(true, "git")).To(MatchError(ContainSubstring("sudo is not permitted")))
+
+		_, err = sdk.RunCommand("curl", "--version")
+		Expect(errors.As(err, &violation)).To(BeTrue())
+	})
+
+	Describe("sandboxed plugins", func() {
+		var (
+			manager   *sdk.ExecutableManager
+			pluginDir string
+			base      string
+		)
+
+		// installPlugin installs a shell script plugin with the given manifest
+		installPlugin := func(name string, permissions *sdk.PluginPermissions, script string) {
+			info := `{"name": "` + name + `", "version": "1.0.0"`
+			if permissions != nil {
+				info += `, "permissions": {"write": ["` + filepath.Join(base, "allowed") + `"]}`
+			}
+			info += `}`
+			contents := "#!/bin/sh\nif [ \"$1\" = --plugin-info ]; then echo '" + info + "'; exit 0; fi\n" + script + "\n"
+			Expect(os.WriteFile(filepath.Join(pluginDir, "devex-plugin-"+name), []byte(contents), 0755)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			base = GinkgoT().TempDir()
+			pluginDir = filepath.Join(base, "plugins")
+			Expect(os.MkdirAll(pluginDir, 0755)).To(Succeed())
+			Expect(os.Mkdir(filepath.Join(base, "allowed"), 0755)).To(Succeed())
+			Expect(os.Mkdir(filepath.Join(base, "forbidden"), 0755)).To(Succeed())
+			Expect(os.Mkdir(filepath.Join(base, "tmp"), 0755)).To(Succeed())
+			// Keep the directories of the test outside the temporary directory every plugin may write
+			GinkgoT().Setenv("TMPDIR", filepath.Join(base, "tmp"))
+
+			launcher, err := os.Executable()
+			Expect(err).ToNot(HaveOccurred())
+			manager = sdk.NewExecutableManager(pluginDir)
+			manager.SetSandbox(&sdk.Sandbox{Launcher: launcher})
+		})
+
+		It("refuses plugins whose permissions have not been granted", func() {
+			installPlugin("writer", &sdk.PluginPermissions{}, "exit 0")
+
+			err := manager.ExecutePlugin("writer", nil)
+			var notGranted *sdk.PermissionsNotGrantedError
+			Expect(errors.As(err, &notGranted)).To(BeTrue())
+			Expect(notGranted.Missing).To(ContainElement("write to " + filepath.Join(base, "allowed")))
+
+			Expect(sdk.SavePermissionGrant(pluginDir, "writer", &sdk.PermissionGrant{Permissions: notGranted.Requested})).To(Succeed())
+			Expect(manager.ExecutePlugin("writer", nil)).To(Succeed())
+
+			Expect(manager.RemovePlugin("writer")).To(Succeed())
+			Expect(sdk.PermissionGrantPath(pluginDir, "writer")).ToNot(BeAnExistingFile())
+		})
+
+		It("reports plugins that exit after violating their manifest", func() {
+			installPlugin("violator", nil, "exit 77")
+			Expect(sdk.SavePermissionGrant(pluginDir, "violator", &sdk.PermissionGrant{})).To(Succeed())
+
+			Expect(manager.ExecutePlugin("violator", nil)).To(MatchError("plugin violator violated its permission manifest"))
+		})
+
+		It("confines writes and commands with Landlock", func() {
+			if !strings.HasPrefix(sdk.SandboxEnforcement(), "Landlock") {
+				Skip("Landlock is not available: " + sdk.SandboxEnforcement())
+			}
+
+			allowed := filepath.Join(base, "allowed", "file")
+			forbidden := filepath.Join(base, "forbidden", "file")
+			installPlugin("writer", &sdk.PluginPermissions{}, `echo ok > "$1" && exit 0; exit 3`)
+			Expect(sdk.SavePermissionGrant(pluginDir, "writer", &sdk.PermissionGrant{
+				Permissions: &sdk.PluginPermissions{Write: []string{filepath.Join(base, "allowed")}},
+			})).To(Succeed())
+
+			run := func(args ...string) error {
+				cmd, err := manager.PluginCommand(context.Background(), "writer", args...)
+				Expect(err).ToNot(HaveOccurred())
+				return cmd.Run()
+			}
+			Expect(run(allowed)).To(Succeed())
+			Expect(allowed).To(BeARegularFile())
+			Expect(run(forbidden)).To(HaveOccurred())
+			Expect(forbidden).ToNot(BeAnExistingFile())
+
+			installPlugin("writer", &sdk.PluginPermissions{}, `touch "$1"`)
+			Expect(run(filepath.Join(base, "allowed", "touched"))).To(HaveOccurred(), "touch is not in the manifest")
+		})
+
+		It("confines plugins granted sudo and runs their commands as root through the audited helper", func() {
+			if !strings.HasPrefix(sdk.SandboxEnforcement(), "Landlock") {
+				Skip("Landlock is not available: " + sdk.SandboxEnforcement())
+			}
+
+			testBinary, err := os.Executable()
+			Expect(err).ToNot(HaveOccurred())
+			permissions := &sdk.PluginPermissions{Sudo: true, Exec: []string{testBinary, "touch"}}
+			manifest, err := json.Marshal(permissions)
+			Expect(err).ToNot(HaveOccurred())
+			info := `{"name": "admin", "version": "1.0.0", "permissions": ` + string(manifest) + `}`
+			contents := "#!/bin/sh\nif [ \"$1\" = --plugin-info ]; then echo '" + info + "'; exit 0; fi\n" +
+				"echo confined > \"$1\" && exit 3\nshift\nexec '" + testBinary + "' " + sudoCommandArg + " \"$@\"\n"
+			Expect(os.WriteFile(filepath.Join(pluginDir, "devex-plugin-admin"), []byte(contents), 0755)).To(Succeed())
+			Expect(sdk.SavePermissionGrant(pluginDir, "admin", &sdk.PermissionGrant{Permissions: permissions})).To(Succeed())
+			auditLog := filepath.Join(base, "audit")
+			GinkgoT().Setenv(sudoAuditEnvVar, auditLog)
+
+			forbidden := filepath.Join(base, "forbidden", "file")
+			run := func(args ...string) error {
+				cmd, err := manager.PluginCommand(context.Background(), "admin", append([]string{forbidden}, args...)...)
+				Expect(err).ToNot(HaveOccurred())
+				return sdk.PluginExitError("admin", cmd.Run())
+			}
+
+			// The plugin cannot write the file itself, the helper creates it for the permitted touch
+			Expect(run("touch", forbidden)).To(Succeed())
+			Expect(forbidden).To(BeARegularFile())
+			Expect(os.ReadFile(forbidden)).To(BeEmpty())
+
+			var violation *sdk.PermissionViolationError
+			Expect(errors.As(run("rm", forbidden), &violation)).To(BeTrue())
+			Expect(forbidden).To(BeARegularFile())
+			Expect(errors.As(run(filepath.Join(base, "allowed", "touch"), forbidden), &violation)).To(BeTrue(),
+				"a program named like a permitted command must not run as root")
+
+			// The SDK refuses rm before asking the helper, which records what it was asked to run
+			Expect(os.ReadFile(auditLog)).To(SatisfyAll(
+				ContainSubstring("admin: touch "+forbidden+" (<nil>)"),
+				ContainSubstring("admin: "+filepath.Join(base, "allowed", "touch")+" "+forbidden+" (plugin admin violated its permission manifest"),
+				Not(ContainSubstring("admin: rm")),
+			))
+		})
+
+		It("reads plugin metadata without permissions", func() {
+			if !strings.HasPrefix(sdk.SandboxEnforcement(), "Landlock") {
+				Skip("Landlock is not available: " + sdk.SandboxEnforcement())
+			}
+
+			written := filepath.Join(base, "allowed", "info")
+			contents := "#!/bin/sh\nif [ \"$1\" = --plugin-info ]; then echo x > '" + written + "'; " +
+				`echo '{"name": "reporter", "version": "1.0.0"}'; exit 0; fi` + "\n"
+			Expect(os.WriteFile(filepath.Join(pluginDir, "devex-plugin-reporter"), []byte(contents), 0755)).To(Succeed())
+
+			Expect(manager.DiscoverPlugins()).To(Succeed())
+			Expect(manager.ListPlugins()).To(HaveKeyWithValue("reporter", HaveField("Version", "1.0.0")))
+			Expect(written).ToNot(BeAnExistingFile())
+		})
+	})
+})
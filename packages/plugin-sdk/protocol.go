@@ -0,0 +1,623 @@
+package sdk
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ProtocolVersion is the version of the structured plugin protocol spoken by this SDK.
+// Plugins advertise it through PluginInfo.ProtocolVersion; plugins reporting 0 are legacy
+// plugins that only understand raw command-line arguments. Version 2 added RPCRequestFlag.
+const ProtocolVersion = 2
+
+// RPCFlag is the argument that switches a plugin into protocol mode.
+// In protocol mode the plugin reads one JSON-RPC 2.0 request per line from stdin and
+// writes event notifications followed by a single response per request to stdout.
+const RPCFlag = "--rpc"
+
+// RPCRequestFlag serves the single request passed as the argument after it. The plugin keeps
+// the stdin of the CLI, so the package managers it runs can prompt the user. Install and
+// remove requests are sent this way.
+const RPCRequestFlag = "--rpc-request"
+
+// requestArgProtocolVersion is the first protocol version that understands RPCRequestFlag
+const requestArgProtocolVersion = 2
+
+// interactiveMethods run commands that may prompt, so their requests are passed as an
+// argument instead of replacing stdin
+var interactiveMethods = map[string]bool{
+	MethodInstall: true,
+	MethodRemove:  true,
+}
+
+// jsonRPCVersion is the JSON-RPC version carried by every message
+const jsonRPCVersion = "2.0"
+
+// Protocol methods understood by package manager plugins
+const (
+	MethodInstall     = "install"
+	MethodRemove      = "remove"
+	MethodIsInstalled = "is-installed"
+	MethodList        = "list"
+	MethodSearch      = "search"
+	MethodInfo        = "info"
+	MethodResolve     = "resolve"
+	MethodVersion     = "version"
+	MethodOptions     = "options"
+)
+
+// Event notification methods sent by plugins while a request is running
+const (
+	EventProgress = "progress"
+	EventLog      = "log"
+)
+
+// Error codes carried in RPCError. The negative codes are the standard JSON-RPC 2.0 codes,
+// the positive codes are DevEx specific.
+const (
+	ErrCodeParse              = -32700
+	ErrCodeInvalidRequest     = -32600
+	ErrCodeMethodNotFound     = -32601
+	ErrCodeInvalidParams      = -32602
+	ErrCodeInternal           = -32603
+	ErrCodeOperationFailed    = 1000
+	ErrCodePackageNotFound    = 1001
+	ErrCodeManagerUnavailable = 1002
+	ErrCodePermissionDenied   = 1003
+	ErrCodeTimeout            = 1004
+	ErrCodeCancelled          = 1005
+	ErrCodeUnsupportedVersion = 1006
+)
+
+// ErrProtocolUnsupported is returned by CallPlugin for legacy plugins that do not speak the protocol.
+// Callers should fall back to ExecutePlugin.
+var ErrProtocolUnsupported = errors.New("plugin does not support the structured protocol")
+
+// RPCParams are the typed parameters of a protocol request
+type RPCParams struct {
+	// Packages the operation applies to (install, remove, is-installed, info)
+	Packages []string `json:"packages,omitempty"`
+
+	// Query is the search term for search requests
+	Query string `json:"query,omitempty"`
+
+	// Constraint is the version constraint for resolve requests, see ParseVersionConstraint
+	Constraint string `json:"constraint,omitempty"`
+
+	// Options carries method specific flags, e.g. {"purge": "true"}
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// RPCRequest is a JSON-RPC 2.0 request sent from the CLI to a plugin
+type RPCRequest struct {
+	JSONRPC  string    `json:"jsonrpc"`
+	ID       int64     `json:"id"`
+	Method   string    `json:"method"`
+	Params   RPCParams `json:"params"`
+	Protocol int       `json:"protocol"`
+}
+
+// NewRPCRequest creates a request for the current protocol version
+func NewRPCRequest(method string, params RPCParams) *RPCRequest {
+	return &RPCRequest{
+		JSONRPC:  jsonRPCVersion,
+		ID:       1,
+		Method:   method,
+		Params:   params,
+		Protocol: ProtocolVersion,
+	}
+}
+
+// PackageInfo describes a single package in list, search, info, resolve and version results
+type PackageInfo struct {
+	Name        string `json:"name"`
+	Version     string `json:"version,omitempty"`
+	Description string `json:"description,omitempty"`
+	Installed   bool   `json:"installed,omitempty"`
+
+	// Source is the repository, remote or index the version comes from
+	Source string `json:"source,omitempty"`
+
+	// Spec is the install argument that selects exactly this version, e.g. "git=1:2.43.0-1" for APT
+	Spec string `json:"spec,omitempty"`
+}
+
+// RPCResult is the typed result of a successful request
+type RPCResult struct {
+	// Installed reports the state of each requested package for is-installed requests
+	Installed map[string]bool `json:"installed,omitempty"`
+
+	// Packages holds list, search and info results, and the versions reported by resolve and version
+	Packages []PackageInfo `json:"packages,omitempty"`
+
+	// Options holds the choices returned by options requests
+	Options []OptionInfo `json:"options,omitempty"`
+
+	// Message is a human-readable summary of the operation
+	Message string `json:"message,omitempty"`
+
+	// Output is the raw text output of plugins that do not produce structured results
+	Output string `json:"output,omitempty"`
+}
+
+// RPCError is a structured error returned by a plugin
+type RPCError struct {
+	Code    int                    `json:"code"`
+	Message string                 `json:"message"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("plugin error %d: %s", e.Code, e.Message)
+}
+
+// NewRPCError creates a structured error with the given code
+func NewRPCError(code int, format string, args ...any) *RPCError {
+	return &RPCError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// RPCResponse is the JSON-RPC 2.0 response that terminates a request
+type RPCResponse struct {
+	JSONRPC string     `json:"jsonrpc"`
+	ID      int64      `json:"id"`
+	Result  *RPCResult `json:"result,omitempty"`
+	Error   *RPCError  `json:"error,omitempty"`
+}
+
+// RPCEventParams are the parameters of progress and log notifications
+type RPCEventParams struct {
+	Progress int    `json:"progress,omitempty"`
+	Message  string `json:"message"`
+	Level    string `json:"level,omitempty"`
+	Package  string `json:"package,omitempty"`
+}
+
+// RPCEvent is a JSON-RPC 2.0 notification streamed by a plugin while a request runs
+type RPCEvent struct {
+	JSONRPC string         `json:"jsonrpc"`
+	Method  string         `json:"method"`
+	Params  RPCEventParams `json:"params"`
+}
+
+// rpcMessage is the union of responses and events used to decode plugin output
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  *RPCResult      `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// EventWriter streams event notifications to the CLI. It is safe for concurrent use.
+type EventWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewEventWriter creates an event writer on top of w
+func NewEventWriter(w io.Writer) *EventWriter {
+	return &EventWriter{enc: json.NewEncoder(w)}
+}
+
+// Progress sends a progress notification (0-100)
+func (ew *EventWriter) Progress(progress int, message string) error {
+	return ew.send(EventProgress, RPCEventParams{Progress: progress, Message: message})
+}
+
+// Log sends a log notification with the given level ("debug", "info", "warn", "error")
+func (ew *EventWriter) Log(level, message string) error {
+	return ew.send(EventLog, RPCEventParams{Level: level, Message: message})
+}
+
+func (ew *EventWriter) send(method string, params RPCEventParams) error {
+	if ew == nil {
+		return nil
+	}
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+	return ew.enc.Encode(RPCEvent{JSONRPC: jsonRPCVersion, Method: method, Params: params})
+}
+
+// write encodes a response under the same lock as events so lines are never interleaved
+func (ew *EventWriter) write(resp *RPCResponse) error {
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+	return ew.enc.Encode(resp)
+}
+
+// RPCHandler is implemented by plugins that handle protocol requests natively.
+// Plugins that only implement Plugin are served through the legacy adapter, which maps
+// requests onto Execute.
+type RPCHandler interface {
+	HandleRPC(ctx context.Context, req *RPCRequest, events *EventWriter) (*RPCResult, error)
+}
+
+// ServeRPC reads line-delimited requests from r until EOF and writes events and responses to w.
+// Errors returned by the handler are sent as RPCError values; non-RPCError errors use ErrCodeOperationFailed.
+func ServeRPC(ctx context.Context, handler RPCHandler, r io.Reader, w io.Writer) error {
+	events := NewEventWriter(w)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var req RPCRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			if err := events.write(&RPCResponse{
+				JSONRPC: jsonRPCVersion,
+				Error:   NewRPCError(ErrCodeParse, "invalid request: %v", err),
+			}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		resp := &RPCResponse{JSONRPC: jsonRPCVersion, ID: req.ID}
+		switch {
+		case req.Method == "":
+			resp.Error = NewRPCError(ErrCodeInvalidRequest, "request has no method")
+		case req.Protocol > ProtocolVersion:
+			resp.Error = NewRPCError(ErrCodeUnsupportedVersion,
+				"protocol version %d is not supported (plugin supports up to %d)", req.Protocol, ProtocolVersion)
+		default:
+			result, err := handler.HandleRPC(ctx, &req, events)
+			if err != nil {
+				resp.Error = toRPCError(err)
+			} else {
+				if result == nil {
+					result = &RPCResult{}
+				}
+				resp.Result = result
+			}
+		}
+
+		if err := events.write(resp); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// toRPCError converts a handler error into a structured error
+func toRPCError(err error) *RPCError {
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) {
+		return NewRPCError(ErrCodeTimeout, "%s", err.Error())
+	}
+	if errors.Is(err, context.Canceled) {
+		return NewRPCError(ErrCodeCancelled, "%s", err.Error())
+	}
+	if errors.Is(err, os.ErrPermission) {
+		return NewRPCError(ErrCodePermissionDenied, "%s", err.Error())
+	}
+	if errors.Is(err, ErrNoMatchingVersion) || errors.Is(err, ErrNotInstalled) {
+		return NewRPCError(ErrCodePackageNotFound, "%s", err.Error())
+	}
+	return NewRPCError(ErrCodeOperationFailed, "%s", err.Error())
+}
+
+// legacyRPCHandler serves protocol requests for plugins that only implement Execute.
+// Anything Execute prints to stdout is captured so it cannot corrupt the protocol stream.
+type legacyRPCHandler struct {
+	plugin Plugin
+}
+
+// NewLegacyRPCHandler adapts a plain Plugin to the protocol by mapping requests onto Execute
+func NewLegacyRPCHandler(plugin Plugin) RPCHandler {
+	return &legacyRPCHandler{plugin: plugin}
+}
+
+func (h *legacyRPCHandler) HandleRPC(ctx context.Context, req *RPCRequest, events *EventWriter) (*RPCResult, error) {
+	if !h.supports(req.Method) {
+		return nil, NewRPCError(ErrCodeMethodNotFound, "plugin %s does not support %q", h.plugin.Info().Name, req.Method)
+	}
+
+	switch req.Method {
+	case MethodIsInstalled:
+		if len(req.Params.Packages) == 0 {
+			return nil, NewRPCError(ErrCodeInvalidParams, "is-installed requires at least one package")
+		}
+		installed := make(map[string]bool, len(req.Params.Packages))
+		for _, pkg := range req.Params.Packages {
+			_, err := captureStdout(func() error {
+				return h.plugin.Execute(MethodIsInstalled, []string{pkg})
+			})
+			switch IsInstalledExitCode(err) {
+			case ExitCodeInstalled:
+				installed[pkg] = true
+			case ExitCodeNotInstalled:
+				installed[pkg] = false
+			default:
+				return nil, toRPCError(err)
+			}
+		}
+		return &RPCResult{Installed: installed}, nil
+
+	case MethodInstall, MethodRemove, MethodInfo, MethodResolve, MethodVersion:
+		if len(req.Params.Packages) == 0 {
+			return nil, NewRPCError(ErrCodeInvalidParams, "%s requires at least one package", req.Method)
+		}
+
+	case MethodOptions:
+		if req.Params.Query == "" {
+			return nil, NewRPCError(ErrCodeInvalidParams, "options requires an option set")
+		}
+	}
+
+	args := legacyArgs(req)
+	_ = events.Progress(0, fmt.Sprintf("Running %s", req.Method))
+	output, err := captureStdout(func() error {
+		return h.plugin.Execute(req.Method, args)
+	})
+	if err != nil {
+		rpcErr := toRPCError(err)
+		if output != "" {
+			rpcErr.Data = map[string]interface{}{"output": output}
+		}
+		return nil, rpcErr
+	}
+	_ = events.Progress(100, fmt.Sprintf("Finished %s", req.Method))
+
+	if req.Method == MethodResolve || req.Method == MethodVersion {
+		packages, err := ParsePackageInfoLines(output)
+		if err != nil {
+			return nil, NewRPCError(ErrCodeInternal, "%s", err.Error())
+		}
+		return &RPCResult{Packages: packages}, nil
+	}
+
+	if req.Method == MethodOptions {
+		options, err := ParseOptionLines(output)
+		if err != nil {
+			return nil, NewRPCError(ErrCodeInternal, "%s", err.Error())
+		}
+		return &RPCResult{Options: options}, nil
+	}
+
+	return &RPCResult{Output: output}, nil
+}
+
+// supports reports whether the plugin lists the method among its commands.
+// Plugins that do not declare commands are assumed to handle every method.
+func (h *legacyRPCHandler) supports(method string) bool {
+	commands := h.plugin.Info().Commands
+	if len(commands) == 0 {
+		return true
+	}
+	for _, command := range commands {
+		if command.Name == method {
+			return true
+		}
+	}
+	return false
+}
+
+// legacyArgs converts request parameters back into command-line arguments
+func legacyArgs(req *RPCRequest) []string {
+	names := make([]string, 0, len(req.Params.Options))
+	for name := range req.Params.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var args []string
+	for _, name := range names {
+		value := req.Params.Options[name]
+		switch value {
+		case "", "true":
+			args = append(args, "--"+name)
+		case "false":
+		default:
+			args = append(args, "--"+name+"="+value)
+		}
+	}
+	if req.Params.Constraint != "" {
+		args = append(args, ConstraintFlag+req.Params.Constraint)
+	}
+	if req.Params.Query != "" {
+		args = append(args, req.Params.Query)
+	}
+	return append(args, req.Params.Packages...)
+}
+
+// stdoutMu serializes stdout redirection in captureStdout
+var stdoutMu sync.Mutex
+
+// captureStdout runs fn with os.Stdout redirected into a buffer and returns what was written
+func captureStdout(fn func() error) (string, error) {
+	stdoutMu.Lock()
+	defer stdoutMu.Unlock()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return "", fmt.Errorf("failed to capture plugin output: %w", err)
+	}
+
+	var buf bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(&buf, reader)
+		close(done)
+	}()
+
+	original := os.Stdout
+	os.Stdout = writer
+	runErr := fn()
+	os.Stdout = original
+
+	_ = writer.Close()
+	<-done
+	_ = reader.Close()
+
+	return strings.TrimSpace(buf.String()), runErr
+}
+
+// serveRPCFromArgs runs the protocol loop on stdin/stdout for HandleArgs
+func serveRPCFromArgs(plugin Plugin) error {
+	return ServeRPC(context.Background(), rpcHandlerFor(plugin), os.Stdin, os.Stdout)
+}
+
+// serveRPCRequestFromArgs serves a single request passed as an argument for HandleArgs,
+// leaving stdin to the commands the plugin runs
+func serveRPCRequestFromArgs(plugin Plugin, request string) error {
+	return ServeRPC(context.Background(), rpcHandlerFor(plugin), strings.NewReader(request), os.Stdout)
+}
+
+// rpcHandlerFor returns the protocol handler of a plugin, adapting plugins that only implement Execute
+func rpcHandlerFor(plugin Plugin) RPCHandler {
+	handler, ok := plugin.(RPCHandler)
+	if !ok {
+		handler = NewLegacyRPCHandler(plugin)
+	}
+	return handler
+}
+
+// CallPlugin sends a structured request to a plugin and waits for its response.
+// Events streamed by the plugin are passed to onEvent, which may be nil.
+// Returns ErrProtocolUnsupported for legacy plugins so callers can fall back to ExecutePlugin.
+// Install and remove requests keep stdin connected to the user; plugins too old to accept
+// them as an argument are reported as legacy plugins for those methods.
+func (em *ExecutableManager) CallPlugin(ctx context.Context, pluginName string, req *RPCRequest, onEvent func(RPCEvent)) (*RPCResult, error) {
+	plugins := em.ListPlugins()
+	pluginInfo, exists := plugins[pluginName]
+	if !exists {
+		return nil, fmt.Errorf("plugin %s is not installed", pluginName)
+	}
+	if pluginInfo.ProtocolVersion < 1 {
+		return nil, ErrProtocolUnsupported
+	}
+
+	// Requests are sent with the newest version both sides speak
+	request := *req
+	request.Protocol = min(request.Protocol, pluginInfo.ProtocolVersion)
+
+	if !interactiveMethods[request.Method] {
+		cmd, err := em.PluginCommand(ctx, pluginName, RPCFlag)
+		if err != nil {
+			return nil, err
+		}
+		return callPluginCommand(ctx, cmd, &request, onEvent)
+	}
+
+	if pluginInfo.ProtocolVersion < requestArgProtocolVersion {
+		return nil, ErrProtocolUnsupported
+	}
+	payload, err := json.Marshal(&request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+	cmd, err := em.PluginCommand(ctx, pluginName, RPCRequestFlag, string(payload))
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdin = os.Stdin
+	return runPluginCommand(ctx, cmd, &request, onEvent)
+}
+
+// CallPluginBinary runs the plugin at path in protocol mode and performs a single request
+func CallPluginBinary(ctx context.Context, path string, req *RPCRequest, onEvent func(RPCEvent)) (*RPCResult, error) {
+	return callPluginCommand(ctx, exec.CommandContext(ctx, path, RPCFlag), req, onEvent)
+}
+
+// callPluginCommand starts a plugin command in protocol mode and performs a single request
+func callPluginCommand(ctx context.Context, cmd *exec.Cmd, req *RPCRequest, onEvent func(RPCEvent)) (*RPCResult, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	cmd.Stdin = bytes.NewReader(append(payload, '\n'))
+	return runPluginCommand(ctx, cmd, req, onEvent)
+}
+
+// runPluginCommand starts a plugin command whose input is already set up and reads the
+// response to req from its output
+func runPluginCommand(ctx context.Context, cmd *exec.Cmd, req *RPCRequest, onEvent func(RPCEvent)) (*RPCResult, error) {
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to plugin: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start plugin: %w", err)
+	}
+
+	resp, readErr := readRPCResponse(stdout, req.ID, onEvent)
+	waitErr := cmd.Wait()
+
+	if ctx.Err() != nil {
+		return nil, NewRPCError(ErrCodeCancelled, "%s", ctx.Err().Error())
+	}
+	if readErr != nil {
+		if waitErr != nil {
+			return nil, fmt.Errorf("plugin exited without a response: %w", waitErr)
+		}
+		return nil, readErr
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	if resp.Result == nil {
+		return &RPCResult{}, nil
+	}
+	return resp.Result, nil
+}
+
+// readRPCResponse consumes events until the response for id arrives
+func readRPCResponse(r io.Reader, id int64, onEvent func(RPCEvent)) (*rpcMessage, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var msg rpcMessage
+		if err := json.Unmarshal(line, &msg); err != nil {
+			return nil, NewRPCError(ErrCodeParse, "invalid plugin output: %v", err)
+		}
+
+		if msg.ID == nil && msg.Method != "" {
+			if onEvent != nil {
+				event := RPCEvent{JSONRPC: msg.JSONRPC, Method: msg.Method}
+				if len(msg.Params) > 0 {
+					_ = json.Unmarshal(msg.Params, &event.Params)
+				}
+				onEvent(event)
+			}
+			continue
+		}
+
+		if msg.ID != nil && *msg.ID == id || msg.Error != nil {
+			_, _ = io.Copy(io.Discard, r)
+			return &msg, nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read plugin output: %w", err)
+	}
+	return nil, errors.New("plugin closed the connection without a response")
+}
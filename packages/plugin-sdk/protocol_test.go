@@ -0,0 +1,320 @@
+package sdk_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+// fakeRPCHandler answers requests from a function so tests can control results
+type fakeRPCHandler struct {
+	handle func(req *sdk.RPCRequest, events *sdk.EventWriter) (*sdk.RPCResult, error)
+}
+
+func (h *fakeRPCHandler) HandleRPC(_ context.Context, req *sdk.RPCRequest, events *sdk.EventWriter) (*sdk.RPCResult, error) {
+	return h.handle(req, events)
+}
+
+// fakeLegacyPlugin records Execute calls and prints to stdout like a real plugin
+type fakeLegacyPlugin struct {
+	installed map[string]bool
+	calls     []string
+}
+
+func (p *fakeLegacyPlugin) Info() sdk.PluginInfo {
+	return sdk.PluginInfo{
+		Name: "package-manager-fake",
+		Commands: []sdk.PluginCommand{
+			{Name: "install"}, {Name: "is-installed"}, {Name: "list"}, {Name: "options"},
+		},
+	}
+}
+
+func (p *fakeLegacyPlugin) Execute(command string, args []string) error {
+	p.calls = append(p.calls, command+" "+strings.Join(args, " "))
+	switch command {
+	case "is-installed":
+		if args[0] == "broken" {
+			return fmt.Errorf("package database is locked")
+		}
+		if !p.installed[args[0]] {
+			return &sdk.NotInstalledError{Packages: args}
+		}
+		fmt.Printf("%s is installed\n", args[0])
+	case "list":
+		fmt.Println("git 2.43")
+	case "options":
+		fmt.Println("Listing remotes")
+		_ = sdk.PrintOption(sdk.OptionInfo{Value: "flathub", Label: "Flathub", Default: true})
+		_ = sdk.PrintOption(sdk.OptionInfo{Value: "fedora"})
+	}
+	return nil
+}
+
+func decodeLines(out *bytes.Buffer) []map[string]interface{} {
+	var messages []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var msg map[string]interface{}
+		Expect(json.Unmarshal([]byte(line), &msg)).To(Succeed())
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+func requestLine(req *sdk.RPCRequest) string {
+	payload, err := json.Marshal(req)
+	Expect(err).ToNot(HaveOccurred())
+	return string(payload) + "\n"
+}
+
+var _ = Describe("Plugin protocol", func() {
+	Describe("ServeRPC", func() {
+		It("streams events before the response", func() {
+			handler := &fakeRPCHandler{handle: func(req *sdk.RPCRequest, events *sdk.EventWriter) (*sdk.RPCResult, error) {
+				Expect(events.Progress(50, "halfway")).To(Succeed())
+				return &sdk.RPCResult{Message: "installed " + strings.Join(req.Params.Packages, ",")}, nil
+			}}
+
+			var out bytes.Buffer
+			in := strings.NewReader(requestLine(sdk.NewRPCRequest(sdk.MethodInstall, sdk.RPCParams{Packages: []string{"git"}})))
+			Expect(sdk.ServeRPC(context.Background(), handler, in, &out)).To(Succeed())
+
+			messages := decodeLines(&out)
+			Expect(messages).To(HaveLen(2))
+			Expect(messages[0]["method"]).To(Equal(sdk.EventProgress))
+			Expect(messages[1]["result"]).To(HaveKeyWithValue("message", "installed git"))
+		})
+
+		It("returns structured errors", func() {
+			handler := &fakeRPCHandler{handle: func(_ *sdk.RPCRequest, _ *sdk.EventWriter) (*sdk.RPCResult, error) {
+				return nil, sdk.NewRPCError(sdk.ErrCodePackageNotFound, "no such package")
+			}}
+
+			var out bytes.Buffer
+			in := strings.NewReader(requestLine(sdk.NewRPCRequest(sdk.MethodInstall, sdk.RPCParams{Packages: []string{"nope"}})))
+			Expect(sdk.ServeRPC(context.Background(), handler, in, &out)).To(Succeed())
+
+			errObj := decodeLines(&out)[0]["error"].(map[string]interface{})
+			Expect(errObj["code"]).To(BeEquivalentTo(sdk.ErrCodePackageNotFound))
+		})
+
+		It("rejects newer protocol versions and malformed lines", func() {
+			handler := &fakeRPCHandler{handle: func(_ *sdk.RPCRequest, _ *sdk.EventWriter) (*sdk.RPCResult, error) {
+				Fail("handler should not be called")
+				return nil, nil
+			}}
+
+			req := sdk.NewRPCRequest(sdk.MethodList, sdk.RPCParams{})
+			req.Protocol = sdk.ProtocolVersion + 1
+
+			var out bytes.Buffer
+			in := strings.NewReader(requestLine(req) + "{not json\n")
+			Expect(sdk.ServeRPC(context.Background(), handler, in, &out)).To(Succeed())
+
+			messages := decodeLines(&out)
+			Expect(messages).To(HaveLen(2))
+			Expect(messages[0]["error"]).To(HaveKeyWithValue("code", BeEquivalentTo(sdk.ErrCodeUnsupportedVersion)))
+			Expect(messages[1]["error"]).To(HaveKeyWithValue("code", BeEquivalentTo(sdk.ErrCodeParse)))
+		})
+	})
+
+	Describe("legacy adapter", func() {
+		var (
+			plugin  *fakeLegacyPlugin
+			handler sdk.RPCHandler
+		)
+
+		BeforeEach(func() {
+			plugin = &fakeLegacyPlugin{installed: map[string]bool{"git": true}}
+			handler = sdk.NewLegacyRPCHandler(plugin)
+		})
+
+		It("reports per-package install state from is-installed", func() {
+			req := sdk.NewRPCRequest(sdk.MethodIsInstalled, sdk.RPCParams{Packages: []string{"git", "curl"}})
+			result, err := handler.HandleRPC(context.Background(), req, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Installed).To(Equal(map[string]bool{"git": true, "curl": false}))
+		})
+
+		It("fails the request when a check cannot be performed", func() {
+			req := sdk.NewRPCRequest(sdk.MethodIsInstalled, sdk.RPCParams{Packages: []string{"git", "broken"}})
+			_, err := handler.HandleRPC(context.Background(), req, nil)
+
+			var rpcErr *sdk.RPCError
+			Expect(errors.As(err, &rpcErr)).To(BeTrue())
+			Expect(rpcErr.Code).To(Equal(sdk.ErrCodeOperationFailed))
+		})
+
+		It("captures stdout instead of writing it to the protocol stream", func() {
+			req := sdk.NewRPCRequest(sdk.MethodList, sdk.RPCParams{})
+			result, err := handler.HandleRPC(context.Background(), req, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Output).To(Equal("git 2.43"))
+		})
+
+		It("maps options onto command-line flags", func() {
+			req := sdk.NewRPCRequest(sdk.MethodInstall, sdk.RPCParams{
+				Packages: []string{"git"},
+				Options:  map[string]string{"yes": "true"},
+			})
+			_, err := handler.HandleRPC(context.Background(), req, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plugin.calls).To(ContainElement("install --yes git"))
+		})
+
+		It("returns the options printed by the options command", func() {
+			req := sdk.NewRPCRequest(sdk.MethodOptions, sdk.RPCParams{Query: "remotes"})
+			result, err := handler.HandleRPC(context.Background(), req, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plugin.calls).To(ContainElement("options remotes"))
+			Expect(result.Options).To(Equal([]sdk.OptionInfo{
+				{Value: "flathub", Label: "Flathub", Default: true},
+				{Value: "fedora"},
+			}))
+		})
+
+		It("requires an option set for options requests", func() {
+			req := sdk.NewRPCRequest(sdk.MethodOptions, sdk.RPCParams{})
+			_, err := handler.HandleRPC(context.Background(), req, nil)
+
+			var rpcErr *sdk.RPCError
+			Expect(errors.As(err, &rpcErr)).To(BeTrue())
+			Expect(rpcErr.Code).To(Equal(sdk.ErrCodeInvalidParams))
+		})
+
+		It("rejects methods the plugin does not declare", func() {
+			req := sdk.NewRPCRequest(sdk.MethodSearch, sdk.RPCParams{Query: "git"})
+			_, err := handler.HandleRPC(context.Background(), req, nil)
+
+			var rpcErr *sdk.RPCError
+			Expect(errors.As(err, &rpcErr)).To(BeTrue())
+			Expect(rpcErr.Code).To(Equal(sdk.ErrCodeMethodNotFound))
+		})
+	})
+
+	Describe("ExecutableManager.CallPlugin", func() {
+		var (
+			tempDir string
+			manager *sdk.ExecutableManager
+		)
+
+		writePlugin := func(name, script string) {
+			path := filepath.Join(tempDir, "devex-plugin-"+name)
+			Expect(os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			var err error
+			tempDir, err = os.MkdirTemp("", "plugin-protocol-test-*")
+			Expect(err).ToNot(HaveOccurred())
+			manager = sdk.NewExecutableManager(tempDir)
+		})
+
+		AfterEach(func() {
+			_ = os.RemoveAll(tempDir)
+		})
+
+		It("returns ErrProtocolUnsupported for legacy plugins", func() {
+			writePlugin("legacy", `echo '{"name":"legacy","version":"1.0.0"}'`)
+
+			_, err := manager.CallPlugin(context.Background(), "legacy", sdk.NewRPCRequest(sdk.MethodList, sdk.RPCParams{}), nil)
+			Expect(err).To(MatchError(sdk.ErrProtocolUnsupported))
+		})
+
+		It("delivers events and the typed result from protocol plugins", func() {
+			writePlugin("modern", `
+if [ "$1" = "--plugin-info" ]; then
+  echo '{"name":"modern","version":"1.0.0","protocol_version":1}'
+  exit 0
+fi
+read request
+echo '{"jsonrpc":"2.0","method":"progress","params":{"progress":40,"message":"checking"}}'
+echo '{"jsonrpc":"2.0","id":1,"result":{"installed":{"git":true}}}'
+`)
+
+			var events []sdk.RPCEvent
+			result, err := manager.CallPlugin(context.Background(), "modern",
+				sdk.NewRPCRequest(sdk.MethodIsInstalled, sdk.RPCParams{Packages: []string{"git"}}),
+				func(event sdk.RPCEvent) { events = append(events, event) })
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Installed).To(HaveKeyWithValue("git", true))
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Params.Progress).To(Equal(40))
+		})
+
+		It("speaks the protocol version of older plugins", func() {
+			writePlugin("older", `
+if [ "$1" = "--plugin-info" ]; then
+  echo '{"name":"older","version":"1.0.0","protocol_version":1}'
+  exit 0
+fi
+read request
+case "$request" in
+  *'"protocol":1}'*) echo '{"jsonrpc":"2.0","id":1,"result":{"installed":{"git":true}}}' ;;
+  *) echo '{"jsonrpc":"2.0","id":1,"error":{"code":1006,"message":"unsupported protocol"}}' ;;
+esac
+`)
+
+			result, err := manager.CallPlugin(context.Background(), "older",
+				sdk.NewRPCRequest(sdk.MethodIsInstalled, sdk.RPCParams{Packages: []string{"git"}}), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Installed).To(HaveKeyWithValue("git", true))
+
+			_, err = manager.CallPlugin(context.Background(), "older",
+				sdk.NewRPCRequest(sdk.MethodInstall, sdk.RPCParams{Packages: []string{"git"}}), nil)
+			Expect(err).To(MatchError(sdk.ErrProtocolUnsupported), "install needs the request as an argument")
+		})
+
+		It("passes install requests as an argument and leaves stdin alone", func() {
+			writePlugin("interactive", `
+if [ "$1" = "--plugin-info" ]; then
+  echo '{"name":"interactive","version":"1.0.0","protocol_version":2}'
+  exit 0
+fi
+if [ "$1" != "--rpc-request" ]; then
+  exit 1
+fi
+case "$2" in
+  *'"method":"install"'*'"git"'*) ;;
+  *) exit 1 ;;
+esac
+echo '{"jsonrpc":"2.0","method":"log","params":{"level":"info","message":"installing git"}}'
+echo '{"jsonrpc":"2.0","id":1,"result":{"message":"installed"}}'
+`)
+
+			var events []sdk.RPCEvent
+			result, err := manager.CallPlugin(context.Background(), "interactive",
+				sdk.NewRPCRequest(sdk.MethodInstall, sdk.RPCParams{Packages: []string{"git"}}),
+				func(event sdk.RPCEvent) { events = append(events, event) })
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Message).To(Equal("installed"))
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Params.Message).To(Equal("installing git"))
+		})
+
+		It("surfaces structured plugin errors", func() {
+			writePlugin("failing", `
+if [ "$1" = "--plugin-info" ]; then
+  echo '{"name":"failing","version":"1.0.0","protocol_version":2}'
+  exit 0
+fi
+echo '{"jsonrpc":"2.0","id":1,"error":{"code":1002,"message":"apt is not available"}}'
+`)
+
+			_, err := manager.CallPlugin(context.Background(), "failing", sdk.NewRPCRequest(sdk.MethodInstall, sdk.RPCParams{Packages: []string{"git"}}), nil)
+			var rpcErr *sdk.RPCError
+			Expect(errors.As(err, &rpcErr)).To(BeTrue())
+			Expect(rpcErr.Code).To(Equal(sdk.ErrCodeManagerUnavailable))
+		})
+	})
+})
@@ -0,0 +1,162 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// SandboxLauncherArg is the first argument of a sandbox launcher invocation. The launcher is
+// normally the DevEx CLI itself: it applies the restrictions to its own process and then
+// replaces itself with the plugin, so the plugin never runs unconfined.
+const SandboxLauncherArg = "__devex-plugin-sandbox"
+
+// sudoHelperArg follows SandboxLauncherArg when the launcher starts the sudo helper of a plugin
+const sudoHelperArg = "--sudo-helper"
+
+// sudoHelperEnvVar tells a confined plugin the descriptor of its connection to the sudo helper
+const sudoHelperEnvVar = "DEVEX_SUDO_HELPER_FD"
+
+// defaultPath replaces a PATH that fails validation
+const defaultPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+// sandboxEnvironment lists the variables every confined plugin receives
+var sandboxEnvironment = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TERM", "COLORTERM", "LANG", "LANGUAGE", "TZ", "TMPDIR",
+	"DISPLAY", "WAYLAND_DISPLAY", "DBUS_SESSION_BUS_ADDRESS", "XDG_RUNTIME_DIR", "XDG_CONFIG_HOME",
+	"XDG_DATA_HOME", "XDG_CACHE_HOME", "XDG_CURRENT_DESKTOP", "XDG_SESSION_DESKTOP", "XDG_SESSION_TYPE",
+	"DESKTOP_SESSION", "GDMSESSION", "NO_COLOR",
+}
+
+// sandboxEnvironmentPrefixes lists prefixes of variables every confined plugin receives
+var sandboxEnvironmentPrefixes = []string{"LC_", "DEVEX_"}
+
+// networkEnvironment lists the variables passed to plugins allowed to use the network
+var networkEnvironment = []string{
+	"http_proxy", "https_proxy", "no_proxy", "HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "ALL_PROXY",
+	"SSL_CERT_FILE", "SSL_CERT_DIR",
+}
+
+// SudoAuditor records a command the sudo helper runs as root for a plugin. It is called before
+// the command starts, and the function it returns with the command's result.
+type SudoAuditor func(plugin, command string, args []string) func(error)
+
+// sudoAuditor records the commands of the sudo helper, see SetSudoAuditor
+var sudoAuditor SudoAuditor
+
+// SetSudoAuditor sets how the sudo helper records the commands plugins run as root. The
+// launcher must set it before RunSandboxLauncher: the helper refuses to run commands it
+// cannot record.
+func SetSudoAuditor(auditor SudoAuditor) {
+	sudoAuditor = auditor
+}
+
+// Sandbox starts plugins confined to the permissions granted to them
+type Sandbox struct {
+	// Launcher is the executable that confines the plugin before it starts. It must call
+	// RunSandboxLauncher when started with SandboxLauncherArg.
+	Launcher string
+	Logger   Logger
+}
+
+// Command returns the command that runs a plugin with the given permissions. A nil manifest runs
+// the plugin with full access and only removes environment variables that fail validation.
+func (s *Sandbox) Command(ctx context.Context, pluginPath string, permissions *PluginPermissions, args ...string) *exec.Cmd {
+	env := SandboxEnvironment(os.Environ(), permissions)
+
+	var cmd *exec.Cmd
+	if permissions == nil || !sandboxSupported {
+		cmd = exec.CommandContext(ctx, pluginPath, args...)
+	} else {
+		cmd = exec.CommandContext(ctx, s.Launcher, append([]string{SandboxLauncherArg, pluginPath}, args...)...)
+	}
+	cmd.Env = env
+
+	if s.Logger != nil {
+		s.Logger.Debug("Starting sandboxed plugin", "plugin", pluginPath, "permissions", permissions.Describe(),
+			"env", SanitizeEnvironmentForLogging(env))
+	}
+	return cmd
+}
+
+// SandboxEnvironment returns the environment of a plugin. Variables that fail
+// ValidateEnvironmentVariable are always removed. With a manifest, only basic variables, proxy
+// settings for plugins using the network and the variables the manifest lists are kept, and
+// the permissions are passed in PermissionsEnvVar.
+func SandboxEnvironment(env []string, permissions *PluginPermissions) []string {
+	scrubbed := make([]string, 0, len(env)+1)
+	hasPath := false
+	for _, entry := range env {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || name == PermissionsEnvVar {
+			continue
+		}
+		if permissions != nil && !permissions.allowsEnvironment(name) {
+			continue
+		}
+		if err := ValidateEnvironmentVariable(name, value); err != nil {
+			continue
+		}
+		hasPath = hasPath || name == "PATH"
+		scrubbed = append(scrubbed, entry)
+	}
+	if !hasPath {
+		scrubbed = append(scrubbed, "PATH="+defaultPath)
+	}
+
+	if permissions != nil {
+		encoded, err := json.Marshal(permissions)
+		if err == nil {
+			scrubbed = append(scrubbed, PermissionsEnvVar+"="+string(encoded))
+		}
+	}
+	return scrubbed
+}
+
+// allowsEnvironment reports whether a confined plugin receives an environment variable
+func (p *PluginPermissions) allowsEnvironment(name string) bool {
+	if slices.Contains(sandboxEnvironment, name) || slices.Contains(p.Env, name) {
+		return true
+	}
+	if p.Network && slices.Contains(networkEnvironment, name) {
+		return true
+	}
+	return slices.ContainsFunc(sandboxEnvironmentPrefixes, func(prefix string) bool {
+		return strings.HasPrefix(name, prefix)
+	})
+}
+
+// RunSandboxLauncher confines the current process to the permissions in PermissionsEnvVar and
+// replaces it with the plugin. args are the arguments following SandboxLauncherArg: the plugin
+// path and its arguments. Plugins granted sudo are confined too; the launcher first starts a
+// sudo helper that runs the commands they need as root, see SetSudoAuditor. It only returns
+// by exiting the process.
+func RunSandboxLauncher(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "plugin sandbox: missing plugin path")
+		os.Exit(1)
+	}
+
+	permissions := pluginPermissionsFromEnv()
+	if permissions == nil {
+		fmt.Fprintln(os.Stderr, "plugin sandbox: no permissions given")
+		os.Exit(1)
+	}
+
+	if args[0] == sudoHelperArg {
+		if err := runSudoHelper(args[1:], permissions); err != nil {
+			fmt.Fprintf(os.Stderr, "plugin sudo helper: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if err := execConfined(args[0], args, permissions); err != nil {
+		fmt.Fprintf(os.Stderr, "plugin sandbox: %v\n", err)
+		os.Exit(1)
+	}
+}
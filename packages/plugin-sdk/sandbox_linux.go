@@ -0,0 +1,241 @@
+//go:build linux
+
+package sdk
+
+import (
+	"bufio"
+	"debug/elf"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// sandboxSupported reports whether plugins are started through the sandbox launcher
+const sandboxSupported = true
+
+// landlockWriteAccess are the Landlock rights that modify the file system, by ABI version
+var landlockWriteAccess = []uint64{
+	1: unix.LANDLOCK_ACCESS_FS_WRITE_FILE | unix.LANDLOCK_ACCESS_FS_REMOVE_DIR | unix.LANDLOCK_ACCESS_FS_REMOVE_FILE |
+		unix.LANDLOCK_ACCESS_FS_MAKE_CHAR | unix.LANDLOCK_ACCESS_FS_MAKE_DIR | unix.LANDLOCK_ACCESS_FS_MAKE_REG |
+		unix.LANDLOCK_ACCESS_FS_MAKE_SOCK | unix.LANDLOCK_ACCESS_FS_MAKE_FIFO | unix.LANDLOCK_ACCESS_FS_MAKE_BLOCK |
+		unix.LANDLOCK_ACCESS_FS_MAKE_SYM,
+	2: unix.LANDLOCK_ACCESS_FS_REFER,
+	3: unix.LANDLOCK_ACCESS_FS_TRUNCATE,
+}
+
+// landlockFileAccess are the Landlock rights that apply to files rather than directories
+const landlockFileAccess = unix.LANDLOCK_ACCESS_FS_EXECUTE | unix.LANDLOCK_ACCESS_FS_WRITE_FILE |
+	unix.LANDLOCK_ACCESS_FS_READ_FILE | unix.LANDLOCK_ACCESS_FS_TRUNCATE
+
+// sandboxWritablePaths may be written by every confined plugin
+var sandboxWritablePaths = []string{"/dev/null", "/dev/tty", "/dev/pts", "/dev/shm"}
+
+// sudoHelperNote notes how plugins granted sudo run commands as root, see startSudoHelper
+const sudoHelperNote = "; plugins granted sudo run commands as root through an audited helper"
+
+// SandboxEnforcement describes which permissions the kernel enforces for confined plugins
+func SandboxEnforcement() string {
+	abi := landlockABI()
+	switch {
+	case abi >= 4:
+		return fmt.Sprintf("Landlock ABI %d confines writes, commands and network access; no-new-privs blocks sudo%s", abi, sudoHelperNote)
+	case abi >= 1:
+		return fmt.Sprintf("Landlock ABI %d confines writes and commands; no-new-privs blocks sudo; network access is not enforced%s", abi, sudoHelperNote)
+	default:
+		return "Landlock is not available, only the environment is scrubbed and no-new-privs blocks sudo" + sudoHelperNote
+	}
+}
+
+// landlockABI returns the Landlock ABI version of the kernel, or 0 when Landlock is unavailable
+func landlockABI() int {
+	abi, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, 0, 0, unix.LANDLOCK_CREATE_RULESET_VERSION)
+	if errno != 0 {
+		return 0
+	}
+	return int(abi)
+}
+
+// execConfined restricts the current thread and replaces the process with the plugin.
+// Restrictions apply to the calling thread only, which is why the thread is locked and the
+// process image replaced from it: the plugin inherits the restricted thread's credentials.
+func execConfined(pluginPath string, args []string, permissions *PluginPermissions) error {
+	// No-new-privs stops sudo inside the plugin, so plugins granted sudo reach root through a
+	// helper started before the restrictions apply
+	_ = os.Unsetenv(sudoHelperEnvVar)
+	if permissions.Sudo {
+		if err := startSudoHelper(pluginPath); err != nil {
+			return err
+		}
+	}
+
+	runtime.LockOSThread()
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("failed to set no-new-privs: %w", err)
+	}
+	if abi := landlockABI(); abi >= 1 {
+		if err := restrictWithLandlock(abi, pluginPath, permissions); err != nil {
+			return err
+		}
+	}
+
+	return syscall.Exec(pluginPath, args, os.Environ())
+}
+
+// restrictWithLandlock limits writes to the permitted paths, execution to the permitted
+// commands and, when the kernel supports it, forbids TCP for plugins without network access
+func restrictWithLandlock(abi int, pluginPath string, permissions *PluginPermissions) error {
+	var writeAccess uint64
+	for version := 1; version < len(landlockWriteAccess) && version <= abi; version++ {
+		writeAccess |= landlockWriteAccess[version]
+	}
+
+	attr := unix.LandlockRulesetAttr{Access_fs: writeAccess | unix.LANDLOCK_ACCESS_FS_EXECUTE}
+	if abi >= 4 && !permissions.Network {
+		attr.Access_net = unix.LANDLOCK_ACCESS_NET_BIND_TCP | unix.LANDLOCK_ACCESS_NET_CONNECT_TCP
+	}
+	fd, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr), 0)
+	if errno != 0 {
+		return fmt.Errorf("failed to create Landlock ruleset: %w", errno)
+	}
+	ruleset := int(fd)
+	defer func() { _ = unix.Close(ruleset) }()
+
+	writable := append([]string{os.TempDir()}, sandboxWritablePaths...)
+	for _, path := range permissions.Write {
+		writable = append(writable, expandHome(path))
+	}
+	for _, path := range writable {
+		if err := allowPath(ruleset, existingPathFor(path), writeAccess); err != nil {
+			return err
+		}
+	}
+
+	executables := []string{pluginPath}
+	for _, command := range permissions.Exec {
+		resolved, err := resolveCommand(command)
+		if err != nil {
+			// A command that is not installed cannot be run anyway
+			continue
+		}
+		executables = append(executables, resolved)
+	}
+	for _, path := range executables {
+		paths := append([]string{path}, interpreters(path)...)
+		for _, executable := range paths {
+			if err := allowPath(ruleset, executable, unix.LANDLOCK_ACCESS_FS_EXECUTE); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, _, errno := unix.Syscall(unix.SYS_LANDLOCK_RESTRICT_SELF, uintptr(ruleset), 0, 0); errno != 0 {
+		return fmt.Errorf("failed to apply Landlock ruleset: %w", errno)
+	}
+	return nil
+}
+
+// allowPath grants access beneath a path. Missing paths are skipped; files only receive the
+// rights that apply to files.
+func allowPath(ruleset int, path string, access uint64) error {
+	fd, err := unix.Open(path, unix.O_PATH|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = unix.Close(fd) }()
+
+	var stat unix.Stat_t
+	if err := unix.Fstat(fd, &stat); err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	if stat.Mode&unix.S_IFMT != unix.S_IFDIR {
+		access &= landlockFileAccess
+	}
+
+	rule := unix.LandlockPathBeneathAttr{Allowed_access: access, Parent_fd: int32(fd)}
+	if _, _, errno := unix.Syscall6(unix.SYS_LANDLOCK_ADD_RULE, uintptr(ruleset), unix.LANDLOCK_RULE_PATH_BENEATH,
+		uintptr(unsafe.Pointer(&rule)), 0, 0, 0); errno != 0 {
+		return fmt.Errorf("failed to allow access to %s: %w", path, errno)
+	}
+	return nil
+}
+
+// existingPathFor returns path, or its parent directory when path does not exist yet so the
+// plugin can create it
+func existingPathFor(path string) string {
+	if _, err := os.Lstat(path); errors.Is(err, os.ErrNotExist) {
+		return filepath.Dir(path)
+	}
+	return path
+}
+
+// resolveCommand finds the program a manifest exec entry refers to
+func resolveCommand(command string) (string, error) {
+	if command = expandHome(command); filepath.IsAbs(command) {
+		return command, nil
+	}
+	return exec.LookPath(command)
+}
+
+// interpreters returns the programs the kernel loads to run an executable: the dynamic loader
+// of an ELF binary or the interpreter named in a script's #! line
+func interpreters(path string) []string {
+	if loader, isELF := elfInterpreter(path); isELF {
+		return loader
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = file.Close() }()
+	line, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "#!") {
+		return nil
+	}
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 {
+		return nil
+	}
+
+	interpreter := fields[0]
+	loader, _ := elfInterpreter(interpreter)
+	result := append([]string{interpreter}, loader...)
+	// #!/usr/bin/env runs the program it names from PATH
+	if filepath.Base(interpreter) == "env" && len(fields) > 1 {
+		if program, err := exec.LookPath(fields[len(fields)-1]); err == nil {
+			loader, _ := elfInterpreter(program)
+			result = append(append(result, program), loader...)
+		}
+	}
+	return result
+}
+
+// elfInterpreter returns the dynamic loader of an ELF binary and whether path is an ELF binary
+func elfInterpreter(path string) ([]string, bool) {
+	file, err := elf.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer func() { _ = file.Close() }()
+
+	for _, prog := range file.Progs {
+		if prog.Type != elf.PT_INTERP {
+			continue
+		}
+		interp, err := io.ReadAll(prog.Open())
+		if err != nil {
+			return nil, true
+		}
+		return []string{strings.TrimRight(string(interp), "\x00")}, true
+	}
+	return nil, true
+}
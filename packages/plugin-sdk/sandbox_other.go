@@ -0,0 +1,31 @@
+//go:build !linux
+
+package sdk
+
+import (
+	"context"
+	"errors"
+)
+
+// sandboxSupported reports whether plugins are started through the sandbox launcher
+const sandboxSupported = false
+
+// SandboxEnforcement describes which permissions the kernel enforces for confined plugins
+func SandboxEnforcement() string {
+	return "only the environment is scrubbed, the operating system cannot confine plugins"
+}
+
+// execConfined is never reached: plugins are not started through the launcher on this platform
+func execConfined(string, []string, *PluginPermissions) error {
+	return errors.New("plugin sandboxing is only supported on Linux")
+}
+
+// runSudoHelper is never reached: plugins are not started through the launcher on this platform
+func runSudoHelper([]string, *PluginPermissions) error {
+	return errors.New("plugin sandboxing is only supported on Linux")
+}
+
+// runWithSudoHelper reports that the plugin has no sudo helper, plugins run sudo themselves
+func runWithSudoHelper(context.Context, string, []string) (bool, error) {
+	return false, nil
+}
@@ -258,6 +258,10 @@ type PluginInfo struct {
 	Tags        []string        `json:"tags,omitempty"`
 	// Timeout configuration for plugin operations
 	Timeouts TimeoutConfig `json:"timeouts,omitempty"`
+	// ProtocolVersion of the structured protocol the plugin speaks (0 for legacy plugins)
+	ProtocolVersion int `json:"protocol_version,omitempty"`
+	// Permissions declared in the plugin's metadata.yaml (nil for plugins without a manifest)
+	Permissions *PluginPermissions `json:"permissions,omitempty"`
 }
 
 // PluginCommand represents a command provided by a plugin
@@ -338,7 +342,11 @@ func (p *BasePlugin) GetTimeout(operationType string) time.Duration {
 
 // OutputPluginInfo outputs plugin info as JSON (for --plugin-info)
 func (p *BasePlugin) OutputPluginInfo() {
-	output, err := json.MarshalIndent(p.info, "", "  ")
+	info := p.info
+	if info.ProtocolVersion == 0 {
+		info.ProtocolVersion = ProtocolVersion
+	}
+	output, err := json.MarshalIndent(info, "", "  ")
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to marshal plugin info: %v\n", err)
 		os.Exit(1)
@@ -359,8 +367,17 @@ func RequireSudo() bool {
 
 // ExecCommandWithContext executes a command with context support for cancellation
 func ExecCommandWithContext(ctx context.Context, useSudo bool, name string, args ...string) error {
-	var cmd *exec.Cmd
+	if err := CheckCommandPermission(useSudo && RequireSudo(), name); err != nil {
+		return err
+	}
+	// Confined plugins reach root through the sudo helper of the sandbox
+	if useSudo {
+		if ran, err := runWithSudoHelper(ctx, name, args); ran {
+			return err
+		}
+	}
 
+	var cmd *exec.Cmd
 	if useSudo && RequireSudo() {
 		cmdArgs := append([]string{name}, args...)
 		cmd = exec.CommandContext(ctx, "sudo", cmdArgs...)
@@ -377,6 +394,9 @@ func ExecCommandWithContext(ctx context.Context, useSudo bool, name string, args
 
 // ExecCommandOutputWithContext executes a command and returns output with context support
 func ExecCommandOutputWithContext(ctx context.Context, name string, args ...string) (string, error) {
+	if err := CheckCommandPermission(false, name); err != nil {
+		return "", err
+	}
 	cmd := exec.CommandContext(ctx, name, args...)
 	output, err := cmd.Output()
 	return string(output), err
@@ -484,11 +504,13 @@ func (p *PackageManagerPlugin) IsAvailable() bool {
 	return CommandExists(p.managerCommand)
 }
 
-// EnsureAvailable ensures the package manager is available or exits with an error
+// EnsureAvailable ensures the package manager is available or exits with an error.
+// The exit code is ExitCodeCheckFailed so an unavailable manager is never mistaken for
+// a package that is not installed.
 func (p *PackageManagerPlugin) EnsureAvailable() {
 	if !p.IsAvailable() {
 		fmt.Fprintf(os.Stderr, "Error: %s is not available on this system\n", p.managerCommand)
-		os.Exit(1)
+		os.Exit(ExitCodeCheckFailed)
 	}
 }
 
@@ -504,6 +526,94 @@ func (p *PackageManagerPlugin) ExecManagerCommandOutput(operation string, args .
 	return ExecCommandOutputWithTimeoutAndOperation(timeout, operation, p.managerCommand, args...)
 }
 
+// Exit codes of the is-installed command. Every package manager plugin follows this contract
+// so the CLI can tell a missing package apart from a check that could not be performed.
+const (
+	ExitCodeInstalled    = 0 // every requested package is installed
+	ExitCodeNotInstalled = 1 // at least one requested package is not installed
+	ExitCodeCheckFailed  = 2 // the check failed (invalid input, package manager unavailable, ...)
+)
+
+// ErrNotInstalled is matched by errors reporting that packages are not installed
+var ErrNotInstalled = errors.New("not installed")
+
+// NotInstalledError lists the packages an is-installed check found missing
+type NotInstalledError struct {
+	Packages []string
+}
+
+func (e *NotInstalledError) Error() string {
+	return fmt.Sprintf("one or more packages are not installed: %s", strings.Join(e.Packages, ", "))
+}
+
+// Is makes NotInstalledError match ErrNotInstalled
+func (e *NotInstalledError) Is(target error) bool {
+	return target == ErrNotInstalled
+}
+
+// IsInstalledExitCode maps the result of an is-installed command onto the exit code contract
+func IsInstalledExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitCodeInstalled
+	case errors.Is(err, ErrNotInstalled):
+		return ExitCodeNotInstalled
+	default:
+		return ExitCodeCheckFailed
+	}
+}
+
+// CheckInstalled implements the is-installed command on top of a per-package check.
+// It returns a NotInstalledError listing the missing packages, or another error if a check failed.
+func (p *PackageManagerPlugin) CheckInstalled(ctx context.Context, packages []string, check func(ctx context.Context, pkg string) (bool, error)) error {
+	if len(packages) == 0 {
+		return fmt.Errorf("no packages specified")
+	}
+
+	var missing []string
+	for _, pkg := range packages {
+		if pkg == "" || strings.HasPrefix(pkg, "-") {
+			return fmt.Errorf("invalid package name '%s'", pkg)
+		}
+
+		installed, err := check(ctx, pkg)
+		if err != nil {
+			return fmt.Errorf("failed to check installation status of %s: %w", pkg, err)
+		}
+
+		if installed {
+			fmt.Printf("Package %s is installed\n", pkg)
+		} else {
+			fmt.Printf("Package %s is not installed\n", pkg)
+			missing = append(missing, pkg)
+		}
+	}
+
+	if len(missing) > 0 {
+		return &NotInstalledError{Packages: missing}
+	}
+	return nil
+}
+
+// CheckCommandWithContext runs a query command and reports whether it exited successfully.
+// A non-zero exit is reported as false; failing to run the command at all is returned as an error.
+func CheckCommandWithContext(ctx context.Context, name string, args ...string) (bool, error) {
+	if err := CheckCommandPermission(false, name); err != nil {
+		return false, err
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
+	err := cmd.Run()
+	if err == nil {
+		return true, nil
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && ctx.Err() == nil {
+		return false, nil
+	}
+	return false, err
+}
+
 // HandleArgs provides standard argument handling for plugins
 func HandleArgs(plugin Plugin, args []string) {
 	if len(args) < 1 {
@@ -517,20 +627,52 @@ func HandleArgs(plugin Plugin, args []string) {
 	case "--plugin-info":
 		// Get plugin info directly from the interface
 		info := plugin.Info()
+		if info.ProtocolVersion == 0 {
+			// Every plugin using HandleArgs can be served over the protocol
+			info.ProtocolVersion = ProtocolVersion
+		}
 		output, err := json.MarshalIndent(info, "", "  ")
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to marshal plugin info: %v\n", err)
 			os.Exit(1)
 		}
 		fmt.Print(string(output))
+	case RPCFlag:
+		if err := serveRPCFromArgs(plugin); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	case RPCRequestFlag:
+		if len(args) < 2 {
+			fmt.Fprintf(os.Stderr, "Usage: %s %s <request>\n", os.Args[0], RPCRequestFlag)
+			os.Exit(1)
+		}
+		if err := serveRPCRequestFromArgs(plugin, args[1]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	case MethodIsInstalled:
+		if err := plugin.Execute(command, args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(IsInstalledExitCode(err))
+		}
 	default:
 		if err := plugin.Execute(command, args[1:]); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			os.Exit(exitCodeFor(err))
 		}
 	}
 }
 
+// exitCodeFor returns the exit code of a plugin command that failed
+func exitCodeFor(err error) int {
+	var violation *PermissionViolationError
+	if errors.As(err, &violation) {
+		return PermissionViolationExitCode
+	}
+	return 1
+}
+
 // FileExists checks if a file exists
 func FileExists(path string) bool {
 	_, err := os.Stat(path)
@@ -539,6 +681,9 @@ func FileExists(path string) bool {
 
 // RunCommand runs a command and returns its output
 func RunCommand(name string, args ...string) (string, error) {
+	if err := CheckCommandPermission(false, name); err != nil {
+		return "", err
+	}
 	cmd := exec.Command(name, args...)
 	output, err := cmd.CombinedOutput()
 	return string(output), err
@@ -574,29 +719,32 @@ type PlatformBinary struct {
 	Size     int64  `json:"size"`
 	OS       string `json:"os"`
 	Arch     string `json:"arch"`
+
+	// Signature is the detached minisign signature of the binary
+	Signature string `json:"signature,omitempty"`
 }
 
 // Downloader handles secure plugin downloading from registry with verification
 type Downloader struct {
-	registryURL      string
-	pluginDir        string
-	cacheDir         string
-	verifyChecksums  bool
-	verifySignatures bool
-	publicKeyPath    string
-	logger           Logger
-	strategy         DownloadStrategy
+	registryURL     string
+	pluginDir       string
+	cacheDir        string
+	verifyChecksums bool
+	keyring         *Keyring
+	allowUnverified map[string]bool
+	logger          Logger
+	strategy        DownloadStrategy
 }
 
 // DownloaderConfig configures the plugin downloader
 type DownloaderConfig struct {
-	RegistryURL      string
-	PluginDir        string
-	CacheDir         string
-	VerifyChecksums  bool
-	VerifySignatures bool
-	PublicKeyPath    string
-	Strategy         DownloadStrategy
+	RegistryURL     string
+	PluginDir       string
+	CacheDir        string
+	VerifyChecksums bool
+	Keyring         *Keyring // keys trusted to sign plugins, plugins are always verified against it
+	AllowUnverified []string // plugins that may be installed without a trusted signature
+	Strategy        DownloadStrategy
 }
 
 // NewDownloader creates a new plugin downloader with default security settings
@@ -605,29 +753,35 @@ func NewDownloader(registryURL, pluginDir string) *Downloader {
 	cacheDir := filepath.Join(homeDir, ".devex", "plugin-cache")
 
 	return &Downloader{
-		registryURL:      registryURL,
-		pluginDir:        pluginDir,
-		cacheDir:         cacheDir,
-		verifyChecksums:  true,  // Enable checksum verification by default
-		verifySignatures: false, // Signature verification optional for now
-		publicKeyPath:    "",
-		logger:           NewDefaultLogger(false), // Default logger
-		strategy:         ContinueOnError,         // Default to continue on error
+		registryURL:     registryURL,
+		pluginDir:       pluginDir,
+		cacheDir:        cacheDir,
+		verifyChecksums: true,         // Enable checksum verification by default
+		keyring:         NewKeyring(), // Plugins must be signed by a key in the keyring
+		allowUnverified: make(map[string]bool),
+		logger:          NewDefaultLogger(false), // Default logger
+		strategy:        ContinueOnError,         // Default to continue on error
 	}
 }
 
 // NewSecureDownloader creates a downloader with custom security configuration
 func NewSecureDownloader(config DownloaderConfig) *Downloader {
-	return &Downloader{
-		registryURL:      config.RegistryURL,
-		pluginDir:        config.PluginDir,
-		cacheDir:         config.CacheDir,
-		verifyChecksums:  config.VerifyChecksums,
-		verifySignatures: config.VerifySignatures,
-		publicKeyPath:    config.PublicKeyPath,
-		logger:           NewDefaultLogger(false), // Default logger
-		strategy:         config.Strategy,
+	keyring := config.Keyring
+	if keyring == nil {
+		keyring = NewKeyring()
+	}
+	d := &Downloader{
+		registryURL:     config.RegistryURL,
+		pluginDir:       config.PluginDir,
+		cacheDir:        config.CacheDir,
+		verifyChecksums: config.VerifyChecksums,
+		keyring:         keyring,
+		allowUnverified: make(map[string]bool),
+		logger:          NewDefaultLogger(false), // Default logger
+		strategy:        config.Strategy,
 	}
+	d.AllowUnverified(config.AllowUnverified...)
+	return d
 }
 
 // SetLogger allows setting a custom logger for the downloader
@@ -649,6 +803,21 @@ func (d *Downloader) SetStrategy(strategy DownloadStrategy) {
 	d.strategy = strategy
 }
 
+// SetKeyring sets the keys trusted to sign plugins
+func (d *Downloader) SetKeyring(keyring *Keyring) {
+	if keyring != nil {
+		d.keyring = keyring
+	}
+}
+
+// AllowUnverified lets plugins be installed when they are unsigned or signed by a key that is
+// not trusted. Plugins whose signature does not match the binary are always refused.
+func (d *Downloader) AllowUnverified(pluginNames ...string) {
+	for _, name := range pluginNames {
+		d.allowUnverified[name] = true
+	}
+}
+
 // GetAvailablePlugins returns available plugins from registry with caching
 func (d *Downloader) GetAvailablePlugins(ctx context.Context) (map[string]PluginMetadata, error) {
 	registry, err := d.fetchRegistry()
@@ -930,6 +1099,12 @@ func (d *Downloader) downloadAndVerifyPlugin(ctx context.Context, pluginName str
 
 	// Check if the plugin already exists and is up to date
 	if d.isPluginUpToDate(pluginPath, binary.Checksum) {
+		if err := d.verifyPluginSignature(pluginName, pluginPath, binary); err != nil {
+			return fmt.Errorf("signature verification failed: %w", err)
+		}
+		if err := SavePluginSignature(d.pluginDir, pluginName, []byte(binary.Signature)); err != nil && d.logger != nil {
+			d.logger.Warning("Failed to save signature of plugin %s: %v", pluginName, err)
+		}
 		if d.logger != nil {
 			d.logger.Printf("Plugin %s is already up to date\n", pluginName)
 		}
@@ -1013,11 +1188,9 @@ func (d *Downloader) downloadAndVerifyPlugin(ctx context.Context, pluginName str
 		}
 	}
 
-	// Verify signature if enabled
-	if d.verifySignatures {
-		if err := d.verifyPluginSignature(tempPath, binary.URL); err != nil {
-			return fmt.Errorf("signature verification failed: %w", err)
-		}
+	// Verify the signature, unsigned plugins are refused unless allowed
+	if err := d.verifyPluginSignature(pluginName, tempPath, binary); err != nil {
+		return fmt.Errorf("signature verification failed: %w", err)
 	}
 
 	// Make executable and move to final location
@@ -1029,6 +1202,11 @@ func (d *Downloader) downloadAndVerifyPlugin(ctx context.Context, pluginName str
 		return fmt.Errorf("failed to move plugin to final location: %w", err)
 	}
 
+	// Keep the signature so verification can be repeated for the installed plugin
+	if err := SavePluginSignature(d.pluginDir, pluginName, []byte(binary.Signature)); err != nil && d.logger != nil {
+		d.logger.Warning("Failed to save signature of plugin %s: %v", pluginName, err)
+	}
+
 	if d.logger != nil {
 		d.logger.Success("Successfully installed plugin %s", pluginName)
 	}
@@ -1082,6 +1260,7 @@ type ExecutableManager struct {
 	cachedPlugins map[string]PluginMetadata
 	cacheTime     time.Time
 	loadTimeout   time.Duration
+	sandbox       *Sandbox
 	mu            sync.RWMutex
 }
 
@@ -1099,6 +1278,12 @@ func (em *ExecutableManager) GetPluginDir() string {
 	return em.pluginDir
 }
 
+// SetSandbox confines the plugins started by the manager to the permissions granted to them.
+// Without a sandbox plugins run with full access.
+func (em *ExecutableManager) SetSandbox(sandbox *Sandbox) {
+	em.sandbox = sandbox
+}
+
 // ListPlugins returns installed plugins with caching
 func (em *ExecutableManager) ListPlugins() map[string]PluginMetadata {
 	// Check cached plugins with read lock
@@ -1243,6 +1428,10 @@ func (em *ExecutableManager) getPluginMetadataWithContext(ctx context.Context, p
 
 	// Execute plugin with --plugin-info flag
 	cmd := exec.CommandContext(ctx, pluginPath, "--plugin-info")
+	if em.sandbox != nil {
+		// Reporting metadata needs no permissions, so the plugin runs confined to none
+		cmd = em.sandbox.Command(ctx, pluginPath, &PluginPermissions{}, "--plugin-info")
+	}
 	output, err := cmd.Output()
 	if err != nil {
 		// Return basic metadata if plugin doesn't respond
@@ -1277,28 +1466,63 @@ func (em *ExecutableManager) getPluginMetadataWithContext(ctx context.Context, p
 
 // ExecutePlugin executes a plugin with given arguments and timeout
 func (em *ExecutableManager) ExecutePlugin(pluginName string, args []string) error {
-	plugins := em.ListPlugins()
-	pluginInfo, exists := plugins[pluginName]
-	if !exists {
-		return fmt.Errorf("plugin %s is not installed", pluginName)
-	}
-
 	// Create context with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), em.loadTimeout)
 	defer cancel()
 
 	// Execute plugin
-	cmd := exec.CommandContext(ctx, pluginInfo.Path, args...)
+	cmd, err := em.PluginCommand(ctx, pluginName, args...)
+	if err != nil {
+		return err
+	}
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
 
-	return cmd.Run()
+	return PluginExitError(pluginName, cmd.Run())
+}
+
+// PluginCommand returns the command that runs an installed plugin. With a sandbox, the
+// permissions the plugin requests must have been granted and the plugin runs confined to them;
+// a PermissionsNotGrantedError is returned otherwise.
+func (em *ExecutableManager) PluginCommand(ctx context.Context, pluginName string, args ...string) (*exec.Cmd, error) {
+	plugins := em.ListPlugins()
+	pluginInfo, exists := plugins[pluginName]
+	if !exists {
+		return nil, fmt.Errorf("plugin %s is not installed", pluginName)
+	}
+	if em.sandbox == nil {
+		return exec.CommandContext(ctx, pluginInfo.Path, args...), nil
+	}
+
+	grant, err := LoadPermissionGrant(em.pluginDir, pluginName)
+	if err != nil {
+		return nil, err
+	}
+	if covered, missing := grant.Covers(pluginInfo.Permissions); !covered {
+		return nil, &PermissionsNotGrantedError{Plugin: pluginName, Requested: pluginInfo.Permissions, Missing: missing}
+	}
+
+	// Plugins run with the permissions they declare, which the grant covers
+	return em.sandbox.Command(ctx, pluginInfo.Path, pluginInfo.Permissions, args...), nil
+}
+
+// PluginExitError reports a plugin that exited after violating its permission manifest as a
+// PermissionViolationError and returns other errors unchanged
+func PluginExitError(pluginName string, err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == PermissionViolationExitCode {
+		return &PermissionViolationError{Plugin: pluginName}
+	}
+	return err
 }
 
 // DiscoverPlugins discovers and caches plugins in the plugin directory
 func (em *ExecutableManager) DiscoverPlugins() error {
-	// Just refresh the cache by calling ListPlugins
+	// Expire the cache so plugins installed or updated since the last scan are picked up
+	em.mu.Lock()
+	em.cacheTime = time.Time{}
+	em.mu.Unlock()
 	em.ListPlugins()
 	return nil
 }
@@ -1319,7 +1543,8 @@ func (em *ExecutableManager) RemovePlugin(pluginName string) error {
 	em.mu.Lock()
 	em.cachedPlugins = make(map[string]PluginMetadata)
 	em.mu.Unlock()
-	return nil
+
+	return SavePermissionGrant(em.pluginDir, pluginName, nil)
 }
 
 // InstallPlugin installs a plugin from a source path
@@ -1,12 +1,58 @@
 package sdk_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/packages/plugin-sdk"
 )
 
+// sudoCommandArg makes the test binary run a command with sudo through the SDK, as a sandboxed
+// plugin would
+const sudoCommandArg = "__sdk-test-sudo-command"
+
+// sudoAuditEnvVar names the file the sudo helper of the test binary records commands in
+const sudoAuditEnvVar = "DEVEX_TEST_SUDO_AUDIT"
+
+// TestMain lets the test binary act as the plugin sandbox launcher and as a plugin using sudo
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && os.Args[1] == sdk.SandboxLauncherArg {
+		sdk.SetSudoAuditor(recordSudoCommand)
+		sdk.RunSandboxLauncher(os.Args[2:])
+	}
+	if len(os.Args) > 2 && os.Args[1] == sudoCommandArg {
+		err := sdk.ExecCommandWithContext(context.Background(), true, os.Args[2], os.Args[3:]...)
+		var violation *sdk.PermissionViolationError
+		switch {
+		case errors.As(err, &violation):
+			os.Exit(sdk.PermissionViolationExitCode)
+		case err != nil:
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// recordSudoCommand appends the commands the sudo helper runs to the file in sudoAuditEnvVar
+func recordSudoCommand(plugin, command string, args []string) func(error) {
+	return func(err error) {
+		file, openErr := os.OpenFile(os.Getenv(sudoAuditEnvVar), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if openErr != nil {
+			return
+		}
+		defer func() { _ = file.Close() }()
+		_, _ = fmt.Fprintf(file, "%s: %s %s (%v)\n", plugin, command, strings.Join(args, " "), err)
+	}
+}
+
 func TestSDK(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Plugin SDK Suite")
@@ -0,0 +1,505 @@
+package sdk
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// Plugin binaries are signed with detached Ed25519 signatures in the minisign format, so
+// releases can be signed with `minisign -S -m <binary>` and verified without a keyserver.
+// The signature is carried in the registry entry of each binary and stored next to the
+// installed plugin, so verification can be repeated at any time.
+
+const (
+	// SignatureExtension is the file extension of detached plugin signatures
+	SignatureExtension = ".minisig"
+
+	// signaturesDir holds the signatures of installed plugins, relative to the plugin directory.
+	// Signatures are kept out of the plugin directory itself, where every devex-plugin-* file
+	// is treated as a plugin.
+	signaturesDir = "signatures"
+
+	// maxSignatureSize bounds the size of a signature file
+	maxSignatureSize = 4096
+
+	untrustedPrefix = "untrusted comment: "
+	trustedPrefix   = "trusted comment: "
+)
+
+var (
+	algorithmPure    = [2]byte{'E', 'd'} // signature over the message itself
+	algorithmPrehash = [2]byte{'E', 'D'} // signature over the BLAKE2b-512 hash of the message
+
+	errNoTrustedKeys  = errors.New("no trusted signing keys configured")
+	errMalformedKeyID = errors.New("key ID must be 16 hexadecimal characters")
+)
+
+// KeyID identifies a signing key. It is the random key number of a minisign key pair.
+type KeyID [8]byte
+
+// String returns the key ID in the hexadecimal form printed by minisign
+func (id KeyID) String() string {
+	// minisign stores the key number little-endian and prints it as a 64-bit integer
+	reversed := make([]byte, len(id))
+	for i, b := range id {
+		reversed[len(id)-1-i] = b
+	}
+	return strings.ToUpper(hex.EncodeToString(reversed))
+}
+
+// ParseKeyID parses a key ID printed by minisign
+func ParseKeyID(text string) (KeyID, error) {
+	var id KeyID
+	raw, err := hex.DecodeString(strings.TrimSpace(text))
+	if err != nil || len(raw) != len(id) {
+		return id, errMalformedKeyID
+	}
+	for i, b := range raw {
+		id[len(id)-1-i] = b
+	}
+	return id, nil
+}
+
+// PublicKey is an Ed25519 public key used to verify plugin signatures
+type PublicKey struct {
+	ID  KeyID
+	Key ed25519.PublicKey
+}
+
+// ParsePublicKey parses a minisign public key, either the base64 key line or the contents of
+// a minisign .pub file
+func ParsePublicKey(text string) (PublicKey, error) {
+	line := ""
+	for _, l := range strings.Split(strings.TrimSpace(text), "\n") {
+		l = strings.TrimSpace(l)
+		if l != "" && !strings.HasPrefix(l, untrustedPrefix) {
+			line = l
+			break
+		}
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(line)
+	if err != nil || len(raw) != 2+8+ed25519.PublicKeySize {
+		return PublicKey{}, fmt.Errorf("invalid public key: expected a base64 minisign key")
+	}
+	if !bytes.Equal(raw[:2], algorithmPure[:]) {
+		return PublicKey{}, fmt.Errorf("invalid public key: unsupported algorithm %q", raw[:2])
+	}
+
+	var key PublicKey
+	copy(key.ID[:], raw[2:10])
+	key.Key = ed25519.PublicKey(append([]byte(nil), raw[10:]...))
+	return key, nil
+}
+
+// String returns the key in the base64 form of a minisign public key
+func (k PublicKey) String() string {
+	raw := make([]byte, 0, 2+8+ed25519.PublicKeySize)
+	raw = append(raw, algorithmPure[:]...)
+	raw = append(raw, k.ID[:]...)
+	raw = append(raw, k.Key...)
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+// Signature is a parsed minisign detached signature
+type Signature struct {
+	Algorithm       [2]byte
+	KeyID           KeyID
+	Signature       []byte
+	TrustedComment  string
+	GlobalSignature []byte
+}
+
+// ParseSignature parses a minisign signature file
+func ParseSignature(data []byte) (*Signature, error) {
+	if len(data) > maxSignatureSize {
+		return nil, fmt.Errorf("invalid signature: larger than %d bytes", maxSignatureSize)
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if line := strings.TrimRight(scanner.Text(), "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], untrustedPrefix) || !strings.HasPrefix(lines[2], trustedPrefix) {
+		return nil, fmt.Errorf("invalid signature: not a minisign signature")
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(raw) != 2+8+ed25519.SignatureSize {
+		return nil, fmt.Errorf("invalid signature: malformed signature line")
+	}
+	global, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(global) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("invalid signature: malformed global signature")
+	}
+
+	sig := &Signature{
+		Signature:       raw[10:],
+		TrustedComment:  strings.TrimPrefix(lines[2], trustedPrefix),
+		GlobalSignature: global,
+	}
+	copy(sig.Algorithm[:], raw[:2])
+	copy(sig.KeyID[:], raw[2:10])
+	if sig.Algorithm != algorithmPure && sig.Algorithm != algorithmPrehash {
+		return nil, fmt.Errorf("invalid signature: unsupported algorithm %q", sig.Algorithm[:])
+	}
+	return sig, nil
+}
+
+// Timestamp returns the signing time recorded in the trusted comment by minisign, if any
+func (s *Signature) Timestamp() (time.Time, bool) {
+	for _, field := range strings.Fields(s.TrustedComment) {
+		if value, ok := strings.CutPrefix(field, "timestamp:"); ok {
+			if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+				return time.Unix(seconds, 0).UTC(), true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// Verify checks the signature of a file against a public key
+func (s *Signature) Verify(key PublicKey, path string) error {
+	if s.KeyID != key.ID {
+		return fmt.Errorf("signature was made with key %s, not %s", s.KeyID, key.ID)
+	}
+
+	message, err := signedMessage(s.Algorithm, path)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key.Key, message, s.Signature) {
+		return fmt.Errorf("signature does not match %s", filepath.Base(path))
+	}
+
+	global := append(append([]byte(nil), s.Signature...), s.TrustedComment...)
+	if !ed25519.Verify(key.Key, global, s.GlobalSignature) {
+		return fmt.Errorf("trusted comment of the signature has been modified")
+	}
+	return nil
+}
+
+// signedMessage returns the bytes a signature of the given algorithm covers for a file
+func signedMessage(algorithm [2]byte, path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	if algorithm == algorithmPure {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		return data, nil
+	}
+
+	hasher, _ := blake2b.New512(nil)
+	if _, err := io.Copy(hasher, file); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return hasher.Sum(nil), nil
+}
+
+// SignFile creates a prehashed minisign signature of a file. It is used to sign plugins
+// published to self-hosted registries and in tests; releases are usually signed with minisign.
+func SignFile(privateKey ed25519.PrivateKey, id KeyID, path, trustedComment string) ([]byte, error) {
+	message, err := signedMessage(algorithmPrehash, path)
+	if err != nil {
+		return nil, err
+	}
+	if trustedComment == "" {
+		trustedComment = fmt.Sprintf("timestamp:%d\tfile:%s\thashed", time.Now().Unix(), filepath.Base(path))
+	}
+
+	raw := make([]byte, 0, 2+8+ed25519.SignatureSize)
+	raw = append(raw, algorithmPrehash[:]...)
+	raw = append(raw, id[:]...)
+	signature := ed25519.Sign(privateKey, message)
+	raw = append(raw, signature...)
+	global := ed25519.Sign(privateKey, append(append([]byte(nil), signature...), trustedComment...))
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "%ssignature from devex secret key\n", untrustedPrefix)
+	fmt.Fprintln(&out, base64.StdEncoding.EncodeToString(raw))
+	fmt.Fprintf(&out, "%s%s\n", trustedPrefix, trustedComment)
+	fmt.Fprintln(&out, base64.StdEncoding.EncodeToString(global))
+	return out.Bytes(), nil
+}
+
+// TrustedKey is a signing key trusted to sign plugins
+type TrustedKey struct {
+	PublicKey
+	Comment string
+
+	// Expires retires the key when it is rotated out: signatures made after it are rejected,
+	// while plugins signed before it keep verifying. Zero means the key does not expire.
+	Expires time.Time
+}
+
+// Keyring holds the keys trusted to sign plugins and the IDs of revoked keys. Rotating keys
+// means adding the new key, letting the old key expire once releases are signed with the new
+// one, and revoking keys that were compromised.
+type Keyring struct {
+	keys    map[KeyID]TrustedKey
+	revoked map[KeyID]bool
+}
+
+// NewKeyring creates a keyring trusting the given keys
+func NewKeyring(keys ...TrustedKey) *Keyring {
+	k := &Keyring{keys: make(map[KeyID]TrustedKey), revoked: make(map[KeyID]bool)}
+	for _, key := range keys {
+		k.Add(key)
+	}
+	return k
+}
+
+// keyringFile is the JSON form of a keyring
+type keyringFile struct {
+	Keys []struct {
+		PublicKey string    `json:"public_key"`
+		Comment   string    `json:"comment,omitempty"`
+		Expires   time.Time `json:"expires,omitempty"`
+	} `json:"keys"`
+	Revoked []string `json:"revoked,omitempty"`
+}
+
+// ParseKeyring parses a keyring from its JSON form:
+//
+//	{"keys": [{"public_key": "RWQ...", "comment": "release key 2026", "expires": "2027-06-01T00:00:00Z"}],
+//	 "revoked": ["3D6A1E5B0F9C2478"]}
+func ParseKeyring(data []byte) (*Keyring, error) {
+	var file keyringFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid keyring: %w", err)
+	}
+
+	keyring := NewKeyring()
+	for i, entry := range file.Keys {
+		key, err := ParsePublicKey(entry.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid keyring: key %d: %w", i+1, err)
+		}
+		keyring.Add(TrustedKey{PublicKey: key, Comment: entry.Comment, Expires: entry.Expires})
+	}
+	for _, text := range file.Revoked {
+		id, err := ParseKeyID(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid keyring: revoked key %q: %w", text, err)
+		}
+		keyring.Revoke(id)
+	}
+	return keyring, nil
+}
+
+// Add trusts a key. A key that was already trusted is replaced.
+func (k *Keyring) Add(key TrustedKey) {
+	k.keys[key.ID] = key
+}
+
+// Revoke stops trusting a key, even if it is added again
+func (k *Keyring) Revoke(id KeyID) {
+	k.revoked[id] = true
+}
+
+// Keys returns the trusted keys that have not been revoked
+func (k *Keyring) Keys() []TrustedKey {
+	keys := make([]TrustedKey, 0, len(k.keys))
+	for id, key := range k.keys {
+		if !k.revoked[id] {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Verify checks that a file was signed by a trusted key
+func (k *Keyring) Verify(path string, signature []byte) (*TrustedKey, error) {
+	sig, err := ParseSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	if k.revoked[sig.KeyID] {
+		return nil, &SignatureError{Status: SignatureRevoked, KeyID: sig.KeyID}
+	}
+	if len(k.Keys()) == 0 {
+		return nil, &SignatureError{Status: SignatureUntrusted, KeyID: sig.KeyID, Err: errNoTrustedKeys}
+	}
+	key, ok := k.keys[sig.KeyID]
+	if !ok {
+		return nil, &SignatureError{Status: SignatureUntrusted, KeyID: sig.KeyID}
+	}
+	if err := sig.Verify(key.PublicKey, path); err != nil {
+		return nil, &SignatureError{Status: SignatureInvalid, KeyID: sig.KeyID, Err: err}
+	}
+	if !key.Expires.IsZero() {
+		signed, ok := sig.Timestamp()
+		if !ok || signed.After(key.Expires) {
+			return nil, &SignatureError{Status: SignatureExpired, KeyID: sig.KeyID}
+		}
+	}
+	return &key, nil
+}
+
+// SignatureStatus describes the outcome of verifying a plugin signature
+type SignatureStatus string
+
+const (
+	// SignatureVerified means the plugin was signed by a trusted key
+	SignatureVerified SignatureStatus = "verified"
+	// SignatureMissing means the plugin has no signature
+	SignatureMissing SignatureStatus = "unsigned"
+	// SignatureUntrusted means the plugin was signed by a key that is not trusted
+	SignatureUntrusted SignatureStatus = "signed by an untrusted key"
+	// SignatureExpired means the plugin was signed after its key was rotated out
+	SignatureExpired SignatureStatus = "signed by an expired key"
+	// SignatureRevoked means the plugin was signed by a revoked key
+	SignatureRevoked SignatureStatus = "signed by a revoked key"
+	// SignatureInvalid means the signature does not match the plugin
+	SignatureInvalid SignatureStatus = "invalid signature"
+)
+
+// SignatureError reports a plugin signature that could not be verified
+type SignatureError struct {
+	Status SignatureStatus
+	KeyID  KeyID
+	Err    error
+}
+
+func (e *SignatureError) Error() string {
+	switch {
+	case e.Err != nil:
+		return fmt.Sprintf("%s: %v", e.Status, e.Err)
+	case e.Status == SignatureMissing:
+		return string(e.Status)
+	default:
+		return fmt.Sprintf("%s (key %s)", e.Status, e.KeyID)
+	}
+}
+
+// Unwrap returns the underlying error for error wrapping support
+func (e *SignatureError) Unwrap() error {
+	return e.Err
+}
+
+// Tampered reports whether the signature was made by a trusted key but does not match the
+// file. Such plugins are refused even when unverified plugins are allowed.
+func (e *SignatureError) Tampered() bool {
+	return e.Status == SignatureInvalid
+}
+
+// SignatureVerification is the verification status of an installed plugin
+type SignatureVerification struct {
+	Status SignatureStatus
+	Key    *TrustedKey // signing key when verified
+	Err    error       // reason verification failed
+}
+
+// VerifyPluginSignature verifies a plugin binary against its detached signature. A nil
+// signature means the plugin is unsigned.
+func VerifyPluginSignature(keyring *Keyring, path string, signature []byte) SignatureVerification {
+	if len(signature) == 0 {
+		return SignatureVerification{Status: SignatureMissing, Err: &SignatureError{Status: SignatureMissing}}
+	}
+	if keyring == nil {
+		keyring = NewKeyring()
+	}
+	key, err := keyring.Verify(path, signature)
+	if err != nil {
+		var sigErr *SignatureError
+		if !errors.As(err, &sigErr) {
+			// The signature could not be parsed
+			sigErr = &SignatureError{Status: SignatureInvalid, Err: err}
+		}
+		return SignatureVerification{Status: sigErr.Status, Err: sigErr}
+	}
+	return SignatureVerification{Status: SignatureVerified, Key: key}
+}
+
+// PluginSignaturePath returns where the signature of an installed plugin is stored
+func PluginSignaturePath(pluginDir, pluginName string) string {
+	return filepath.Join(pluginDir, signaturesDir, pluginName+SignatureExtension)
+}
+
+// SavePluginSignature stores the signature of an installed plugin. An empty signature removes
+// a stored one, so a plugin replaced by an unsigned build is not reported as signed.
+func SavePluginSignature(pluginDir, pluginName string, signature []byte) error {
+	path := PluginSignaturePath(pluginDir, pluginName)
+	if len(signature) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove plugin signature: %w", err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create signature directory: %w", err)
+	}
+	if err := os.WriteFile(path, signature, 0644); err != nil {
+		return fmt.Errorf("failed to save plugin signature: %w", err)
+	}
+	return nil
+}
+
+// LoadPluginSignature reads the stored signature of an installed plugin, returning nil when
+// the plugin is unsigned
+func LoadPluginSignature(pluginDir, pluginName string) ([]byte, error) {
+	data, err := os.ReadFile(PluginSignaturePath(pluginDir, pluginName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin signature: %w", err)
+	}
+	return data, nil
+}
+
+// RequireTrustedSignature returns an error unless a plugin binary is signed by a trusted key.
+// allowUnverified accepts plugins that are unsigned or signed by a key that is not trusted;
+// signatures that do not match the binary are always refused.
+func RequireTrustedSignature(keyring *Keyring, pluginName, path string, signature []byte, allowUnverified bool) (SignatureVerification, error) {
+	verification := VerifyPluginSignature(keyring, path, signature)
+	if verification.Status == SignatureVerified {
+		return verification, nil
+	}
+
+	var sigErr *SignatureError
+	if errors.As(verification.Err, &sigErr) && !sigErr.Tampered() && allowUnverified {
+		return verification, nil
+	}
+	return verification, fmt.Errorf("plugin %s: %w", pluginName, verification.Err)
+}
+
+// verifyPluginSignature checks that a downloaded plugin is signed by a trusted key, unless the
+// plugin has been allowed to install unverified
+func (d *Downloader) verifyPluginSignature(pluginName, path string, binary PlatformBinary) error {
+	verification, err := RequireTrustedSignature(d.keyring, pluginName, path, []byte(binary.Signature), d.allowUnverified[pluginName])
+	if err != nil {
+		return err
+	}
+	if d.logger != nil {
+		if verification.Status == SignatureVerified {
+			d.logger.Success("Plugin %s is signed by trusted key %s", pluginName, verification.Key.ID)
+		} else {
+			d.logger.Warning("Installing plugin %s without a trusted signature (%v) as allowed", pluginName, verification.Err)
+		}
+	}
+	return nil
+}
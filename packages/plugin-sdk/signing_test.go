@@ -0,0 +1,243 @@
+package sdk_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+// newSigningKey creates a signing key pair for tests
+func newSigningKey() (sdk.PublicKey, ed25519.PrivateKey) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+	var id sdk.KeyID
+	_, err = rand.Read(id[:])
+	Expect(err).ToNot(HaveOccurred())
+	return sdk.PublicKey{ID: id, Key: public}, private
+}
+
+var _ = Describe("Plugin signing", func() {
+	var (
+		binary  string
+		key     sdk.PublicKey
+		private ed25519.PrivateKey
+	)
+
+	BeforeEach(func() {
+		binary = filepath.Join(GinkgoT().TempDir(), "devex-plugin-test")
+		Expect(os.WriteFile(binary, []byte("#!/bin/sh\necho plugin\n"), 0755)).To(Succeed())
+		key, private = newSigningKey()
+	})
+
+	It("parses minisign public keys and prints their key IDs like minisign", func() {
+		parsed, err := sdk.ParsePublicKey("untrusted comment: minisign public key E7620F1842B4E81F\nRWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3\n")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parsed.ID.String()).To(Equal("E7620F1842B4E81F"))
+		Expect(parsed.String()).To(Equal("RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3"))
+
+		id, err := sdk.ParseKeyID("E7620F1842B4E81F")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(id).To(Equal(parsed.ID))
+
+		_, err = sdk.ParsePublicKey("not a key")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("verifies signatures made by a trusted key", func() {
+		signature, err := sdk.SignFile(private, key.ID, binary, "")
+		Expect(err).ToNot(HaveOccurred())
+
+		keyring := sdk.NewKeyring(sdk.TrustedKey{PublicKey: key, Comment: "test key"})
+		signer, err := keyring.Verify(binary, signature)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(signer.Comment).To(Equal("test key"))
+
+		parsed, err := sdk.ParseSignature(signature)
+		Expect(err).ToNot(HaveOccurred())
+		signed, ok := parsed.Timestamp()
+		Expect(ok).To(BeTrue())
+		Expect(signed).To(BeTemporally("~", time.Now(), time.Minute))
+	})
+
+	It("rejects modified binaries and modified trusted comments", func() {
+		signature, err := sdk.SignFile(private, key.ID, binary, "timestamp:1700000000\tfile:devex-plugin-test\thashed")
+		Expect(err).ToNot(HaveOccurred())
+		keyring := sdk.NewKeyring(sdk.TrustedKey{PublicKey: key})
+
+		forged := strings.Replace(string(signature), "timestamp:1700000000", "timestamp:1800000000", 1)
+		verification := sdk.VerifyPluginSignature(keyring, binary, []byte(forged))
+		Expect(verification.Status).To(Equal(sdk.SignatureInvalid))
+		Expect(verification.Err).To(MatchError(ContainSubstring("trusted comment")))
+
+		Expect(os.WriteFile(binary, []byte("#!/bin/sh\necho tampered\n"), 0755)).To(Succeed())
+		verification = sdk.VerifyPluginSignature(keyring, binary, signature)
+		Expect(verification.Status).To(Equal(sdk.SignatureInvalid))
+
+		var sigErr *sdk.SignatureError
+		Expect(errors.As(verification.Err, &sigErr)).To(BeTrue())
+		Expect(sigErr.Tampered()).To(BeTrue())
+	})
+
+	It("reports unsigned plugins and keys that are not trusted", func() {
+		Expect(sdk.VerifyPluginSignature(sdk.NewKeyring(), binary, nil).Status).To(Equal(sdk.SignatureMissing))
+
+		other, _ := newSigningKey()
+		signature, err := sdk.SignFile(private, key.ID, binary, "")
+		Expect(err).ToNot(HaveOccurred())
+		verification := sdk.VerifyPluginSignature(sdk.NewKeyring(sdk.TrustedKey{PublicKey: other}), binary, signature)
+		Expect(verification.Status).To(Equal(sdk.SignatureUntrusted))
+		Expect(verification.Err).To(MatchError(ContainSubstring(key.ID.String())))
+	})
+
+	It("supports rotating keys with expiry and revocation", func() {
+		signedAt := func(t time.Time) []byte {
+			signature, err := sdk.SignFile(private, key.ID, binary, fmt.Sprintf("timestamp:%d\tfile:devex-plugin-test\thashed", t.Unix()))
+			Expect(err).ToNot(HaveOccurred())
+			return signature
+		}
+		expires := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+		keyring, err := sdk.ParseKeyring([]byte(fmt.Sprintf(`{"keys": [{"public_key": %q, "comment": "2025 release key", "expires": %q}]}`,
+			key.String(), expires.Format(time.RFC3339))))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = keyring.Verify(binary, signedAt(expires.Add(-time.Hour)))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sdk.VerifyPluginSignature(keyring, binary, signedAt(expires.Add(time.Hour))).Status).To(Equal(sdk.SignatureExpired))
+
+		keyring.Revoke(key.ID)
+		Expect(keyring.Keys()).To(BeEmpty())
+		Expect(sdk.VerifyPluginSignature(keyring, binary, signedAt(expires.Add(-time.Hour))).Status).To(Equal(sdk.SignatureRevoked))
+	})
+
+	It("stores signatures of installed plugins outside the plugin directory listing", func() {
+		pluginDir := GinkgoT().TempDir()
+		Expect(sdk.SavePluginSignature(pluginDir, "test", []byte("signature"))).To(Succeed())
+		Expect(sdk.PluginSignaturePath(pluginDir, "test")).To(BeARegularFile())
+
+		loaded, err := sdk.LoadPluginSignature(pluginDir, "test")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(loaded)).To(Equal("signature"))
+
+		Expect(sdk.SavePluginSignature(pluginDir, "test", nil)).To(Succeed())
+		loaded, err = sdk.LoadPluginSignature(pluginDir, "test")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loaded).To(BeNil())
+		Expect(sdk.NewExecutableManager(pluginDir).ListPlugins()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Downloader signature verification", func() {
+	var (
+		binary    []byte
+		signature []byte
+		key       sdk.PublicKey
+		pluginDir string
+		server    *httptest.Server
+	)
+
+	BeforeEach(func() {
+		binary = []byte("#!/bin/sh\necho plugin\n")
+		source := filepath.Join(GinkgoT().TempDir(), "plugin")
+		Expect(os.WriteFile(source, binary, 0755)).To(Succeed())
+
+		var private ed25519.PrivateKey
+		key, private = newSigningKey()
+		var err error
+		signature, err = sdk.SignFile(private, key.ID, source, "")
+		Expect(err).ToNot(HaveOccurred())
+		pluginDir = GinkgoT().TempDir()
+
+		platformKey := runtime.GOOS + "-" + runtime.GOARCH
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			name := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/plugins/"), "/")[0]
+			if strings.HasSuffix(r.URL.Path, "/download/"+platformKey) {
+				_, _ = w.Write(binary)
+				return
+			}
+			checksum := sha256.Sum256(binary)
+			metadata := sdk.PluginMetadata{
+				PluginInfo: sdk.PluginInfo{Name: name, Version: "1.0.0"},
+				Binaries: map[string]sdk.PlatformBinary{platformKey: {
+					URL:      "https://example.com/" + name,
+					Checksum: hex.EncodeToString(checksum[:]),
+					Size:     int64(len(binary)),
+				}},
+			}
+			if name == "signed" {
+				binary := metadata.Binaries[platformKey]
+				binary.Signature = string(signature)
+				metadata.Binaries[platformKey] = binary
+			}
+			_ = json.NewEncoder(w).Encode(metadata)
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	newDownloader := func() *sdk.Downloader {
+		downloader := sdk.NewDownloader(server.URL, pluginDir)
+		downloader.SetSilent(true)
+		downloader.SetKeyring(sdk.NewKeyring(sdk.TrustedKey{PublicKey: key}))
+		return downloader
+	}
+
+	It("installs signed plugins and keeps their signature", func() {
+		Expect(newDownloader().DownloadPluginWithContext(context.Background(), "signed")).To(Succeed())
+
+		stored, err := sdk.LoadPluginSignature(pluginDir, "signed")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stored).To(Equal(signature))
+	})
+
+	It("refuses unsigned plugins unless they are allowed", func() {
+		downloader := newDownloader()
+		err := downloader.DownloadPluginWithContext(context.Background(), "unsigned")
+		var sigErr *sdk.SignatureError
+		Expect(errors.As(err, &sigErr)).To(BeTrue())
+		Expect(sigErr.Status).To(Equal(sdk.SignatureMissing))
+		Expect(filepath.Join(pluginDir, "devex-plugin-unsigned")).ToNot(BeAnExistingFile())
+
+		downloader.AllowUnverified("unsigned")
+		Expect(downloader.DownloadPluginWithContext(context.Background(), "unsigned")).To(Succeed())
+	})
+
+	It("refuses unsigned plugins by default", func() {
+		downloader := sdk.NewSecureDownloader(sdk.DownloaderConfig{RegistryURL: server.URL, PluginDir: pluginDir})
+		downloader.SetSilent(true)
+
+		err := downloader.DownloadPluginWithContext(context.Background(), "unsigned")
+		Expect(err).To(MatchError(ContainSubstring(string(sdk.SignatureMissing))))
+		Expect(filepath.Join(pluginDir, "devex-plugin-unsigned")).ToNot(BeAnExistingFile())
+
+		downloader = sdk.NewDownloader(server.URL, pluginDir)
+		downloader.SetSilent(true)
+		err = downloader.DownloadPluginWithContext(context.Background(), "signed")
+		Expect(err).To(MatchError(ContainSubstring(string(sdk.SignatureUntrusted))))
+	})
+
+	It("refuses plugins that do not match their signature even when allowed", func() {
+		binary = []byte("#!/bin/sh\necho tampered\n")
+		downloader := newDownloader()
+		downloader.AllowUnverified("signed")
+
+		err := downloader.DownloadPluginWithContext(context.Background(), "signed")
+		Expect(err).To(MatchError(ContainSubstring(string(sdk.SignatureInvalid))))
+	})
+})
@@ -0,0 +1,357 @@
+//go:build linux
+
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// A plugin granted sudo runs confined like every other plugin, and no-new-privs keeps sudo from
+// gaining privileges inside it. Before confining itself, the launcher starts a sudo helper: a
+// second, unconfined launcher process connected to the plugin by a socket pair. The SDK command
+// helpers send the commands the plugin runs with sudo to the helper together with the plugin's
+// standard streams. The helper checks each command against the manifest, records it with the
+// SudoAuditor and runs it with sudo.
+
+// sudoHelperFD is the descriptor the sudo helper receives its connection on (ExtraFiles[0])
+const sudoHelperFD = 3
+
+// maxSudoMessage bounds a message between a plugin and its sudo helper
+const maxSudoMessage = 64 * 1024
+
+// sudoStopDelay is how long a command stopped by its plugin has to exit before it is killed
+const sudoStopDelay = 10 * time.Second
+
+// sudoRequest is a message from a confined plugin to its sudo helper
+type sudoRequest struct {
+	Command string   `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+
+	// Cancel stops the command that is running
+	Cancel bool `json:"cancel,omitempty"`
+}
+
+// sudoResponse is the result of a command the sudo helper was asked to run
+type sudoResponse struct {
+	ExitCode int    `json:"exit_code"`
+	Denied   bool   `json:"denied,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// sudoMessage is a request received by the sudo helper with the streams passed along with it
+type sudoMessage struct {
+	request sudoRequest
+	files   []*os.File
+	err     error
+}
+
+// sudoHelper is the connection of a confined plugin to its sudo helper, nil without a helper
+var sudoHelper struct {
+	mu   sync.Mutex
+	conn *net.UnixConn
+}
+
+// init takes over the sudo helper connection of a confined plugin, so the programs the plugin
+// runs do not inherit it
+func init() {
+	fd, err := strconv.Atoi(os.Getenv(sudoHelperEnvVar))
+	if err != nil || fd <= 2 || os.Getenv(PermissionsEnvVar) == "" {
+		return
+	}
+	_ = os.Unsetenv(sudoHelperEnvVar)
+
+	file := os.NewFile(uintptr(fd), "sudo-helper")
+	conn, err := net.FileConn(file)
+	_ = file.Close()
+	if err != nil {
+		return
+	}
+	if unixConn, ok := conn.(*net.UnixConn); ok {
+		sudoHelper.conn = unixConn
+		return
+	}
+	_ = conn.Close()
+}
+
+// startSudoHelper starts the sudo helper of a plugin and leaves the plugin's end of the
+// connection open across exec. The helper exits when the plugin closes the connection.
+func startSudoHelper(pluginPath string) error {
+	launcher, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the sudo helper: %w", err)
+	}
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_SEQPACKET|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("failed to connect the sudo helper: %w", err)
+	}
+	helperEnd := os.NewFile(uintptr(fds[0]), "sudo-helper")
+	defer func() { _ = helperEnd.Close() }()
+
+	plugin := strings.TrimPrefix(filepath.Base(pluginPath), "devex-plugin-")
+	cmd := exec.Command(launcher, SandboxLauncherArg, sudoHelperArg, plugin)
+	cmd.ExtraFiles = []*os.File{helperEnd}
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		_ = unix.Close(fds[1])
+		return fmt.Errorf("failed to start the sudo helper: %w", err)
+	}
+
+	if _, err := unix.FcntlInt(uintptr(fds[1]), unix.F_SETFD, 0); err != nil {
+		return fmt.Errorf("failed to pass the sudo helper connection: %w", err)
+	}
+	return os.Setenv(sudoHelperEnvVar, strconv.Itoa(fds[1]))
+}
+
+// runWithSudoHelper runs a command as root through the sudo helper of the plugin, with the
+// plugin's standard streams. It reports false when the plugin was started without a helper.
+func runWithSudoHelper(ctx context.Context, name string, args []string) (bool, error) {
+	conn := sudoHelper.conn
+	if conn == nil {
+		return false, nil
+	}
+	sudoHelper.mu.Lock()
+	defer sudoHelper.mu.Unlock()
+
+	request, err := json.Marshal(sudoRequest{Command: name, Args: args})
+	if err != nil {
+		return true, fmt.Errorf("failed to encode sudo request: %w", err)
+	}
+	if len(request) > maxSudoMessage {
+		return true, fmt.Errorf("command line of %s is too long for the sudo helper", name)
+	}
+	streams := unix.UnixRights(int(os.Stdin.Fd()), int(os.Stdout.Fd()), int(os.Stderr.Fd()))
+	if _, _, err := conn.WriteMsgUnix(request, streams, nil); err != nil {
+		return true, fmt.Errorf("failed to reach the sudo helper: %w", err)
+	}
+
+	// The cancel message must not outlive the request, or it would stop the next command
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-ctx.Done():
+			cancel, _ := json.Marshal(sudoRequest{Cancel: true})
+			_, _ = conn.Write(cancel)
+		case <-done:
+		}
+	}()
+	defer wg.Wait()
+	defer close(done)
+
+	buf := make([]byte, maxSudoMessage)
+	n, err := conn.Read(buf)
+	if err != nil || n == 0 {
+		return true, fmt.Errorf("sudo helper closed the connection: %v", err)
+	}
+	var response sudoResponse
+	if err := json.Unmarshal(buf[:n], &response); err != nil {
+		return true, fmt.Errorf("invalid sudo helper response: %w", err)
+	}
+
+	switch {
+	case response.Denied:
+		return true, &PermissionViolationError{Plugin: runningPluginName(), Permission: "running " + name + " as root"}
+	case response.Error == "":
+		return true, nil
+	case ctx.Err() != nil:
+		return true, fmt.Errorf("sudo %s: %w", name, ctx.Err())
+	default:
+		return true, fmt.Errorf("sudo %s: %s", name, response.Error)
+	}
+}
+
+// runSudoHelper serves the commands a confined plugin runs as root until the plugin exits.
+// args are the arguments following sudoHelperArg: the plugin name.
+func runSudoHelper(args []string, permissions *PluginPermissions) error {
+	if len(args) != 1 {
+		return errors.New("missing plugin name")
+	}
+	if sudoAuditor == nil {
+		return errors.New("no audit log to record commands run as root in")
+	}
+	plugin := args[0]
+
+	file := os.NewFile(sudoHelperFD, "sudo-helper")
+	conn, err := net.FileConn(file)
+	_ = file.Close()
+	if err != nil {
+		return fmt.Errorf("invalid sudo helper connection: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return errors.New("invalid sudo helper connection")
+	}
+
+	// Interrupts reach the whole process group; the plugin decides whether its command stops
+	signal.Notify(make(chan os.Signal, 1), os.Interrupt)
+
+	messages := make(chan sudoMessage)
+	go readSudoMessages(unixConn, messages)
+	for message := range messages {
+		if message.request.Cancel {
+			closeFiles(message.files)
+			continue
+		}
+		response, err := json.Marshal(serveSudoRequest(plugin, permissions, message, messages))
+		if err != nil {
+			return fmt.Errorf("failed to encode sudo response: %w", err)
+		}
+		if _, err := unixConn.Write(response); err != nil {
+			return nil
+		}
+	}
+	return nil
+}
+
+// readSudoMessages passes the messages of the plugin on until it closes the connection
+func readSudoMessages(conn *net.UnixConn, messages chan<- sudoMessage) {
+	defer close(messages)
+	buf := make([]byte, maxSudoMessage)
+	oob := make([]byte, unix.CmsgSpace(3*4))
+	for {
+		n, oobn, flags, _, err := conn.ReadMsgUnix(buf, oob)
+		if err != nil || n == 0 {
+			return
+		}
+
+		message := sudoMessage{files: receivedFiles(oob[:oobn])}
+		if flags&(unix.MSG_TRUNC|unix.MSG_CTRUNC) != 0 {
+			message.err = errors.New("sudo request is too long")
+		} else if err := json.Unmarshal(buf[:n], &message.request); err != nil {
+			message.err = fmt.Errorf("invalid sudo request: %w", err)
+		}
+		messages <- message
+	}
+}
+
+// receivedFiles returns the descriptors passed along with a message
+func receivedFiles(oob []byte) []*os.File {
+	controls, err := unix.ParseSocketControlMessage(oob)
+	if err != nil {
+		return nil
+	}
+	var files []*os.File
+	for i := range controls {
+		fds, err := unix.ParseUnixRights(&controls[i])
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			unix.CloseOnExec(fd)
+			files = append(files, os.NewFile(uintptr(fd), "plugin-stream"))
+		}
+	}
+	return files
+}
+
+// serveSudoRequest records and runs one command as root with the plugin's streams. A cancel
+// message, or the plugin going away, stops the command while it runs.
+func serveSudoRequest(plugin string, permissions *PluginPermissions, message sudoMessage, messages <-chan sudoMessage) sudoResponse {
+	defer closeFiles(message.files)
+	end := sudoAuditor(plugin, message.request.Command, message.request.Args)
+
+	if err := checkSudoRequest(plugin, permissions, message); err != nil {
+		end(err)
+		var violation *PermissionViolationError
+		return sudoResponse{ExitCode: -1, Denied: errors.As(err, &violation), Error: err.Error()}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cmd := rootCommand(ctx, message.request.Command, message.request.Args)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = message.files[0], message.files[1], message.files[2]
+	// sudo passes SIGTERM on to the command, which it cannot do for SIGKILL
+	cmd.Cancel = func() error { return cmd.Process.Signal(syscall.SIGTERM) }
+	cmd.WaitDelay = sudoStopDelay
+
+	err := cmd.Start()
+	if err == nil {
+		err = waitSudoCommand(cmd, cancel, messages)
+	}
+	end(err)
+
+	response := sudoResponse{}
+	if err != nil {
+		response.ExitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			response.ExitCode = exitErr.ExitCode()
+		}
+		response.Error = err.Error()
+	}
+	return response
+}
+
+// checkSudoRequest refuses requests the manifest of the plugin does not allow
+func checkSudoRequest(plugin string, permissions *PluginPermissions, message sudoMessage) error {
+	if message.err != nil {
+		return message.err
+	}
+	if len(message.files) != 3 {
+		return errors.New("sudo request did not pass the standard streams")
+	}
+	if !permissions.Sudo {
+		return &PermissionViolationError{Plugin: plugin, Permission: "sudo"}
+	}
+	if !permissions.allowsRootCommand(message.request.Command) {
+		return &PermissionViolationError{Plugin: plugin, Permission: "running " + message.request.Command + " as root"}
+	}
+	return nil
+}
+
+// rootCommand returns the command that runs a program as root: through sudo, or directly when
+// sudo is not needed
+func rootCommand(ctx context.Context, name string, args []string) *exec.Cmd {
+	if RequireSudo() {
+		return exec.CommandContext(ctx, "sudo", append([]string{"--", name}, args...)...)
+	}
+	return exec.CommandContext(ctx, name, args...)
+}
+
+// waitSudoCommand waits for a command, stopping it when the plugin cancels it or goes away
+func waitSudoCommand(cmd *exec.Cmd, cancel context.CancelFunc, messages <-chan sudoMessage) error {
+	finished := make(chan error, 1)
+	go func() { finished <- cmd.Wait() }()
+	for {
+		select {
+		case err := <-finished:
+			return err
+		case message, ok := <-messages:
+			if !ok {
+				messages = nil
+				cancel()
+				continue
+			}
+			// The plugin waits for the response before sending another request
+			closeFiles(message.files)
+			if message.request.Cancel {
+				cancel()
+			}
+		}
+	}
+}
+
+// closeFiles closes the streams passed with a message
+func closeFiles(files []*os.File) {
+	for _, file := range files {
+		_ = file.Close()
+	}
+}
@@ -0,0 +1,408 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ConstraintFlag carries the version constraint of a resolve command, e.g. "resolve --constraint=>=2.40 git"
+const ConstraintFlag = "--constraint="
+
+// ErrNoMatchingVersion is matched by NoMatchingVersionError
+var ErrNoMatchingVersion = errors.New("no version matches the constraint")
+
+// NoMatchingVersionError reports that none of the available versions of a package satisfies a constraint
+type NoMatchingVersionError struct {
+	Package    string
+	Constraint string
+	Available  []string
+}
+
+func (e *NoMatchingVersionError) Error() string {
+	if len(e.Available) == 0 {
+		return fmt.Sprintf("no versions of %s are available", e.Package)
+	}
+	return fmt.Sprintf("no version of %s matches %q (available: %s)", e.Package, e.Constraint, strings.Join(e.Available, ", "))
+}
+
+// Is makes errors.Is(err, ErrNoMatchingVersion) match
+func (e *NoMatchingVersionError) Is(target error) bool {
+	return target == ErrNoMatchingVersion
+}
+
+// versionClause is a single comparison of a constraint
+type versionClause struct {
+	op      string
+	version string
+}
+
+// VersionConstraint is a parsed version constraint.
+//
+// Supported forms, combined with commas (all clauses must match):
+//
+//	""  "*"  "latest"   any version
+//	"1.2"               1.2 or any 1.2.x release (prefix on a version boundary)
+//	"=1.2.3"            exactly 1.2.3
+//	">=1.2" ">1.2" "<=2" "<2" "!=1.3"
+//	"~1.2.3"            >=1.2.3 within 1.2.x
+//	"^1.2.3"            >=1.2.3 within 1.x (within 0.2.x for 0.2.3)
+type VersionConstraint struct {
+	raw     string
+	clauses []versionClause
+}
+
+// ParseVersionConstraint parses a constraint string
+func ParseVersionConstraint(constraint string) (VersionConstraint, error) {
+	c := VersionConstraint{raw: strings.TrimSpace(constraint)}
+	if c.raw == "" || c.raw == "*" || strings.EqualFold(c.raw, "latest") {
+		return c, nil
+	}
+
+	for _, part := range strings.Split(c.raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		op := ""
+		for _, candidate := range []string{">=", "<=", "!=", "==", ">", "<", "=", "~", "^"} {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				break
+			}
+		}
+		version := strings.TrimSpace(strings.TrimPrefix(part, op))
+		if version == "" || strings.ContainsAny(version, " <>=!") {
+			return VersionConstraint{}, fmt.Errorf("invalid version constraint %q", constraint)
+		}
+
+		switch op {
+		case "":
+			c.clauses = append(c.clauses, versionClause{op: "prefix", version: version})
+		case "==":
+			c.clauses = append(c.clauses, versionClause{op: "=", version: version})
+		case "~":
+			c.clauses = append(c.clauses,
+				versionClause{op: ">=", version: version},
+				versionClause{op: "prefix", version: versionPrefix(version, 2)})
+		case "^":
+			segments := 1
+			if strings.HasPrefix(strings.TrimPrefix(version, "v"), "0.") {
+				segments = 2
+			}
+			c.clauses = append(c.clauses,
+				versionClause{op: ">=", version: version},
+				versionClause{op: "prefix", version: versionPrefix(version, segments)})
+		default:
+			c.clauses = append(c.clauses, versionClause{op: op, version: version})
+		}
+	}
+	return c, nil
+}
+
+// String returns the constraint as written
+func (c VersionConstraint) String() string {
+	return c.raw
+}
+
+// IsAny reports whether the constraint accepts every version
+func (c VersionConstraint) IsAny() bool {
+	return len(c.clauses) == 0
+}
+
+// Matches reports whether a version satisfies every clause of the constraint
+func (c VersionConstraint) Matches(version string) bool {
+	for _, clause := range c.clauses {
+		candidate := version
+		if !strings.Contains(clause.version, ":") {
+			// Constraints are usually written without the epoch package managers add
+			_, candidate = splitEpoch(version)
+		}
+		cmp := CompareVersions(candidate, clause.version)
+		var ok bool
+		switch clause.op {
+		case "prefix":
+			ok = hasVersionPrefix(candidate, clause.version)
+		case "=":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		case ">=":
+			ok = cmp >= 0
+		case ">":
+			ok = cmp > 0
+		case "<=":
+			ok = cmp <= 0
+		case "<":
+			ok = cmp < 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// SelectVersion returns the highest candidate satisfying the constraint.
+// Candidates with equal versions keep their order, so package managers should list preferred sources first.
+func SelectVersion(pkg, constraint string, candidates []PackageInfo) (PackageInfo, error) {
+	parsed, err := ParseVersionConstraint(constraint)
+	if err != nil {
+		return PackageInfo{}, err
+	}
+
+	var best *PackageInfo
+	available := make([]string, 0, len(candidates))
+	for i := range candidates {
+		candidate := &candidates[i]
+		available = append(available, candidate.Version)
+		if candidate.Version == "" || !parsed.Matches(candidate.Version) {
+			continue
+		}
+		if best == nil || CompareVersions(candidate.Version, best.Version) > 0 {
+			best = candidate
+		}
+	}
+
+	if best == nil {
+		return PackageInfo{}, &NoMatchingVersionError{Package: pkg, Constraint: constraint, Available: available}
+	}
+
+	selected := *best
+	if selected.Name == "" {
+		selected.Name = pkg
+	}
+	return selected, nil
+}
+
+// CompareVersions compares two version strings and returns -1, 0 or 1.
+//
+// The comparison understands the common shapes used by package managers: an optional "v" prefix,
+// an optional numeric epoch ("1:2.43.0"), numeric segments compared as numbers and alphabetic
+// segments compared as text. A trailing alphabetic segment such as "rc1" sorts before the release.
+func CompareVersions(a, b string) int {
+	epochA, restA := splitEpoch(a)
+	epochB, restB := splitEpoch(b)
+	if epochA != epochB {
+		if epochA < epochB {
+			return -1
+		}
+		return 1
+	}
+
+	tokensA := versionTokens(restA)
+	tokensB := versionTokens(restB)
+	for i := 0; i < len(tokensA) || i < len(tokensB); i++ {
+		switch {
+		case i >= len(tokensA):
+			return trailingOrder(tokensB[i], -1)
+		case i >= len(tokensB):
+			return trailingOrder(tokensA[i], 1)
+		}
+
+		ta, tb := tokensA[i], tokensB[i]
+		na, errA := strconv.ParseUint(ta, 10, 64)
+		nb, errB := strconv.ParseUint(tb, 10, 64)
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				if na < nb {
+					return -1
+				}
+				return 1
+			}
+		case errA == nil:
+			return 1 // numbers sort after pre-release labels
+		case errB == nil:
+			return -1
+		default:
+			if cmp := strings.Compare(ta, tb); cmp != 0 {
+				return cmp
+			}
+		}
+	}
+	return 0
+}
+
+// trailingOrder orders a version that has an extra token: extra numbers make it newer,
+// an extra label (e.g. "rc1") makes it a pre-release and therefore older
+func trailingOrder(extra string, longer int) int {
+	if _, err := strconv.ParseUint(extra, 10, 64); err == nil {
+		return longer
+	}
+	return -longer
+}
+
+// splitEpoch separates a numeric "epoch:" prefix and a leading "v"
+func splitEpoch(version string) (uint64, string) {
+	version = strings.TrimSpace(version)
+	if idx := strings.Index(version, ":"); idx > 0 {
+		if epoch, err := strconv.ParseUint(version[:idx], 10, 64); err == nil {
+			return epoch, strings.TrimPrefix(version[idx+1:], "v")
+		}
+	}
+	return 0, strings.TrimPrefix(version, "v")
+}
+
+// versionTokens splits a version into alternating runs of digits and letters, dropping separators
+func versionTokens(version string) []string {
+	var tokens []string
+	start := -1
+	digits := false
+	for i, r := range version {
+		isDigit := r >= '0' && r <= '9'
+		isAlpha := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if !isDigit && !isAlpha {
+			if start >= 0 {
+				tokens = append(tokens, version[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && isDigit != digits {
+			tokens = append(tokens, version[start:i])
+			start = -1
+		}
+		if start < 0 {
+			start = i
+			digits = isDigit
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, version[start:])
+	}
+	return tokens
+}
+
+// hasVersionPrefix reports whether version equals prefix or continues it after a separator
+func hasVersionPrefix(version, prefix string) bool {
+	prefix = strings.TrimPrefix(prefix, "v")
+	version = strings.TrimPrefix(version, "v")
+	if !strings.HasPrefix(version, prefix) {
+		return false
+	}
+	if len(version) == len(prefix) {
+		return true
+	}
+	return strings.ContainsRune(".-+~_", rune(version[len(prefix)]))
+}
+
+// versionPrefix returns the first n dot-separated segments of a version
+func versionPrefix(version string, n int) string {
+	segments := strings.Split(version, ".")
+	if len(segments) <= n {
+		return version
+	}
+	return strings.Join(segments[:n], ".")
+}
+
+// ParseConstraintArgs separates the --constraint flag from the package arguments of a resolve command
+func ParseConstraintArgs(args []string) (string, []string) {
+	constraint := ""
+	packages := make([]string, 0, len(args))
+	for _, arg := range args {
+		if strings.HasPrefix(arg, ConstraintFlag) {
+			constraint = strings.TrimPrefix(arg, ConstraintFlag)
+			continue
+		}
+		packages = append(packages, arg)
+	}
+	return constraint, packages
+}
+
+// PrintPackageInfo writes a package as one JSON line, the output format of the resolve and version commands
+func PrintPackageInfo(info PackageInfo) error {
+	return json.NewEncoder(os.Stdout).Encode(info)
+}
+
+// ParsePackageInfoLines decodes the output of the resolve and version commands
+func ParsePackageInfoLines(output string) ([]PackageInfo, error) {
+	var packages []PackageInfo
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var info PackageInfo
+		if err := json.Unmarshal([]byte(line), &info); err != nil {
+			return nil, fmt.Errorf("invalid package line %q: %w", line, err)
+		}
+		packages = append(packages, info)
+	}
+	return packages, nil
+}
+
+// ResolveVersions implements the resolve command on top of a per-package candidate lookup.
+// For every package it prints the highest available version matching the --constraint flag,
+// including the Spec that installs exactly that version.
+func (p *PackageManagerPlugin) ResolveVersions(ctx context.Context, args []string, candidates func(ctx context.Context, pkg string) ([]PackageInfo, error)) error {
+	constraint, packages := ParseConstraintArgs(args)
+	if len(packages) == 0 {
+		return fmt.Errorf("no packages specified")
+	}
+	if _, err := ParseVersionConstraint(constraint); err != nil {
+		return err
+	}
+
+	for _, pkg := range packages {
+		if pkg == "" || strings.HasPrefix(pkg, "-") {
+			return fmt.Errorf("invalid package name '%s'", pkg)
+		}
+
+		available, err := candidates(ctx, pkg)
+		if err != nil {
+			return fmt.Errorf("failed to list versions of %s: %w", pkg, err)
+		}
+
+		selected, err := SelectVersion(pkg, constraint, available)
+		if err != nil {
+			return err
+		}
+		if err := PrintPackageInfo(selected); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReportVersions implements the version command: it prints the installed version and source of every package.
+// Packages that are not installed are reported together in a NotInstalledError.
+func (p *PackageManagerPlugin) ReportVersions(ctx context.Context, packages []string, installed func(ctx context.Context, pkg string) (PackageInfo, error)) error {
+	if len(packages) == 0 {
+		return fmt.Errorf("no packages specified")
+	}
+
+	var missing []string
+	for _, pkg := range packages {
+		if pkg == "" || strings.HasPrefix(pkg, "-") {
+			return fmt.Errorf("invalid package name '%s'", pkg)
+		}
+
+		info, err := installed(ctx, pkg)
+		if errors.Is(err, ErrNotInstalled) {
+			missing = append(missing, pkg)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to query the installed version of %s: %w", pkg, err)
+		}
+
+		if info.Name == "" {
+			info.Name = pkg
+		}
+		info.Installed = true
+		if err := PrintPackageInfo(info); err != nil {
+			return err
+		}
+	}
+
+	if len(missing) > 0 {
+		return &NotInstalledError{Packages: missing}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package sdk_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jameswlane/devex/packages/plugin-sdk"
+)
+
+// fakeVersionedPlugin serves resolve and version from fixed candidate lists
+type fakeVersionedPlugin struct {
+	*sdk.PackageManagerPlugin
+	candidates map[string][]sdk.PackageInfo
+	installed  map[string]sdk.PackageInfo
+}
+
+func newFakeVersionedPlugin() *fakeVersionedPlugin {
+	return &fakeVersionedPlugin{
+		PackageManagerPlugin: sdk.NewPackageManagerPlugin(sdk.PluginInfo{
+			Name:     "package-manager-fake",
+			Commands: []sdk.PluginCommand{{Name: "resolve"}, {Name: "version"}},
+		}, "true"),
+		candidates: map[string][]sdk.PackageInfo{
+			"git": {
+				{Version: "1:2.43.0-1", Source: "noble/main", Spec: "git=1:2.43.0-1"},
+				{Version: "1:2.39.2-1", Source: "bookworm/main", Spec: "git=1:2.39.2-1"},
+			},
+		},
+		installed: map[string]sdk.PackageInfo{
+			"git": {Version: "1:2.43.0-1", Source: "noble/main"},
+		},
+	}
+}
+
+func (p *fakeVersionedPlugin) Execute(command string, args []string) error {
+	ctx := context.Background()
+	switch command {
+	case "resolve":
+		return p.ResolveVersions(ctx, args, func(_ context.Context, pkg string) ([]sdk.PackageInfo, error) {
+			return p.candidates[pkg], nil
+		})
+	case "version":
+		return p.ReportVersions(ctx, args, func(_ context.Context, pkg string) (sdk.PackageInfo, error) {
+			info, ok := p.installed[pkg]
+			if !ok {
+				return sdk.PackageInfo{}, sdk.ErrNotInstalled
+			}
+			return info, nil
+		})
+	}
+	return errors.New("unknown command")
+}
+
+var _ = Describe("Version handling", func() {
+	DescribeTable("CompareVersions",
+		func(a, b string, expected int) {
+			Expect(sdk.CompareVersions(a, b)).To(Equal(expected))
+			Expect(sdk.CompareVersions(b, a)).To(Equal(-expected))
+		},
+		Entry("numeric segments", "1.10.0", "1.9.3", 1),
+		Entry("equal with v prefix", "v2.0.1", "2.0.1", 0),
+		Entry("epoch wins", "1:1.0", "9.9", 1),
+		Entry("longer release is newer", "1.2.1", "1.2", 1),
+		Entry("pre-release is older", "1.2rc1", "1.2", -1),
+		Entry("debian revisions", "2.43.0-1ubuntu7", "2.43.0-1ubuntu10", -1),
+	)
+
+	DescribeTable("VersionConstraint.Matches",
+		func(constraint, version string, expected bool) {
+			parsed, err := sdk.ParseVersionConstraint(constraint)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed.Matches(version)).To(Equal(expected))
+		},
+		Entry("any", "", "0.1", true),
+		Entry("prefix on a boundary", "1.2", "1.2.9", true),
+		Entry("prefix is not a substring match", "1.2", "1.20.0", false),
+		Entry("prefix ignores epochs", "2.43", "1:2.43.0-1", true),
+		Entry("exact", "=1.2.3", "1.2.3", true),
+		Entry("range", ">=1.2, <2", "1.9.0", true),
+		Entry("range upper bound", ">=1.2, <2", "2.0.0", false),
+		Entry("tilde", "~1.2.3", "1.2.9", true),
+		Entry("tilde stays in minor", "~1.2.3", "1.3.0", false),
+		Entry("caret", "^1.2.3", "1.9.0", true),
+		Entry("caret on zero major", "^0.2.3", "0.3.0", false),
+	)
+
+	It("rejects malformed constraints", func() {
+		_, err := sdk.ParseVersionConstraint(">=")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("selects the highest matching candidate", func() {
+		selected, err := sdk.SelectVersion("node", "20", []sdk.PackageInfo{
+			{Version: "18.19.0"}, {Version: "20.11.1"}, {Version: "20.9.0"}, {Version: "21.6.0"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(selected.Name).To(Equal("node"))
+		Expect(selected.Version).To(Equal("20.11.1"))
+
+		_, err = sdk.SelectVersion("node", ">=22", []sdk.PackageInfo{{Version: "20.11.1"}})
+		Expect(err).To(MatchError(sdk.ErrNoMatchingVersion))
+		Expect(err.Error()).To(ContainSubstring("20.11.1"))
+	})
+
+	Describe("legacy adapter", func() {
+		var handler sdk.RPCHandler
+
+		BeforeEach(func() {
+			handler = sdk.NewLegacyRPCHandler(newFakeVersionedPlugin())
+		})
+
+		It("returns resolved versions as packages", func() {
+			req := sdk.NewRPCRequest(sdk.MethodResolve, sdk.RPCParams{Packages: []string{"git"}, Constraint: "2.39"})
+			result, err := handler.HandleRPC(context.Background(), req, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Packages).To(HaveLen(1))
+			Expect(result.Packages[0].Version).To(Equal("1:2.39.2-1"))
+			Expect(result.Packages[0].Spec).To(Equal("git=1:2.39.2-1"))
+			Expect(result.Packages[0].Source).To(Equal("bookworm/main"))
+		})
+
+		It("reports unsatisfiable constraints as package not found", func() {
+			req := sdk.NewRPCRequest(sdk.MethodResolve, sdk.RPCParams{Packages: []string{"git"}, Constraint: ">=3"})
+			_, err := handler.HandleRPC(context.Background(), req, nil)
+
+			var rpcErr *sdk.RPCError
+			Expect(errors.As(err, &rpcErr)).To(BeTrue())
+			Expect(rpcErr.Code).To(Equal(sdk.ErrCodePackageNotFound))
+			Expect(strings.Contains(rpcErr.Message, "2.43.0")).To(BeTrue())
+		})
+
+		It("returns installed versions", func() {
+			req := sdk.NewRPCRequest(sdk.MethodVersion, sdk.RPCParams{Packages: []string{"git"}})
+			result, err := handler.HandleRPC(context.Background(), req, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Packages).To(ConsistOf(sdk.PackageInfo{
+				Name: "git", Version: "1:2.43.0-1", Source: "noble/main", Installed: true,
+			}))
+		})
+	})
+})
@@ -9,6 +9,7 @@ require (
 	github.com/cloudflare/circl v1.6.1 // indirect
 	golang.org/x/crypto v0.45.0 // indirect
 	golang.org/x/sys v0.38.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 replace github.com/jameswlane/devex/packages/plugin-sdk => ../plugin-sdk
@@ -37,13 +37,17 @@ func (p *StackDetectorPlugin) handleReport(args []string) error {
 
 	// Parse arguments
 	for i := 0; i < len(args); i++ {
-		switch args[i] {
-		case "--format":
+		switch {
+		case strings.HasPrefix(args[i], "--format="):
+			format = strings.TrimPrefix(args[i], "--format=")
+		case strings.HasPrefix(args[i], "--output="):
+			outputPath = strings.TrimPrefix(args[i], "--output=")
+		case args[i] == "--format":
 			if i+1 < len(args) {
 				format = args[i+1]
 				i++
 			}
-		case "--output":
+		case args[i] == "--output":
 			if i+1 < len(args) {
 				outputPath = args[i+1]
 				i++
@@ -66,7 +70,10 @@ func (p *StackDetectorPlugin) handleReport(args []string) error {
 		return err
 	}
 
-	fmt.Printf("📄 Generating stack report for: %s\n", dir)
+	// Keep machine-readable output on stdout parseable
+	if format == "text" || outputPath != "" {
+		fmt.Printf("📄 Generating stack report for: %s\n", dir)
+	}
 
 	// Perform analysis
 	analysis, err := p.performProjectAnalysis(dir)
@@ -1,8 +1,12 @@
 package main_test
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
 	. "github.com/onsi/ginkgo/v2"
-	_ "github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
 
 	sdk "github.com/jameswlane/devex/packages/plugin-sdk"
 	main "github.com/jameswlane/devex/packages/tool-stackdetector"
@@ -43,7 +47,19 @@ var _ = Describe("Stack Reporting", func() {
 			})
 
 			It("should generate JSON format reports", func() {
-				Skip("Integration test - requires JSON report generation")
+				dir := GinkgoT().TempDir()
+				Expect(os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0600)).To(Succeed())
+				output := filepath.Join(GinkgoT().TempDir(), "report.json")
+
+				plugin := main.NewStackDetectorPlugin()
+				Expect(plugin.Execute("report", []string{"--format=json", "--output=" + output, dir})).To(Succeed())
+
+				data, err := os.ReadFile(output)
+				Expect(err).ToNot(HaveOccurred())
+				var report main.StackReport
+				Expect(json.Unmarshal(data, &report)).To(Succeed())
+				Expect(report.Technologies).To(ContainElement(HaveField("Name", "Go")))
+				Expect(string(data)).To(ContainSubstring(`"confidence": 10`))
 			})
 
 			It("should generate YAML format reports", func() {